@@ -134,6 +134,11 @@ func (it *internalClient) does(method string, request *Request) *Response {
 		response.Elapsed = stopwatch.Elapsed()
 		common.Trace("%s %s took %s", method, url, response.Elapsed)
 	}()
+	if settings.Global.Offline() {
+		response.Status = 9003
+		response.Err = settings.OfflineError(fmt.Sprintf("%s %s", method, url))
+		return response
+	}
 	httpRequest, err := http.NewRequest(method, url, request.Body)
 	if err != nil {
 		response.Status = 9001
@@ -220,6 +225,10 @@ func Download(url, filename string) error {
 	common.Timeline("start %s download", filename)
 	defer common.Timeline("done %s download", filename)
 
+	if settings.Global.Offline() {
+		return settings.OfflineError(fmt.Sprintf("download %q", url))
+	}
+
 	if pathlib.Exists(filename) {
 		err := os.Remove(filename)
 		if err != nil {
@@ -1,11 +1,16 @@
 package cloud_test
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/joshyorko/rcc/cloud"
+	"github.com/joshyorko/rcc/common"
 	"github.com/joshyorko/rcc/hamlet"
+	"github.com/joshyorko/rcc/settings"
 )
 
 func TestCannotCreateClientForBadEndpoint(t *testing.T) {
@@ -46,3 +51,35 @@ func TestCanEnsureHttps(t *testing.T) {
 	must_be.Nil(err)
 	must_be.Equal(special, output)
 }
+
+func TestOfflineModeFailsFastWithoutNetwork(t *testing.T) {
+	must_be, wont_be := hamlet.Specifications(t)
+
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		hits += 1
+	}))
+	defer server.Close()
+
+	defer func(original bool) {
+		common.Offline = original
+	}(common.Offline)
+	common.Offline = true
+
+	sut, err := cloud.NewUnsafeClient(server.URL)
+	must_be.Nil(err)
+	response := sut.Get(sut.NewRequest("/"))
+	must_be.Equal(9003, response.Status)
+	wont_be.Nil(response.Err)
+	must_be.True(strings.Contains(response.Err.Error(), "offline mode"))
+
+	err = cloud.Download(server.URL, filepath.Join(t.TempDir(), "download"))
+	wont_be.Nil(err)
+	must_be.True(strings.Contains(err.Error(), "offline mode"))
+
+	client := &http.Client{Transport: settings.Global.ConfiguredHttpTransport()}
+	_, err = client.Get(server.URL)
+	wont_be.Nil(err)
+	must_be.True(strings.Contains(err.Error(), "offline mode"))
+	must_be.Equal(0, hits)
+}
@@ -60,7 +60,7 @@ func sendMetric(metricsHost, kind, name, value string) {
 }
 
 func BackgroundMetric(kind, name, value string) {
-	if common.WarrantyVoided() {
+	if common.WarrantyVoided() || settings.Global.Offline() {
 		return
 	}
 	metricsHost := settings.Global.TelemetryURL()
@@ -98,6 +98,11 @@ func BatchMetric(filename string) error {
 		Code: 999,
 	}
 
+	if settings.Global.Offline() {
+		status.Warning = "Offline mode."
+		return stdoutDump(nil, status)
+	}
+
 	metricsHost := settings.Global.TelemetryURL()
 	if len(metricsHost) < 8 {
 		status.Warning = "No metrics host."
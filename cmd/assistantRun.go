@@ -129,6 +129,7 @@ func init() {
 	assistantRunCmd.MarkFlagRequired("workspace")
 	assistantRunCmd.Flags().StringVarP(&assistantId, "assistant", "a", "", "Assistant id to execute.")
 	assistantRunCmd.MarkFlagRequired("assistant")
+	assistantRunCmd.Flags().BoolVarP(&refreshTokens, "refresh-tokens", "", false, "Keep renewing workspace tokens during long runs, and publish them in file pointed by RC_API_TOKEN_FILE.")
 	assistantRunCmd.Flags().StringVarP(&copyDirectory, "copy", "c", "", "Location to copy changed artifacts from run (optional).")
 	assistantRunCmd.Flags().StringVarP(&common.HolotreeSpace, "space", "s", "user", "Client specific name to identify this environment.")
 }
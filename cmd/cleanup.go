@@ -27,6 +27,11 @@ After cleanup, they will not be available anymore.`,
 		if common.DebugFlag() {
 			defer common.Stopwatch("Env cleanup lasted").Report()
 		}
+		if !dryFlag && (allFlag || cachesFlag) {
+			pretty.Guard(pretty.ConfirmTyped(confirmationsDisabled(), "cleanup", "This removes all environments or caches and cannot be undone."), 2, "Cleanup was cancelled.")
+		} else if !dryFlag && (quickFlag || micromambaFlag || downloadsFlag) {
+			pretty.Guard(confirmed("Really do bulk cleanup?"), 2, "Cleanup was cancelled.")
+		}
 		err := conda.Cleanup(daysOption, dryFlag, quickFlag, allFlag, micromambaFlag, downloadsFlag, noCompressFlag, cachesFlag)
 		if err != nil {
 			pretty.Exit(1, "Error: %v", err)
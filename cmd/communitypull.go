@@ -19,9 +19,18 @@ var (
 
 var communityPullCmd = &cobra.Command{
 	Use:   "pull",
-	Short: "Pull a robot from URL or community sources.",
-	Long:  "Pull a robot from URL or community sources.",
-	Args:  cobra.ExactArgs(1),
+	Short: "Pull a robot from URL, community sources, or rccremote.",
+	Long: `Pull a robot from URL, community sources, or rccremote.
+
+Robot packages served by rccremote (started with -robots option) are pulled
+with "rcc-remote://<domain>/<name>" reference (newest version), or with
+"rcc-remote://<domain>/<name>/<version>" (exact version). Server address is
+taken from RCC_REMOTE_ORIGIN, and RCC_REMOTE_AUTHORIZATION is used as token.`,
+	Example: `
+  rcc pull github.com/robocorp/template-python-browser
+  rcc pull rcc-remote://team-a/invoice-bot --directory invoice-bot
+  rcc pull rcc-remote://team-a/invoice-bot/1.2.0 --directory invoice-bot`,
+	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		if common.DebugFlag() {
 			defer common.Stopwatch("Pull lasted").Report()
@@ -31,6 +40,18 @@ var communityPullCmd = &cobra.Command{
 		defer os.Remove(zipfile)
 		common.Debug("Using temporary zipfile at %v", zipfile)
 
+		if operations.IsRemoteRobot(args[0]) {
+			robot, err := operations.ParseRemoteRobot(args[0])
+			pretty.Guard(err == nil, 2, "%v", err)
+			version, err := operations.DownloadRemoteRobot(common.RccRemoteOrigin(), robot, zipfile)
+			pretty.Guard(err == nil, 3, "%v", err)
+			err = operations.Unzip(directory, zipfile, true, false, true)
+			pretty.Guard(err == nil, 1, "Error: %v", err)
+			common.Log("Pulled robot %s version %s into %q.", robot.Name, version, directory)
+			pretty.Ok()
+			return
+		}
+
 		var err error
 		branches := []string{branch, "master", "trunk", "main"}
 
@@ -3,8 +3,6 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"path/filepath"
-	"strings"
 
 	"github.com/joshyorko/rcc/common"
 	"github.com/joshyorko/rcc/operations"
@@ -22,16 +20,11 @@ var (
 )
 
 func profileMap() map[string]string {
-	pattern := common.ExpandPath(filepath.Join(common.Product.Home(), "profile_*.yaml"))
-	found, err := filepath.Glob(pattern)
+	found, err := settings.ImportedProfiles()
 	pretty.Guard(err == nil, 1, "Error while searching profiles: %v", err)
 	profiles := make(map[string]string)
-	for _, name := range found {
-		profile := settings.Profile{}
-		err = profile.LoadFrom(name)
-		if err == nil {
-			profiles[profile.Name] = profile.Description
-		}
+	for _, profile := range found {
+		profiles[profile.Name] = profile.Description
 	}
 	return profiles
 }
@@ -56,8 +49,7 @@ func listProfiles() {
 }
 
 func profileFullPath(name string) string {
-	filename := fmt.Sprintf("profile_%s.yaml", strings.ToLower(name))
-	return common.ExpandPath(filepath.Join(common.Product.Home(), filename))
+	return settings.ProfileFilename(name)
 }
 
 func loadNamedProfile(name string) *settings.Profile {
@@ -116,7 +108,7 @@ var configureRemoveCmd = &cobra.Command{
 		}
 		profiles := profileMap()
 		description, ok := profiles[profileName]
-		pretty.Guard(ok, 2, "No match for profile with name %q.", profileName)
+		pretty.GuardCoded(ok, 2, common.ErrProfileNotFound, "No match for profile with name %q.", profileName)
 		fullpath := profileFullPath(profileName)
 
 		common.Log("Trying to remove profile: %s %q [%s].", profileName, description, fullpath)
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/joshyorko/rcc/settings"
+)
+
+// confirmationsDisabled is true unless confirmations were asked with
+// --confirm, so that scripts run from terminal never stop at prompt.
+func confirmationsDisabled() bool {
+	return !confirmFlag || settings.Global.NoConfirm()
+}
+
+func confirmed(form string, details ...interface{}) bool {
+	return pretty.Confirm(confirmationsDisabled(), form, details...)
+}
+
+func confirmedDeletion(kind string, names []string) bool {
+	if len(names) == 0 {
+		return true
+	}
+	common.Log("Following %s will be deleted:", kind)
+	for _, name := range names {
+		common.Log("- %s", name)
+	}
+	expected := names[0]
+	if len(names) > 1 {
+		expected = fmt.Sprintf("%d %s", len(names), kind)
+	}
+	return pretty.ConfirmTyped(confirmationsDisabled(), expected, "This cannot be undone.")
+}
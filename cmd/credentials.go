@@ -15,14 +15,20 @@ import (
 )
 
 var (
-	deleteCredentialsFlag bool
+	deleteCredentialsFlag  bool
+	migrateCredentialsFlag bool
 )
 
 var credentialsCmd = &cobra.Command{
 	Use:   "credentials [credentials]",
 	Short: fmt.Sprintf("Manage %s Control Room API credentials.", common.Product.Name()),
-	Long:  fmt.Sprintf("Manage %s Control Room API credentials for later use.", common.Product.Name()),
-	Args:  cobra.MaximumNArgs(1),
+	Long: fmt.Sprintf(`Manage %s Control Room API credentials for later use.
+
+Secrets are stored in OS keychain (Keychain on macOS, Credential Manager on
+Windows, Secret Service on Linux) when available, and otherwise in encrypted
+vault file under ROBOCORP_HOME. Store can be forced with RCC_CREDENTIALS_STORE
+environment variable (keychain, file, or plain).`, common.Product.Name()),
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		if common.DebugFlag() {
 			defer common.Stopwatch("Credentials query lasted").Report()
@@ -32,6 +38,13 @@ var credentialsCmd = &cobra.Command{
 			credentials = strings.TrimSpace(args[0])
 		}
 		show := len(credentials) == 0
+		if show && migrateCredentialsFlag {
+			migrated, err := operations.MigrateCredentials()
+			pretty.Guard(err == nil, 2, "Error: %v", err)
+			common.Log("Migrated %d account secret(s) out of plain text configuration.", migrated)
+			pretty.Ok()
+			return
+		}
 		if show && verifiedFlag {
 			operations.VerifyAccounts(forceFlag)
 		}
@@ -83,6 +96,7 @@ func init() {
 	configureCmd.AddCommand(credentialsCmd)
 
 	credentialsCmd.Flags().BoolVarP(&deleteCredentialsFlag, "delete", "", false, "Delete this account and corresponding Control Room credentials! DANGER!")
+	credentialsCmd.Flags().BoolVarP(&migrateCredentialsFlag, "migrate", "", false, "Move plain text secrets of existing accounts into OS keychain (or encrypted vault file).")
 	credentialsCmd.Flags().BoolVarP(&defaultFlag, "default", "d", false, "Set this as the default account.")
 	credentialsCmd.Flags().BoolVarP(&jsonFlag, "json", "j", false, "Output in JSON format.")
 	credentialsCmd.Flags().BoolVarP(&verifiedFlag, "verified", "v", false, "Updates the verified timestamp, if the credentials are still active.")
@@ -23,10 +23,13 @@ var diagnosticsCmd = &cobra.Command{
 		if common.DebugFlag() {
 			defer common.Stopwatch("Diagnostic run lasted").Report()
 		}
-		_, err := operations.ProduceDiagnostics(fileOption, robotOption, jsonFlag, productionFlag, quickFilterFlag || common.WarrantyVoided())
+		result, err := operations.ProduceDiagnostics(fileOption, robotOption, jsonFlag, productionFlag, quickFilterFlag || common.WarrantyVoided())
 		if err != nil {
 			pretty.Exit(1, "Error: %v", err)
 		}
+		if len(tableExport) > 0 {
+			exportTable(operations.DiagnosticsTable(result), "Diagnostics")
+		}
 		pretty.Ok()
 	},
 }
@@ -40,4 +43,5 @@ func init() {
 	diagnosticsCmd.Flags().StringVarP(&fileOption, "file", "f", "", "Save output into a file.")
 	diagnosticsCmd.Flags().StringVarP(&robotOption, "robot", "r", "", "Full path to 'robot.yaml' configuration file. [optional]")
 	diagnosticsCmd.Flags().BoolVarP(&productionFlag, "production", "p", false, "Checks for production level robots. [optional]")
+	diagnosticsCmd.Flags().StringVarP(&tableExport, "export", "", "", "Also export checks into markdown file (or CSV file, when name ends with .csv) for sharing in tickets.")
 }
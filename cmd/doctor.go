@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pretty"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	doctorFixFlag bool
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Run diagnostics and fix known local problems.",
+	Long: `Run quick diagnostics and, for each known failure category (lockfile
+permissions, long path support, shared holotree directories, missing
+micromamba, stale temp files), show or apply an automated fix. After
+fixes, diagnostics are run again and a before/after summary is shown.
+
+Without --fix, only suggested fixes are shown.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if common.DebugFlag() {
+			defer common.Stopwatch("Doctor run lasted").Report()
+		}
+		findings, err := operations.RunDoctor(doctorFixFlag)
+		if err != nil {
+			pretty.Exit(1, "Error: %v", err)
+		}
+		operations.DoctorReport(findings, jsonFlag)
+		for _, finding := range findings {
+			pretty.Guard(!doctorFixFlag || finding.Applied, 2, "Some fixes failed, see summary above.")
+		}
+		pretty.Ok()
+	},
+}
+
+func init() {
+	configureCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(doctorCmd)
+
+	doctorCmd.Flags().BoolVarP(&jsonFlag, "json", "j", false, "Output summary in JSON format.")
+	doctorCmd.Flags().BoolVarP(&doctorFixFlag, "fix", "", false, "Apply suggested fixes, and verify them afterwards.")
+}
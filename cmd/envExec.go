@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/conda"
+	"github.com/joshyorko/rcc/journal"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/joshyorko/rcc/shell"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	envCondaFiles []string
+)
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Group of commands for using holotree environments without robot.",
+	Long: `Group of commands for using holotree environments directly from conda.yaml
+files, without requiring robot project (robot.yaml) around them.`,
+}
+
+var envExecCmd = &cobra.Command{
+	Use:     "exec -- command [arguments]",
+	Aliases: []string{"inject", "run"},
+	Short:   "Run any command inside holotree space created from conda.yaml file(s).",
+	Long: `Run any command inside holotree space created from conda.yaml file(s), without
+requiring robot.yaml. Space is created (or reused) as needed, and command gets
+same environment variables (including PATH) as robot tasks would get.
+
+Command is run in current working directory, and its exit code becomes exit
+code of rcc.`,
+	Example: `
+  rcc env exec -c conda.yaml -- python script.py
+  rcc env exec -c conda.yaml -c extras.yaml --space analysis -- jupyter lab
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		defer journal.BuildEventStats("envexec")
+		if common.DebugFlag() {
+			defer common.Stopwatch("Env exec command lasted").Report()
+		}
+		pretty.Guard(len(envCondaFiles) > 0, 1, "At least one conda.yaml file must be given with --conda option.")
+		for _, filename := range envCondaFiles {
+			pretty.Guard(pathlib.IsFile(filename), 1, "Environment file %q does not exist.", filename)
+		}
+		if holotreeForce {
+			pretty.Guard(confirmed("Force will rebuild environment from scratch. Continue?"), 2, "Forced environment rebuild was cancelled.")
+		}
+		env := holotreeExpandEnvironment(envCondaFiles, "", environmentFile, "", 0, holotreeForce, common.DevDependencies)
+		executable, ok := pathlib.EnvironmentPath(env).Which(args[0], conda.FileExtensions)
+		pretty.Guard(ok, 6, "Cannot find command %q from environment PATH.", args[0])
+		common.Debug("Executing %q with arguments %q inside space %q.", executable, args[1:], common.HolotreeSpace)
+		task := append([]string{executable}, args[1:]...)
+		code, err := shell.New(env, ".", task...).Transparent()
+		if code != 0 {
+			pretty.Exit(code, "Command %q failed with exit code %d, reason: %v", args[0], code, err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(envCmd)
+	envCmd.AddCommand(envExecCmd)
+
+	envExecCmd.Flags().StringArrayVarP(&envCondaFiles, "conda", "c", nil, "Full path to conda.yaml environment file(s) to use (repeatable, merged in given order).")
+	envExecCmd.Flags().StringVarP(&environmentFile, "environment", "e", "", "Full path to 'env.json' development environment data file. <optional>")
+	envExecCmd.Flags().StringVarP(&common.HolotreeSpace, "space", "s", "user", "Client specific name to identify this environment.")
+	envExecCmd.Flags().BoolVarP(&holotreeForce, "force", "f", false, "Force environment creation with refresh.")
+	envExecCmd.Flags().BoolVarP(&common.DevDependencies, "devdeps", "", false, "Include dev-dependencies from the `package.yaml` file in the environment (only valid when dealing with a `package.yaml` file).")
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pretty"
+
+	"github.com/spf13/cobra"
+)
+
+func explainErrorCode(code string) {
+	found, ok := common.LookupErrorCode(code)
+	pretty.Guard(ok, 1, "Unknown error code %q. Use \"rcc explain\" to list all known codes.", code)
+	docs := common.ErrorDocsLink(found.Docs)
+	if jsonFlag {
+		output := *found
+		output.Docs = docs
+		body, err := json.MarshalIndent(output, "", "  ")
+		pretty.Guard(err == nil, 2, "Error while converting error code: %v", err)
+		common.Stdout("%s\n", body)
+		return
+	}
+	common.Stdout("%s%s: %s%s\n\n", pretty.Bold, found.Code, found.Title, pretty.Reset)
+	common.Stdout("%s\n\n", found.Description)
+	common.Stdout("Hint: %s\n", found.Hint)
+	common.Stdout("Docs: %s\n", docs)
+}
+
+func listErrorCodes() {
+	codes := common.ErrorCodes()
+	if jsonFlag {
+		body, err := json.MarshalIndent(codes, "", "  ")
+		pretty.Guard(err == nil, 2, "Error while converting error codes: %v", err)
+		common.Stdout("%s\n", body)
+		return
+	}
+	tabbed := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+	tabbed.Write([]byte("Code\tTitle\n"))
+	tabbed.Write([]byte("----\t-----\n"))
+	for _, code := range codes {
+		tabbed.Write([]byte(fmt.Sprintf("%s\t%s\n", code.Code, code.Title)))
+	}
+	tabbed.Flush()
+}
+
+var explainCmd = &cobra.Command{
+	Use:   "explain [code]",
+	Short: "Explain rcc error code, like E2104.",
+	Long: `Explain stable error code shown in rcc failure messages. Codes are grouped
+by area: E1xxx environment, E2xxx holotree, E3xxx network, E4xxx robot, and
+E5xxx configuration. Without code, lists all known error codes.`,
+	Example: `
+  rcc explain E2104
+  rcc explain --json`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			listErrorCodes()
+			return
+		}
+		explainErrorCode(args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+	explainCmd.Flags().BoolVarP(&jsonFlag, "json", "j", false, "Output in JSON format.")
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/conda"
+	"github.com/joshyorko/rcc/journal"
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/spf13/cobra"
+)
+
+var (
+	activateShell  string
+	activateTarget string
+)
+
+var holotreeActivateCmd = &cobra.Command{
+	Use:   "activate conda.yaml*",
+	Short: "Create activation script, that sets up holotree space into current shell.",
+	Long: `Create (or refresh) holotree space of robot (or conda.yaml files), and output
+activation script for selected shell, which sets PATH and other variables of
+that space into current shell, when evaluated or sourced. Like with
+virtualenvs, prompt is prefixed with space name, and "deactivate" restores
+previous values (except in cmd, where new window is needed).
+
+Supported shells are bash, zsh, fish, powershell, and cmd. Default is guessed
+from SHELL (and on Windows from PSModulePath). With --write, script is
+written into given file instead of stdout.`,
+	Example: `
+  eval "$(rcc holotree activate -r robot.yaml)"
+  rcc holotree activate -r robot.yaml --shell fish | source
+  rcc holotree activate -r robot.yaml --shell powershell --write activate.ps1
+  rcc holotree activate -r robot.yaml --shell cmd --write activate.bat`,
+	Run: func(cmd *cobra.Command, args []string) {
+		defer journal.BuildEventStats("activate")
+		if common.DebugFlag() {
+			defer common.Stopwatch("Holotree activate command lasted").Report()
+		}
+		if len(activateShell) == 0 {
+			activateShell = operations.DefaultActivationShell(conda.IsWindows())
+		}
+		pretty.Guard(slices.Contains(operations.ActivationShells, activateShell), 1, "Unknown shell %q, expected one of: %s.", activateShell, strings.Join(operations.ActivationShells, ", "))
+		env := holotreeExpandEnvironment(args, robotFile, environmentFile, "", validityTime, false, common.DevDependencies)
+		content, err := operations.ActivationScript(activateShell, common.HolotreeSpace, env)
+		pretty.Guard(err == nil, 2, "%v", err)
+		if len(activateTarget) == 0 {
+			common.Stdout("%s\n", content)
+			return
+		}
+		err = pathlib.WriteFile(activateTarget, []byte(content+"\n"), 0o600)
+		pretty.Guard(err == nil, 3, "Could not write activation script to %q, reason: %v", activateTarget, err)
+		common.Log("Wrote %s activation script of space %q to %q.", activateShell, common.HolotreeSpace, activateTarget)
+		pretty.Ok()
+	},
+}
+
+func init() {
+	holotreeCmd.AddCommand(holotreeActivateCmd)
+	holotreeActivateCmd.Flags().StringVarP(&environmentFile, "environment", "e", "", "Full path to 'env.json' development environment data file. <optional>")
+	holotreeActivateCmd.Flags().StringVarP(&robotFile, "robot", "r", "robot.yaml", "Full path to 'robot.yaml' configuration file. <optional>")
+	holotreeActivateCmd.Flags().StringVarP(&common.HolotreeSpace, "space", "s", "user", "Client specific name to identify this environment.")
+	holotreeActivateCmd.Flags().StringVarP(&activateShell, "shell", "", "", "Shell to create script for: bash, zsh, fish, powershell, or cmd. Default is guessed.")
+	holotreeActivateCmd.Flags().StringVarP(&activateTarget, "write", "", "", "Write activation script into given file, instead of stdout.")
+	holotreeActivateCmd.Flags().BoolVarP(&common.DevDependencies, "devdeps", "", false, "Include dev-dependencies from the `package.yaml` file in the environment (only valid when dealing with a `package.yaml` file).")
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/conda"
 	"github.com/joshyorko/rcc/htfs"
 	"github.com/joshyorko/rcc/operations"
 	"github.com/joshyorko/rcc/pretty"
@@ -23,6 +24,10 @@ func holotreeExpandBlueprint(userFiles []string, packfile string) map[string]int
 
 	result["hash"] = common.BlueprintHash(holotreeBlueprint)
 	result["exist"] = tree.HasBlueprint(holotreeBlueprint)
+	result["gpu"] = ""
+	if requirement := conda.GpuRequirementOf(holotreeBlueprint); requirement != nil {
+		result["gpu"] = requirement.String()
+	}
 
 	return result
 }
@@ -44,6 +49,9 @@ var holotreeBlueprintCmd = &cobra.Command{
 			fmt.Println(out)
 		} else {
 			common.Log("Blueprint %q is available: %v", status["hash"], status["exist"])
+			if gpu := status["gpu"]; gpu != "" {
+				common.Log("Blueprint %q requires %s.", status["hash"], gpu)
+			}
 		}
 	},
 }
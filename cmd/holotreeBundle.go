@@ -328,7 +328,7 @@ func processBundleEnvs(zr *zip.Reader, bundleFilename string, force bool, restor
 		pretty.Note("%d/%d: Blueprint hash for %q is %s", at+1, total, envName, hash)
 
 		if restore {
-			label, _, err := htfs.NewEnvironment(tmpConda, "", true, force, operations.PullCatalog)
+			label, _, err := htfs.NewEnvironment(tmpConda, "", nil, true, force, operations.PullCatalog)
 			if err != nil {
 				result.Error = fmt.Sprintf("Failed to build environment: %v", err)
 				results = append(results, result)
@@ -11,6 +11,7 @@ import (
 
 	"github.com/joshyorko/rcc/common"
 	"github.com/joshyorko/rcc/htfs"
+	"github.com/joshyorko/rcc/operations"
 	"github.com/joshyorko/rcc/pathlib"
 	"github.com/joshyorko/rcc/pretty"
 	"github.com/joshyorko/rcc/set"
@@ -29,28 +30,7 @@ func megas(bytes uint64) uint64 {
 }
 
 func catalogUsedStats() map[string]int {
-	result := make(map[string]int)
-	handle, err := os.Open(common.HololibUsageLocation())
-	if err != nil {
-		return result
-	}
-	defer handle.Close()
-	entries, err := handle.Readdir(-1)
-	if err != nil {
-		return result
-	}
-	for _, entry := range entries {
-		name := filepath.Base(entry.Name())
-		tail := filepath.Ext(name)
-		size := len(name) - len(tail)
-		base := name[:size]
-		days := common.DayCountSince(entry.ModTime())
-		previous, ok := result[base]
-		if !ok || days < previous {
-			result[base] = days
-		}
-	}
-	return result
+	return operations.CatalogIdleDays()
 }
 
 func identityContent(catalog *htfs.Root) string {
@@ -72,6 +52,7 @@ func identityContentLines(catalog *htfs.Root) []string {
 
 func jsonCatalogDetails(roots []*htfs.Root, topN int) {
 	used := catalogUsedStats()
+	verifications := htfs.LoadCatalogVerifications(common.HololibLocation())
 	holder := make(map[string]map[string]interface{})
 	for _, catalog := range roots {
 		lastUse, ok := used[catalog.Blueprint]
@@ -101,6 +82,10 @@ func jsonCatalogDetails(roots []*htfs.Root, topN int) {
 		age, _ := pathlib.DaysSinceModified(catalog.Source())
 		data["age_in_days"] = age
 		data["days_since_last_use"] = lastUse
+		verification, ok := verifications[filepath.Base(catalog.Source())]
+		if ok {
+			data["verification"] = verification
+		}
 	}
 	nice, err := json.MarshalIndent(holder, "", "  ")
 	pretty.Guard(err == nil, 2, "%s", err)
@@ -158,19 +143,102 @@ func listCatalogDetails(roots []*htfs.Root, topN int) {
 	tabbed.Flush()
 }
 
+func formatted(form string) func(any) string {
+	return func(value any) string {
+		return fmt.Sprintf(form, value)
+	}
+}
+
+func catalogListing(roots []*htfs.Root) *pretty.Table {
+	used := catalogUsedStats()
+	table := pretty.NewTable(
+		&pretty.TableColumn{Name: "blueprint", Title: "Blueprint"},
+		&pretty.TableColumn{Name: "platform", Title: "Platform"},
+		&pretty.TableColumn{Name: "dirs", Title: "Dirs  ", Format: formatted("% 6d")},
+		&pretty.TableColumn{Name: "files", Title: "Files  ", Format: formatted("% 7d")},
+		&pretty.TableColumn{Name: "size", Title: "Size   ", Format: func(value any) string {
+			return fmt.Sprintf("% 6dM", megas(value.(uint64)))
+		}},
+		&pretty.TableColumn{Name: "relocations", Title: "Relocate", Format: formatted("% 8d")},
+		&pretty.TableColumn{Name: "identity", Title: "identity.yaml (gzipped blob inside hololib)"},
+		&pretty.TableColumn{Name: "holotree", Title: "Holotree path"},
+		&pretty.TableColumn{Name: "age", Title: "Age (days)", Format: formatted("%10d")},
+		&pretty.TableColumn{Name: "idle", Title: "Idle (days)", Format: formatted("%11d")},
+		&pretty.TableColumn{Name: "verified", Title: "Verified"},
+	)
+	verifications := htfs.LoadCatalogVerifications(common.HololibLocation())
+	for _, catalog := range roots {
+		lastUse, ok := used[catalog.Blueprint]
+		if !ok {
+			catalog.Touch()
+			lastUse = -1
+		}
+		stats, err := catalog.Stats()
+		pretty.Guard(err == nil, 1, "Could not get stats for %s, reason: %s", catalog.Blueprint, err)
+		days, _ := pathlib.DaysSinceModified(catalog.Source())
+		table.Add(pretty.TableRow{
+			"blueprint":   catalog.Blueprint,
+			"platform":    catalog.Platform,
+			"dirs":        stats.Directories,
+			"files":       stats.Files,
+			"size":        stats.Bytes,
+			"relocations": stats.Relocations,
+			"identity":    stats.Identity,
+			"holotree":    catalog.HolotreeBase(),
+			"age":         days,
+			"idle":        lastUse,
+			"verified":    htfs.VerificationLabel(verifications[filepath.Base(catalog.Source())]),
+			"root":        catalog,
+		})
+	}
+	return table
+}
+
+func tableCatalogs(table *pretty.Table) []*htfs.Root {
+	result := make([]*htfs.Root, 0, len(table.Rows))
+	for _, row := range table.Rows {
+		result = append(result, row["root"].(*htfs.Root))
+	}
+	return result
+}
+
 var holotreeCatalogsCmd = &cobra.Command{
 	Use:   "catalogs",
 	Short: "List native and imported holotree catalogs.",
-	Long:  "List native and imported holotree catalogs.",
+	Long: `List native and imported holotree catalogs.
+
+Listing can be filtered, sorted, and limited to selected columns, same way as
+in "rcc holotree list" command.
+
+Available columns: blueprint, platform, dirs, files, size, relocations,
+identity, holotree, age, idle, verified
+
+Verified column tells how blobs of pulled or imported catalog were verified
+on arrival (full, spot, or trust), and is "-" for catalogs built locally.
+
+Examples:
+  rcc holotree catalogs --filter idle>30d --sort=-size
+  rcc holotree catalogs --filter size>1G --columns blueprint,size,idle --csv`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if common.DebugFlag() {
 			defer common.Stopwatch("Holotree catalogs command lasted").Report()
 		}
 		_, roots := htfs.LoadCatalogs()
-		if jsonFlag {
-			jsonCatalogDetails(roots, topSizes)
-		} else {
-			listCatalogDetails(roots, topSizes)
+		if len(catalogPlatform) > 0 {
+			roots = roots.ForPlatform(catalogPlatform)
+		}
+		table := catalogListing(roots)
+		applyTableControls(table, "Catalogs")
+		switch {
+		case jsonFlag:
+			jsonCatalogDetails(tableCatalogs(table), topSizes)
+		case showIdentityYaml || topSizes > 0:
+			listCatalogDetails(tableCatalogs(table), topSizes)
+		case csvFlag:
+			err := table.WriteCSV(os.Stdout)
+			pretty.Guard(err == nil, 1, "Could not write CSV, reason: %v", err)
+		default:
+			table.WriteText(os.Stderr)
 		}
 		pretty.Ok()
 	},
@@ -180,5 +248,7 @@ func init() {
 	holotreeCmd.AddCommand(holotreeCatalogsCmd)
 	holotreeCatalogsCmd.Flags().BoolVarP(&jsonFlag, "json", "j", false, "Output in JSON format")
 	holotreeCatalogsCmd.Flags().BoolVarP(&showIdentityYaml, "identity", "i", false, "Show identity.yaml in catalog context.")
+	holotreeCatalogsCmd.Flags().StringVarP(&catalogPlatform, "platform", "", "", "Only list catalogs for given platform, like linux_amd64 or windows_amd64.")
 	holotreeCatalogsCmd.Flags().IntVarP(&topSizes, "top", "t", 0, "Show top N sized files from catalog")
+	addTableFlags(holotreeCatalogsCmd)
 }
@@ -2,12 +2,14 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 
 	"github.com/joshyorko/rcc/anywork"
 	"github.com/joshyorko/rcc/common"
 	"github.com/joshyorko/rcc/fail"
 	"github.com/joshyorko/rcc/htfs"
+	"github.com/joshyorko/rcc/operations"
 	"github.com/joshyorko/rcc/pathlib"
 	"github.com/joshyorko/rcc/pretty"
 	"github.com/spf13/cobra"
@@ -15,6 +17,7 @@ import (
 
 var (
 	checkRetries int
+	checkOutput  string
 )
 
 func checkHolotreeIntegrity() (err error) {
@@ -51,6 +54,12 @@ func checkHolotreeIntegrity() (err error) {
 			purge[catalog] = true
 		}
 	}
+	purged := make([]string, 0, len(purge))
+	for k := range purge {
+		purged = append(purged, filepath.Base(k))
+	}
+	err = htfs.ExpandCatalogs(purged)
+	fail.On(err != nil, "%s", err)
 	redo := false
 	for k := range purge {
 		fmt.Println("Purge catalog:", k)
@@ -80,12 +89,35 @@ loop:
 	pretty.Guard(err == nil, 1, "%s", err)
 }
 
+func sarifCatalogCheck() {
+	healths := operations.CheckCatalogHealth(htfs.AllCatalogNames(), nil)
+	status := operations.CatalogDiagnostics(healths)
+	err := operations.WriteSarif(os.Stdout, operations.DiagnosticsAsSarif(status, ""))
+	pretty.Guard(err == nil, 2, "%v", err)
+	fatal, fail, _, _ := status.Counts()
+	pretty.Guard(fatal+fail == 0, 3, "%d of %d catalogs are broken.", fatal+fail, len(healths))
+	pretty.Ok()
+}
+
 var holotreeCheckCmd = &cobra.Command{
-	Use:     "check",
-	Short:   "Check holotree library integrity.",
-	Long:    "Check holotree library integrity.",
+	Use:   "check",
+	Short: "Check holotree library integrity.",
+	Long: `Check holotree library integrity.
+
+With "--output sarif", catalogs are only checked (nothing is purged), and
+result is written to stdout as SARIF log, for CI systems to show broken
+catalogs as annotations (rule RCC2040). Exit code is non-zero, when some
+catalog is broken.`,
 	Aliases: []string{"chk"},
 	Run: func(cmd *cobra.Command, args []string) {
+		switch checkOutput {
+		case "text":
+		case "sarif":
+			sarifCatalogCheck()
+			return
+		default:
+			pretty.Exit(1, "Unknown output %q, use 'text' or 'sarif'.", checkOutput)
+		}
 		repeat := 1
 		if checkRetries > 0 {
 			repeat += checkRetries
@@ -96,6 +128,7 @@ var holotreeCheckCmd = &cobra.Command{
 }
 
 func init() {
+	holotreeCheckCmd.Flags().StringVarP(&checkOutput, "output", "", "text", "Output format, either 'text' or 'sarif' (for CI annotations).")
 	holotreeCheckCmd.Flags().IntVarP(&checkRetries, "retries", "r", 1, "How many retries to do in case of failures.")
 	holotreeCmd.AddCommand(holotreeCheckCmd)
 }
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/htfs"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/joshyorko/rcc/set"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	compactController string
+	compactUnused     int
+	compactExpand     string
+	compactList       bool
+)
+
+func compactableCatalogs(controller string, unused int) map[string][]string {
+	result := make(map[string][]string)
+	used := catalogUsedStats()
+	for _, root := range htfs.LoadCatalogInfos() {
+		if !pathlib.IsFile(root.Source()) {
+			continue
+		}
+		if len(controller) > 0 && root.Controller != controller {
+			continue
+		}
+		if idle, ok := used[root.Blueprint]; ok && idle <= unused {
+			continue
+		}
+		name := htfs.SnapshotSetName(root.Controller)
+		result[name] = append(result[name], filepath.Base(root.Source()))
+	}
+	return result
+}
+
+func listSnapshots() {
+	names := htfs.SnapshotNames()
+	common.Stdout("Snapshot sets: %d\n", len(names))
+	for _, name := range names {
+		snapshot, err := htfs.LoadSnapshot(name)
+		if err != nil {
+			pretty.Warning("Snapshot %q: %v", name, err)
+			continue
+		}
+		created := time.Unix(snapshot.Created, 0).Format(time.DateTime)
+		common.Stdout("  %-30s %s  %4d catalogs\n", name, created, len(snapshot.Catalogs))
+		for _, catalog := range snapshot.Names() {
+			common.Stdout("    - %s\n", catalog)
+		}
+	}
+}
+
+var holotreeCompactCmd = &cobra.Command{
+	Use:   "compact",
+	Short: "Compact many small catalogs into snapshot sets.",
+	Long: `Compact many small catalogs into snapshot sets, one set per controller.
+Compacted catalogs are removed from catalog directory, which makes commands
+that load all catalogs faster, but their blueprints still resolve normally,
+since catalog is expanded back from its snapshot set when it is restored.
+Compaction requires explicit --unused threshold (in days).
+
+Examples:
+  rcc holotree compact --unused 7
+  rcc holotree compact --controller rcc.user --unused 30
+  rcc holotree compact --list
+  rcc holotree compact --expand rcc.user`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if common.DebugFlag() {
+			defer common.Stopwatch("Holotree compact command lasted").Report()
+		}
+		if compactList {
+			listSnapshots()
+			return
+		}
+		if len(compactExpand) > 0 {
+			count, err := htfs.ExpandSnapshot(compactExpand)
+			pretty.Guard(err == nil, 2, "%v", err)
+			common.Log("Expanded %d catalogs from snapshot set %q.", count, compactExpand)
+			pretty.Ok()
+			return
+		}
+		pretty.Guard(compactUnused > 0, 1, "Compaction needs explicit --unused threshold (days), like --unused 30.")
+		groups := compactableCatalogs(compactController, compactUnused)
+		total := 0
+		for _, name := range set.Keys(groups) {
+			count, err := htfs.CompactCatalogs(name, groups[name])
+			pretty.Guard(err == nil, 3, "%v", err)
+			common.Log("Compacted %d catalogs into snapshot set %q.", count, name)
+			total += count
+		}
+		if total == 0 {
+			pretty.Warning("No catalogs to compact.")
+		}
+		pretty.Ok()
+	},
+}
+
+func init() {
+	holotreeCmd.AddCommand(holotreeCompactCmd)
+	holotreeCompactCmd.Flags().StringVarP(&compactController, "controller", "", "", "Only compact catalogs created by this controller.")
+	holotreeCompactCmd.Flags().IntVarP(&compactUnused, "unused", "", 0, "Only compact catalogs which have been idle/unused more than given days (required when compacting).")
+	holotreeCompactCmd.Flags().StringVarP(&compactExpand, "expand", "", "", "Expand named snapshot set back into individual catalogs.")
+	holotreeCompactCmd.Flags().BoolVarP(&compactList, "list", "l", false, "List existing snapshot sets.")
+}
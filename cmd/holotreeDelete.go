@@ -55,12 +55,16 @@ func allUnusedSpaces(limit int) []string {
 }
 
 func deleteByPartialIdentity(partials []string) {
-	_, roots := htfs.LoadCatalogs()
+	roots := htfs.LoadCatalogInfos()
 	var note string
 	if dryFlag {
 		note = "[dry run] "
 	}
-	for _, label := range roots.FindEnvironments(partials) {
+	labels := roots.FindEnvironments(partials)
+	if !dryFlag {
+		pretty.Guard(confirmedDeletion("spaces", labels), 2, "Deleting spaces was cancelled.")
+	}
+	for _, label := range labels {
 		common.Log("%sRemoving %v", note, label)
 		if dryFlag {
 			continue
@@ -12,8 +12,9 @@ import (
 )
 
 var (
-	holozip     string
-	exportRobot string
+	holozip         string
+	exportRobot     string
+	catalogPlatform string
 )
 
 func holotreeExport(catalogs, known []string, archive string) {
@@ -28,20 +29,27 @@ func holotreeExport(catalogs, known []string, archive string) {
 	}
 
 	tree, err := htfs.New()
-	pretty.Guard(err == nil, 2, "%s", err)
+	pretty.GuardCoded(err == nil, 2, common.ErrHololibOpen, "%s", err)
 
 	err = tree.Export(catalogs, known, archive)
-	pretty.Guard(err == nil, 3, "%s", err)
+	pretty.GuardCoded(err == nil, 3, common.ErrCatalogExport, "%s", err)
+}
+
+func platformCatalogs() []string {
+	if len(catalogPlatform) > 0 {
+		return htfs.CatalogsForPlatform(htfs.AllCatalogNames(), catalogPlatform)
+	}
+	return htfs.AllCatalogNames()
 }
 
 func listCatalogs(jsonForm bool) {
 	if jsonForm {
-		nice, err := json.MarshalIndent(htfs.CatalogNames(), "", "  ")
+		nice, err := json.MarshalIndent(platformCatalogs(), "", "  ")
 		pretty.Guard(err == nil, 2, "%s", err)
 		common.Stdout("%s\n", nice)
 	} else {
 		common.Log("Selectable catalogs (you can use substrings):")
-		for _, catalog := range htfs.CatalogNames() {
+		for _, catalog := range platformCatalogs() {
 			common.Log("- %s", catalog)
 		}
 	}
@@ -49,7 +57,7 @@ func listCatalogs(jsonForm bool) {
 
 func selectCatalogs(filters []string) []string {
 	result := make([]string, 0, len(filters))
-	for _, catalog := range htfs.CatalogNames() {
+	for _, catalog := range htfs.AllCatalogNames() {
 		for _, filter := range filters {
 			if strings.Contains(catalog, filter) {
 				result = append(result, catalog)
@@ -64,7 +72,12 @@ func selectCatalogs(filters []string) []string {
 var holotreeExportCmd = &cobra.Command{
 	Use:   "export catalog+",
 	Short: "Export existing holotree catalog and library parts.",
-	Long:  "Export existing holotree catalog and library parts.",
+	Long: `Export existing holotree catalog and library parts.
+
+Every exported library blob is digest verified while it is written, and after
+export verification summary (catalogs, files, bytes, digests verified, skipped
+duplicates, and throughput) is shown. Without catalogs, selectable catalogs
+are listed. With --json, listing or summary is written to stdout as JSON.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if common.DebugFlag() {
 			defer common.Stopwatch("Holotree export command lasted").Report()
@@ -72,14 +85,27 @@ var holotreeExportCmd = &cobra.Command{
 		if len(exportRobot) > 0 {
 			devDependencies := false
 			_, holotreeBlueprint, err := htfs.ComposeFinalBlueprint(nil, exportRobot, devDependencies)
-			pretty.Guard(err == nil, 1, "Blueprint calculation failed: %v", err)
+			pretty.GuardCoded(err == nil, 1, common.ErrBlueprintCompose, "Blueprint calculation failed: %v", err)
 			hash := common.BlueprintHash(holotreeBlueprint)
 			args = append(args, htfs.CatalogName(hash))
 		}
 		if len(args) == 0 {
 			listCatalogs(jsonFlag)
 		} else {
-			holotreeExport(selectCatalogs(args), nil, holozip)
+			selected := selectCatalogs(args)
+			if len(catalogPlatform) > 0 {
+				selected = htfs.CatalogsForPlatform(selected, catalogPlatform)
+				pretty.Guard(len(selected) > 0, 4, "None of selected catalogs were for %q platform.", catalogPlatform)
+			}
+			for _, catalog := range selected {
+				if htfs.CatalogPlatform(catalog) != common.Platform() {
+					pretty.Warning("Exporting catalog %q, which is not for this %q platform.", catalog, common.Platform())
+				}
+			}
+			holotreeExport(selected, nil, holozip)
+			if jsonFlag {
+				transferSummariesAsJson()
+			}
 		}
 		pretty.Ok()
 	},
@@ -89,5 +115,6 @@ func init() {
 	holotreeCmd.AddCommand(holotreeExportCmd)
 	holotreeExportCmd.Flags().StringVarP(&holozip, "zipfile", "z", "hololib.zip", "Name of zipfile to export.")
 	holotreeExportCmd.Flags().BoolVarP(&jsonFlag, "json", "j", false, "Output in JSON format")
+	holotreeExportCmd.Flags().StringVarP(&catalogPlatform, "platform", "", "", "Only export catalogs for given platform, like linux_amd64 or windows_amd64. <optional>")
 	holotreeExportCmd.Flags().StringVarP(&exportRobot, "robot", "r", "", "Full path to 'robot.yaml' configuration file to export as catalog. <optional>")
 }
@@ -1,13 +1,11 @@
 package cmd
 
 import (
-	"fmt"
+	"encoding/json"
 	"net/url"
-	"os"
-	"path/filepath"
 
-	"github.com/joshyorko/rcc/cloud"
 	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/htfs"
 	"github.com/joshyorko/rcc/operations"
 	"github.com/joshyorko/rcc/pathlib"
 	"github.com/joshyorko/rcc/pretty"
@@ -22,16 +20,6 @@ func isUrl(name string) bool {
 	return link.IsAbs() && (link.Scheme == "http" || link.Scheme == "https")
 }
 
-func temporaryDownload(at int, link string) (string, error) {
-	common.Timeline("Download %v", link)
-	zipfile := filepath.Join(pathlib.TempDir(), fmt.Sprintf("hololib%x%x.zip", common.When, at))
-	err := cloud.Download(link, zipfile)
-	if err != nil {
-		return "", err
-	}
-	return zipfile, nil
-}
-
 func reportAllErrors(filename string, errors []error) error {
 	if errors == nil || len(errors) == 0 {
 		return nil
@@ -46,30 +34,68 @@ func reportAllErrors(filename string, errors []error) error {
 	return errors[0]
 }
 
+func transferSummariesAsJson() {
+	nice, err := json.MarshalIndent(htfs.TransferSummaries(), "", "  ")
+	pretty.Guard(err == nil, 6, "Could not create json, reason: %v", err)
+	common.Stdout("%s\n", nice)
+}
+
 var holotreeImportCmd = &cobra.Command{
 	Use:   "import hololib.zip+",
 	Short: "Import one or more hololib.zip files into local hololib.",
-	Long:  "Import one or more hololib.zip files into local hololib.",
-	Args:  cobra.MinimumNArgs(1),
+	Long: `Import one or more hololib.zip files into local hololib.
+
+Sources can also be un-zipped hololib directories (with catalog and library
+subdirectories, for example on SMB or NFS network share). From those, only
+blobs missing from local hololib are copied, and each of them is digest
+verified.
+
+Sources can also be http(s) URLs. Zip files (.zip), and tar streams (.tar,
+.tar.gz or .tgz, and .tar.zst or .tzst) are all imported while downloading,
+without writing whole archive into temporary file first. Archive format is
+selected by URL path suffix, and unknown suffixes are read as zip. Zip entry
+checksums, and (by default) digests of every library blob, are verified
+before blob is accepted into hololib, and catalogs are written only after
+all blobs were imported successfully.
+
+With --verify (or RCC_PULL_VERIFY), arriving blobs can instead be spot-checked
+("spot", about every tenth blob), or trusted without verification ("trust",
+with warning), which is faster on trusted networks. Default is "full". Mode
+used is recorded per catalog, and shown in "rcc holotree catalogs".
+
+After each import, verification summary (catalogs, files, bytes, digests
+verified, skipped duplicates, and throughput) is shown. With --json, same
+summaries are also written to stdout as JSON.`,
+	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		var err error
 
 		if common.DebugFlag() {
 			defer common.Stopwatch("Holotree import command lasted").Report()
 		}
-		for at, filename := range args {
+		guardPullVerify()
+		for _, filename := range args {
 			if isUrl(filename) {
-				filename, err = temporaryDownload(at, filename)
-				pretty.Guard(err == nil, 2, "Could not download %q, reason: %v", filename, err)
-				defer os.Remove(filename)
+				err = operations.ImportFromUrl(filename)
+				pretty.GuardCoded(err == nil, 2, common.ErrCatalogImport, "Could not import %q, reason: %v", filename, err)
+				continue
+			}
+			foreign, err := operations.ForeignCatalogs(filename, common.Platform())
+			pretty.Guard(err == nil, 4, "Could not read %q, reason: %v", filename, err)
+			for _, catalog := range foreign {
+				pretty.Warning("Catalog %q in %q is not for this %q platform.", catalog, filename, common.Platform())
 			}
-			if common.StrictFlag {
+			pretty.GuardCoded(!common.StrictFlag || len(foreign) == 0, 5, common.ErrForeignCatalog, "Refusing to import %d foreign platform catalogs in strict mode.", len(foreign))
+			if common.StrictFlag && !pathlib.IsDir(filename) {
 				errors := operations.VerifyZip(filename, operations.HololibZipShape)
 				err = reportAllErrors(filename, errors)
-				pretty.Guard(err == nil, 3, "Could not verify %q, first reason: %v", filename, err)
+				pretty.GuardCoded(err == nil, 3, common.ErrCatalogImport, "Could not verify %q, first reason: %v", filename, err)
 			}
 			err = operations.ProtectedImport(filename)
-			pretty.Guard(err == nil, 1, "Could not import %q, reason: %v", filename, err)
+			pretty.GuardCoded(err == nil, 1, common.ErrCatalogImport, "Could not import %q, reason: %v", filename, err)
+		}
+		if jsonFlag {
+			transferSummariesAsJson()
 		}
 		pretty.Ok()
 	},
@@ -77,4 +103,6 @@ var holotreeImportCmd = &cobra.Command{
 
 func init() {
 	holotreeCmd.AddCommand(holotreeImportCmd)
+	holotreeImportCmd.Flags().BoolVarP(&jsonFlag, "json", "j", false, "Output verification summaries in JSON format")
+	holotreeImportCmd.Flags().StringVarP(&common.PullVerify, "verify", "", "", "Verification of arriving blobs: full, spot, or trust (default is full, or RCC_PULL_VERIFY).")
 }
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"text/tabwriter"
 	"time"
 
 	"github.com/joshyorko/rcc/common"
@@ -14,52 +13,116 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func whatUsage(space string) (string, string, int) {
-	usefile := fmt.Sprintf("%s.use", space)
-	stat, err := os.Stat(usefile)
+var (
+	spaceDefaultColumns = []string{"identity", "controller", "space", "blueprint", "path", "last-used", "uses"}
+)
+
+func usedTimes(value any) string {
+	uses, ok := value.(int64)
+	if !ok || uses == 0 {
+		return "N/A"
+	}
+	return fmt.Sprintf("%d times", uses)
+}
+
+func spaceUsage(space string) (int64, int64, int) {
+	stat, err := os.Stat(fmt.Sprintf("%s.use", space))
 	if err != nil {
-		return "N/A", "N/A", 0
+		return 0, 0, 0
 	}
-	times := fmt.Sprintf("%d times", stat.Size())
-	delta := time.Now().Sub(stat.ModTime()).Hours() / 24.0
-	when := fmt.Sprintf("%1.0f days ago", delta)
-	return when, times, int(delta)
+	idle := time.Now().Sub(stat.ModTime()).Hours() / 24.0
+	return stat.ModTime().Unix(), stat.Size(), int(idle)
 }
 
-func humaneHolotreeSpaceListing() {
-	tabbed := tabwriter.NewWriter(os.Stderr, 2, 4, 2, ' ', 0)
-	tabbed.Write([]byte("Identity\tController\tSpace\tBlueprint\tFull path\tLast used\tUse count\n"))
-	tabbed.Write([]byte("--------\t----------\t-----\t---------\t---------\t---------\t---------\n"))
-	_, roots := htfs.LoadCatalogs()
-	for _, space := range roots.Spaces() {
-		when, times, _ := whatUsage(space.Path)
-		data := fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s\t%s\n", space.Identity, space.Controller, space.Space, space.Blueprint, space.Path, when, times)
-		tabbed.Write([]byte(data))
+func daysAgo(value any) string {
+	stamp, ok := value.(int64)
+	if !ok || stamp == 0 {
+		return "N/A"
 	}
-	tabbed.Flush()
+	return fmt.Sprintf("%1.0f days ago", time.Now().Sub(time.Unix(stamp, 0)).Hours()/24.0)
 }
 
-func jsonicHolotreeSpaceListing() {
+func spaceListing() *pretty.Table {
+	table := pretty.NewTable(
+		&pretty.TableColumn{Name: "identity", Title: "Identity"},
+		&pretty.TableColumn{Name: "controller", Title: "Controller"},
+		&pretty.TableColumn{Name: "space", Title: "Space"},
+		&pretty.TableColumn{Name: "blueprint", Title: "Blueprint"},
+		&pretty.TableColumn{Name: "path", Title: "Full path"},
+		&pretty.TableColumn{Name: "last-used", Title: "Last used", Format: daysAgo},
+		&pretty.TableColumn{Name: "uses", Title: "Use count", Format: usedTimes},
+		&pretty.TableColumn{Name: "idle", Title: "Idle (days)"},
+		&pretty.TableColumn{Name: "size", Title: "Size", Format: humaneSize},
+		&pretty.TableColumn{Name: "files", Title: "Files"},
+		&pretty.TableColumn{Name: "platform", Title: "Platform"},
+	)
+	if holotreeRefresh {
+		err := htfs.ForgetCatalogIndex()
+		pretty.Guard(err == nil, 1, "Could not refresh catalog index, reason: %v", err)
+	}
+	roots := htfs.LoadCatalogInfos()
+	spaces := roots.Spaces()
+	counter := pretty.NewCounter("Loading spaces", len(spaces))
+	defer counter.Done()
+	for _, space := range spaces {
+		counter.Tick()
+		used, uses, idle := spaceUsage(space.Path)
+		var size, files uint64
+		stats, err := space.Stats()
+		if err == nil {
+			size, files = stats.Bytes, stats.Files
+		}
+		table.Add(pretty.TableRow{
+			"identity":   space.Identity,
+			"controller": space.Controller,
+			"space":      space.Space,
+			"blueprint":  space.Blueprint,
+			"path":       space.Path,
+			"last-used":  used,
+			"uses":       uses,
+			"idle":       idle,
+			"size":       size,
+			"files":      files,
+			"platform":   space.Platform,
+		})
+	}
+	table.Sort("path")
+	err := table.Select(spaceDefaultColumns)
+	pretty.Guard(err == nil, 1, "%v", err)
+	return table
+}
+
+func humaneHolotreeSpaceListing(table *pretty.Table) {
+	if csvFlag {
+		err := table.WriteCSV(os.Stdout)
+		pretty.Guard(err == nil, 1, "Could not write CSV, reason: %v", err)
+	} else {
+		table.WriteText(os.Stderr)
+	}
+}
+
+func jsonicHolotreeSpaceListing(table *pretty.Table) {
 	details := make(map[string]map[string]any)
-	_, roots := htfs.LoadCatalogs()
-	for _, space := range roots.Spaces() {
-		hold, ok := details[space.Identity]
-		if !ok {
-			hold = make(map[string]any)
-			details[space.Identity] = hold
-			hold["id"] = space.Identity
-			hold["controller"] = space.Controller
-			hold["space"] = space.Space
-			hold["blueprint"] = space.Blueprint
-			hold["path"] = space.Path
-			hold["meta"] = space.Path + ".meta"
-			hold["spec"] = filepath.Join(space.Path, "identity.yaml")
-			hold["plan"] = filepath.Join(space.Path, "rcc_plan.log")
-			when, times, idle := whatUsage(space.Path)
-			hold["last-used"] = when
-			hold["idle-days"] = idle
-			hold["use-count"] = times
+	for _, row := range table.Rows {
+		identity := row["identity"].(string)
+		path := row["path"].(string)
+		if _, ok := details[identity]; ok {
+			continue
 		}
+		hold := make(map[string]any)
+		details[identity] = hold
+		hold["id"] = identity
+		hold["controller"] = row["controller"]
+		hold["space"] = row["space"]
+		hold["blueprint"] = row["blueprint"]
+		hold["path"] = path
+		hold["meta"] = path + ".meta"
+		hold["spec"] = filepath.Join(path, "identity.yaml")
+		hold["plan"] = filepath.Join(path, "rcc_plan.log")
+		hold["last-used"] = daysAgo(row["last-used"])
+		hold["idle-days"] = row["idle"]
+		hold["use-count"] = usedTimes(row["uses"])
+		hold["size"] = row["size"]
 	}
 	body, err := json.MarshalIndent(details, "", "  ")
 	pretty.Guard(err == nil, 1, "Could not create json, reason: %w", err)
@@ -70,16 +133,34 @@ var holotreeListCmd = &cobra.Command{
 	Use:     "list",
 	Aliases: []string{"ls"},
 	Short:   "List holotree spaces.",
-	Long:    "List holotree spaces.",
+	Long: `List holotree spaces.
+
+Listing can be filtered, sorted, and limited to selected columns. Filters use
+form "column<operator>value", where operator is one of = != < <= > >= or ~
+(regular expression match). Numeric values accept unit suffixes, like "30d"
+for days or "2G" for sizes. Multiple filters must all match.
+
+Available columns: identity, controller, space, blueprint, path, last-used,
+uses, idle, size, files, platform
+
+Examples:
+  rcc holotree list --filter idle>30d --sort=-size
+  rcc holotree list --filter controller=rcc.user --columns identity,size,idle
+  rcc holotree list --sort last-used --csv > spaces.csv
+
+Catalog infos are cached in hololib catalog index, and on big installations
+progress is shown while loading. Use --refresh to rebuild that cache.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if common.DebugFlag() {
 			defer common.Stopwatch("Holotree list lasted").Report()
 		}
 
+		table := spaceListing()
+		applyTableControls(table, "Holotree spaces")
 		if jsonFlag {
-			jsonicHolotreeSpaceListing()
+			jsonicHolotreeSpaceListing(table)
 		} else {
-			humaneHolotreeSpaceListing()
+			humaneHolotreeSpaceListing(table)
 		}
 
 	},
@@ -88,4 +169,6 @@ var holotreeListCmd = &cobra.Command{
 func init() {
 	holotreeCmd.AddCommand(holotreeListCmd)
 	holotreeListCmd.Flags().BoolVarP(&jsonFlag, "json", "j", false, "Output in JSON format")
+	holotreeListCmd.Flags().BoolVarP(&holotreeRefresh, "refresh", "", false, "Rebuild cached catalog index before listing.")
+	addTableFlags(holotreeListCmd)
 }
@@ -19,7 +19,7 @@ var holotreePlanCmd = &cobra.Command{
 
 	Run: func(cmd *cobra.Command, args []string) {
 		found := false
-		_, roots := htfs.LoadCatalogs()
+		roots := htfs.LoadCatalogInfos()
 		for _, label := range roots.FindEnvironments(args) {
 			planfile, ok := roots.InstallationPlan(label)
 			pretty.Guard(ok, 1, "Could not find plan for: %v", label)
@@ -121,7 +121,7 @@ var holotreePrebuildCmd = &cobra.Command{
 				continue
 			}
 			pretty.Note("%d/%d: Now building config %q", at+1, total, configfile)
-			_, _, err = htfs.NewEnvironment(configfile, "", false, forceBuild, operations.PullCatalog)
+			_, _, err = htfs.NewEnvironment(configfile, "", nil, false, forceBuild, operations.PullCatalog)
 			if err != nil {
 				failed += 1
 				pretty.Warning("%d/%d: Holotree recording error: %v", at+1, total, err)
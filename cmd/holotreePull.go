@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"time"
+
 	"github.com/joshyorko/rcc/common"
 	"github.com/joshyorko/rcc/htfs"
 	"github.com/joshyorko/rcc/operations"
@@ -12,37 +14,62 @@ var (
 	remoteOriginOption string
 	pullRobot          string
 	forcePull          bool
+	watchPull          bool
+	watchPullWait      int
 )
 
 var holotreePullCmd = &cobra.Command{
 	Use:   "pull",
 	Short: "Try to pull existing holotree catalog from remote source.",
-	Long:  "Try to pull existing holotree catalog from remote source.",
+	Long: `Try to pull existing holotree catalog from remote source.
+
+With --watch flag, robot is not used. Instead, command subscribes to remote
+source (rccremote) and keeps pulling all new and updated catalogs of this
+platform (limited by RCC_REMOTE_DOMAIN, when set) as soon as they appear,
+until stopped. This keeps machine in sync without scheduled polling.
+
+With --verify (or RCC_PULL_VERIFY), pulled blobs are digest verified fully
+("full", default), spot-checked ("spot"), or trusted ("trust"). Mode used is
+recorded per catalog, and shown in "rcc holotree catalogs".`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if common.DebugFlag() {
 			defer common.Stopwatch("Holotree pull command lasted").Report()
 		}
+		guardPullVerify()
+		if watchPull {
+			pretty.Guard(watchPullWait > 0, 1, "Watch wait must be positive, not %d seconds.", watchPullWait)
+			common.Log("Watching %q for new catalogs. Stop with Ctrl-C.", remoteOriginOption)
+			operations.WatchCatalogs(remoteOriginOption, time.Duration(watchPullWait)*time.Second)
+		}
 		devDependencies := false
 		_, holotreeBlueprint, err := htfs.ComposeFinalBlueprint(nil, pullRobot, devDependencies)
-		pretty.Guard(err == nil, 1, "Blueprint calculation failed: %v", err)
+		pretty.GuardCoded(err == nil, 1, common.ErrBlueprintCompose, "Blueprint calculation failed: %v", err)
 		hash := common.BlueprintHash(holotreeBlueprint)
 		tree, err := htfs.New()
-		pretty.Guard(err == nil, 2, "%s", err)
+		pretty.GuardCoded(err == nil, 2, common.ErrHololibOpen, "%s", err)
 
 		present := tree.HasBlueprint(holotreeBlueprint)
 		if !present || forcePull {
 			catalog := htfs.CatalogName(hash)
 			err = operations.PullCatalog(remoteOriginOption, catalog, true)
-			pretty.Guard(err == nil, 3, "%s", err)
+			pretty.GuardCoded(err == nil, 3, common.ErrCatalogPull, "%s", err)
 		}
 		pretty.Ok()
 	},
 }
 
+func guardPullVerify() {
+	mode := common.RccPullVerify()
+	pretty.Guard(len(mode) == 0 || htfs.ValidPullVerifyMode(mode), 1, "Unknown verification mode %q, use full, spot, or trust.", mode)
+}
+
 func init() {
 	origin := common.RccRemoteOrigin()
 	holotreeCmd.AddCommand(holotreePullCmd)
 	holotreePullCmd.Flags().BoolVarP(&forcePull, "force", "", false, "Force pull check, even when blueprint is already present.")
+	holotreePullCmd.Flags().BoolVarP(&watchPull, "watch", "", false, "Keep pulling new and updated catalogs from remote origin, until stopped.")
+	holotreePullCmd.Flags().IntVarP(&watchPullWait, "wait", "", 60, "Seconds to wait for catalog notifications per request, when watching.")
+	holotreePullCmd.Flags().StringVarP(&common.PullVerify, "verify", "", "", "Verification of pulled blobs: full, spot, or trust (default is full, or RCC_PULL_VERIFY).")
 	holotreePullCmd.Flags().StringVarP(&remoteOriginOption, "origin", "o", origin, "URL of remote origin to pull environment from.")
 	holotreePullCmd.Flags().StringVarP(&pullRobot, "robot", "r", "robot.yaml", "Full path to 'robot.yaml' configuration file to export as catalog. <optional>")
 	if len(origin) == 0 {
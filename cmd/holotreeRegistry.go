@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/htfs"
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pretty"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	registryOrigin string
+	registryRobot  string
+)
+
+func registryDuration(entry *operations.RegistryEntry) string {
+	if entry.Latency == 0 {
+		return "-"
+	}
+	return entry.Latency.Round(100_000).String()
+}
+
+// queryBlueprintRegistry queries configured registry sources for catalogs of
+// blueprint, and reports source problems as warnings.
+func queryBlueprintRegistry(blueprint string) []*operations.RegistryEntry {
+	sources := operations.RegistrySources(registryOrigin)
+	pretty.Guard(len(sources) > 0, 1, "No registry sources. Give --origin, or set RCC_REMOTE_ORIGIN or RCC_ENDPOINT_REGISTRY.")
+	entries, problems := operations.QueryRegistry(sources, blueprint)
+	for _, problem := range problems {
+		pretty.Warning("%v", problem)
+	}
+	return entries
+}
+
+var holotreeRegistryCmd = &cobra.Command{
+	Use:   "registry [blueprint]",
+	Short: "Query which catalogs exist for blueprint across the fleet.",
+	Long: `Query which catalogs exist for blueprint across the fleet, from rccremote
+origin (--origin or RCC_REMOTE_ORIGIN) and from blueprint registry service
+(endpoint "registry" in settings, or RCC_ENDPOINT_REGISTRY). Blueprint hash
+is given as argument, or calculated from robot.
+
+Sources having catalog for this platform are probed for latency, and nearest
+one is suggested as source for "rcc holotree pull".
+
+Examples:
+  rcc holotree registry --robot path/to/robot.yaml
+  rcc holotree registry 0123456789abcdef --json`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if common.DebugFlag() {
+			defer common.Stopwatch("Holotree registry command lasted").Report()
+		}
+		blueprint := ""
+		if len(args) > 0 {
+			blueprint = args[0]
+		} else {
+			_, holotreeBlueprint, err := htfs.ComposeFinalBlueprint(nil, registryRobot, false)
+			pretty.GuardCoded(err == nil, 1, common.ErrBlueprintCompose, "Blueprint calculation failed: %v", err)
+			blueprint = common.BlueprintHash(holotreeBlueprint)
+		}
+		entries := queryBlueprintRegistry(blueprint)
+		if jsonFlag {
+			body, err := operations.NiceJsonOutput(entries)
+			pretty.Guard(err == nil, 2, "%v", err)
+			common.Stdout("%s\n", body)
+			return
+		}
+		common.Stdout("Blueprint %s is available from %d catalog source(s):\n\n", blueprint, len(entries))
+		for _, entry := range entries {
+			common.Stdout("  %-16s  %10s  %s\n", entry.Platform, registryDuration(entry), entry.Source)
+		}
+		common.Stdout("\n")
+		nearest, ok := operations.NearestSource(entries)
+		if ok {
+			pretty.Highlight("Nearest source for this platform is %s, pull with: rcc holotree pull --origin %s", nearest.Source, nearest.Source)
+		} else {
+			pretty.Note("No reachable source has catalog for this platform (%s).", common.Platform())
+		}
+		pretty.Ok()
+	},
+}
+
+func init() {
+	holotreeCmd.AddCommand(holotreeRegistryCmd)
+	holotreeRegistryCmd.Flags().StringVarP(&registryOrigin, "origin", "o", common.RccRemoteOrigin(), "URL of rccremote origin to query, in addition to registry endpoint.")
+	holotreeRegistryCmd.Flags().StringVarP(&registryRobot, "robot", "r", "robot.yaml", "Full path to 'robot.yaml' configuration file, when blueprint is not given.")
+	holotreeRegistryCmd.Flags().BoolVarP(&jsonFlag, "json", "j", false, "Show registry entries as JSON.")
+}
+
+var envPlanCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "Show where environment of conda.yaml file(s) would come from.",
+	Long: `Show where environment of conda.yaml file(s) would come from: local hololib,
+nearest catalog source known by blueprint registry (see "rcc holotree
+registry"), or local build. Nothing is built or pulled.`,
+	Example: `
+  rcc env plan -c conda.yaml
+  rcc env plan -c conda.yaml --origin https://rccremote.example.com:4653
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		pretty.Guard(len(envCondaFiles) > 0, 1, "At least one conda.yaml file must be given with --conda option.")
+		_, holotreeBlueprint, err := htfs.ComposeFinalBlueprint(envCondaFiles, "", false)
+		pretty.GuardCoded(err == nil, 1, common.ErrBlueprintCompose, "Blueprint calculation failed: %v", err)
+		blueprint := common.BlueprintHash(holotreeBlueprint)
+		tree, err := htfs.New()
+		pretty.GuardCoded(err == nil, 2, common.ErrHololibOpen, "%s", err)
+		common.Stdout("Blueprint: %s\n", blueprint)
+		if tree.HasBlueprint(holotreeBlueprint) {
+			common.Stdout("Plan:      use catalog %s from local hololib\n", htfs.CatalogName(blueprint))
+			pretty.Ok()
+			return
+		}
+		if len(operations.RegistrySources(registryOrigin)) > 0 {
+			nearest, ok := operations.NearestSource(queryBlueprintRegistry(blueprint))
+			if ok {
+				common.Stdout("Plan:      pull catalog %s from %s (latency %s)\n", nearest.Catalog, nearest.Source, registryDuration(nearest))
+				common.Stdout("Hint:      set RCC_REMOTE_ORIGIN=%s, and environment is pulled instead of built\n", nearest.Source)
+				pretty.Ok()
+				return
+			}
+		}
+		common.Stdout("Plan:      build environment locally (not found from hololib or registry)\n")
+		pretty.Ok()
+	},
+}
+
+func init() {
+	envCmd.AddCommand(envPlanCmd)
+	envPlanCmd.Flags().StringArrayVarP(&envCondaFiles, "conda", "c", nil, "Full path to conda.yaml environment file(s) to use (repeatable, merged in given order).")
+	envPlanCmd.Flags().StringVarP(&registryOrigin, "origin", "o", common.RccRemoteOrigin(), "URL of rccremote origin to query, in addition to registry endpoint.")
+}
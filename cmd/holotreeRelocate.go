@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/htfs"
+	"github.com/joshyorko/rcc/pretty"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	relocateDryRun bool
+)
+
+var holotreeRelocateCmd = &cobra.Command{
+	Use:   "relocate NEWPATH",
+	Short: "Move product home (hololib and holotree spaces) into new location.",
+	Long: `Move product home (hololib and holotree spaces) into new location, for
+example onto other drive, without rebuilding environments.
+
+Home content and hololib are copied into NEWPATH, catalogs are relocated and
+library files having embedded holotree paths are rewritten, all library blobs
+are verified against their digests, and existing holotree spaces are restored
+into new location. Absolute home paths in settings.yaml are updated. Old home
+is left untouched, so it can be removed after new one is taken into use.
+
+Since environments have absolute holotree paths embedded in them (also inside
+binaries), and those can only be overwritten in place, NEWPATH cannot have
+more characters than current home. Shorter NEWPATH works, since embedded paths
+are padded with extra path separators (like "/new/holotree///space"), which
+point to same place. If longer path is needed, rebuild environments there
+instead. Shared holotree cannot be relocated with this command.
+
+Examples:
+  rcc holotree relocate --dry-run /mnt/data/rcchome
+  rcc holotree relocate /mnt/data/rcchome`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if common.DebugFlag() {
+			defer common.Stopwatch("Holotree relocate command lasted").Report()
+		}
+		relocation, err := htfs.NewRelocation(args[0])
+		pretty.Guard(err == nil, 1, "%v", err)
+		common.Log("Relocating %s from %q to %q.", common.Product.HomeVariable(), relocation.OldHome, relocation.NewHome)
+		if relocateDryRun {
+			common.Log("Dry run: relocation is possible.")
+			pretty.Ok()
+			return
+		}
+		err = relocation.Run()
+		pretty.Guard(err == nil, 2, "%v", err)
+		common.Log("Relocated %d catalogs, rewrote %d library files, and restored %d spaces.", relocation.Catalogs, relocation.Blobs, len(relocation.Spaces))
+		pretty.Highlight("Take new location into use by setting %s=%s", common.Product.HomeVariable(), relocation.NewHome)
+		pretty.Note("Old location %q was left untouched, and can be removed after that.", relocation.OldHome)
+		pretty.Ok()
+	},
+}
+
+func init() {
+	holotreeCmd.AddCommand(holotreeRelocateCmd)
+	holotreeRelocateCmd.Flags().BoolVarP(&relocateDryRun, "dry-run", "", false, "Only check that relocation is possible.")
+}
@@ -52,7 +52,9 @@ var holotreeRemoveCmd = &cobra.Command{
 		if unusedDays > 0 {
 			args = append(args, allUnusedCatalogs(unusedDays)...)
 		}
-		holotreeRemove(selectCatalogs(args))
+		catalogs := selectCatalogs(args)
+		pretty.Guard(confirmedDeletion("catalogs", catalogs), 4, "Removing catalogs was cancelled.")
+		holotreeRemove(catalogs)
 		if removeCheckRetries > 0 {
 			checkLoop(removeCheckRetries)
 		} else {
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pretty"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	scrubDaemon   bool
+	scrubInterval time.Duration
+	scrubBatch    int
+	scrubWebhook  string
+)
+
+func reportScrub(summary *operations.ScrubSummary) {
+	if jsonFlag {
+		out, err := operations.NiceJsonOutput(summary)
+		pretty.Guard(err == nil, 2, "%s", err)
+		fmt.Println(out)
+		return
+	}
+	if summary.Healthy() {
+		common.Log("Scrub cycle %d: %s.", summary.Cycle, summary)
+	} else {
+		pretty.Warning("Scrub cycle %d: %s. Unrepaired catalogs: %q", summary.Cycle, summary, summary.Unrepaired)
+	}
+}
+
+var holotreeScrubCmd = &cobra.Command{
+	Use:   "scrub",
+	Short: "Verify next batch of hololib blobs, and repair broken catalogs.",
+	Long: `Verify next batch of hololib blobs, and repair broken catalogs.
+
+Each cycle verifies --batch blobs, continuing where previous cycle ended, so
+that repeated cycles eventually cover whole hololib. Catalogs that have
+corrupted or missing blobs are repaired from RCC_REMOTE_ORIGIN, when it is
+set. Health summary of each cycle is written into journal, and posted as
+JSON to --webhook, when one is given.
+
+Without --daemon, one cycle is done (for running from scheduler), and exit
+code is non-zero, if some broken catalog could not be repaired. With
+--daemon, cycles are repeated with --interval between them, until stopped.`,
+	Example: `
+  rcc holotree scrub --batch 5000
+  rcc holotree scrub --daemon --interval 24h --webhook https://hooks.example.com/rcc`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if common.DebugFlag() {
+			defer common.Stopwatch("Holotree scrub lasted").Report()
+		}
+		pretty.Guard(scrubBatch > 0, 1, "Option --batch must be at least 1.")
+		if scrubDaemon {
+			pretty.Guard(scrubInterval >= time.Minute, 1, "Option --interval must be at least one minute.")
+			operations.ScrubDaemon(scrubBatch, scrubInterval, scrubWebhook, reportScrub)
+			return
+		}
+		summary, err := operations.ScrubCycle(scrubBatch, scrubWebhook)
+		pretty.Guard(err == nil, 3, "%v", err)
+		reportScrub(summary)
+		pretty.Guard(summary.Healthy(), 4, "%d broken catalogs could not be repaired.", len(summary.Unrepaired))
+		pretty.Ok()
+	},
+}
+
+func init() {
+	holotreeCmd.AddCommand(holotreeScrubCmd)
+	holotreeScrubCmd.Flags().BoolVarP(&scrubDaemon, "daemon", "", false, "Keep running scrub cycles, with --interval between them.")
+	holotreeScrubCmd.Flags().DurationVarP(&scrubInterval, "interval", "", 24*time.Hour, "Time between scrub cycles in daemon mode.")
+	holotreeScrubCmd.Flags().IntVarP(&scrubBatch, "batch", "", 10000, "How many blobs to verify in one cycle.")
+	holotreeScrubCmd.Flags().StringVarP(&scrubWebhook, "webhook", "", "", "URL (https) where cycle summaries are posted as JSON.")
+	holotreeScrubCmd.Flags().BoolVarP(&jsonFlag, "json", "j", false, "Output cycle summaries in JSON format.")
+}
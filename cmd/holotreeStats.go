@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"encoding/json"
+	"os"
+
 	"github.com/joshyorko/rcc/common"
 	"github.com/joshyorko/rcc/journal"
 	"github.com/joshyorko/rcc/pretty"
@@ -14,6 +17,7 @@ var (
 	onlyPrepareStats   bool
 	onlyVariablesStats bool
 	statsWeeks         uint
+	buildSourceStats   bool
 )
 
 var holotreeStatsCmd = &cobra.Command{
@@ -25,7 +29,11 @@ var holotreeStatsCmd = &cobra.Command{
 		if common.DebugFlag() {
 			defer common.Stopwatch("Holotree stats calculation lasted").Report()
 		}
-		journal.ShowStatistics(statsWeeks, onlyAssistantStats, onlyRobotStats, onlyPrepareStats, onlyVariablesStats)
+		if buildSourceStats {
+			showBuildSources(statsWeeks)
+		} else {
+			journal.ShowStatistics(statsWeeks, onlyAssistantStats, onlyRobotStats, onlyPrepareStats, onlyVariablesStats)
+		}
 		pretty.Ok()
 	},
 }
@@ -37,4 +45,18 @@ func init() {
 	holotreeStatsCmd.Flags().BoolVarP(&onlyPrepareStats, "--prepares", "p", false, "Include 'cloud prepare' into stats.")
 	holotreeStatsCmd.Flags().BoolVarP(&onlyVariablesStats, "--variables", "v", false, "Include 'holotree variables' into stats.")
 	holotreeStatsCmd.Flags().UintVarP(&statsWeeks, "--weeks", "w", 12, "Number of previous weeks to include into stats.")
+	holotreeStatsCmd.Flags().BoolVarP(&buildSourceStats, "builds", "b", false, "Show where environments came from (cache hit, remote pull, partial rebuild, full build) and cache hit ratio.")
+	holotreeStatsCmd.Flags().BoolVarP(&jsonFlag, "json", "j", false, "Output build sources in JSON format (with --builds).")
+}
+
+func showBuildSources(weeks uint) {
+	sources, err := journal.BuildSourceStatistics(weeks)
+	pretty.Guard(err == nil, 1, "Loading statistics failed, reason: %v", err)
+	if jsonFlag {
+		body, err := json.MarshalIndent(sources, "", "  ")
+		pretty.Guard(err == nil, 1, "Could not create json, reason: %v", err)
+		common.Stdout("%s\n", body)
+		return
+	}
+	sources.Write(os.Stderr)
 }
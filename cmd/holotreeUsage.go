@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/htfs"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/spf13/cobra"
+)
+
+var (
+	usageByUser bool
+)
+
+func idleDays(stamp int64) int {
+	if stamp == 0 {
+		return 0
+	}
+	return int(time.Since(time.Unix(stamp, 0)).Hours() / 24.0)
+}
+
+func spaceSizes(spaces htfs.Roots) ([]string, map[string]uint64) {
+	paths := make([]string, 0, len(spaces))
+	sizes := make(map[string]uint64)
+	counter := pretty.NewCounter("Loading spaces", len(spaces))
+	defer counter.Done()
+	for _, space := range spaces {
+		counter.Tick()
+		paths = append(paths, space.Path)
+		stats, err := space.Stats()
+		if err == nil {
+			sizes[space.Path] = stats.Bytes
+		}
+	}
+	return paths, sizes
+}
+
+func userUsageListing() *pretty.Table {
+	table := pretty.NewTable(
+		&pretty.TableColumn{Name: "user", Title: "User"},
+		&pretty.TableColumn{Name: "controllers", Title: "Controllers"},
+		&pretty.TableColumn{Name: "spaces", Title: "Spaces"},
+		&pretty.TableColumn{Name: "size", Title: "Size (shared)", Format: humaneSize},
+		&pretty.TableColumn{Name: "uses", Title: "Use count"},
+		&pretty.TableColumn{Name: "last-used", Title: "Last used", Format: daysAgo},
+		&pretty.TableColumn{Name: "idle", Title: "Idle (days)"},
+	)
+	paths, sizes := spaceSizes(htfs.LoadCatalogInfos().Spaces())
+	for _, usage := range htfs.UsageByUser(paths, sizes) {
+		table.Add(pretty.TableRow{
+			"user":        usage.User,
+			"controllers": strings.Join(usage.Controllers, ","),
+			"spaces":      usage.Spaces,
+			"size":        usage.Bytes,
+			"uses":        usage.Uses,
+			"last-used":   usage.Last,
+			"idle":        idleDays(usage.Last),
+		})
+	}
+	table.Sort("user")
+	return table
+}
+
+func spaceUserListing() *pretty.Table {
+	table := pretty.NewTable(
+		&pretty.TableColumn{Name: "path", Title: "Full path"},
+		&pretty.TableColumn{Name: "user", Title: "User"},
+		&pretty.TableColumn{Name: "controller", Title: "Controller"},
+		&pretty.TableColumn{Name: "uses", Title: "Use count"},
+		&pretty.TableColumn{Name: "first-used", Title: "First used", Format: daysAgo},
+		&pretty.TableColumn{Name: "last-used", Title: "Last used", Format: daysAgo},
+		&pretty.TableColumn{Name: "idle", Title: "Idle (days)"},
+	)
+	for _, space := range htfs.LoadCatalogInfos().Spaces() {
+		for _, entry := range htfs.LoadSpaceUsers(space.Path) {
+			table.Add(pretty.TableRow{
+				"path":       space.Path,
+				"user":       entry.User,
+				"controller": entry.Controller,
+				"uses":       entry.Uses,
+				"first-used": entry.First,
+				"last-used":  entry.Last,
+				"idle":       idleDays(entry.Last),
+			})
+		}
+	}
+	table.Sort("path")
+	return table
+}
+
+var holotreeUsageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Show which users and controllers have used holotree spaces.",
+	Long: `Show which users and controllers have used holotree spaces, and when. This
+helps admins of shared machines to attribute disk usage and to clean up spaces
+of stale users.
+
+With --by-user, usage is aggregated per user, and size of each space is shared
+evenly between all users of that space.
+
+Listing can be filtered, sorted, and limited to selected columns, same way as
+in "rcc holotree list" command.
+
+Available columns: path, user, controller, uses, first-used, last-used, idle
+Available columns with --by-user: user, controllers, spaces, size, uses,
+last-used, idle
+
+Examples:
+  rcc holotree usage --by-user --sort=-size
+  rcc holotree usage --filter idle>90d --columns path,user,idle`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if common.DebugFlag() {
+			defer common.Stopwatch("Holotree usage command lasted").Report()
+		}
+		var table *pretty.Table
+		if usageByUser {
+			table = userUsageListing()
+		} else {
+			table = spaceUserListing()
+		}
+		applyTableControls(table, "Holotree usage")
+		switch {
+		case jsonFlag:
+			body, err := json.MarshalIndent(table.Rows, "", "  ")
+			pretty.Guard(err == nil, 1, "Could not create json, reason: %v", err)
+			fmt.Println(string(body))
+		case csvFlag:
+			err := table.WriteCSV(os.Stdout)
+			pretty.Guard(err == nil, 1, "Could not write CSV, reason: %v", err)
+		default:
+			table.WriteText(os.Stderr)
+		}
+	},
+}
+
+func init() {
+	holotreeCmd.AddCommand(holotreeUsageCmd)
+	holotreeUsageCmd.Flags().BoolVarP(&usageByUser, "by-user", "", false, "Aggregate usage per user.")
+	holotreeUsageCmd.Flags().BoolVarP(&jsonFlag, "json", "j", false, "Output in JSON format")
+	addTableFlags(holotreeUsageCmd)
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/joshyorko/rcc/common"
@@ -24,46 +25,21 @@ var (
 	holotreeBlueprint []byte
 	holotreeForce     bool
 	holotreeJson      bool
+	holotreeRefresh   bool
+	variablesFormat   string
+	variablesTarget   string
 )
 
-func asSimpleMap(line string) map[string]string {
-	parts := strings.SplitN(strings.TrimSpace(line), "=", 2)
-	if len(parts) != 2 {
-		return nil
-	}
-	if len(parts[0]) == 0 {
-		return nil
-	}
-	result := make(map[string]string)
-	result["key"] = parts[0]
-	result["value"] = parts[1]
-	return result
-}
-
-func asJson(items []string) error {
-	result := make([]map[string]string, 0, len(items))
-	for _, line := range items {
-		entry := asSimpleMap(line)
-		if entry != nil {
-			result = append(result, entry)
-		}
-	}
-	content, err := operations.NiceJsonOutput(result)
-	if err != nil {
-		return err
-	}
-	common.Stdout("%s\n", content)
-	return nil
-}
-
-func asExportedText(items []string) {
-	prefix := "export"
-	if conda.IsWindows() {
-		prefix = "SET"
-	}
-	for _, line := range items {
-		common.Stdout("%s %s\n", prefix, line)
+func outputVariables(format, target string, items []string) {
+	content, err := operations.FormatVariables(format, items, conda.IsWindows())
+	pretty.Guard(err == nil, 1, "%v", err)
+	if len(target) == 0 {
+		common.Stdout("%s\n", content)
+		return
 	}
+	err = pathlib.WriteFile(target, []byte(content+"\n"), 0o600)
+	pretty.Guard(err == nil, 1, "Could not write variables to %q, reason: %v", target, err)
+	common.Log("Wrote %d variables in %s format to %q.", len(items), format, target)
 }
 
 func holotreeExpandEnvironment(userFiles []string, packfile, environment, workspace string, validity int, force bool, devDependencies bool) []string {
@@ -73,7 +49,7 @@ func holotreeExpandEnvironment(userFiles []string, packfile, environment, worksp
 	defer common.TimelineEnd()
 
 	config, holotreeBlueprint, err := htfs.ComposeFinalBlueprint(userFiles, packfile, devDependencies)
-	pretty.Guard(err == nil, 5, "%s", err)
+	pretty.GuardCoded(err == nil, 5, common.ErrBlueprintCompose, "%s", err)
 
 	condafile := filepath.Join(common.ProductTemp(), common.BlueprintHash(holotreeBlueprint))
 	err = pathlib.WriteFile(condafile, holotreeBlueprint, 0o644)
@@ -82,15 +58,17 @@ func holotreeExpandEnvironment(userFiles []string, packfile, environment, worksp
 	holozip := ""
 	if config != nil {
 		holozip = config.Holozip()
+		_, err = operations.UseSharedEnvironment(config)
+		pretty.GuardCoded(err == nil, 5, common.ErrRobotValidation, "%s", err)
 	}
 
 	// i.e.: the conda file is now already created in the temp folder, so, there's no need to use the devDependencies flag
 	// anymore.
-	path, _, err := htfs.NewEnvironment(condafile, holozip, true, force, operations.PullCatalog)
+	path, _, err := htfs.NewEnvironment(condafile, holozip, nil, true, force, operations.PullCatalog)
 	if !common.WarrantyVoided() {
 		pretty.RccPointOfView(newEnvironment, err)
 	}
-	pretty.Guard(err == nil, 6, "%s", err)
+	pretty.GuardCoded(err == nil, 6, common.ErrEnvironmentCreation, "%s", err)
 
 	if Has(environment) {
 		common.Timeline("load robot environment")
@@ -117,7 +95,7 @@ func holotreeExpandEnvironment(userFiles []string, packfile, environment, worksp
 		period.EnforceGracePeriod()
 		claims := operations.RunRobotClaims(period.RequestSeconds(), workspace)
 		data, err = operations.AuthorizeClaims(AccountName(), claims, period)
-		pretty.Guard(err == nil, 9, "Failed to get cloud data, reason: %v", err)
+		pretty.GuardCoded(err == nil, 9, common.ErrCloudRequest, "Failed to get cloud data, reason: %v", err)
 	}
 
 	if len(data) > 0 {
@@ -153,19 +131,31 @@ var holotreeVariablesCmd = &cobra.Command{
 	Use:     "variables conda.yaml+",
 	Aliases: []string{"vars"},
 	Short:   "Do holotree operations.",
-	Long:    "Do holotree operations.",
+	Long: `Do holotree operations, and show environment variables of resulting
+environment. Output format is selected with --format option:
+
+  shell       export KEY=value lines (SET KEY=value on Windows), default
+  dotenv      KEY=value lines, quoted when needed, for .env files
+  json        list of key/value objects
+  powershell  $env:KEY = 'value' lines
+  fish        set -gx KEY 'value' lines
+
+With --write, variables are written into given file instead of stdout.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		defer journal.BuildEventStats("variables")
 		if common.DebugFlag() {
 			defer common.Stopwatch("Holotree variables command lasted").Report()
 		}
 
-		env := holotreeExpandEnvironment(args, robotFile, environmentFile, workspaceId, validityTime, holotreeForce, common.DevDependencies)
+		if holotreeForce {
+			pretty.Guard(confirmed("Force will rebuild environment from scratch. Continue?"), 2, "Forced environment rebuild was cancelled.")
+		}
 		if holotreeJson {
-			asJson(env)
-		} else {
-			asExportedText(env)
+			variablesFormat = operations.FormatJson
 		}
+		pretty.Guard(slices.Contains(operations.VariableFormats, variablesFormat), 1, "Unknown format %q, expected one of: %s.", variablesFormat, strings.Join(operations.VariableFormats, ", "))
+		env := holotreeExpandEnvironment(args, robotFile, environmentFile, workspaceId, validityTime, holotreeForce, common.DevDependencies)
+		outputVariables(variablesFormat, variablesTarget, env)
 	},
 }
 
@@ -180,6 +170,8 @@ func init() {
 
 	holotreeVariablesCmd.Flags().StringVarP(&common.HolotreeSpace, "space", "s", "user", "Client specific name to identify this environment.")
 	holotreeVariablesCmd.Flags().BoolVarP(&holotreeForce, "force", "f", false, "Force environment creation with refresh.")
-	holotreeVariablesCmd.Flags().BoolVarP(&holotreeJson, "json", "j", false, "Show environment as JSON.")
+	holotreeVariablesCmd.Flags().BoolVarP(&holotreeJson, "json", "j", false, "Show environment as JSON (same as --format json).")
+	holotreeVariablesCmd.Flags().StringVarP(&variablesFormat, "format", "", operations.FormatShell, "Output format: shell, dotenv, json, powershell, or fish.")
+	holotreeVariablesCmd.Flags().StringVarP(&variablesTarget, "write", "", "", "Write variables into given file, instead of stdout.")
 	holotreeVariablesCmd.Flags().BoolVarP(&common.DevDependencies, "devdeps", "", false, "Include dev-dependencies from the `package.yaml` file in the environment (only valid when dealing with a `package.yaml` file).")
 }
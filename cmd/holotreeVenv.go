@@ -21,7 +21,7 @@ import (
 )
 
 func deleteByExactIdentity(exact string) {
-	_, roots := htfs.LoadCatalogs()
+	roots := htfs.LoadCatalogInfos()
 	for _, label := range roots.FindEnvironments([]string{exact}) {
 		common.Log("Removing %v", label)
 		err := roots.RemoveHolotreeSpace(label)
@@ -51,6 +51,9 @@ var holotreeVenvCmd = &cobra.Command{
 		location := filepath.Join(where, "venv")
 
 		previous := pathlib.IsDir(location)
+		if holotreeForce {
+			pretty.Guard(confirmed("Force will delete existing venv and unmanaged space. Continue?"), 8, "Forced venv creation was cancelled.")
+		}
 		if holotreeForce && previous {
 			pretty.Note("Trying to remove existing venv at %q ...", location)
 			err := pathlib.TryRemoveAll("venv", location)
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/spf13/cobra"
+)
+
+var holotreeVerifyBundleCmd = &cobra.Command{
+	Use:   "verify-bundle hololib.zip+",
+	Short: "Verify hololib.zip bundles before importing them.",
+	Long: `Verify hololib.zip bundles before importing them into local hololib.
+
+Checks that every entry has holotree shape, every library blob matches its
+digest, every catalog parses and is for this platform and catalog format, and
+that all blobs referenced by catalogs are in bundle or already in local
+hololib. Nothing is written into hololib.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if common.DebugFlag() {
+			defer common.Stopwatch("Holotree verify-bundle command lasted").Report()
+		}
+		valid := true
+		for _, filename := range args {
+			report, err := operations.VerifyBundle(filename)
+			pretty.Guard(err == nil, 1, "Could not verify %q, reason: %v", filename, err)
+			operations.BundleVerifyReport(report, jsonFlag)
+			valid = valid && report.Valid
+		}
+		pretty.Guard(valid, 2, "Some bundles failed verification.")
+		pretty.Ok()
+	},
+}
+
+func init() {
+	holotreeCmd.AddCommand(holotreeVerifyBundleCmd)
+	holotreeVerifyBundleCmd.Flags().BoolVarP(&jsonFlag, "json", "j", false, "Output report in JSON format")
+}
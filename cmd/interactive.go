@@ -1,8 +1,21 @@
 package cmd
 
 import (
+	"sort"
+	"strings"
+	"time"
+
 	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pretty"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var (
+	interactiveView  string
+	interactiveYank  bool
+	interactiveFresh bool
 )
 
 var interactiveCmd = &cobra.Command{
@@ -10,11 +23,129 @@ var interactiveCmd = &cobra.Command{
 	Aliases: []string{"i"},
 	Short:   "Group of interactive commands. For human users. Do not use in automation.",
 	Long: `This group of commands are interactive, asking questions from user when needed.
-Do not try to use these in automation, they will fail there.`,
+Do not try to use these in automation, they will fail there.
+
+With --view flag, named view (interactive command) is opened directly, with
+context given by --robot flag and remaining arguments, so that scripts and
+docs can deep-link users to right view. For example:
+
+  rcc interactive --view history --robot ./path/robot.yaml
+  rcc interactive --view compare leftcatalog rightcatalog
+
+With --yank flag, primary value of view (like catalog path, remote URL, or
+command preview) is copied into clipboard after view is shown. Views that
+keep asking commands also accept "y" to copy currently selected value.
+
+Last opened view (with its arguments, search, toggles, and selection) is
+remembered in interactive.yaml in ROBOCORP_HOME, and plain "rcc interactive"
+offers to resume it. Use --fresh to forget it and start fresh.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if interactiveFresh {
+			err := operations.ForgetView()
+			pretty.Guard(err == nil, 2, "Could not forget last view, reason: %v", err)
+		}
+		if len(interactiveView) == 0 && resumeLastView(cmd) {
+			return
+		}
+		if len(interactiveView) == 0 {
+			cmd.Help()
+			return
+		}
+		view, ok := interactiveViewCommand(cmd, interactiveView)
+		pretty.Guard(ok, 1, "Unknown view %q. Available views are: %s.", interactiveView, strings.Join(interactiveViews(cmd), ", "))
+		pretty.Guard(view.ValidateArgs(args) == nil, 1, "Invalid arguments %q for view %q, usage: %s", args, interactiveView, view.UseLine())
+		common.Debug("Opening interactive view %q with robot %q and arguments %q.", view.Name(), robotFile, args)
+		rememberView(view, args)
+		view.Run(view, args)
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		yankPrimaryValue()
+	},
+}
+
+func rememberView(view *cobra.Command, args []string) {
+	if !pretty.Interactive {
+		return
+	}
+	flags := make(map[string]string)
+	view.Flags().Visit(func(flag *pflag.Flag) {
+		if flag.Name != "yank" {
+			flags[flag.Name] = flag.Value.String()
+		}
+	})
+	err := operations.RememberView(view.Name(), args, flags)
+	if err != nil {
+		common.Debug("Could not remember interactive view, reason: %v", err)
+	}
+}
+
+func resumeLastView(parent *cobra.Command) bool {
+	last, ok := operations.LastView()
+	if !ok || !pretty.Interactive {
+		return false
+	}
+	view, ok := interactiveViewCommand(parent, last.View)
+	if !ok {
+		return false
+	}
+	if !pretty.Confirm(false, "Resume last view %q from %s?", last, last.When.Format(time.DateTime)) {
+		return false
+	}
+	for name, value := range last.Flags {
+		err := view.Flags().Set(name, value)
+		if err != nil {
+			pretty.Warning("Could not restore --%s of view %q, reason: %v", name, last.View, err)
+		}
+	}
+	common.Debug("Resuming interactive view %q.", last)
+	rememberView(view, last.Arguments)
+	view.Run(view, last.Arguments)
+	return true
+}
+
+func yankPrimaryValue() {
+	if !interactiveYank {
+		return
+	}
+	interactiveYank = false
+	err := operations.YankToClipboard()
+	if err != nil {
+		pretty.Warning("%v", err)
+	}
+}
+
+func interactiveViews(parent *cobra.Command) []string {
+	result := []string{}
+	for _, command := range parent.Commands() {
+		if command.IsAvailableCommand() {
+			result = append(result, command.Name())
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func interactiveViewCommand(parent *cobra.Command, name string) (*cobra.Command, bool) {
+	for _, command := range parent.Commands() {
+		if command.IsAvailableCommand() && (command.Name() == name || command.HasAlias(name)) && command.Run != nil {
+			return command, true
+		}
+	}
+	return nil, false
 }
 
 func init() {
 	if common.Product.IsLegacy() {
 		rootCmd.AddCommand(interactiveCmd)
 	}
+	interactiveCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		if cmd != interactiveCmd {
+			rememberView(cmd, args)
+		}
+	}
+
+	interactiveCmd.PersistentFlags().BoolVarP(&interactiveYank, "yank", "y", false, "Copy primary value of view (path, hash, URL, or command) into clipboard.")
+	interactiveCmd.Flags().StringVarP(&interactiveView, "view", "", "", "Open named interactive view directly (like home, history, compare, actions, robots, profiles, activity, or processes).")
+	interactiveCmd.Flags().BoolVarP(&interactiveFresh, "fresh", "", false, "Forget last view of previous session, instead of offering to resume it.")
+	interactiveCmd.Flags().StringVarP(&robotFile, "robot", "r", "robot.yaml", "Full path to 'robot.yaml' configuration file, as context for view. <optional>")
 }
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/joshyorko/rcc/wizard"
+
+	"github.com/spf13/cobra"
+)
+
+var wizardActionsCmd = &cobra.Command{
+	Use:   "actions [key]",
+	Short: "Show quick actions bar and run user defined shortcut interactively.",
+	Long: `Show quick actions bar and run user defined shortcut interactively.
+Quick actions are frequent rcc operations (like "rebuild env for robot X",
+"pull from remote", or "clean idle spaces") defined in quickactions.yaml file
+in ROBOCORP_HOME, each mapped to single key. Actions bar is also shown in
+"rcc interactive home" view.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !pretty.Interactive {
+			pretty.Exit(1, "This is for interactive use only. Do not use in scripting/CI!")
+		}
+		if common.DebugFlag() {
+			defer common.Stopwatch("Interactive actions lasted").Report()
+		}
+		err := wizard.QuickActions(args)
+		if err != nil {
+			pretty.Exit(2, "%v", err)
+		}
+	},
+}
+
+func init() {
+	if common.Product.IsLegacy() {
+		interactiveCmd.AddCommand(wizardActionsCmd)
+	}
+}
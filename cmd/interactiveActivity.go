@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/pretty"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	activityWatch  int
+	activityCancel int
+)
+
+func currentActivities() pathlib.Lockpids {
+	activities, err := pathlib.Activities()
+	pretty.Guard(err == nil, 1, "Could not list background operations, reason: %v", err)
+	return activities
+}
+
+var activityCmd = &cobra.Command{
+	Use:     "activity",
+	Aliases: []string{"activities", "background"},
+	Short:   "Show background operations (pull, environment, import, export) of all rcc processes.",
+	Long: `Show in-flight background operations (like holotree pull, environment
+build or restore, import, and export) of all rcc processes sharing this
+holotree, so that they can be tracked from other terminal or view.
+
+Operation can be cancelled using --cancel with process id shown, which sends
+SIGTERM to rcc process running that operation. Same summary is shown as
+"activity" in "rcc interactive home" view.`,
+	Example: `
+  rcc interactive activity --watch 2
+  rcc interactive activity --cancel 12345`,
+	Run: func(cmd *cobra.Command, args []string) {
+		activities := currentActivities()
+		if activityCancel > 0 {
+			err := operations.CancelActivity(activities, activityCancel)
+			pretty.Guard(err == nil, 2, "Could not cancel process %d, reason: %v", activityCancel, err)
+			common.Log("Process %d asked to stop.", activityCancel)
+			time.Sleep(500 * time.Millisecond)
+			activities = currentActivities()
+		}
+		for frame := 0; ; frame++ {
+			operations.ShowActivities(activities, frame)
+			if activityWatch < 1 || !pretty.Interactive {
+				break
+			}
+			time.Sleep(time.Duration(activityWatch) * time.Second)
+			common.Stdout("--- %s ---\n", pretty.Now().Format(time.TimeOnly))
+			activities = currentActivities()
+		}
+		pretty.Ok()
+	},
+}
+
+func init() {
+	interactiveCmd.AddCommand(activityCmd)
+
+	activityCmd.Flags().IntVarP(&activityWatch, "watch", "w", 0, "Refresh background operations every N seconds, until interrupted.")
+	activityCmd.Flags().IntVarP(&activityCancel, "cancel", "", 0, "Cancel background operation of given rcc process id.")
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pretty"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	homeWatch   int
+	homeTimeout int
+)
+
+var homeCmd = &cobra.Command{
+	Use:     "home",
+	Aliases: []string{"status"},
+	Short:   "Show system status widgets (disk, hololib, spaces, remote, last run).",
+	Long: `Show system status widgets: free disk under ROBOCORP_HOME, hololib size,
+count of catalogs and spaces, RCC_REMOTE_ORIGIN reachability, shared holotree
+state, and last run result. Each widget is checked in parallel, and widgets
+that do not finish in time are shown as pending. Each widget also shows
+command to use for more details. User defined quick actions (see "rcc
+interactive actions") are shown below widgets.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		timeout := time.Duration(homeTimeout) * time.Second
+		operations.Yankable("remote URL", common.RccRemoteOrigin())
+		for {
+			operations.ShowHomeStatus(operations.HomeStatus(timeout))
+			actions, err := operations.LoadQuickActions()
+			if err != nil {
+				pretty.Warning("Could not load quick actions, reason: %v", err)
+			} else {
+				operations.ShowQuickActions(actions)
+			}
+			if homeWatch < 1 || !pretty.Interactive {
+				break
+			}
+			time.Sleep(time.Duration(homeWatch) * time.Second)
+			common.Stdout("--- %s ---\n", pretty.Now().Format(time.TimeOnly))
+		}
+		pretty.Ok()
+	},
+}
+
+func init() {
+	interactiveCmd.AddCommand(homeCmd)
+
+	homeCmd.Flags().IntVarP(&homeWatch, "watch", "w", 0, "Refresh widgets every N seconds, until interrupted.")
+	homeCmd.Flags().IntVarP(&homeTimeout, "timeout", "t", 10, "How many seconds to wait for slow widgets.")
+}
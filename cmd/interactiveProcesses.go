@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pretty"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	monitorPid       int
+	monitorWatch     int
+	monitorTerminate int
+	monitorKill      int
+	monitorJournal   bool
+)
+
+func monitoredProcesses() (operations.ProcessMap, []int) {
+	processes, err := operations.ProcessMapAll()
+	if err != nil {
+		pretty.Exit(1, "Could not get process snapshot, reason: %v", err)
+	}
+	if monitorPid > 0 {
+		_, ok := processes[monitorPid]
+		pretty.Guard(ok, 2, "Process %d not found.", monitorPid)
+		return processes, []int{monitorPid}
+	}
+	return processes, operations.RobotProcessRoots(processes)
+}
+
+var processesCmd = &cobra.Command{
+	Use:     "processes",
+	Aliases: []string{"ps", "process"},
+	Short:   "Show live process tree of running robots, and stop runaway subprocesses.",
+	Long: `Show live process tree of running robots (PID, CPU, RSS, runtime).
+By default all running rcc processes with subprocesses are monitored.
+
+Process details come from /proc on Linux, from ps command on macOS, and
+from process APIs on Windows (where RSS is working set size). Details of
+processes that cannot be inspected are shown as N/A.
+
+Runaway subprocesses (like zombie chromedriver processes) can be stopped
+using --terminate (SIGTERM) or --kill (SIGKILL). On Windows only --kill is
+available. Only subprocesses of monitored processes can be signaled.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		processes, roots := monitoredProcesses()
+		if monitorTerminate > 0 || monitorKill > 0 {
+			pid, force := monitorTerminate, false
+			if monitorKill > 0 {
+				pid, force = monitorKill, true
+			}
+			err := operations.SignalRobotProcess(processes, roots, pid, force)
+			pretty.Guard(err == nil, 3, "Could not signal process %d, reason: %v", pid, err)
+			common.Log("Process %d signaled (force: %v).", pid, force)
+			time.Sleep(500 * time.Millisecond)
+			processes, roots = monitoredProcesses()
+		}
+		for {
+			rows := operations.ProcessTreeRows(processes, roots)
+			if len(rows) == 0 {
+				pretty.Note("No running robot processes found.")
+			} else {
+				operations.ShowProcessRows(rows)
+			}
+			if monitorJournal {
+				operations.JournalProcessRows(rows)
+			}
+			if monitorWatch < 1 || !pretty.Interactive {
+				break
+			}
+			time.Sleep(time.Duration(monitorWatch) * time.Second)
+			common.Stdout("\n--- %s ---\n", pretty.Now().Format(time.TimeOnly))
+			processes, roots = monitoredProcesses()
+		}
+		pretty.Ok()
+	},
+}
+
+func init() {
+	interactiveCmd.AddCommand(processesCmd)
+
+	processesCmd.Flags().IntVarP(&monitorPid, "pid", "p", 0, "Process id to monitor instead of all running rcc processes.")
+	processesCmd.Flags().IntVarP(&monitorWatch, "watch", "w", 0, "Refresh process tree every N seconds, until interrupted.")
+	processesCmd.Flags().IntVarP(&monitorTerminate, "terminate", "", 0, "Send SIGTERM to given subprocess.")
+	processesCmd.Flags().IntVarP(&monitorKill, "kill", "", 0, "Send SIGKILL to given subprocess.")
+	processesCmd.Flags().BoolVarP(&monitorJournal, "journal", "", false, "Dump process tree into run journal.")
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/joshyorko/rcc/wizard"
+
+	"github.com/spf13/cobra"
+)
+
+var wizardProfilesCmd = &cobra.Command{
+	Use:     "profiles [name]",
+	Aliases: []string{"profile"},
+	Short:   "Show configuration profiles and switch active one interactively.",
+	Long: `Show imported configuration profiles, with active one marked, and switch
+immediately to selected one (same as "rcc configuration switch"). After switch,
+status widgets that depend on endpoints and settings are shown again. Active
+profile is also shown in "rcc interactive home" view.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !pretty.Interactive {
+			pretty.Exit(1, "This is for interactive use only. Do not use in scripting/CI!")
+		}
+		if common.DebugFlag() {
+			defer common.Stopwatch("Interactive profiles lasted").Report()
+		}
+		switched, err := wizard.Profiles(args)
+		if err != nil {
+			pretty.Exit(2, "%v", err)
+		}
+		if switched {
+			operations.ShowHomeStatus(operations.HomeStatus(time.Duration(homeTimeout) * time.Second))
+		}
+	},
+}
+
+func init() {
+	if common.Product.IsLegacy() {
+		interactiveCmd.AddCommand(wizardProfilesCmd)
+	}
+}
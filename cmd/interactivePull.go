@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/joshyorko/rcc/wizard"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	interactivePullOrigin string
+	interactivePullServer string
+)
+
+var wizardPullCmd = &cobra.Command{
+	Use:   "pull",
+	Short: "Pull selected catalogs from remote catalog listing interactively.",
+	Long: `Pull selected catalogs from remote catalog listing interactively. Catalogs
+of this platform in rccremote origin (limited by RCC_REMOTE_DOMAIN, when set)
+are listed with their status in local hololib, and with estimated download
+size (size of blobs missing from local hololib).
+
+Mark catalogs to pull by their numbers or ranges (like "1,3-5"), or mark all
+missing ones with "m", and then pull marked ones with "p". Aggregate progress
+against estimated total is shown while pulling.
+
+With --server, origin, domain, and authorization come from saved server
+profile (see "rcc interactive servers").`,
+	Example: `
+  rcc interactive pull --origin https://rccremote.example.com:4653
+  rcc interactive pull --server team`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !pretty.Interactive {
+			pretty.Exit(1, "This is for interactive use only. Do not use in scripting/CI!")
+		}
+		if common.DebugFlag() {
+			defer common.Stopwatch("Interactive pull lasted").Report()
+		}
+		if len(interactivePullServer) > 0 {
+			profile, err := operations.ServerProfileByName(interactivePullServer)
+			pretty.Guard(err == nil, 3, "%v", err)
+			profile.Apply()
+			interactivePullOrigin = profile.Origin
+		}
+		err := wizard.Pull(interactivePullOrigin)
+		if err != nil {
+			pretty.Exit(2, "%v", err)
+		}
+	},
+}
+
+func init() {
+	if common.Product.IsLegacy() {
+		interactiveCmd.AddCommand(wizardPullCmd)
+	}
+
+	wizardPullCmd.Flags().StringVarP(&interactivePullOrigin, "origin", "o", common.RccRemoteOrigin(), "URL of remote origin to list and pull catalogs from.")
+	wizardPullCmd.Flags().StringVarP(&interactivePullServer, "server", "s", "", "Name of saved server profile to list and pull catalogs from.")
+}
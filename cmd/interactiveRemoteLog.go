@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pretty"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	remoteLogFile   string
+	remoteLogLines  int
+	remoteLogFollow bool
+	remoteLogFull   bool
+)
+
+var remoteLogCmd = &cobra.Command{
+	Use:     "remotelog",
+	Aliases: []string{"remote-log", "hostlog"},
+	Short:   "Show and follow log of rccremote server running on this machine.",
+	Long: `Show last lines of rccremote server log (by default rccremote.log in
+ROBOCORP_HOME, which rccremote writes unless started with "-log -"), with
+errors and warnings highlighted, so that failed server starts and denied
+requests can be debugged without leaving interactive use.
+
+With --follow, new log lines are shown as they appear (until interrupted),
+and with --full, whole log is shown instead of last lines only.`,
+	Example: `
+  rcc interactive remotelog --lines 50
+  rcc interactive remotelog --follow
+  rcc interactive remotelog --full --log /var/log/rccremote.log`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(remoteLogFile) == 0 {
+			remoteLogFile = common.RccRemoteLogLocation()
+		}
+		count := remoteLogLines
+		if remoteLogFull {
+			count = 0
+		}
+		lines, offset, err := operations.TailLog(remoteLogFile, count)
+		pretty.Guard(err == nil, 1, "Could not read rccremote log, reason: %v", err)
+		operations.Yankable("log path", remoteLogFile)
+		operations.ShowRemoteLog(remoteLogFile, lines)
+		if remoteLogFollow && pretty.Interactive {
+			err = operations.FollowLog(remoteLogFile, offset, time.Second)
+			pretty.Guard(err == nil, 2, "Could not follow rccremote log, reason: %v", err)
+		}
+		pretty.Ok()
+	},
+}
+
+func init() {
+	interactiveCmd.AddCommand(remoteLogCmd)
+
+	remoteLogCmd.Flags().StringVarP(&remoteLogFile, "log", "", "", "Log file to show. Default is rccremote.log in ROBOCORP_HOME.")
+	remoteLogCmd.Flags().IntVarP(&remoteLogLines, "lines", "n", 20, "How many last lines of log to show.")
+	remoteLogCmd.Flags().BoolVarP(&remoteLogFollow, "follow", "f", false, "Keep showing new log lines as they appear, until interrupted.")
+	remoteLogCmd.Flags().BoolVarP(&remoteLogFull, "full", "", false, "Show whole log, not just last lines.")
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/joshyorko/rcc/wizard"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	robotsDirectory string
+	robotsSort      string
+	robotsSearch    string
+	robotsStale     bool
+	robotsSelect    string
+	robotsAddRoot   []string
+	robotsDropRoot  []string
+)
+
+var wizardRobotsCmd = &cobra.Command{
+	Use:   "robots",
+	Short: "Browse robots of monorepo interactively, with search and sorting.",
+	Long: `Browse robots (robot.yaml files) found under directory interactively. List can
+be searched (type "/text"), sorted by name, path, modified (newest first), or
+env (environment status), and limited to robots with stale environments, that
+is, ones whose environment is not yet in hololib.
+
+Chosen sort order is remembered in interactive.yaml in ROBOCORP_HOME for the
+next session, and so are previously visited directories. Additional scan roots
+(searched in addition to --directory) can be configured with --add-root and
+--remove-root options, and inside browser, "d" jumps to any configured root,
+recently visited directory, or other path without restarting.`,
+	Example: `
+  rcc interactive robots --directory ./monorepo --sort modified --stale
+  rcc interactive robots --add-root ~/work/robots --add-root ~/shared/robots`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !pretty.Interactive {
+			pretty.Exit(1, "This is for interactive use only. Do not use in scripting/CI!")
+		}
+		if common.DebugFlag() {
+			defer common.Stopwatch("Interactive robots lasted").Report()
+		}
+		for _, root := range robotsAddRoot {
+			err := operations.AddRobotScanRoot(root)
+			pretty.Guard(err == nil, 3, "%v", err)
+		}
+		for _, root := range robotsDropRoot {
+			err := operations.RemoveRobotScanRoot(root)
+			pretty.Guard(err == nil, 3, "%v", err)
+		}
+		if len(robotsSort) > 0 {
+			err := operations.SortRobots(nil, robotsSort)
+			pretty.Guard(err == nil, 1, "%v", err)
+		}
+		err := wizard.Robots(operations.RobotScanRoots(robotsDirectory), robotsSort, robotsSearch, robotsSelect, robotsStale)
+		if err != nil {
+			pretty.Exit(2, "%v", err)
+		}
+	},
+}
+
+func init() {
+	if common.Product.IsLegacy() {
+		interactiveCmd.AddCommand(wizardRobotsCmd)
+	}
+
+	wizardRobotsCmd.Flags().StringVarP(&robotsDirectory, "directory", "d", ".", "Root directory to search robots from.")
+	wizardRobotsCmd.Flags().StringVarP(&robotsSort, "sort", "", "", "Initial sort order: name, path, modified, or env. Default is remembered one.")
+	wizardRobotsCmd.Flags().StringVarP(&robotsSearch, "filter", "", "", "Initial search text for robot paths.")
+	wizardRobotsCmd.Flags().StringArrayVarP(&robotsAddRoot, "add-root", "", nil, "Add directory as additional scan root for this and later sessions. Can be repeated.")
+	wizardRobotsCmd.Flags().StringArrayVarP(&robotsDropRoot, "remove-root", "", nil, "Remove directory from additional scan roots. Can be repeated.")
+	wizardRobotsCmd.Flags().BoolVarP(&robotsStale, "stale", "", false, "Initially show only robots with stale environments.")
+	wizardRobotsCmd.Flags().StringVarP(&robotsSelect, "select", "", "", "Initially show details of robot in this path.")
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"maps"
+	"path/filepath"
+	"strconv"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/joshyorko/rcc/robot"
+	"github.com/joshyorko/rcc/wizard"
+
+	"github.com/spf13/cobra"
+)
+
+func robotTaskNames(config robot.Robot) func(bool) []string {
+	return func(developer bool) []string {
+		previous := common.DeveloperFlag
+		defer func() { common.DeveloperFlag = previous }()
+		common.DeveloperFlag = developer
+		result := []string{}
+		for _, quoted := range config.AvailableTasks() {
+			name, err := strconv.Unquote(quoted)
+			if err != nil {
+				name = quoted
+			}
+			result = append(result, name)
+		}
+		return result
+	}
+}
+
+// simulatedWorkItems starts local work item simulator for robot, and gives
+// run environment which makes robot use it.
+func simulatedWorkItems(robotfile string, config robot.Robot, extra map[string]string) map[string]string {
+	input, ok := operations.WorkItemsFile(robotfile)
+	if !ok {
+		pretty.Warning("Work item simulator was selected, but there is no %q.", input)
+		return extra
+	}
+	output := filepath.Join(config.ArtifactDirectory(), "work-items.json")
+	queue, err := operations.LoadWorkItemQueue(input, output)
+	pretty.Guard(err == nil, 3, "%v", err)
+	base, err := queue.Serve("127.0.0.1:0")
+	pretty.Guard(err == nil, 3, "Could not start work item simulator, reason: %v", err)
+	common.Log("Output work items of this run are written into %q.", output)
+	environment := make(map[string]string)
+	maps.Copy(environment, extra)
+	maps.Copy(environment, queue.Environment(base))
+	return environment
+}
+
+var wizardRunCmd = &cobra.Command{
+	Use:   "run",
+	Short: "Choose run parameters and task of robot interactively, and run it.",
+	Long: `Choose run parameters and task of robot interactively, and run it.
+Before launching, extra environment variables, --dev and --no-pip-freeze
+flags, space name, and timeout can be changed. Robots with
+devdata/work-items.json can also be run against local work item simulator
+(see "rcc workitems serve"), with output items written into artifacts. Chosen values are remembered
+per robot in interactive.yaml in ROBOCORP_HOME, and used as defaults on next
+run. Arguments after -- are passed to task, like with "rcc run".`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !pretty.Interactive {
+			pretty.Exit(1, "This is for interactive use only. Do not use in scripting/CI!")
+		}
+		if common.DebugFlag() {
+			defer common.Stopwatch("Interactive run lasted").Report()
+		}
+		robotfile, err := filepath.Abs(robotFile)
+		pretty.Guard(err == nil, 2, "%v", err)
+		config, err := robot.LoadRobotYaml(robotfile, false)
+		pretty.Guard(err == nil, 2, "%v", err)
+		parameters, err := wizard.RunParameters(robotfile, robotTaskNames(config))
+		if err != nil {
+			pretty.Exit(2, "%v", err)
+		}
+		robotFile, runTask = robotfile, parameters.Task
+		common.HolotreeSpace, common.DeveloperFlag = parameters.Space, parameters.Developer
+		runTimeout, runNoPipFreeze, runEnvironment = parameters.Duration(), parameters.NoPipFreeze, parameters.Environment
+		if parameters.WorkItems {
+			runEnvironment = simulatedWorkItems(robotfile, config, parameters.Environment)
+		}
+		runRobotTask(args)
+	},
+}
+
+func init() {
+	interactiveCmd.AddCommand(wizardRunCmd)
+	wizardRunCmd.Flags().StringVarP(&robotFile, "robot", "r", "robot.yaml", "Full path to the 'robot.yaml' configuration file.")
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pretty"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	serverDescription   string
+	serverDomain        string
+	serverAuthorization bool
+	serverPassphrase    string
+	serverOverwrite     bool
+	serverKeep          bool
+)
+
+var serversCmd = &cobra.Command{
+	Use:     "servers",
+	Aliases: []string{"server", "remotes"},
+	Short:   "Show saved rccremote server profiles, and share them with team.",
+	Long: `Show saved rccremote server profiles (kept in servers.yaml in ROBOCORP_HOME).
+Saved profile can be used by name, for example with "rcc interactive pull
+--server name", instead of typing its origin, domain, and authorization again.
+
+Profiles can be exported into sharable file, and team provided file can be
+imported (and merged) with subcommands. Authorizations are stripped from
+exported files, unless --passphrase is given, in which case they are
+encrypted with it.`,
+	Example: `
+  rcc interactive servers add team https://rccremote.example.com:4653 --domain robots
+  rcc interactive servers export team-servers.yaml --passphrase secret
+  rcc interactive servers import team-servers.yaml --passphrase secret`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		profiles, err := operations.ServerProfiles()
+		pretty.Guard(err == nil, 1, "Could not load server profiles, reason: %v", err)
+		if jsonFlag {
+			out, err := operations.NiceJsonOutput(profiles)
+			pretty.Guard(err == nil, 2, "%s", err)
+			fmt.Println(out)
+			return
+		}
+		pretty.Guard(len(profiles) > 0, 3, "No server profiles saved. Add one with: rcc interactive servers add <name> <origin>")
+		operations.ServerProfileTable(profiles).WriteText(os.Stderr)
+		operations.Yankable("servers file", common.ServerProfilesLocation())
+	},
+}
+
+var serversAddCmd = &cobra.Command{
+	Use:   "add <name> <origin>",
+	Short: "Save rccremote server profile (or replace existing one with same name).",
+	Long: `Save rccremote server profile (or replace existing one with same name).
+With --authorization, authorization is read from RCC_REMOTE_AUTHORIZATION
+environment variable, so that it does not end up in shell history.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		profile := &operations.ServerProfile{
+			Name:        args[0],
+			Origin:      args[1],
+			Description: serverDescription,
+			Domain:      serverDomain,
+		}
+		if serverAuthorization {
+			authorization, ok := common.RccRemoteAuthorization()
+			pretty.Guard(ok, 1, "Option --authorization needs %s environment variable.", common.RCC_REMOTE_AUTHORIZATION)
+			profile.Authorization = authorization
+		}
+		err := operations.SaveServerProfile(profile)
+		pretty.Guard(err == nil, 2, "Could not save server profile, reason: %v", err)
+		pretty.Ok()
+	},
+}
+
+var serversRemoveCmd = &cobra.Command{
+	Use:     "remove <name>",
+	Aliases: []string{"rm", "delete"},
+	Short:   "Remove saved rccremote server profile.",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := operations.RemoveServerProfile(args[0])
+		pretty.Guard(err == nil, 1, "%v", err)
+		pretty.Ok()
+	},
+}
+
+var serversExportCmd = &cobra.Command{
+	Use:   "export <filename> [name ...]",
+	Short: "Export saved server profiles (all or named ones) into sharable file.",
+	Long: `Export saved server profiles (all or named ones) into sharable file.
+Authorizations are stripped, unless --passphrase is given, in which case
+they are encrypted with it.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		count, err := operations.ExportServerProfiles(args[0], args[1:], serverPassphrase)
+		pretty.Guard(err == nil, 1, "Could not export server profiles, reason: %v", err)
+		if len(serverPassphrase) == 0 {
+			pretty.Note("Authorizations were not exported. Use --passphrase to include them encrypted.")
+		}
+		common.Log("Exported %d server profiles into %q.", count, args[0])
+		operations.Yankable("exported file", args[0])
+		pretty.Ok()
+	},
+}
+
+var serversImportCmd = &cobra.Command{
+	Use:   "import <filename>",
+	Short: "Import (and merge) server profiles from team provided file.",
+	Long: `Import (and merge) server profiles from team provided file. New profiles are
+added, and identical ones left as they are. When saved profile with same name
+differs, you are asked which one to keep; with --overwrite imported ones
+replace saved ones, and with --keep saved ones are kept. When not run
+interactively, saved ones are kept, unless --overwrite is given.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		pretty.Guard(!(serverOverwrite && serverKeep), 1, "Options --overwrite and --keep cannot be used together.")
+		incoming, err := operations.ReadServerProfiles(args[0], serverPassphrase)
+		pretty.Guard(err == nil, 2, "Could not read server profiles, reason: %v", err)
+		merge, err := operations.MergeServerProfiles(incoming, func(saved, incoming *operations.ServerProfile) bool {
+			if serverOverwrite || serverKeep || !pretty.Interactive {
+				return serverOverwrite
+			}
+			return pretty.Confirm(false, "Server profile %q differs: saved %s, imported %s. Replace saved one?", saved.Name, saved, incoming)
+		})
+		pretty.Guard(err == nil, 3, "Could not save server profiles, reason: %v", err)
+		if jsonFlag {
+			out, err := operations.NiceJsonOutput(merge)
+			pretty.Guard(err == nil, 4, "%s", err)
+			fmt.Println(out)
+			return
+		}
+		common.Log("Server profiles: %d added, %d replaced, %d kept, %d unchanged.", len(merge.Added), len(merge.Replaced), len(merge.Kept), len(merge.Unchanged))
+		if len(merge.Kept) > 0 {
+			pretty.Note("Kept saved versions of %q. Use --overwrite to replace them.", merge.Kept)
+		}
+		pretty.Ok()
+	},
+}
+
+func init() {
+	interactiveCmd.AddCommand(serversCmd)
+	serversCmd.AddCommand(serversAddCmd)
+	serversCmd.AddCommand(serversRemoveCmd)
+	serversCmd.AddCommand(serversExportCmd)
+	serversCmd.AddCommand(serversImportCmd)
+
+	serversCmd.PersistentFlags().BoolVarP(&jsonFlag, "json", "j", false, "Output in JSON format.")
+	serversAddCmd.Flags().StringVarP(&serverDescription, "description", "", "", "Description of server profile.")
+	serversAddCmd.Flags().StringVarP(&serverDomain, "domain", "", "", "Catalog domain of server (like RCC_REMOTE_DOMAIN).")
+	serversAddCmd.Flags().BoolVarP(&serverAuthorization, "authorization", "", false, "Save authorization from RCC_REMOTE_AUTHORIZATION environment variable.")
+	serversExportCmd.Flags().StringVarP(&serverPassphrase, "passphrase", "", "", "Encrypt authorizations with this passphrase (otherwise they are stripped).")
+	serversImportCmd.Flags().StringVarP(&serverPassphrase, "passphrase", "", "", "Decrypt authorizations with this passphrase.")
+	serversImportCmd.Flags().BoolVarP(&serverOverwrite, "overwrite", "", false, "Replace saved profiles that differ from imported ones.")
+	serversImportCmd.Flags().BoolVarP(&serverKeep, "keep", "", false, "Keep saved profiles that differ from imported ones, without asking.")
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/joshyorko/rcc/wizard"
+
+	"github.com/spf13/cobra"
+)
+
+var wizardSharedCmd = &cobra.Command{
+	Use:   "shared",
+	Short: "Enable shared holotree interactively, with elevated rights.",
+	Long: `Enable shared holotree interactively. Detects if shared holotree is already
+enabled on this machine and initialized for current user, explains what will
+be changed, and then runs "rcc holotree shared --enable" with elevated rights
+(sudo on Linux and macOS, UAC prompt on Windows) and "rcc holotree init" as
+current user. Result is verified with same checks as diagnostics use.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !pretty.Interactive {
+			pretty.Exit(1, "This is for interactive use only. Do not use in scripting/CI!")
+		}
+		if common.DebugFlag() {
+			defer common.Stopwatch("Interactive shared lasted").Report()
+		}
+		err := wizard.SharedHolotree()
+		if err != nil {
+			pretty.Exit(2, "%v", err)
+		}
+		pretty.Ok()
+	},
+}
+
+func init() {
+	interactiveCmd.AddCommand(wizardSharedCmd)
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/joshyorko/rcc/wizard"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	spacesDirectory string
+	spacesSearch    string
+)
+
+var wizardSpacesCmd = &cobra.Command{
+	Use:   "spaces",
+	Short: "Browse holotree spaces interactively, with robots that produced them.",
+	Long: `Browse holotree spaces interactively, and see which robot (and so which
+conda.yaml) produced each space. Spaces are matched to robots found under scan
+roots (--directory and roots configured with "rcc interactive robots
+--add-root") by blueprint of their environment, and to robots recorded as
+users of that space. Robots whose environment has changed since they used
+space are marked as "changed".
+
+List can be searched (type "/text") by space name, path, blueprint, or robot
+path. Selecting space shows its robots, and selecting robot from there jumps
+to that robot.`,
+	Example: `
+  rcc interactive spaces --directory ./monorepo --filter invoice`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !pretty.Interactive {
+			pretty.Exit(1, "This is for interactive use only. Do not use in scripting/CI!")
+		}
+		if common.DebugFlag() {
+			defer common.Stopwatch("Interactive spaces lasted").Report()
+		}
+		err := wizard.Spaces(operations.RobotScanRoots(spacesDirectory), spacesSearch)
+		if err != nil {
+			pretty.Exit(2, "%v", err)
+		}
+	},
+}
+
+func init() {
+	if common.Product.IsLegacy() {
+		interactiveCmd.AddCommand(wizardSpacesCmd)
+	}
+
+	wizardSpacesCmd.Flags().StringVarP(&spacesDirectory, "directory", "d", ".", "Root directory to search robots from.")
+	wizardSpacesCmd.Flags().StringVarP(&spacesSearch, "filter", "", "", "Initial search text for spaces.")
+}
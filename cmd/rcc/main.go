@@ -78,15 +78,21 @@ func ExitProtection() {
 		if ok {
 			exit.ShowMessage()
 			pretty.Highlight("[rcc] exit status will be: %d!", exit.Code)
+			pretty.EmitSummary(cmd.Origin(), exit.Code)
+			pretty.NotifyCompletion(cmd.Origin(), exit.Code)
 			cloud.WaitTelemetry()
 			common.WaitLogs()
 			os.Exit(exit.Code)
 		}
 		cloud.InternalBackgroundMetric(common.ControllerIdentity(), "rcc.panic.origin", cmd.Origin())
+		pretty.EmitSummary(cmd.Origin(), 2)
+		pretty.NotifyCompletion(cmd.Origin(), 2)
 		cloud.WaitTelemetry()
 		common.WaitLogs()
 		panic(status)
 	}
+	pretty.EmitSummary(cmd.Origin(), 0)
+	pretty.NotifyCompletion(cmd.Origin(), 0)
 	cloud.WaitTelemetry()
 	common.WaitLogs()
 }
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/joshyorko/rcc/remotree"
+)
+
+const (
+	domainsUsage = `Usage: rccremote domains add|list [options]
+
+Domains let single rccremote serve multiple teams. Each domain has its own
+catalog patterns (like "*-linux_amd64" or "0123abcd*"), robot package name
+patterns (for robots served with -robots option), and optional tokens
+that clients must give as RCC_REMOTE_AUTHORIZATION. Clients select domain
+with RCC_REMOTE_DOMAIN. Domains are stored in domains.yaml in hold directory,
+and running server picks up changes automatically.
+`
+)
+
+func domainsFlags(name string, hold *string) *flag.FlagSet {
+	flags := flag.NewFlagSet(fmt.Sprintf("domains %s", name), flag.ExitOnError)
+	flags.StringVar(hold, "hold", defaultHoldLocation(), "Directory where rccremote keeps its HOLD files (and domains.yaml).")
+	return flags
+}
+
+func addDomain(arguments []string) {
+	var hold, name string
+	var catalogs, robots, tokens stringList
+	flags := domainsFlags("add", &hold)
+	flags.StringVar(&name, "name", "", "Name of domain to add or extend.")
+	flags.Var(&catalogs, "catalog", "Catalog name glob pattern served in domain (repeatable). Default is all catalogs.")
+	flags.Var(&robots, "robot", "Robot package name glob pattern served in domain (repeatable). Default is all robots.")
+	flags.Var(&tokens, "token", "Authorization token accepted in domain (repeatable). Only its digest is stored.")
+	flags.Parse(arguments)
+
+	domains, err := remotree.LoadDomains(hold)
+	pretty.Guard(err == nil, 3, "%v", err)
+	err = domains.Add(name, catalogs, tokens)
+	if err == nil {
+		err = domains.AddRobots(name, robots)
+	}
+	pretty.Guard(err == nil, 4, "%v", err)
+	err = domains.Save(hold)
+	pretty.Guard(err == nil, 5, "Could not save domains, reason: %v", err)
+	common.Log("Domain %q saved into %q.", name, remotree.DomainsFilename(hold))
+}
+
+func listDomains(arguments []string) {
+	var hold string
+	flags := domainsFlags("list", &hold)
+	flags.Parse(arguments)
+
+	domains, err := remotree.LoadDomains(hold)
+	pretty.Guard(err == nil, 3, "%v", err)
+	if len(domains.Domains) == 0 {
+		common.Log("No domains defined in %q, so all catalogs are served to everyone.", remotree.DomainsFilename(hold))
+		return
+	}
+	tabbed := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+	tabbed.Write([]byte("Domain\tTokens\tCatalogs\tRobots\n"))
+	tabbed.Write([]byte("------\t------\t--------\t------\n"))
+	for _, domain := range domains.Domains {
+		catalogs := strings.Join(domain.Catalogs, ", ")
+		if len(catalogs) == 0 {
+			catalogs = "*"
+		}
+		robots := strings.Join(domain.Robots, ", ")
+		if len(robots) == 0 {
+			robots = "*"
+		}
+		tabbed.Write([]byte(fmt.Sprintf("%s\t%d\t%s\t%s\n", domain.Name, len(domain.Tokens), catalogs, robots)))
+	}
+	tabbed.Flush()
+}
+
+func manageDomains(arguments []string) {
+	if len(arguments) == 0 {
+		fmt.Fprint(os.Stderr, domainsUsage)
+		pretty.Exit(1, "Missing domains command, use 'add' or 'list'.")
+	}
+	switch arguments[0] {
+	case "add":
+		addDomain(arguments[1:])
+	case "list":
+		listDomains(arguments[1:])
+	default:
+		fmt.Fprint(os.Stderr, domainsUsage)
+		pretty.Exit(2, "Unknown domains command %q, use 'add' or 'list'.", arguments[0])
+	}
+}
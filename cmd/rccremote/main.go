@@ -4,6 +4,9 @@ import (
 	"flag"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joshyorko/rcc/common"
 	"github.com/joshyorko/rcc/pathlib"
@@ -12,15 +15,35 @@ import (
 )
 
 var (
-	domainId    string
-	serverName  string
-	serverPort  int
-	versionFlag bool
-	holdingArea string
-	debugFlag   bool
-	traceFlag   bool
+	domainId     string
+	serverName   string
+	serverPort   int
+	versionFlag  bool
+	holdingArea  string
+	robotsArea   string
+	debugFlag    bool
+	traceFlag    bool
+	proxyFlag    bool
+	allowCidrs   stringList
+	certificate  string
+	privateKey   string
+	clientCA     string
+	drainTimeout time.Duration
+	logFile      string
+	configFile   string
 )
 
+type stringList []string
+
+func (it *stringList) String() string {
+	return strings.Join(*it, ",")
+}
+
+func (it *stringList) Set(value string) error {
+	*it = append(*it, value)
+	return nil
+}
+
 func defaultHoldLocation() string {
 	where, err := pathlib.Abs(filepath.Join(pathlib.TempDir(), "rccremotehold"))
 	if err != nil {
@@ -37,7 +60,113 @@ func init() {
 	flag.StringVar(&serverName, "hostname", "localhost", "Hostname/address to bind server to.")
 	flag.IntVar(&serverPort, "port", 4653, "Port to bind server in given hostname.")
 	flag.StringVar(&holdingArea, "hold", defaultHoldLocation(), "Directory where to put HOLD files once known.")
-	flag.StringVar(&domainId, "domain", "personal", "Symbolic domain that this peer serves.")
+	flag.StringVar(&robotsArea, "robots", "", "Directory of robot packages (as <name>/<version>.zip) to serve for \"rcc pull rcc-remote://<domain>/<name>\". Default is not to serve robots.")
+	flag.StringVar(&domainId, "domain", "personal", "Symbolic domain served to clients that do not request any domain. See 'rccremote domains'.")
+	flag.Var(&allowCidrs, "allow-cidr", "Allow only peers from this CIDR or address (repeatable). Default is to allow all peers.")
+	flag.StringVar(&certificate, "tls-cert", "", "Server certificate (PEM) file. Together with -tls-key, serve HTTPS instead of HTTP.")
+	flag.StringVar(&privateKey, "tls-key", "", "Server private key (PEM) file for -tls-cert.")
+	flag.StringVar(&clientCA, "client-ca", "", "CA certificate (PEM) file. Requires clients to present certificate signed by this CA (mutual TLS).")
+	flag.DurationVar(&drainTimeout, "drain-timeout", 30*time.Second, "How long to wait in-flight transfers to complete on shutdown (SIGTERM/SIGINT/SIGHUP).")
+	flag.StringVar(&logFile, "log", "", "File where server log is also written. Default is rccremote.log in ROBOCORP_HOME. Use \"-\" to disable.")
+	flag.BoolVar(&proxyFlag, "proxy", false, "Also serve read-through caching proxy for PyPI (/pypi/simple/) and conda (/conda/) using settings.yaml endpoints as upstreams.")
+	flag.StringVar(&configFile, "config", "", "YAML configuration file with same settings as flags. Flags override it. SIGHUP reloads access control, verbosity, and drain timeout from it.")
+}
+
+// explicitFlags gives names of flags given on command line.
+func explicitFlags() map[string]bool {
+	explicit := make(map[string]bool)
+	flag.Visit(func(it *flag.Flag) {
+		explicit[it.Name] = true
+	})
+	return explicit
+}
+
+// applyConfig sets settings from configuration file, unless same setting was
+// given as flag on command line.
+func applyConfig(config *remotree.Config, explicit map[string]bool) error {
+	drain, err := config.Drain()
+	if err != nil {
+		return err
+	}
+	if !explicit["hostname"] && len(config.Hostname) > 0 {
+		serverName = config.Hostname
+	}
+	if !explicit["port"] && config.Port > 0 {
+		serverPort = config.Port
+	}
+	if !explicit["domain"] && len(config.Domain) > 0 {
+		domainId = config.Domain
+	}
+	if !explicit["hold"] && len(config.Hold) > 0 {
+		holdingArea = config.Hold
+	}
+	if !explicit["robots"] && len(config.Robots) > 0 {
+		robotsArea = config.Robots
+	}
+	if !explicit["log"] && len(config.Log) > 0 {
+		logFile = config.Log
+	}
+	if !explicit["proxy"] {
+		proxyFlag = config.Proxy
+	}
+	if !explicit["debug"] {
+		debugFlag = config.Debug
+	}
+	if !explicit["trace"] {
+		traceFlag = config.Trace
+	}
+	if !explicit["drain-timeout"] && drain > 0 {
+		drainTimeout = drain
+	}
+	if !explicit["allow-cidr"] {
+		allowCidrs = stringList(config.AllowCidrs)
+	}
+	if !explicit["tls-cert"] {
+		certificate = config.Certificate
+	}
+	if !explicit["tls-key"] {
+		privateKey = config.Key
+	}
+	if !explicit["client-ca"] {
+		clientCA = config.ClientCA
+	}
+	return nil
+}
+
+func currentAccess() *remotree.Access {
+	return &remotree.Access{
+		AllowCidrs:  allowCidrs,
+		Certificate: certificate,
+		Key:         privateKey,
+		ClientCA:    clientCA,
+	}
+}
+
+// restartNeeded gives a snapshot of settings which are only used on startup.
+func restartNeeded() string {
+	return strings.Join([]string{serverName, strconv.Itoa(serverPort), domainId, holdingArea, robotsArea, logFile, strconv.FormatBool(proxyFlag)}, "|")
+}
+
+func reloader(explicit map[string]bool) remotree.Reload {
+	if len(configFile) == 0 {
+		return nil
+	}
+	return func() (*remotree.Access, time.Duration, error) {
+		config, err := remotree.LoadConfig(configFile)
+		if err != nil {
+			return nil, 0, err
+		}
+		before := restartNeeded()
+		err = applyConfig(config, explicit)
+		if err != nil {
+			return nil, 0, err
+		}
+		if before != restartNeeded() {
+			common.Log("Changes in hostname, port, domain, hold, robots, log, or proxy of %q need restart of rccremote.", configFile)
+		}
+		common.DefineVerbosity(false, debugFlag, traceFlag)
+		return currentAccess(), drainTimeout, nil
+	}
 }
 
 func ExitProtection() {
@@ -60,20 +189,47 @@ func showVersion() {
 	os.Exit(0)
 }
 
-func process() {
+func process(explicit map[string]bool) {
 	if versionFlag {
 		showVersion()
 	}
-	pretty.Guard(common.SharedHolotree, 1, "Shared holotree must be enabled and in use for rccremote to work.")
+	pretty.GuardCoded(common.SharedHolotree, 1, common.ErrSharedHolotree, "Shared holotree must be enabled and in use for rccremote to work.")
+	if logFile != "-" {
+		if len(logFile) == 0 {
+			logFile = common.RccRemoteLogLocation()
+		}
+		stop, err := remotree.LogInto(logFile)
+		if err != nil {
+			pretty.Warning("Could not write log into %q, reason: %v", logFile, err)
+		} else {
+			defer stop()
+		}
+	}
 	common.Log("Remote for rcc starting (%s) ...", common.Version)
-	remotree.Serve(serverName, serverPort, domainId, holdingArea)
+	if len(robotsArea) > 0 {
+		common.Log("Serving robot packages from %q.", robotsArea)
+	}
+	err := remotree.Serve(serverName, serverPort, domainId, holdingArea, robotsArea, proxyFlag, currentAccess(), drainTimeout, reloader(explicit))
+	pretty.Guard(err == nil, 2, "Remote for rcc failed, reason: %v", err)
 }
 
 func main() {
 	defer ExitProtection()
 	pretty.Setup()
 
+	if len(os.Args) > 1 && os.Args[1] == "domains" {
+		manageDomains(os.Args[2:])
+		return
+	}
 	flag.Parse()
+	explicit := explicitFlags()
+	if len(configFile) > 0 {
+		config, err := remotree.LoadConfig(configFile)
+		if err == nil {
+			err = applyConfig(config, explicit)
+		}
+		pretty.Guard(err == nil, 3, "Could not use configuration %q, reason: %v", configFile, err)
+	}
 	common.DefineVerbosity(false, debugFlag, traceFlag)
-	process()
+	process(explicit)
 }
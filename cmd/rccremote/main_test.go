@@ -1 +1,31 @@
 package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/joshyorko/rcc/hamlet"
+	"github.com/joshyorko/rcc/remotree"
+)
+
+func TestFlagsOverrideConfigFile(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	serverName, serverPort, drainTimeout = "localhost", 4653, 30*time.Second
+	allowCidrs = stringList{"10.0.0.0/8"}
+	config := &remotree.Config{
+		Hostname:     "0.0.0.0",
+		Port:         4700,
+		DrainTimeout: "1m",
+		AllowCidrs:   []string{"192.168.0.0/16"},
+		Debug:        true,
+	}
+	must.Nil(applyConfig(config, map[string]bool{"port": true, "allow-cidr": true}))
+	must.Equal("0.0.0.0", serverName)
+	must.Equal(4653, serverPort)
+	must.Equal(time.Minute, drainTimeout)
+	must.Equal(stringList{"10.0.0.0/8"}, allowCidrs)
+	must.True(debugFlag)
+
+	wont.Nil(applyConfig(&remotree.Config{DrainTimeout: "later"}, map[string]bool{}))
+}
@@ -49,7 +49,7 @@ executed by 'rcc robot run-from-bundle'.`,
 			holozip = config.Holozip()
 		}
 
-		_, _, err = htfs.NewEnvironment(condafile, holozip, false, false, operations.PullCatalog)
+		_, _, err = htfs.NewEnvironment(condafile, holozip, nil, false, false, operations.PullCatalog)
 		pretty.Guard(err == nil, 3, "Failed to create environment: %v", err)
 
 		// 2. Export holotree
@@ -61,7 +61,7 @@ executed by 'rcc robot run-from-bundle'.`,
 		pretty.Guard(err == nil, 4, "Failed to initialize holotree: %v", err)
 
 		// Find the full catalog name
-		catalogs := htfs.CatalogNames()
+		catalogs := htfs.AllCatalogNames()
 		var catalogName string
 		for _, name := range catalogs {
 			if strings.HasPrefix(name, hash) {
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/conda"
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/joshyorko/rcc/settings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	deniedLicenses []string
+)
+
+func jsonLicenseReport(packages []*conda.PackageLicense, violations map[*conda.PackageLicense]string) {
+	denied := make(map[string]string)
+	for entry, pattern := range violations {
+		denied[entry.Name] = pattern
+	}
+	report := map[string]any{
+		"packages": packages,
+		"licenses": conda.GroupedLicenses(packages),
+		"denied":   denied,
+	}
+	body, err := json.MarshalIndent(report, "", "  ")
+	pretty.Guard(err == nil, 1, "Could not create json, reason: %v", err)
+	common.Stdout("%s\n", body)
+}
+
+func humaneLicenseReport(packages []*conda.PackageLicense, violations map[*conda.PackageLicense]string) {
+	grouped := conda.GroupedLicenses(packages)
+	licenses := make([]string, 0, len(grouped))
+	for license := range grouped {
+		licenses = append(licenses, license)
+	}
+	sort.Strings(licenses)
+	common.WaitLogs()
+	tabbed := tabwriter.NewWriter(os.Stderr, 2, 4, 2, ' ', 0)
+	tabbed.Write([]byte("License\tPackage\tVersion\tOrigin\tPolicy\n"))
+	tabbed.Write([]byte("-------\t-------\t-------\t------\t------\n"))
+	for _, license := range licenses {
+		tabbed.Write([]byte(fmt.Sprintf("%s (%d)\t\t\t\t\n", license, len(grouped[license]))))
+		for _, entry := range grouped[license] {
+			policy := fmt.Sprintf("%sok%s", pretty.Green, pretty.Reset)
+			if pattern, ok := violations[entry]; ok {
+				policy = fmt.Sprintf("%sdenied by %q%s", pretty.Red, pattern, pretty.Reset)
+			}
+			tabbed.Write([]byte(fmt.Sprintf("\t%s\t%s\t%s\t%s\n", entry.Name, entry.Version, entry.Origin, policy)))
+		}
+	}
+	tabbed.Write([]byte("\n"))
+	tabbed.Flush()
+}
+
+var robotLicensesCmd = &cobra.Command{
+	Use:   "licenses",
+	Short: "Report licenses of robot execution environment packages, and check them against policy.",
+	Long: `Report licenses of robot execution environment packages, and check them against policy.
+
+Licenses are resolved from package metadata inside built environment (conda-meta
+and python dist-info). Denied licenses come from "deny-licenses" list in
+settings.yaml and from --deny options. Patterns are case insensitive and can
+have shell style wildcards, like "GPL-3.0*" or "AGPL*". If any package has
+denied license, command fails with nonzero exit code.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if common.DebugFlag() {
+			defer common.Stopwatch("Robot licenses run lasted").Report()
+		}
+		simple, _, _, label := operations.LoadAnyTaskEnvironment(robotFile, forceFlag)
+		pretty.Guard(!simple, 1, "Cannot report licenses of simple robots.")
+		packages := conda.EnvironmentLicenses(label)
+		pretty.Guard(len(packages) > 0, 2, "Could not find any package metadata from %q.", label)
+		denied := append([]string{}, settings.Global.DeniedLicenses()...)
+		denied = append(denied, deniedLicenses...)
+		violations := conda.LicenseViolations(packages, denied)
+		if jsonFlag {
+			jsonLicenseReport(packages, violations)
+		} else {
+			humaneLicenseReport(packages, violations)
+		}
+		pretty.Guard(len(violations) == 0, 5, "Found %d packages with denied licenses.", len(violations))
+		pretty.Ok()
+	},
+}
+
+func init() {
+	robotCmd.AddCommand(robotLicensesCmd)
+	robotLicensesCmd.Flags().BoolVarP(&jsonFlag, "json", "j", false, "Output in JSON format")
+	robotLicensesCmd.Flags().BoolVarP(&forceFlag, "force", "f", false, "Forced environment update.")
+	robotLicensesCmd.Flags().StringArrayVarP(&deniedLicenses, "deny", "", []string{}, "Additional denied license pattern (repeatable), on top of settings.yaml deny-licenses.")
+	robotLicensesCmd.Flags().StringVarP(&robotFile, "robot", "r", "robot.yaml", "Full path to the 'robot.yaml' configuration file.")
+	robotLicensesCmd.Flags().StringVarP(&common.HolotreeSpace, "space", "s", "user", "Space to use for execution environment.")
+}
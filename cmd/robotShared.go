@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/htfs"
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/joshyorko/rcc/robot"
+
+	"github.com/spf13/cobra"
+)
+
+var robotSharedCmd = &cobra.Command{
+	Use:   "shared",
+	Short: "Show shared environment of robot and other robots using it.",
+	Long: `Show shared environment (sharedEnvironment: in robot.yaml) of robot, and
+which robots have used its holotree space. Shared environment is either path
+to conda.yaml (or directory having one), or alias defined in environments.yaml
+file of workspace (in robot directory or above it), like:
+
+  environments:
+    reporting:
+      conda: shared/reporting/conda.yaml
+      space: reporting
+
+All robots referencing same shared environment resolve to same blueprint and
+space, and environment is built only once.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		config, err := robot.LoadRobotYaml(robotFile, false)
+		pretty.GuardCoded(err == nil, 1, common.ErrRobotLoad, "%v", err)
+		shared, err := config.SharedEnvironment()
+		pretty.GuardCoded(err == nil, 2, common.ErrRobotValidation, "%v", err)
+		pretty.Guard(shared != nil, 3, "Robot %q does not use shared environment.", robotFile)
+		location := operations.SharedSpaceLocation(shared)
+		common.Stdout("Shared environment:  %s\n", shared.Name)
+		common.Stdout("Definition:          %s\n", shared.Conda)
+		if len(shared.Workspace) > 0 {
+			common.Stdout("Workspace:           %s\n", shared.Workspace)
+		}
+		common.Stdout("Space:               %s (%s)\n", shared.Space, location)
+		robots := htfs.LoadSpaceRobots(location).Sorted()
+		common.Stdout("\nRobots using this space: %d\n", len(robots))
+		for _, entry := range robots {
+			last := time.Unix(entry.Last, 0).Format(time.DateTime)
+			common.Stdout("  %s  %5d uses  %s\n", last, entry.Uses, entry.Robot)
+		}
+		common.Stdout("\n")
+		pretty.Ok()
+	},
+}
+
+func init() {
+	robotCmd.AddCommand(robotSharedCmd)
+	robotSharedCmd.Flags().StringVarP(&robotFile, "robot", "r", "robot.yaml", "Full path to the 'robot.yaml' configuration file.")
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/joshyorko/rcc/wizard"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	upgradeCheckFlag      bool
+	upgradeCompatibleFlag bool
+	upgradeTask           string
+)
+
+var robotUpgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Check and apply newer versions of pinned pip dependencies of robot.",
+	Long: `Check configured PyPI index for newer versions of pinned (==) pip dependencies
+in robot conda.yaml, and show report with compatible (same major version) and
+latest versions, and changelog links (from dependency line comments, or from
+PyPI project page).
+
+With --check, only report is shown. With --compatible, all compatible bumps
+are applied without asking. Otherwise bumps to apply are chosen interactively.
+After conda.yaml was changed, --task can be used to rebuild environment and
+run given task (like tests) against upgraded dependencies.
+
+Conda dependencies are not checked, only pip ones.`,
+	Example: `
+  rcc robot upgrade --check
+  rcc robot upgrade --compatible --task Tests
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if common.DebugFlag() {
+			defer common.Stopwatch("Robot upgrade lasted").Report()
+		}
+		if !upgradeCheckFlag && !upgradeCompatibleFlag && !pretty.Interactive {
+			pretty.Exit(1, "Choosing bumps is for interactive use only. Use --check or --compatible in scripting/CI!")
+		}
+		changed, err := wizard.Upgrade(robotFile, upgradeCheckFlag, upgradeCompatibleFlag)
+		pretty.Guard(err == nil, 2, "Upgrade failed, reason: %v", err)
+		if !changed || len(upgradeTask) == 0 {
+			pretty.Ok()
+			return
+		}
+		common.Log("--")
+		simple, config, todo, label := operations.LoadTaskWithEnvironment(robotFile, upgradeTask, false)
+		flags := captureRunFlags(false)
+		flags.Task = upgradeTask
+		operations.SelectExecutionModel(flags, simple, todo.Commandline(), config, todo, label, false, nil)
+	},
+}
+
+func init() {
+	robotCmd.AddCommand(robotUpgradeCmd)
+	robotUpgradeCmd.Flags().BoolVarP(&upgradeCheckFlag, "check", "c", false, "Only check and report newer versions, do not change anything.")
+	robotUpgradeCmd.Flags().BoolVarP(&upgradeCompatibleFlag, "compatible", "", false, "Apply all compatible version bumps without asking.")
+	robotUpgradeCmd.Flags().StringVarP(&upgradeTask, "task", "t", "", "Task to run (like tests) after dependencies were upgraded. <optional>")
+	robotUpgradeCmd.Flags().StringVarP(&robotFile, "robot", "r", "robot.yaml", "Full path to the 'robot.yaml' configuration file.")
+	robotUpgradeCmd.Flags().StringVarP(&common.HolotreeSpace, "space", "s", "user", "Space to use for rebuilt execution environment.")
+}
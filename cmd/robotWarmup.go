@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"strings"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pretty"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	warmupList string
+)
+
+func warmupTargets() operations.WarmupTargets {
+	if len(warmupList) == 0 {
+		return operations.WarmupTargets{{Robot: robotFile, Space: common.HolotreeSpace}}
+	}
+	targets, err := operations.ReadWarmupTargets(warmupList, common.HolotreeSpace)
+	pretty.Guard(err == nil, 1, "Could not read robot list %q, reason: %v", warmupList, err)
+	pretty.Guard(len(targets) > 0, 1, "No robots listed in %q.", warmupList)
+	return targets
+}
+
+var robotWarmupCmd = &cobra.Command{
+	Use:     "warmup",
+	Aliases: []string{"warm", "prewarm"},
+	Short:   "Prepare robot environment and space ahead of scheduled runs.",
+	Long: `Build or refresh environment of robot, restore it into holotree space, and
+validate that installed python packages can be imported, and then exit. This
+is intended to be run ahead of scheduled production runs, so that actual run
+can start instantly.
+
+Batch mode (--list) reads robots from file, one per line. Line has path to
+robot.yaml (relative to list file) and optional space name, which defaults
+to --space value. Lines starting with # are comments.`,
+	Example: `
+  rcc robot warmup -r robot.yaml --space production
+  rcc robot warmup --list scheduled-robots.txt`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if common.DebugFlag() {
+			defer common.Stopwatch("Robot warmup lasted").Report()
+		}
+		targets := warmupTargets()
+		total, failed := len(targets), 0
+		for at, target := range targets {
+			pretty.Note("%d/%d: Warming up %q into space %q.", at+1, total, target.Robot, target.Space)
+			result, err := operations.WarmupRobot(target, forceFlag)
+			switch {
+			case err != nil:
+				failed += 1
+				pretty.Warning("%d/%d: Warmup of %q failed, reason: %v", at+1, total, target.Robot, err)
+			case result.Simple:
+				common.Log("%d/%d: %q does not use environment, nothing to warm up.", at+1, total, target.Robot)
+			case len(result.Missing) > 0:
+				failed += 1
+				pretty.Warning("%d/%d: %q has %d/%d packages that cannot be imported: %s", at+1, total, target.Robot, len(result.Missing), result.Checked, strings.Join(result.Missing, ", "))
+			default:
+				common.Log("%s%d/%d: %q is ready at %q (%d packages validated in %s).%s", pretty.Green, at+1, total, target.Robot, result.Label, result.Checked, result.Elapsed.Round(time.Millisecond), pretty.Reset)
+			}
+		}
+		pretty.Guard(failed == 0, 2, "Warmup failed for %d of %d robots.", failed, total)
+		pretty.Ok()
+	},
+}
+
+func init() {
+	robotCmd.AddCommand(robotWarmupCmd)
+	robotWarmupCmd.Flags().StringVarP(&robotFile, "robot", "r", "robot.yaml", "Full path to the 'robot.yaml' configuration file.")
+	robotWarmupCmd.Flags().StringVarP(&warmupList, "list", "l", "", "File listing robots (and optional spaces) to warm up in batch.")
+	robotWarmupCmd.Flags().StringVarP(&common.HolotreeSpace, "space", "s", "user", "Client specific name to identify this environment.")
+	robotWarmupCmd.Flags().BoolVarP(&forceFlag, "force", "f", false, "Force environment rebuild.")
+}
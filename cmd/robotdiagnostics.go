@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"os"
+
 	"github.com/joshyorko/rcc/common"
 	"github.com/joshyorko/rcc/operations"
 	"github.com/joshyorko/rcc/pretty"
@@ -8,6 +10,10 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var (
+	diagnosticsOutput string
+)
+
 var robotDiagnosticsCmd = &cobra.Command{
 	Use:   "diagnostics",
 	Short: "Run system diagnostics to help resolve rcc issues.",
@@ -16,6 +22,16 @@ var robotDiagnosticsCmd = &cobra.Command{
 		if common.DebugFlag() {
 			defer common.Stopwatch("Diagnostic run lasted").Report()
 		}
+		if diagnosticsOutput == "sarif" {
+			status := operations.RunRobotDiagnostics(robotFile, productionFlag)
+			err := operations.WriteSarif(os.Stdout, operations.DiagnosticsAsSarif(status, robotFile))
+			pretty.Guard(err == nil, 1, "Error: %v", err)
+			fatal, fail, _, _ := status.Counts()
+			pretty.Guard(fatal+fail == 0, 2, "Robot diagnostics found %d problem(s).", fatal+fail)
+			pretty.Ok()
+			return
+		}
+		pretty.Guard(diagnosticsOutput == "text", 1, "Unknown output %q, use 'text' or 'sarif'.", diagnosticsOutput)
 		err := operations.PrintRobotDiagnostics(robotFile, jsonFlag, productionFlag)
 		if err != nil {
 			pretty.Exit(1, "Error: %v", err)
@@ -27,6 +43,7 @@ var robotDiagnosticsCmd = &cobra.Command{
 func init() {
 	robotCmd.AddCommand(robotDiagnosticsCmd)
 	robotDiagnosticsCmd.Flags().BoolVarP(&jsonFlag, "json", "j", false, "Output in JSON format")
+	robotDiagnosticsCmd.Flags().StringVarP(&diagnosticsOutput, "output", "", "text", "Output format, either 'text' or 'sarif' (for CI annotations).")
 	robotDiagnosticsCmd.Flags().BoolVarP(&productionFlag, "production", "p", false, "Checks for production level robots.")
 	robotDiagnosticsCmd.Flags().StringVarP(&robotFile, "robot", "r", "robot.yaml", "Full path to the 'robot.yaml' configuration file.")
 }
@@ -13,6 +13,7 @@ import (
 	"github.com/joshyorko/rcc/pathlib"
 	"github.com/joshyorko/rcc/pretty"
 	"github.com/joshyorko/rcc/set"
+	"github.com/joshyorko/rcc/settings"
 	"github.com/joshyorko/rcc/xviper"
 
 	"github.com/spf13/cobra"
@@ -21,11 +22,13 @@ import (
 var (
 	anythingIgnore  string
 	profilefile     string
+	mirrorAddress   string
 	profiling       *os.File
 	versionFlag     bool
 	silentFlag      bool
 	debugFlag       bool
 	traceFlag       bool
+	confirmFlag     bool
 	productFakeFlag bool // this is handled in common init
 
 	excludedCommands = []string{"completion"}
@@ -120,11 +123,14 @@ func init() {
 
 	rootCmd.PersistentFlags().BoolVarP(&productFakeFlag, "robocorp", "", false, "Select Robocorp toolset strategy.")
 	rootCmd.PersistentFlags().BoolVarP(&common.NoBuild, "no-build", "", false, "never allow building new environments, only use what exists already in hololib (also RCC_NO_BUILD=1)")
+	rootCmd.PersistentFlags().BoolVarP(&common.Offline, "offline", "", false, "fail fast on all network operations, and only use environments that exist already in hololib (also RCC_OFFLINE=1)")
 	rootCmd.PersistentFlags().BoolVarP(&common.NoRetryBuild, "no-retry-build", "", false, "no retry in case of first environment build fails, just report error immediately")
 	rootCmd.PersistentFlags().BoolVarP(&silentFlag, "silent", "", false, "be less verbose on output (also RCC_VERBOSITY=silent)")
 	rootCmd.PersistentFlags().BoolVarP(&common.Liveonly, "liveonly", "", false, "do not create base environment from live ... DANGER! For containers only!")
 	rootCmd.PersistentFlags().BoolVarP(&pathlib.Lockless, "lockless", "", false, "do not use file locking ... DANGER!")
 	rootCmd.PersistentFlags().BoolVarP(&pretty.Colorless, "colorless", "", false, "do not use colors in CLI UI")
+	rootCmd.PersistentFlags().BoolVarP(&pretty.PlainProgress, "plain-progress", "", false, "report progress as timestamped lines at fixed percentage checkpoints, for log collectors (default when output is piped; also RCC_PLAIN_PROGRESS=1)")
+	rootCmd.PersistentFlags().BoolVarP(&confirmFlag, "confirm", "", false, "ask confirmation in interactive terminal before destructive actions (holotree remove/delete, bulk cleanups, forced rebuilds)")
 	rootCmd.PersistentFlags().BoolVarP(&common.NoCache, "nocache", "", false, "do not use cache for credentials and tokens, always request them from cloud")
 
 	rootCmd.PersistentFlags().BoolVarP(&common.LogLinenumbers, "numbers", "", false, "put line numbers on rcc produced log output")
@@ -138,6 +144,8 @@ func init() {
 	rootCmd.PersistentFlags().BoolVarP(&common.NoTempManagement, "no-temp-management", "", common.NoTempManagement, "rcc wont do any temp directory management ... DO NOT USE (unless you know what you are doing)")
 	rootCmd.PersistentFlags().BoolVarP(&common.NoPycManagement, "no-pyc-management", "", common.NoPycManagement, "rcc wont do any .pyc file management ... DO NOT USE (unless you know what you are doing)")
 	rootCmd.PersistentFlags().StringArrayVarP(&common.LogHides, "log-hide", "", []string{}, "hide logging output that matches given text fragment and this option can be given multiple times")
+	rootCmd.PersistentFlags().StringVar(&common.SummaryTarget, "summary-to", "", "write single line JSON summary of run (exit code, durations, blueprint, warnings) at end into given file or \"fd:N\" (also RCC_SUMMARY_TO)")
+	rootCmd.PersistentFlags().StringVar(&mirrorAddress, "dashboard-mirror", "", "publish progress and log output as server-sent events on given local address, for example 127.0.0.1:8765")
 	rootCmd.PersistentFlags().BoolVarP(&common.BundledFlag, "bundled", "", common.BundledFlag, "used to tell rcc, that this is bundled use (do not use, unless you know what you are doing)")
 }
 
@@ -160,6 +168,11 @@ func initConfig() {
 	common.UnifyStageHandling()
 
 	pretty.Setup()
+	pretty.ConfigureNotifications(settings.Global.Notifications())
+	if len(mirrorAddress) > 0 {
+		_, err := pretty.StartMirror(mirrorAddress)
+		pretty.Guard(err == nil, 7, "Failed to start dashboard mirror on %q, reason %v.", mirrorAddress, err)
+	}
 
 	if common.WarrantyVoided() {
 		pretty.Warning("Note that 'rcc' is running in 'warranty voided' mode.")
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pretty"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	cleanupOptions = &operations.CleanupOptions{}
+)
+
+var rootCleanupCmd = &cobra.Command{
+	Use:   "cleanup",
+	Short: "Reclaim disk space by removing caches, temps, idle spaces, and unused catalogs.",
+	Long: `Reclaim disk space by removing caches, temps, idle spaces, and unused catalogs.
+
+Only selected cleanups are done, and they can be freely combined:
+
+  --caches              download caches (templates, pip, uv, and conda packages)
+  --temps               temp entries older than --temp-days days
+  --idle-spaces N       holotree spaces not used in more than N days
+  --unused-catalogs N   hololib catalogs not used in more than N days, and
+                        blobs that only they referenced
+  --artifacts           rotated (compressed) log and journal files
+
+At end, report of reclaimed space per category is shown (or with --json,
+printed as JSON). This command never asks for confirmation, and is
+serialized with environment builds, so it is safe to run from cron. Exit code
+is non-zero, if some removal failed.`,
+	Example: `
+  rcc cleanup --temps --artifacts --dryrun
+  rcc cleanup --caches --idle-spaces 30 --unused-catalogs 60 --json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if common.DebugFlag() {
+			defer common.Stopwatch("Cleanup lasted").Report()
+		}
+		cleanupOptions.Dryrun = dryFlag
+		pretty.Guard(cleanupOptions.Any(), 1, "Nothing selected to cleanup. Use --caches, --temps, --idle-spaces, --unused-catalogs, or --artifacts.")
+		pretty.Guard(cleanupOptions.TempDays > 0, 1, "Option --temp-days must be at least 1.")
+		report, err := operations.Cleanup(cleanupOptions)
+		pretty.Guard(err == nil, 2, "%v", err)
+		common.WaitLogs()
+		if jsonFlag {
+			out, err := operations.NiceJsonOutput(report)
+			pretty.Guard(err == nil, 3, "%s", err)
+			fmt.Println(out)
+		} else {
+			report.Table().WriteText(os.Stderr)
+		}
+		pretty.Guard(report.Failures == 0, 4, "Cleanup had %d failures, see warnings above.", report.Failures)
+		if report.Dryrun {
+			common.Log("[dry run] Would reclaim %s.", report.Size())
+		} else {
+			common.Log("Reclaimed %s.", report.Size())
+		}
+		pretty.Ok()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rootCleanupCmd)
+	rootCleanupCmd.Flags().BoolVarP(&dryFlag, "dryrun", "d", false, "Don't remove anything, just show what would be removed and reclaimed.")
+	rootCleanupCmd.Flags().BoolVarP(&jsonFlag, "json", "j", false, "Output report in JSON format.")
+	rootCleanupCmd.Flags().BoolVarP(&cleanupOptions.Caches, "caches", "", false, "Remove download caches (templates, pip, uv, and conda packages).")
+	rootCleanupCmd.Flags().BoolVarP(&cleanupOptions.Temps, "temps", "", false, "Remove temp entries older than --temp-days.")
+	rootCleanupCmd.Flags().IntVarP(&cleanupOptions.TempDays, "temp-days", "", 7, "Age limit in days for --temps.")
+	rootCleanupCmd.Flags().IntVarP(&cleanupOptions.IdleSpaces, "idle-spaces", "", 0, "Remove holotree spaces idle more than given days.")
+	rootCleanupCmd.Flags().IntVarP(&cleanupOptions.UnusedCatalogs, "unused-catalogs", "", 0, "Remove hololib catalogs (and their exclusive blobs) unused more than given days.")
+	rootCleanupCmd.Flags().BoolVarP(&cleanupOptions.Artifacts, "artifacts", "", false, "Remove rotated log and journal files.")
+}
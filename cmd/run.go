@@ -1,11 +1,15 @@
 package cmd
 
 import (
+	"path/filepath"
+	"time"
+
 	"github.com/joshyorko/rcc/cloud"
 	"github.com/joshyorko/rcc/common"
 	"github.com/joshyorko/rcc/conda"
 	"github.com/joshyorko/rcc/journal"
 	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pretty"
 
 	"github.com/spf13/cobra"
 )
@@ -14,6 +18,9 @@ var (
 	rcHosts         = []string{"RC_API_SECRET_HOST", "RC_API_WORKITEM_HOST"}
 	rcTokens        = []string{"RC_API_SECRET_TOKEN", "RC_API_WORKITEM_TOKEN"}
 	interactiveFlag bool
+	changedFlag     bool
+	runLockMode     string
+	runLockTimeout  time.Duration
 )
 
 var runCmd = &cobra.Command{
@@ -21,22 +28,64 @@ var runCmd = &cobra.Command{
 	Aliases: []string{"r"},
 	Short:   "Run task in place, to debug current setup.",
 	Long: `Local task run, in place, to see how full run execution works
-in your own machine.`,
+in your own machine.
+
+With --changed, run is skipped (with exit code 0, and "no changes" marker
+in stdout) when robot sources, conda.yaml, environment file, task, and
+arguments are same as in last successful run of that robot and task.
+
+Concurrent runs of same robot in same space are serialized by run lock.
+With --run-lock, other run can "wait" for it (default, optionally limited
+by --run-lock-timeout), "fail" fast, or use "suffix" space (like "user-2")
+instead. Default mode can also be given in RCC_RUN_LOCK environment variable.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		defer conda.RemoveCurrentTemp()
-		defer journal.BuildEventStats("robot")
-		defer journal.StopRunJournal()
-		if common.DebugFlag() {
-			defer common.Stopwatch("Task run lasted").Report()
-		}
-		simple, config, todo, label := operations.LoadTaskWithEnvironment(robotFile, runTask, forceFlag)
-		cloud.InternalBackgroundMetric(common.ControllerIdentity(), "rcc.cli.run", common.Version)
-		commandline := todo.Commandline()
-		commandline = append(commandline, args...)
-		operations.SelectExecutionModel(captureRunFlags(false), simple, commandline, config, todo, label, interactiveFlag, nil)
+		runRobotTask(args)
 	},
 }
 
+func runRobotTask(args []string) {
+	defer conda.RemoveCurrentTemp()
+	defer journal.BuildEventStats("robot")
+	defer journal.StopRunJournal()
+	if common.DebugFlag() {
+		defer common.Stopwatch("Task run lasted").Report()
+	}
+	inputs := ""
+	if changedFlag {
+		inputs = skipUnchangedRun(args)
+	}
+	defer operations.AcquireRunLock(robotFile, runLockMode, runLockTimeout)()
+	simple, config, todo, label := operations.LoadTaskWithEnvironment(robotFile, runTask, forceFlag)
+	cloud.InternalBackgroundMetric(common.ControllerIdentity(), "rcc.cli.run", common.Version)
+	rest, environment := operations.TaskArguments(config, todo, args, runEnvironment)
+	commandline := todo.Commandline()
+	commandline = append(commandline, rest...)
+	flags := captureRunFlags(false)
+	flags.Task = runTask
+	flags.History = true
+	flags.Inputs = inputs
+	operations.SelectExecutionModel(flags, simple, commandline, config, todo, label, interactiveFlag, environment)
+}
+
+func skipUnchangedRun(args []string) string {
+	robotfile, err := filepath.Abs(robotFile)
+	pretty.Guard(err == nil, 1, "Could not resolve %q, reason: %v", robotFile, err)
+	inputs, err := operations.RunInputsDigest(robotfile, runTask, environmentFile, args)
+	if err != nil {
+		pretty.Warning("Could not detect changes, so running anyway, reason: %v", err)
+		return ""
+	}
+	record, unchanged := operations.UnchangedSinceSuccess(robotfile, runTask, inputs)
+	if !unchanged {
+		return inputs
+	}
+	when := time.Unix(record.When, 0).Format(time.RFC3339)
+	common.RunJournal("run", "skipped", "no changes since successful run at %s", when)
+	common.Stdout("%s (%s)\n", operations.NoChangesMarker, when)
+	pretty.Exit(0, "Skipping run, since nothing has changed since successful run at %s.", when)
+	return inputs
+}
+
 func captureRunFlags(assistant bool) *operations.RunFlags {
 	return &operations.RunFlags{
 		TokenPeriod: &operations.TokenPeriod{
@@ -48,6 +97,14 @@ func captureRunFlags(assistant bool) *operations.RunFlags {
 		EnvironmentFile: environmentFile,
 		RobotYaml:       robotFile,
 		Assistant:       assistant,
+		RefreshTokens:   refreshTokens,
+		NoPipFreeze:     runNoPipFreeze,
+		Limits: &operations.RunLimits{
+			Timeout:  runTimeout,
+			Memory:   runMemoryLimit,
+			Niceness: runNiceness,
+		},
+		Sandbox: runSandbox,
 	}
 }
 
@@ -62,9 +119,18 @@ func init() {
 	runCmd.Flags().IntVarP(&validityTime, "minutes", "m", 15, "How many minutes the authorization should be valid for (minimum 15 minutes).")
 	runCmd.Flags().IntVarP(&gracePeriod, "graceperiod", "", 5, "What is grace period buffer in minutes on top of validity minutes (minimum 5 minutes).")
 	runCmd.Flags().StringVarP(&accountName, "account", "", "", "Account used for workspace. OPTIONAL")
+	runCmd.Flags().BoolVarP(&refreshTokens, "refresh-tokens", "", false, "Keep renewing workspace tokens during long runs, and publish them in file pointed by RC_API_TOKEN_FILE.")
+	runCmd.Flags().DurationVarP(&runTimeout, "timeout", "", 0, "Maximum wall-clock time for robot run, like 90m (overrides 'limits:' in robot.yaml).")
+	runCmd.Flags().Uint64VarP(&runMemoryLimit, "memory-limit", "", 0, "Maximum resident memory in megabytes for robot processes (overrides 'limits:' in robot.yaml).")
+	runCmd.Flags().IntVarP(&runNiceness, "niceness", "", 0, "CPU niceness for robot processes (overrides 'limits:' in robot.yaml).")
+	runCmd.Flags().BoolVarP(&runNoPipFreeze, "no-pip-freeze", "", false, "Do not show pip freeze report of environment before run.")
+	runCmd.Flags().BoolVarP(&runSandbox, "sandbox", "", false, "Run robot in sandbox: private tmp, no network, read-only robot directory (Linux only, see 'sandbox:' in robot.yaml).")
 	runCmd.Flags().BoolVarP(&forceFlag, "force", "f", false, "Force conda cache update (only for new environments).")
+	runCmd.Flags().BoolVarP(&changedFlag, "changed", "", false, "Only run if robot sources, conda.yaml, environment file, or arguments changed since last successful run.")
 	runCmd.Flags().BoolVarP(&interactiveFlag, "interactive", "", false, "Allow robot to be interactive in terminal/command prompt. For development only, not for production!")
 	runCmd.Flags().StringVarP(&common.HolotreeSpace, "space", "s", "user", "Client specific name to identify this environment.")
+	runCmd.Flags().StringVarP(&runLockMode, "run-lock", "", "", "When same robot is already running in same space: wait, fail, or suffix (default: wait, or RCC_RUN_LOCK).")
+	runCmd.Flags().DurationVarP(&runLockTimeout, "run-lock-timeout", "", 0, "Maximum time to wait for run lock in wait mode, like 5m (default: no limit).")
 	runCmd.Flags().BoolVarP(&common.NoOutputCapture, "no-outputs", "", false, "Do not capture stderr/stdout into files.")
 	runCmd.Flags().BoolVarP(&common.DeveloperFlag, "dev", "", false, "Use devTasks instead of normal tasks. For development work only. Strategy selection.")
 }
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pretty"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	sandboxReadonly string
+	sandboxWritable []string
+	sandboxTemp     string
+)
+
+var sandboxCmd = &cobra.Command{
+	Use:   "sandbox",
+	Short: "Internal command to enter robot sandbox and run command there.",
+	Long: `Internal tool, which rcc run uses for sandboxed robots. It is started in
+new user and mount namespace, makes robot directory read-only (but keeps
+writable directories inside it writable), mounts private temporary directory
+over /tmp, and then runs given command.
+
+Example:
+    rcc internal sandbox --readonly /robot --writable /robot/output --tmp /home/user/.robocorp/temp/sandbox_1 -- python -m robot`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := operations.EnterSandbox(sandboxReadonly, sandboxWritable, sandboxTemp, args)
+		pretty.ExitCoded(126, common.ErrRobotSandbox, "Error: %v", err)
+	},
+}
+
+func init() {
+	internalCmd.AddCommand(sandboxCmd)
+
+	sandboxCmd.Flags().StringVarP(&sandboxReadonly, "readonly", "", "", "Directory to make read-only.")
+	sandboxCmd.Flags().StringArrayVarP(&sandboxWritable, "writable", "", []string{}, "Directory inside read-only directory, which stays writable.")
+	sandboxCmd.Flags().StringVarP(&sandboxTemp, "tmp", "", "", "Private directory to mount over /tmp.")
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/joshyorko/rcc/common"
 	"github.com/joshyorko/rcc/pretty"
 	"github.com/joshyorko/rcc/settings"
 	"github.com/spf13/cobra"
@@ -25,13 +26,13 @@ var settingsCmd = &cobra.Command{
 			fmt.Fprintf(os.Stdout, "%s", string(raw))
 		case jsonFlag:
 			config, err := settings.SummonSettings()
-			pretty.Guard(err == nil, 2, "Error while loading settings: %v", err)
+			pretty.GuardCoded(err == nil, 2, common.ErrSettingsInvalid, "Error while loading settings: %v", err)
 			json, err := config.AsJson()
 			pretty.Guard(err == nil, 3, "Error while converting settings: %v", err)
 			fmt.Fprintf(os.Stdout, "%s", string(json))
 		default:
 			config, err := settings.SummonSettings()
-			pretty.Guard(err == nil, 2, "Error while loading settings: %v", err)
+			pretty.GuardCoded(err == nil, 2, common.ErrSettingsInvalid, "Error while loading settings: %v", err)
 			yaml, err := config.AsYaml()
 			pretty.Guard(err == nil, 3, "Error while converting settings: %v", err)
 			fmt.Fprintf(os.Stdout, "%s", string(yaml))
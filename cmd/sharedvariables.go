@@ -1,5 +1,9 @@
 package cmd
 
+import (
+	"time"
+)
+
 // flags
 var (
 	autoInstall    bool
@@ -33,8 +37,16 @@ var (
 	shellDirectory  string
 	templateName    string
 	gracePeriod     int
+	refreshTokens   bool
+	runMemoryLimit  uint64
+	runNiceness     int
+	runNoPipFreeze  bool
+	runSandbox      bool
+	runTimeout      time.Duration
 	validityTime    int
 	workspaceId     string
 	wskey           string
 	zipfile         string
+
+	runEnvironment map[string]string
 )
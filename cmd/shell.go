@@ -1,31 +1,54 @@
 package cmd
 
 import (
+	"os"
+
 	"github.com/joshyorko/rcc/common"
 	"github.com/joshyorko/rcc/conda"
 	"github.com/joshyorko/rcc/operations"
 	"github.com/joshyorko/rcc/pretty"
+	"github.com/joshyorko/rcc/shell"
 
 	"github.com/spf13/cobra"
 )
 
+var (
+	shellRecord bool
+	shellReplay string
+	shellSpeed  float64
+)
+
 var shellCmd = &cobra.Command{
 	Use:     "shell",
 	Aliases: []string{"sh", "s"},
 	Short:   "Run the given command inside the given environment",
 	Long: `Shell command executes the given command inside a managed virtual environment.
 It can be used to get inside a managed environment and execute your own
-command within that environment.`,
+command within that environment.
+
+With --record, session input and output are recorded with timestamps into
+transcript file in robot artifact directory, and --replay shows recorded
+transcript again.`,
 	Args: cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		if common.DebugFlag() {
 			defer common.Stopwatch("rcc shell lasted").Report()
 		}
+		if len(shellReplay) > 0 {
+			source, err := os.Open(shellReplay)
+			pretty.Guard(err == nil, 2, "Could not open transcript %q, reason: %v", shellReplay, err)
+			defer source.Close()
+			err = shell.Replay(source, os.Stdout, os.Stderr, shellSpeed)
+			pretty.Guard(err == nil, 3, "Could not replay transcript %q, reason: %v", shellReplay, err)
+			return
+		}
 		simple, config, todo, label := operations.LoadAnyTaskEnvironment(robotFile, forceFlag)
 		if simple {
 			pretty.Exit(1, "Cannot do shell for simple execution model.")
 		}
-		operations.ExecuteTask(captureRunFlags(false), conda.Shell, config, todo, label, true, nil)
+		flags := captureRunFlags(false)
+		flags.Transcript = shellRecord
+		operations.ExecuteTask(flags, conda.Shell, config, todo, label, true, nil)
 	},
 }
 
@@ -36,5 +59,8 @@ func init() {
 	shellCmd.Flags().StringVarP(&robotFile, "robot", "r", "robot.yaml", "Full path to the 'robot.yaml' configuration file.")
 	shellCmd.Flags().StringVarP(&common.HolotreeSpace, "space", "s", "user", "Client specific name to identify used environment.")
 	shellCmd.Flags().StringVarP(&runTask, "task", "t", "", "Task to configure shell from configuration file. <deprecated, non-functional>")
+	shellCmd.Flags().BoolVarP(&shellRecord, "record", "", false, "Record session transcript (input and output with timestamps) into artifact directory.")
+	shellCmd.Flags().StringVarP(&shellReplay, "replay", "", "", "Replay given recorded session transcript file instead of starting shell.")
+	shellCmd.Flags().Float64VarP(&shellSpeed, "speed", "", 1.0, "Replay speed multiplier, zero means no delays.")
 	shellCmd.MarkFlagRequired("config")
 }
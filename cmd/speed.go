@@ -79,7 +79,7 @@ var speedtestCmd = &cobra.Command{
 		err = pathlib.WriteFile(condafile, content, 0o666)
 		pretty.Guard(err == nil, 2, "Error: %v", err)
 		common.Product.ForceHome(folder)
-		_, score, err := htfs.NewEnvironment(condafile, "", true, true, operations.PullCatalog)
+		_, score, err := htfs.NewEnvironment(condafile, "", nil, true, true, operations.PullCatalog)
 		common.DefineVerbosity(silent, debug, trace)
 		pretty.Guard(err == nil, 3, "Error: %v", err)
 		common.Product.ForceHome("")
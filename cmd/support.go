@@ -0,0 +1,15 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var supportCmd = &cobra.Command{
+	Use:   "support",
+	Short: "Group of commands for getting help with problems.",
+	Long:  "Group of commands for getting help with problems.",
+}
+
+func init() {
+	rootCmd.AddCommand(supportCmd)
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pretty"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	supportTarget string
+	supportLimit  int
+	supportDays   int
+	supportQuick  bool
+	supportYes    bool
+)
+
+var supportBundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Collect diagnostics, journals, and settings into zip for issue reports.",
+	Long: `Collect diagnostics, journals, and settings into zip for issue reports.
+
+Bundle contains rcc and micromamba versions, diagnostics, effective settings,
+holotree statistics, last robot run (with listing of its artifacts and its
+failure report, but no artifact contents), and journals of recent days.
+Passwords and tokens are redacted from all of them, but review bundle before
+sharing it anyway.
+
+What would be included is listed first, and bundle is written only after
+confirmation (or without asking with --yes). Entries are taken in order of
+importance (oldest journals last), and manifest.json counts against --max-size.
+When first entry does not fit, it and all entries after it are left out.`,
+	Example: `
+  rcc support bundle
+  rcc support bundle --output issue-1234.zip --max-size 5 --days 3 --yes`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if common.DebugFlag() {
+			defer common.Stopwatch("Support bundle lasted").Report()
+		}
+		pretty.Guard(supportLimit >= 0, 1, "Option --max-size cannot be negative.")
+		pretty.Guard(supportDays > 0, 1, "Option --days must be at least 1.")
+		if len(supportTarget) == 0 {
+			supportTarget = time.Now().Format("rcc-support-20060102-150405.zip")
+		}
+		bundle := operations.CollectSupportBundle(supportLimit*1024*1024, supportDays, supportQuick)
+		common.WaitLogs()
+		bundle.Table().WriteText(os.Stderr)
+		pretty.Guard(supportYes || confirmed("Write these into %q, for sharing with support?", supportTarget), 2, "Support bundle was cancelled.")
+		err := bundle.WriteZip(supportTarget)
+		pretty.Guard(err == nil, 3, "Could not write support bundle, reason: %v", err)
+		common.Log("Support bundle with %d bytes of content is at %q.", bundle.Size(), supportTarget)
+		pretty.Ok()
+	},
+}
+
+func init() {
+	supportCmd.AddCommand(supportBundleCmd)
+	supportBundleCmd.Flags().StringVarP(&supportTarget, "output", "o", "", "Name of zip file to write. Default is timestamped rcc-support-*.zip.")
+	supportBundleCmd.Flags().IntVarP(&supportLimit, "max-size", "", 10, "Maximum size of bundle content in megabytes (0 means no limit).")
+	supportBundleCmd.Flags().IntVarP(&supportDays, "days", "", 7, "Include journals changed in this many last days.")
+	supportBundleCmd.Flags().BoolVarP(&supportQuick, "quick", "q", false, "Only run quick diagnostics (no network checks).")
+	supportBundleCmd.Flags().BoolVarP(&supportYes, "yes", "y", false, "Write bundle without asking for confirmation.")
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/spf13/cobra"
+)
+
+var (
+	csvFlag      bool
+	tableSort    string
+	tableFilters []string
+	tableColumns []string
+	tableExport  string
+)
+
+func humaneSize(value any) string {
+	size, ok := value.(uint64)
+	if !ok {
+		return fmt.Sprintf("%v", value)
+	}
+	amount, suffix := pathlib.HumaneSizer(int64(size))
+	return fmt.Sprintf("%6.1f%s", amount, suffix)
+}
+
+func addTableFlags(command *cobra.Command) {
+	command.Flags().BoolVarP(&csvFlag, "csv", "", false, "Output in CSV format (raw values, selected columns).")
+	command.Flags().StringVarP(&tableSort, "sort", "", "", "Sort by given column, prefix with '-' for reverse order (like --sort=-size).")
+	command.Flags().StringArrayVarP(&tableFilters, "filter", "", []string{}, "Filter expression like idle>30d or controller=rcc.user (repeatable, all must match).")
+	command.Flags().StringSliceVarP(&tableColumns, "columns", "", []string{}, "Comma separated list of columns to show.")
+	command.Flags().StringVarP(&tableExport, "export", "", "", "Also export listing into markdown file (or CSV file, when name ends with .csv) for sharing.")
+}
+
+func applyTableControls(table *pretty.Table, title string) {
+	err := table.Filter(tableFilters)
+	pretty.Guard(err == nil, 1, "%v", err)
+	if len(tableSort) > 0 {
+		err = table.Sort(tableSort)
+		pretty.Guard(err == nil, 1, "%v", err)
+	}
+	if len(tableColumns) > 0 {
+		err = table.Select(tableColumns)
+		pretty.Guard(err == nil, 1, "%v", err)
+	}
+	exportTable(table, title)
+}
+
+func exportTable(table *pretty.Table, title string) {
+	if len(tableExport) == 0 {
+		return
+	}
+	operations.Exportable(title, table)
+	err := operations.ExportView(tableExport)
+	pretty.Guard(err == nil, 1, "%v", err)
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pretty"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	usageWeeks uint
+)
+
+var usageCmd = &cobra.Command{
+	Use:   "usage",
+	Short: "Group of commands related to local usage of rcc.",
+	Long:  "Group of commands related to local usage of rcc.",
+}
+
+func humaneUsageReport(report *operations.UsageReport) {
+	tabbed := tabwriter.NewWriter(os.Stderr, 2, 4, 2, ' ', 0)
+	fmt.Fprintf(tabbed, "Local usage of last %d full weeks (from %s journals):\n\n", report.Weeks, common.Product.HomeVariable())
+	tabbed.Write([]byte("Command\tRuns\tBuilds\tReuses\tFailed\tSetup time\n"))
+	tabbed.Write([]byte("-------\t----\t------\t------\t------\t----------\n"))
+	for _, command := range report.Commands {
+		fmt.Fprintf(tabbed, "%s\t%d\t%d\t%d\t%d\t%.1fs\n", command.Command, command.Runs, command.Builds, command.Reuses, command.Failed, command.SetupTime)
+	}
+	tabbed.Write([]byte("\n"))
+	fmt.Fprintf(tabbed, "Commands run:\t%d\n", report.Runs)
+	fmt.Fprintf(tabbed, "Environments built:\t%d (%d failed)\n", report.Builds, report.FailedBuilds)
+	fmt.Fprintf(tabbed, "Environments reused:\t%d\n", report.Reuses)
+	fmt.Fprintf(tabbed, "Cache hit rate:\t%.1f%%\n", report.HitRate)
+	fmt.Fprintf(tabbed, "Average setup with build:\t%.1fs\n", report.BuildTime)
+	fmt.Fprintf(tabbed, "Average setup with reuse:\t%.1fs\n", report.ReuseTime)
+	fmt.Fprintf(tabbed, "Time saved by holotree:\t%.1f hours\n", report.TimeSaved/3600.0)
+	fmt.Fprintf(tabbed, "Robot runs:\t%d (%d failed, %d robots)\n", report.RobotRuns, report.RobotFailures, report.Robots)
+	tabbed.Flush()
+}
+
+var usageReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Show private summary of local rcc usage.",
+	Long: `Show private summary of local rcc usage: commands run, environments built
+and reused, holotree cache hit rate, and estimated time saved by holotree.
+
+Report is computed only from local journals under product home. Nothing is
+sent anywhere, and report is independent of cloud telemetry, which stays
+disabled.
+
+Examples:
+  rcc usage report
+  rcc usage report --weeks 4 --json`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if common.DebugFlag() {
+			defer common.Stopwatch("Usage report command lasted").Report()
+		}
+		report, err := operations.LocalUsageReport(usageWeeks)
+		pretty.Guard(err == nil, 1, "Could not load local journals, reason: %v", err)
+		if jsonFlag {
+			body, err := json.MarshalIndent(report, "", "  ")
+			pretty.Guard(err == nil, 2, "Could not create json, reason: %v", err)
+			fmt.Println(string(body))
+			return
+		}
+		humaneUsageReport(report)
+		pretty.Ok()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(usageCmd)
+	usageCmd.AddCommand(usageReportCmd)
+	usageReportCmd.Flags().UintVarP(&usageWeeks, "weeks", "w", 12, "Number of previous weeks to include into report.")
+	usageReportCmd.Flags().BoolVarP(&jsonFlag, "json", "j", false, "Output in JSON format.")
+}
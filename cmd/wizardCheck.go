@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/joshyorko/rcc/wizard"
+
+	"github.com/spf13/cobra"
+)
+
+var wizardCheckCmd = &cobra.Command{
+	Use:   "check [catalog filter]*",
+	Short: "Check holotree catalogs and repair or delete broken ones interactively.",
+	Long: `Check holotree catalogs and repair or delete broken ones interactively.
+Each catalog is checked in turn and its missing or corrupted content is
+reported as progress. After the check, a follow-up action (repair from remote
+origin, delete, or skip) is offered for each broken catalog.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !pretty.Interactive {
+			pretty.Exit(1, "This is for interactive use only. Do not use in scripting/CI!")
+		}
+		if common.DebugFlag() {
+			defer common.Stopwatch("Interactive check lasted").Report()
+		}
+		err := wizard.Check(args)
+		if err != nil {
+			pretty.Exit(2, "%v", err)
+		}
+	},
+}
+
+func init() {
+	if common.Product.IsLegacy() {
+		interactiveCmd.AddCommand(wizardCheckCmd)
+	}
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/joshyorko/rcc/wizard"
+
+	"github.com/spf13/cobra"
+)
+
+var wizardCompareCmd = &cobra.Command{
+	Use:   "compare [left catalog] [right catalog]",
+	Short: "Compare two holotree catalogs side by side interactively.",
+	Long: `Compare two holotree catalogs side by side interactively.
+Catalogs not given as arguments are chosen from hololib. Comparison shows
+python version, size and file count deltas, requested dependencies from
+identity.yaml, and differences of installed conda and pip packages. Useful
+when deciding which of two similar environments to keep.`,
+	Args: cobra.MaximumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !pretty.Interactive {
+			pretty.Exit(1, "This is for interactive use only. Do not use in scripting/CI!")
+		}
+		if common.DebugFlag() {
+			defer common.Stopwatch("Interactive compare lasted").Report()
+		}
+		err := wizard.Compare(args)
+		if err != nil {
+			pretty.Exit(2, "%v", err)
+		}
+	},
+}
+
+func init() {
+	if common.Product.IsLegacy() {
+		interactiveCmd.AddCommand(wizardCompareCmd)
+	}
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/joshyorko/rcc/wizard"
+
+	"github.com/spf13/cobra"
+)
+
+var wizardExportCmd = &cobra.Command{
+	Use:   "export [catalog filter]*",
+	Short: "Export holotree catalogs into zip file interactively.",
+	Long: `Export holotree catalogs into zip file interactively.
+Wizard asks which catalogs to export, destination, optional delta base
+catalog (already present on target) and compression level, and shows size
+estimate before exporting.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !pretty.Interactive {
+			pretty.Exit(1, "This is for interactive use only. Do not use in scripting/CI!")
+		}
+		if common.DebugFlag() {
+			defer common.Stopwatch("Interactive export lasted").Report()
+		}
+		err := wizard.Export(args)
+		if err != nil {
+			pretty.Exit(2, "%v", err)
+		}
+	},
+}
+
+func init() {
+	if common.Product.IsLegacy() {
+		interactiveCmd.AddCommand(wizardExportCmd)
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"path/filepath"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/joshyorko/rcc/wizard"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	historyWeeks uint
+)
+
+var wizardHistoryCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show recent runs of robot and rerun selected one interactively.",
+	Long: `Show recent runs of robot and rerun selected one interactively.
+Each run done with "rcc run" is recorded into run history with its task,
+space, environment file, duration, exit code, and artifact directory. Selecting
+one of those runs repeats it with same task/space/environment combination.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !pretty.Interactive {
+			pretty.Exit(1, "This is for interactive use only. Do not use in scripting/CI!")
+		}
+		if common.DebugFlag() {
+			defer common.Stopwatch("Interactive history lasted").Report()
+		}
+		robotfile, err := filepath.Abs(robotFile)
+		pretty.Guard(err == nil, 2, "%v", err)
+		record, err := wizard.History(robotfile, historyWeeks)
+		if err != nil {
+			pretty.Exit(2, "%v", err)
+		}
+		if record == nil {
+			return
+		}
+		yankPrimaryValue()
+		robotFile, runTask, environmentFile = record.Robot, record.Task, record.Environment
+		common.HolotreeSpace, common.DeveloperFlag = record.Space, record.Developer
+		runRobotTask(args)
+	},
+}
+
+func init() {
+	if common.Product.IsLegacy() {
+		interactiveCmd.AddCommand(wizardHistoryCmd)
+		wizardHistoryCmd.Flags().StringVarP(&robotFile, "robot", "r", "robot.yaml", "Full path to the 'robot.yaml' configuration file.")
+		wizardHistoryCmd.Flags().UintVarP(&historyWeeks, "weeks", "w", 4, "How many weeks of run history to show.")
+	}
+}
@@ -11,7 +11,10 @@ import (
 var wizardCreateCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Create a directory structure for a robot interactively.",
-	Long:  "Create a directory structure for a robot interactively.",
+	Long: `Create a directory structure for a robot interactively.
+After choosing template and name, Python version and initial pip/conda
+dependencies can be chosen (with version completion from configured PyPI
+endpoint), and they are written into generated conda.yaml before first build.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if !pretty.Interactive {
 			pretty.Exit(1, "This is for interactive use only. Do not use in scripting/CI!")
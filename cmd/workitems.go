@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/pretty"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	workItemsPort   int
+	workItemsOutput string
+	workItemsEnv    string
+)
+
+var workItemsCmd = &cobra.Command{
+	Use:     "workitems",
+	Aliases: []string{"workitem", "wi"},
+	Short:   "Group of commands related to work items of robots.",
+	Long:    "Group of commands related to work items of robots.",
+}
+
+var workItemsServeCmd = &cobra.Command{
+	Use:   "serve <work-items.json>",
+	Short: "Serve local work item queue simulator for developing robots offline.",
+	Long: `Serve local work item queue simulator for developing producer and consumer
+robots offline. Simulator serves same work item endpoints (RC_API_WORKITEM_HOST
+and RC_API_PROCESS_HOST) that robots use in Control Room, with input items
+from given work-items.json file (same format that file adapter uses, like
+devdata/work-items.json of robot).
+
+Output items that robot creates (and their files) are written into --output
+file, which can be given as input of next step. Simulator only listens on
+127.0.0.1, and it prints environment variables which make robot use it.
+With --env-file, those variables are written into env.json file, which can
+be given to "rcc run --environment". Simulator runs until interrupted.
+
+Examples:
+  rcc workitems serve devdata/work-items.json
+  rcc workitems serve --env-file output/env.json devdata/work-items.json
+  rcc workitems serve --port 4000 --output output/step1.json devdata/work-items.json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		queue, err := operations.LoadWorkItemQueue(args[0], workItemsOutput)
+		pretty.Guard(err == nil, 1, "%v", err)
+		base, err := queue.Serve(fmt.Sprintf("127.0.0.1:%d", workItemsPort))
+		pretty.Guard(err == nil, 2, "Could not start work item simulator, reason: %v", err)
+		environment := queue.Environment(base)
+		if len(workItemsEnv) > 0 {
+			content, err := json.MarshalIndent(environment, "", "  ")
+			if err == nil {
+				err = pathlib.WriteFile(workItemsEnv, content, 0o600)
+			}
+			pretty.Guard(err == nil, 3, "Could not write %q, reason: %v", workItemsEnv, err)
+			common.Log("Run robot against simulator with: rcc run --environment %s", workItemsEnv)
+		} else {
+			common.Log("Use these environment variables in robot run:")
+			for _, key := range slices.Sorted(maps.Keys(environment)) {
+				common.Stdout("%s=%s\n", key, environment[key])
+			}
+		}
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt)
+		<-signals
+		common.Log("Work item simulator stopped with %d output items in %q.", len(queue.Outputs()), workItemsOutput)
+		pretty.Ok()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(workItemsCmd)
+	workItemsCmd.AddCommand(workItemsServeCmd)
+	workItemsServeCmd.Flags().IntVarP(&workItemsPort, "port", "p", 0, "Port to listen on 127.0.0.1 (default is any free port).")
+	workItemsServeCmd.Flags().StringVarP(&workItemsEnv, "env-file", "e", "", "Write environment variables of simulator into this env.json file.")
+	workItemsServeCmd.Flags().StringVarP(&workItemsOutput, "output", "o", "output/work-items.json", "File where output work items are written.")
+}
@@ -8,17 +8,41 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var (
+	wrapExcludes []string
+	wrapVerify   bool
+)
+
 var wrapCmd = &cobra.Command{
 	Use:   "wrap",
 	Short: "Build a robot out of directory content.",
 	Long: `Build a robot out of directory content. This command expects to get robot
 filename, source directory and optional ignore files. When wrap is run again
-existing robot file will silently be overwritten..`,
+existing robot file will silently be overwritten..
+
+Patterns in ".rccignore" file at source directory, and --exclude patterns are
+also excluded. Produced zip is reproducible, since entries are sorted and
+have fixed timestamps, so identical sources produce identical bytes.
+
+With --verify option, existing robot file is compared against source tree
+(nothing is written), and differences are listed.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if common.DebugFlag() {
 			defer common.Stopwatch("Wrap lasted").Report()
 		}
-		err := operations.Zip(directory, zipfile, ignores)
+		if wrapVerify {
+			differences, err := operations.VerifyWrap(directory, zipfile, ignores, wrapExcludes)
+			if err != nil {
+				pretty.Exit(1, "Error: %v", err)
+			}
+			for _, difference := range differences {
+				common.Stdout("%s\n", difference)
+			}
+			pretty.Guard(len(differences) == 0, 2, "Robot %q differs from %q in %d entries.", zipfile, directory, len(differences))
+			pretty.Ok()
+			return
+		}
+		err := operations.ZipExcluding(directory, zipfile, ignores, wrapExcludes)
 		if err != nil {
 			pretty.Exit(1, "Error: %v", err)
 		}
@@ -31,4 +55,6 @@ func init() {
 	wrapCmd.Flags().StringVarP(&zipfile, "zipfile", "z", "robot.zip", "The filename for the robot.")
 	wrapCmd.Flags().StringVarP(&directory, "directory", "d", ".", "The root directory create the robot from.")
 	wrapCmd.Flags().StringArrayVarP(&ignores, "ignore", "i", []string{}, "File with ignore patterns.")
+	wrapCmd.Flags().StringArrayVarP(&wrapExcludes, "exclude", "x", []string{}, "Exclude pattern (repeatable), like '*.log' or 'devdata'.")
+	wrapCmd.Flags().BoolVarP(&wrapVerify, "verify", "", false, "Verify existing robot file against source tree, instead of writing it.")
 }
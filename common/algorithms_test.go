@@ -34,3 +34,24 @@ func TestCanCalculateGcd(t *testing.T) {
 	must_be.Equal(int64(5), common.Gcd(5, 0))
 	must_be.Equal(int64(1), common.Gcd(0, 0))
 }
+
+func blake3Hexdigest(size int) string {
+	input := make([]byte, size)
+	for at := range input {
+		input[at] = byte(at % 251)
+	}
+	digester := common.NewBlake3()
+	digester.Write(input)
+	return common.Hexdigest(digester.Sum(nil))
+}
+
+func TestCanCalculateBlake3Digests(t *testing.T) {
+	must_be, _ := hamlet.Specifications(t)
+
+	must_be.Equal("af1349b9f5f9a1a6a0404dea36dcc9499bcb25c9adc112b7cc9a93cae41f3262", blake3Hexdigest(0))
+	must_be.Equal("2d3adedff11b61f14c886e35afa036736dcd87a74d27b5c1510225d0f592e213", blake3Hexdigest(1))
+	must_be.Equal("42214739f095a406f3fc83deb889744ac00df831c10daa55189b5d121c855af7", blake3Hexdigest(1024))
+	must_be.Equal("d00278ae47eb27b34faecf67b4fe263f82d5412916c1ffd97c8cb7fb814b8444", blake3Hexdigest(1025))
+	must_be.Equal("e776b6028c7cd22a4d0ba182a8bf62205d2ef576467e838ed6f2529b85fba24a", blake3Hexdigest(2048))
+	must_be.Equal("bab6c09cb8ce8cf459261398d2e7aef35700bf488116ceb94a36d0f5f1b7bc3b", blake3Hexdigest(8193))
+}
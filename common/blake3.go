@@ -0,0 +1,14 @@
+package common
+
+import (
+	"hash"
+
+	"github.com/zeebo/blake3"
+)
+
+// NewBlake3 creates BLAKE3 digester (hash mode, 32 byte output). It uses
+// SIMD accelerated implementation when CPU supports it, which is the reason
+// to prefer it over legacy digests on large environments.
+func NewBlake3() hash.Hash {
+	return blake3.New()
+}
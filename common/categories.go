@@ -7,7 +7,14 @@ const (
 	CategoryLockPid            = 1021
 	CategoryPathCheck          = 1030
 	CategoryEnvVarCheck        = 1040
+	CategoryMicromamba         = 1050
+	CategoryStaleTemp          = 1060
+	CategoryGpu                = 1070
 	CategoryHolotreeShared     = 2010
+	CategoryRestoreValidation  = 2020
+	CategorySpaceUsage         = 2030
+	CategoryCatalogIntegrity   = 2040
+	CategoryAntivirus          = 2050
 	CategoryProductHome        = 3010
 	CategoryProductHomeMembers = 3020
 	CategoryNetworkDNS         = 4010
@@ -0,0 +1,266 @@
+package common
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Stable error codes, grouped by area: E1xxx environment, E2xxx holotree,
+// E3xxx network, E4xxx robot, and E5xxx configuration. Once released, code
+// must keep its meaning, since users and support search for them.
+const (
+	ErrEnvironmentCreation = "E1101"
+	ErrBlueprintCompose    = "E1102"
+	ErrPreRunScript        = "E1103"
+	ErrHololibOpen         = "E2101"
+	ErrCatalogImport       = "E2102"
+	ErrCatalogExport       = "E2103"
+	ErrCatalogPull         = "E2104"
+	ErrForeignCatalog      = "E2105"
+	ErrSharedHolotree      = "E2106"
+	ErrSpaceBusy           = "E2107"
+	ErrCloudRequest        = "E3101"
+	ErrRobotLoad           = "E4101"
+	ErrRobotValidation     = "E4102"
+	ErrTaskNotFound        = "E4103"
+	ErrTaskCommand         = "E4104"
+	ErrMissingSecrets      = "E4105"
+	ErrTaskInputs          = "E4106"
+	ErrTaskOutputs         = "E4107"
+	ErrRobotSandbox        = "E4108"
+	ErrTaskArguments       = "E4109"
+	ErrSettingsInvalid     = "E5101"
+	ErrProfileNotFound     = "E5102"
+)
+
+// ErrorCode describes one stable failure code. Hint is one line remediation
+// shown on failure, and Description is full text shown by "rcc explain".
+// Docs is documentation page relative to docs location.
+type ErrorCode struct {
+	Code        string `json:"code"`
+	Title       string `json:"title"`
+	Hint        string `json:"hint"`
+	Description string `json:"description"`
+	Docs        string `json:"docs"`
+}
+
+var (
+	errorCodes = map[string]*ErrorCode{}
+
+	// ErrorDocsLink resolves documentation page into link, and is replaced
+	// by settings, which knows the docs endpoint.
+	ErrorDocsLink = func(page string) string {
+		return page
+	}
+)
+
+func registerErrorCode(code, title, hint, docs, description string) {
+	errorCodes[code] = &ErrorCode{
+		Code:        code,
+		Title:       title,
+		Hint:        hint,
+		Description: strings.TrimSpace(description),
+		Docs:        docs,
+	}
+}
+
+func init() {
+	registerErrorCode(ErrEnvironmentCreation, "Environment creation failed",
+		"Check the failing conda/pip/uv step above, then retry with --debug or --force.",
+		"troubleshooting",
+		`
+Building holotree environment from conda.yaml failed. Most common reasons are
+unresolvable dependencies (package versions that do not exist or conflict),
+network access to package repositories being blocked, or disk running out of
+space. Output above the failure shows which step failed. Running with --debug
+gives more details, and "rcc configuration diagnostics" checks network access.`)
+	registerErrorCode(ErrBlueprintCompose, "Environment configuration is invalid",
+		"Check conda.yaml (and environment configs in robot.yaml) for syntax errors.",
+		"troubleshooting",
+		`
+Environment blueprint could not be composed from conda.yaml and possible
+environment configuration files. Usually this means that file is missing, is
+not valid YAML, or has unknown structure. Validate files with a YAML linter
+and check that robot.yaml points to correct conda.yaml.`)
+	registerErrorCode(ErrPreRunScript, "Pre-run script failed",
+		"Check preRunScripts in robot.yaml and run the failing script manually.",
+		"troubleshooting",
+		`
+One of preRunScripts defined in robot.yaml failed or could not be started.
+Scripts are run inside of the robot environment before task itself, so run
+"rcc task shell" to get same environment and run failing script there.`)
+	registerErrorCode(ErrHololibOpen, "Hololib could not be opened",
+		"Check that ROBOCORP_HOME is writable, and run \"rcc holotree check\".",
+		"troubleshooting",
+		`
+Holotree library (hololib) in ROBOCORP_HOME could not be opened. This happens
+when directory is not writable by current user, disk is full, or shared
+holotree setup is incomplete. "rcc holotree check" verifies hololib content,
+and "rcc holotree shared --enable" (with elevated rights) fixes shared setup.`)
+	registerErrorCode(ErrCatalogImport, "Holotree import failed",
+		"Check that import file is complete hololib.zip from \"rcc holotree export\".",
+		"troubleshooting",
+		`
+Importing hololib.zip (or URL) into local hololib failed. Archive may be
+truncated or corrupted, created by incompatible rcc version, or blobs inside
+it may not match their digests. Re-export archive with "rcc holotree export"
+and verify it with "rcc holotree import --json" to see transfer summary.`)
+	registerErrorCode(ErrCatalogExport, "Holotree export failed",
+		"Check that catalogs exist (\"rcc holotree catalogs\") and target is writable.",
+		"troubleshooting",
+		`
+Exporting catalogs from local hololib into zip file failed. Either requested
+catalogs do not exist in hololib, hololib content is broken (fix it with
+"rcc holotree check"), or target file could not be written.`)
+	registerErrorCode(ErrCatalogPull, "Holotree pull failed",
+		"Check that remote origin is reachable and has the requested catalog.",
+		"troubleshooting",
+		`
+Pulling environment catalog from remote origin (rccremote) failed. Remote
+origin is given with --origin option or RCC_REMOTE_ORIGIN environment variable.
+Check that it is reachable (and trusted, when using TLS), and that it really
+has catalog with same blueprint for same platform. When pull fails, rcc can
+still build environment locally, unless that was disabled.`)
+	registerErrorCode(ErrForeignCatalog, "Catalog is for other platform",
+		"Import catalogs built for this platform, or drop the strict option.",
+		"troubleshooting",
+		`
+Import contained catalogs that were built on other platform (operating system
+or architecture) than current one, and strict checking refused them. Those
+catalogs could not be used here anyway, so export them again on matching
+platform.`)
+	registerErrorCode(ErrSharedHolotree, "Shared holotree is required",
+		"Enable shared holotree with \"rcc holotree shared --enable\".",
+		"troubleshooting",
+		`
+Operation requires that holotree is in shared mode, so that environments
+built once can be used by other users and services. Enable shared holotree
+once per machine with "rcc holotree shared --enable" (needs elevated rights)
+and then "rcc holotree init" for each user.`)
+	registerErrorCode(ErrSpaceBusy, "Robot space is in use by other run",
+		"Wait for other run to finish, use --run-lock=wait with --run-lock-timeout, or --run-lock=suffix.",
+		"troubleshooting",
+		`
+Other rcc run of same robot is currently using same holotree space, and
+running concurrently would corrupt that space. Run lock holders are listed
+in "rcc configuration diagnostics" output.`)
+	registerErrorCode(ErrCloudRequest, "Cloud request failed",
+		"Check network access with \"rcc configuration diagnostics\" and your credentials.",
+		"troubleshooting/firewall-and-proxies",
+		`
+Request to Control Room (or other cloud endpoint) failed. Network may be
+blocking access (firewalls, proxies, TLS inspection), endpoint may be wrong
+(see RCC_ENDPOINT_* variables and settings.yaml), or credentials may be
+expired or lack needed access rights.`)
+	registerErrorCode(ErrRobotLoad, "Robot could not be loaded",
+		"Check that robot.yaml exists and is valid YAML.",
+		"troubleshooting",
+		`
+Robot configuration file (robot.yaml) could not be found or parsed. Check
+that --robot option points to right file, and that file is valid YAML with
+expected structure.`)
+	registerErrorCode(ErrRobotValidation, "Robot configuration is invalid",
+		"Fix problems listed above in robot.yaml.",
+		"troubleshooting",
+		`
+Robot configuration file (robot.yaml) was loaded, but its content did not pass
+validation. Typical reasons are tasks without command or shell, paths that
+point outside of robot, or missing artifacts directory definition.`)
+	registerErrorCode(ErrTaskNotFound, "Task not found",
+		"Check task name with --task against tasks listed in robot.yaml.",
+		"troubleshooting",
+		`
+Requested task was not found from robot.yaml, or robot has multiple tasks
+and none was selected. Give task name with --task option, and check that it
+is spelled exactly as in robot.yaml.`)
+	registerErrorCode(ErrTaskCommand, "Task command could not be run",
+		"Check task command in robot.yaml and that it exists in the environment.",
+		"troubleshooting",
+		`
+Task was found, but its command is missing, empty, or could not be started
+in the robot environment. Check task definition in robot.yaml, and that the
+executable is installed by conda.yaml dependencies.`)
+	registerErrorCode(ErrMissingSecrets, "Required secrets are missing",
+		"Provide listed secrets from vault (Control Room) or as environment variables.",
+		"troubleshooting",
+		`
+Robot declares secrets that must be available before run, but some of them
+were not found. Provide them from vault (Control Room), or as environment
+variables when running locally.`)
+	registerErrorCode(ErrTaskInputs, "Declared task inputs are missing",
+		"Provide input files and variables listed in task 'inputs:' of robot.yaml.",
+		"troubleshooting",
+		`
+Task declares in its 'inputs:' contract files (relative to robot root) and
+environment variables, that must exist before run, but some of them were not
+found. Check work item setup (like devdata files and --environment file), or
+fix the declaration in robot.yaml.`)
+	registerErrorCode(ErrTaskOutputs, "Declared task outputs were not produced",
+		"Check task logs, and outputs listed in task 'outputs:' of robot.yaml.",
+		"troubleshooting",
+		`
+Task declares in its 'outputs:' contract files (relative to robot root), that
+it produces during run, and policy of that contract is "fail", but some of
+those files did not exist after run. Check robot logs for reason, or fix the
+declaration in robot.yaml.`)
+	registerErrorCode(ErrRobotSandbox, "Robot sandbox could not be set up",
+		"Enable unprivileged user namespaces, or run robot without sandbox.",
+		"troubleshooting",
+		`
+Robot was asked to run in sandbox (--sandbox flag or 'sandbox: enabled: true'
+in robot.yaml), but sandbox could not be set up. Sandbox is only available on
+Linux, and it needs unprivileged user namespaces for network isolation and
+read-only robot directory. Robot can declare capabilities it needs (network,
+write-robot, shared-tmp) in 'sandbox: capabilities:' of robot.yaml, and then
+those restrictions are not applied.`)
+	registerErrorCode(ErrTaskArguments, "Task arguments are invalid",
+		"Give required task arguments after '--', with values of declared types.",
+		"troubleshooting",
+		`
+Task declares named arguments in its 'arguments:' of robot.yaml, and they are
+given after "--" on command line (like "rcc run -t task -- --count 5"). Some
+required argument was not given, or given value was not of declared type
+(string, int, float, or bool).`)
+	registerErrorCode(ErrSettingsInvalid, "Settings are invalid",
+		"Check settings.yaml in ROBOCORP_HOME against \"rcc configuration settings --defaults\".",
+		"troubleshooting",
+		`
+Settings (settings.yaml in ROBOCORP_HOME, or active profile) could not be
+loaded or have invalid content. Fix the file, or switch to other profile with
+"rcc configuration switch".`)
+	registerErrorCode(ErrProfileNotFound, "Profile not found",
+		"List available profiles with \"rcc configuration switch\".",
+		"troubleshooting",
+		`
+Requested configuration profile was not found from imported profiles. Import
+profile first with "rcc configuration import", and check its exact name.`)
+}
+
+// LookupErrorCode finds error code, case insensitively.
+func LookupErrorCode(code string) (*ErrorCode, bool) {
+	found, ok := errorCodes[strings.ToUpper(strings.TrimSpace(code))]
+	return found, ok
+}
+
+// ErrorCodes lists all known error codes in code order.
+func ErrorCodes() []*ErrorCode {
+	result := make([]*ErrorCode, 0, len(errorCodes))
+	for _, code := range errorCodes {
+		result = append(result, code)
+	}
+	sort.Slice(result, func(left, right int) bool {
+		return result[left].Code < result[right].Code
+	})
+	return result
+}
+
+// ExplainFailure decorates failure message with error code, remediation hint,
+// and pointer to full description.
+func ExplainFailure(code, message string) string {
+	found, ok := LookupErrorCode(code)
+	if !ok {
+		return fmt.Sprintf("[%s] %s", code, message)
+	}
+	return fmt.Sprintf("[%s] %s\nHint: %s\nDetails: rcc explain %s (docs: %s)", found.Code, message, found.Hint, found.Code, ErrorDocsLink(found.Docs))
+}
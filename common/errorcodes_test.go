@@ -0,0 +1,44 @@
+package common_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestErrorCodesAreWellFormed(t *testing.T) {
+	must_be, wont_be := hamlet.Specifications(t)
+
+	pattern := regexp.MustCompile(`^E[1-5][0-9]{3}$`)
+	codes := common.ErrorCodes()
+	wont_be.Equal(0, len(codes))
+	for at, code := range codes {
+		must_be.True(pattern.MatchString(code.Code))
+		wont_be.Equal("", code.Title)
+		wont_be.Equal("", code.Hint)
+		wont_be.Equal("", code.Description)
+		wont_be.Equal("", code.Docs)
+		if at > 0 {
+			must_be.True(codes[at-1].Code < code.Code)
+		}
+	}
+
+	found, ok := common.LookupErrorCode(" e2104 ")
+	must_be.True(ok)
+	must_be.Equal(common.ErrCatalogPull, found.Code)
+	_, ok = common.LookupErrorCode("E9999")
+	wont_be.True(ok)
+}
+
+func TestFailuresAreExplainedWithCodeAndHint(t *testing.T) {
+	must_be, _ := hamlet.Specifications(t)
+
+	message := common.ExplainFailure(common.ErrCatalogPull, "pull failed")
+	must_be.True(strings.HasPrefix(message, "[E2104] pull failed\n"))
+	must_be.True(strings.Contains(message, "Hint: "))
+	must_be.True(strings.Contains(message, "rcc explain E2104"))
+	must_be.Equal("[E9999] other failure", common.ExplainFailure("E9999", "other failure"))
+}
@@ -10,10 +10,18 @@ import (
 )
 
 var (
-	logsource  = make(logwriters)
-	logbarrier = sync.WaitGroup{}
+	logsource    = make(logwriters)
+	logbarrier   = sync.WaitGroup{}
+	observerLock = sync.Mutex{}
+	observerLast = 0
+	logObservers = []*logObserver{}
 )
 
+type logObserver struct {
+	identity int
+	observe  func(string)
+}
+
 type logwriter func() (*os.File, string)
 type logwriters chan logwriter
 
@@ -37,6 +45,7 @@ func loggerLoop(writers logwriters) {
 		}
 		fmt.Fprintf(out, "%s%s\n", stamp, message)
 		out.Sync()
+		notifyLogObservers(message)
 		logbarrier.Done()
 	}
 }
@@ -45,6 +54,38 @@ func init() {
 	go loggerLoop(logsource)
 }
 
+// RegisterLogObserver adds observer, which sees every log line after it is
+// written, in order of registration. Returned identity unregisters it.
+func RegisterLogObserver(observe func(string)) int {
+	observerLock.Lock()
+	defer observerLock.Unlock()
+	observerLast += 1
+	logObservers = append(logObservers, &logObserver{identity: observerLast, observe: observe})
+	return observerLast
+}
+
+// UnregisterLogObserver removes observer registered with given identity.
+func UnregisterLogObserver(identity int) {
+	observerLock.Lock()
+	defer observerLock.Unlock()
+	remaining := make([]*logObserver, 0, len(logObservers))
+	for _, observer := range logObservers {
+		if observer.identity != identity {
+			remaining = append(remaining, observer)
+		}
+	}
+	logObservers = remaining
+}
+
+func notifyLogObservers(message string) {
+	observerLock.Lock()
+	observers := logObservers
+	observerLock.Unlock()
+	for _, observer := range observers {
+		observer.observe(message)
+	}
+}
+
 func AcceptableOutput(message string) bool {
 	for _, fragment := range LogHides {
 		if strings.Contains(message, fragment) {
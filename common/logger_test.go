@@ -0,0 +1,34 @@
+package common_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestLogObserversCanBeRegisteredAndUnregistered(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	lock := sync.Mutex{}
+	seen := []string{}
+	identity := common.RegisterLogObserver(func(message string) {
+		if strings.HasPrefix(message, "observed ") {
+			lock.Lock()
+			defer lock.Unlock()
+			seen = append(seen, message)
+		}
+	})
+	common.Log("observed %d", 1)
+	common.WaitLogs()
+	common.UnregisterLogObserver(identity)
+	common.Log("observed %d", 2)
+	common.WaitLogs()
+
+	lock.Lock()
+	defer lock.Unlock()
+	must.Equal([]string{"observed 1"}, seen)
+	wont.Equal(0, identity)
+}
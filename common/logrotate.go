@@ -0,0 +1,155 @@
+package common
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultLogMaxSize  = 10 * 1024 * 1024
+	defaultLogMaxFiles = 5
+)
+
+var (
+	rotatedLogPattern = regexp.MustCompile(`\.\d+\.gz$`)
+)
+
+// ParseLogSize parses size with optional K, M, or G suffix (binary units,
+// trailing "B" or "iB" is allowed), like "500K", "10M", or "1GiB".
+func ParseLogSize(text string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(text))
+	value = strings.TrimSuffix(strings.TrimSuffix(value, "B"), "I")
+	multiplier := int64(1)
+	switch {
+	case strings.HasSuffix(value, "K"):
+		multiplier = 1024
+	case strings.HasSuffix(value, "M"):
+		multiplier = 1024 * 1024
+	case strings.HasSuffix(value, "G"):
+		multiplier = 1024 * 1024 * 1024
+	}
+	if multiplier > 1 {
+		value = value[:len(value)-1]
+	}
+	size, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("Invalid log size %q.", text)
+	}
+	return size * multiplier, nil
+}
+
+// RccLogMaxSize is size limit of rcc managed log and journal files, after
+// which they are rotated. Zero means no rotation.
+func RccLogMaxSize() int64 {
+	value := strings.TrimSpace(os.Getenv(RCC_LOG_MAX_SIZE))
+	if len(value) == 0 {
+		return defaultLogMaxSize
+	}
+	size, err := ParseLogSize(value)
+	if err != nil {
+		return defaultLogMaxSize
+	}
+	return size
+}
+
+// RccLogMaxFiles is how many compressed rotated files are kept of each rcc
+// managed log and journal file.
+func RccLogMaxFiles() int {
+	count, err := strconv.Atoi(strings.TrimSpace(os.Getenv(RCC_LOG_MAX_FILES)))
+	if err != nil || count < 1 {
+		return defaultLogMaxFiles
+	}
+	return count
+}
+
+// RotatedLog gives name of Nth rotated (compressed) file of log.
+func RotatedLog(filename string, nth int) string {
+	return fmt.Sprintf("%s.%d.gz", filename, nth)
+}
+
+// RotatedLogs lists rotated (compressed) log files in directory.
+func RotatedLogs(directory string) []string {
+	result := []string{}
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return result
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && rotatedLogPattern.MatchString(entry.Name()) {
+			result = append(result, filepath.Join(directory, entry.Name()))
+		}
+	}
+	return result
+}
+
+// RotateLog rotates log file, when it has grown to configured maximum size
+// (see RccLogMaxSize and RccLogMaxFiles). Tells if rotation happened.
+func RotateLog(filename string) (bool, error) {
+	return RotateLogAt(filename, RccLogMaxSize(), RccLogMaxFiles())
+}
+
+// RotateLogAt rotates log file, when it is at least limit bytes in size.
+// Current content is compressed into "filename.1.gz", older rotations are
+// shifted one number up, and only keep rotations are retained. If other
+// process is rotating same file at same time, this one backs off.
+func RotateLogAt(filename string, limit int64, keep int) (bool, error) {
+	if limit < 1 {
+		return false, nil
+	}
+	stat, err := os.Stat(filename)
+	if err != nil || stat.Size() < limit {
+		return false, nil
+	}
+	rotating := fmt.Sprintf("%s.%d.rotating", filename, os.Getpid())
+	err = os.Rename(filename, rotating)
+	if err != nil {
+		return false, nil
+	}
+	keep = max(keep, 1)
+	os.Remove(RotatedLog(filename, keep))
+	for nth := keep - 1; nth > 0; nth-- {
+		os.Rename(RotatedLog(filename, nth), RotatedLog(filename, nth+1))
+	}
+	err = compressLog(rotating, RotatedLog(filename, 1))
+	if err != nil {
+		return true, err
+	}
+	return true, os.Remove(rotating)
+}
+
+func compressLog(source, target string) (err error) {
+	reader, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	partial := target + ".part"
+	sink, err := os.OpenFile(partial, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o640)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(partial)
+		}
+	}()
+	writer := gzip.NewWriter(sink)
+	_, err = io.Copy(writer, reader)
+	if err == nil {
+		err = writer.Close()
+	}
+	closed := sink.Close()
+	if err == nil {
+		err = closed
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(partial, target)
+}
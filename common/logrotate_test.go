@@ -0,0 +1,83 @@
+package common_test
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func readRotated(t *testing.T, filename string) string {
+	source, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer source.Close()
+	reader, err := gzip.NewReader(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestCanParseLogSizes(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	for text, expected := range map[string]int64{"0": 0, "1000": 1000, "500k": 500 * 1024, "10M": 10 * 1024 * 1024, "1GiB": 1024 * 1024 * 1024, " 2MB ": 2 * 1024 * 1024} {
+		size, err := common.ParseLogSize(text)
+		must.Nil(err)
+		must.Equal(expected, size)
+	}
+	for _, text := range []string{"", "big", "-1M", "10T"} {
+		_, err := common.ParseLogSize(text)
+		wont.Nil(err)
+	}
+}
+
+func TestLogRotationKeepsLimitedCompressedFiles(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	filename := filepath.Join(t.TempDir(), "event.log")
+	must.Nil(os.WriteFile(filename, []byte("small\n"), 0o644))
+	rotated, err := common.RotateLogAt(filename, 100, 2)
+	must.Nil(err)
+	wont.True(rotated)
+
+	for _, round := range []string{"first", "second", "third"} {
+		must.Nil(os.WriteFile(filename, []byte(strings.Repeat(round+"\n", 50)), 0o644))
+		rotated, err = common.RotateLogAt(filename, 100, 2)
+		must.Nil(err)
+		must.True(rotated)
+		_, err = os.Stat(filename)
+		must.True(os.IsNotExist(err))
+	}
+	must.True(strings.HasPrefix(readRotated(t, common.RotatedLog(filename, 1)), "third\n"))
+	must.True(strings.HasPrefix(readRotated(t, common.RotatedLog(filename, 2)), "second\n"))
+	_, err = os.Stat(common.RotatedLog(filename, 3))
+	must.True(os.IsNotExist(err))
+
+	must.Nil(os.WriteFile(filename, []byte(strings.Repeat("x", 500)), 0o644))
+	rotated, err = common.RotateLogAt(filename, 0, 2)
+	must.Nil(err)
+	wont.True(rotated)
+}
+
+func TestCanListRotatedLogs(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	directory := t.TempDir()
+	for _, name := range []string{"event.log", "event.log.1.gz", "event.log.12.gz", "other.gz", "rccremote.log.2.gz"} {
+		must.Nil(os.WriteFile(filepath.Join(directory, name), []byte("x"), 0o644))
+	}
+	must.Equal(3, len(common.RotatedLogs(directory)))
+	must.Equal(0, len(common.RotatedLogs(filepath.Join(directory, "missing"))))
+}
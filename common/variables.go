@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
@@ -27,8 +28,24 @@ const (
 const (
 	RCC_REMOTE_ORIGIN                     = `RCC_REMOTE_ORIGIN`
 	RCC_REMOTE_AUTHORIZATION              = `RCC_REMOTE_AUTHORIZATION`
+	RCC_REMOTE_DOMAIN                     = `RCC_REMOTE_DOMAIN`
+	RCC_REMOTE_STREAMING                  = `RCC_REMOTE_STREAMING`
+	RCC_PARALLEL_PIP                      = `RCC_PARALLEL_PIP`
+	RCC_RESTORE_VERIFY                    = `RCC_RESTORE_VERIFY`
+	RCC_SUMMARY_TO                        = `RCC_SUMMARY_TO`
+	RCC_CREDENTIALS_STORE                 = `RCC_CREDENTIALS_STORE`
+	RCC_CREDENTIALS_KEY                   = `RCC_CREDENTIALS_KEY`
+	RCC_REMOTE_CLIENT_CERT                = `RCC_REMOTE_CLIENT_CERT`
+	RCC_REMOTE_CLIENT_KEY                 = `RCC_REMOTE_CLIENT_KEY`
 	RCC_NO_TEMP_MANAGEMENT                = `RCC_NO_TEMP_MANAGEMENT`
 	RCC_NO_PYC_MANAGEMENT                 = `RCC_NO_PYC_MANAGEMENT`
+	RCC_NO_BROWSER_CACHE                  = `RCC_NO_BROWSER_CACHE`
+	RCC_NO_CLONE                          = `RCC_NO_CLONE`
+	RCC_RUN_LOCK                          = `RCC_RUN_LOCK`
+	RCC_LOG_MAX_SIZE                      = `RCC_LOG_MAX_SIZE`
+	RCC_PULL_VERIFY                       = `RCC_PULL_VERIFY`
+	RCC_LOG_MAX_FILES                     = `RCC_LOG_MAX_FILES`
+	PLAYWRIGHT_BROWSERS_PATH              = `PLAYWRIGHT_BROWSERS_PATH`
 	VERBOSE_ENVIRONMENT_BUILDING          = `RCC_VERBOSE_ENVIRONMENT_BUILDING`
 	ROBOCORP_OVERRIDE_SYSTEM_REQUIREMENTS = `ROBOCORP_OVERRIDE_SYSTEM_REQUIREMENTS`
 	RCC_VERBOSITY                         = `RCC_VERBOSITY`
@@ -39,6 +56,7 @@ const (
 
 var (
 	NoBuild                 bool
+	Offline                 bool
 	NoRetryBuild            bool
 	NoTempManagement        bool
 	NoPycManagement         bool
@@ -58,6 +76,8 @@ var (
 	StageFolder             string
 	ControllerType          string
 	HolotreeSpace           string
+	SummaryTarget           string
+	PullVerify              string
 	EnvironmentHash         string
 	SemanticTag             string
 	When                    int64
@@ -129,15 +149,101 @@ func DisablePycManagement() bool {
 	return NoPycManagement || len(os.Getenv(RCC_NO_PYC_MANAGEMENT)) > 0
 }
 
+func RccNoClone() bool {
+	return len(os.Getenv(RCC_NO_CLONE)) > 0
+}
+
+// RccRunLock returns default run lock mode (wait, fail, or suffix) given in
+// environment, if any.
+func RccRunLock() string {
+	return strings.ToLower(strings.TrimSpace(os.Getenv(RCC_RUN_LOCK)))
+}
+
+// BrowserCacheEnabled tells if playwright browser builds are cached in
+// hololib. Setting PLAYWRIGHT_BROWSERS_PATH to "0" means browsers live inside
+// environment, and then there is nothing to cache.
+func BrowserCacheEnabled() bool {
+	return len(os.Getenv(RCC_NO_BROWSER_CACHE)) == 0 && os.Getenv(PLAYWRIGHT_BROWSERS_PATH) != "0"
+}
+
 func RccRemoteOrigin() string {
 	return os.Getenv(RCC_REMOTE_ORIGIN)
 }
 
+func RccRemoteStreaming() bool {
+	return len(os.Getenv(RCC_REMOTE_STREAMING)) > 0
+}
+
+func RccRemoteClientCertificate() (string, string, bool) {
+	certificate, key := os.Getenv(RCC_REMOTE_CLIENT_CERT), os.Getenv(RCC_REMOTE_CLIENT_KEY)
+	return certificate, key, len(certificate) > 0 && len(key) > 0
+}
+
+func RccParallelPip() bool {
+	return len(os.Getenv(RCC_PARALLEL_PIP)) > 0
+}
+
+// RccRestoreVerify is percentage (0-100) of restored files, which are digest
+// verified even when their size and mode already match catalog.
+func RccRestoreVerify() float64 {
+	value, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(os.Getenv(RCC_RESTORE_VERIFY)), "%"), 64)
+	if err != nil {
+		return 0
+	}
+	return min(max(value, 0), 100)
+}
+
+// RccSummaryTarget is where end-of-run JSON summary is written, either file
+// name, or "fd:N" for already open file descriptor. Empty means no summary.
+func RccSummaryTarget() string {
+	if len(SummaryTarget) > 0 {
+		return SummaryTarget
+	}
+	return strings.TrimSpace(os.Getenv(RCC_SUMMARY_TO))
+}
+
+// RccPullVerify is how pulled and imported blobs are verified: "full",
+// "spot", or "trust". Empty means default (full).
+func RccPullVerify() string {
+	if len(PullVerify) > 0 {
+		return strings.ToLower(strings.TrimSpace(PullVerify))
+	}
+	return strings.ToLower(strings.TrimSpace(os.Getenv(RCC_PULL_VERIFY)))
+}
+
 func RccRemoteAuthorization() (string, bool) {
 	result := os.Getenv(RCC_REMOTE_AUTHORIZATION)
 	return result, len(result) > 0
 }
 
+func RccRemoteDomain() (string, bool) {
+	result := os.Getenv(RCC_REMOTE_DOMAIN)
+	return result, len(result) > 0
+}
+
+func RccCredentialsStore() string {
+	return strings.ToLower(strings.TrimSpace(os.Getenv(RCC_CREDENTIALS_STORE)))
+}
+
+func RccCredentialsKey() (string, bool) {
+	result := os.Getenv(RCC_CREDENTIALS_KEY)
+	return result, len(result) > 0
+}
+
+func CredentialsVaultLocation() string {
+	return filepath.Join(Product.Home(), "credentials.vault")
+}
+
+// CredentialsKeyLocation is generated vault key location, which is kept in
+// user configuration directory, away from vault itself under product home.
+func CredentialsKeyLocation() string {
+	config, err := os.UserConfigDir()
+	if err != nil || len(config) == 0 {
+		return ""
+	}
+	return filepath.Join(config, "rcc", "credentials.key")
+}
+
 func ProductLock() string {
 	return filepath.Join(Product.Home(), "robocorp.lck")
 }
@@ -182,10 +288,61 @@ func EventJournal() string {
 	return filepath.Join(JournalLocation(), "event.log")
 }
 
+func RccRemoteLogLocation() string {
+	return filepath.Join(Product.Home(), "rccremote.log")
+}
+
+func RunLockLocation() string {
+	return filepath.Join(Product.Home(), "runlocks")
+}
+
+func MirrorHealthLocation() string {
+	return filepath.Join(Product.Home(), "mirrors.json")
+}
+
+func InteractiveSessionLocation() string {
+	return filepath.Join(Product.Home(), "interactive.yaml")
+}
+
+// ServerProfilesLocation is where saved rccremote server profiles are kept.
+func ServerProfilesLocation() string {
+	return filepath.Join(Product.Home(), "servers.yaml")
+}
+
+// BrowsersLocation is where playwright browser builds are rehydrated, which
+// is user given PLAYWRIGHT_BROWSERS_PATH, or "browsers" in ROBOCORP_HOME.
+func BrowsersLocation() string {
+	location := os.Getenv(PLAYWRIGHT_BROWSERS_PATH)
+	if len(location) > 0 {
+		return ExpandPath(location)
+	}
+	return filepath.Join(Product.Home(), "browsers")
+}
+
 func JournalLocation() string {
 	return filepath.Join(Product.Home(), "journals")
 }
 
+func LocalPackagesLocation() string {
+	return filepath.Join(Product.Home(), "localpackages")
+}
+
+func QuickActionsLocation() string {
+	return filepath.Join(Product.Home(), "quickactions.yaml")
+}
+
+func RestoreFindingsLocation() string {
+	return filepath.Join(Product.Home(), "restorefindings.json")
+}
+
+func AntivirusFindingsLocation() string {
+	return filepath.Join(Product.Home(), "antivirusfindings.json")
+}
+
+func StepDurationsLocation() string {
+	return filepath.Join(Product.Home(), "stepdurations.json")
+}
+
 func TemplateLocation() string {
 	return filepath.Join(Product.Home(), "templates")
 }
@@ -257,6 +414,18 @@ func HololibCatalogLocation() string {
 	return filepath.Join(HololibLocation(), "catalog")
 }
 
+func HololibStreamingLocation() string {
+	return filepath.Join(HololibLocation(), "streaming")
+}
+
+func HololibSnapshotLocation() string {
+	return filepath.Join(HololibLocation(), "snapshots")
+}
+
+func HololibCatalogIndex() string {
+	return filepath.Join(HololibLocation(), "catalog.index")
+}
+
 func HololibLibraryLocation() string {
 	return filepath.Join(HololibLocation(), "library")
 }
@@ -265,6 +434,20 @@ func HololibUsageLocation() string {
 	return filepath.Join(HololibLocation(), "used")
 }
 
+func HololibMutationLocation() string {
+	return filepath.Join(HololibLocation(), "mutations")
+}
+
+// HololibScrubLocation is where scrubber keeps its position in hololib, so
+// that each cycle continues verification where previous one ended.
+func HololibScrubLocation() string {
+	return filepath.Join(HololibLocation(), "scrub.json")
+}
+
+func HololibLiftLocation() string {
+	return filepath.Join(HololibLocation(), "lift")
+}
+
 func HololibCompressMarker() string {
 	return filepath.Join(HololibCatalogLocation(), "compress.no")
 }
@@ -26,20 +26,24 @@ var (
 )
 
 type internalEnvironment struct {
-	Name         string        `yaml:"name,omitempty"`
-	Channels     []string      `yaml:"channels"`
-	Dependencies []interface{} `yaml:"dependencies"`
-	Prefix       string        `yaml:"prefix,omitempty"`
-	PostInstall  []string      `yaml:"rccPostInstall,omitempty"`
+	Name          string        `yaml:"name,omitempty"`
+	Channels      []string      `yaml:"channels"`
+	Dependencies  []interface{} `yaml:"dependencies"`
+	Prefix        string        `yaml:"prefix,omitempty"`
+	PostInstall   []string      `yaml:"rccPostInstall,omitempty"`
+	LocalPackages []string      `yaml:"localPackages,omitempty"`
+	Extends       extendsList   `yaml:"extends,omitempty"`
 }
 
 type Environment struct {
-	Name        string
-	Prefix      string
-	Channels    []string
-	Conda       []*Dependency
-	Pip         []*Dependency
-	PostInstall []string
+	Name          string
+	Prefix        string
+	Channels      []string
+	Conda         []*Dependency
+	Pip           []*Dependency
+	PostInstall   []string
+	LocalPackages []string
+	Extends       []string
 }
 
 type Dependency struct {
@@ -140,9 +144,12 @@ func (it *internalEnvironment) AsEnvironment() *Environment {
 		Name:        it.Name,
 		Prefix:      it.Prefix,
 		PostInstall: []string{},
+		Extends:     it.Extends,
 	}
 	seenScripts := make(map[string]bool)
 	result.PostInstall = addItem(seenScripts, it.PostInstall, result.PostInstall)
+	seenPackages := make(map[string]bool)
+	result.LocalPackages = addItem(seenPackages, it.LocalPackages, result.LocalPackages)
 	channel, ok := LocalChannel()
 	if ok {
 		pushChannels(result, []string{channel})
@@ -215,6 +222,9 @@ func (it *Environment) ValidateUvNative() error {
 }
 
 func (it *Environment) IsCacheable() bool {
+	if len(it.LocalPackages) > 0 {
+		return false
+	}
 	for _, dependency := range it.Conda {
 		if !dependency.IsCacheable() {
 			return false
@@ -230,12 +240,13 @@ func (it *Environment) IsCacheable() bool {
 
 func (it *Environment) FreezeDependencies(fixed dependencies) *Environment {
 	result := &Environment{
-		Name:        it.Name,
-		Prefix:      it.Prefix,
-		Channels:    it.Channels,
-		Conda:       []*Dependency{},
-		Pip:         []*Dependency{},
-		PostInstall: it.PostInstall,
+		Name:          it.Name,
+		Prefix:        it.Prefix,
+		Channels:      it.Channels,
+		Conda:         []*Dependency{},
+		Pip:           []*Dependency{},
+		PostInstall:   it.PostInstall,
+		LocalPackages: it.LocalPackages,
 	}
 	used := make(map[string]bool)
 	for _, dependency := range fixed {
@@ -273,12 +284,13 @@ func (it *Environment) FreezeDependencies(fixed dependencies) *Environment {
 
 func (it *Environment) FromDependencies(fixed dependencies) (*Environment, bool) {
 	result := &Environment{
-		Name:        it.Name,
-		Prefix:      it.Prefix,
-		Channels:    it.Channels,
-		Conda:       []*Dependency{},
-		Pip:         []*Dependency{},
-		PostInstall: it.PostInstall,
+		Name:          it.Name,
+		Prefix:        it.Prefix,
+		Channels:      it.Channels,
+		Conda:         []*Dependency{},
+		Pip:           []*Dependency{},
+		PostInstall:   it.PostInstall,
+		LocalPackages: it.LocalPackages,
 	}
 	same := true
 	for _, dependency := range it.Conda {
@@ -420,6 +432,10 @@ func (it *Environment) Merge(right *Environment) (*Environment, error) {
 	result.PostInstall = addItem(seenScripts, it.PostInstall, result.PostInstall)
 	result.PostInstall = addItem(seenScripts, right.PostInstall, result.PostInstall)
 
+	seenPackages := make(map[string]bool)
+	result.LocalPackages = addItem(seenPackages, it.LocalPackages, result.LocalPackages)
+	result.LocalPackages = addItem(seenPackages, right.LocalPackages, result.LocalPackages)
+
 	err := pushConda(result, it.Conda)
 	if err != nil {
 		return nil, err
@@ -552,6 +568,8 @@ func (it *Environment) AsYaml() (string, error) {
 	result.Dependencies = it.CondaList()
 	seenScripts := make(map[string]bool)
 	result.PostInstall = addItem(seenScripts, it.PostInstall, result.PostInstall)
+	seenPackages := make(map[string]bool)
+	result.LocalPackages = addItem(seenPackages, it.LocalPackages, result.LocalPackages)
 	if len(it.Pip) > 0 {
 		result.Dependencies = append(result.Dependencies, it.PipMap())
 	}
@@ -677,9 +695,26 @@ func (it *Environment) Diagnostics(target *common.DiagnosticStatus, production b
 	if floating {
 		diagnose.Warning(0, "", "Floating dependencies in %s Cloud containers will be slow, because floating environments cannot be cached.", common.Product.Name())
 	}
+	requirement := it.GpuRequirement()
+	if requirement == nil {
+		return
+	}
+	target.Details["gpu-requirement"] = requirement.String()
+	host := DetectGpu()
+	switch requirement.Compatibility(host) {
+	case GpuMissing:
+		diagnose.Warning(common.CategoryGpu, "", "Environment requires %s, but there is no NVIDIA driver on this machine.", requirement)
+	case GpuIncompatible:
+		notice(common.CategoryGpu, "", "Environment requires %s, but driver %s only supports CUDA %s.", requirement, host.Driver, host.Cuda)
+	case GpuMinorMismatch:
+		diagnose.Warning(common.CategoryGpu, "", "Environment requires %s, but driver %s supports CUDA %s, so it relies on minor version compatibility.", requirement, host.Driver, host.Cuda)
+	default:
+		diagnose.Ok(common.CategoryGpu, "Environment requires %s, and %s is compatible.", requirement, host)
+	}
 }
 
 func CondaYamlFrom(content []byte) (*Environment, error) {
+	content = currentPlatformOnly(content)
 	result := new(internalEnvironment)
 	err := yaml.Unmarshal(content, result)
 	if err != nil {
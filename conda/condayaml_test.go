@@ -1,11 +1,14 @@
 package conda_test
 
 import (
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/joshyorko/rcc/common"
 	"github.com/joshyorko/rcc/conda"
 	"github.com/joshyorko/rcc/hamlet"
+	"github.com/joshyorko/rcc/pathlib"
 )
 
 func TestCanParseDependencies(t *testing.T) {
@@ -238,3 +241,82 @@ func TestCacheability(t *testing.T) {
 	wont_be.True(conda.IsCacheable("urllib3@https://github.com/urllib3/urllib3/archive/refs/tags/1.26.8.zip"))
 	wont_be.True(conda.IsCacheable("https://github.com/urllib3/urllib3/archive/refs/tags/1.26.8.zip"))
 }
+
+func TestCanResolveLocalPackagesIntoBlueprint(t *testing.T) {
+	must_be, wont_be := hamlet.Specifications(t)
+
+	common.Product.ForceHome(t.TempDir())
+	defer common.Product.ForceHome("")
+
+	robotdir := t.TempDir()
+	wheel := filepath.Join(robotdir, "wheels", "private-1.0-py3-none-any.whl")
+	must_be.Nil(pathlib.WriteFile(wheel, []byte("not really a wheel"), 0o644))
+	condafile := filepath.Join(robotdir, "conda.yaml")
+	content := "channels:\n- conda-forge\ndependencies:\n- python=3.10.12\nlocalPackages:\n- wheels/private-1.0-py3-none-any.whl\n"
+	must_be.Nil(pathlib.WriteFile(condafile, []byte(content), 0o644))
+
+	sut, err := conda.ReadPackageCondaYaml(condafile, false)
+	must_be.Nil(err)
+	must_be.Equal(1, len(sut.LocalPackages))
+	must_be.Equal("private-1.0-py3-none-any.whl#sha256=5012353aa6a0734b468ac4dcad5b802e70382f41303f87cfe89d223e3a6be654", sut.LocalPackages[0])
+	wont_be.True(sut.IsCacheable())
+
+	stored, ok := conda.LocalPackageFile(sut.LocalPackages[0])
+	must_be.True(ok)
+	wont_be.True(pathlib.IsFile(stored))
+	must_be.Nil(conda.StoreLocalPackages(sut.LocalPackages))
+	must_be.True(pathlib.IsFile(stored))
+
+	layers := sut.AsLayers()
+	must_be.True(strings.Contains(layers[2], "localPackages"))
+	wont_be.True(strings.Contains(layers[1], "localPackages"))
+
+	blueprint, err := sut.AsYaml()
+	must_be.Nil(err)
+	again, err := conda.CondaYamlFrom([]byte(blueprint))
+	must_be.Nil(err)
+	must_be.Equal(sut.LocalPackages, again.LocalPackages)
+}
+
+func TestCanExtendBaseEnvironments(t *testing.T) {
+	must_be, wont_be := hamlet.Specifications(t)
+
+	teamdir := t.TempDir()
+	robotdir := t.TempDir()
+	base := filepath.Join(teamdir, "base.yaml")
+	extra := filepath.Join(teamdir, "extra.yaml")
+	condafile := filepath.Join(robotdir, "conda.yaml")
+	must_be.Nil(pathlib.WriteFile(base, []byte("name: team\nchannels:\n- conda-forge\ndependencies:\n- python=3.10.12\n- nodejs=18.17.1\n- pip=23.2.1\n- pip:\n  - requests==2.31.0\n  - robotframework==6.1.1\nrccPostInstall:\n- echo base\n"), 0o644))
+	must_be.Nil(pathlib.WriteFile(extra, []byte("channels:\n- bioconda\ndependencies:\n- pip:\n  - pyyaml==6.0.1\n"), 0o644))
+	content := "extends:\n- " + base + "\n- " + extra + "\ndependencies:\n- python=3.12.3\n- pip:\n  - robotframework==7.0\n  - rpaframework==28.0.0\nrccPostInstall:\n- echo robot\n"
+	must_be.Nil(pathlib.WriteFile(condafile, []byte(content), 0o644))
+
+	sut, err := conda.ReadPackageCondaYaml(condafile, false)
+	must_be.Nil(err)
+	must_be.Equal("team", sut.Name)
+	must_be.Equal([]string{"conda-forge", "bioconda"}, sut.Channels)
+	must_be.Equal([]interface{}{"python=3.12.3", "nodejs=18.17.1", "pip=23.2.1"}, sut.CondaList())
+	must_be.Equal([]interface{}{"requests==2.31.0", "robotframework==7.0", "pyyaml==6.0.1", "rpaframework==28.0.0"}, sut.PipList())
+	must_be.Equal([]string{"echo base", "echo robot"}, sut.PostInstall)
+
+	blueprint, err := sut.AsYaml()
+	must_be.Nil(err)
+	wont_be.True(strings.Contains(blueprint, "extends"))
+	again, err := conda.ReadPackageCondaYaml(condafile, false)
+	must_be.Nil(err)
+	repeated, err := again.AsYaml()
+	must_be.Nil(err)
+	must_be.Equal(blueprint, repeated)
+}
+
+func TestCanDetectCyclicExtends(t *testing.T) {
+	must_be, _ := hamlet.Specifications(t)
+
+	directory := t.TempDir()
+	must_be.Nil(pathlib.WriteFile(filepath.Join(directory, "first.yaml"), []byte("extends: second.yaml\ndependencies:\n- python=3.10.12\n"), 0o644))
+	must_be.Nil(pathlib.WriteFile(filepath.Join(directory, "second.yaml"), []byte("extends: first.yaml\ndependencies:\n- pip=23.2.1\n"), 0o644))
+
+	_, err := conda.ReadPackageCondaYaml(filepath.Join(directory, "first.yaml"), false)
+	must_be.True(err != nil)
+	must_be.True(strings.Contains(err.Error(), "Cyclic 'extends'"))
+}
@@ -0,0 +1,147 @@
+package conda
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// extendsList is list of base environments of conda.yaml, given either as
+// single reference or as list of them.
+type extendsList []string
+
+func (it *extendsList) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var single string
+	if unmarshal(&single) == nil {
+		*it = extendsList{single}
+		return nil
+	}
+	var multiple []string
+	err := unmarshal(&multiple)
+	if err != nil {
+		return fmt.Errorf("'extends' must be a file reference or a list of them, reason: %w", err)
+	}
+	*it = multiple
+	return nil
+}
+
+func isRemoteReference(reference string) bool {
+	lowered := strings.ToLower(reference)
+	return strings.HasPrefix(lowered, "http://") || strings.HasPrefix(lowered, "https://")
+}
+
+// extendsLocation resolves base reference relative to file that extends it.
+func extendsLocation(filename, reference string) string {
+	if isRemoteReference(reference) {
+		return reference
+	}
+	if isRemoteReference(filename) {
+		origin, err := url.Parse(filename)
+		relative, fault := url.Parse(filepath.ToSlash(reference))
+		if err == nil && fault == nil {
+			return origin.ResolveReference(relative).String()
+		}
+		return reference
+	}
+	if filepath.IsAbs(reference) {
+		return filepath.Clean(reference)
+	}
+	return filepath.Join(filepath.Dir(filename), reference)
+}
+
+func extendsIdentity(location string) string {
+	if isRemoteReference(location) {
+		return location
+	}
+	fullpath, err := filepath.Abs(location)
+	if err != nil {
+		return filepath.Clean(location)
+	}
+	return fullpath
+}
+
+// resolveExtends layers environment on top of its base environments. Bases
+// are applied in listed order, and each later layer overrides earlier ones.
+// Chain holds files being resolved, to detect cyclic extends.
+func (it *Environment) resolveExtends(filename string, chain []string) (*Environment, error) {
+	if len(it.Extends) == 0 {
+		return it, nil
+	}
+	chain = append(slices.Clone(chain), extendsIdentity(filename))
+	var result *Environment
+	for _, reference := range it.Extends {
+		location := extendsLocation(filename, reference)
+		if slices.Contains(chain, extendsIdentity(location)) {
+			return nil, fmt.Errorf("Cyclic 'extends' in conda.yaml: %s -> %s", strings.Join(chain, " -> "), extendsIdentity(location))
+		}
+		base, err := readPackageCondaYaml(location, false, chain)
+		if err != nil {
+			return nil, fmt.Errorf("Could not extend %q, reason: %w", reference, err)
+		}
+		if result == nil {
+			result = base
+			continue
+		}
+		result, err = result.Overlay(base)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result.Overlay(it)
+}
+
+func overlayDependencies(base, overrides []*Dependency) []*Dependency {
+	result := slices.Clone(base)
+	for _, candidate := range overrides {
+		index := candidate.Index(result)
+		if index < 0 {
+			index = slices.IndexFunc(result, candidate.ExactlySame)
+		}
+		if index < 0 {
+			result = append(result, candidate)
+		} else {
+			result[index] = candidate
+		}
+	}
+	return result
+}
+
+// Overlay layers right environment on top of this one. Unlike Merge, which
+// refuses conflicting versions, right side always wins: its dependencies
+// replace same named ones (keeping their position), new ones are appended,
+// and channels, post install scripts, and local packages are appended when
+// not already present. Name and prefix come from right side, when given.
+func (it *Environment) Overlay(right *Environment) (*Environment, error) {
+	result := &Environment{
+		Name:   it.Name,
+		Prefix: it.Prefix,
+	}
+	if len(right.Name) > 0 {
+		result.Name = right.Name
+	}
+	if len(right.Prefix) > 0 {
+		result.Prefix = right.Prefix
+	}
+
+	seenChannels := make(map[string]bool)
+	result.Channels = addItem(seenChannels, it.Channels, result.Channels)
+	result.Channels = addItem(seenChannels, right.Channels, result.Channels)
+
+	seenScripts := make(map[string]bool)
+	result.PostInstall = addItem(seenScripts, it.PostInstall, []string{})
+	result.PostInstall = addItem(seenScripts, right.PostInstall, result.PostInstall)
+
+	seenPackages := make(map[string]bool)
+	result.LocalPackages = addItem(seenPackages, it.LocalPackages, result.LocalPackages)
+	result.LocalPackages = addItem(seenPackages, right.LocalPackages, result.LocalPackages)
+
+	result.Conda = overlayDependencies(it.Conda, right.Conda)
+	result.Pip = overlayDependencies(it.Pip, right.Pip)
+	err := result.pipPromote()
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,240 @@
+package conda
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/joshyorko/rcc/shell"
+)
+
+const (
+	GpuCompatible = iota
+	GpuMinorMismatch
+	GpuIncompatible
+	GpuMissing
+)
+
+var (
+	driverVersionPattern = regexp.MustCompile(`Driver Version:\s*([0-9.]+)`)
+	cudaVersionPattern   = regexp.MustCompile(`CUDA Version:\s*([0-9.]+)`)
+	gpuDevicePattern     = regexp.MustCompile(`(?m)^GPU \d+:\s*(.*?)\s*(?:\(UUID:.*)?$`)
+	leadingCudaVersion   = regexp.MustCompile(`^(\d+)(?:\.(\d+))?`)
+	pipCudaSuffix        = regexp.MustCompile(`-cu(\d+)$`)
+	localCudaVersion     = regexp.MustCompile(`\+cu(\d+)(\d)$`)
+
+	gpuDetection sync.Once
+	detectedGpu  *GpuHost
+
+	// cuda packages pin CUDA version directly
+	cudaPackages = []string{"cudatoolkit", "cuda-version", "cuda-toolkit", "cuda-runtime", "cuda-cudart", "pytorch-cuda"}
+
+	// oldest CUDA that each cudnn major version was built for
+	cudnnMinimumCuda = map[string]string{
+		"7": "9.0",
+		"8": "10.2",
+		"9": "11.8",
+	}
+)
+
+// GpuHost tells what NVIDIA GPU support host has. Cuda is highest CUDA
+// version that installed driver supports.
+type GpuHost struct {
+	Driver  string
+	Cuda    string
+	Devices []string
+}
+
+// GpuRequirement tells that environment needs NVIDIA GPU, and what is
+// minimum CUDA version (empty if it could not be concluded) that host driver
+// must support. Packages are dependencies that caused the requirement.
+type GpuRequirement struct {
+	Cuda     string
+	Packages []string
+}
+
+// ParseNvidiaSmi parses output of plain `nvidia-smi` (summary) and
+// `nvidia-smi -L` (listing).
+func ParseNvidiaSmi(summary, listing string) *GpuHost {
+	result := &GpuHost{Devices: []string{}}
+	if found := driverVersionPattern.FindStringSubmatch(summary); len(found) > 1 {
+		result.Driver = found[1]
+	}
+	if found := cudaVersionPattern.FindStringSubmatch(summary); len(found) > 1 {
+		result.Cuda = found[1]
+	}
+	for _, found := range gpuDevicePattern.FindAllStringSubmatch(listing, -1) {
+		result.Devices = append(result.Devices, found[1])
+	}
+	return result
+}
+
+// DetectGpu detects NVIDIA driver and devices using nvidia-smi. Detection is
+// done only once per run.
+func DetectGpu() *GpuHost {
+	gpuDetection.Do(func() {
+		detectedGpu = &GpuHost{Devices: []string{}}
+		tool, err := exec.LookPath("nvidia-smi")
+		if err != nil {
+			common.Debug("No nvidia-smi found, so no NVIDIA GPU support detected.")
+			return
+		}
+		summary, _, err := shell.New(nil, ".", tool).CaptureOutput()
+		if err != nil {
+			common.Debug("Running %q failed, reason: %v", tool, err)
+			return
+		}
+		listing, _, err := shell.New(nil, ".", tool, "-L").CaptureOutput()
+		if err != nil {
+			common.Debug("Listing GPUs with %q failed, reason: %v", tool, err)
+		}
+		detectedGpu = ParseNvidiaSmi(summary, listing)
+		common.Timeline("GPU driver %q supports CUDA %q with %d devices.", detectedGpu.Driver, detectedGpu.Cuda, len(detectedGpu.Devices))
+	})
+	return detectedGpu
+}
+
+// Available tells if there is working NVIDIA driver on host.
+func (it *GpuHost) Available() bool {
+	return it != nil && len(it.Driver) > 0
+}
+
+func (it *GpuHost) String() string {
+	if !it.Available() {
+		return "no NVIDIA GPU"
+	}
+	devices := "no devices listed"
+	if len(it.Devices) > 0 {
+		devices = strings.Join(it.Devices, ", ")
+	}
+	return fmt.Sprintf("NVIDIA driver %s (CUDA %s) with %s", it.Driver, it.Cuda, devices)
+}
+
+// cudaVersion gives "major.minor" from start of version text.
+func cudaVersion(text string) string {
+	found := leadingCudaVersion.FindStringSubmatch(strings.TrimSpace(text))
+	if len(found) < 2 {
+		return ""
+	}
+	if len(found[2]) == 0 {
+		return found[1] + ".0"
+	}
+	return found[1] + "." + found[2]
+}
+
+// pipCudaVersion gives CUDA version from pip package naming conventions, like
+// "nvidia-cudnn-cu12" or "torch==2.1.0+cu121".
+func pipCudaVersion(dependency *Dependency) string {
+	if found := localCudaVersion.FindStringSubmatch(dependency.Versions); len(found) > 2 {
+		return found[1] + "." + found[2]
+	}
+	if found := pipCudaSuffix.FindStringSubmatch(dependency.Name); len(found) > 1 {
+		return found[1] + ".0"
+	}
+	return ""
+}
+
+// CudaLess compares "major.minor" CUDA versions numerically.
+func CudaLess(left, right string) bool {
+	leftMajor, leftMinor := cudaParts(left)
+	rightMajor, rightMinor := cudaParts(right)
+	if leftMajor != rightMajor {
+		return leftMajor < rightMajor
+	}
+	return leftMinor < rightMinor
+}
+
+func cudaParts(version string) (int, int) {
+	major, minor, _ := strings.Cut(cudaVersion(version), ".")
+	first, _ := strconv.Atoi(major)
+	second, _ := strconv.Atoi(minor)
+	return first, second
+}
+
+func (it *GpuRequirement) demand(name, cuda string) {
+	it.Packages = append(it.Packages, name)
+	if len(cuda) > 0 && (len(it.Cuda) == 0 || CudaLess(it.Cuda, cuda)) {
+		it.Cuda = cuda
+	}
+}
+
+// GpuRequirement tells if environment needs NVIDIA GPU, based on CUDA and
+// cuDNN dependencies. Nil means no GPU is needed.
+func (it *Environment) GpuRequirement() *GpuRequirement {
+	result := &GpuRequirement{Packages: []string{}}
+	for _, dependency := range it.Conda {
+		switch {
+		case dependency.Name == "cudnn":
+			major, _, _ := strings.Cut(dependency.Versions, ".")
+			result.demand(dependency.Name, cudnnMinimumCuda[major])
+		case slices.Contains(cudaPackages, dependency.Name):
+			result.demand(dependency.Name, cudaVersion(dependency.Versions))
+		}
+	}
+	for _, dependency := range it.Pip {
+		cuda := pipCudaVersion(dependency)
+		if len(cuda) > 0 {
+			result.demand(dependency.Name, cuda)
+		}
+	}
+	if len(result.Packages) == 0 {
+		return nil
+	}
+	return result
+}
+
+// GpuRequirementOf tells GPU requirement of holotree blueprint.
+func GpuRequirementOf(blueprint []byte) *GpuRequirement {
+	environment, err := CondaYamlFrom(blueprint)
+	if err != nil {
+		return nil
+	}
+	return environment.GpuRequirement()
+}
+
+func (it *GpuRequirement) String() string {
+	if len(it.Cuda) == 0 {
+		return fmt.Sprintf("NVIDIA GPU (%s)", strings.Join(it.Packages, ", "))
+	}
+	return fmt.Sprintf("NVIDIA GPU with CUDA %s (%s)", it.Cuda, strings.Join(it.Packages, ", "))
+}
+
+// Compatibility tells if host can run environment with this requirement.
+// Newer minor version than driver supports may still work thru CUDA minor
+// version compatibility, but newer major version will not.
+func (it *GpuRequirement) Compatibility(host *GpuHost) int {
+	if !host.Available() {
+		return GpuMissing
+	}
+	if len(it.Cuda) == 0 || len(host.Cuda) == 0 || !CudaLess(host.Cuda, it.Cuda) {
+		return GpuCompatible
+	}
+	required, _ := cudaParts(it.Cuda)
+	supported, _ := cudaParts(host.Cuda)
+	if required == supported {
+		return GpuMinorMismatch
+	}
+	return GpuIncompatible
+}
+
+// WarnGpuRequirement warns when environment needs GPU that host does not
+// have, or newer CUDA than host driver supports.
+func WarnGpuRequirement(requirement *GpuRequirement, host *GpuHost) {
+	if requirement == nil {
+		return
+	}
+	switch requirement.Compatibility(host) {
+	case GpuMissing:
+		pretty.Warning("Environment requires %s, but no NVIDIA driver was detected on this machine. GPU code will fail or fall back to CPU.", requirement)
+	case GpuIncompatible:
+		pretty.Warning("Environment requires %s, but driver %s only supports CUDA %s. Upgrade driver or pin older CUDA in conda.yaml.", requirement, host.Driver, host.Cuda)
+	case GpuMinorMismatch:
+		pretty.Warning("Environment requires %s, and driver %s supports CUDA %s, so it relies on CUDA minor version compatibility.", requirement, host.Driver, host.Cuda)
+	}
+}
@@ -0,0 +1,67 @@
+package conda_test
+
+import (
+	"testing"
+
+	"github.com/joshyorko/rcc/conda"
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+const (
+	nvidiaSummary = `+-----------------------------------------------------------------------------+
+| NVIDIA-SMI 525.85.12    Driver Version: 525.85.12    CUDA Version: 12.0     |
+|-------------------------------+----------------------+----------------------+`
+	nvidiaListing = `GPU 0: NVIDIA GeForce RTX 3080 (UUID: GPU-2f1c1a9e-0000-0000-0000-000000000000)
+GPU 1: Tesla T4 (UUID: GPU-8d5e2b10-0000-0000-0000-000000000000)
+`
+)
+
+func TestCanParseNvidiaSmiOutput(t *testing.T) {
+	must_be, wont_be := hamlet.Specifications(t)
+
+	host := conda.ParseNvidiaSmi(nvidiaSummary, nvidiaListing)
+	must_be.True(host.Available())
+	must_be.Equal("525.85.12", host.Driver)
+	must_be.Equal("12.0", host.Cuda)
+	must_be.Equal(2, len(host.Devices))
+	must_be.Equal("NVIDIA GeForce RTX 3080", host.Devices[0])
+	must_be.Equal("Tesla T4", host.Devices[1])
+
+	wont_be.True(conda.ParseNvidiaSmi("", "").Available())
+}
+
+func TestCanDetectGpuRequirementOfEnvironment(t *testing.T) {
+	must_be, wont_be := hamlet.Specifications(t)
+
+	plain, err := conda.CondaYamlFrom([]byte("dependencies:\n- python=3.10.12\n- pip:\n  - requests==2.31.0\n"))
+	must_be.Nil(err)
+	must_be.Nil(plain.GpuRequirement())
+
+	cuda, err := conda.CondaYamlFrom([]byte("dependencies:\n- python=3.10.12\n- cudatoolkit=11.2\n- cudnn=8.1.0\n"))
+	must_be.Nil(err)
+	requirement := cuda.GpuRequirement()
+	wont_be.Nil(requirement)
+	must_be.Equal("11.2", requirement.Cuda)
+	must_be.Equal(2, len(requirement.Packages))
+
+	modern, err := conda.CondaYamlFrom([]byte("dependencies:\n- python=3.10.12\n- cudnn=9.1.0\n- pip:\n  - torch==2.1.0+cu121\n"))
+	must_be.Nil(err)
+	must_be.Equal("12.1", modern.GpuRequirement().Cuda)
+
+	wheels, err := conda.CondaYamlFrom([]byte("dependencies:\n- python=3.10.12\n- pip:\n  - nvidia-cudnn-cu11==8.9.4.25\n"))
+	must_be.Nil(err)
+	must_be.Equal("11.0", wheels.GpuRequirement().Cuda)
+}
+
+func TestCanJudgeGpuCompatibility(t *testing.T) {
+	must_be, wont_be := hamlet.Specifications(t)
+
+	host := conda.ParseNvidiaSmi(nvidiaSummary, nvidiaListing)
+	must_be.Equal(conda.GpuCompatible, (&conda.GpuRequirement{Cuda: "11.8"}).Compatibility(host))
+	must_be.Equal(conda.GpuCompatible, (&conda.GpuRequirement{Cuda: "12.0"}).Compatibility(host))
+	must_be.Equal(conda.GpuMinorMismatch, (&conda.GpuRequirement{Cuda: "12.4"}).Compatibility(host))
+	must_be.Equal(conda.GpuIncompatible, (&conda.GpuRequirement{Cuda: "13.0"}).Compatibility(host))
+	must_be.Equal(conda.GpuMissing, (&conda.GpuRequirement{Cuda: "11.8"}).Compatibility(&conda.GpuHost{}))
+	must_be.True(conda.CudaLess("11.8", "11.10"))
+	wont_be.True(conda.CudaLess("12.0", "11.8"))
+}
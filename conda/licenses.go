@@ -0,0 +1,200 @@
+package conda
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/joshyorko/rcc/common"
+)
+
+const (
+	UnknownLicense   = "UNKNOWN"
+	licenseMaxLength = 80
+)
+
+var (
+	licenseSplitter = regexp.MustCompile(`(?i)\s+(?:or|and|with)\s+|[()/,;|]`)
+	metadataGlobs   = []string{
+		filepath.Join("lib", "python*", "site-packages", "*.dist-info", "METADATA"),
+		filepath.Join("Lib", "site-packages", "*.dist-info", "METADATA"),
+	}
+)
+
+type PackageLicense struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Origin  string `json:"origin"`
+	License string `json:"license"`
+}
+
+type condaMeta struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	License string `json:"license"`
+}
+
+func cleanLicense(license string) string {
+	license = strings.TrimSpace(license)
+	if len(license) == 0 || len(license) > licenseMaxLength || strings.Contains(license, "\n") {
+		return UnknownLicense
+	}
+	if strings.EqualFold(license, "unknown") || strings.EqualFold(license, "none") {
+		return UnknownLicense
+	}
+	return license
+}
+
+func condaLicenses(targetFolder string) []*PackageLicense {
+	result := []*PackageLicense{}
+	metafiles, _ := filepath.Glob(filepath.Join(targetFolder, "conda-meta", "*.json"))
+	for _, metafile := range metafiles {
+		body, err := os.ReadFile(metafile)
+		if err != nil {
+			common.Debug("Could not read %q, reason: %v", metafile, err)
+			continue
+		}
+		meta := &condaMeta{}
+		err = json.Unmarshal(body, meta)
+		if err != nil || len(meta.Name) == 0 {
+			continue
+		}
+		result = append(result, &PackageLicense{
+			Name:    meta.Name,
+			Version: meta.Version,
+			Origin:  "mamba",
+			License: cleanLicense(meta.License),
+		})
+	}
+	return result
+}
+
+func pypiLicense(metafile string) (*PackageLicense, error) {
+	handle, err := os.Open(metafile)
+	if err != nil {
+		return nil, err
+	}
+	defer handle.Close()
+	result := &PackageLicense{Origin: "pypi"}
+	expression, license, classifiers := "", "", []string{}
+	scanner := bufio.NewScanner(handle)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(strings.TrimSpace(line)) == 0 {
+			break
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(key) {
+		case "name":
+			result.Name = value
+		case "version":
+			result.Version = value
+		case "license-expression":
+			expression = value
+		case "license":
+			license = value
+		case "classifier":
+			if strings.HasPrefix(value, "License ::") {
+				parts := strings.Split(value, "::")
+				classifiers = append(classifiers, strings.TrimSpace(parts[len(parts)-1]))
+			}
+		}
+	}
+	switch {
+	case len(expression) > 0:
+		result.License = cleanLicense(expression)
+	case cleanLicense(license) != UnknownLicense:
+		result.License = cleanLicense(license)
+	default:
+		result.License = cleanLicense(strings.Join(classifiers, " OR "))
+	}
+	return result, scanner.Err()
+}
+
+func normalizedName(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "-", ".", "-").Replace(name))
+}
+
+// EnvironmentLicenses resolves licenses of packages installed in environment,
+// using conda-meta and python dist-info metadata inside that environment.
+func EnvironmentLicenses(targetFolder string) []*PackageLicense {
+	seen := make(map[string]bool)
+	result := []*PackageLicense{}
+	for _, entry := range condaLicenses(targetFolder) {
+		seen[normalizedName(entry.Name)] = true
+		result = append(result, entry)
+	}
+	for _, pattern := range metadataGlobs {
+		metafiles, _ := filepath.Glob(filepath.Join(targetFolder, pattern))
+		for _, metafile := range metafiles {
+			entry, err := pypiLicense(metafile)
+			if err != nil {
+				common.Debug("Could not read %q, reason: %v", metafile, err)
+				continue
+			}
+			key := normalizedName(entry.Name)
+			if len(key) == 0 || seen[key] {
+				continue
+			}
+			seen[key] = true
+			result = append(result, entry)
+		}
+	}
+	sort.SliceStable(result, func(left, right int) bool {
+		return normalizedName(result[left].Name) < normalizedName(result[right].Name)
+	})
+	return result
+}
+
+// GroupedLicenses maps each license to packages using it.
+func GroupedLicenses(packages []*PackageLicense) map[string][]*PackageLicense {
+	result := make(map[string][]*PackageLicense)
+	for _, entry := range packages {
+		result[entry.License] = append(result[entry.License], entry)
+	}
+	return result
+}
+
+func licenseDenied(license string, denied []string) (string, bool) {
+	candidates := append([]string{license}, licenseSplitter.Split(license, -1)...)
+	for _, pattern := range denied {
+		lowered := strings.ToLower(strings.TrimSpace(pattern))
+		if len(lowered) == 0 {
+			continue
+		}
+		for _, candidate := range candidates {
+			candidate = strings.ToLower(strings.TrimSpace(candidate))
+			if len(candidate) == 0 {
+				continue
+			}
+			matched, err := path.Match(lowered, candidate)
+			if err == nil && matched {
+				return pattern, true
+			}
+		}
+	}
+	return "", false
+}
+
+// LicenseViolations returns packages which have license (or part of license
+// expression) matching any of denied license patterns (case insensitive,
+// shell style wildcards allowed, like "GPL-3.0*" or "AGPL*").
+func LicenseViolations(packages []*PackageLicense, denied []string) map[*PackageLicense]string {
+	result := make(map[*PackageLicense]string)
+	for _, entry := range packages {
+		pattern, ok := licenseDenied(entry.License, denied)
+		if ok {
+			result[entry] = pattern
+		}
+	}
+	return result
+}
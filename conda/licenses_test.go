@@ -0,0 +1,68 @@
+package conda
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func writeLicenseFixture(t *testing.T, folder, relative, content string) {
+	fullpath := filepath.Join(folder, relative)
+	if err := os.MkdirAll(filepath.Dir(fullpath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fullpath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEnvironmentLicensesAreResolvedFromMetadata(t *testing.T) {
+	must_be, wont_be := hamlet.Specifications(t)
+
+	folder := t.TempDir()
+	writeLicenseFixture(t, folder, "conda-meta/python-3.12.1-h0.json", `{"name": "python", "version": "3.12.1", "license": "Python-2.0"}`)
+	writeLicenseFixture(t, folder, "conda-meta/readline-8.2-h1.json", `{"name": "readline", "version": "8.2", "license": "GPL-3.0-only"}`)
+	writeLicenseFixture(t, folder, "lib/python3.12/site-packages/requests-2.31.0.dist-info/METADATA", "Metadata-Version: 2.1\nName: requests\nVersion: 2.31.0\nLicense: Apache 2.0\n\nLicense: not this one\n")
+	writeLicenseFixture(t, folder, "lib/python3.12/site-packages/robocorp_tasks-3.0.dist-info/METADATA", "Metadata-Version: 2.4\nName: robocorp-tasks\nVersion: 3.0\nLicense-Expression: Apache-2.0\n")
+	writeLicenseFixture(t, folder, "lib/python3.12/site-packages/shady-1.0.dist-info/METADATA", "Metadata-Version: 2.1\nName: shady\nVersion: 1.0\nLicense: UNKNOWN\nClassifier: License :: OSI Approved :: GNU Affero General Public License v3\n")
+	writeLicenseFixture(t, folder, "lib/python3.12/site-packages/mystery-0.1.dist-info/METADATA", "Metadata-Version: 2.1\nName: mystery\nVersion: 0.1\n")
+	writeLicenseFixture(t, folder, "lib/python3.12/site-packages/Python-3.12.dist-info/METADATA", "Name: Python\nVersion: 3.12\nLicense: duplicate\n")
+
+	packages := EnvironmentLicenses(folder)
+	must_be.Equal(6, len(packages))
+	byName := make(map[string]*PackageLicense)
+	for _, entry := range packages {
+		byName[entry.Name] = entry
+	}
+	must_be.Equal("Python-2.0", byName["python"].License)
+	must_be.Equal("mamba", byName["python"].Origin)
+	must_be.Equal("Apache 2.0", byName["requests"].License)
+	must_be.Equal("pypi", byName["requests"].Origin)
+	must_be.Equal("Apache-2.0", byName["robocorp-tasks"].License)
+	must_be.Equal("GNU Affero General Public License v3", byName["shady"].License)
+	must_be.Equal(UnknownLicense, byName["mystery"].License)
+
+	grouped := GroupedLicenses(packages)
+	must_be.Equal(1, len(grouped["Apache-2.0"]))
+
+	violations := LicenseViolations(packages, []string{"gpl-3.0*", "*affero*"})
+	must_be.Equal(2, len(violations))
+	must_be.Equal("gpl-3.0*", violations[byName["readline"]])
+	must_be.Equal("*affero*", violations[byName["shady"]])
+	wont_be.Equal("", violations[byName["readline"]])
+	must_be.Equal(0, len(LicenseViolations(packages, []string{})))
+}
+
+func TestLicenseExpressionPartsAreMatched(t *testing.T) {
+	must_be, wont_be := hamlet.Specifications(t)
+
+	pattern, ok := licenseDenied("MIT OR GPL-3.0-or-later", []string{"GPL-3.0*"})
+	must_be.True(ok)
+	must_be.Equal("GPL-3.0*", pattern)
+	_, ok = licenseDenied("LGPL-2.1", []string{"GPL*"})
+	wont_be.True(ok)
+	_, ok = licenseDenied("(Apache-2.0 AND BSD-3-Clause)", []string{"bsd-3-clause"})
+	must_be.True(ok)
+}
@@ -0,0 +1,154 @@
+package conda
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/fail"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/settings"
+)
+
+var (
+	localPackagePattern = regexp.MustCompile("^([^#/\\\\]+)#sha256=([0-9a-f]{64})$")
+	localPackageSources = make(map[string]string)
+	localPackageLock    sync.Mutex
+)
+
+func localPackageDigest(filename string) (string, error) {
+	source, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer source.Close()
+	digest := sha256.New()
+	_, err = io.Copy(digest, source)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%02x", digest.Sum(nil)), nil
+}
+
+func LocalPackageFile(entry string) (string, bool) {
+	parts := localPackagePattern.FindStringSubmatch(entry)
+	if parts == nil {
+		return "", false
+	}
+	return filepath.Join(common.LocalPackagesLocation(), parts[2], parts[1]), true
+}
+
+func IsCondaPackageFile(filename string) bool {
+	lowered := strings.ToLower(filename)
+	return strings.HasSuffix(lowered, ".conda") || strings.HasSuffix(lowered, ".tar.bz2")
+}
+
+// resolveLocalPackage digests local package file, and remembers where it
+// was found, so that it can be stored later, when environment gets built.
+// Reading configuration files has no other side effects.
+func resolveLocalPackage(basedir, entry string) (result string, err error) {
+	defer fail.Around(&err)
+
+	fullpath := entry
+	if !filepath.IsAbs(fullpath) {
+		fullpath = filepath.Join(basedir, entry)
+	}
+	fail.On(!pathlib.IsFile(fullpath), "Local package %q does not exist.", fullpath)
+	digest, err := localPackageDigest(fullpath)
+	fail.On(err != nil, "Could not digest local package %q, reason: %v", fullpath, err)
+	result = fmt.Sprintf("%s#sha256=%s", filepath.Base(fullpath), digest)
+	localPackageLock.Lock()
+	localPackageSources[result] = fullpath
+	localPackageLock.Unlock()
+	common.Debug("Local package %q resolved as %q.", fullpath, result)
+	return result, nil
+}
+
+func (it *Environment) resolveLocalPackages(basedir string) error {
+	for at, entry := range it.LocalPackages {
+		if localPackagePattern.MatchString(entry) {
+			continue
+		}
+		resolved, err := resolveLocalPackage(basedir, entry)
+		if err != nil {
+			return err
+		}
+		it.LocalPackages[at] = resolved
+	}
+	return nil
+}
+
+// AddLocalPackages resolves local package files (like ones declared in
+// robot.yaml) and adds them to environment, unless already there.
+func (it *Environment) AddLocalPackages(filenames []string) error {
+	for _, filename := range filenames {
+		resolved, err := resolveLocalPackage("", filename)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(it.LocalPackages, resolved) {
+			it.LocalPackages = append(it.LocalPackages, resolved)
+		}
+	}
+	return nil
+}
+
+// StoreLocalPackages copies local packages resolved while reading environment
+// configurations into shared local package storage. This is done when
+// environment is built, and packages already stored are not copied again.
+func StoreLocalPackages(localPackages []string) (err error) {
+	defer fail.Around(&err)
+
+	for _, entry := range localPackages {
+		target, ok := LocalPackageFile(entry)
+		if !ok || pathlib.IsFile(target) {
+			continue
+		}
+		localPackageLock.Lock()
+		source, ok := localPackageSources[entry]
+		localPackageLock.Unlock()
+		fail.On(!ok, "Local package %q is not available in %q.", entry, common.LocalPackagesLocation())
+		digest, err := localPackageDigest(source)
+		fail.On(err != nil, "Could not digest local package %q, reason: %v", source, err)
+		fail.On(!strings.HasSuffix(entry, "#sha256="+digest), "Local package %q has changed after it was resolved as %q.", source, entry)
+		err = pathlib.CopyFile(source, target, false)
+		fail.On(err != nil, "Could not store local package %q, reason: %v", source, err)
+		common.Debug("Local package %q stored as %q.", source, entry)
+	}
+	return nil
+}
+
+func localPackageCommands(localPackages []string, python, targetFolder string) [][]string {
+	wheels, condas := []string{}, []string{}
+	for _, entry := range localPackages {
+		fullpath, ok := LocalPackageFile(entry)
+		if !ok {
+			continue
+		}
+		if IsCondaPackageFile(fullpath) {
+			link := url.URL{Scheme: "file", Path: filepath.ToSlash(fullpath)}
+			condas = append(condas, link.String())
+		} else {
+			wheels = append(wheels, fullpath)
+		}
+	}
+	result := [][]string{}
+	if len(condas) > 0 {
+		mambaCommand := common.NewCommander(BinMicromamba(), "install", "--always-copy", "--offline", "-y", "-p", targetFolder)
+		mambaCommand.ConditionalFlag(!settings.Global.HasMicroMambaRc(), "--no-rc")
+		result = append(result, append(mambaCommand.CLI(), condas...))
+	}
+	if len(wheels) > 0 {
+		pipCommand := common.NewCommander(python, "-m", "pip", "install", "--isolated", "--no-color", "--disable-pip-version-check", "--no-deps", "--no-index")
+		result = append(result, append(pipCommand.CLI(), wheels...))
+	}
+	return result
+}
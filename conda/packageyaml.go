@@ -90,6 +90,14 @@ func packageYamlFrom(content []byte, devDependencies bool) (*Environment, error)
 }
 
 func ReadPackageCondaYamlFromContents(content []byte, filename string, devDependencies bool) (*Environment, error) {
+	return readPackageCondaYamlFromContents(content, filename, devDependencies, []string{})
+}
+
+func ReadPackageCondaYaml(filename string, devDependencies bool) (*Environment, error) {
+	return readPackageCondaYaml(filename, devDependencies, []string{})
+}
+
+func readPackageCondaYamlFromContents(content []byte, filename string, devDependencies bool, chain []string) (*Environment, error) {
 	basename := strings.ToLower(filepath.Base(filename))
 	if basename == "package.yaml" {
 		return packageYamlFrom(content, devDependencies)
@@ -98,10 +106,18 @@ func ReadPackageCondaYamlFromContents(content []byte, filename string, devDepend
 		// error: only valid when dealing with a `package.yaml` file
 		return nil, fmt.Errorf("'--devdeps' flag is only valid when dealing with a `package.yaml` file. Current file: %q", filename)
 	}
-	return CondaYamlFrom(content)
+	environment, err := CondaYamlFrom(content)
+	if err != nil {
+		return nil, err
+	}
+	err = environment.resolveLocalPackages(filepath.Dir(filename))
+	if err != nil {
+		return nil, fmt.Errorf("%q: %w", filename, err)
+	}
+	return environment.resolveExtends(filename, chain)
 }
 
-func ReadPackageCondaYaml(filename string, devDependencies bool) (*Environment, error) {
+func readPackageCondaYaml(filename string, devDependencies bool, chain []string) (*Environment, error) {
 	var content []byte
 	var err error
 
@@ -114,5 +130,5 @@ func ReadPackageCondaYaml(filename string, devDependencies bool) (*Environment,
 		return nil, fmt.Errorf("%q: %w", filename, err)
 	}
 
-	return ReadPackageCondaYamlFromContents(content, filename, devDependencies)
+	return readPackageCondaYamlFromContents(content, filename, devDependencies, chain)
 }
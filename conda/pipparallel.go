@@ -0,0 +1,220 @@
+package conda
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/settings"
+)
+
+const (
+	parallelPipMaxWorkers = 4
+)
+
+var (
+	requirementName = regexp.MustCompile(`^\s*([A-Za-z0-9][A-Za-z0-9._-]*)`)
+	extraMarker     = regexp.MustCompile(`\bextra\s*==`)
+)
+
+type pipReport struct {
+	Install []*pipReportEntry `json:"install"`
+}
+
+type pipReportEntry struct {
+	Metadata struct {
+		Name         string   `json:"name"`
+		Version      string   `json:"version"`
+		RequiresDist []string `json:"requires_dist"`
+	} `json:"metadata"`
+}
+
+func (it *pipReportEntry) requirement() string {
+	return fmt.Sprintf("%s==%s", it.Metadata.Name, it.Metadata.Version)
+}
+
+// dependencies returns normalized names of unconditional and environment
+// marker dependencies; dependencies of extras are resolved into report as
+// separate entries, so they are ignored here.
+func (it *pipReportEntry) dependencies() []string {
+	result := make([]string, 0, len(it.Metadata.RequiresDist))
+	for _, requirement := range it.Metadata.RequiresDist {
+		_, marker, _ := strings.Cut(requirement, ";")
+		if extraMarker.MatchString(marker) {
+			continue
+		}
+		found := requirementName.FindStringSubmatch(requirement)
+		if found == nil {
+			continue
+		}
+		result = append(result, normalizedName(found[1]))
+	}
+	return result
+}
+
+func parsePipReport(content []byte) (*pipReport, error) {
+	report := &pipReport{}
+	err := json.Unmarshal(content, report)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range report.Install {
+		if len(entry.Metadata.Name) == 0 || len(entry.Metadata.Version) == 0 {
+			return nil, fmt.Errorf("Pip report has entry without name or version.")
+		}
+	}
+	return report, nil
+}
+
+type unionFind map[string]string
+
+func (it unionFind) root(name string) string {
+	parent, ok := it[name]
+	if !ok || parent == name {
+		it[name] = name
+		return name
+	}
+	root := it.root(parent)
+	it[name] = root
+	return root
+}
+
+func (it unionFind) join(left, right string) {
+	first, second := it.root(left), it.root(right)
+	if first != second {
+		it[second] = first
+	}
+}
+
+// partitions splits resolved packages into independent subtrees, where no
+// package depends on package from another partition. Largest partitions are
+// first, so that they get started first.
+func (it *pipReport) partitions() [][]string {
+	packages := make(map[string]*pipReportEntry)
+	for _, entry := range it.Install {
+		packages[normalizedName(entry.Metadata.Name)] = entry
+	}
+	groups := make(unionFind)
+	for name, entry := range packages {
+		groups.root(name)
+		for _, dependency := range entry.dependencies() {
+			if _, ok := packages[dependency]; ok {
+				groups.join(name, dependency)
+			}
+		}
+	}
+	members := make(map[string][]string)
+	for name, entry := range packages {
+		root := groups.root(name)
+		members[root] = append(members[root], entry.requirement())
+	}
+	result := make([][]string, 0, len(members))
+	for _, requirements := range members {
+		sort.Strings(requirements)
+		result = append(result, requirements)
+	}
+	sort.SliceStable(result, func(left, right int) bool {
+		if len(result[left]) != len(result[right]) {
+			return len(result[left]) > len(result[right])
+		}
+		return result[left][0] < result[right][0]
+	})
+	return result
+}
+
+func pipInstallCommand(python string, arguments ...string) *common.Commander {
+	pipCommand := common.NewCommander(python, "-m", "pip", "install", "--isolated", "--no-color", "--disable-pip-version-check", "--prefer-binary", "--cache-dir", common.PipCache(), "--find-links", common.WheelCache())
+	pipCommand.ConditionalFlag(true, arguments...)
+	pipCommand.Option("--index-url", settings.Global.PypiURL())
+	pipCommand.Option("--trusted-host", settings.Global.PypiTrustedHost())
+	pipCommand.ConditionalFlag(common.VerboseEnvironmentBuilding(), "--verbose")
+	return pipCommand
+}
+
+func resolvePipPartitions(python, requirementsText, targetFolder string, planWriter io.Writer) ([][]string, error) {
+	reportfile := filepath.Join(common.ProductTemp(), fmt.Sprintf("pipreport_%s.json", <-common.Identities))
+	defer os.Remove(reportfile)
+	resolveCommand := pipInstallCommand(python, "--dry-run", "--quiet", "--report", reportfile, "--requirement", requirementsText)
+	code, err := LiveExecution(planWriter, targetFolder, resolveCommand.CLI()...)
+	if err != nil || code != 0 {
+		return nil, fmt.Errorf("pip resolve failed with code %d, reason: %v", code, err)
+	}
+	content, err := os.ReadFile(reportfile)
+	if err != nil {
+		return nil, err
+	}
+	report, err := parsePipReport(content)
+	if err != nil {
+		return nil, err
+	}
+	return report.partitions(), nil
+}
+
+func parallelPipWorkers(partitions int) int {
+	workers := min(runtime.NumCPU(), parallelPipMaxWorkers, partitions)
+	return max(workers, 1)
+}
+
+// parallelPipInstall resolves full dependency set first, and then installs
+// independent subtrees concurrently without further dependency resolution.
+// Returning false means that caller should do normal serial pip install.
+func parallelPipInstall(python, requirementsText, targetFolder string, planWriter io.Writer) bool {
+	common.TimelineBegin("parallel pip install")
+	defer common.TimelineEnd()
+
+	partitions, err := resolvePipPartitions(python, requirementsText, targetFolder, planWriter)
+	if err != nil {
+		common.Debug("Parallel pip resolve failed, reason: %v", err)
+		return false
+	}
+	if len(partitions) < 2 {
+		common.Debug("Parallel pip install skipped, only %d dependency partitions.", len(partitions))
+		return false
+	}
+	workers := parallelPipWorkers(len(partitions))
+	common.Debug("Parallel pip install of %d partitions using %d workers.", len(partitions), workers)
+	fmt.Fprintf(planWriter, "Parallel pip install of %d partitions using %d workers.\n", len(partitions), workers)
+
+	var lock sync.Mutex
+	var group sync.WaitGroup
+	failures := 0
+	queue := make(chan []string, len(partitions))
+	for _, partition := range partitions {
+		queue <- partition
+	}
+	close(queue)
+	for range workers {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			for partition := range queue {
+				output := &bytes.Buffer{}
+				arguments := append([]string{"--no-deps"}, partition...)
+				code, err := LiveExecution(output, targetFolder, pipInstallCommand(python, arguments...).CLI()...)
+				lock.Lock()
+				planWriter.Write(output.Bytes())
+				if err != nil || code != 0 {
+					failures += 1
+					common.Debug("Parallel pip partition %q failed with code %d, reason: %v", partition, code, err)
+				}
+				lock.Unlock()
+			}
+		}()
+	}
+	group.Wait()
+	if failures > 0 {
+		common.Timeline("parallel pip failed on %d partitions.", failures)
+		fmt.Fprintf(planWriter, "Parallel pip install failed on %d partitions, falling back to serial install.\n", failures)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,46 @@
+package conda
+
+import (
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+const pipReportFixture = `{
+  "version": "1",
+  "install": [
+    {"metadata": {"name": "requests", "version": "2.31.0", "requires_dist": ["charset-normalizer<4,>=2", "idna<4,>=2.5", "urllib3<3,>=1.21.1", "certifi>=2017.4.17", "PySocks!=1.5.7,>=1.5.6; extra == \"socks\""]}},
+    {"metadata": {"name": "certifi", "version": "2024.2.2"}},
+    {"metadata": {"name": "idna", "version": "3.6"}},
+    {"metadata": {"name": "urllib3", "version": "2.2.1", "requires_dist": ["brotli>=1.0.9; platform_python_implementation == \"CPython\" and extra == \"brotli\""]}},
+    {"metadata": {"name": "charset_normalizer", "version": "3.3.2"}},
+    {"metadata": {"name": "PyYAML", "version": "6.0.1"}},
+    {"metadata": {"name": "robocorp-tasks", "version": "3.0.0", "requires_dist": ["psutil<6,>=5 ; sys_platform != \"win32\"", "robocorp.log>=2"]}},
+    {"metadata": {"name": "psutil", "version": "5.9.8"}},
+    {"metadata": {"name": "robocorp-log", "version": "2.8.1"}},
+    {"metadata": {"name": "pysocks", "version": "1.7.1"}}
+  ]
+}`
+
+func TestPipReportIsPartitionedIntoIndependentSubtrees(t *testing.T) {
+	must_be, wont_be := hamlet.Specifications(t)
+
+	report, err := parsePipReport([]byte(pipReportFixture))
+	must_be.Nil(err)
+	must_be.Equal(10, len(report.Install))
+
+	partitions := report.partitions()
+	must_be.Equal(4, len(partitions))
+	must_be.Equal([]string{"certifi==2024.2.2", "charset_normalizer==3.3.2", "idna==3.6", "requests==2.31.0", "urllib3==2.2.1"}, partitions[0])
+	must_be.Equal([]string{"psutil==5.9.8", "robocorp-log==2.8.1", "robocorp-tasks==3.0.0"}, partitions[1])
+	must_be.Equal([]string{"PyYAML==6.0.1"}, partitions[2])
+	must_be.Equal([]string{"pysocks==1.7.1"}, partitions[3])
+
+	must_be.Equal(1, parallelPipWorkers(1))
+	wont_be.True(parallelPipWorkers(100) > parallelPipMaxWorkers)
+
+	_, err = parsePipReport([]byte(`{"install": [{"metadata": {"name": "broken"}}]}`))
+	wont_be.Nil(err)
+	_, err = parsePipReport([]byte(`not json`))
+	wont_be.Nil(err)
+}
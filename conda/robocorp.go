@@ -187,6 +187,9 @@ func CondaExecutionEnvironment(location string, inject []string, full bool) []st
 	} else {
 		common.Timeline("temp directory management was disabled.")
 	}
+	if common.BrowserCacheEnabled() {
+		environment = append(environment, common.PLAYWRIGHT_BROWSERS_PATH+"="+common.BrowsersLocation())
+	}
 	if pathlib.IsFile(filepath.Join(location, "pyvenv.cfg")) {
 		environment = append(environment, "VIRTUAL_ENV="+location)
 	} else {
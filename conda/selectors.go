@@ -0,0 +1,106 @@
+package conda
+
+import (
+	"fmt"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"github.com/joshyorko/rcc/pretty"
+)
+
+var (
+	selectorPattern = regexp.MustCompile(`\s+#\s*\[([^\]]*)\]\s*$`)
+)
+
+// platformSelectors maps selector names (as used in conda-build recipes) to
+// their values on given operating system and architecture.
+func platformSelectors(goos, goarch string) map[string]bool {
+	return map[string]bool{
+		"win":     goos == "windows",
+		"linux":   goos == "linux",
+		"osx":     goos == "darwin",
+		"mac":     goos == "darwin",
+		"unix":    goos != "windows",
+		"x86_64":  goarch == "amd64",
+		"amd64":   goarch == "amd64",
+		"arm64":   goarch == "arm64",
+		"aarch64": goarch == "arm64",
+	}
+}
+
+// evaluateSelector evaluates selector expression like "win", "not win",
+// "linux or osx", or "osx and arm64"; "and" binds tighter than "or".
+func evaluateSelector(expression string, selectors map[string]bool) (bool, error) {
+	fields := strings.Fields(expression)
+	if len(fields) == 0 {
+		return false, fmt.Errorf("empty platform selector")
+	}
+	result, term, negate, expectName := false, true, false, true
+	for _, field := range fields {
+		switch {
+		case !expectName && field == "or":
+			result, term, expectName = result || term, true, true
+		case !expectName && field == "and":
+			expectName = true
+		case expectName && field == "not":
+			negate = !negate
+		case expectName:
+			value, ok := selectors[field]
+			if !ok {
+				return false, fmt.Errorf("unknown platform selector %q in [%s]", field, expression)
+			}
+			term, negate, expectName = term && (value != negate), false, false
+		default:
+			return false, fmt.Errorf("invalid platform selector [%s]", expression)
+		}
+	}
+	if expectName {
+		return false, fmt.Errorf("incomplete platform selector [%s]", expression)
+	}
+	return result || term, nil
+}
+
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+// resolveSelectors removes lines whose "# [selector]" comment does not match
+// given platform, together with their nested (more indented) lines, so that
+// one conda.yaml can describe Windows, macOS, and Linux environments. Since
+// blueprints are computed from parsed environment, they only contain the
+// effective, platform-resolved dependencies. Bracketed comments that are not
+// known selectors (like "py3k") are plain comments, and their lines are kept.
+func resolveSelectors(content []byte, goos, goarch string) []byte {
+	selectors := platformSelectors(goos, goarch)
+	lines := strings.SplitAfter(string(content), "\n")
+	result := make([]string, 0, len(lines))
+	dropping, depth := false, 0
+	for number, line := range lines {
+		if dropping {
+			if len(strings.TrimSpace(line)) == 0 || indentation(line) > depth {
+				continue
+			}
+			dropping = false
+		}
+		found := selectorPattern.FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+		if found == nil {
+			result = append(result, line)
+			continue
+		}
+		keep, err := evaluateSelector(found[1], selectors)
+		if err != nil {
+			pretty.Warning("Line %d: %v, so it is kept as plain comment.", number+1, err)
+		}
+		if keep || err != nil {
+			result = append(result, line)
+			continue
+		}
+		dropping, depth = true, indentation(line)
+	}
+	return []byte(strings.Join(result, ""))
+}
+
+func currentPlatformOnly(content []byte) []byte {
+	return resolveSelectors(content, runtime.GOOS, runtime.GOARCH)
+}
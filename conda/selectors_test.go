@@ -0,0 +1,100 @@
+package conda
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+
+	"gopkg.in/yaml.v2"
+)
+
+const selectorsFixture = `channels:
+  - conda-forge
+dependencies:
+  - python=3.10.12
+  - pywin32=306  # [win]
+  - libgcc-ng=13.2.0  # [linux]
+  - tk=8.6.13  # [not win]
+  - pyobjc-core=10.1  # [osx and arm64]
+  - pip=23.2.1
+  - pip:  # [linux or osx]
+      - rpaframework-posix==1.0.0
+      - psutil==5.9.8
+  - pip:
+      - robocorp==1.4.0
+      - comtypes==1.2.0  # [win]
+`
+
+func dependencyNames(environment *Environment) []string {
+	result := []string{}
+	for _, dependency := range environment.Conda {
+		result = append(result, dependency.Name)
+	}
+	for _, dependency := range environment.Pip {
+		result = append(result, "pip:"+dependency.Name)
+	}
+	return result
+}
+
+func resolvedFixture(t *testing.T, goos, goarch string) string {
+	content := resolveSelectors([]byte(selectorsFixture), goos, goarch)
+	result := new(internalEnvironment)
+	err := yaml.Unmarshal(content, result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Join(dependencyNames(result.AsEnvironment()), " ")
+}
+
+func TestCanEvaluatePlatformSelectors(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	windows := platformSelectors("windows", "amd64")
+	macos := platformSelectors("darwin", "arm64")
+
+	for _, expression := range []string{"win", "not osx", "unix or win", "win and x86_64", "not not win"} {
+		ok, err := evaluateSelector(expression, windows)
+		must.Nil(err)
+		must.True(ok)
+	}
+	for _, expression := range []string{"osx", "unix", "linux or osx", "win and arm64"} {
+		ok, err := evaluateSelector(expression, windows)
+		must.Nil(err)
+		wont.True(ok)
+	}
+	ok, err := evaluateSelector("win or osx and arm64", macos)
+	must.Nil(err)
+	must.True(ok)
+
+	for _, expression := range []string{"", "solaris", "win or", "not", "win linux", "and win"} {
+		_, err := evaluateSelector(expression, windows)
+		wont.Nil(err)
+	}
+}
+
+func TestCanResolvePlatformSpecificDependencies(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	must.Equal("python pywin32 pip pip:robocorp pip:comtypes", resolvedFixture(t, "windows", "amd64"))
+	must.Equal("python libgcc-ng tk pip pip:rpaframework-posix pip:psutil pip:robocorp", resolvedFixture(t, "linux", "amd64"))
+	must.Equal("python tk pyobjc-core pip pip:rpaframework-posix pip:psutil pip:robocorp", resolvedFixture(t, "darwin", "arm64"))
+	must.Equal("python tk pip pip:rpaframework-posix pip:psutil pip:robocorp", resolvedFixture(t, "darwin", "amd64"))
+
+	plain := []byte("dependencies:\n  - python=3.10.12 # plain comment\n  - pip=23.2.1\n")
+	content := resolveSelectors(plain, "windows", "amd64")
+	must.Equal(string(plain), string(content))
+}
+
+func TestUnknownSelectorsAreKeptAsPlainComments(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	bracketed := "dependencies:\n  - python=3.10.12  # [py3k]\n  - pip=23.2.1  # [pinned, see issue 12]\n  - tk=8.6.13  # [not win]\n"
+	content := resolveSelectors([]byte(bracketed), "windows", "amd64")
+	must.Equal("dependencies:\n  - python=3.10.12  # [py3k]\n  - pip=23.2.1  # [pinned, see issue 12]\n", string(content))
+
+	environment, err := CondaYamlFrom(content)
+	must.Nil(err)
+	wont.Nil(environment)
+	must.Equal(2, len(environment.Conda))
+}
@@ -46,6 +46,11 @@ func doFailUv(version string) bool {
 func downloadUv(version string, delay time.Duration) bool {
 	time.Sleep(delay)
 
+	if settings.Global.Offline() {
+		pretty.Warning("%v", settings.OfflineError(fmt.Sprintf("download uv binary version %s", version)))
+		return false
+	}
+
 	pretty.Highlight("Downloading uv binary version %s...", version)
 
 	target := uvPlatformTarget()
@@ -322,7 +322,7 @@ func uvNativeHolotreeLayers(requirementsText string, finalEnv *Environment, targ
 	defer common.TimelineEnd()
 
 	pipNeeded := len(requirementsText) > 0
-	postInstall := len(finalEnv.PostInstall) > 0
+	postInstall := len(finalEnv.PostInstall) > 0 || len(finalEnv.LocalPackages) > 0
 
 	layers := finalEnv.AsLayers()
 	fingerprints := finalEnv.FingerprintLayers()
@@ -361,7 +361,7 @@ func uvNativeHolotreeLayers(requirementsText string, finalEnv *Environment, targ
 		fmt.Fprintf(planWriter, "\n---  pip plan skipped, layer exists  ---\n\n")
 	}
 	if skip < SkipPostinstallLayer {
-		success, fatal = postInstallLayer(fingerprints[2], finalEnv.PostInstall, targetFolder, stopwatch, planWriter)
+		success, fatal = postInstallLayer(fingerprints[2], finalEnv.PostInstall, finalEnv.LocalPackages, targetFolder, stopwatch, planWriter)
 		if !success {
 			return success, fatal, pipUsed, python
 		}
@@ -147,7 +147,7 @@ func newLive(yaml, condaYaml, requirementsText, key string, force, freshInstall
 	common.Debug("===  first try phase ===")
 	common.Timeline("first try.")
 	success, fatal := newLiveInternal(yaml, condaYaml, requirementsText, key, force, freshInstall, skip, finalEnv, recorder)
-	if !success && !force && !fatal && !common.NoRetryBuild {
+	if !success && !force && !fatal && !common.NoRetryBuild && !shell.Cancelled() {
 		journal.CurrentBuildEvent().Rebuild()
 		cloud.InternalBackgroundMetric(common.ControllerIdentity(), "rcc.env.creation.retry", common.Version)
 		common.Debug("===  second try phase ===")
@@ -190,7 +190,7 @@ func newLiveUvNative(yaml, requirementsText, key string, force, freshInstall boo
 	common.Debug("===  first try phase (uv-native) ===")
 	common.Timeline("first try (uv-native).")
 	success, fatal := newLiveUvNativeInternal(yaml, requirementsText, key, force, freshInstall, skip, finalEnv, recorder, uvBinary, pythonVersion)
-	if !success && !force && !fatal && !common.NoRetryBuild {
+	if !success && !force && !fatal && !common.NoRetryBuild && !shell.Cancelled() {
 		journal.CurrentBuildEvent().Rebuild()
 		cloud.InternalBackgroundMetric(common.ControllerIdentity(), "rcc.env.creation.retry.uvnative", common.Version)
 		common.Debug("===  second try phase (uv-native) ===")
@@ -387,6 +387,11 @@ func pipLayer(fingerprint, requirementsText, targetFolder string, stopwatch fmt.
 		}
 		pretty.Progress(8, "Running pip install phase. (pip v%s) [layer: %s]", PipVersion(python), fingerprint)
 		common.Debug("Updating new environment at %v with pip requirements from %v (size: %v)", targetFolder, requirementsText, size)
+		if common.RccParallelPip() && parallelPipInstall(python, requirementsText, targetFolder, planWriter) {
+			journal.CurrentBuildEvent().PipComplete()
+			common.Timeline("pip done (parallel).")
+			return true, false, true, python
+		}
 		pipCommand := common.NewCommander(python, "-m", "pip", "install", "--isolated", "--no-color", "--disable-pip-version-check", "--prefer-binary", "--cache-dir", pipCache, "--find-links", wheelCache, "--requirement", requirementsText)
 		pipCommand.Option("--index-url", settings.Global.PypiURL())
 		pipCommand.Option("--trusted-host", settings.Global.PypiTrustedHost())
@@ -407,12 +412,31 @@ func pipLayer(fingerprint, requirementsText, targetFolder string, stopwatch fmt.
 	return true, false, pipUsed, python
 }
 
-func postInstallLayer(fingerprint string, postInstall []string, targetFolder string, stopwatch fmt.Stringer, planWriter io.Writer) (bool, bool) {
+func postInstallLayer(fingerprint string, postInstall, localPackages []string, targetFolder string, stopwatch fmt.Stringer, planWriter io.Writer) (bool, bool) {
 	assertStageFolder(targetFolder)
 	common.TimelineBegin("Layer: post install scripts [%s]", fingerprint)
 	defer common.TimelineEnd()
 
 	fmt.Fprintf(planWriter, "\n---  post install plan @%ss  ---\n\n", stopwatch)
+	if len(localPackages) > 0 {
+		pretty.Progress(9, "Installing %d local packages. [layer: %s]", len(localPackages), fingerprint)
+		err := StoreLocalPackages(localPackages)
+		if err != nil {
+			common.Fatal("Local packages", err)
+			pretty.RccPointOfView(postInstallScripts, err)
+			return false, false
+		}
+		python, _ := FindPython(targetFolder)
+		for _, command := range localPackageCommands(localPackages, python, targetFolder) {
+			common.Debug("Running local package install '%s' ...", command)
+			code, err := LiveExecution(planWriter, targetFolder, command...)
+			if err != nil || code != 0 {
+				common.Fatal(fmt.Sprintf("Local packages [%d/%x]", code, code), err)
+				pretty.RccPointOfView(postInstallScripts, err)
+				return false, false
+			}
+		}
+	}
 	if postInstall != nil && len(postInstall) > 0 {
 		pretty.Progress(9, "Post install scripts phase started. [layer: %s]", fingerprint)
 		common.Debug("===  post install phase ===")
@@ -446,7 +470,7 @@ func holotreeLayers(condaYaml, requirementsText string, finalEnv *Environment, t
 	defer common.TimelineEnd()
 
 	pipNeeded := len(requirementsText) > 0
-	postInstall := len(finalEnv.PostInstall) > 0
+	postInstall := len(finalEnv.PostInstall) > 0 || len(finalEnv.LocalPackages) > 0
 
 	var pypiSelector pipTool = pipLayer
 
@@ -492,7 +516,7 @@ func holotreeLayers(condaYaml, requirementsText string, finalEnv *Environment, t
 		fmt.Fprintf(planWriter, "\n---  pip plan skiped, layer exists  ---\n\n")
 	}
 	if skip < SkipPostinstallLayer {
-		success, fatal = postInstallLayer(fingerprints[2], finalEnv.PostInstall, targetFolder, stopwatch, planWriter)
+		success, fatal = postInstallLayer(fingerprints[2], finalEnv.PostInstall, finalEnv.LocalPackages, targetFolder, stopwatch, planWriter)
 		if !success {
 			return success, fatal, pipUsed, python
 		}
@@ -0,0 +1,121 @@
+package htfs
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/joshyorko/rcc/anywork"
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/journal"
+	"github.com/joshyorko/rcc/pretty"
+)
+
+const (
+	ExclusionYes     = "yes"
+	ExclusionNo      = "no"
+	ExclusionUnknown = "unknown"
+
+	antivirusMinimumFiles   = 200
+	antivirusNormalLatency  = 500 * time.Microsecond
+	antivirusSuspectLatency = 3 * time.Millisecond
+)
+
+// AntivirusFindings is timing of latest larger restore, where average time
+// to write one file tells if antivirus was scanning files while they were
+// restored. Excluded tells if holotree is excluded from scanning, when that
+// can be asked from scanner (Windows Defender).
+type AntivirusFindings struct {
+	When      string  `json:"when"`
+	Space     string  `json:"space"`
+	Files     uint64  `json:"files"`
+	Latency   float64 `json:"latency_ms"`
+	Slowdown  float64 `json:"slowdown_seconds"`
+	Suspected bool    `json:"suspected"`
+	Scanner   string  `json:"scanner,omitempty"`
+	Excluded  string  `json:"excluded"`
+}
+
+// Timed measures how long restoring of one file takes.
+func (it *stats) Timed(work anywork.Work) anywork.Work {
+	return func() {
+		started := time.Now()
+		defer func() {
+			elapsed := time.Since(started)
+			it.Lock()
+			defer it.Unlock()
+			it.written++
+			it.writing += elapsed
+		}()
+		work()
+	}
+}
+
+// antivirusSlowdown gives average latency of file writes, estimated wall
+// clock slowdown (compared to normal latency, with writes done by parallel
+// workers), and if latency suggests that files are scanned while written.
+func antivirusSlowdown(files uint64, writing time.Duration, workers uint64) (time.Duration, float64, bool) {
+	if files == 0 {
+		return 0, 0, false
+	}
+	latency := writing / time.Duration(files)
+	if workers < 1 {
+		workers = 1
+	}
+	extra := max(latency-antivirusNormalLatency, 0)
+	slowdown := (extra * time.Duration(files)).Seconds() / float64(workers)
+	suspected := files >= antivirusMinimumFiles && latency > antivirusSuspectLatency
+	return latency, slowdown, suspected
+}
+
+func (it *stats) reportAntivirus(space string) {
+	latency, slowdown, suspected := antivirusSlowdown(it.written, it.writing, anywork.Scale())
+	if it.written == 0 {
+		return
+	}
+	common.Timeline("- written %d files, %s per file (antivirus slowdown estimate %.1fs)", it.written, latency, slowdown)
+	journal.CurrentBuildEvent().AntivirusSlowdown(slowdown)
+	if it.written < antivirusMinimumFiles {
+		return
+	}
+	findings := &AntivirusFindings{
+		When:      time.Now().Format(time.RFC3339),
+		Space:     space,
+		Files:     it.written,
+		Latency:   float64(latency.Microseconds()) / 1000.0,
+		Slowdown:  slowdown,
+		Suspected: suspected,
+		Excluded:  ExclusionUnknown,
+	}
+	if suspected {
+		findings.Scanner, findings.Excluded = antivirusExclusion(space)
+		if findings.Excluded != ExclusionYes {
+			pretty.Warning("Restoring %d files took %.1fms per file, which suggests that antivirus is scanning %q (estimated slowdown %.1fs). See \"rcc configure diagnostics\" for exclusion advice.", findings.Files, findings.Latency, space, slowdown)
+		}
+	}
+	blob, err := json.MarshalIndent(findings, "", "  ")
+	if err == nil {
+		err = os.WriteFile(common.AntivirusFindingsLocation(), blob, 0o644)
+	}
+	if err != nil {
+		common.Debug("Could not save antivirus findings, reason: %v", err)
+	}
+}
+
+// LoadAntivirusFindings loads timing findings of latest larger restore.
+// Missing findings are not an error, but result is then nil.
+func LoadAntivirusFindings() (*AntivirusFindings, error) {
+	blob, err := os.ReadFile(common.AntivirusFindingsLocation())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	findings := &AntivirusFindings{}
+	err = json.Unmarshal(blob, findings)
+	if err != nil {
+		return nil, err
+	}
+	return findings, nil
+}
@@ -0,0 +1,6 @@
+package htfs
+
+// antivirusExclusion cannot ask scanner about exclusions on this platform.
+func antivirusExclusion(path string) (string, string) {
+	return "", ExclusionUnknown
+}
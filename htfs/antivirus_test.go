@@ -0,0 +1,41 @@
+package htfs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestAntivirusSlowdownIsEstimatedFromWriteLatency(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	latency, slowdown, suspected := antivirusSlowdown(0, time.Second, 4)
+	must.Equal(time.Duration(0), latency)
+	must.Equal(0.0, slowdown)
+	wont.True(suspected)
+
+	latency, slowdown, suspected = antivirusSlowdown(1000, 400*time.Millisecond, 4)
+	must.Equal(400*time.Microsecond, latency)
+	must.Equal(0.0, slowdown)
+	wont.True(suspected)
+
+	latency, slowdown, suspected = antivirusSlowdown(1000, 10500*time.Millisecond, 4)
+	must.Equal(10500*time.Microsecond, latency)
+	must.Equal(2.5, slowdown)
+	must.True(suspected)
+
+	_, _, suspected = antivirusSlowdown(100, 1050*time.Millisecond, 0)
+	wont.True(suspected)
+}
+
+func TestTimedWorkIsCountedIntoStats(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	score := &stats{}
+	for range 3 {
+		score.Timed(func() { time.Sleep(time.Millisecond) })()
+	}
+	must.Equal(uint64(3), score.written)
+	must.True(score.writing >= 3*time.Millisecond)
+}
@@ -0,0 +1,34 @@
+package htfs
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pathlib"
+)
+
+const (
+	windowsDefender = "Windows Defender"
+)
+
+// antivirusExclusion asks Windows Defender if path is excluded from
+// scanning, using "MpCmdRun.exe -CheckExclusion".
+func antivirusExclusion(path string) (string, string) {
+	tool := filepath.Join(os.Getenv("ProgramFiles"), windowsDefender, "MpCmdRun.exe")
+	if !pathlib.IsFile(tool) {
+		return "", ExclusionUnknown
+	}
+	output, err := exec.Command(tool, "-CheckExclusion", "-path", path).CombinedOutput()
+	reply := strings.ToLower(string(output))
+	switch {
+	case strings.Contains(reply, "is not excluded"):
+		return windowsDefender, ExclusionNo
+	case strings.Contains(reply, "is excluded"):
+		return windowsDefender, ExclusionYes
+	}
+	common.Debug("Could not check antivirus exclusion of %q, reason: %v %q", path, err, reply)
+	return windowsDefender, ExclusionUnknown
+}
@@ -0,0 +1,199 @@
+package htfs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joshyorko/rcc/anywork"
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/fail"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/pretty"
+)
+
+const (
+	installationMarker = "INSTALLATION_COMPLETE"
+)
+
+var (
+	playwrightManifests = []string{
+		filepath.Join("lib", "python3*", "site-packages", "playwright", "driver", "package", "browsers.json"),
+		filepath.Join("Lib", "site-packages", "playwright", "driver", "package", "browsers.json"),
+		filepath.Join("lib", "node_modules", "playwright-core", "browsers.json"),
+		filepath.Join("node_modules", "playwright-core", "browsers.json"),
+	}
+)
+
+type browsersManifest struct {
+	Browsers []struct {
+		Name     string `json:"name"`
+		Revision string `json:"revision"`
+	} `json:"browsers"`
+}
+
+func browserBuildsFrom(content []byte) ([]string, error) {
+	manifest := new(browsersManifest)
+	err := json.Unmarshal(content, manifest)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]string, 0, len(manifest.Browsers))
+	for _, browser := range manifest.Browsers {
+		if len(browser.Name) == 0 || len(browser.Revision) == 0 {
+			continue
+		}
+		name := strings.ReplaceAll(browser.Name, "-", "_")
+		result = append(result, fmt.Sprintf("%s-%s", name, browser.Revision))
+	}
+	return result, nil
+}
+
+// PlaywrightBuilds finds browser builds (like "chromium-1091") that playwright
+// installed in given space expects to find from browsers location.
+func PlaywrightBuilds(space string) []string {
+	seen := make(map[string]bool)
+	for _, pattern := range playwrightManifests {
+		manifests, _ := filepath.Glob(filepath.Join(space, pattern))
+		for _, manifest := range manifests {
+			content, err := os.ReadFile(manifest)
+			if err != nil {
+				continue
+			}
+			builds, err := browserBuildsFrom(content)
+			if err != nil {
+				common.Debug("Ignoring playwright manifest %q, reason: %v", manifest, err)
+				continue
+			}
+			for _, build := range builds {
+				seen[build] = true
+			}
+		}
+	}
+	result := make([]string, 0, len(seen))
+	for build := range seen {
+		result = append(result, build)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// BrowserBlueprint is blueprint of catalog, that has one browser build for
+// current platform. Browser builds are immutable, so name identifies content.
+func BrowserBlueprint(build string) []byte {
+	return []byte(fmt.Sprintf("browser-build: %s\nplatform: %s\n", build, common.Platform()))
+}
+
+func browserDigester(algorithm string) Filetask {
+	return func(fullpath string, details *File) anywork.Work {
+		return func() {
+			source, err := os.Open(fullpath)
+			if err != nil {
+				panic(fmt.Sprintf("Open[browser] %q, reason: %v", fullpath, err))
+			}
+			defer source.Close()
+			digest := NewDigest(algorithm)
+			_, err = io.Copy(digest, source)
+			if err != nil {
+				panic(fmt.Sprintf("Copy[browser] %q, reason: %v", fullpath, err))
+			}
+			details.Rewrite = make([]int64, 0)
+			details.Digest = fmt.Sprintf("%02x", digest.Sum(nil))
+		}
+	}
+}
+
+func recordBrowserBuild(library MutableLibrary, location, build string) (err error) {
+	defer fail.Around(&err)
+
+	key := common.BlueprintHash(BrowserBlueprint(build))
+	common.TimelineBegin("browser build record start %s [%s]", build, key)
+	defer common.TimelineEnd()
+	fs, err := NewRoot(filepath.Join(location, build))
+	fail.On(err != nil, "Failed to create root for %q -> %v", build, err)
+	fail.Fast(fs.Lift())
+	fs.Algorithm = SelectedDigest()
+	fail.Fast(fs.AllFiles(browserDigester(fs.Algorithm)))
+	fs.Blueprint, fs.Machine = key, CurrentMachine()
+	catalog := library.CatalogPath(key)
+	mutation, err := BeginMutation(MutationLift, newCatalogs(catalog), nil)
+	fail.Fast(err)
+	score := &stats{}
+	err = fs.Treetop(ScheduleLifters(library, score))
+	if err == nil {
+		err = fs.SaveAs(catalog)
+	}
+	common.Debug("Browser build %q recorded as %q, new workload: %d/%d", build, key, score.dirty, score.total)
+	return mutation.Complete(err)
+}
+
+func restoreBrowserBuild(library MutableLibrary, location, build string) (err error) {
+	defer fail.Around(&err)
+
+	key := common.BlueprintHash(BrowserBlueprint(build))
+	catalog := expandedCatalog(library, key)
+	common.TimelineBegin("browser build restore start %s [%s]", build, key)
+	defer common.TimelineEnd()
+	fs, err := NewRoot(filepath.Join(location, build))
+	fail.On(err != nil, "Failed to create root for %q -> %v", build, err)
+	targetdir := fs.Path
+	err = fs.LoadFrom(catalog)
+	fail.On(err != nil, "Failed to load catalog %s -> %v", catalog, err)
+	fs.Path, fs.Identity = targetdir, build
+	fail.Fast(fs.Treetop(MakeBranches))
+	score := &stats{}
+	fail.Fast(fs.AllDirs(RestoreDirectory(library, fs, make(map[string]string), score)))
+	pathlib.TouchWhen(catalog, time.Now())
+	common.Debug("Browser build %q restored from %q, dirty workload: %d/%d", build, key, score.dirty, score.total)
+	return nil
+}
+
+// SyncBrowsers keeps playwright browser builds of given space and hololib in
+// sync. Builds that are in browsers location but not in hololib are recorded
+// there, and builds that are in hololib but missing from browsers location
+// are rehydrated, so that same browser build is downloaded only once.
+func SyncBrowsers(space string) (recorded, restored []string, err error) {
+	defer fail.Around(&err)
+
+	builds := PlaywrightBuilds(space)
+	if len(builds) == 0 {
+		return nil, nil, nil
+	}
+	library, err := New()
+	fail.Fast(err)
+	location := common.BrowsersLocation()
+	for _, build := range builds {
+		present := pathlib.IsFile(filepath.Join(location, build, installationMarker))
+		cached := library.HasBlueprint(BrowserBlueprint(build))
+		switch {
+		case present && !cached:
+			fail.Fast(recordBrowserBuild(library, location, build))
+			recorded = append(recorded, build)
+		case cached && !present:
+			fail.Fast(restoreBrowserBuild(library, location, build))
+			restored = append(restored, build)
+		}
+	}
+	return recorded, restored, nil
+}
+
+func syncBrowserBuilds(space string) {
+	recorded, restored, err := SyncBrowsers(space)
+	if err != nil {
+		pretty.Warning("Could not sync playwright browser builds with hololib, reason: %v", err)
+		return
+	}
+	if len(recorded) > 0 {
+		common.Log("Recorded browser builds into hololib: %s", strings.Join(recorded, ", "))
+		common.RunJournal("browsers", "recorded", "%s", strings.Join(recorded, ", "))
+	}
+	if len(restored) > 0 {
+		common.Log("Rehydrated browser builds from hololib: %s", strings.Join(restored, ", "))
+		common.RunJournal("browsers", "restored", "%s", strings.Join(restored, ", "))
+	}
+}
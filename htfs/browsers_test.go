@@ -0,0 +1,86 @@
+package htfs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+const (
+	testBrowsersJson = `{
+  "comment": "Do not edit this file, use utils/roll_browser.js",
+  "browsers": [
+    {"name": "chromium", "revision": "1091", "installByDefault": true},
+    {"name": "chromium-headless-shell", "revision": "1155", "installByDefault": true},
+    {"name": "firefox", "revision": "1429", "installByDefault": true}
+  ]
+}`
+)
+
+func writeTestFile(t *testing.T, fullpath, content string) {
+	err := os.MkdirAll(filepath.Dir(fullpath), 0o755)
+	if err == nil {
+		err = os.WriteFile(fullpath, []byte(content), 0o644)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPlaywrightBuildsAreFoundFromSpace(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	builds, err := browserBuildsFrom([]byte(testBrowsersJson))
+	must.Nil(err)
+	must.Equal([]string{"chromium-1091", "chromium_headless_shell-1155", "firefox-1429"}, builds)
+	_, err = browserBuildsFrom([]byte("{not json"))
+	wont.Nil(err)
+
+	space := t.TempDir()
+	must.Equal(0, len(PlaywrightBuilds(space)))
+	writeTestFile(t, filepath.Join(space, "lib", "python3.10", "site-packages", "playwright", "driver", "package", "browsers.json"), testBrowsersJson)
+	must.Equal([]string{"chromium-1091", "chromium_headless_shell-1155", "firefox-1429"}, PlaywrightBuilds(space))
+
+	wont.Equal(string(BrowserBlueprint("chromium-1091")), string(BrowserBlueprint("firefox-1429")))
+}
+
+func TestBrowserBuildsAreRecordedAndRehydrated(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	t.Setenv("ROBOCORP_HOME", t.TempDir())
+	browsers := t.TempDir()
+	t.Setenv("PLAYWRIGHT_BROWSERS_PATH", browsers)
+	space := t.TempDir()
+	writeTestFile(t, filepath.Join(space, "Lib", "site-packages", "playwright", "driver", "package", "browsers.json"), testBrowsersJson)
+
+	chrome := filepath.Join(browsers, "chromium-1091")
+	writeTestFile(t, filepath.Join(chrome, "chrome-linux", "chrome"), "pretend this is chrome")
+	writeTestFile(t, filepath.Join(chrome, installationMarker), "")
+	writeTestFile(t, filepath.Join(browsers, "firefox-1429", "firefox", "firefox"), "partial download")
+
+	recorded, restored, err := SyncBrowsers(space)
+	must.Nil(err)
+	must.Equal([]string{"chromium-1091"}, recorded)
+	must.Equal(0, len(restored))
+
+	recorded, restored, err = SyncBrowsers(space)
+	must.Nil(err)
+	must.Equal(0, len(recorded))
+	must.Equal(0, len(restored))
+
+	must.Nil(os.RemoveAll(chrome))
+	recorded, restored, err = SyncBrowsers(space)
+	must.Nil(err)
+	must.Equal(0, len(recorded))
+	must.Equal([]string{"chromium-1091"}, restored)
+
+	content, err := os.ReadFile(filepath.Join(chrome, "chrome-linux", "chrome"))
+	must.Nil(err)
+	must.Equal("pretend this is chrome", string(content))
+	_, err = os.Stat(filepath.Join(chrome, installationMarker))
+	must.Nil(err)
+	_, err = os.Stat(filepath.Join(browsers, "firefox-1429", installationMarker))
+	wont.Nil(err)
+}
@@ -0,0 +1,166 @@
+package htfs
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/joshyorko/rcc/set"
+)
+
+const (
+	catalogIndexVersion = 1
+)
+
+// catalogIndex is compact binary (gob) summary of catalog infos, so that
+// commands needing only catalog identity, platform, and holotree location do
+// not have to decompress and parse full catalog trees on every invocation.
+// Entries are validated against catalog size and modification time, and
+// stale or missing entries are rebuilt lazily (from catalog .info file when
+// available, otherwise from full catalog).
+type catalogIndex struct {
+	Version int
+	Entries map[string]*catalogIndexEntry
+}
+
+type catalogIndexEntry struct {
+	Size     int64
+	Modified int64
+	Info     Info
+	Lifted   bool
+}
+
+func loadCatalogIndex(filename string) *catalogIndex {
+	result := &catalogIndex{
+		Version: catalogIndexVersion,
+		Entries: make(map[string]*catalogIndexEntry),
+	}
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return result
+	}
+	loaded := &catalogIndex{}
+	err = gob.NewDecoder(bytes.NewReader(content)).Decode(loaded)
+	if err != nil || loaded.Version != catalogIndexVersion || loaded.Entries == nil {
+		common.Debug("Ignoring catalog index %q, reason: %v", filename, err)
+		return result
+	}
+	return loaded
+}
+
+func (it *catalogIndex) save(filename string) error {
+	sink := bytes.NewBuffer(nil)
+	err := gob.NewEncoder(sink).Encode(it)
+	if err != nil {
+		return err
+	}
+	partname := fmt.Sprintf("%s.part%s", filename, <-common.Identities)
+	defer os.Remove(partname)
+	err = pathlib.WriteFile(partname, sink.Bytes(), 0o644)
+	if err != nil {
+		return err
+	}
+	return pathlib.TryRename("catalog index", partname, filename)
+}
+
+func (it *catalogIndexEntry) fresh(stat os.FileInfo) bool {
+	return it.Size == stat.Size() && it.Modified == stat.ModTime().UnixNano()
+}
+
+func (it *catalogIndexEntry) root(fullpath string) *Root {
+	info := it.Info
+	return &Root{
+		Info:   &info,
+		Lifted: it.Lifted,
+		Tree:   newDir("", "", false),
+		source: fullpath,
+	}
+}
+
+func indexedCatalog(fullpath string, stat os.FileInfo) (*catalogIndexEntry, error) {
+	entry := &catalogIndexEntry{
+		Size:     stat.Size(),
+		Modified: stat.ModTime().UnixNano(),
+	}
+	content, err := os.ReadFile(fullpath + ".info")
+	if err == nil && json.Unmarshal(content, &entry.Info) == nil && len(entry.Info.Blueprint) > 0 {
+		return entry, nil
+	}
+	shadow, err := NewRoot(filepath.Join(common.ProductTemp(), "shadow"))
+	if err != nil {
+		return nil, err
+	}
+	err = shadow.LoadFrom(fullpath)
+	if err != nil {
+		return nil, err
+	}
+	entry.Info, entry.Lifted = *shadow.Info, shadow.Lifted
+	return entry, nil
+}
+
+// LoadCatalogInfos is fast path alternative for LoadCatalogs, when only
+// catalog infos (blueprint, platform, holotree path) are needed. Returned
+// roots have empty trees.
+func LoadCatalogInfos() Roots {
+	common.TimelineBegin("catalog index load start")
+	defer common.TimelineEnd()
+
+	indexfile := common.HololibCatalogIndex()
+	index := loadCatalogIndex(indexfile)
+	catalogs := CatalogNames()
+	entries := make(map[string]*catalogIndexEntry)
+	roots := make(Roots, 0, len(catalogs))
+	changed := len(index.Entries) != len(catalogs)
+	counter := pretty.NewCounter("Loading catalog infos", len(catalogs))
+	defer counter.Done()
+	for _, catalog := range catalogs {
+		counter.Tick()
+		fullpath := filepath.Join(common.HololibCatalogLocation(), catalog)
+		stat, err := os.Stat(fullpath)
+		if err != nil {
+			continue
+		}
+		entry, ok := index.Entries[catalog]
+		if !ok || !entry.fresh(stat) {
+			entry, err = indexedCatalog(fullpath, stat)
+			if err != nil {
+				common.Debug("Could not index catalog %q, reason: %v", catalog, err)
+				continue
+			}
+			changed = true
+		}
+		entries[catalog] = entry
+		roots = append(roots, entry.root(fullpath))
+	}
+	compacted := compactedInfos(catalogs)
+	for _, catalog := range set.Keys(compacted) {
+		entry := &catalogIndexEntry{Info: compacted[catalog].Info, Lifted: compacted[catalog].Lifted}
+		roots = append(roots, entry.root(filepath.Join(common.HololibCatalogLocation(), catalog)))
+	}
+	if changed {
+		index.Entries = entries
+		err := index.save(indexfile)
+		if err != nil {
+			common.Debug("Could not save catalog index %q, reason: %v", indexfile, err)
+		}
+	}
+	common.Timeline("%d catalog infos loaded (index changed: %v)", len(roots), changed)
+	return roots
+}
+
+// ForgetCatalogIndex removes catalog index, so that next LoadCatalogInfos
+// rebuilds it from scratch.
+func ForgetCatalogIndex() error {
+	indexfile := common.HololibCatalogIndex()
+	if !pathlib.IsFile(indexfile) {
+		return nil
+	}
+	common.Debug("Forgetting catalog index %q.", indexfile)
+	return pathlib.TryRemove("catalog index", indexfile)
+}
@@ -0,0 +1,65 @@
+package htfs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func saveTestCatalog(t *testing.T, blueprint string, withInfo bool) string {
+	root, err := NewRoot(filepath.Join(t.TempDir(), "space"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root.Blueprint = blueprint
+	fullpath := filepath.Join(common.HololibCatalogLocation(), CatalogName(blueprint))
+	if err := root.SaveAs(fullpath); err != nil {
+		t.Fatal(err)
+	}
+	if !withInfo {
+		os.Remove(fullpath + ".info")
+	}
+	return fullpath
+}
+
+func TestCatalogInfosAreIndexedLazily(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	t.Setenv("ROBOCORP_HOME", t.TempDir())
+	first := saveTestCatalog(t, "0123456789abcdef", true)
+	saveTestCatalog(t, "fedcba9876543210", false)
+
+	roots := LoadCatalogInfos()
+	must.Equal(2, len(roots))
+	must.True(roots[0].Info.Blueprint == "0123456789abcdef")
+	must.True(roots[1].Info.Blueprint == "fedcba9876543210")
+	must.Equal(first, roots[0].Source())
+
+	index := loadCatalogIndex(common.HololibCatalogIndex())
+	must.Equal(2, len(index.Entries))
+
+	// fresh entries are used as is, without touching catalogs
+	index.Entries[CatalogName("0123456789abcdef")].Info.Space = "from-index"
+	must.Nil(index.save(common.HololibCatalogIndex()))
+	roots = LoadCatalogInfos()
+	must.Equal("from-index", roots[0].Info.Space)
+
+	// forgotten index is rebuilt from catalogs
+	must.Nil(ForgetCatalogIndex())
+	must.Nil(ForgetCatalogIndex())
+	roots = LoadCatalogInfos()
+	wont.Equal("from-index", roots[0].Info.Space)
+	index.Entries[CatalogName("0123456789abcdef")].Info.Space = "from-index"
+	must.Nil(index.save(common.HololibCatalogIndex()))
+
+	// removed catalogs disappear, and stale entries are rebuilt
+	must.Nil(os.Remove(first))
+	saveTestCatalog(t, "fedcba9876543210", true)
+	roots = LoadCatalogInfos()
+	must.Equal(1, len(roots))
+	wont.Equal("from-index", roots[0].Info.Space)
+	must.Equal(1, len(loadCatalogIndex(common.HololibCatalogIndex()).Entries))
+}
@@ -0,0 +1,42 @@
+package htfs
+
+import (
+	"errors"
+	"io"
+	"os"
+	"sync/atomic"
+
+	"github.com/joshyorko/rcc/common"
+)
+
+var (
+	errCloneUnsupported = errors.New("file cloning is not supported")
+
+	cloningFailed atomic.Bool
+	clonedFiles   atomic.Uint64
+)
+
+// clonedSink tries to make copy-on-write clone of uncompressed hololib file
+// into partname, when hololib and holotree live on same CoW capable filesystem.
+// After first failure, cloning is not tried again, and restore falls back to
+// copying bytes.
+func clonedSink(reader io.Reader, partname string) (*os.File, bool) {
+	source, ok := reader.(*os.File)
+	if !ok || cloningFailed.Load() || common.RccNoClone() {
+		return nil, false
+	}
+	sink, err := cloneFile(source, partname)
+	if err != nil {
+		if cloningFailed.CompareAndSwap(false, true) {
+			common.Debug("Restore falls back to copying files, since cloning %q failed, reason: %v", source.Name(), err)
+		}
+		return nil, false
+	}
+	clonedFiles.Add(1)
+	return sink, true
+}
+
+// ClonedFiles tells how many files were restored as copy-on-write clones.
+func ClonedFiles() uint64 {
+	return clonedFiles.Load()
+}
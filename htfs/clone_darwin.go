@@ -0,0 +1,17 @@
+package htfs
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// cloneFile makes copy-on-write clone of source using clonefile, which works
+// on same APFS volume. Returned sink is open for reading and writing.
+func cloneFile(source *os.File, target string) (*os.File, error) {
+	err := unix.Clonefile(source.Name(), target, unix.CLONE_NOFOLLOW)
+	if err != nil {
+		return nil, err
+	}
+	return os.OpenFile(target, os.O_RDWR, 0)
+}
@@ -0,0 +1,23 @@
+package htfs
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// cloneFile makes copy-on-write clone of source using FICLONE ioctl, which
+// works on same filesystem in btrfs and XFS (with reflinks). Returned sink is
+// open for reading and writing.
+func cloneFile(source *os.File, target string) (*os.File, error) {
+	sink, err := os.OpenFile(target, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	err = unix.IoctlFileClone(int(sink.Fd()), int(source.Fd()))
+	if err != nil {
+		sink.Close()
+		return nil, err
+	}
+	return sink, nil
+}
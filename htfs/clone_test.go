@@ -0,0 +1,46 @@
+package htfs
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestClonedSinkFallsBackToCopying(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	defer cloningFailed.Store(false)
+	folder := t.TempDir()
+	source := filepath.Join(folder, "source")
+	must.Nil(os.WriteFile(source, []byte("hololib content"), 0o644))
+
+	_, cloned := clonedSink(bytes.NewReader([]byte("compressed")), filepath.Join(folder, "first"))
+	wont.True(cloned)
+
+	t.Setenv(common.RCC_NO_CLONE, "true")
+	reader, err := os.Open(source)
+	must.Nil(err)
+	defer reader.Close()
+	_, cloned = clonedSink(reader, filepath.Join(folder, "second"))
+	wont.True(cloned)
+
+	t.Setenv(common.RCC_NO_CLONE, "")
+	before := ClonedFiles()
+	sink, cloned := clonedSink(reader, filepath.Join(folder, "third"))
+	if !cloned {
+		must.True(cloningFailed.Load())
+		_, cloned = clonedSink(reader, filepath.Join(folder, "fourth"))
+		wont.True(cloned)
+		return
+	}
+	defer sink.Close()
+	must.Equal(before+1, ClonedFiles())
+	content, err := io.ReadAll(sink)
+	must.Nil(err)
+	must.Equal("hololib content", string(content))
+}
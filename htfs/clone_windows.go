@@ -0,0 +1,10 @@
+package htfs
+
+import (
+	"os"
+)
+
+// cloneFile is not supported on Windows, so restore always copies bytes.
+func cloneFile(source *os.File, target string) (*os.File, error) {
+	return nil, errCloneUnsupported
+}
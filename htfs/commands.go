@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/joshyorko/rcc/anywork"
 	"github.com/joshyorko/rcc/common"
@@ -17,13 +18,54 @@ import (
 	"github.com/joshyorko/rcc/pretty"
 	"github.com/joshyorko/rcc/robot"
 	"github.com/joshyorko/rcc/settings"
+	"github.com/joshyorko/rcc/shell"
 	"github.com/joshyorko/rcc/xviper"
 )
 
 type CatalogPuller func(string, string, bool) error
+type BlobFetcher func(string, string, string) error
 
-func NewEnvironment(condafile, holozip string, restore, force bool, puller CatalogPuller) (label string, scorecard common.Scorecard, err error) {
+var (
+	RemoteCatalog CatalogPuller
+	RemoteBlob    BlobFetcher
+)
+
+func streamCatalog(blueprint []byte) bool {
+	origin := common.RccRemoteOrigin()
+	if !common.RccRemoteStreaming() || len(origin) == 0 || RemoteCatalog == nil || settings.Global.Offline() {
+		return false
+	}
+	catalog := CatalogName(common.BlueprintHash(blueprint))
+	pretty.Progress(3, "Fetch catalog, and blobs of restored files, from RCC_REMOTE_ORIGIN.")
+	err := RemoteCatalog(origin, catalog, false)
+	if err != nil {
+		pretty.Warning("Failed to stream %q from %q, reason: %v", catalog, origin, err)
+		return false
+	}
+	common.Timeline("catalog %q streamed from %q", catalog, origin)
+	return true
+}
+
+func streamedBlob(digest string) error {
+	location := ExactDefaultLocation(digest)
+	if RemoteBlob == nil || !common.RccRemoteStreaming() || pathlib.IsFile(location) {
+		return nil
+	}
+	origin := common.RccRemoteOrigin()
+	if len(origin) == 0 {
+		return nil
+	}
+	err := RemoteBlob(origin, digest, location)
+	if err != nil {
+		return fmt.Errorf("Streaming blob %q from %q failed, reason: %v", digest, origin, err)
+	}
+	return nil
+}
+
+func NewEnvironment(condafile, holozip string, localPackages []string, restore, force bool, puller CatalogPuller) (label string, scorecard common.Scorecard, err error) {
 	defer fail.Around(&err)
+	defer pretty.SummaryDuration("environment", time.Now())
+	defer pathlib.TrackActivity("environment")()
 
 	who, _ := user.Current()
 	host, _ := os.Hostname()
@@ -39,6 +81,9 @@ func NewEnvironment(condafile, holozip string, restore, force bool, puller Catal
 
 	journal.CurrentBuildEvent().StartNow(force)
 
+	if settings.Global.Offline() {
+		pretty.Note("Offline mode is active. Only environments already in hololib can be used!")
+	}
 	if settings.Global.NoBuild() {
 		pretty.Note("'no-build' setting is active. Only cached, prebuild, or imported environments are allowed!")
 	}
@@ -57,6 +102,7 @@ func NewEnvironment(condafile, holozip string, restore, force bool, puller Catal
 			if len(path) > 0 {
 				usefile := fmt.Sprintf("%s.use", path)
 				pathlib.AppendFile(usefile, []byte{'.'})
+				recordSpaceUser(path)
 			}
 		}
 		if haszip {
@@ -84,17 +130,37 @@ func NewEnvironment(condafile, holozip string, restore, force bool, puller Catal
 	fail.On(err != nil, "Could not get lock for holotree. Quiting.")
 	defer locker.Release()
 
-	_, holotreeBlueprint, err := ComposeFinalBlueprint([]string{condafile}, "", false)
+	RecoverMutations()
+
+	holotreeBlueprint, err := composeBlueprint([]string{condafile}, localPackages, false)
 	fail.Fast(err)
 
 	common.EnvironmentHash, common.FreshlyBuildEnvironment = common.BlueprintHash(holotreeBlueprint), false
+	pretty.EstimateFor(common.EnvironmentHash)
 	pretty.Progress(2, "Holotree blueprint is %q [%s with %d workers on %d CPUs from %q].", common.EnvironmentHash, common.Platform(), anywork.Scale(), runtime.NumCPU(), filepath.Base(condafile))
 	journal.CurrentBuildEvent().Blueprint(common.EnvironmentHash)
+	if requirement := conda.GpuRequirementOf(holotreeBlueprint); requirement != nil {
+		common.Debug("Holotree blueprint %q requires %s.", common.EnvironmentHash, requirement)
+		conda.WarnGpuRequirement(requirement, conda.DetectGpu())
+	}
 
 	tree, err := New()
 	fail.Fast(err)
 
-	if !haszip && !tree.HasBlueprint(holotreeBlueprint) && common.Liveonly {
+	stopCancel := shell.CancelOnInterrupt("environment build")
+	defer stopCancel()
+	restoring := false
+	defer func() {
+		if shell.Cancelled() {
+			err = cancelledBuild(tree, holotreeBlueprint, restoring && len(path) == 0)
+		}
+	}()
+
+	streamed := false
+	if restore && !haszip && !force && !tree.HasBlueprint(holotreeBlueprint) {
+		streamed = streamCatalog(holotreeBlueprint)
+	}
+	if !streamed && !haszip && !tree.HasBlueprint(holotreeBlueprint) && common.Liveonly {
 		tree = Virtual()
 		common.Timeline("downgraded to virtual holotree library")
 	}
@@ -108,17 +174,26 @@ func NewEnvironment(condafile, holozip string, restore, force bool, puller Catal
 		library, err = ZipLibrary(holozip)
 		fail.On(err != nil, "Failed to load %q -> %s", holozip, err)
 		common.Timeline("downgraded to holotree zip library")
+		journal.CurrentBuildEvent().BuildSource(journal.BuildSourceCache)
+	} else if streamed {
+		library = tree
+		journal.CurrentBuildEvent().BuildSource(journal.BuildSourceRemote)
 	} else {
 		scorecard.Start()
 		fail.Fast(RecordEnvironment(tree, holotreeBlueprint, force, scorecard, puller))
 		library = tree
 	}
 
+	fail.On(shell.Cancelled(), "%v", shell.ErrCancelled)
 	if restore {
+		restoring = true
 		pretty.Progress(14, "Restore space from library [with %d workers on %d CPUs; with compression: %v].", anywork.Scale(), runtime.NumCPU(), Compress())
 		path, err = library.Restore(holotreeBlueprint, []byte(common.ControllerIdentity()), []byte(common.HolotreeSpace))
 		fail.On(err != nil, "Failed to restore blueprint %q, reason: %v", string(holotreeBlueprint), err)
 		journal.CurrentBuildEvent().RestoreComplete()
+		if !haszip && common.BrowserCacheEnabled() {
+			syncBrowserBuilds(path)
+		}
 	} else {
 		pretty.Progress(14, "Restoring space skipped.")
 	}
@@ -130,6 +205,31 @@ func NewEnvironment(condafile, holozip string, restore, force bool, puller Catal
 	return path, scorecard, nil
 }
 
+// cancelledBuild cleans up after interrupted environment build, so that
+// holotree is left in consistent state: stage is removed, and so is space,
+// if its restore was interrupted.
+func cancelledBuild(tree MutableLibrary, blueprint []byte, partialSpace bool) error {
+	journal.CurrentBuildEvent().Cancel()
+	pretty.Warning("Environment build was cancelled. Cleaning up partial results.")
+	err := CleanupHolotreeStage(tree)
+	if err != nil {
+		pretty.Warning("Could not remove holotree stage, reason: %v", err)
+	}
+	if partialSpace {
+		targetdir, err := tree.TargetDir(blueprint, []byte(common.ControllerIdentity()), []byte(common.HolotreeSpace))
+		if err == nil {
+			err = pathlib.TryRemoveAll("space", targetdir)
+		}
+		if err == nil {
+			os.Remove(fmt.Sprintf("%s.meta", targetdir))
+		}
+		if err != nil {
+			pretty.Warning("Could not remove partially restored space, reason: %v", err)
+		}
+	}
+	return shell.ErrCancelled
+}
+
 func CleanupHolotreeStage(tree MutableLibrary) error {
 	common.TimelineBegin("holotree stage removal")
 	defer common.TimelineEnd()
@@ -155,10 +255,13 @@ func RecordEnvironment(tree MutableLibrary, blueprint []byte, force bool, scorec
 
 	conda.LogUnifiedEnvironment(blueprint)
 
+	journal.CurrentBuildEvent().BuildSource(journal.BuildSourceCache)
 	if force || !exists {
 		common.FreshlyBuildEnvironment = true
 		remoteOrigin := common.RccRemoteOrigin()
-		if len(remoteOrigin) > 0 {
+		if len(remoteOrigin) > 0 && settings.Global.Offline() {
+			pretty.Progress(3, "Fill hololib from RCC_REMOTE_ORIGIN skipped. Offline mode is active.")
+		} else if len(remoteOrigin) > 0 {
 			pretty.Progress(3, "Fill hololib from RCC_REMOTE_ORIGIN.")
 			hash := common.BlueprintHash(blueprint)
 			catalog := CatalogName(hash)
@@ -166,6 +269,7 @@ func RecordEnvironment(tree MutableLibrary, blueprint []byte, force bool, scorec
 			if err != nil {
 				pretty.Warning("Failed to pull %q from %q, reason: %v", catalog, remoteOrigin, err)
 			} else {
+				journal.CurrentBuildEvent().BuildSource(journal.BuildSourceRemote)
 				return nil
 			}
 			exists = tree.HasBlueprint(blueprint)
@@ -173,6 +277,7 @@ func RecordEnvironment(tree MutableLibrary, blueprint []byte, force bool, scorec
 			pretty.Progress(3, "Fill hololib from RCC_REMOTE_ORIGIN skipped. RCC_REMOTE_ORIGIN was not defined.")
 		}
 		pretty.Progress(4, "Cleanup holotree stage for fresh install.")
+		fail.On(settings.Global.Offline(), "Building new holotree environment is not possible in offline mode, and it could not be found from hololib cache!")
 		fail.On(settings.Global.NoBuild(), "Building new holotree environment is blocked by settings, and could not be found from hololib cache!")
 		err = CleanupHolotreeStage(tree)
 		fail.On(err != nil, "Failed to clean stage, reason %v.", err)
@@ -197,7 +302,13 @@ func RecordEnvironment(tree MutableLibrary, blueprint []byte, force bool, scorec
 		err = os.WriteFile(identityfile, blueprint, 0o644)
 		fail.On(err != nil, "Failed to save %q, reason %w.", identityfile, err)
 
+		if skip > conda.SkipNoLayers {
+			journal.CurrentBuildEvent().BuildSource(journal.BuildSourcePartial)
+		} else {
+			journal.CurrentBuildEvent().BuildSource(journal.BuildSourceFull)
+		}
 		err = conda.LegacyEnvironment(tree, force, skip, identityfile)
+		fail.On(shell.Cancelled(), "%v", shell.ErrCancelled)
 		fail.On(err != nil, "Failed to create environment, reason %w.", err)
 
 		scorecard.Midpoint()
@@ -253,12 +364,20 @@ func RobotBlueprints(userBlueprints []string, packfile string) (robot.Robot, []s
 }
 
 func ComposeFinalBlueprint(userFiles []string, packfile string, devDependencies bool) (config robot.Robot, blueprint []byte, err error) {
+	config, filenames := RobotBlueprints(userFiles, packfile)
+	var localPackages []string
+	if config != nil {
+		localPackages = config.LocalPackages()
+	}
+	blueprint, err = composeBlueprint(filenames, localPackages, devDependencies)
+	return config, blueprint, err
+}
+
+func composeBlueprint(filenames, localPackages []string, devDependencies bool) (blueprint []byte, err error) {
 	defer fail.Around(&err)
 
 	var left, right *conda.Environment
 
-	config, filenames := RobotBlueprints(userFiles, packfile)
-
 	for _, filename := range filenames {
 		left = right
 		right, err = conda.ReadPackageCondaYaml(filename, devDependencies)
@@ -270,13 +389,15 @@ func ComposeFinalBlueprint(userFiles []string, packfile string, devDependencies
 		fail.On(err != nil, "Failure: %v", err)
 	}
 	fail.On(right == nil, "Missing environment specification(s).")
+	err = right.AddLocalPackages(localPackages)
+	fail.On(err != nil, "Failure: %v", err)
 	blueprint, err = BlueprintFromEnvironment(right)
 	fail.On(err != nil, "Blueprint from environment error: %v", err)
 	if !right.IsCacheable() {
 		fingerprint := common.BlueprintHash(blueprint)
 		pretty.Warning("Holotree blueprint %q is not publicly cacheable. Use `rcc robot diagnostics` to find out more.", fingerprint)
 	}
-	return config, blueprint, nil
+	return blueprint, nil
 }
 
 func BlueprintFromEnvironment(environment *conda.Environment) ([]byte, error) {
@@ -0,0 +1,39 @@
+package htfs
+
+import (
+	"hash"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/settings"
+)
+
+const (
+	DigestBlake3 = "blake3"
+)
+
+// SelectedDigest is digest algorithm used for new catalogs. Empty means
+// legacy algorithm, which is sha256 or siphash, depending on compression.
+func SelectedDigest() string {
+	if settings.Global.Blake3Digests() {
+		return DigestBlake3
+	}
+	return ""
+}
+
+// NewDigest creates digester for algorithm recorded in catalog.
+func NewDigest(algorithm string) hash.Hash {
+	if algorithm == DigestBlake3 {
+		return common.NewBlake3()
+	}
+	return common.NewDigester(Compress())
+}
+
+func DigestName(algorithm string) string {
+	if len(algorithm) > 0 {
+		return algorithm
+	}
+	if Compress() {
+		return "sha256"
+	}
+	return "siphash"
+}
@@ -0,0 +1,64 @@
+package htfs
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func hexdigest(algorithm string, content []byte) string {
+	digester := NewDigest(algorithm)
+	digester.Write(content)
+	return fmt.Sprintf("%02x", digester.Sum(nil))
+}
+
+func TestBlobDigestTriesLegacyBeforeBlake3(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	content := []byte("shared hololib blob content")
+	legacy := hexdigest("", content)
+	blake3 := hexdigest(DigestBlake3, content)
+	wont.Equal(legacy, blake3)
+
+	reads := 0
+	reopen := func() (io.ReadCloser, error) {
+		reads++
+		return io.NopCloser(bytes.NewReader(content)), nil
+	}
+
+	actual, err := BlobDigest(bytes.NewReader(content), legacy, reopen)
+	must.Nil(err)
+	must.Equal(legacy, actual)
+	must.Equal(0, reads)
+
+	actual, err = BlobDigest(bytes.NewReader(content), blake3, reopen)
+	must.Nil(err)
+	must.Equal(blake3, actual)
+	must.Equal(1, reads)
+
+	actual, err = BlobDigest(bytes.NewReader(content), "unknown", reopen)
+	must.Nil(err)
+	must.Equal(legacy, actual)
+
+	actual, err = BlobDigest(bytes.NewReader(content), blake3, nil)
+	must.Nil(err)
+	must.Equal(legacy, actual)
+}
+
+func TestVerifyBlobFileAcceptsAllKnownAlgorithms(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	content := []byte("shared hololib blob content")
+	fullpath := filepath.Join(t.TempDir(), "blob")
+	must.Nil(os.WriteFile(fullpath, content, 0o644))
+
+	must.Nil(VerifyBlobFile(fullpath, hexdigest("", content)))
+	must.Nil(VerifyBlobFile(fullpath, hexdigest(DigestBlake3, content)))
+	wont.Nil(VerifyBlobFile(fullpath, hexdigest("", []byte("other content"))))
+	wont.Nil(VerifyBlobFile(fullpath+".missing", hexdigest("", content)))
+}
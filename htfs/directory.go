@@ -37,6 +37,7 @@ func init() {
 		pathlib.MakeSharedDir(common.HololibCatalogLocation())
 		pathlib.MakeSharedDir(common.HololibLibraryLocation())
 		pathlib.MakeSharedDir(common.HololibUsageLocation())
+		pathlib.MakeSharedDir(common.HololibLiftLocation())
 		pathlib.MakeSharedDir(common.HololibPids())
 	}
 }
@@ -47,13 +48,16 @@ type (
 	Treetop  func(string, *Dir) error
 
 	Info struct {
-		RccVersion string `json:"rcc"`
-		Identity   string `json:"identity"`
-		Path       string `json:"path"`
-		Controller string `json:"controller"`
-		Space      string `json:"space"`
-		Platform   string `json:"platform"`
-		Blueprint  string `json:"blueprint"`
+		RccVersion    string   `json:"rcc"`
+		Identity      string   `json:"identity"`
+		Path          string   `json:"path"`
+		Controller    string   `json:"controller"`
+		Space         string   `json:"space"`
+		Platform      string   `json:"platform"`
+		Blueprint     string   `json:"blueprint"`
+		Algorithm     string   `json:"algorithm,omitempty"`
+		Machine       *Machine `json:"machine,omitempty"`
+		LocalPackages []string `json:"localpackages,omitempty"`
 	}
 
 	Root struct {
@@ -80,6 +84,7 @@ type (
 		Mode    fs.FileMode `json:"mode"`
 		Digest  string      `json:"digest"`
 		Rewrite []int64     `json:"rewrite"`
+		mtime   int64
 	}
 )
 
@@ -113,6 +118,16 @@ func (it Roots) BaseFolders() []string {
 	return set.Set(result)
 }
 
+func (it Roots) ForPlatform(platform string) Roots {
+	roots := make(Roots, 0, len(it))
+	for _, root := range it {
+		if strings.EqualFold(root.Platform, platform) {
+			roots = append(roots, root)
+		}
+	}
+	return roots
+}
+
 func (it Roots) Spaces() Roots {
 	roots := make(Roots, 0, 20)
 	for directory, metafile := range it.Spacemap() {
@@ -172,6 +187,8 @@ func (it Roots) RemoveHolotreeSpace(label string) (err error) {
 		}
 		pathlib.TryRemove("metafile", metafile)
 		pathlib.TryRemove("lockfile", directory+".lck")
+		pathlib.TryRemove("usersfile", spaceUsersFile(directory))
+		pathlib.TryRemove("robotsfile", spaceRobotsFile(directory))
 		err = pathlib.TryRemoveAll("space", directory)
 		fail.On(err != nil, "Problem removing %q, reason: %v.", directory, err)
 		common.Timeline("removed holotree space %q", directory)
@@ -487,5 +504,6 @@ func newFile(info fs.FileInfo, symlink string) *File {
 		Size:    info.Size(),
 		Digest:  "N/A",
 		Rewrite: make([]int64, 0),
+		mtime:   info.ModTime().UnixNano(),
 	}
 }
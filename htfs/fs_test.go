@@ -74,3 +74,39 @@ func TestZipLibrary(t *testing.T) {
 	wont.Nil(sut)
 	must.True(sut.HasBlueprint(blueprint))
 }
+
+func TestCanFilterCatalogsByPlatform(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	catalogs := []string{
+		"0123456789abcdefv12.linux_amd64",
+		"fedcba9876543210v12.windows_amd64",
+		"00112233445566ffv12.Linux_AMD64",
+		"broken",
+	}
+	must.Equal("linux_amd64", htfs.CatalogPlatform(catalogs[0]))
+	must.Equal("windows_amd64", htfs.CatalogPlatform("/some/where/"+catalogs[1]))
+	must.Equal("", htfs.CatalogPlatform(catalogs[3]))
+	must.Equal([]string{catalogs[0], catalogs[2]}, htfs.CatalogsForPlatform(catalogs, "linux_amd64"))
+	must.Equal([]string{catalogs[1]}, htfs.CatalogsForPlatform(catalogs, "WINDOWS_amd64"))
+	must.Equal(0, len(htfs.CatalogsForPlatform(catalogs, "darwin_arm64")))
+}
+
+func TestCanComposeRobotLocalPackagesIntoBlueprint(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	robotdir := t.TempDir()
+	must.Nil(os.MkdirAll(filepath.Join(robotdir, "wheels"), 0o755))
+	must.Nil(os.WriteFile(filepath.Join(robotdir, "wheels", "private-1.0-py3-none-any.whl"), []byte("not really a wheel"), 0o644))
+	must.Nil(os.WriteFile(filepath.Join(robotdir, "conda.yaml"), []byte("channels:\n- conda-forge\ndependencies:\n- python=3.10.12\n"), 0o644))
+	robotfile := filepath.Join(robotdir, "robot.yaml")
+	content := "tasks:\n  run:\n    shell: python -m robot\nartifactsDir: output\ncondaConfigFile: conda.yaml\nlocalPackages:\n- wheels/private-1.0-py3-none-any.whl\n"
+	must.Nil(os.WriteFile(robotfile, []byte(content), 0o644))
+
+	config, blueprint, err := htfs.ComposeFinalBlueprint(nil, robotfile, false)
+	must.Nil(err)
+	valid, err := config.Validate()
+	must.True(valid)
+	must.Nil(err)
+	must.True(strings.Contains(string(blueprint), "private-1.0-py3-none-any.whl#sha256=5012353aa6a0734b468ac4dcad5b802e70382f41303f87cfe89d223e3a6be654"))
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/joshyorko/rcc/common"
 	"github.com/joshyorko/rcc/fail"
 	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/pretty"
 )
 
 func justFileExistCheck(location string, path, name, digest string) anywork.Work {
@@ -98,32 +99,17 @@ func CheckHasher(known map[string]map[string]bool) Filetask {
 			if !ok {
 				defer anywork.Backlog(RemoveFile(fullpath))
 			}
-			source, err := os.Open(fullpath)
-			if err != nil {
-				anywork.Backlog(RemoveFile(fullpath))
-				panic(fmt.Sprintf("Open[check] %q, reason: %v", fullpath, err))
-			}
-			defer source.Close()
-
-			var reader io.ReadCloser
-			reader, err = gzip.NewReader(source)
-			if err != nil {
-				_, err = source.Seek(0, 0)
-				fail.On(err != nil, "Failed to seek %q -> %v", fullpath, err)
-				reader = source
-			}
-			digest := common.NewDigester(Compress())
-			_, err = io.Copy(digest, reader)
+			digest, err := blobFileDigest(fullpath, details.Name)
 			if err != nil {
 				anywork.Backlog(RemoveFile(fullpath))
-				panic(fmt.Sprintf("Copy[check] %q, reason: %v", fullpath, err))
+				panic(fmt.Sprintf("Digest[check] %q, reason: %v", fullpath, err))
 			}
-			details.Digest = fmt.Sprintf("%02x", digest.Sum(nil))
+			details.Digest = digest
 		}
 	}
 }
 
-func Locator(seek string) Filetask {
+func Locator(seek, algorithm string) Filetask {
 	return func(fullpath string, details *File) anywork.Work {
 		return func() {
 			source, err := os.Open(fullpath)
@@ -131,7 +117,7 @@ func Locator(seek string) Filetask {
 				panic(fmt.Sprintf("Open[Locator] %q, reason: %v", fullpath, err))
 			}
 			defer source.Close()
-			digest := common.NewDigester(Compress())
+			digest := NewDigest(algorithm)
 			locator := RelocateWriter(digest, seek)
 			_, err = io.Copy(locator, source)
 			if err != nil {
@@ -256,7 +242,7 @@ func LiftFile(sourcename, sinkname string, compress bool) anywork.Work {
 	}
 }
 
-func DropFile(library Library, digest, sinkname string, details *File, rewrite []byte) anywork.Work {
+func DropFile(library Library, digest, sinkname string, details *File, rewrite []byte, algorithm string) anywork.Work {
 	return func() {
 		if details.IsSymlink() {
 			anywork.OnErrPanicCloseAll(restoreSymlink(details.Symlink, sinkname))
@@ -268,14 +254,21 @@ func DropFile(library Library, digest, sinkname string, details *File, rewrite [
 		defer closer()
 		partname := fmt.Sprintf("%s.part%s", sinkname, <-common.Identities)
 		defer os.Remove(partname)
-		sink, err := os.Create(partname)
-		anywork.OnErrPanicCloseAll(err)
+		digester := NewDigest(algorithm)
 
-		digester := common.NewDigester(Compress())
-		many := io.MultiWriter(sink, digester)
+		sink, cloned := clonedSink(reader, partname)
+		if cloned {
+			_, err = io.Copy(digester, sink)
+			anywork.OnErrPanicCloseAll(err, sink)
+		} else {
+			sink, err = os.Create(partname)
+			anywork.OnErrPanicCloseAll(err)
 
-		_, err = io.Copy(many, reader)
-		anywork.OnErrPanicCloseAll(err, sink)
+			many := io.MultiWriter(sink, digester)
+
+			_, err = io.Copy(many, reader)
+			anywork.OnErrPanicCloseAll(err, sink)
+		}
 
 		hexdigest := fmt.Sprintf("%02x", digester.Sum(nil))
 		if digest != hexdigest {
@@ -362,6 +355,7 @@ func restoreSymlink(source, target string) error {
 }
 
 func RestoreDirectory(library Library, fs *Root, current map[string]string, stats *stats) Dirtask {
+	percent := common.RccRestoreVerify()
 	return func(path string, it *Dir) anywork.Work {
 		return func() {
 			if it.Shadow {
@@ -407,10 +401,13 @@ func RestoreDirectory(library Library, fs *Root, current map[string]string, stat
 				info, err := part.Info()
 				anywork.OnErrPanicCloseAll(err)
 				ok = golden && found.Match(info)
+				if ok && len(found.Rewrite) == 0 && sampledForValidation(percent) {
+					ok = stats.Validated(directpath, restoredFileIntact(directpath, found.Digest, fs.Algorithm))
+				}
 				stats.Dirty(!ok)
 				if !ok {
 					common.Trace("* Holotree: update changed file    %q", directpath)
-					anywork.Backlog(DropFile(library, found.Digest, directpath, found, fs.Rewrite()))
+					anywork.Backlog(stats.Timed(DropFile(library, found.Digest, directpath, found, fs.Rewrite(), fs.Algorithm)))
 				}
 			}
 			for name, found := range it.Files {
@@ -419,7 +416,7 @@ func RestoreDirectory(library Library, fs *Root, current map[string]string, stat
 				if !seen {
 					stats.Dirty(true)
 					common.Trace("* Holotree: add missing file       %q", directpath)
-					anywork.Backlog(DropFile(library, found.Digest, directpath, found, fs.Rewrite()))
+					anywork.Backlog(stats.Timed(DropFile(library, found.Digest, directpath, found, fs.Rewrite(), fs.Algorithm)))
 				}
 			}
 		}
@@ -532,19 +529,28 @@ func LoadCatalogs() ([]string, Roots) {
 	common.TimelineBegin("catalog load start")
 	defer common.TimelineEnd()
 	catalogs := CatalogNames()
-	roots := make(Roots, len(catalogs))
+	compacted := compactedCatalogs(catalogs)
+	roots := make(Roots, len(catalogs)+len(compacted))
+	counter := pretty.NewCounter("Loading catalogs", len(roots))
+	defer counter.Done()
 	for at, catalog := range catalogs {
 		fullpath := filepath.Join(common.HololibCatalogLocation(), catalog)
-		anywork.Backlog(CatalogLoader(fullpath, at, roots))
+		anywork.Backlog(CatalogLoader(fullpath, at, roots, counter))
 		catalogs[at] = fullpath
 	}
+	for _, catalog := range compacted {
+		fullpath := filepath.Join(common.HololibCatalogLocation(), catalog.name)
+		anywork.Backlog(catalog.loader(fullpath, len(catalogs), roots, counter))
+		catalogs = append(catalogs, fullpath)
+	}
 	runtime.Gosched()
 	anywork.Sync()
 	return catalogs, ignoreFailedCatalogs(roots)
 }
 
-func CatalogLoader(catalog string, at int, roots Roots) anywork.Work {
+func CatalogLoader(catalog string, at int, roots Roots, counter *pretty.Counter) anywork.Work {
 	return func() {
+		defer counter.Tick()
 		tempdir := filepath.Join(common.ProductTemp(), "shadow")
 		shadow, err := NewRoot(tempdir)
 		if err != nil {
@@ -2,6 +2,7 @@ package htfs
 
 import (
 	"archive/zip"
+	"compress/flate"
 	"fmt"
 	"io"
 	"os"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/joshyorko/rcc/cloud"
 	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/conda"
 	"github.com/joshyorko/rcc/fail"
 	"github.com/joshyorko/rcc/journal"
 	"github.com/joshyorko/rcc/pathlib"
@@ -25,7 +27,9 @@ const (
 )
 
 var (
-	motherTime = time.Unix(epoc, 0)
+	motherTime     = time.Unix(epoc, 0)
+	ExportLevel    = flate.DefaultCompression
+	ExportProgress io.Writer
 )
 
 type stats struct {
@@ -34,6 +38,11 @@ type stats struct {
 	dirty     uint64
 	links     uint64
 	duplicate uint64
+	sampled   uint64
+	broken    uint64
+	corrupted []string
+	written   uint64
+	writing   time.Duration
 }
 
 func (it *stats) Dirtyness() float64 {
@@ -103,6 +112,10 @@ type hololib struct {
 }
 
 func (it *hololib) Open(digest string) (readable io.Reader, closer Closer, err error) {
+	err = streamedBlob(digest)
+	if err != nil {
+		return nil, nil, err
+	}
 	return delegateOpen(it, digest, Compress())
 }
 
@@ -145,7 +158,8 @@ func (it *hololib) Stage() string {
 
 type zipseen struct {
 	*zip.Writer
-	seen map[string]bool
+	seen    map[string]bool
+	summary *TransferSummary
 }
 
 func (it zipseen) Ignore(relativepath string) {
@@ -156,6 +170,7 @@ func (it zipseen) Add(fullpath, relativepath string) (err error) {
 	defer fail.Around(&err)
 
 	if it.seen[relativepath] {
+		it.summary.Skip()
 		return nil
 	}
 	it.seen[relativepath] = true
@@ -165,43 +180,100 @@ func (it zipseen) Add(fullpath, relativepath string) (err error) {
 	defer source.Close()
 	target, err := it.Create(relativepath)
 	fail.On(err != nil, "Could not create: %q -> %v", relativepath, err)
-	_, err = io.Copy(target, source)
+	blob := strings.HasPrefix(filepath.ToSlash(relativepath), "library/")
+	if !blob {
+		size, err := io.Copy(target, source)
+		fail.On(err != nil, "Copy failure: %q -> %q -> %v", fullpath, relativepath, err)
+		it.summary.File(size, false)
+		return nil
+	}
+	counted := &countingWriter{}
+	tee := io.TeeReader(source, io.MultiWriter(target, counted))
+	digest, err := BlobDigest(tee, filepath.Base(fullpath), func() (io.ReadCloser, error) {
+		return os.Open(fullpath)
+	})
+	if err == nil {
+		_, err = io.Copy(io.Discard, tee)
+	}
 	fail.On(err != nil, "Copy failure: %q -> %q -> %v", fullpath, relativepath, err)
+	fail.On(digest != filepath.Base(fullpath), "Corrupted blob %q, expected %s, actual %s", fullpath, filepath.Base(fullpath), digest)
+	it.summary.File(counted.size, true)
 	return nil
 }
 
+type countingWriter struct {
+	size int64
+}
+
+func (it *countingWriter) Write(content []byte) (int, error) {
+	it.size += int64(len(content))
+	return len(content), nil
+}
+
 func (it *hololib) Remove(catalogs []string) (err error) {
 	defer fail.Around(&err)
 
 	common.TimelineBegin("holotree remove start")
 	defer common.TimelineEnd()
 
+	lockfile := common.HolotreeLock()
+	completed := pathlib.LockWaitMessage(lockfile, "Serialized catalog removal [holotree lock]")
+	locker, err := pathlib.Locker(lockfile, 30000, common.SharedHolotree)
+	completed()
+	fail.On(err != nil, "Could not get lock for holotree. Quiting.")
+	defer locker.Release()
+
+	RecoverMutations()
+
+	removable := make([]string, 0, len(catalogs))
 	for _, name := range catalogs {
 		catalog := filepath.Join(common.HololibCatalogLocation(), name)
+		if !pathlib.IsFile(catalog) {
+			expandCatalog(name)
+		}
 		if !pathlib.IsFile(catalog) {
 			pretty.Warning("Catalog %s (%s) is not a file! Ignored!", name, catalog)
 			continue
 		}
+		removable = append(removable, catalog)
+	}
+	mutation, err := BeginMutation(MutationDelete, removable, nil)
+	fail.On(err != nil, "%v", err)
+	for _, catalog := range removable {
 		err := os.Remove(catalog)
-		fail.On(err != nil, "Could not remove catalog %s [filename: %q]", name, catalog)
+		fail.On(err != nil, "Could not remove catalog %s [filename: %q]", filepath.Base(catalog), catalog)
 	}
-	return nil
+	return mutation.Commit()
 }
 
 func (it *hololib) Export(catalogs, known []string, archive string) (err error) {
 	defer fail.Around(&err)
+	defer pathlib.TrackActivity("export")()
 
 	common.TimelineBegin("holotree export start")
 	defer common.TimelineEnd()
 
+	err = ExpandCatalogs(append(append([]string{}, known...), catalogs...))
+	fail.Fast(err)
+
 	writer, err := os.Create(archive)
 	fail.On(err != nil, "Could not create archive %q.", archive)
 
+	var sink io.Writer = writer
+	if ExportProgress != nil {
+		sink = io.MultiWriter(writer, ExportProgress)
+	}
+	summary := NewTransferSummary("export", archive)
 	zipper := &zipseen{
-		zip.NewWriter(writer),
+		zip.NewWriter(sink),
 		make(map[string]bool),
+		summary,
 	}
 	defer zipper.Close()
+	level := ExportLevel
+	zipper.RegisterCompressor(zip.Deflate, func(out io.Writer) (io.WriteCloser, error) {
+		return flate.NewWriter(out, level)
+	})
 
 	exported := false
 
@@ -231,13 +303,38 @@ func (it *hololib) Export(catalogs, known []string, archive string) (err error)
 		fail.On(err != nil, "Could not get relative location for catalog -> %v.", err)
 		err = zipper.Add(catalog, relative)
 		fail.On(err != nil, "Could not add catalog to zip -> %v.", err)
+		summary.Catalog()
+
+		for _, entry := range fs.LocalPackages {
+			source, ok := conda.LocalPackageFile(entry)
+			if !ok || !pathlib.IsFile(source) {
+				pretty.Warning("Local package %q of catalog %s is not available, so it is not exported.", entry, name)
+				continue
+			}
+			relative, err := filepath.Rel(common.Product.Home(), source)
+			fail.On(err != nil, "Could not get relative location for local package -> %v.", err)
+			err = zipper.Add(source, relative)
+			fail.On(err != nil, "Could not add local package to zip -> %v.", err)
+		}
 
 		exported = true
 	}
 	fail.On(!exported, "None of given catalogs were available for export!")
+	fail.On(zipper.Close() != nil, "Could not finalize archive %q.", archive)
+	summary.Done()
 	return nil
 }
 
+// blueprintLocalPackages lists local packages of blueprint, so that catalog
+// knows which local packages to export with it.
+func blueprintLocalPackages(blueprint []byte) []string {
+	environment, err := conda.CondaYamlFrom(blueprint)
+	if err != nil {
+		return nil
+	}
+	return environment.LocalPackages
+}
+
 func (it *hololib) Record(blueprint []byte) error {
 	defer common.Stopwatch("Holotree recording took:").Debug()
 	err := it.WriteIdentity(blueprint)
@@ -255,14 +352,24 @@ func (it *hololib) Record(blueprint []byte) error {
 	if err != nil {
 		return err
 	}
-	common.Timeline("holotree (re)locator start")
-	err = fs.AllFiles(Locator(it.Identity()))
+	fs.Algorithm = SelectedDigest()
+	indexfile := liftIndexFilename(it.Identity())
+	previous := loadLiftIndex(indexfile, fs.Path, it.Identity(), fs.Algorithm)
+	reuse := &liftScore{}
+	common.Timeline("holotree (re)locator start [%s digests]", DigestName(fs.Algorithm))
+	err = fs.AllFiles(IncrementalLocator(fs.Path, it.Identity(), fs.Algorithm, previous, reuse))
 	if err != nil {
 		return err
 	}
-	common.Timeline("holotree (re)locator done")
-	fs.Blueprint = key
+	common.Timeline("holotree (re)locator done (reused: %d, hashed: %d)", reuse.reused, reuse.hashed)
+	common.Debug("Holotree (re)locator reused %d and hashed %d files.", reuse.reused, reuse.hashed)
+	fs.Blueprint, fs.Machine = key, CurrentMachine()
+	fs.LocalPackages = blueprintLocalPackages(blueprint)
 	catalog := it.CatalogPath(key)
+	mutation, err := BeginMutation(MutationLift, newCatalogs(catalog), nil)
+	if err != nil {
+		return err
+	}
 	err = fs.SaveAs(catalog)
 	if err != nil {
 		return err
@@ -271,19 +378,73 @@ func (it *hololib) Record(blueprint []byte) error {
 	common.Timeline("holotree lift start %q", catalog)
 	err = fs.Treetop(ScheduleLifters(it, score))
 	common.Timeline("holotree lift done")
+	if err == nil {
+		warning := newLiftIndex(fs, it.Identity(), 0).saveAs(indexfile)
+		if warning != nil {
+			common.Debug("Could not save lift index %q, reason: %v", indexfile, warning)
+		}
+	}
 	defer common.Timeline("- new %d/%d (duplicate: %d, links: %d)", score.dirty, score.total, score.duplicate, score.links)
 	common.Debug("Holotree new workload: %d/%d\n", score.dirty, score.total)
-	return err
+	return mutation.Complete(err)
 }
 
 func CatalogName(key string) string {
-	return fmt.Sprintf("%sv12.%s", key, common.Platform())
+	return fmt.Sprintf("%s%s", CatalogPrefix(key), common.Platform())
+}
+
+// CatalogPrefix is common start of catalog names of blueprint, on all
+// platforms.
+func CatalogPrefix(key string) string {
+	return fmt.Sprintf("%sv12.", key)
 }
 
 func (it *hololib) CatalogPath(key string) string {
 	return filepath.Join(common.HololibCatalogLocation(), CatalogName(key))
 }
 
+// completeStreamedCatalog moves streamed catalog from streaming area into
+// hololib catalogs, when all of its blobs are in hololib. Blobs are never
+// fetched here; only restore fetches blobs of files it writes, so until some
+// restore has needed all of them, catalog stays staged and its blueprint is
+// not seen as being available in hololib. Tells if catalog was moved.
+func (it *hololib) completeStreamedCatalog(key, staged, catalog string) (bool, error) {
+	shadow, err := NewRoot(filepath.Join(common.ProductTemp(), "shadow"))
+	if err != nil {
+		return false, err
+	}
+	err = shadow.LoadFrom(staged)
+	if err != nil {
+		return false, err
+	}
+	missing := 0
+	eachFile(shadow.Tree, func(details *File) error {
+		if !details.IsSymlink() && !pathlib.IsFile(it.ExactLocation(details.Digest)) {
+			missing++
+		}
+		return nil
+	})
+	if missing > 0 {
+		common.Debug("Streamed catalog %s stays staged, since %d of its blobs are not fetched yet.", filepath.Base(catalog), missing)
+		return false, nil
+	}
+	mutation, err := BeginMutation(MutationImport, newCatalogs(catalog), nil)
+	if err != nil {
+		return false, err
+	}
+	err = pathlib.TryRename("catalog", staged, catalog)
+	if err != nil {
+		return false, err
+	}
+	delete(it.queryCache, key)
+	record := &CatalogVerification{Mode: PullVerifyMode(), Source: common.RccRemoteOrigin()}
+	err = RecordCatalogVerification(common.HololibLocation(), []string{filepath.Base(catalog)}, record)
+	if err != nil {
+		common.Debug("Could not record catalog verification, reason: %v", err)
+	}
+	return true, mutation.Commit()
+}
+
 func (it *hololib) ValidateBlueprint(blueprint []byte) error {
 	return nil
 }
@@ -306,7 +467,7 @@ func (it *hololib) queryBlueprint(key string) bool {
 	common.Timeline("holotree blueprint query")
 	catalog := it.CatalogPath(key)
 	if !pathlib.IsFile(catalog) {
-		return false
+		return isCompacted(CatalogName(key))
 	}
 	tempdir := filepath.Join(common.ProductTemp(), key)
 	shadow, err := NewRoot(tempdir)
@@ -329,6 +490,70 @@ func (it *hololib) queryBlueprint(key string) bool {
 	return pathlib.IsFile(catalog)
 }
 
+func ExportEstimate(catalogs, known []string) (count int, size int64, err error) {
+	defer fail.Around(&err)
+
+	digests := make(map[string]bool)
+	var collect func(*Dir, bool)
+	collect = func(it *Dir, include bool) {
+		for _, file := range it.Files {
+			if include && !digests[file.Digest] {
+				count, size = count+1, size+librarySize(file.Digest)
+			}
+			digests[file.Digest] = true
+		}
+		for _, subdir := range it.Dirs {
+			collect(subdir, include)
+		}
+	}
+	for _, name := range known {
+		fs, err := NewRoot(".")
+		fail.On(err != nil, "Could not create root location -> %v.", err)
+		if fs.LoadFrom(filepath.Join(common.HololibCatalogLocation(), name)) == nil {
+			collect(fs.Tree, false)
+		}
+	}
+	for _, name := range catalogs {
+		catalog := filepath.Join(common.HololibCatalogLocation(), name)
+		fs, err := NewRoot(".")
+		fail.On(err != nil, "Could not create root location -> %v.", err)
+		err = fs.LoadFrom(catalog)
+		fail.On(err != nil, "Could not load catalog from %s -> %v.", catalog, err)
+		collect(fs.Tree, true)
+		stat, err := os.Stat(catalog)
+		if err == nil {
+			count, size = count+1, size+stat.Size()
+		}
+	}
+	return count, size, nil
+}
+
+func librarySize(digest string) int64 {
+	stat, err := os.Stat(ExactDefaultLocation(digest))
+	if err != nil {
+		return 0
+	}
+	return stat.Size()
+}
+
+func CatalogPlatform(catalog string) string {
+	parts := strings.SplitN(filepath.Base(catalog), ".", 2)
+	if len(parts) != 2 {
+		return ""
+	}
+	return strings.ToLower(parts[1])
+}
+
+func CatalogsForPlatform(catalogs []string, platform string) []string {
+	result := make([]string, 0, len(catalogs))
+	for _, catalog := range catalogs {
+		if CatalogPlatform(catalog) == strings.ToLower(platform) {
+			result = append(result, catalog)
+		}
+	}
+	return result
+}
+
 func CatalogNames() []string {
 	result := make([]string, 0, 10)
 	for _, catalog := range pathlib.Glob(common.HololibCatalogLocation(), "[0-9a-f]*v12.*") {
@@ -383,13 +608,19 @@ func (it *hololib) RestoreTo(blueprint []byte, label, controller, space string,
 	defer common.Stopwatch("Holotree restore took:").Debug()
 
 	key := common.BlueprintHash(blueprint)
-	catalog := it.CatalogPath(key)
+	catalog := expandedCatalog(it, key)
+	source, streamed := catalog, false
+	if !pathlib.IsFile(catalog) {
+		source = filepath.Join(common.HololibStreamingLocation(), CatalogName(key))
+		streamed = pathlib.IsFile(source)
+	}
 	common.TimelineBegin("holotree space restore start [%s]", key)
 	defer common.TimelineEnd()
 	fs, err := NewRoot(it.Stage())
 	fail.On(err != nil, "Failed to create stage -> %v", err)
-	err = fs.LoadFrom(catalog)
-	fail.On(err != nil, "Failed to load catalog %s -> %v", catalog, err)
+	err = fs.LoadFrom(source)
+	fail.On(err != nil, "Failed to load catalog %s -> %v", source, err)
+	fail.Fast(checkAffinity(source, fs.Machine))
 	targetdir := filepath.Join(fs.HolotreeBase(), label)
 	metafile := fmt.Sprintf("%s.meta", targetdir)
 	lockfile := fmt.Sprintf("%s.lck", targetdir)
@@ -417,6 +648,7 @@ func (it *hololib) RestoreTo(blueprint []byte, label, controller, space string,
 	}
 	common.Timeline("mode: %s", mode)
 	common.Debug("Holotree operating mode is: %s", mode)
+	samestage := fs.Path == targetdir
 	err = fs.Relocate(targetdir)
 	fail.On(err != nil, "Failed to relocate %s -> %v", targetdir, err)
 	common.TimelineBegin("holotree make branches start")
@@ -424,17 +656,34 @@ func (it *hololib) RestoreTo(blueprint []byte, label, controller, space string,
 	common.TimelineEnd()
 	fail.On(err != nil, "Failed to make branches -> %v", err)
 	score := &stats{}
+	clonedBefore := ClonedFiles()
 	common.TimelineBegin("holotree restore start")
 	err = fs.AllDirs(RestoreDirectory(it, fs, currentstate, score))
 	fail.On(err != nil, "Failed to restore directories -> %v", err)
 	common.TimelineEnd()
+	if cloned := ClonedFiles() - clonedBefore; cloned > 0 {
+		common.Timeline("- cloned %d files (copy-on-write)", cloned)
+	}
 	defer common.Timeline("- dirty %d/%d (duplicate: %d, links: %d)", score.dirty, score.total, score.duplicate, score.links)
 	common.Debug("Holotree dirty workload: %d/%d\n", score.dirty, score.total)
 	journal.CurrentBuildEvent().Dirty(score.Dirtyness())
+	score.reportValidation(targetdir)
+	score.reportAntivirus(targetdir)
 	fs.Controller = controller
 	fs.Space = space
 	err = fs.SaveAs(metafile)
 	fail.On(err != nil, "Failed to save metafile %q -> %v", metafile, err)
+	if partial && samestage {
+		indexfile := liftIndexFilename(it.Identity())
+		warning := newLiftIndex(fs, it.Identity(), motherTime.UnixNano()).saveAs(indexfile)
+		if warning != nil {
+			common.Debug("Could not save lift index %q, reason: %v", indexfile, warning)
+		}
+	}
+	if streamed {
+		_, err = it.completeStreamedCatalog(key, source, catalog)
+		fail.On(err != nil, "Failed to complete streamed catalog %s -> %v", catalog, err)
+	}
 	pathlib.TouchWhen(catalog, time.Now())
 	planfile := filepath.Join(targetdir, "rcc_plan.log")
 	if !partial && pathlib.FileExist(planfile) {
@@ -0,0 +1,61 @@
+package htfs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/hamlet"
+	"github.com/joshyorko/rcc/pathlib"
+)
+
+func TestStreamedCatalogStaysOutOfHololibUntilComplete(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	t.Setenv("ROBOCORP_HOME", t.TempDir())
+	tree, err := New()
+	must.Nil(err)
+	library := tree.(*hololib)
+
+	key := "0123456789abcdef"
+	digest := hexdigest("", []byte("streamed content"))
+	root, err := NewRoot(filepath.Join(t.TempDir(), "space"))
+	must.Nil(err)
+	root.Blueprint = key
+	root.Tree.Files["content.txt"] = &File{Name: "content.txt", Digest: digest}
+	staged := filepath.Join(common.HololibStreamingLocation(), CatalogName(key))
+	catalog := filepath.Join(common.HololibCatalogLocation(), CatalogName(key))
+	must.Nil(root.SaveAs(staged))
+
+	moved, err := library.completeStreamedCatalog(key, staged, catalog)
+	must.Nil(err)
+	wont.True(moved)
+	must.True(pathlib.IsFile(staged))
+	wont.True(pathlib.IsFile(catalog))
+
+	location := ExactDefaultLocation(digest)
+	must.Nil(os.MkdirAll(filepath.Dir(location), 0o755))
+	must.Nil(os.WriteFile(location, []byte("streamed content"), 0o644))
+	moved, err = library.completeStreamedCatalog(key, staged, catalog)
+	must.Nil(err)
+	must.True(moved)
+	wont.True(pathlib.IsFile(staged))
+	must.True(pathlib.IsFile(catalog))
+}
+
+func TestInterruptedLiftKeepsExistingCatalog(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	t.Setenv("ROBOCORP_HOME", t.TempDir())
+	existing := saveTestCatalog(t, "0123456789abcdef", true)
+	fresh := filepath.Join(common.HololibCatalogLocation(), CatalogName("fedcba9876543210"))
+
+	_, err := BeginMutation(MutationLift, newCatalogs(existing, fresh), nil)
+	must.Nil(err)
+	must.Nil(os.WriteFile(fresh, []byte("partial"), 0o644))
+
+	RecoverMutations()
+	must.True(pathlib.IsFile(existing))
+	wont.True(pathlib.IsFile(fresh))
+}
@@ -0,0 +1,136 @@
+package htfs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/joshyorko/rcc/anywork"
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pathlib"
+)
+
+type (
+	liftEntry struct {
+		Size    int64   `json:"size"`
+		Mtime   int64   `json:"mtime"`
+		Digest  string  `json:"digest"`
+		Rewrite []int64 `json:"rewrite"`
+	}
+
+	liftIndex struct {
+		Path      string                `json:"path"`
+		Seek      string                `json:"seek"`
+		Compress  bool                  `json:"compress"`
+		Algorithm string                `json:"algorithm,omitempty"`
+		Entries   map[string]*liftEntry `json:"entries"`
+	}
+
+	liftScore struct {
+		reused uint64
+		hashed uint64
+	}
+)
+
+func liftIndexFilename(identity string) string {
+	return filepath.Join(common.HololibLiftLocation(), fmt.Sprintf("%s.json", identity))
+}
+
+func loadLiftIndex(filename, path, seek, algorithm string) *liftIndex {
+	blob, err := os.ReadFile(filename)
+	if err != nil {
+		return nil
+	}
+	result := &liftIndex{}
+	err = json.Unmarshal(blob, result)
+	if err != nil {
+		common.Debug("Ignoring broken lift index %q, reason: %v", filename, err)
+		return nil
+	}
+	if result.Path != path || result.Seek != seek || result.Compress != Compress() || result.Algorithm != algorithm || result.Entries == nil {
+		common.Debug("Ignoring lift index %q, since it was made for different stage.", filename)
+		return nil
+	}
+	return result
+}
+
+func (it *liftIndex) lookup(relative string, details *File) (*liftEntry, bool) {
+	if it == nil || details.mtime == 0 {
+		return nil, false
+	}
+	entry, ok := it.Entries[relative]
+	if !ok || entry.Size != details.Size || entry.Mtime != details.mtime {
+		return nil, false
+	}
+	return entry, true
+}
+
+func newLiftIndex(root *Root, seek string, mtime int64) *liftIndex {
+	result := &liftIndex{
+		Path:      root.Path,
+		Seek:      seek,
+		Compress:  Compress(),
+		Algorithm: root.Algorithm,
+		Entries:   make(map[string]*liftEntry),
+	}
+	result.collect("", root.Tree, mtime)
+	return result
+}
+
+func (it *liftIndex) collect(prefix string, dir *Dir, mtime int64) {
+	for name, subdir := range dir.Dirs {
+		it.collect(filepath.Join(prefix, name), subdir, mtime)
+	}
+	for name, file := range dir.Files {
+		stamp := file.mtime
+		if mtime != 0 {
+			stamp = mtime
+		}
+		if stamp == 0 || file.IsSymlink() {
+			continue
+		}
+		it.Entries[filepath.Join(prefix, name)] = &liftEntry{
+			Size:    file.Size,
+			Mtime:   stamp,
+			Digest:  file.Digest,
+			Rewrite: file.Rewrite,
+		}
+	}
+}
+
+func (it *liftIndex) saveAs(filename string) error {
+	blob, err := json.Marshal(it)
+	if err != nil {
+		return err
+	}
+	partname := fmt.Sprintf("%s.part%s", filename, <-common.Identities)
+	defer os.Remove(partname)
+	err = pathlib.WriteFile(partname, blob, 0o644)
+	if err != nil {
+		return err
+	}
+	return pathlib.TryRename("liftindex", partname, filename)
+}
+
+func IncrementalLocator(root, seek, algorithm string, previous *liftIndex, score *liftScore) Filetask {
+	locator := Locator(seek, algorithm)
+	return func(fullpath string, details *File) anywork.Work {
+		relative, err := filepath.Rel(root, fullpath)
+		if err == nil {
+			entry, ok := previous.lookup(relative, details)
+			if ok {
+				atomic.AddUint64(&score.reused, 1)
+				details.Digest = entry.Digest
+				details.Rewrite = entry.Rewrite
+				if details.Rewrite == nil {
+					details.Rewrite = make([]int64, 0)
+				}
+				return func() {}
+			}
+		}
+		atomic.AddUint64(&score.hashed, 1)
+		return locator(fullpath, details)
+	}
+}
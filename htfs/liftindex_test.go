@@ -0,0 +1,66 @@
+package htfs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func liftedRoot(t *testing.T, path string, task Filetask) *Root {
+	root, err := NewRoot(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = root.Lift(); err != nil {
+		t.Fatal(err)
+	}
+	if err = root.AllFiles(task); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestIncrementalLocatorReusesUnchangedFiles(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	stage := t.TempDir()
+	seek := "h0123456789abcdef_0123456789abct"
+	must.Nil(os.MkdirAll(filepath.Join(stage, "lib", "site"), 0o755))
+	must.Nil(os.WriteFile(filepath.Join(stage, "lib", "site", "same.py"), []byte("print('same')"), 0o644))
+	must.Nil(os.WriteFile(filepath.Join(stage, "lib", "changed.py"), []byte("print('before')"), 0o644))
+	must.Nil(os.WriteFile(filepath.Join(stage, "located.txt"), []byte("/holotree/"+seek+"/bin/python"), 0o644))
+
+	first := liftedRoot(t, stage, Locator(seek, ""))
+	indexfile := filepath.Join(t.TempDir(), "lift.json")
+	must.Nil(newLiftIndex(first, seek, 0).saveAs(indexfile))
+
+	wont.Nil(loadLiftIndex(indexfile, stage, seek, ""))
+	must.Nil(loadLiftIndex(indexfile, stage, "h0123456789abcdef_fedcba987654t", ""))
+	must.Nil(loadLiftIndex(indexfile, t.TempDir(), seek, ""))
+	must.Nil(loadLiftIndex(indexfile, stage, seek, DigestBlake3))
+
+	later := time.Now().Add(time.Minute)
+	changed := filepath.Join(stage, "lib", "changed.py")
+	must.Nil(os.WriteFile(changed, []byte("print('after!')"), 0o644))
+	must.Nil(os.Chtimes(changed, later, later))
+
+	score := &liftScore{}
+	second := liftedRoot(t, stage, IncrementalLocator(stage, seek, "", loadLiftIndex(indexfile, stage, seek, ""), score))
+	must.Equal(uint64(2), score.reused)
+	must.Equal(uint64(1), score.hashed)
+
+	fresh := liftedRoot(t, stage, Locator(seek, ""))
+	must.Equal(fresh.Tree.Dirs["lib"].Dirs["site"].Files["same.py"].Digest, second.Tree.Dirs["lib"].Dirs["site"].Files["same.py"].Digest)
+	must.Equal(fresh.Tree.Dirs["lib"].Files["changed.py"].Digest, second.Tree.Dirs["lib"].Files["changed.py"].Digest)
+	must.Equal(1, len(second.Tree.Files["located.txt"].Rewrite))
+	must.Equal(fresh.Tree.Files["located.txt"].Rewrite, second.Tree.Files["located.txt"].Rewrite)
+	wont.Equal(first.Tree.Dirs["lib"].Files["changed.py"].Digest, second.Tree.Dirs["lib"].Files["changed.py"].Digest)
+
+	score = &liftScore{}
+	liftedRoot(t, stage, IncrementalLocator(stage, seek, "", nil, score))
+	must.Equal(uint64(0), score.reused)
+	must.Equal(uint64(3), score.hashed)
+}
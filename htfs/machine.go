@@ -0,0 +1,101 @@
+package htfs
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/conda"
+	"github.com/joshyorko/rcc/pretty"
+)
+
+var (
+	machineOnce sync.Once
+	thisMachine *Machine
+)
+
+// Machine is fingerprint of machine where catalog was built. It is used to
+// detect catalogs which are restored onto incompatible machines (like ones
+// with older glibc than what built environment expects).
+type Machine struct {
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+	Glibc     string `json:"glibc,omitempty"`
+	LongPaths bool   `json:"longpaths"`
+}
+
+// CurrentMachine is fingerprint of this machine, detected once per process.
+func CurrentMachine() *Machine {
+	machineOnce.Do(func() {
+		thisMachine = &Machine{
+			OS:        runtime.GOOS,
+			Arch:      runtime.GOARCH,
+			Glibc:     glibcVersion(),
+			LongPaths: conda.HasLongPathSupport(),
+		}
+	})
+	return thisMachine
+}
+
+func olderVersion(left, right string) bool {
+	lefties, righties := strings.Split(left, "."), strings.Split(right, ".")
+	for at := range max(len(lefties), len(righties)) {
+		first, second := 0, 0
+		if at < len(lefties) {
+			first, _ = strconv.Atoi(lefties[at])
+		}
+		if at < len(righties) {
+			second, _ = strconv.Atoi(righties[at])
+		}
+		if first != second {
+			return first < second
+		}
+	}
+	return false
+}
+
+// Mismatches lists reasons, why catalog built on recorded machine might not
+// work on this (current) machine. Catalogs without fingerprint are accepted.
+func (it *Machine) Mismatches(current *Machine) []string {
+	result := []string{}
+	if it == nil || current == nil {
+		return result
+	}
+	if it.OS != current.OS {
+		result = append(result, fmt.Sprintf("operating system: catalog %q, machine %q", it.OS, current.OS))
+	}
+	if it.Arch != current.Arch {
+		result = append(result, fmt.Sprintf("architecture: catalog %q, machine %q", it.Arch, current.Arch))
+	}
+	if len(it.Glibc) > 0 && len(current.Glibc) == 0 {
+		result = append(result, fmt.Sprintf("glibc: catalog needs %q, machine has no glibc", it.Glibc))
+	}
+	if len(it.Glibc) > 0 && len(current.Glibc) > 0 && olderVersion(current.Glibc, it.Glibc) {
+		result = append(result, fmt.Sprintf("glibc: catalog needs %q, machine has older %q", it.Glibc, current.Glibc))
+	}
+	if it.LongPaths && !current.LongPaths {
+		result = append(result, "long path support: catalog was built with it, machine does not have it")
+	}
+	return result
+}
+
+// checkAffinity warns (or in strict mode, refuses) when catalog is restored
+// onto machine, which does not match machine that built that catalog.
+func checkAffinity(catalog string, recorded *Machine) error {
+	mismatches := recorded.Mismatches(CurrentMachine())
+	if len(mismatches) == 0 {
+		return nil
+	}
+	common.RunJournal("holotree", "affinity", "catalog %q machine mismatches: %s", catalog, strings.Join(mismatches, "; "))
+	if common.StrictFlag {
+		return fmt.Errorf("Refusing to restore catalog %q in strict mode, because it was built on incompatible machine: %s", catalog, strings.Join(mismatches, "; "))
+	}
+	pretty.Warning("Catalog %q was built on different kind of machine, and environment might not work here:", catalog)
+	for _, mismatch := range mismatches {
+		pretty.Warning("- %s", mismatch)
+	}
+	return nil
+}
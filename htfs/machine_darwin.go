@@ -0,0 +1,5 @@
+package htfs
+
+func glibcVersion() string {
+	return ""
+}
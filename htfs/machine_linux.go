@@ -0,0 +1,20 @@
+package htfs
+
+import (
+	"strings"
+
+	"github.com/joshyorko/rcc/shell"
+)
+
+// glibcVersion is empty on non-glibc (like musl based) systems.
+func glibcVersion() string {
+	output, code, err := shell.New(nil, ".", "getconf", "GNU_LIBC_VERSION").CaptureOutput()
+	if err != nil || code != 0 {
+		return ""
+	}
+	fields := strings.Fields(output)
+	if len(fields) != 2 || fields[0] != "glibc" {
+		return ""
+	}
+	return fields[1]
+}
@@ -0,0 +1,57 @@
+package htfs
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestMachineFingerprintMismatches(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	current := CurrentMachine()
+	must.Equal(runtime.GOOS, current.OS)
+	must.Equal(runtime.GOARCH, current.Arch)
+	must.Equal(0, len(current.Mismatches(current)))
+
+	var unknown *Machine
+	must.Equal(0, len(unknown.Mismatches(current)))
+
+	host := &Machine{OS: "linux", Arch: "amd64", Glibc: "2.31"}
+	must.Equal(0, len((&Machine{OS: "linux", Arch: "amd64", Glibc: "2.28"}).Mismatches(host)))
+	must.Equal(0, len((&Machine{OS: "linux", Arch: "amd64", Glibc: "2.31"}).Mismatches(host)))
+
+	mismatches := (&Machine{OS: "linux", Arch: "amd64", Glibc: "2.35"}).Mismatches(host)
+	must.Equal(1, len(mismatches))
+	must.True(strings.Contains(mismatches[0], `machine has older "2.31"`))
+
+	mismatches = (&Machine{OS: "linux", Arch: "amd64", Glibc: "2.35"}).Mismatches(&Machine{OS: "linux", Arch: "amd64"})
+	must.Equal(1, len(mismatches))
+	must.True(strings.Contains(mismatches[0], "no glibc"))
+
+	mismatches = (&Machine{OS: "windows", Arch: "arm64", LongPaths: true}).Mismatches(&Machine{OS: "linux", Arch: "amd64"})
+	must.Equal(3, len(mismatches))
+
+	wont.True(olderVersion("2.10", "2.9"))
+	must.True(olderVersion("2.9", "2.10"))
+	wont.True(olderVersion("2.31", "2.31"))
+}
+
+func TestStrictModeRefusesIncompatibleCatalogs(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	defer func(original bool) {
+		common.StrictFlag = original
+	}(common.StrictFlag)
+
+	foreign := &Machine{OS: "plan9", Arch: runtime.GOARCH}
+	common.StrictFlag = false
+	must.Nil(checkAffinity("foreign", foreign))
+	must.Nil(checkAffinity("compatible", CurrentMachine()))
+	common.StrictFlag = true
+	wont.Nil(checkAffinity("foreign", foreign))
+	must.Nil(checkAffinity("legacy", nil))
+}
@@ -0,0 +1,218 @@
+package htfs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/fail"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/pretty"
+)
+
+const (
+	MutationLift    = "lift"
+	MutationImport  = "import"
+	MutationDelete  = "delete"
+	MutationCompact = "compact"
+)
+
+var (
+	partfilePattern = regexp.MustCompile(`\.part#\d+$`)
+)
+
+// Mutation is write-ahead journal entry for hololib operation. It is written
+// before hololib is changed, and removed when operation is complete. If rcc
+// gets killed in between, entry remains, and next invocation (holding holotree
+// lock) can finish or undo that operation.
+type Mutation struct {
+	Identity  string   `json:"identity"`
+	Operation string   `json:"operation"`
+	Catalogs  []string `json:"catalogs"`
+	Blobs     []string `json:"blobs,omitempty"`
+	Started   string   `json:"started"`
+	Pid       int      `json:"pid"`
+	filename  string
+}
+
+func mutationFilename(identity string) string {
+	return filepath.Join(common.HololibMutationLocation(), fmt.Sprintf("%s.json", identity))
+}
+
+func writeSynced(filename string, content []byte) (err error) {
+	defer fail.Around(&err)
+
+	partname := fmt.Sprintf("%s.part%s", filename, <-common.Identities)
+	defer os.Remove(partname)
+	sink, err := pathlib.Create(partname)
+	fail.On(err != nil, "Could not create %q, reason: %v", partname, err)
+	_, err = sink.Write(content)
+	if err == nil {
+		err = sink.Sync()
+	}
+	sink.Close()
+	fail.On(err != nil, "Could not write %q, reason: %v", partname, err)
+	return pathlib.TryRename("mutation", partname, filename)
+}
+
+// BeginMutation records intent to change given catalogs and blobs (full paths)
+// in hololib. Blobs are only needed, when they are not written atomically.
+func BeginMutation(operation string, catalogs, blobs []string) (_ *Mutation, err error) {
+	defer fail.Around(&err)
+
+	_, err = pathlib.MakeSharedDir(common.HololibMutationLocation())
+	fail.On(err != nil, "Could not create mutation journal directory, reason: %v", err)
+	identity := fmt.Sprintf("%x_%d", time.Now().UnixNano(), os.Getpid())
+	mutation := &Mutation{
+		Identity:  identity,
+		Operation: operation,
+		Catalogs:  catalogs,
+		Blobs:     blobs,
+		Started:   time.Now().Format(time.RFC3339),
+		Pid:       os.Getpid(),
+		filename:  mutationFilename(identity),
+	}
+	content, err := json.MarshalIndent(mutation, "", "  ")
+	fail.On(err != nil, "Could not serialize hololib mutation, reason: %v", err)
+	err = writeSynced(mutation.filename, content)
+	fail.On(err != nil, "Could not write hololib mutation journal, reason: %v", err)
+	common.Trace("Hololib %s mutation %s started for %d catalogs.", operation, identity, len(catalogs))
+	return mutation, nil
+}
+
+// newCatalogs filters out catalogs which already exist. Only new catalogs can
+// be rolled back by removing them, existing ones must survive recovery.
+func newCatalogs(catalogs ...string) []string {
+	result := make([]string, 0, len(catalogs))
+	for _, catalog := range catalogs {
+		if !pathlib.Exists(catalog) {
+			result = append(result, catalog)
+		}
+	}
+	return result
+}
+
+// Commit marks mutation completed by removing its journal entry.
+func (it *Mutation) Commit() error {
+	common.Trace("Hololib %s mutation %s completed.", it.Operation, it.Identity)
+	return pathlib.TryRemove("mutation", it.filename)
+}
+
+// Complete commits mutation if operation succeeded, otherwise leaves it for recovery.
+func (it *Mutation) Complete(err error) error {
+	if err != nil {
+		return err
+	}
+	return it.Commit()
+}
+
+func loadMutation(filename string) (*Mutation, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	mutation := &Mutation{}
+	err = json.Unmarshal(content, mutation)
+	if err != nil {
+		return nil, err
+	}
+	mutation.filename = filename
+	return mutation, nil
+}
+
+func removePartfiles(directory string) int {
+	removed := 0
+	filepath.WalkDir(directory, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || !partfilePattern.MatchString(entry.Name()) {
+			return nil
+		}
+		if os.Remove(path) == nil {
+			removed += 1
+		}
+		return nil
+	})
+	return removed
+}
+
+// recoverCompaction rolls compaction forward for catalogs that made it into
+// some snapshot set, and keeps catalog files of all others.
+func (it *Mutation) recoverCompaction() (string, error) {
+	for _, catalog := range it.Catalogs {
+		if !pathlib.Exists(catalog) || !isCompacted(filepath.Base(catalog)) {
+			continue
+		}
+		err := pathlib.TryRemove("catalog", catalog)
+		if err != nil {
+			return "", err
+		}
+		os.Remove(catalog + ".info")
+	}
+	return "rolled forward", nil
+}
+
+func (it *Mutation) recover() (string, error) {
+	if it.Operation == MutationCompact {
+		return it.recoverCompaction()
+	}
+	for _, catalog := range it.Catalogs {
+		if !pathlib.Exists(catalog) {
+			continue
+		}
+		err := pathlib.TryRemove("catalog", catalog)
+		if err != nil {
+			return "", err
+		}
+	}
+	for _, blob := range it.Blobs {
+		if !pathlib.Exists(blob) {
+			continue
+		}
+		err := pathlib.TryRemove("blob", blob)
+		if err != nil {
+			return "", err
+		}
+	}
+	if it.Operation == MutationDelete {
+		return "rolled forward", nil
+	}
+	return "rolled back", nil
+}
+
+// RecoverMutations finishes or undoes incomplete hololib mutations left behind
+// by killed rcc processes. Incomplete lifts and imports are rolled back by
+// removing their (possibly half written) catalogs and blobs, and incomplete
+// deletes and compactions are rolled forward. Must be called while holding holotree lock.
+func RecoverMutations() {
+	pending := pathlib.Glob(common.HololibMutationLocation(), "*.json")
+	if len(pending) == 0 {
+		return
+	}
+	common.TimelineBegin("hololib mutation recovery start")
+	defer common.TimelineEnd()
+
+	for _, filename := range pending {
+		mutation, err := loadMutation(filename)
+		if err != nil {
+			pretty.Warning("Removing unreadable hololib mutation journal %q, reason: %v", filename, err)
+			pathlib.TryRemove("mutation", filename)
+			continue
+		}
+		outcome, err := mutation.recover()
+		if err != nil {
+			pretty.Warning("Could not recover incomplete hololib %s from %s (pid %d), reason: %v", mutation.Operation, mutation.Started, mutation.Pid, err)
+			continue
+		}
+		pretty.Note("Incomplete hololib %s from %s (pid %d) was %s [%d catalogs].", mutation.Operation, mutation.Started, mutation.Pid, outcome, len(mutation.Catalogs))
+		common.RunJournal("hololib recovery", mutation.Operation, "%s mutation %s %s", mutation.Operation, mutation.Identity, outcome)
+		mutation.Commit()
+	}
+	removed := removePartfiles(common.HololibCatalogLocation())
+	removed += removePartfiles(common.HololibLibraryLocation())
+	removed += removePartfiles(common.HololibStreamingLocation())
+	common.Debug("Hololib recovery removed %d leftover part files.", removed)
+}
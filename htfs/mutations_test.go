@@ -0,0 +1,52 @@
+package htfs_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/hamlet"
+	"github.com/joshyorko/rcc/htfs"
+	"github.com/joshyorko/rcc/pathlib"
+)
+
+func TestIncompleteMutationsAreRecovered(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	t.Setenv("ROBOCORP_HOME", t.TempDir())
+	catalogs := common.HololibCatalogLocation()
+	library := common.HololibLibraryLocation()
+	must.Nil(os.MkdirAll(catalogs, 0o755))
+	must.Nil(os.MkdirAll(filepath.Join(library, "ab"), 0o755))
+
+	lifted := filepath.Join(catalogs, "0123456789abcdefv12.linux_amd64")
+	deleted := filepath.Join(catalogs, "fedcba9876543210v12.linux_amd64")
+	kept := filepath.Join(catalogs, "1111111111111111v12.linux_amd64")
+	halfblob := filepath.Join(library, "ab", "abcdef")
+	leftover := filepath.Join(library, "ab", "abcdef.part#42")
+	for _, filename := range []string{lifted, deleted, kept, halfblob, leftover} {
+		must.Nil(os.WriteFile(filename, []byte("partial"), 0o644))
+	}
+
+	completed, err := htfs.BeginMutation(htfs.MutationLift, []string{kept}, nil)
+	must.Nil(err)
+	must.Nil(completed.Complete(nil))
+
+	_, err = htfs.BeginMutation(htfs.MutationLift, []string{lifted}, nil)
+	must.Nil(err)
+	_, err = htfs.BeginMutation(htfs.MutationDelete, []string{deleted}, nil)
+	must.Nil(err)
+	_, err = htfs.BeginMutation(htfs.MutationImport, []string{}, []string{halfblob})
+	must.Nil(err)
+	must.Equal(3, len(pathlib.Glob(common.HololibMutationLocation(), "*.json")))
+
+	htfs.RecoverMutations()
+
+	must.Equal(0, len(pathlib.Glob(common.HololibMutationLocation(), "*.json")))
+	wont.True(pathlib.Exists(lifted))
+	wont.True(pathlib.Exists(deleted))
+	wont.True(pathlib.Exists(halfblob))
+	wont.True(pathlib.Exists(leftover))
+	must.True(pathlib.Exists(kept))
+}
@@ -0,0 +1,174 @@
+package htfs
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/joshyorko/rcc/conda"
+	"github.com/joshyorko/rcc/set"
+)
+
+const (
+	distInfoSuffix = ".dist-info"
+	sitePackages   = "site-packages"
+)
+
+// EnvironmentSummary is package level view of one catalog, computed from
+// catalog metadata (conda-meta and dist-info entries) and its identity.yaml.
+type EnvironmentSummary struct {
+	Catalog   string            `json:"catalog"`
+	Blueprint string            `json:"blueprint"`
+	Platform  string            `json:"platform"`
+	Python    string            `json:"python"`
+	Bytes     uint64            `json:"bytes"`
+	Files     uint64            `json:"files"`
+	Conda     map[string]string `json:"conda"`
+	Pip       map[string]string `json:"pip"`
+	Requested []string          `json:"requested"`
+}
+
+// PackageDelta is one difference between two environments. Empty version
+// means that package is missing from that side.
+type PackageDelta struct {
+	Name  string `json:"name"`
+	Left  string `json:"left"`
+	Right string `json:"right"`
+}
+
+func packageName(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "-", ".", "-").Replace(name))
+}
+
+// condaMetaPackage splits "name-version-build.json" into name and version.
+func condaMetaPackage(filename string) (string, string, bool) {
+	if filepath.Ext(filename) != ".json" {
+		return "", "", false
+	}
+	parts := strings.Split(strings.TrimSuffix(filename, ".json"), "-")
+	if len(parts) < 3 {
+		return "", "", false
+	}
+	return strings.Join(parts[:len(parts)-2], "-"), parts[len(parts)-2], true
+}
+
+// distInfoPackage splits "name-version.dist-info" into name and version.
+func distInfoPackage(dirname string) (string, string, bool) {
+	if !strings.HasSuffix(dirname, distInfoSuffix) {
+		return "", "", false
+	}
+	name, version, ok := strings.Cut(strings.TrimSuffix(dirname, distInfoSuffix), "-")
+	return name, version, ok && len(name) > 0 && len(version) > 0
+}
+
+func (it *Dir) pipPackages(parent string, target map[string]string) {
+	for name, subdir := range it.Dirs {
+		if parent == sitePackages {
+			if pkg, version, ok := distInfoPackage(name); ok {
+				target[packageName(pkg)] = version
+				continue
+			}
+		}
+		subdir.pipPackages(name, target)
+	}
+}
+
+func (it *Root) condaPackages() map[string]string {
+	result := make(map[string]string)
+	meta, ok := it.Tree.Dirs["conda-meta"]
+	if !ok {
+		return result
+	}
+	for filename := range meta.Files {
+		if name, version, ok := condaMetaPackage(filename); ok {
+			result[packageName(name)] = version
+		}
+	}
+	return result
+}
+
+func requestedDependencies(identity []byte) []string {
+	environment, err := conda.CondaYamlFrom(identity)
+	if err != nil {
+		return []string{}
+	}
+	result := make([]string, 0, len(environment.Conda)+len(environment.Pip))
+	for _, dependency := range environment.Conda {
+		result = append(result, dependency.Original)
+	}
+	for _, dependency := range environment.Pip {
+		result = append(result, "pip: "+dependency.Original)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Summary computes package level view of catalog for environment comparison.
+func (it *Root) Summary() (*EnvironmentSummary, error) {
+	stats, err := it.Stats()
+	if err != nil {
+		return nil, err
+	}
+	result := &EnvironmentSummary{
+		Catalog:   filepath.Base(it.Source()),
+		Blueprint: it.Blueprint,
+		Platform:  it.Platform,
+		Bytes:     stats.Bytes,
+		Files:     stats.Files,
+		Conda:     it.condaPackages(),
+		Pip:       make(map[string]string),
+		Requested: []string{},
+	}
+	it.Tree.pipPackages("", result.Pip)
+	result.Python = result.Conda["python"]
+	identity, err := it.Show("identity.yaml")
+	if err == nil {
+		result.Requested = requestedDependencies(identity)
+	}
+	return result, nil
+}
+
+// DiffPackages lists packages which are different (or missing) between two
+// package maps, sorted by name.
+func DiffPackages(left, right map[string]string) []*PackageDelta {
+	result := []*PackageDelta{}
+	for name, version := range left {
+		if right[name] != version {
+			result = append(result, &PackageDelta{name, version, right[name]})
+		}
+	}
+	for name, version := range right {
+		if _, ok := left[name]; !ok {
+			result = append(result, &PackageDelta{name, "", version})
+		}
+	}
+	sort.SliceStable(result, func(first, second int) bool {
+		return result[first].Name < result[second].Name
+	})
+	return result
+}
+
+// RequestedChanges lists requested dependencies, which were added to or removed
+// from recorded identity to make current one.
+func RequestedChanges(recorded, current []byte) ([]string, []string) {
+	removed, added := DiffRequested(requestedDependencies(recorded), requestedDependencies(current))
+	return added, removed
+}
+
+// DiffRequested lists requested dependency specifications, which are only on
+// one side; first result is left only, second is right only.
+func DiffRequested(left, right []string) ([]string, []string) {
+	lefties, righties := set.Membership(left), set.Membership(right)
+	leftOnly, rightOnly := []string{}, []string{}
+	for _, entry := range left {
+		if !righties[entry] {
+			leftOnly = append(leftOnly, entry)
+		}
+	}
+	for _, entry := range right {
+		if !lefties[entry] {
+			rightOnly = append(rightOnly, entry)
+		}
+	}
+	return leftOnly, rightOnly
+}
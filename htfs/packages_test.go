@@ -0,0 +1,60 @@
+package htfs
+
+import (
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestCatalogPackagesAreFoundFromMetadata(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	root := &Root{Info: &Info{Blueprint: "abc", Platform: "linux_amd64"}, Tree: newDir("", "", false)}
+	meta := newDir("conda-meta", "", false)
+	meta.Files["python-3.12.1-hab00c5b_1_cpython.json"] = &File{Name: "python-3.12.1-hab00c5b_1_cpython.json"}
+	meta.Files["ca-certificates-2024.2.2-hbcca054_0.json"] = &File{Name: "ca-certificates-2024.2.2-hbcca054_0.json"}
+	meta.Files["history"] = &File{Name: "history"}
+	root.Tree.Dirs["conda-meta"] = meta
+	site := newDir("site-packages", "", false)
+	site.Dirs["typing_extensions-4.9.0.dist-info"] = newDir("typing_extensions-4.9.0.dist-info", "", false)
+	site.Dirs["requests-2.31.0.dist-info"] = newDir("requests-2.31.0.dist-info", "", false)
+	site.Dirs["requests"] = newDir("requests", "", false)
+	python := newDir("python3.12", "", false)
+	python.Dirs["site-packages"] = site
+	lib := newDir("lib", "", false)
+	lib.Dirs["python3.12"] = python
+	root.Tree.Dirs["lib"] = lib
+
+	conda := root.condaPackages()
+	must.Equal(map[string]string{"python": "3.12.1", "ca-certificates": "2024.2.2"}, conda)
+	pip := make(map[string]string)
+	root.Tree.pipPackages("", pip)
+	must.Equal(map[string]string{"typing-extensions": "4.9.0", "requests": "2.31.0"}, pip)
+
+	_, _, ok := condaMetaPackage("broken.json")
+	wont.True(ok)
+	_, _, ok = distInfoPackage("nodash.dist-info")
+	wont.True(ok)
+}
+
+func TestPackageAndRequestedDifferences(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	left := map[string]string{"python": "3.10.12", "pip": "23.2", "robocorp": "1.0"}
+	right := map[string]string{"python": "3.12.1", "pip": "23.2", "uv": "0.4.0"}
+	deltas := DiffPackages(left, right)
+	must.Equal(3, len(deltas))
+	must.Equal(PackageDelta{"python", "3.10.12", "3.12.1"}, *deltas[0])
+	must.Equal(PackageDelta{"robocorp", "1.0", ""}, *deltas[1])
+	must.Equal(PackageDelta{"uv", "", "0.4.0"}, *deltas[2])
+
+	requested := requestedDependencies([]byte("channels:\n- conda-forge\ndependencies:\n- python=3.12\n- pip=23.2\n- pip:\n  - robocorp==1.0\n"))
+	must.Equal([]string{"pip: robocorp==1.0", "pip=23.2", "python=3.12"}, requested)
+	leftOnly, rightOnly := DiffRequested(requested, []string{"pip=23.2", "python=3.10"})
+	must.Equal([]string{"pip: robocorp==1.0", "python=3.12"}, leftOnly)
+	must.Equal([]string{"python=3.10"}, rightOnly)
+
+	added, removed := RequestedChanges([]byte("dependencies:\n- python=3.10\n- pip=23.2\n"), []byte("dependencies:\n- python=3.12\n- pip=23.2\n"))
+	must.Equal([]string{"python=3.12"}, added)
+	must.Equal([]string{"python=3.10"}, removed)
+}
@@ -0,0 +1,79 @@
+package htfs
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/fail"
+	"github.com/joshyorko/rcc/pathlib"
+)
+
+// ExclusiveBlobs gives digests of those blobs, that are referenced only by
+// given catalogs, and so become garbage once those catalogs are removed.
+func ExclusiveBlobs(catalogs []string) map[string]bool {
+	removing := make(map[string]bool, len(catalogs))
+	for _, catalog := range catalogs {
+		removing[filepath.Base(catalog)] = true
+	}
+	known, _ := LoadHololibHashes()
+	result := make(map[string]bool)
+search:
+	for digest, users := range known {
+		for user := range users {
+			if !removing[filepath.Base(user)] {
+				continue search
+			}
+		}
+		if len(users) > 0 {
+			result[digest] = true
+		}
+	}
+	return result
+}
+
+// BlobsSize gives size of given blobs in hololib library, as stored on disk.
+func BlobsSize(digests map[string]bool) uint64 {
+	total := uint64(0)
+	for digest := range digests {
+		size, ok := pathlib.Size(ExactDefaultLocation(digest))
+		if ok {
+			total += uint64(size)
+		}
+	}
+	return total
+}
+
+// PruneBlobs removes those candidate blobs from hololib library, that no
+// catalog references anymore. References are checked again under holotree
+// lock, so blobs adopted by catalogs built meanwhile are kept. Gives count
+// and size of removed blobs.
+func PruneBlobs(candidates map[string]bool) (count int, size uint64, err error) {
+	defer fail.Around(&err)
+
+	common.TimelineBegin("holotree blob prune start")
+	defer common.TimelineEnd()
+
+	lockfile := common.HolotreeLock()
+	completed := pathlib.LockWaitMessage(lockfile, "Serialized blob pruning [holotree lock]")
+	locker, err := pathlib.Locker(lockfile, 30000, common.SharedHolotree)
+	completed()
+	fail.On(err != nil, "Could not get lock for holotree. Quiting.")
+	defer locker.Release()
+
+	known, _ := LoadHololibHashes()
+	for digest := range candidates {
+		if len(known[digest]) > 0 {
+			continue
+		}
+		location := ExactDefaultLocation(digest)
+		stat, err := os.Stat(location)
+		if err != nil {
+			continue
+		}
+		fail.Fast(pathlib.TryRemove("blob", location))
+		count, size = count+1, size+uint64(stat.Size())
+	}
+	fail.Fast(pathlib.RemoveEmptyDirectores(common.HololibLibraryLocation()))
+	return count, size, nil
+}
@@ -0,0 +1,113 @@
+package htfs
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pathlib"
+)
+
+const (
+	PullVerifyFull  = "full"
+	PullVerifySpot  = "spot"
+	PullVerifyTrust = "trust"
+
+	spotCheckPercent  = 10
+	verificationsFile = "verifications.json"
+)
+
+// CatalogVerification tells how blobs of pulled or imported catalog were
+// verified on arrival. Verified blobs had their digest checked, unverified
+// ones were trusted as is (spot and trust modes).
+type CatalogVerification struct {
+	Mode       string `json:"mode"`
+	Source     string `json:"source"`
+	When       string `json:"when"`
+	Verified   int    `json:"verified"`
+	Unverified int    `json:"unverified"`
+}
+
+// ValidPullVerifyMode tells if mode is known verification mode.
+func ValidPullVerifyMode(mode string) bool {
+	return slices.Contains([]string{PullVerifyFull, PullVerifySpot, PullVerifyTrust}, mode)
+}
+
+// PullVerifyMode gives verification mode of pulled and imported blobs, from
+// --verify option or RCC_PULL_VERIFY. Unknown values fall back to full
+// verification.
+func PullVerifyMode() string {
+	mode := common.RccPullVerify()
+	if ValidPullVerifyMode(mode) {
+		return mode
+	}
+	if len(mode) == 0 {
+		return PullVerifyFull
+	}
+	common.Debug("Unknown pull verification mode %q, using %q instead.", mode, PullVerifyFull)
+	return PullVerifyFull
+}
+
+// VerifiedOnArrival tells if arriving blob should be digest verified in
+// given mode: always in full mode, about every tenth blob in spot mode, and
+// never in trust mode.
+func VerifiedOnArrival(mode string) bool {
+	switch mode {
+	case PullVerifyTrust:
+		return false
+	case PullVerifySpot:
+		return sampledForValidation(spotCheckPercent)
+	}
+	return true
+}
+
+// LoadCatalogVerifications gives recorded verifications of hololib by
+// catalog name. Catalogs built locally have no record.
+func LoadCatalogVerifications(hololib string) map[string]*CatalogVerification {
+	result := make(map[string]*CatalogVerification)
+	content, err := os.ReadFile(filepath.Join(hololib, verificationsFile))
+	if err != nil {
+		return result
+	}
+	err = json.Unmarshal(content, &result)
+	if err != nil {
+		common.Debug("Ignoring catalog verifications, reason: %v", err)
+		return make(map[string]*CatalogVerification)
+	}
+	return result
+}
+
+// RecordCatalogVerification records into hololib how blobs of given catalogs
+// were verified, replacing earlier records of same catalogs.
+func RecordCatalogVerification(hololib string, catalogs []string, record *CatalogVerification) error {
+	if len(record.When) == 0 {
+		record.When = time.Now().Format(time.RFC3339)
+	}
+	known := LoadCatalogVerifications(hololib)
+	for _, catalog := range catalogs {
+		known[catalog] = record
+	}
+	content, err := json.MarshalIndent(known, "", "  ")
+	if err != nil {
+		return err
+	}
+	filename := filepath.Join(hololib, verificationsFile)
+	err = pathlib.WriteFile(filename, content, 0o644)
+	if err != nil {
+		return err
+	}
+	pathlib.MakeSharedFile(filename)
+	return nil
+}
+
+// VerificationLabel gives short description of how catalog was verified,
+// for listings.
+func VerificationLabel(record *CatalogVerification) string {
+	if record == nil {
+		return "-"
+	}
+	return record.Mode
+}
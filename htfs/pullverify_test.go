@@ -0,0 +1,46 @@
+package htfs
+
+import (
+	"testing"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestPullVerifyModeDefaultsToFull(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	t.Setenv(common.RCC_PULL_VERIFY, "")
+	must.Equal(PullVerifyFull, PullVerifyMode())
+	t.Setenv(common.RCC_PULL_VERIFY, "Spot")
+	must.Equal(PullVerifySpot, PullVerifyMode())
+	t.Setenv(common.RCC_PULL_VERIFY, "sometimes")
+	must.Equal(PullVerifyFull, PullVerifyMode())
+	wont.True(ValidPullVerifyMode("sometimes"))
+
+	must.True(VerifiedOnArrival(PullVerifyFull))
+	wont.True(VerifiedOnArrival(PullVerifyTrust))
+	sampled := 0
+	for range 1000 {
+		if VerifiedOnArrival(PullVerifySpot) {
+			sampled++
+		}
+	}
+	must.True(sampled > 20 && sampled < 300)
+}
+
+func TestCatalogVerificationsAreRecordedPerCatalog(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	hololib := t.TempDir()
+	must.Equal(0, len(LoadCatalogVerifications(hololib)))
+	must.Nil(RecordCatalogVerification(hololib, []string{"aaaa.linux_amd64", "bbbb.linux_amd64"}, &CatalogVerification{Mode: PullVerifyFull, Source: "first.zip", Verified: 3}))
+	must.Nil(RecordCatalogVerification(hololib, []string{"bbbb.linux_amd64"}, &CatalogVerification{Mode: PullVerifyTrust, Source: "second.zip", Unverified: 2}))
+
+	known := LoadCatalogVerifications(hololib)
+	must.Equal(2, len(known))
+	must.Equal(PullVerifyFull, VerificationLabel(known["aaaa.linux_amd64"]))
+	must.Equal(PullVerifyTrust, VerificationLabel(known["bbbb.linux_amd64"]))
+	must.Equal("second.zip", known["bbbb.linux_amd64"].Source)
+	must.Equal("-", VerificationLabel(known["cccc.linux_amd64"]))
+}
@@ -0,0 +1,400 @@
+package htfs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/fail"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/joshyorko/rcc/set"
+)
+
+var (
+	// top level hololib entries, which are either rebuilt during relocation
+	// (catalogs), or are caches and state bound to old location
+	unrelocatedHololib = map[string]bool{
+		"catalog":       true,
+		"catalog.index": true,
+		"snapshots":     true,
+		"lift":          true,
+		"pids":          true,
+		"mutations":     true,
+	}
+)
+
+// Relocation describes move of product home (and its hololib and holotree
+// spaces) into new location.
+type Relocation struct {
+	OldHome  string
+	NewHome  string
+	OldBase  string
+	NewBase  string
+	Catalogs int
+	Blobs    int
+	Spaces   []string
+}
+
+// NewRelocation validates that product home can be moved to target. Files in
+// holotree spaces have absolute holotree paths embedded in them (also inside
+// binaries), and those can only be overwritten in place, so new holotree
+// location cannot be longer than old one. Shorter one is padded with extra
+// path separators (see padding).
+func NewRelocation(target string) (*Relocation, error) {
+	if common.SharedHolotree {
+		return nil, fmt.Errorf("Shared holotree lives outside of %s and cannot be relocated by this command.", common.Product.HomeVariable())
+	}
+	newHome, err := filepath.Abs(common.ExpandPath(target))
+	if err != nil {
+		return nil, err
+	}
+	result := &Relocation{
+		OldHome: common.Product.Home(),
+		NewHome: newHome,
+		OldBase: common.HolotreeLocation(),
+		NewBase: filepath.Join(newHome, "holotree"),
+	}
+	return result, result.validate()
+}
+
+func (it *Relocation) validate() error {
+	if pathlib.IsFile(it.NewHome) {
+		return fmt.Errorf("Target %q is a file.", it.NewHome)
+	}
+	if pathlib.IsDir(it.NewHome) && !pathlib.IsEmptyDir(it.NewHome) {
+		return fmt.Errorf("Target %q is not empty.", it.NewHome)
+	}
+	if isInside(it.OldHome, it.NewHome) || isInside(it.NewHome, it.OldHome) {
+		return fmt.Errorf("Target %q and current home %q cannot be inside each other.", it.NewHome, it.OldHome)
+	}
+	if len(it.NewBase) > len(it.OldBase) {
+		return fmt.Errorf("Holotree location %q has %d characters, but it can have at most %d characters like %q. Environments have holotree paths embedded in them (also inside binaries), and those can only be overwritten in place, so they cannot grow. Pick target path with at most %d characters (shorter ones are padded with extra path separators), or rebuild environments in new location instead.", it.NewBase, len(it.NewBase), len(it.OldBase), it.OldBase, len(it.OldHome))
+	}
+	return nil
+}
+
+// padding is extra path separators, which are written after shorter new
+// holotree location, so that embedded paths keep their length (and still
+// point to same place, like "/new/holotree///space").
+func (it *Relocation) padding() string {
+	return strings.Repeat(string(filepath.Separator), len(it.OldBase)-len(it.NewBase))
+}
+
+func isInside(parent, child string) bool {
+	relative, err := filepath.Rel(parent, child)
+	return err == nil && relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator))
+}
+
+func (it *Relocation) newHololib() string {
+	return filepath.Join(it.NewHome, filepath.Base(common.HololibLocation()))
+}
+
+func (it *Relocation) blobLocation(library, digest string) string {
+	return filepath.Join(library, digest[:2], digest[2:4], digest[4:6], digest)
+}
+
+// copyTree copies regular files under source directory into target, leaving
+// out top level entries in skip set. Symbolic links and lock files are left
+// out.
+func copyTree(source, target string, skip map[string]bool) (count int, err error) {
+	err = filepath.WalkDir(source, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relative, err := filepath.Rel(source, path)
+		if err != nil {
+			return err
+		}
+		if relative == "." {
+			return nil
+		}
+		if skip[filepath.ToSlash(relative)] {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() {
+			_, err = pathlib.MakeSharedDir(filepath.Join(target, relative))
+			return err
+		}
+		if !entry.Type().IsRegular() || filepath.Ext(path) == ".lck" {
+			common.Debug("Relocation skips lock or non-regular file %q.", path)
+			return nil
+		}
+		count += 1
+		return pathlib.CopyFile(path, filepath.Join(target, relative), false)
+	})
+	return count, err
+}
+
+// relocateBlob rewrites holotree base in front of every rewrite position of
+// library blob, and stores result into new library under its new digest.
+func (it *Relocation) relocateBlob(details *File, algorithm string) (string, error) {
+	reader, closer, err := gzDelegateOpen(ExactDefaultLocation(details.Digest), true)
+	if err != nil {
+		return "", err
+	}
+	content, err := io.ReadAll(reader)
+	closer()
+	if err != nil {
+		return "", err
+	}
+	prefix := []byte(it.OldBase + string(filepath.Separator))
+	replacement := []byte(it.NewBase + it.padding() + string(filepath.Separator))
+	for _, position := range details.Rewrite {
+		start := position - int64(len(prefix))
+		if start < 0 || !bytes.Equal(content[start:position], prefix) {
+			return "", fmt.Errorf("Blob %s does not have %q at position %d.", details.Digest, it.OldBase, start)
+		}
+		copy(content[start:position], replacement)
+	}
+	digester := NewDigest(algorithm)
+	digester.Write(content)
+	digest := fmt.Sprintf("%02x", digester.Sum(nil))
+	sinkname := it.blobLocation(filepath.Join(it.newHololib(), "library"), digest)
+	if pathlib.IsFile(sinkname) {
+		return digest, nil
+	}
+	_, err = pathlib.MakeSharedDir(filepath.Dir(sinkname))
+	if err != nil {
+		return "", err
+	}
+	sink := bytes.NewBuffer(nil)
+	if Compress() {
+		writer, _ := gzip.NewWriterLevel(sink, gzip.BestSpeed)
+		writer.Write(content)
+		writer.Close()
+	} else {
+		sink.Write(content)
+	}
+	return digest, writeAtomically(sinkname, sink.Bytes())
+}
+
+func eachFile(it *Dir, task func(*File) error) error {
+	for _, name := range set.Keys(it.Dirs) {
+		err := eachFile(it.Dirs[name], task)
+		if err != nil {
+			return err
+		}
+	}
+	for _, name := range set.Keys(it.Files) {
+		err := task(it.Files[name])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func rewrittenDigests(roots Roots, base string) map[string]bool {
+	result := make(map[string]bool)
+	for _, root := range roots {
+		if root.HolotreeBase() != base {
+			continue
+		}
+		eachFile(root.Tree, func(details *File) error {
+			if len(details.Rewrite) > 0 {
+				result[details.Digest] = true
+			}
+			return nil
+		})
+	}
+	return result
+}
+
+func (it *Relocation) relocateCatalogs(roots Roots) error {
+	relocated := make(map[string]string)
+	catalogs := filepath.Join(it.newHololib(), "catalog")
+	counter := pretty.NewCounter("Relocating catalogs", len(roots))
+	defer counter.Done()
+	for _, root := range roots {
+		counter.Tick()
+		target := filepath.Join(catalogs, filepath.Base(root.Source()))
+		if root.HolotreeBase() != it.OldBase {
+			pretty.Warning("Catalog %s is from other holotree location %q, and was copied as is.", filepath.Base(root.Source()), root.HolotreeBase())
+			err := root.SaveAs(target)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		err := eachFile(root.Tree, func(details *File) error {
+			if len(details.Rewrite) == 0 {
+				return nil
+			}
+			digest, ok := relocated[details.Digest]
+			if !ok {
+				var err error
+				digest, err = it.relocateBlob(details, root.Algorithm)
+				if err != nil {
+					return err
+				}
+				relocated[details.Digest] = digest
+				it.Blobs += 1
+			}
+			details.Digest = digest
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("Catalog %s: %v", filepath.Base(root.Source()), err)
+		}
+		root.Path = filepath.Join(it.NewBase, filepath.Base(root.Path))
+		err = root.SaveAs(target)
+		if err != nil {
+			return err
+		}
+		it.Catalogs += 1
+	}
+	return nil
+}
+
+// verify checks digests of all library blobs referenced by relocated
+// catalogs in new location.
+func (it *Relocation) verify(roots Roots) error {
+	library := filepath.Join(it.newHololib(), "library")
+	digests := make(map[string]bool)
+	for _, root := range roots {
+		eachFile(root.Tree, func(details *File) error {
+			if !details.IsSymlink() {
+				digests[details.Digest] = true
+			}
+			return nil
+		})
+	}
+	counter := pretty.NewCounter("Verifying blobs", len(digests))
+	defer counter.Done()
+	for _, digest := range set.Keys(digests) {
+		counter.Tick()
+		err := VerifyBlobFile(it.blobLocation(library, digest), digest)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type relocatedSpace struct {
+	label      string
+	identity   []byte
+	controller string
+	space      string
+}
+
+func (it *Relocation) oldSpaces() []*relocatedSpace {
+	result := []*relocatedSpace{}
+	for _, metafile := range pathlib.Glob(it.OldBase, "*.meta") {
+		directory := strings.TrimSuffix(metafile, ".meta")
+		shadow, err := NewRoot(directory)
+		if err == nil {
+			err = shadow.LoadFrom(metafile)
+		}
+		var identity []byte
+		if err == nil {
+			identity, err = os.ReadFile(filepath.Join(directory, "identity.yaml"))
+		}
+		if err != nil {
+			pretty.Warning("Space %q cannot be relocated, reason: %v", directory, err)
+			continue
+		}
+		result = append(result, &relocatedSpace{
+			label:      filepath.Base(directory),
+			identity:   identity,
+			controller: shadow.Controller,
+			space:      shadow.Space,
+		})
+	}
+	return result
+}
+
+func (it *Relocation) restoreSpaces(spaces []*relocatedSpace) error {
+	library, err := New()
+	if err != nil {
+		return err
+	}
+	for _, space := range spaces {
+		common.Log("Restoring space %q [%s] into new location.", space.space, space.label)
+		restored, err := library.RestoreTo(space.identity, space.label, space.controller, space.space, false)
+		if err != nil {
+			return err
+		}
+		robots := filepath.Join(it.OldBase, space.label+".robots")
+		if pathlib.IsFile(robots) {
+			pathlib.CopyFile(robots, restored+".robots", false)
+		}
+		it.Spaces = append(it.Spaces, restored)
+	}
+	return nil
+}
+
+func (it *Relocation) relocateSettings() error {
+	settings := filepath.Join(it.NewHome, "settings.yaml")
+	content, err := os.ReadFile(settings)
+	if err != nil {
+		return nil
+	}
+	updated := bytes.ReplaceAll(content, []byte(it.OldHome), []byte(it.NewHome))
+	if bytes.Equal(content, updated) {
+		return nil
+	}
+	return pathlib.WriteFile(settings, updated, 0o644)
+}
+
+// Run copies product home into new location, relocates catalogs and their
+// path rewriting blobs, verifies library digests, and restores existing
+// holotree spaces into new location. Old home is left untouched, and product
+// home of current process points to new location afterwards.
+func (it *Relocation) Run() (err error) {
+	defer fail.Around(&err)
+
+	common.TimelineBegin("holotree relocate start [%s -> %s]", it.OldHome, it.NewHome)
+	defer common.TimelineEnd()
+
+	lockfile := common.HolotreeLock()
+	completed := pathlib.LockWaitMessage(lockfile, "Serialized holotree relocation [holotree lock]")
+	locker, err := pathlib.Locker(lockfile, 30000, common.SharedHolotree)
+	completed()
+	fail.On(err != nil, "Could not get lock for holotree. Quiting.")
+	defer locker.Release()
+
+	_, roots := LoadCatalogs()
+	spaces := it.oldSpaces()
+
+	skip := map[string]bool{
+		filepath.Base(it.OldBase):               true,
+		filepath.Base(common.HololibLocation()): true,
+		filepath.Base(common.ProductTempRoot()): true,
+		filepath.Base(common.RunLockLocation()): true,
+	}
+	copied, err := copyTree(it.OldHome, it.NewHome, skip)
+	fail.On(err != nil, "Copying home failed, reason: %v", err)
+	common.Debug("Relocation copied %d files from home.", copied)
+
+	skip = make(map[string]bool)
+	for name := range unrelocatedHololib {
+		skip[name] = true
+	}
+	for digest := range rewrittenDigests(roots, it.OldBase) {
+		skip[filepath.ToSlash(RelativeDefaultLocation(digest))] = true
+	}
+	copied, err = copyTree(common.HololibLocation(), it.newHololib(), skip)
+	fail.On(err != nil, "Copying hololib failed, reason: %v", err)
+	common.Debug("Relocation copied %d files from hololib.", copied)
+
+	fail.Fast(it.relocateCatalogs(roots))
+	fail.Fast(it.verify(roots))
+	fail.Fast(it.relocateSettings())
+
+	common.Product.ForceHome(it.NewHome)
+	_, err = pathlib.MakeSharedDir(it.NewBase)
+	fail.On(err != nil, "%v", err)
+	fail.Fast(it.restoreSpaces(spaces))
+	return nil
+}
@@ -0,0 +1,59 @@
+package htfs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestRelocationRewritesEmbeddedHolotreePaths(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	basedir := t.TempDir()
+	t.Setenv("ROBOCORP_HOME", filepath.Join(basedir, "home1"))
+
+	_, err := NewRelocation(filepath.Join(basedir, "toolong"))
+	wont.Nil(err)
+	_, err = NewRelocation(filepath.Join(basedir, "home1", "inner"))
+	wont.Nil(err)
+
+	relocation, err := NewRelocation(filepath.Join(basedir, "home2"))
+	must.Nil(err)
+	must.Equal(filepath.Join(basedir, "home1", "holotree"), relocation.OldBase)
+	must.Equal(filepath.Join(basedir, "home2", "holotree"), relocation.NewBase)
+
+	identity := "h0123456789_abcdef0123456t"
+	content := "#!" + filepath.Join(relocation.OldBase, identity, "bin", "python") + "\n"
+	position := int64(strings.Index(content, identity))
+	details := &File{Name: "script", Digest: "00112233445566", Rewrite: []int64{position}}
+	source := ExactDefaultLocation(details.Digest)
+	must.Nil(os.MkdirAll(filepath.Dir(source), 0o755))
+	must.Nil(os.WriteFile(source, []byte(content), 0o644))
+
+	digest, err := relocation.relocateBlob(details, SelectedDigest())
+	must.Nil(err)
+	sinkname := relocation.blobLocation(filepath.Join(relocation.NewHome, "hololib", "library"), digest)
+	must.Nil(VerifyBlobFile(sinkname, digest))
+	relocated, err := showFile(sinkname)
+	must.Nil(err)
+	must.Equal("#!"+filepath.Join(relocation.NewBase, identity, "bin", "python")+"\n", string(relocated))
+	must.Equal(common.Product.Home(), relocation.OldHome)
+
+	shorter, err := NewRelocation(filepath.Join(basedir, "hom"))
+	must.Nil(err)
+	must.Equal(string(filepath.Separator)+string(filepath.Separator), shorter.padding())
+	digest, err = shorter.relocateBlob(details, SelectedDigest())
+	must.Nil(err)
+	relocated, err = showFile(shorter.blobLocation(filepath.Join(shorter.NewHome, "hololib", "library"), digest))
+	must.Nil(err)
+	must.Equal(len(content), len(relocated))
+	must.Equal("#!"+shorter.NewBase+shorter.padding()+string(filepath.Separator)+filepath.Join(identity, "bin", "python")+"\n", string(relocated))
+
+	details.Rewrite = []int64{1}
+	_, err = relocation.relocateBlob(details, SelectedDigest())
+	wont.Nil(err)
+}
@@ -0,0 +1,109 @@
+package htfs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pretty"
+)
+
+const (
+	restoreFindingsKept = 100
+)
+
+// RestoreFindings is result of latest sampled restore validation, where
+// files that looked unchanged (by size and mode) were digest verified against
+// catalog. Corrupted files were restored again from hololib, and at most
+// first hundred of those are listed.
+type RestoreFindings struct {
+	When      string   `json:"when"`
+	Space     string   `json:"space"`
+	Percent   float64  `json:"percent"`
+	Sampled   uint64   `json:"sampled"`
+	Total     uint64   `json:"total"`
+	Broken    uint64   `json:"broken"`
+	Corrupted []string `json:"corrupted"`
+}
+
+func sampledForValidation(percent float64) bool {
+	return percent >= 100 || (percent > 0 && rand.Float64()*100 < percent)
+}
+
+func restoredFileIntact(fullpath, expected, algorithm string) bool {
+	source, err := os.Open(fullpath)
+	if err != nil {
+		return false
+	}
+	defer source.Close()
+	digest := NewDigest(algorithm)
+	_, err = io.Copy(digest, source)
+	return err == nil && fmt.Sprintf("%02x", digest.Sum(nil)) == expected
+}
+
+func (it *stats) Validated(fullpath string, intact bool) bool {
+	it.Lock()
+	defer it.Unlock()
+
+	it.sampled++
+	if intact {
+		return true
+	}
+	it.broken++
+	if len(it.corrupted) < restoreFindingsKept {
+		it.corrupted = append(it.corrupted, fullpath)
+	}
+	return false
+}
+
+// reportValidation logs and saves findings of restore validation, if there
+// was any sampling done.
+func (it *stats) reportValidation(space string) {
+	if it.sampled == 0 {
+		return
+	}
+	common.Timeline("- validated %d/%d (corrupted: %d)", it.sampled, it.total, it.broken)
+	common.Debug("Holotree restore validation: %d/%d files digest verified, %d corrupted.", it.sampled, it.total, it.broken)
+	if it.broken > 0 {
+		pretty.Warning("Restore validation found %d corrupted files (of %d sampled) in %q. They were restored again from hololib.", it.broken, it.sampled, space)
+		common.RunJournal("holotree", "corruption", "restore validation found %d corrupted files in %q", it.broken, space)
+	}
+	findings := &RestoreFindings{
+		When:      time.Now().Format(time.RFC3339),
+		Space:     space,
+		Percent:   common.RccRestoreVerify(),
+		Sampled:   it.sampled,
+		Total:     it.total,
+		Broken:    it.broken,
+		Corrupted: append([]string{}, it.corrupted...),
+	}
+	blob, err := json.MarshalIndent(findings, "", "  ")
+	if err == nil {
+		err = os.WriteFile(common.RestoreFindingsLocation(), blob, 0o644)
+	}
+	if err != nil {
+		common.Debug("Could not save restore validation findings, reason: %v", err)
+	}
+}
+
+// LoadRestoreFindings loads findings of latest restore validation. Missing
+// findings are not an error, but result is then nil.
+func LoadRestoreFindings() (*RestoreFindings, error) {
+	blob, err := os.ReadFile(common.RestoreFindingsLocation())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	findings := &RestoreFindings{}
+	err = json.Unmarshal(blob, findings)
+	if err != nil {
+		return nil, err
+	}
+	return findings, nil
+}
@@ -0,0 +1,61 @@
+package htfs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestRestoreValidationDetectsSilentCorruption(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	t.Setenv("ROBOCORP_HOME", t.TempDir())
+	t.Setenv(common.RCC_RESTORE_VERIFY, "")
+	must.Equal(0.0, common.RccRestoreVerify())
+	t.Setenv(common.RCC_RESTORE_VERIFY, "12.5%")
+	must.Equal(12.5, common.RccRestoreVerify())
+	t.Setenv(common.RCC_RESTORE_VERIFY, "250")
+	must.Equal(100.0, common.RccRestoreVerify())
+
+	wont.True(sampledForValidation(0))
+	must.True(sampledForValidation(100))
+
+	findings, err := LoadRestoreFindings()
+	must.Nil(err)
+	must.Nil(findings)
+
+	fullpath := filepath.Join(t.TempDir(), "module.py")
+	must.Nil(os.WriteFile(fullpath, []byte("print('hello')\n"), 0o644))
+	digester := common.NewDigester(Compress())
+	digester.Write([]byte("print('hello')\n"))
+	expected := fmt.Sprintf("%02x", digester.Sum(nil))
+	must.True(restoredFileIntact(fullpath, expected, ""))
+
+	must.Nil(os.WriteFile(fullpath, []byte("print('hellO')\n"), 0o644))
+	wont.True(restoredFileIntact(fullpath, expected, ""))
+	wont.True(restoredFileIntact(fullpath+".missing", expected, ""))
+
+	score := &stats{}
+	score.reportValidation("nothing sampled")
+	findings, err = LoadRestoreFindings()
+	must.Nil(err)
+	must.Nil(findings)
+
+	must.True(score.Validated("intact.py", true))
+	for at := range restoreFindingsKept + 5 {
+		wont.True(score.Validated(fmt.Sprintf("broken%d.py", at), false))
+	}
+	score.reportValidation("space")
+	findings, err = LoadRestoreFindings()
+	must.Nil(err)
+	wont.Nil(findings)
+	must.Equal("space", findings.Space)
+	must.Equal(uint64(restoreFindingsKept+6), findings.Sampled)
+	must.Equal(uint64(restoreFindingsKept+5), findings.Broken)
+	must.Equal(restoreFindingsKept, len(findings.Corrupted))
+	must.Equal("broken0.py", findings.Corrupted[0])
+}
@@ -0,0 +1,55 @@
+package htfs
+
+import (
+	"os"
+	"sort"
+)
+
+// ScrubSelection picks next batch of blobs to verify, continuing after
+// cursor in digest order and wrapping around at end, so that repeated
+// batches eventually cover all of hololib. Gives selected digests, cursor
+// for next batch, and if selection wrapped around.
+func ScrubSelection(known map[string]map[string]bool, cursor string, batch int) ([]string, string, bool) {
+	digests := make([]string, 0, len(known))
+	for digest := range known {
+		digests = append(digests, digest)
+	}
+	sort.Strings(digests)
+	if len(digests) == 0 {
+		return digests, "", false
+	}
+	if batch < 1 || batch > len(digests) {
+		batch = len(digests)
+	}
+	start := sort.SearchStrings(digests, cursor)
+	if start < len(digests) && digests[start] == cursor {
+		start++
+	}
+	if start == len(digests) {
+		start = 0
+	}
+	selected := make([]string, 0, batch)
+	for at := 0; at < batch; at++ {
+		selected = append(selected, digests[(start+at)%len(digests)])
+	}
+	return selected, selected[len(selected)-1], start+batch >= len(digests)
+}
+
+// ScrubBlobs verifies given blobs in hololib library, and gives those that
+// are corrupted and those that are missing.
+func ScrubBlobs(digests []string) (corrupt []string, missing []string) {
+	corrupt, missing = []string{}, []string{}
+	for _, digest := range digests {
+		location := ExactDefaultLocation(digest)
+		_, err := os.Stat(location)
+		if err != nil {
+			missing = append(missing, digest)
+			continue
+		}
+		err = VerifyBlobFile(location, digest)
+		if err != nil {
+			corrupt = append(corrupt, digest)
+		}
+	}
+	return corrupt, missing
+}
@@ -0,0 +1,61 @@
+package htfs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestScrubSelectionRotatesOverWholeLibrary(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	known := map[string]map[string]bool{"aa": nil, "bb": nil, "cc": nil, "dd": nil, "ee": nil}
+	selected, cursor, wrapped := ScrubSelection(known, "", 2)
+	must.Equal([]string{"aa", "bb"}, selected)
+	must.Equal("bb", cursor)
+	wont.True(wrapped)
+
+	selected, cursor, wrapped = ScrubSelection(known, cursor, 2)
+	must.Equal([]string{"cc", "dd"}, selected)
+	wont.True(wrapped)
+
+	selected, cursor, wrapped = ScrubSelection(known, cursor, 2)
+	must.Equal([]string{"ee", "aa"}, selected)
+	must.Equal("aa", cursor)
+	must.True(wrapped)
+
+	selected, _, wrapped = ScrubSelection(known, "ee", 10)
+	must.Equal(5, len(selected))
+	must.Equal("aa", selected[0])
+	must.True(wrapped)
+
+	selected, _, _ = ScrubSelection(known, "bc", 1)
+	must.Equal([]string{"cc"}, selected)
+
+	selected, cursor, wrapped = ScrubSelection(map[string]map[string]bool{}, "aa", 2)
+	must.Equal(0, len(selected))
+	must.Equal("", cursor)
+	wont.True(wrapped)
+}
+
+func TestScrubBlobsFindsCorruptAndMissingBlobs(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	t.Setenv("ROBOCORP_HOME", t.TempDir())
+	good := []byte("good blob content")
+	bad := []byte("original blob content")
+	goodDigest := hexdigest(DigestBlake3, good)
+	badDigest := hexdigest(DigestBlake3, bad)
+	missingDigest := hexdigest(DigestBlake3, []byte("never stored"))
+	for digest, content := range map[string][]byte{goodDigest: good, badDigest: []byte("bit rotted content")} {
+		location := ExactDefaultLocation(digest)
+		must.Nil(os.MkdirAll(filepath.Dir(location), 0o755))
+		must.Nil(os.WriteFile(location, content, 0o644))
+	}
+
+	corrupt, missing := ScrubBlobs([]string{goodDigest, badDigest, missingDigest})
+	must.Equal([]string{badDigest}, corrupt)
+	must.Equal([]string{missingDigest}, missing)
+}
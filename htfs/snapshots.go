@@ -0,0 +1,494 @@
+package htfs
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joshyorko/rcc/anywork"
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/fail"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/joshyorko/rcc/set"
+)
+
+const (
+	snapshotVersion  = 1
+	snapshotManifest = ".manifest"
+	snapshotContent  = ".snapshot"
+)
+
+// Snapshot sets are compacted form of many catalogs. Content file keeps
+// original (gzipped) catalog files in one gob encoded blob, and small JSON
+// manifest keeps catalog infos, so that catalog listings and blueprint
+// lookups do not need to read content. Compacted catalogs are expanded back
+// into catalog directory on demand, when their blueprint is needed.
+type (
+	Snapshot struct {
+		Name     string                    `json:"name"`
+		Created  int64                     `json:"created"`
+		Catalogs map[string]*SnapshotEntry `json:"catalogs"`
+	}
+
+	SnapshotEntry struct {
+		Info   Info `json:"info"`
+		Lifted bool `json:"lifted"`
+	}
+
+	snapshotSet struct {
+		Version  int
+		Catalogs map[string][]byte
+	}
+
+	compactedCatalog struct {
+		name    string
+		content []byte
+	}
+)
+
+func snapshotFilename(name, extension string) string {
+	return filepath.Join(common.HololibSnapshotLocation(), name+extension)
+}
+
+// SnapshotNames lists names of all snapshot sets in hololib.
+func SnapshotNames() []string {
+	result := []string{}
+	for _, manifest := range pathlib.Glob(common.HololibSnapshotLocation(), "*"+snapshotManifest) {
+		result = append(result, strings.TrimSuffix(filepath.Base(manifest), snapshotManifest))
+	}
+	sort.Strings(result)
+	return result
+}
+
+// SnapshotSetName converts controller name into snapshot set name.
+func SnapshotSetName(controller string) string {
+	if len(controller) == 0 {
+		controller = "unknown"
+	}
+	return strings.Map(func(letter rune) rune {
+		if strings.ContainsRune("/\\:*?\"<>| ", letter) {
+			return '_'
+		}
+		return letter
+	}, strings.ToLower(controller))
+}
+
+func LoadSnapshot(name string) (*Snapshot, error) {
+	content, err := os.ReadFile(snapshotFilename(name, snapshotManifest))
+	if err != nil {
+		return nil, err
+	}
+	result := &Snapshot{}
+	err = json.Unmarshal(content, result)
+	if err != nil {
+		return nil, fmt.Errorf("Snapshot manifest %q is broken, reason: %v", name, err)
+	}
+	if result.Catalogs == nil {
+		result.Catalogs = make(map[string]*SnapshotEntry)
+	}
+	return result, nil
+}
+
+func (it *Snapshot) Names() []string {
+	result := make([]string, 0, len(it.Catalogs))
+	for name := range it.Catalogs {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func loadSnapshotSet(name string) (*snapshotSet, error) {
+	content, err := os.ReadFile(snapshotFilename(name, snapshotContent))
+	if err != nil {
+		return nil, err
+	}
+	result := &snapshotSet{}
+	err = gob.NewDecoder(bytes.NewReader(content)).Decode(result)
+	if err != nil {
+		return nil, fmt.Errorf("Snapshot set %q is broken, reason: %v", name, err)
+	}
+	if result.Version != snapshotVersion || result.Catalogs == nil {
+		return nil, fmt.Errorf("Snapshot set %q has unsupported version %d.", name, result.Version)
+	}
+	return result, nil
+}
+
+func writeAtomically(filename string, content []byte) error {
+	partname := fmt.Sprintf("%s.part%s", filename, <-common.Identities)
+	defer os.Remove(partname)
+	err := pathlib.WriteFile(partname, content, 0o644)
+	if err != nil {
+		return err
+	}
+	return pathlib.TryRename("snapshot", partname, filename)
+}
+
+func saveSnapshot(manifest *Snapshot, set *snapshotSet) error {
+	if len(manifest.Catalogs) == 0 {
+		os.Remove(snapshotFilename(manifest.Name, snapshotManifest))
+		os.Remove(snapshotFilename(manifest.Name, snapshotContent))
+		return nil
+	}
+	sink := bytes.NewBuffer(nil)
+	err := gob.NewEncoder(sink).Encode(set)
+	if err != nil {
+		return err
+	}
+	err = writeAtomically(snapshotFilename(manifest.Name, snapshotContent), sink.Bytes())
+	if err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeAtomically(snapshotFilename(manifest.Name, snapshotManifest), content)
+}
+
+// CompactCatalogs moves given catalogs into named snapshot set (merging with
+// existing set of same name), and removes per-catalog files from catalog
+// directory. Returns number of catalogs compacted.
+func CompactCatalogs(name string, catalogs []string) (count int, err error) {
+	defer fail.Around(&err)
+
+	common.TimelineBegin("holotree compact start [%s]", name)
+	defer common.TimelineEnd()
+
+	lockfile := common.HolotreeLock()
+	completed := pathlib.LockWaitMessage(lockfile, "Serialized catalog compaction [holotree lock]")
+	locker, err := pathlib.Locker(lockfile, 30000, common.SharedHolotree)
+	completed()
+	fail.On(err != nil, "Could not get lock for holotree. Quiting.")
+	defer locker.Release()
+
+	RecoverMutations()
+
+	manifest, err := LoadSnapshot(name)
+	if err != nil {
+		manifest = &Snapshot{Name: name, Catalogs: make(map[string]*SnapshotEntry)}
+	}
+	set, err := loadSnapshotSet(name)
+	if err != nil {
+		set = &snapshotSet{Version: snapshotVersion, Catalogs: make(map[string][]byte)}
+	}
+	compacted := make([]string, 0, len(catalogs))
+	for _, catalog := range catalogs {
+		fullpath := filepath.Join(common.HololibCatalogLocation(), catalog)
+		content, err := os.ReadFile(fullpath)
+		if err != nil {
+			pretty.Warning("Catalog %s is not readable, reason: %v. Ignored!", catalog, err)
+			continue
+		}
+		shadow, err := NewRoot(filepath.Join(common.ProductTemp(), "shadow"))
+		fail.On(err != nil, "%v", err)
+		err = shadow.LoadFrom(fullpath)
+		if err != nil {
+			pretty.Warning("Catalog %s is not loadable, reason: %v. Ignored!", catalog, err)
+			continue
+		}
+		manifest.Catalogs[catalog] = &SnapshotEntry{Info: *shadow.Info, Lifted: shadow.Lifted}
+		set.Catalogs[catalog] = content
+		compacted = append(compacted, fullpath)
+	}
+	if len(compacted) == 0 {
+		return 0, nil
+	}
+	manifest.Created = time.Now().Unix()
+	_, err = pathlib.MakeSharedDir(common.HololibSnapshotLocation())
+	fail.On(err != nil, "Could not create snapshot directory, reason: %v", err)
+	mutation, err := BeginMutation(MutationCompact, compacted, nil)
+	fail.Fast(err)
+	fail.Fast(saveSnapshot(manifest, set))
+	for _, fullpath := range compacted {
+		err = os.Remove(fullpath)
+		fail.On(err != nil, "Could not remove compacted catalog %q, reason: %v", fullpath, err)
+		os.Remove(fullpath + ".info")
+	}
+	fail.Fast(mutation.Commit())
+	common.Timeline("%d catalogs compacted into snapshot set %q", len(compacted), name)
+	return len(compacted), nil
+}
+
+func restoreCatalog(catalog string, content []byte, entry *SnapshotEntry) error {
+	fullpath := filepath.Join(common.HololibCatalogLocation(), catalog)
+	err := writeAtomically(fullpath, content)
+	if err != nil {
+		return err
+	}
+	info := entry.Info
+	return info.saveAs(fullpath + ".info")
+}
+
+// ExpandSnapshot restores all catalogs of snapshot set back into catalog
+// directory, and removes snapshot set. Returns number of catalogs restored.
+func ExpandSnapshot(name string) (count int, err error) {
+	defer fail.Around(&err)
+
+	lockfile := common.HolotreeLock()
+	completed := pathlib.LockWaitMessage(lockfile, "Serialized snapshot expansion [holotree lock]")
+	locker, err := pathlib.Locker(lockfile, 30000, common.SharedHolotree)
+	completed()
+	fail.On(err != nil, "Could not get lock for holotree. Quiting.")
+	defer locker.Release()
+
+	manifest, err := LoadSnapshot(name)
+	fail.On(err != nil, "Could not load snapshot %q, reason: %v", name, err)
+	set, err := loadSnapshotSet(name)
+	fail.On(err != nil, "%v", err)
+	for catalog, entry := range manifest.Catalogs {
+		content, ok := set.Catalogs[catalog]
+		if !ok {
+			pretty.Warning("Snapshot %q has no content for catalog %s. Ignored!", name, catalog)
+			continue
+		}
+		fail.Fast(restoreCatalog(catalog, content, entry))
+		count++
+	}
+	manifest.Catalogs = make(map[string]*SnapshotEntry)
+	fail.Fast(saveSnapshot(manifest, set))
+	return count, nil
+}
+
+// isCompacted tells if catalog is in some snapshot set, without expanding it.
+func isCompacted(catalog string) bool {
+	for _, name := range SnapshotNames() {
+		manifest, err := LoadSnapshot(name)
+		if err != nil {
+			continue
+		}
+		if _, ok := manifest.Catalogs[catalog]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// expandedCatalog gives catalog path of blueprint, and expands catalog from
+// its snapshot set, if it was compacted. This modifies hololib, so it is only
+// for restore paths, which already hold holotree lock.
+func expandedCatalog(library MutableLibrary, key string) string {
+	catalog := library.CatalogPath(key)
+	if !pathlib.IsFile(catalog) {
+		expandCatalog(CatalogName(key))
+	}
+	return catalog
+}
+
+// expandCatalog restores single catalog from snapshot set containing it,
+// and removes it from that set. Returns true when catalog was restored.
+func expandCatalog(catalog string) bool {
+	for _, name := range SnapshotNames() {
+		manifest, err := LoadSnapshot(name)
+		if err != nil {
+			continue
+		}
+		entry, ok := manifest.Catalogs[catalog]
+		if !ok {
+			continue
+		}
+		set, err := loadSnapshotSet(name)
+		if err == nil {
+			content, ok := set.Catalogs[catalog]
+			if ok {
+				err = restoreCatalog(catalog, content, entry)
+			} else {
+				err = fmt.Errorf("no content for catalog %s", catalog)
+			}
+		}
+		if err != nil {
+			common.Debug("Could not expand catalog %s from snapshot %q, reason: %v", catalog, name, err)
+			return false
+		}
+		delete(manifest.Catalogs, catalog)
+		delete(set.Catalogs, catalog)
+		err = saveSnapshot(manifest, set)
+		if err != nil {
+			common.Debug("Could not update snapshot %q, reason: %v", name, err)
+		}
+		common.Timeline("catalog %s expanded from snapshot set %q", catalog, name)
+		return true
+	}
+	return false
+}
+
+// AllCatalogNames lists catalog names like CatalogNames, but also includes
+// catalogs compacted into snapshot sets. Listing does not expand anything,
+// so callers reading catalog files must use ExpandCatalogs or LoadCatalog.
+func AllCatalogNames() []string {
+	catalogs := CatalogNames()
+	for catalog := range compactedInfos(catalogs) {
+		catalogs = append(catalogs, catalog)
+	}
+	return set.Set(catalogs)
+}
+
+// CompactedCatalogTimes gives creation time of snapshot set, for each
+// compacted catalog.
+func CompactedCatalogTimes() map[string]time.Time {
+	result := make(map[string]time.Time)
+	for _, name := range SnapshotNames() {
+		manifest, err := LoadSnapshot(name)
+		if err != nil {
+			continue
+		}
+		for catalog := range manifest.Catalogs {
+			result[catalog] = time.Unix(manifest.Created, 0)
+		}
+	}
+	return result
+}
+
+// ExpandCatalogs restores given catalogs from their snapshot sets back into
+// catalog directory, so that their files can be used directly (like when
+// exporting or serving them). Catalogs already present as files are left as
+// they are.
+func ExpandCatalogs(catalogs []string) (err error) {
+	defer fail.Around(&err)
+
+	wanted := make([]string, 0, len(catalogs))
+	for _, catalog := range catalogs {
+		if !pathlib.IsFile(filepath.Join(common.HololibCatalogLocation(), catalog)) {
+			wanted = append(wanted, catalog)
+		}
+	}
+	if len(wanted) == 0 {
+		return nil
+	}
+
+	lockfile := common.HolotreeLock()
+	completed := pathlib.LockWaitMessage(lockfile, "Serialized catalog expansion [holotree lock]")
+	locker, err := pathlib.Locker(lockfile, 30000, common.SharedHolotree)
+	completed()
+	fail.On(err != nil, "Could not get lock for holotree. Quiting.")
+	defer locker.Release()
+
+	for _, catalog := range wanted {
+		if !pathlib.IsFile(filepath.Join(common.HololibCatalogLocation(), catalog)) {
+			expandCatalog(catalog)
+		}
+	}
+	return nil
+}
+
+// LoadCatalog loads catalog from given catalog file, or when there is no
+// such file, from snapshot set where catalog with same name was compacted.
+// Snapshot sets are only read, never modified.
+func (it *Root) LoadCatalog(fullpath string) error {
+	if pathlib.IsFile(fullpath) {
+		return it.LoadFrom(fullpath)
+	}
+	content, err := CompactedCatalog(filepath.Base(fullpath))
+	if err != nil {
+		return err
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	it.source = fullpath
+	return it.ReadFrom(reader)
+}
+
+// CompactedCatalog gives content of catalog file, as it was when catalog was
+// compacted into snapshot set. Snapshot sets are only read, never modified.
+func CompactedCatalog(catalog string) ([]byte, error) {
+	for _, name := range SnapshotNames() {
+		manifest, err := LoadSnapshot(name)
+		if err != nil {
+			continue
+		}
+		if _, ok := manifest.Catalogs[catalog]; !ok {
+			continue
+		}
+		set, err := loadSnapshotSet(name)
+		if err != nil {
+			return nil, err
+		}
+		content, ok := set.Catalogs[catalog]
+		if !ok {
+			return nil, fmt.Errorf("Snapshot %q has no content for catalog %s.", name, catalog)
+		}
+		return content, nil
+	}
+	return nil, fmt.Errorf("Catalog %s is not a file, nor compacted into any snapshot set.", catalog)
+}
+
+// compactedInfos returns catalog infos of all compacted catalogs, which are
+// not (also) present as catalog files.
+func compactedInfos(present []string) map[string]*SnapshotEntry {
+	known := make(map[string]bool)
+	for _, catalog := range present {
+		known[catalog] = true
+	}
+	result := make(map[string]*SnapshotEntry)
+	for _, name := range SnapshotNames() {
+		manifest, err := LoadSnapshot(name)
+		if err != nil {
+			common.Debug("Ignoring snapshot %q, reason: %v", name, err)
+			continue
+		}
+		for catalog, entry := range manifest.Catalogs {
+			if !known[catalog] {
+				result[catalog] = entry
+			}
+		}
+	}
+	return result
+}
+
+// compactedCatalogs returns content of all compacted catalogs, which are
+// not (also) present as catalog files.
+func compactedCatalogs(present []string) []*compactedCatalog {
+	wanted := compactedInfos(present)
+	result := make([]*compactedCatalog, 0, len(wanted))
+	for _, name := range SnapshotNames() {
+		set, err := loadSnapshotSet(name)
+		if err != nil {
+			common.Debug("Ignoring snapshot %q, reason: %v", name, err)
+			continue
+		}
+		for catalog, content := range set.Catalogs {
+			if _, ok := wanted[catalog]; ok {
+				result = append(result, &compactedCatalog{name: catalog, content: content})
+				delete(wanted, catalog)
+			}
+		}
+	}
+	sort.Slice(result, func(left, right int) bool {
+		return result[left].name < result[right].name
+	})
+	return result
+}
+
+func (it *compactedCatalog) loader(fullpath string, at int, roots Roots, counter *pretty.Counter) anywork.Work {
+	return func() {
+		defer counter.Tick()
+		tempdir := filepath.Join(common.ProductTemp(), "shadow")
+		shadow, err := NewRoot(tempdir)
+		if err != nil {
+			panic(fmt.Sprintf("Temp dir %q, reason: %v", tempdir, err))
+		}
+		reader, err := gzip.NewReader(bytes.NewReader(it.content))
+		if err != nil {
+			panic(fmt.Sprintf("Load compacted %q, reason: %v", it.name, err))
+		}
+		defer reader.Close()
+		err = shadow.ReadFrom(reader)
+		if err != nil {
+			panic(fmt.Sprintf("Load compacted %q, reason: %v", it.name, err))
+		}
+		shadow.source = fullpath
+		roots[at] = shadow
+		common.Trace("Compacted catalog %q loaded.", it.name)
+	}
+}
@@ -0,0 +1,128 @@
+package htfs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/hamlet"
+	"github.com/joshyorko/rcc/pathlib"
+)
+
+func TestCatalogsCanBeCompactedAndExpanded(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	t.Setenv("ROBOCORP_HOME", t.TempDir())
+	first := saveTestCatalog(t, "0123456789abcdef", true)
+	second := saveTestCatalog(t, "fedcba9876543210", false)
+	third := saveTestCatalog(t, "00112233aabbccdd", true)
+	must.Equal(3, len(CatalogNames()))
+
+	count, err := CompactCatalogs("rcc.user", []string{filepath.Base(first), filepath.Base(second)})
+	must.Nil(err)
+	must.Equal(2, count)
+	must.Equal(1, len(CatalogNames()))
+	wont.True(pathlib.IsFile(first))
+	wont.True(pathlib.IsFile(first + ".info"))
+	must.Equal([]string{"rcc.user"}, SnapshotNames())
+
+	// compacted catalogs are still visible in listings and loads
+	must.Equal(3, len(LoadCatalogInfos()))
+	catalogs, roots := LoadCatalogs()
+	must.Equal(3, len(catalogs))
+	must.Equal(3, len(roots))
+	for at, root := range roots {
+		must.Equal(catalogs[at], root.Source())
+	}
+
+	// lookups do not expand, but restore expands needed catalog on demand
+	library := &hololib{queryCache: make(map[string]bool)}
+	must.Equal(first, library.CatalogPath("0123456789abcdef"))
+	wont.True(pathlib.IsFile(first))
+	must.True(library.queryBlueprint("0123456789abcdef"))
+	wont.True(pathlib.IsFile(first))
+	must.Equal(first, expandedCatalog(library, "0123456789abcdef"))
+	must.True(pathlib.IsFile(first))
+	snapshot, err := LoadSnapshot("rcc.user")
+	must.Nil(err)
+	must.Equal([]string{filepath.Base(second)}, snapshot.Names())
+	must.Equal(3, len(LoadCatalogInfos()))
+
+	// expanding last catalogs removes snapshot set
+	count, err = ExpandSnapshot("rcc.user")
+	must.Nil(err)
+	must.Equal(1, count)
+	must.True(pathlib.IsFile(second))
+	must.True(pathlib.IsFile(third))
+	must.Equal(0, len(SnapshotNames()))
+	must.Equal(3, len(CatalogNames()))
+	must.Equal("fedcba9876543210", loadInfo(t, second))
+}
+
+func TestCompactedCatalogsAreListedAndReadable(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	t.Setenv("ROBOCORP_HOME", t.TempDir())
+	first := saveTestCatalog(t, "0123456789abcdef", true)
+	saveTestCatalog(t, "fedcba9876543210", false)
+	original, err := os.ReadFile(first)
+	must.Nil(err)
+	_, err = CompactCatalogs("rcc.user", []string{filepath.Base(first)})
+	must.Nil(err)
+	must.Equal(1, len(CatalogNames()))
+	must.Equal(2, len(AllCatalogNames()))
+	_, ok := CompactedCatalogTimes()[filepath.Base(first)]
+	must.True(ok)
+
+	root, err := NewRoot(filepath.Join(common.ProductTemp(), "shadow"))
+	must.Nil(err)
+	must.Nil(root.LoadCatalog(first))
+	must.Equal("0123456789abcdef", root.Blueprint)
+	wont.True(pathlib.IsFile(first))
+	wont.Nil(root.LoadCatalog(first + ".missing"))
+	content, err := CompactedCatalog(filepath.Base(first))
+	must.Nil(err)
+	must.Equal(original, content)
+	wont.True(pathlib.IsFile(first))
+
+	must.Nil(ExpandCatalogs([]string{filepath.Base(first)}))
+	must.True(pathlib.IsFile(first))
+	must.Equal(2, len(CatalogNames()))
+	must.Equal(2, len(AllCatalogNames()))
+}
+
+func loadInfo(t *testing.T, catalog string) string {
+	root, err := NewRoot(filepath.Join(common.ProductTemp(), "shadow"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := root.LoadFrom(catalog); err != nil {
+		t.Fatal(err)
+	}
+	return root.Blueprint
+}
+
+func TestIncompleteCompactionIsRolledForward(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	t.Setenv("ROBOCORP_HOME", t.TempDir())
+	first := saveTestCatalog(t, "0123456789abcdef", true)
+	second := saveTestCatalog(t, "fedcba9876543210", false)
+	content, err := os.ReadFile(first)
+	must.Nil(err)
+	_, err = CompactCatalogs("rcc.user", []string{filepath.Base(first)})
+	must.Nil(err)
+
+	// simulate compaction killed after snapshot was saved
+	must.Nil(os.WriteFile(first, content, 0o644))
+	_, err = BeginMutation(MutationCompact, []string{first, second}, nil)
+	must.Nil(err)
+
+	RecoverMutations()
+
+	must.Equal(0, len(pathlib.Glob(common.HololibMutationLocation(), "*.json")))
+	wont.True(pathlib.IsFile(first))
+	must.True(pathlib.IsFile(second))
+	must.Equal(2, len(AllCatalogNames()))
+}
@@ -0,0 +1,124 @@
+package htfs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pathlib"
+)
+
+// SpaceRobot is one robot that has used holotree space, as recorded in space
+// ".robots" file. Shared environments have multiple robots per space.
+type SpaceRobot struct {
+	Robot string `json:"robot"`
+	First int64  `json:"first"`
+	Last  int64  `json:"last"`
+	Uses  int64  `json:"uses"`
+
+	Identity string `json:"identity,omitempty"`
+}
+
+type SpaceRobots map[string]*SpaceRobot
+
+func spaceRobotsFile(space string) string {
+	return fmt.Sprintf("%s.robots", space)
+}
+
+func (it SpaceRobots) record(robotfile, identity string, when time.Time) {
+	found, ok := it[robotfile]
+	if !ok {
+		found = &SpaceRobot{
+			Robot: robotfile,
+			First: when.Unix(),
+		}
+		it[robotfile] = found
+	}
+	found.Last = when.Unix()
+	found.Uses += 1
+	if len(identity) > 0 {
+		found.Identity = identity
+	}
+}
+
+// Sorted returns robots of space, most recently used first.
+func (it SpaceRobots) Sorted() []*SpaceRobot {
+	result := make([]*SpaceRobot, 0, len(it))
+	for _, entry := range it {
+		result = append(result, entry)
+	}
+	sort.Slice(result, func(left, right int) bool {
+		if result[left].Last != result[right].Last {
+			return result[left].Last > result[right].Last
+		}
+		return result[left].Robot < result[right].Robot
+	})
+	return result
+}
+
+// LoadSpaceRobots loads recorded robots of given space. Missing or broken
+// file just means that there is no robots known.
+func LoadSpaceRobots(space string) SpaceRobots {
+	result := make(SpaceRobots)
+	content, err := os.ReadFile(spaceRobotsFile(space))
+	if err != nil {
+		return result
+	}
+	err = json.Unmarshal(content, &result)
+	if err != nil {
+		common.Debug("Ignoring space robots %q, reason: %v", spaceRobotsFile(space), err)
+		return make(SpaceRobots)
+	}
+	return result
+}
+
+// RecordSpaceRobot records that given robot used holotree space, and which
+// identity.yaml the space had, so that later changes in robot environment
+// definition can be explained.
+func RecordSpaceRobot(space, robotfile string) {
+	if len(space) == 0 || len(robotfile) == 0 {
+		return
+	}
+	identity, err := os.ReadFile(filepath.Join(space, "identity.yaml"))
+	if err != nil {
+		identity = []byte{}
+	}
+	robots := LoadSpaceRobots(space)
+	robots.record(robotfile, string(identity), time.Now())
+	content, err := json.MarshalIndent(robots, "", "  ")
+	if err == nil {
+		err = pathlib.WriteFile(spaceRobotsFile(space), content, 0o644)
+	}
+	if err != nil {
+		common.Debug("Could not record space robot for %q, reason: %v", space, err)
+	}
+}
+
+func latestSpaceIdentity(basedir, robotfile string) (string, []byte, bool) {
+	var latest *SpaceRobot
+	space := ""
+	for _, filename := range pathlib.Glob(basedir, "*.robots") {
+		found, ok := LoadSpaceRobots(strings.TrimSuffix(filename, ".robots"))[robotfile]
+		if !ok || len(found.Identity) == 0 {
+			continue
+		}
+		if latest == nil || found.Last > latest.Last {
+			latest, space = found, strings.TrimSuffix(filename, ".robots")
+		}
+	}
+	if latest == nil {
+		return "", nil, false
+	}
+	return space, []byte(latest.Identity), true
+}
+
+// LastBuiltIdentity finds identity.yaml which was used when given robot last
+// got its environment, and space where that happened.
+func LastBuiltIdentity(robotfile string) (string, []byte, bool) {
+	return latestSpaceIdentity(common.HolotreeLocation(), robotfile)
+}
@@ -0,0 +1,60 @@
+package htfs
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestSpaceRobotsAreRecordedPerRobot(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	space := filepath.Join(t.TempDir(), "shared")
+	must.Equal(0, len(LoadSpaceRobots(space)))
+
+	RecordSpaceRobot(space, "/robots/first/robot.yaml")
+	RecordSpaceRobot(space, "/robots/second/robot.yaml")
+	RecordSpaceRobot(space, "/robots/first/robot.yaml")
+	RecordSpaceRobot("", "/robots/ignored/robot.yaml")
+
+	robots := LoadSpaceRobots(space)
+	must.Equal(2, len(robots))
+	must.Equal(int64(2), robots["/robots/first/robot.yaml"].Uses)
+	must.Equal(int64(1), robots["/robots/second/robot.yaml"].Uses)
+	must.Equal(2, len(robots.Sorted()))
+
+	must.Nil(os.WriteFile(spaceRobotsFile(space), []byte("{broken"), 0o644))
+	must.Equal(0, len(LoadSpaceRobots(space)))
+}
+
+func TestLatestSpaceIdentityIsFoundForRobot(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	basedir := t.TempDir()
+	older := filepath.Join(basedir, "older")
+	newer := filepath.Join(basedir, "newer")
+	must.Nil(os.MkdirAll(older, 0o755))
+	must.Nil(os.MkdirAll(newer, 0o755))
+	must.Nil(os.WriteFile(filepath.Join(older, "identity.yaml"), []byte("older"), 0o644))
+	must.Nil(os.WriteFile(filepath.Join(newer, "identity.yaml"), []byte("newer"), 0o644))
+
+	_, _, ok := latestSpaceIdentity(basedir, "/robots/first/robot.yaml")
+	wont.True(ok)
+
+	RecordSpaceRobot(older, "/robots/first/robot.yaml")
+	RecordSpaceRobot(newer, "/robots/second/robot.yaml")
+	robots := LoadSpaceRobots(older)
+	robots["/robots/first/robot.yaml"].Last -= 100
+	content, err := json.Marshal(robots)
+	must.Nil(err)
+	must.Nil(os.WriteFile(spaceRobotsFile(older), content, 0o644))
+	RecordSpaceRobot(newer, "/robots/first/robot.yaml")
+
+	space, identity, ok := latestSpaceIdentity(basedir, "/robots/first/robot.yaml")
+	must.True(ok)
+	must.Equal(newer, space)
+	must.Equal("newer", string(identity))
+}
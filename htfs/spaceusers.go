@@ -0,0 +1,131 @@
+package htfs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"sort"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pathlib"
+)
+
+// SpaceUser is one user/controller combination that has used holotree space,
+// as recorded in space ".users" file next to ".use" file.
+type SpaceUser struct {
+	User       string `json:"user"`
+	Controller string `json:"controller"`
+	First      int64  `json:"first"`
+	Last       int64  `json:"last"`
+	Uses       int64  `json:"uses"`
+}
+
+type SpaceUsers map[string]*SpaceUser
+
+// UserUsage is aggregated usage of one user over all holotree spaces. Size
+// of space is shared evenly between all users of that space.
+type UserUsage struct {
+	User        string
+	Controllers []string
+	Spaces      int
+	Bytes       uint64
+	Uses        int64
+	Last        int64
+}
+
+func spaceUsersFile(space string) string {
+	return fmt.Sprintf("%s.users", space)
+}
+
+func currentUserName() string {
+	who, err := user.Current()
+	if err != nil || len(who.Username) == 0 {
+		return common.SymbolicUserIdentity()
+	}
+	return who.Username
+}
+
+func (it SpaceUsers) record(username, controller string, when time.Time) {
+	key := fmt.Sprintf("%s/%s", username, controller)
+	found, ok := it[key]
+	if !ok {
+		found = &SpaceUser{
+			User:       username,
+			Controller: controller,
+			First:      when.Unix(),
+		}
+		it[key] = found
+	}
+	found.Last = when.Unix()
+	found.Uses += 1
+}
+
+// LoadSpaceUsers loads recorded users of given space. Missing or broken file
+// just means that there is no users known.
+func LoadSpaceUsers(space string) SpaceUsers {
+	result := make(SpaceUsers)
+	content, err := os.ReadFile(spaceUsersFile(space))
+	if err != nil {
+		return result
+	}
+	err = json.Unmarshal(content, &result)
+	if err != nil {
+		common.Debug("Ignoring space users %q, reason: %v", spaceUsersFile(space), err)
+		return make(SpaceUsers)
+	}
+	return result
+}
+
+func recordSpaceUser(space string) {
+	users := LoadSpaceUsers(space)
+	users.record(currentUserName(), common.ControllerIdentity(), time.Now())
+	content, err := json.MarshalIndent(users, "", "  ")
+	if err == nil {
+		err = pathlib.WriteFile(spaceUsersFile(space), content, 0o644)
+	}
+	if err != nil {
+		common.Debug("Could not record space user for %q, reason: %v", space, err)
+	}
+}
+
+// UsageByUser aggregates space usage per user over given spaces.
+func UsageByUser(spaces []string, sizes map[string]uint64) []*UserUsage {
+	usages := make(map[string]*UserUsage)
+	controllers := make(map[string]map[string]bool)
+	for _, space := range spaces {
+		users := LoadSpaceUsers(space)
+		owners := make(map[string]bool)
+		for _, entry := range users {
+			owners[entry.User] = true
+		}
+		for _, entry := range users {
+			usage, ok := usages[entry.User]
+			if !ok {
+				usage = &UserUsage{User: entry.User}
+				usages[entry.User] = usage
+				controllers[entry.User] = make(map[string]bool)
+			}
+			controllers[entry.User][entry.Controller] = true
+			usage.Uses += entry.Uses
+			usage.Last = max(usage.Last, entry.Last)
+		}
+		for owner := range owners {
+			usages[owner].Spaces += 1
+			usages[owner].Bytes += sizes[space] / uint64(len(owners))
+		}
+	}
+	result := make([]*UserUsage, 0, len(usages))
+	for name, usage := range usages {
+		for controller := range controllers[name] {
+			usage.Controllers = append(usage.Controllers, controller)
+		}
+		sort.Strings(usage.Controllers)
+		result = append(result, usage)
+	}
+	sort.Slice(result, func(left, right int) bool {
+		return result[left].User < result[right].User
+	})
+	return result
+}
@@ -0,0 +1,51 @@
+package htfs
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestSpaceUsersAreRecordedAndAggregated(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	first := filepath.Join(t.TempDir(), "first")
+	second := filepath.Join(t.TempDir(), "second")
+	must.Equal(0, len(LoadSpaceUsers(first)))
+
+	recordSpaceUser(first)
+	recordSpaceUser(first)
+	users := LoadSpaceUsers(first)
+	must.Equal(1, len(users))
+	for _, entry := range users {
+		must.Equal(currentUserName(), entry.User)
+		must.Equal(int64(2), entry.Uses)
+	}
+
+	when := time.Unix(1700000000, 0)
+	shared := make(SpaceUsers)
+	shared.record("alice", "rcc.user", when)
+	shared.record("alice", "rcc.vscode", when.Add(time.Hour))
+	shared.record("bob", "rcc.user", when)
+	content, err := json.Marshal(shared)
+	must.Nil(err)
+	must.Nil(os.WriteFile(spaceUsersFile(second), content, 0o644))
+
+	must.Nil(os.WriteFile(spaceUsersFile(first), []byte("{broken"), 0o644))
+	must.Equal(0, len(LoadSpaceUsers(first)))
+
+	usages := UsageByUser([]string{first, second}, map[string]uint64{second: 1000})
+	must.Equal(2, len(usages))
+	must.Equal("alice", usages[0].User)
+	must.Equal([]string{"rcc.user", "rcc.vscode"}, usages[0].Controllers)
+	must.Equal(1, usages[0].Spaces)
+	must.Equal(uint64(500), usages[0].Bytes)
+	must.Equal(int64(2), usages[0].Uses)
+	must.Equal(when.Add(time.Hour).Unix(), usages[0].Last)
+	must.Equal("bob", usages[1].User)
+	must.Equal(uint64(500), usages[1].Bytes)
+}
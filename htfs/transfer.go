@@ -0,0 +1,110 @@
+package htfs
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/pretty"
+)
+
+var (
+	transferLock      sync.Mutex
+	transferSummaries = []*TransferSummary{}
+)
+
+// TransferSummary is verification summary of one holotree export or import.
+// Skipped are blobs that were already present (or already exported), and
+// Verified are blobs whose content matched their digest.
+type TransferSummary struct {
+	Operation  string  `json:"operation"`
+	Archive    string  `json:"archive"`
+	Catalogs   int     `json:"catalogs"`
+	Files      int     `json:"files"`
+	Bytes      int64   `json:"bytes"`
+	Verified   int     `json:"verified"`
+	Skipped    int     `json:"skipped"`
+	Seconds    float64 `json:"seconds"`
+	Throughput float64 `json:"throughput"`
+
+	label   string
+	started time.Time
+	counter *pretty.Counter
+	sync.Mutex
+}
+
+func NewTransferSummary(operation, archive string) *TransferSummary {
+	label := strings.ToUpper(operation[:1]) + operation[1:]
+	return &TransferSummary{
+		Operation: operation,
+		Archive:   archive,
+		label:     label,
+		started:   time.Now(),
+		counter:   pretty.NewCounter(fmt.Sprintf("%s files", label), 0),
+	}
+}
+
+func (it *TransferSummary) Catalog() {
+	it.Lock()
+	defer it.Unlock()
+
+	it.Catalogs += 1
+}
+
+func (it *TransferSummary) File(size int64, verified bool) {
+	it.Lock()
+	defer it.Unlock()
+
+	it.Files += 1
+	it.Bytes += size
+	if verified {
+		it.Verified += 1
+	}
+	it.counter.Tick()
+}
+
+func (it *TransferSummary) Skip() {
+	it.Lock()
+	defer it.Unlock()
+
+	it.Skipped += 1
+	it.counter.Tick()
+}
+
+func (it *TransferSummary) String() string {
+	value, suffix := pathlib.HumaneSizer(it.Bytes)
+	speed, unit := pathlib.HumaneSizer(int64(it.Throughput))
+	return fmt.Sprintf("%d catalogs, %d files (%3.1f%s), %d digests verified, %d duplicates skipped, in %3.1fs (%3.1f%s/s)", it.Catalogs, it.Files, value, suffix, it.Verified, it.Skipped, it.Seconds, speed, unit)
+}
+
+// Done finalizes summary, reports it, and keeps it for TransferSummaries.
+func (it *TransferSummary) Done() {
+	it.Lock()
+	it.counter.Done()
+	it.Seconds = time.Since(it.started).Seconds()
+	if it.Seconds > 0 {
+		it.Throughput = float64(it.Bytes) / it.Seconds
+	}
+	it.Unlock()
+
+	common.Log("%s summary for %q: %s.", it.label, it.Archive, it)
+	common.Timeline("%s %q: %s", it.Operation, it.Archive, it)
+
+	transferLock.Lock()
+	defer transferLock.Unlock()
+	transferSummaries = append(transferSummaries, it)
+}
+
+// TransferSummaries gives summaries of all exports and imports completed in
+// this process, in completion order.
+func TransferSummaries() []*TransferSummary {
+	transferLock.Lock()
+	defer transferLock.Unlock()
+
+	result := make([]*TransferSummary, len(transferSummaries))
+	copy(result, transferSummaries)
+	return result
+}
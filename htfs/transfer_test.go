@@ -0,0 +1,86 @@
+package htfs
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/conda"
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestExportIsVerifiedAndSummarized(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	t.Setenv("ROBOCORP_HOME", t.TempDir())
+	location := t.TempDir()
+	writeTestFile(t, filepath.Join(location, "chromium-1091", "chrome"), "pretend this is chrome")
+	writeTestFile(t, filepath.Join(location, "chromium-1091", "copy", "chrome"), "pretend this is chrome")
+	writeTestFile(t, filepath.Join(location, "chromium-1091", installationMarker), "")
+
+	library, err := New()
+	must.Nil(err)
+	must.Nil(recordBrowserBuild(library, location, "chromium-1091"))
+	catalogs := CatalogNames()
+	must.Equal(1, len(catalogs))
+
+	archive := filepath.Join(t.TempDir(), "hololib.zip")
+	must.Nil(library.Export(catalogs, nil, archive))
+	summaries := TransferSummaries()
+	summary := summaries[len(summaries)-1]
+	must.Equal("export", summary.Operation)
+	must.Equal(archive, summary.Archive)
+	must.Equal(1, summary.Catalogs)
+	must.Equal(3, summary.Files)
+	must.Equal(2, summary.Verified)
+	must.Equal(1, summary.Skipped)
+
+	blobs, err := filepath.Glob(filepath.Join(common.HololibLibraryLocation(), "*", "*", "*", "*"))
+	must.Nil(err)
+	must.True(len(blobs) > 0)
+	for _, blob := range blobs {
+		must.Nil(os.WriteFile(blob, []byte("tampered"), 0o644))
+	}
+	wont.Nil(library.Export(catalogs, nil, archive))
+}
+
+func TestExportCarriesLocalPackagesOfCatalogs(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	t.Setenv("ROBOCORP_HOME", t.TempDir())
+	location := t.TempDir()
+	writeTestFile(t, filepath.Join(location, "chromium-1091", "chrome"), "pretend this is chrome")
+	writeTestFile(t, filepath.Join(location, "chromium-1091", installationMarker), "")
+
+	library, err := New()
+	must.Nil(err)
+	must.Nil(recordBrowserBuild(library, location, "chromium-1091"))
+	catalogs := CatalogNames()
+	must.Equal(1, len(catalogs))
+
+	entry := "private-1.0-py3-none-any.whl#sha256=5012353aa6a0734b468ac4dcad5b802e70382f41303f87cfe89d223e3a6be654"
+	must.Equal([]string{entry}, blueprintLocalPackages([]byte("dependencies:\n- python=3.10.12\nlocalPackages:\n- "+entry+"\n")))
+	stored, ok := conda.LocalPackageFile(entry)
+	must.True(ok)
+	writeTestFile(t, stored, "not really a wheel")
+
+	catalog := filepath.Join(common.HololibCatalogLocation(), catalogs[0])
+	fs, err := NewRoot(".")
+	must.Nil(err)
+	must.Nil(fs.LoadFrom(catalog))
+	fs.LocalPackages = []string{entry}
+	must.Nil(fs.SaveAs(catalog))
+
+	archive := filepath.Join(t.TempDir(), "hololib.zip")
+	must.Nil(library.Export(catalogs, nil, archive))
+	reader, err := zip.OpenReader(archive)
+	must.Nil(err)
+	defer reader.Close()
+	names := map[string]bool{}
+	for _, file := range reader.File {
+		names[file.Name] = true
+	}
+	must.True(names["localpackages/5012353aa6a0734b468ac4dcad5b802e70382f41303f87cfe89d223e3a6be654/private-1.0-py3-none-any.whl"])
+}
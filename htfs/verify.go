@@ -0,0 +1,81 @@
+package htfs
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+)
+
+func digestBlob(source io.Reader, digest io.Writer) error {
+	buffered := bufio.NewReader(source)
+	var reader io.Reader = buffered
+	magic, err := buffered.Peek(2)
+	if err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		wrapper, err := gzip.NewReader(buffered)
+		if err != nil {
+			return err
+		}
+		defer wrapper.Close()
+		reader = wrapper
+	}
+	_, err = io.Copy(digest, reader)
+	return err
+}
+
+// BlobDigest digests (possibly compressed) blob content using legacy digest,
+// and only when that does not match expected name, blob is read again using
+// BLAKE3 from reopened source. Without reopen, legacy digest is returned.
+func BlobDigest(source io.Reader, expected string, reopen func() (io.ReadCloser, error)) (string, error) {
+	legacy := NewDigest("")
+	err := digestBlob(source, legacy)
+	if err != nil {
+		return "", err
+	}
+	actual := fmt.Sprintf("%02x", legacy.Sum(nil))
+	if actual == expected || reopen == nil {
+		return actual, nil
+	}
+	again, err := reopen()
+	if err != nil {
+		return "", err
+	}
+	defer again.Close()
+	blake3 := NewDigest(DigestBlake3)
+	err = digestBlob(again, blake3)
+	if err != nil {
+		return "", err
+	}
+	if fmt.Sprintf("%02x", blake3.Sum(nil)) == expected {
+		return expected, nil
+	}
+	return actual, nil
+}
+
+func blobFileDigest(fullpath, expected string) (string, error) {
+	reopen := func() (io.ReadCloser, error) {
+		return os.Open(fullpath)
+	}
+	source, err := reopen()
+	if err != nil {
+		return "", err
+	}
+	defer source.Close()
+	actual, err := BlobDigest(source, expected, reopen)
+	if err != nil {
+		return "", fmt.Errorf("Could not digest %q, reason: %v", fullpath, err)
+	}
+	return actual, nil
+}
+
+// VerifyBlobFile checks blob against legacy digest first, and only on
+// mismatch reads it again using BLAKE3, so that verifying legacy blobs
+// costs single digest calculation.
+func VerifyBlobFile(fullpath, expected string) error {
+	actual, err := blobFileDigest(fullpath, expected)
+	if err != nil || actual == expected {
+		return err
+	}
+	return fmt.Errorf("Corrupted blob %q, expected %s, actual %s", fullpath, expected, actual)
+}
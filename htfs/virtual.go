@@ -70,7 +70,8 @@ func (it *virtual) Record(blueprint []byte) (err error) {
 	err = fs.Lift()
 	fail.On(err != nil, "Failed to lift structure out of stage: %v", err)
 	common.Timeline("holotree (re)locator start (virtual)")
-	err = fs.AllFiles(Locator(it.Identity()))
+	fs.Algorithm = SelectedDigest()
+	err = fs.AllFiles(Locator(it.Identity(), fs.Algorithm))
 	fail.On(err != nil, "Failed to apply relocate to stage: %v", err)
 	common.Timeline("holotree (re)locator done (virtual)")
 	it.registry = make(map[string]string)
@@ -139,6 +140,7 @@ func (it *virtual) RestoreTo(blueprint []byte, label, controller, space string,
 	defer common.Timeline("- dirty %d/%d", score.dirty, score.total)
 	common.Debug("Holotree dirty workload: %d/%d\n", score.dirty, score.total)
 	journal.CurrentBuildEvent().Dirty(score.Dirtyness())
+	score.reportValidation(targetdir)
 	fs.Controller = controller
 	fs.Space = space
 	err = fs.SaveAs(metafile)
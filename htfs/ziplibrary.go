@@ -116,6 +116,7 @@ func (it *ziplibrary) RestoreTo(blueprint []byte, label, controller, space strin
 	defer closer()
 	err = fs.ReadFrom(reader)
 	fail.On(err != nil, "Failed to read catalog %q -> %v", catalog, err)
+	fail.Fast(checkAffinity(catalog, fs.Machine))
 	targetdir := filepath.Join(fs.HolotreeBase(), label)
 	metafile := fmt.Sprintf("%s.meta", targetdir)
 	lockfile := fmt.Sprintf("%s.lck", targetdir)
@@ -149,6 +150,7 @@ func (it *ziplibrary) RestoreTo(blueprint []byte, label, controller, space strin
 	defer common.Timeline("- dirty %d/%d", score.dirty, score.total)
 	common.Debug("Holotree dirty workload: %d/%d\n", score.dirty, score.total)
 	journal.CurrentBuildEvent().Dirty(score.Dirtyness())
+	score.reportValidation(targetdir)
 	fs.Controller = controller
 	fs.Space = space
 	err = fs.SaveAs(metafile)
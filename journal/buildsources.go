@@ -0,0 +1,119 @@
+package journal
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	BuildSourceCache   = "cache"
+	BuildSourceRemote  = "remote"
+	BuildSourcePartial = "partial"
+	BuildSourceFull    = "full"
+)
+
+var (
+	buildSources = []string{BuildSourceCache, BuildSourceRemote, BuildSourcePartial, BuildSourceFull}
+)
+
+type BuildSourceCount struct {
+	Source  string  `json:"source"`
+	Count   int     `json:"count"`
+	Percent float64 `json:"percent"`
+	Setup   float64 `json:"average_setup_seconds"`
+}
+
+// BuildSources tells where environments came from: pure cache hits from
+// hololib, pulls from remote origin, partial (pip-only) rebuilds on top of
+// cached base layer, or full builds.
+type BuildSources struct {
+	Weeks    uint                `json:"weeks"`
+	Total    int                 `json:"total"`
+	HitRatio float64             `json:"hit_ratio"`
+	NoBuild  float64             `json:"no_build_ratio"`
+	Sources  []*BuildSourceCount `json:"sources"`
+}
+
+// BuildSource records where environment came from.
+func (it *BuildEvent) BuildSource(source string) {
+	it.Source = source
+}
+
+func setupTime(the *BuildEvent) float64 {
+	if the.RobotStart > 0 {
+		return the.RobotStart - the.Started
+	}
+	return the.Finished - the.Started
+}
+
+// Sources aggregates build sources of events. Events without known source
+// (older rcc versions, and runs without environment) are not counted.
+func (it BuildEvents) Sources(weeks uint) *BuildSources {
+	counts := make(map[string]int)
+	setups := make(map[string]float64)
+	total := 0
+	for _, event := range it {
+		if len(event.Source) == 0 {
+			continue
+		}
+		total++
+		counts[event.Source]++
+		setups[event.Source] += setupTime(event)
+	}
+	result := &BuildSources{
+		Weeks:   weeks,
+		Total:   total,
+		Sources: make([]*BuildSourceCount, 0, len(buildSources)),
+	}
+	for _, source := range buildSources {
+		entry := &BuildSourceCount{Source: source, Count: counts[source]}
+		if entry.Count > 0 {
+			entry.Percent = 100.0 * float64(entry.Count) / float64(total)
+			entry.Setup = setups[source] / float64(entry.Count)
+		}
+		result.Sources = append(result.Sources, entry)
+	}
+	if total > 0 {
+		result.HitRatio = float64(counts[BuildSourceCache]) / float64(total)
+		result.NoBuild = float64(counts[BuildSourceCache]+counts[BuildSourceRemote]) / float64(total)
+	}
+	return result
+}
+
+// BuildSourceStatistics loads build sources of given number of weeks.
+func BuildSourceStatistics(weeks uint) (*BuildSources, error) {
+	stats, err := Stats(weeks)
+	if err != nil {
+		return nil, err
+	}
+	return stats.Sources(weeks), nil
+}
+
+// Summary gives one line summary of build sources.
+func (it *BuildSources) Summary() string {
+	if it.Total == 0 {
+		return "no environment builds recorded"
+	}
+	parts := make([]string, 0, len(it.Sources))
+	for _, entry := range it.Sources {
+		parts = append(parts, fmt.Sprintf("%d %s", entry.Count, entry.Source))
+	}
+	return fmt.Sprintf("%.1f%% cache hits from %d environments (%s)", 100.0*it.HitRatio, it.Total, strings.Join(parts, ", "))
+}
+
+// Write writes build sources as table.
+func (it *BuildSources) Write(sink io.Writer) {
+	tabbed := tabwriter.NewWriter(sink, 2, 4, 2, ' ', tabwriter.AlignRight)
+	tabbed.Write(sprint("Environment sources: %d environments [%d full weeks]\t\n", it.Total, it.Weeks))
+	tabbed.Write([]byte("\n"))
+	tabbed.Write([]byte("Source \tCount\tShare\tAverage setup\t\n"))
+	for _, entry := range it.Sources {
+		tabbed.Write(tabs(entry.Source+" ", asCount(entry.Count), asPercent(entry.Percent), asSecond(entry.Setup)))
+	}
+	tabbed.Write([]byte("\n"))
+	tabbed.Write(sprint("Cache hit ratio \t%s\t\n", asPercent(100.0*it.HitRatio)))
+	tabbed.Write(sprint("Without local build \t%s\t\n", asPercent(100.0*it.NoBuild)))
+	tabbed.Flush()
+}
@@ -0,0 +1,36 @@
+package journal_test
+
+import (
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+	"github.com/joshyorko/rcc/journal"
+)
+
+func TestBuildSourcesAggregateHitRatio(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	events := journal.BuildEvents{
+		{Source: journal.BuildSourceCache, Started: 1, Finished: 3},
+		{Source: journal.BuildSourceCache, Started: 1, RobotStart: 2, Finished: 9},
+		{Source: journal.BuildSourceCache, Started: 1, Finished: 4},
+		{Source: journal.BuildSourceRemote, Started: 1, Finished: 11},
+		{Source: journal.BuildSourcePartial, Started: 1, Finished: 31},
+		{Source: journal.BuildSourceFull, Started: 1, Finished: 121},
+		{Started: 1, Finished: 2},
+	}
+	sources := events.Sources(4)
+	must.Equal(6, sources.Total)
+	must.Equal(0.5, sources.HitRatio)
+	must.Equal(4.0/6.0, sources.NoBuild)
+	must.Equal(4, len(sources.Sources))
+	must.Equal(journal.BuildSourceCache, sources.Sources[0].Source)
+	must.Equal(3, sources.Sources[0].Count)
+	must.Equal(2.0, sources.Sources[0].Setup)
+	must.Equal(120.0, sources.Sources[3].Setup)
+	must.Equal("50.0% cache hits from 6 environments (3 cache, 1 remote, 1 partial, 1 full)", sources.Summary())
+
+	empty := journal.BuildEvents{}.Sources(4)
+	must.Equal(0, empty.Total)
+	wont.Equal("", empty.Summary())
+}
@@ -37,6 +37,7 @@ type (
 		Success       bool   `json:"success"`
 		Retry         bool   `json:"retry"`
 		Run           bool   `json:"run"`
+		Cancelled     bool   `json:"cancelled"`
 		Controller    string `json:"controller"`
 		Space         string `json:"space"`
 		BlueprintHash string `json:"blueprint"`
@@ -53,6 +54,8 @@ type (
 		RobotEnd        float64 `json:"robotend"`
 		Finished        float64 `json:"finished"`
 		Dirtyness       float64 `json:"dirtyness"`
+		Antivirus       float64 `json:"antivirus,omitempty"`
+		Source          string  `json:"source,omitempty"`
 	}
 )
 
@@ -218,6 +221,9 @@ func MakeStatistics(weeks uint, assistants, robots, prepares, variables bool) (i
 		}
 		return the.Finished - the.Started
 	})
+	stats.Statsline(tabbed, "Antivirus slowdown    ", asSecond, func(the *BuildEvent) float64 {
+		return the.Antivirus
+	})
 	stats.Statsline(tabbed, "Pre-run               ", asSecond, func(the *BuildEvent) float64 {
 		if the.PreRunDone > 0 {
 			return the.PreRunDone - the.RestoreDone
@@ -428,6 +434,13 @@ func (it *BuildEvent) Successful() {
 	it.Success = true
 }
 
+// Cancel marks build cancelled by interrupt, and records partial timings
+// of build phases into run journal.
+func (it *BuildEvent) Cancel() {
+	it.Cancelled = true
+	common.RunJournal("environment", "cancelled", "build of %q cancelled at %.3fs (prepare: %.3f, micromamba: %.3f, pip: %.3f, postinstall: %.3f, record: %.3f, restore: %.3f)", it.BlueprintHash, it.stowatch(), it.Prepared, it.MicromambaDone, it.PipDone, it.PostInstallDone, it.RecordDone, it.RestoreDone)
+}
+
 func (it *BuildEvent) StartNow(force bool) {
 	it.Started = it.stowatch()
 	it.Force = force
@@ -470,6 +483,12 @@ func (it *BuildEvent) Dirty(dirtyness float64) {
 	it.Dirtyness = dirtyness
 }
 
+// AntivirusSlowdown records estimated seconds that restore was slowed down
+// by antivirus scanning.
+func (it *BuildEvent) AntivirusSlowdown(seconds float64) {
+	it.Antivirus = seconds
+}
+
 func (it *BuildEvent) RestoreComplete() {
 	it.RestoreDone = it.stowatch()
 }
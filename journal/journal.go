@@ -91,6 +91,10 @@ func AppendJournal(journalname string, blob []byte) (err error) {
 	if common.WarrantyVoided() {
 		return nil
 	}
+	_, err = common.RotateLog(journalname)
+	if err != nil {
+		common.Debug("Could not rotate journal %v -> %v", journalname, err)
+	}
 	handle, err := os.OpenFile(journalname, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
 	fail.On(err != nil, "Failed to open event journal %v -> %v", journalname, err)
 	defer handle.Close()
@@ -0,0 +1,105 @@
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/fail"
+	"github.com/joshyorko/rcc/pathlib"
+)
+
+// RunRecord is one robot run in run history, with enough details to rerun it.
+type RunRecord struct {
+	When        int64   `json:"when"`
+	Robot       string  `json:"robot"`
+	Task        string  `json:"task"`
+	Space       string  `json:"space"`
+	Environment string  `json:"environment,omitempty"`
+	Developer   bool    `json:"dev,omitempty"`
+	Elapsed     float64 `json:"elapsed"`
+	Exitcode    int     `json:"exitcode"`
+	Success     bool    `json:"success"`
+	Artifacts   string  `json:"artifacts"`
+	Inputs      string  `json:"inputs,omitempty"`
+}
+
+func (it *RunRecord) TaskName() string {
+	if len(it.Task) == 0 {
+		return "(default)"
+	}
+	return it.Task
+}
+
+func RunHistoryFilenameFor(stamp time.Time) string {
+	year, week := stamp.ISOWeek()
+	filename := fmt.Sprintf("runs_%s_%04d_%02d.log", common.UserHomeIdentity(), year, week)
+	return filepath.Join(common.JournalLocation(), filename)
+}
+
+func runHistoryFilenamesFor(weekcount int) []string {
+	weekstep := -7 * 24 * time.Hour
+	timestamp := time.Now()
+	result := make([]string, 0, weekcount+1)
+	for weekcount >= 0 {
+		result = append(result, RunHistoryFilenameFor(timestamp))
+		timestamp = timestamp.Add(weekstep)
+		weekcount--
+	}
+	return result
+}
+
+func RecordRun(record *RunRecord) (err error) {
+	defer fail.Around(&err)
+
+	if record.When == 0 {
+		record.When = time.Now().Unix()
+	}
+	blob, err := json.Marshal(record)
+	fail.On(err != nil, "Could not serialize run record: %v -> %v", record.Robot, err)
+	return AppendJournal(RunHistoryFilenameFor(time.Unix(record.When, 0)), blob)
+}
+
+// RunHistory returns runs of given robot (all robots, if empty) from last
+// weeks, newest first.
+func RunHistory(robot string, weeks uint) (result []*RunRecord, err error) {
+	defer fail.Around(&err)
+
+	result = make([]*RunRecord, 0, 20)
+	for _, journalname := range runHistoryFilenamesFor(int(weeks)) {
+		if !pathlib.IsFile(journalname) {
+			continue
+		}
+		handle, err := os.Open(journalname)
+		fail.On(err != nil, "Failed to open run history %v -> %v", journalname, err)
+		defer handle.Close()
+		source := bufio.NewReader(handle)
+	innerloop:
+		for {
+			line, err := source.ReadBytes('\n')
+			if err == io.EOF {
+				break innerloop
+			}
+			fail.On(err != nil, "Failed to read %s.", journalname)
+			record := &RunRecord{}
+			err = json.Unmarshal(line, record)
+			if err != nil {
+				continue innerloop
+			}
+			if len(robot) > 0 && record.Robot != robot {
+				continue innerloop
+			}
+			result = append(result, record)
+		}
+	}
+	sort.SliceStable(result, func(left, right int) bool {
+		return result[left].When > result[right].When
+	})
+	return result, nil
+}
@@ -0,0 +1,36 @@
+package journal_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/hamlet"
+	"github.com/joshyorko/rcc/journal"
+)
+
+func TestRunHistoryIsFilteredPerRobotNewestFirst(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	t.Setenv("ROBOCORP_HOME", t.TempDir())
+	must.Nil(os.MkdirAll(common.JournalLocation(), 0o755))
+
+	now := time.Now().Unix()
+	must.Nil(journal.RecordRun(&journal.RunRecord{When: now - 60, Robot: "/robots/a/robot.yaml", Task: "First", Space: "user", Success: true}))
+	must.Nil(journal.RecordRun(&journal.RunRecord{When: now - 30, Robot: "/robots/b/robot.yaml", Task: "Other", Space: "user"}))
+	must.Nil(journal.RecordRun(&journal.RunRecord{When: now, Robot: "/robots/a/robot.yaml", Space: "dev", Exitcode: 3}))
+
+	history, err := journal.RunHistory("/robots/a/robot.yaml", 1)
+	must.Nil(err)
+	must.Equal(2, len(history))
+	must.Equal("(default)", history[0].TaskName())
+	must.Equal(3, history[0].Exitcode)
+	wont.True(history[0].Success)
+	must.Equal("First", history[1].TaskName())
+	must.True(history[1].Success)
+
+	everything, err := journal.RunHistory("", 1)
+	must.Nil(err)
+	must.Equal(3, len(everything))
+}
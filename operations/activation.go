@@ -0,0 +1,142 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	ShellBash       = "bash"
+	ShellZsh        = "zsh"
+	ShellFish       = "fish"
+	ShellPowershell = "powershell"
+	ShellCmd        = "cmd"
+)
+
+var (
+	ActivationShells = []string{ShellBash, ShellZsh, ShellFish, ShellPowershell, ShellCmd}
+)
+
+// DefaultActivationShell guesses shell of user from SHELL (and on Windows
+// from PSModulePath), falling back to bash, or to cmd on Windows.
+func DefaultActivationShell(windows bool) string {
+	if windows {
+		if len(os.Getenv("PSModulePath")) > 0 {
+			return ShellPowershell
+		}
+		return ShellCmd
+	}
+	switch filepath.Base(os.Getenv("SHELL")) {
+	case ShellZsh:
+		return ShellZsh
+	case ShellFish:
+		return ShellFish
+	}
+	return ShellBash
+}
+
+func posixValue(value string) string {
+	return fmt.Sprintf("'%s'", strings.ReplaceAll(value, "'", `'\''`))
+}
+
+func cmdValue(value string) string {
+	return strings.ReplaceAll(value, "%", "%%")
+}
+
+// ActivationScript gives script, that sets variables of holotree space into
+// current shell when sourced (or evaluated), and defines "deactivate" to
+// restore previous values (except in cmd, which has no functions). Prompt
+// is prefixed with name of space, like with virtualenvs.
+func ActivationScript(shell, space string, items []string) (string, error) {
+	variables := splitVariables(items)
+	label := posixValue(fmt.Sprintf("(%s) ", space))
+	lines := []string{}
+	add := func(form string, details ...interface{}) {
+		lines = append(lines, fmt.Sprintf(form, details...))
+	}
+	switch shell {
+	case ShellBash, ShellZsh:
+		add("# rcc activation of holotree space %q, use: source this-file", space)
+		if shell == ShellZsh {
+			add("if (( ${+functions[deactivate]} )); then deactivate; fi")
+		} else {
+			add("if [ \"$(type -t deactivate)\" = function ]; then deactivate; fi")
+		}
+		for at, entry := range variables {
+			add("_RCC_HAD_%d=\"${%s+x}\"; _RCC_OLD_%d=\"${%s-}\"", at, entry.Key, at, entry.Key)
+			add("export %s=%s", entry.Key, posixValue(entry.Value))
+		}
+		add("_RCC_OLD_PS1=\"${PS1-}\"")
+		add("PS1=%s\"${PS1-}\"", label)
+		add("deactivate () {")
+		for at, entry := range variables {
+			add("    if [ -n \"$_RCC_HAD_%d\" ]; then export %s=\"$_RCC_OLD_%d\"; else unset %s; fi", at, entry.Key, at, entry.Key)
+			add("    unset _RCC_HAD_%d _RCC_OLD_%d", at, at)
+		}
+		add("    PS1=\"$_RCC_OLD_PS1\"")
+		add("    unset _RCC_OLD_PS1")
+		add("    unset -f deactivate")
+		add("    hash -r 2>/dev/null")
+		add("}")
+		add("hash -r 2>/dev/null")
+	case ShellFish:
+		add("# rcc activation of holotree space %q, use: source this-file", space)
+		add("functions -q deactivate; and deactivate")
+		for at, entry := range variables {
+			add("set -q %s; and set -g _rcc_had_%d 1; or set -g _rcc_had_%d", entry.Key, at, at)
+			add("set -g _rcc_old_%d $%s", at, entry.Key)
+			add("set -gx %s %s", entry.Key, fishValue(entry.Value))
+		}
+		add("functions -q fish_prompt; and functions -c fish_prompt _rcc_old_fish_prompt")
+		add("function fish_prompt")
+		add("    printf '%%s' %s", fishValue(fmt.Sprintf("(%s) ", space)))
+		add("    functions -q _rcc_old_fish_prompt; and _rcc_old_fish_prompt")
+		add("end")
+		add("function deactivate")
+		for at, entry := range variables {
+			add("    if test -n \"$_rcc_had_%d\"; set -gx %s $_rcc_old_%d; else; set -e %s; end", at, entry.Key, at, entry.Key)
+			add("    set -e _rcc_had_%d _rcc_old_%d", at, at)
+		}
+		add("    functions -e fish_prompt")
+		add("    if functions -q _rcc_old_fish_prompt")
+		add("        functions -c _rcc_old_fish_prompt fish_prompt")
+		add("        functions -e _rcc_old_fish_prompt")
+		add("    end")
+		add("    functions -e deactivate")
+		add("end")
+	case ShellPowershell:
+		add("# rcc activation of holotree space %q, use: . this-file.ps1", space)
+		add("if (Test-Path function:deactivate) { deactivate }")
+		for at, entry := range variables {
+			add("$global:_rcc_old_%d = $env:%s", at, entry.Key)
+			add("$env:%s = %s", entry.Key, powershellValue(entry.Value))
+		}
+		add("if (Test-Path function:prompt) { Copy-Item function:prompt function:global:_rcc_old_prompt }")
+		add("function global:prompt { %s + $(if (Test-Path function:_rcc_old_prompt) { _rcc_old_prompt } else { 'PS> ' }) }", powershellValue(fmt.Sprintf("(%s) ", space)))
+		add("function global:deactivate {")
+		for at, entry := range variables {
+			add("    $env:%s = $global:_rcc_old_%d", entry.Key, at)
+			add("    Remove-Variable -Scope Global -Name _rcc_old_%d", at)
+		}
+		add("    if (Test-Path function:_rcc_old_prompt) {")
+		add("        Copy-Item function:_rcc_old_prompt function:global:prompt")
+		add("        Remove-Item function:_rcc_old_prompt")
+		add("    }")
+		add("    Remove-Item function:deactivate")
+		add("}")
+	case ShellCmd:
+		add("@ECHO OFF")
+		add("REM rcc activation of holotree space %q, use: call this-file.bat", space)
+		add("REM There is no deactivate in cmd, open new window to get back.")
+		for _, entry := range variables {
+			add("SET \"%s=%s\"", entry.Key, cmdValue(entry.Value))
+		}
+		add("IF NOT DEFINED PROMPT SET \"PROMPT=$P$G\"")
+		add("SET \"PROMPT=(%s) %%PROMPT%%\"", cmdValue(space))
+	default:
+		return "", fmt.Errorf("Unknown shell %q, expected one of: %s.", shell, strings.Join(ActivationShells, ", "))
+	}
+	return strings.Join(lines, "\n"), nil
+}
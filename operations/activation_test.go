@@ -0,0 +1,57 @@
+package operations
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestCanCreateActivationScriptsForShells(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	items := []string{"PATH=/env/bin:/usr/bin", "QUOTED=it's 100%", "=broken"}
+
+	content, err := ActivationScript(ShellBash, "dev", items)
+	must.Nil(err)
+	must.True(strings.Contains(content, `export QUOTED='it'\''s 100%'`))
+	must.True(strings.Contains(content, `_RCC_HAD_0="${PATH+x}"; _RCC_OLD_0="${PATH-}"`))
+	must.True(strings.Contains(content, `PS1='(dev) '"${PS1-}"`))
+	must.True(strings.Contains(content, "deactivate () {"))
+	wont.True(strings.Contains(content, "broken"))
+
+	content, err = ActivationScript(ShellZsh, "dev", items)
+	must.Nil(err)
+	must.True(strings.Contains(content, "${+functions[deactivate]}"))
+
+	content, err = ActivationScript(ShellFish, "dev", items)
+	must.Nil(err)
+	must.True(strings.Contains(content, `set -gx QUOTED 'it\'s 100%'`))
+	must.True(strings.Contains(content, "function deactivate"))
+
+	content, err = ActivationScript(ShellPowershell, "dev", items)
+	must.Nil(err)
+	must.True(strings.Contains(content, `$env:QUOTED = 'it''s 100%'`))
+	must.True(strings.Contains(content, "$env:QUOTED = $global:_rcc_old_1"))
+
+	content, err = ActivationScript(ShellCmd, "dev", items)
+	must.Nil(err)
+	must.True(strings.Contains(content, `SET "QUOTED=it's 100%%"`))
+	must.True(strings.Contains(content, `SET "PROMPT=(dev) %PROMPT%"`))
+
+	_, err = ActivationScript("tcsh", "dev", items)
+	wont.Nil(err)
+}
+
+func TestDefaultActivationShellFollowsEnvironment(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	t.Setenv("SHELL", "/usr/bin/fish")
+	must.Equal(ShellFish, DefaultActivationShell(false))
+	t.Setenv("SHELL", "/bin/sh")
+	must.Equal(ShellBash, DefaultActivationShell(false))
+	t.Setenv("PSModulePath", "")
+	must.Equal(ShellCmd, DefaultActivationShell(true))
+	t.Setenv("PSModulePath", `C:\Modules`)
+	must.Equal(ShellPowershell, DefaultActivationShell(true))
+}
@@ -0,0 +1,77 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/pretty"
+)
+
+var (
+	spinnerFrames = []string{"|", "/", "-", "\\"}
+)
+
+func activityWidget() (string, string) {
+	activities, err := pathlib.Activities()
+	if err != nil {
+		return fmt.Sprintf("unknown, reason: %v", err), statusWarning
+	}
+	if len(activities) == 0 {
+		return "no background operations running", statusOk
+	}
+	kinds := make([]string, 0, len(activities))
+	for _, activity := range activities {
+		kind, _ := activity.Activity()
+		kinds = append(kinds, fmt.Sprintf("%s [pid %d]", kind, activity.ProcessID))
+	}
+	return fmt.Sprintf("%d running: %s", len(activities), strings.Join(kinds, ", ")), statusOk
+}
+
+// ShowActivities shows in-flight operations of all rcc processes. Frame
+// rotates spinner between refreshes.
+func ShowActivities(activities pathlib.Lockpids, frame int) {
+	if len(activities) == 0 {
+		pretty.Note("No background operations running.")
+		return
+	}
+	spinner := spinnerFrames[frame%len(spinnerFrames)]
+	if pretty.Compact() {
+		for _, activity := range activities {
+			kind, _ := activity.Activity()
+			common.Stdout("%s%s%s %s [%d]\n", pretty.Cyan, spinner, pretty.Reset, kind, activity.ProcessID)
+		}
+		common.Stdout("\n")
+		return
+	}
+	common.Stdout("%s  %-12s %8s  %-12s %-12s %s%s\n", pretty.White, "Operation", "PID", "Space", "Controller", "User", pretty.Reset)
+	for _, activity := range activities {
+		kind, _ := activity.Activity()
+		common.Stdout("%s%s%s %-12s %8d  %-12s %-12s %s\n", pretty.Cyan, spinner, pretty.Reset, kind, activity.ProcessID, activity.Space, activity.Controller, activity.Username)
+	}
+	common.Stdout("\n")
+}
+
+// CancelActivity asks rcc process running given in-flight operation to stop.
+// Only processes that have visible activity can be cancelled.
+func CancelActivity(activities pathlib.Lockpids, pid int) error {
+	found := ""
+	for _, activity := range activities {
+		if activity.ProcessID == pid {
+			found, _ = activity.Activity()
+		}
+	}
+	if len(found) == 0 {
+		return fmt.Errorf("Process %d is not running any background operation.", pid)
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	err = process.Signal(syscall.SIGTERM)
+	common.RunJournal("activity cancel", fmt.Sprintf("pid=%d operation=%s error=%v", pid, found, err), "background operation cancel")
+	return err
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/joshyorko/rcc/cloud"
+	"github.com/joshyorko/rcc/common"
 	"github.com/joshyorko/rcc/hamlet"
 	"github.com/joshyorko/rcc/mocks"
 	"github.com/joshyorko/rcc/operations"
@@ -150,6 +151,7 @@ func TestCanCreateViewWorkspacesClaims(t *testing.T) {
 func TestCanCallAuthorizeCommand(t *testing.T) {
 	must_be, wont_be := hamlet.Specifications(t)
 
+	t.Setenv(common.RCC_CREDENTIALS_STORE, operations.StorePlain)
 	operations.UpdateCredentials("authz", "https://end", "42", "answer")
 	account := operations.AccountByName("authz")
 	wont_be.Nil(account)
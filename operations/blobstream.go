@@ -0,0 +1,96 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/joshyorko/rcc/cloud"
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/fail"
+	"github.com/joshyorko/rcc/htfs"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/pretty"
+)
+
+func init() {
+	htfs.RemoteCatalog = StreamCatalog
+	htfs.RemoteBlob = StreamBlob
+}
+
+func streamRemoteFile(origin, url, partname string) (err error) {
+	defer fail.Around(&err)
+
+	client, err := cloud.NewUnsafeClient(origin)
+	fail.On(err != nil, "Could not create web client for %q, reason: %v", origin, err)
+
+	sink, err := pathlib.Create(partname)
+	fail.On(err != nil, "Could not create %q, reason: %v", partname, err)
+
+	request := client.NewRequest(url)
+	request.Headers[X_RCC_RANDOM_IDENTITY] = common.RandomIdentifier()
+	authorization, ok := common.RccRemoteAuthorization()
+	if ok {
+		request.Headers[AUTHORIZATION] = authorization
+	}
+	domain, ok := common.RccRemoteDomain()
+	if ok {
+		request.Headers[X_RCC_DOMAIN] = domain
+	}
+	request.Stream = sink
+	response := client.Get(request)
+	sink.Close()
+	common.Trace("status %d from GET %s%s", response.Status, origin, url)
+	fail.On(response.Err != nil, "Streaming %s%s failed, reason: %v", origin, url, response.Err)
+	fail.On(response.Status != 200, "Streaming %s%s failed, status=%d", origin, url, response.Status)
+	return nil
+}
+
+func StreamCatalog(origin, catalogName string, useLock bool) (err error) {
+	defer fail.Around(&err)
+
+	common.TimelineBegin("hololib catalog stream start")
+	defer common.TimelineEnd()
+
+	target := filepath.Join(common.HololibStreamingLocation(), catalogName)
+	staging := fmt.Sprintf("%s.part%s", target, <-common.Identities)
+	defer os.Remove(staging)
+	err = streamRemoteFile(origin, fmt.Sprintf("/catalog/%s", catalogName), staging)
+	fail.On(err != nil, "%v", err)
+
+	shadow, err := htfs.NewRoot(filepath.Join(common.ProductTemp(), "shadow"))
+	fail.On(err != nil, "%v", err)
+	err = shadow.LoadFrom(staging)
+	fail.On(err != nil, "Streamed catalog %q is not valid, reason: %v", catalogName, err)
+
+	// catalog stays in streaming area until restore has all of its blobs,
+	// so that incomplete environment is never seen as being in hololib
+	err = pathlib.TryRename("catalog", staging, target)
+	fail.On(err != nil, "%v", err)
+	mode := htfs.PullVerifyMode()
+	if mode == htfs.PullVerifyTrust {
+		pretty.Warning("Blobs of %q streamed from %q are trusted without digest verification (%s=%s).", catalogName, origin, common.RCC_PULL_VERIFY, mode)
+	}
+	return nil
+}
+
+func StreamBlob(origin, digest, target string) (err error) {
+	defer fail.Around(&err)
+
+	staging := fmt.Sprintf("%s.part%s", target, <-common.Identities)
+	defer os.Remove(staging)
+	err = streamRemoteFile(origin, fmt.Sprintf("/blob/%s", digest), staging)
+	fail.On(err != nil, "%v", err)
+	if htfs.VerifiedOnArrival(htfs.PullVerifyMode()) {
+		err = htfs.VerifyBlobFile(staging, digest)
+		fail.On(err != nil, "%v", err)
+	}
+	if pathlib.IsFile(target) {
+		return nil
+	}
+	err = pathlib.TryRename("blob", staging, target)
+	fail.On(err != nil, "%v", err)
+	pathlib.MakeSharedFile(target)
+	common.Trace("Blob %q streamed from %q.", digest, origin)
+	return nil
+}
@@ -0,0 +1,58 @@
+package operations
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+	"github.com/joshyorko/rcc/htfs"
+)
+
+func TestStreamBlobVerifiesContentBeforeCaching(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	good := []byte("streamed content")
+	digest, err := htfs.BlobDigest(bytes.NewReader(good), "", nil)
+	must.Nil(err)
+	corrupt := strings.Repeat("0", len(digest))
+
+	server := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		switch request.URL.Path {
+		case "/blob/" + digest:
+			response.Write(good)
+		case "/blob/" + corrupt:
+			response.Write([]byte("not matching"))
+		default:
+			response.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	library := t.TempDir()
+	target := filepath.Join(library, digest[:2], digest)
+	must.Nil(StreamBlob(server.URL, digest, target))
+	content, err := os.ReadFile(target)
+	must.Nil(err)
+	must.Equal(good, content)
+
+	target = filepath.Join(library, corrupt[:2], corrupt)
+	err = StreamBlob(server.URL, corrupt, target)
+	wont.Nil(err)
+	must.True(strings.Contains(err.Error(), "Corrupted blob"))
+	_, err = os.Stat(target)
+	must.True(os.IsNotExist(err))
+
+	missing := strings.Repeat("f", len(digest))
+	err = StreamBlob(server.URL, missing, filepath.Join(library, missing))
+	wont.Nil(err)
+	must.True(strings.Contains(err.Error(), "status=404"))
+
+	leftovers, err := filepath.Glob(filepath.Join(library, "*", "*.part*"))
+	must.Nil(err)
+	must.Equal(0, len(leftovers))
+}
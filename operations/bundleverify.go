@@ -0,0 +1,261 @@
+package operations
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/conda"
+	"github.com/joshyorko/rcc/htfs"
+	"github.com/joshyorko/rcc/pathlib"
+)
+
+type BundleCatalog struct {
+	Name       string `json:"name"`
+	Blueprint  string `json:"blueprint"`
+	Platform   string `json:"platform"`
+	RccVersion string `json:"rcc"`
+	Files      int    `json:"files"`
+	Compatible bool   `json:"compatible"`
+	Missing    int    `json:"missing"`
+}
+
+type BundleReport struct {
+	Bundle      string           `json:"bundle"`
+	ArchiveSize int64            `json:"archive_size"`
+	ContentSize uint64           `json:"content_size"`
+	Entries     int              `json:"entries"`
+	Blobs       int              `json:"blobs"`
+	Catalogs    []*BundleCatalog `json:"catalogs"`
+	LocalBlobs  int              `json:"local_blobs"`
+	Errors      []string         `json:"errors"`
+	Warnings    []string         `json:"warnings"`
+	Valid       bool             `json:"valid"`
+	CurrentRcc  string           `json:"current_rcc"`
+	CurrentOS   string           `json:"current_platform"`
+}
+
+func (it *BundleReport) fail(form string, details ...interface{}) {
+	it.Errors = append(it.Errors, fmt.Sprintf(form, details...))
+}
+
+func (it *BundleReport) warn(form string, details ...interface{}) {
+	it.Warnings = append(it.Warnings, fmt.Sprintf(form, details...))
+}
+
+// collectDigests collects blob digests of files in catalog tree, and counts
+// those files. Symbolic links have no blobs, so they are left out.
+func collectDigests(dir *htfs.Dir, target map[string]bool) int {
+	count := 0
+	for _, file := range dir.Files {
+		if file.IsSymlink() {
+			continue
+		}
+		target[file.Digest] = true
+		count++
+	}
+	for _, subdir := range dir.Dirs {
+		count += collectDigests(subdir, target)
+	}
+	return count
+}
+
+func (it *BundleReport) verifyBlob(file *zip.File) {
+	expected := path.Base(slashed(file.Name))
+	source, err := file.Open()
+	if err != nil {
+		it.fail("Could not open %q, reason: %v", file.Name, err)
+		return
+	}
+	defer source.Close()
+	expected = strings.ToLower(expected)
+	actual, err := htfs.BlobDigest(source, expected, file.Open)
+	if err != nil {
+		it.fail("Could not digest %q, reason: %v", file.Name, err)
+		return
+	}
+	if actual != expected {
+		it.fail("Corrupted blob %q, expected %s, actual %s.", file.Name, expected, actual)
+	}
+}
+
+func (it *BundleReport) verifyCatalog(file *zip.File) (*BundleCatalog, map[string]bool) {
+	name := path.Base(slashed(file.Name))
+	catalog := &BundleCatalog{Name: name}
+	source, err := file.Open()
+	if err != nil {
+		it.fail("Could not open catalog %q, reason: %v", name, err)
+		return nil, nil
+	}
+	defer source.Close()
+	reader, err := gzip.NewReader(source)
+	if err != nil {
+		it.fail("Catalog %q is not gzipped, reason: %v", name, err)
+		return nil, nil
+	}
+	defer reader.Close()
+	root, err := htfs.NewRoot(".")
+	if err != nil {
+		it.fail("Could not create root for %q, reason: %v", name, err)
+		return nil, nil
+	}
+	err = root.ReadFrom(reader)
+	if err != nil || root.Tree == nil {
+		it.fail("Could not parse catalog %q, reason: %v", name, err)
+		return nil, nil
+	}
+	digests := make(map[string]bool)
+	catalog.Files = collectDigests(root.Tree, digests)
+	catalog.Blueprint = root.Blueprint
+	catalog.Platform = root.Platform
+	catalog.RccVersion = root.RccVersion
+	catalog.Compatible = true
+	if !strings.HasSuffix(name, common.Platform()) || root.Platform != common.Platform() {
+		catalog.Compatible = false
+		it.fail("Catalog %q is for platform %q, but this machine is %q.", name, root.Platform, common.Platform())
+	}
+	if name != htfs.CatalogName(root.Blueprint) && strings.HasSuffix(name, common.Platform()) {
+		catalog.Compatible = false
+		it.fail("Catalog %q does not match current catalog format %q.", name, htfs.CatalogName(root.Blueprint))
+	}
+	required, _ := conda.AsVersion(root.RccVersion)
+	current, _ := conda.AsVersion(common.Version)
+	if required > current && current > 0 {
+		it.warn("Catalog %q was made with rcc %s, which is newer than current rcc %s.", name, root.RccVersion, common.Version)
+	}
+	return catalog, digests
+}
+
+func VerifyBundle(zipfile string) (*BundleReport, error) {
+	common.TimelineBegin("bundle verify %q [size: %s]", zipfile, pathlib.HumaneSize(zipfile))
+	defer common.TimelineEnd()
+
+	report := &BundleReport{
+		Bundle:     zipfile,
+		Catalogs:   []*BundleCatalog{},
+		Errors:     []string{},
+		Warnings:   []string{},
+		CurrentRcc: common.Version,
+		CurrentOS:  common.Platform(),
+	}
+	stat, err := os.Stat(zipfile)
+	if err != nil {
+		return nil, err
+	}
+	report.ArchiveSize = stat.Size()
+	unzip, err := zip.OpenReader(zipfile)
+	if err != nil {
+		return nil, err
+	}
+	defer unzip.Close()
+
+	present := make(map[string]bool)
+	required := make(map[string]*BundleCatalog)
+	needs := make(map[string]map[string]bool)
+	for _, file := range unzip.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		report.Entries++
+		report.ContentSize += file.UncompressedSize64
+		err := HololibZipShape(file)
+		if err != nil {
+			report.fail("%v", err)
+			continue
+		}
+		if localPattern.MatchString(file.Name) {
+			continue
+		}
+		if libraryPattern.MatchString(file.Name) {
+			report.Blobs++
+			present[strings.ToLower(path.Base(slashed(file.Name)))] = true
+			report.verifyBlob(file)
+			continue
+		}
+		catalog, digests := report.verifyCatalog(file)
+		if catalog != nil {
+			report.Catalogs = append(report.Catalogs, catalog)
+			required[catalog.Name] = catalog
+			needs[catalog.Name] = digests
+		}
+	}
+	local := make(map[string]bool)
+	for name, digests := range needs {
+		for digest := range digests {
+			if present[digest] {
+				continue
+			}
+			if pathlib.IsFile(htfs.ExactDefaultLocation(digest)) {
+				local[digest] = true
+				continue
+			}
+			required[name].Missing++
+		}
+		if required[name].Missing > 0 {
+			report.fail("Catalog %q references %d blobs, which are not in bundle nor in local hololib.", name, required[name].Missing)
+		}
+	}
+	report.LocalBlobs = len(local)
+	if len(report.Catalogs) == 0 {
+		report.fail("Bundle %q does not contain any catalogs.", zipfile)
+	}
+	sort.Slice(report.Catalogs, func(left, right int) bool {
+		return report.Catalogs[left].Name < report.Catalogs[right].Name
+	})
+	report.Valid = len(report.Errors) == 0
+	return report, nil
+}
+
+func BundleVerifyReport(report *BundleReport, json bool) {
+	if json {
+		body, err := NiceJsonOutput(report)
+		if err != nil {
+			common.Error("verify-bundle", err)
+		} else {
+			common.Stdout("%s\n", body)
+		}
+		return
+	}
+	common.Log("Bundle %q:", report.Bundle)
+	common.Log("- archive size %d bytes, content size %d bytes in %d entries", report.ArchiveSize, report.ContentSize, report.Entries)
+	common.Log("- %d library blobs in bundle, %d referenced blobs already in local hololib", report.Blobs, report.LocalBlobs)
+	common.Log("- current rcc is %s on %s", report.CurrentRcc, report.CurrentOS)
+	for _, catalog := range report.Catalogs {
+		common.Log("- catalog %s [%s, rcc %s, %d files, compatible: %v, missing blobs: %d]", catalog.Name, catalog.Platform, catalog.RccVersion, catalog.Files, catalog.Compatible, catalog.Missing)
+	}
+	for _, warning := range report.Warnings {
+		common.Log("Warning: %s", warning)
+	}
+	for _, failure := range report.Errors {
+		common.Log("Error: %s", failure)
+	}
+}
+
+func ForeignCatalogs(zipfile, platform string) ([]string, error) {
+	if pathlib.IsDir(zipfile) {
+		return foreignDirectoryCatalogs(zipfile, platform)
+	}
+	unzip, err := zip.OpenReader(zipfile)
+	if err != nil {
+		return nil, err
+	}
+	defer unzip.Close()
+
+	result := []string{}
+	for _, file := range unzip.File {
+		if !catalogPattern.MatchString(file.Name) {
+			continue
+		}
+		name := path.Base(slashed(file.Name))
+		if htfs.CatalogPlatform(name) != strings.ToLower(platform) {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
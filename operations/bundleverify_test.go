@@ -0,0 +1,105 @@
+package operations
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/hamlet"
+	"github.com/joshyorko/rcc/htfs"
+)
+
+func blobEntry(digest string) string {
+	return strings.Join([]string{"library", digest[:2], digest[2:4], digest[4:6], digest}, "/")
+}
+
+func writeBundle(t *testing.T, filename string, entries map[string][]byte) {
+	sink, err := os.Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	writer := zip.NewWriter(sink)
+	defer writer.Close()
+	for name, content := range entries {
+		target, err := writer.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		target.Write(content)
+	}
+}
+
+func TestVerifyBundleReportsDigestsCatalogsAndMissingBlobs(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	good := []byte("good content")
+	digest, err := htfs.BlobDigest(bytes.NewReader(good), "", nil)
+	must.Nil(err)
+	corrupt := strings.Repeat("0", len(digest))
+	missing := strings.Repeat("f", len(digest))
+
+	root, err := htfs.NewRoot(".")
+	must.Nil(err)
+	root.Blueprint = "0123456789abcdef"
+	root.Tree.Files["good.txt"] = &htfs.File{Name: "good.txt", Digest: digest}
+	root.Tree.Files["corrupt.txt"] = &htfs.File{Name: "corrupt.txt", Digest: corrupt}
+	root.Tree.Files["link.txt"] = &htfs.File{Name: "link.txt", Digest: missing, Symlink: "good.txt"}
+	body, err := json.Marshal(root)
+	must.Nil(err)
+	zipped := bytes.NewBuffer(nil)
+	compressor := gzip.NewWriter(zipped)
+	compressor.Write(body)
+	compressor.Close()
+
+	bundle := filepath.Join(t.TempDir(), "hololib.zip")
+	writeBundle(t, bundle, map[string][]byte{
+		blobEntry(digest):  good,
+		blobEntry(corrupt): []byte("not matching"),
+		"catalog/" + htfs.CatalogName(root.Blueprint): zipped.Bytes(),
+	})
+
+	report, err := VerifyBundle(bundle)
+	must.Nil(err)
+	wont.True(report.Valid)
+	must.Equal(2, report.Blobs)
+	must.Equal(1, len(report.Catalogs))
+	must.True(report.Catalogs[0].Compatible)
+	must.Equal(common.Platform(), report.Catalogs[0].Platform)
+	must.Equal(0, report.Catalogs[0].Missing)
+	must.Equal(2, report.Catalogs[0].Files)
+	must.Equal(1, len(report.Errors))
+	must.True(strings.Contains(report.Errors[0], "Corrupted blob"))
+
+	root.Tree.Files["missing.txt"] = &htfs.File{Name: "missing.txt", Digest: missing}
+	body, err = json.Marshal(root)
+	must.Nil(err)
+	zipped.Reset()
+	compressor = gzip.NewWriter(zipped)
+	compressor.Write(body)
+	compressor.Close()
+	writeBundle(t, bundle, map[string][]byte{
+		blobEntry(digest): good,
+		"catalog/" + htfs.CatalogName(root.Blueprint): zipped.Bytes(),
+		"extra/file.txt": []byte("unexpected"),
+	})
+
+	report, err = VerifyBundle(bundle)
+	must.Nil(err)
+	wont.True(report.Valid)
+	must.Equal(2, report.Catalogs[0].Missing)
+	must.Equal(3, report.Entries)
+
+	foreign, err := ForeignCatalogs(bundle, common.Platform())
+	must.Nil(err)
+	must.Equal(0, len(foreign))
+	foreign, err = ForeignCatalogs(bundle, "plan9_mips")
+	must.Nil(err)
+	must.Equal([]string{htfs.CatalogName(root.Blueprint)}, foreign)
+}
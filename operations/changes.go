@@ -0,0 +1,71 @@
+package operations
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/fail"
+	"github.com/joshyorko/rcc/journal"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/robot"
+)
+
+const (
+	NoChangesMarker = "rcc: no changes since last successful run"
+	changesWeeks    = 8
+)
+
+// RunInputsDigest is fingerprint of everything that affects robot run: robot
+// sources (same ones that would be wrapped), conda.yaml, environment file,
+// task, and arguments.
+func RunInputsDigest(robotfile, task, environmentFile string, arguments []string) (result string, err error) {
+	defer fail.Around(&err)
+
+	common.TimelineBegin("run inputs digest for %q", robotfile)
+	defer common.TimelineEnd()
+
+	config, err := robot.LoadRobotYaml(robotfile, false)
+	fail.On(err != nil, "Could not load %q, reason: %v", robotfile, err)
+	entries, err := wrapEntries(filepath.Dir(robotfile), "", nil, nil)
+	fail.Fast(err)
+
+	digest := sha256.New()
+	remember := func(kind, name, filename string) {
+		sum, err := pathlib.Sha256(filename)
+		fail.On(err != nil, "Could not digest %q, reason: %v", filename, err)
+		fmt.Fprintf(digest, "%s %s %s\n", kind, name, sum)
+	}
+	for _, entry := range entries {
+		remember("source", entry.relative, entry.fullpath)
+	}
+	condafile := config.CondaConfigFile()
+	if len(condafile) > 0 && pathlib.IsFile(condafile) {
+		remember("conda", filepath.Base(condafile), condafile)
+	}
+	if len(environmentFile) > 0 {
+		remember("environment", filepath.Base(environmentFile), environmentFile)
+	}
+	fmt.Fprintf(digest, "task %q dev %v\n", task, common.DeveloperFlag)
+	fmt.Fprintf(digest, "arguments %q\n", strings.Join(arguments, "\x00"))
+	return fmt.Sprintf("%02x", digest.Sum(nil)), nil
+}
+
+// UnchangedSinceSuccess tells if last successful run of same robot and task
+// had exactly same inputs, and when that run happened.
+func UnchangedSinceSuccess(robotfile, task, inputs string) (*journal.RunRecord, bool) {
+	history, err := journal.RunHistory(robotfile, changesWeeks)
+	if err != nil {
+		common.Debug("Could not read run history, reason: %v", err)
+		return nil, false
+	}
+	for _, record := range history {
+		if record.Task != task || record.Developer != common.DeveloperFlag || !record.Success {
+			continue
+		}
+		return record, len(inputs) > 0 && record.Inputs == inputs
+	}
+	return nil, false
+}
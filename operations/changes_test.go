@@ -0,0 +1,77 @@
+package operations
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/hamlet"
+	"github.com/joshyorko/rcc/journal"
+)
+
+func TestRunInputsDigestFollowsRobotInputs(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	source := t.TempDir()
+	writeWrapFixture(t, source, "robot.yaml", "tasks:\n  Demo:\n    shell: python task.py\ncondaConfigFile: conda.yaml\nartifactsDir: output\n")
+	writeWrapFixture(t, source, "conda.yaml", "dependencies:\n- python=3.10\n")
+	writeWrapFixture(t, source, "task.py", "print('hello')\n")
+	robotfile := filepath.Join(source, "robot.yaml")
+
+	first, err := RunInputsDigest(robotfile, "Demo", "", nil)
+	must.Nil(err)
+	again, err := RunInputsDigest(robotfile, "Demo", "", nil)
+	must.Nil(err)
+	must.Equal(first, again)
+
+	writeWrapFixture(t, source, "output/log.html", "<html/>\n")
+	ignored, err := RunInputsDigest(robotfile, "Demo", "", nil)
+	must.Nil(err)
+	must.Equal(first, ignored)
+
+	other, err := RunInputsDigest(robotfile, "Other", "", nil)
+	must.Nil(err)
+	wont.Equal(first, other)
+	arguments, err := RunInputsDigest(robotfile, "Demo", "", []string{"--dryrun"})
+	must.Nil(err)
+	wont.Equal(first, arguments)
+
+	writeWrapFixture(t, source, "conda.yaml", "dependencies:\n- python=3.12\n")
+	changed, err := RunInputsDigest(robotfile, "Demo", "", nil)
+	must.Nil(err)
+	wont.Equal(first, changed)
+
+	_, err = RunInputsDigest(filepath.Join(t.TempDir(), "robot.yaml"), "Demo", "", nil)
+	wont.Nil(err)
+}
+
+func TestUnchangedRunsAreDetectedFromRunHistory(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	t.Setenv("ROBOCORP_HOME", t.TempDir())
+	must.Nil(os.MkdirAll(common.JournalLocation(), 0o755))
+	robotfile := "/robots/a/robot.yaml"
+
+	_, ok := UnchangedSinceSuccess(robotfile, "Demo", "abc")
+	wont.True(ok)
+
+	now := time.Now().Unix()
+	must.Nil(journal.RecordRun(&journal.RunRecord{When: now - 60, Robot: robotfile, Task: "Demo", Success: true, Inputs: "abc"}))
+	record, ok := UnchangedSinceSuccess(robotfile, "Demo", "abc")
+	must.True(ok)
+	must.Equal(now-60, record.When)
+	_, ok = UnchangedSinceSuccess(robotfile, "Demo", "")
+	wont.True(ok)
+	_, ok = UnchangedSinceSuccess(robotfile, "Other", "abc")
+	wont.True(ok)
+
+	must.Nil(journal.RecordRun(&journal.RunRecord{When: now - 30, Robot: robotfile, Task: "Demo", Exitcode: 1, Inputs: "abc"}))
+	_, ok = UnchangedSinceSuccess(robotfile, "Demo", "abc")
+	must.True(ok)
+
+	must.Nil(journal.RecordRun(&journal.RunRecord{When: now, Robot: robotfile, Task: "Demo", Success: true, Inputs: "def"}))
+	_, ok = UnchangedSinceSuccess(robotfile, "Demo", "abc")
+	wont.True(ok)
+}
@@ -0,0 +1,324 @@
+package operations
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/htfs"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/pretty"
+)
+
+const (
+	CleanupCaches    = "caches"
+	CleanupTemps     = "temps"
+	CleanupSpaces    = "idle spaces"
+	CleanupCatalogs  = "unused catalogs"
+	CleanupBlobs     = "orphan blobs"
+	CleanupArtifacts = "artifacts"
+)
+
+// CleanupOptions selects what `rcc cleanup` removes. Zero values select
+// nothing, so only explicitly requested cleanups are done.
+type CleanupOptions struct {
+	Caches         bool
+	Temps          bool
+	TempDays       int
+	IdleSpaces     int
+	UnusedCatalogs int
+	Artifacts      bool
+	Dryrun         bool
+}
+
+// CleanupItem is one removed (or in dry run, removable) path.
+type CleanupItem struct {
+	Category string `json:"category"`
+	Path     string `json:"path"`
+	Size     uint64 `json:"size"`
+	Error    string `json:"error,omitempty"`
+}
+
+// CleanupReport tells what cleanup removed, and how much space it reclaimed.
+type CleanupReport struct {
+	Dryrun    bool           `json:"dryrun"`
+	Reclaimed uint64         `json:"reclaimed"`
+	Failures  int            `json:"failures"`
+	Items     []*CleanupItem `json:"items"`
+}
+
+// Any tells if any cleanup was selected.
+func (it *CleanupOptions) Any() bool {
+	return it.Caches || it.Temps || it.IdleSpaces > 0 || it.UnusedCatalogs > 0 || it.Artifacts
+}
+
+// DiskUsage gives total size of files in path (file or directory tree).
+func DiskUsage(path string) uint64 {
+	total := uint64(0)
+	filepath.WalkDir(path, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err == nil && info.Mode().IsRegular() {
+			total += uint64(info.Size())
+		}
+		return nil
+	})
+	return total
+}
+
+func (it *CleanupReport) add(category, path string, size uint64, err error) {
+	item := &CleanupItem{Category: category, Path: path, Size: size}
+	if err != nil {
+		item.Error = err.Error()
+		it.Failures++
+		pretty.Warning("Cleanup of %q failed, reason: %v", path, err)
+	} else {
+		it.Reclaimed += size
+	}
+	it.Items = append(it.Items, item)
+}
+
+// remove removes path (in dry run just measures it) and records result.
+func (it *CleanupReport) remove(category, path string) {
+	if !pathlib.Exists(path) {
+		return
+	}
+	size := DiskUsage(path)
+	if it.Dryrun {
+		common.Log("[dry run] Would remove %s %q (%s).", category, path, humaneBytes(size))
+		it.add(category, path, size, nil)
+		return
+	}
+	common.Debug("Removing %s %q (%s).", category, path, humaneBytes(size))
+	if pathlib.IsDir(path) {
+		it.add(category, path, size, pathlib.TryRemoveAll(category, path))
+	} else {
+		it.add(category, path, size, pathlib.TryRemove(category, path))
+	}
+}
+
+// Size gives reclaimed space in human readable form.
+func (it *CleanupReport) Size() string {
+	return humaneBytes(it.Reclaimed)
+}
+
+// Table gives report summarized by category.
+func (it *CleanupReport) Table() *pretty.Table {
+	table := pretty.NewTable(
+		&pretty.TableColumn{Name: "category", Title: "Category"},
+		&pretty.TableColumn{Name: "items", Title: "Items"},
+		&pretty.TableColumn{Name: "reclaimed", Title: "Reclaimed"},
+		&pretty.TableColumn{Name: "failed", Title: "Failed"},
+	)
+	order := []string{}
+	items := make(map[string]int)
+	sizes := make(map[string]uint64)
+	failures := make(map[string]int)
+	for _, item := range it.Items {
+		if _, ok := items[item.Category]; !ok {
+			order = append(order, item.Category)
+		}
+		items[item.Category]++
+		if len(item.Error) > 0 {
+			failures[item.Category]++
+		} else {
+			sizes[item.Category] += item.Size
+		}
+	}
+	for _, category := range order {
+		table.Add(pretty.TableRow{
+			"category":  category,
+			"items":     items[category],
+			"reclaimed": humaneBytes(sizes[category]),
+			"failed":    failures[category],
+		})
+	}
+	table.Add(pretty.TableRow{
+		"category":  "total",
+		"items":     len(it.Items),
+		"reclaimed": humaneBytes(it.Reclaimed),
+		"failed":    it.Failures,
+	})
+	return table
+}
+
+// CacheLocations are download caches, that are filled again when needed.
+func CacheLocations() []string {
+	return []string{
+		common.TemplateLocation(),
+		common.PipCache(),
+		common.UvCache(),
+		common.MambaPackages(),
+	}
+}
+
+// IdleSpaces gives holotree space directories, that have not been used in
+// more than given days. Spaces without usage information are kept.
+func IdleSpaces(days int) []string {
+	result := []string{}
+	for directory := range htfs.LoadCatalogInfos().Spacemap() {
+		stat, err := os.Stat(directory + ".use")
+		if err != nil {
+			continue
+		}
+		if common.DayCountSince(stat.ModTime()) > days {
+			result = append(result, directory)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// CatalogIdleDays gives days since last use of each blueprint (by hash),
+// from all users of hololib.
+func CatalogIdleDays() map[string]int {
+	result := make(map[string]int)
+	entries, err := os.ReadDir(common.HololibUsageLocation())
+	if err != nil {
+		return result
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		name := filepath.Base(entry.Name())
+		base := strings.TrimSuffix(name, filepath.Ext(name))
+		days := common.DayCountSince(info.ModTime())
+		previous, ok := result[base]
+		if !ok || days < previous {
+			result[base] = days
+		}
+	}
+	return result
+}
+
+// UnusedCatalogs gives catalogs, whose blueprint has not been used in more
+// than given days. Catalogs without usage information are kept.
+func UnusedCatalogs(days int) []string {
+	used := CatalogIdleDays()
+	result := []string{}
+	for _, catalog := range htfs.AllCatalogNames() {
+		for hash, idle := range used {
+			if idle > days && strings.HasPrefix(catalog, hash) {
+				result = append(result, catalog)
+				break
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// ArtifactLocations are leftovers of earlier runs, currently rotated and
+// compressed log and journal files.
+func ArtifactLocations() []string {
+	result := common.RotatedLogs(common.Product.Home())
+	return append(result, common.RotatedLogs(common.JournalLocation())...)
+}
+
+func cleanupSpaces(report *CleanupReport, spaces []string) {
+	roots := htfs.LoadCatalogInfos()
+	for _, directory := range spaces {
+		size := DiskUsage(directory)
+		if report.Dryrun {
+			common.Log("[dry run] Would remove %s %q (%s).", CleanupSpaces, directory, humaneBytes(size))
+			report.add(CleanupSpaces, directory, size, nil)
+			continue
+		}
+		report.add(CleanupSpaces, directory, size, roots.RemoveHolotreeSpace(filepath.Base(directory)))
+	}
+}
+
+func cleanupCatalogs(report *CleanupReport, catalogs []string) {
+	if len(catalogs) == 0 {
+		return
+	}
+	blobs := htfs.ExclusiveBlobs(catalogs)
+	for _, catalog := range catalogs {
+		path := filepath.Join(common.HololibCatalogLocation(), catalog)
+		size, _ := pathlib.Size(path)
+		if report.Dryrun {
+			common.Log("[dry run] Would remove %s %q.", CleanupCatalogs, catalog)
+		}
+		report.add(CleanupCatalogs, path, uint64(size), nil)
+	}
+	if report.Dryrun {
+		size := htfs.BlobsSize(blobs)
+		common.Log("[dry run] Would remove %d %s (%s).", len(blobs), CleanupBlobs, humaneBytes(size))
+		report.add(CleanupBlobs, common.HololibLibraryLocation(), size, nil)
+		return
+	}
+	tree, err := htfs.New()
+	if err == nil {
+		err = tree.Remove(catalogs)
+	}
+	if err != nil {
+		report.add(CleanupCatalogs, common.HololibCatalogLocation(), 0, err)
+		return
+	}
+	count, size, err := htfs.PruneBlobs(blobs)
+	common.Debug("Pruned %d blobs (%s) of removed catalogs.", count, humaneBytes(size))
+	report.add(CleanupBlobs, common.HololibLibraryLocation(), size, err)
+}
+
+// Cleanup does selected cleanups, and reports what was (or in dry run, would
+// be) removed. It never asks anything, so it is safe to run from cron;
+// environment builds are serialized with it thru holotree lock, and other
+// cleanups thru product lock.
+func Cleanup(options *CleanupOptions) (*CleanupReport, error) {
+	report := &CleanupReport{Dryrun: options.Dryrun, Items: []*CleanupItem{}}
+
+	lockfile := common.ProductLock()
+	completed := pathlib.LockWaitMessage(lockfile, "Serialized environment cleanup [robocorp lock]")
+	locker, err := pathlib.Locker(lockfile, 30000, false)
+	completed()
+	if err != nil {
+		return nil, fmt.Errorf("Could not get lock on %q, reason: %v", lockfile, err)
+	}
+	defer locker.Release()
+
+	func() {
+		lockfile := common.HolotreeLock()
+		completed := pathlib.LockWaitMessage(lockfile, "Serialized environment cleanup [holotree lock]")
+		locker, err = pathlib.Locker(lockfile, 30000, common.SharedHolotree)
+		completed()
+		if err != nil {
+			return
+		}
+		defer locker.Release()
+
+		if options.Caches {
+			for _, location := range CacheLocations() {
+				report.remove(CleanupCaches, location)
+			}
+		}
+		if options.Temps {
+			for _, location := range staleTempEntries(options.TempDays) {
+				report.remove(CleanupTemps, location)
+			}
+		}
+		if options.IdleSpaces > 0 {
+			cleanupSpaces(report, IdleSpaces(options.IdleSpaces))
+		}
+		if options.Artifacts {
+			for _, location := range ArtifactLocations() {
+				report.remove(CleanupArtifacts, location)
+			}
+		}
+	}()
+	if err != nil {
+		return nil, fmt.Errorf("Could not get holotree lock, reason: %v", err)
+	}
+	if options.UnusedCatalogs > 0 {
+		cleanupCatalogs(report, UnusedCatalogs(options.UnusedCatalogs))
+	}
+	common.Timeline("cleanup reclaimed %d bytes with %d failures", report.Reclaimed, report.Failures)
+	return report, nil
+}
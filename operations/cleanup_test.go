@@ -0,0 +1,48 @@
+package operations
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestCleanupReportMeasuresAndRemoves(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	directory := t.TempDir()
+	tree := filepath.Join(directory, "tree")
+	must.Nil(os.MkdirAll(filepath.Join(tree, "deeper"), 0o755))
+	must.Nil(os.WriteFile(filepath.Join(tree, "first.txt"), make([]byte, 100), 0o644))
+	must.Nil(os.WriteFile(filepath.Join(tree, "deeper", "second.txt"), make([]byte, 50), 0o644))
+	single := filepath.Join(directory, "single.log.1.gz")
+	must.Nil(os.WriteFile(single, make([]byte, 10), 0o644))
+	must.Equal(uint64(150), DiskUsage(tree))
+	must.Equal(uint64(10), DiskUsage(single))
+
+	dryrun := &CleanupReport{Dryrun: true, Items: []*CleanupItem{}}
+	dryrun.remove(CleanupCaches, tree)
+	dryrun.remove(CleanupArtifacts, single)
+	dryrun.remove(CleanupArtifacts, filepath.Join(directory, "missing"))
+	must.Equal(2, len(dryrun.Items))
+	must.Equal(uint64(160), dryrun.Reclaimed)
+	must.True(filepath.IsAbs(dryrun.Items[0].Path))
+	_, err := os.Stat(tree)
+	must.Nil(err)
+
+	report := &CleanupReport{Items: []*CleanupItem{}}
+	report.remove(CleanupCaches, tree)
+	report.remove(CleanupArtifacts, single)
+	must.Equal(uint64(160), report.Reclaimed)
+	must.Equal(0, report.Failures)
+	_, err = os.Stat(tree)
+	wont.Nil(err)
+	_, err = os.Stat(single)
+	wont.Nil(err)
+
+	table := report.Table()
+	must.Equal(3, len(table.Rows))
+	must.Equal("total", table.Rows[2]["category"])
+	must.Equal(2, table.Rows[2]["items"])
+}
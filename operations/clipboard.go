@@ -0,0 +1,77 @@
+package operations
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/joshyorko/rcc/shell"
+)
+
+type yankable struct {
+	label string
+	value string
+}
+
+var (
+	yanked *yankable
+)
+
+// Yankable remembers primary value (like path, hash, URL, or command) of
+// current interactive view, so that it can be copied into clipboard.
+func Yankable(label, value string) {
+	if len(value) == 0 {
+		return
+	}
+	yanked = &yankable{
+		label: label,
+		value: value,
+	}
+}
+
+// Yanked returns latest primary value of interactive view and its label.
+func Yanked() (string, string, bool) {
+	if yanked == nil {
+		return "", "", false
+	}
+	return yanked.label, yanked.value, true
+}
+
+// CopyToClipboard puts value into clipboard using platform clipboard tools,
+// and if none of those are available, using OSC 52 terminal escape sequence.
+// Returns name of method used.
+func CopyToClipboard(value string) (string, error) {
+	for _, command := range clipboardCommands {
+		code, err := shell.New(nil, ".", command...).Feed([]byte(value))
+		if err == nil && code == 0 {
+			return command[0], nil
+		}
+		common.Trace("Clipboard command %q failed with code %d, reason: %v", command, code, err)
+	}
+	if !pretty.Interactive {
+		return "", fmt.Errorf("No clipboard tool available, and terminal is not interactive.")
+	}
+	encoded := base64.StdEncoding.EncodeToString([]byte(value))
+	_, err := fmt.Fprintf(os.Stdout, "\x1b]52;c;%s\a", encoded)
+	if err != nil {
+		return "", err
+	}
+	return "terminal", nil
+}
+
+// YankToClipboard copies latest primary value of interactive view into
+// clipboard, and confirms it to user.
+func YankToClipboard() error {
+	label, value, ok := Yanked()
+	if !ok {
+		return fmt.Errorf("Nothing to copy in this view.")
+	}
+	method, err := CopyToClipboard(value)
+	if err != nil {
+		return fmt.Errorf("Could not copy %s to clipboard, reason: %v", label, err)
+	}
+	common.Log("%sCopied %s to clipboard [%s]: %s%s", pretty.Green, label, method, value, pretty.Reset)
+	return nil
+}
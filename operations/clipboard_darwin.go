@@ -0,0 +1,7 @@
+package operations
+
+var (
+	clipboardCommands = [][]string{
+		{"pbcopy"},
+	}
+)
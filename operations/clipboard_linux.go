@@ -0,0 +1,9 @@
+package operations
+
+var (
+	clipboardCommands = [][]string{
+		{"wl-copy"},
+		{"xclip", "-selection", "clipboard"},
+		{"xsel", "--clipboard", "--input"},
+	}
+)
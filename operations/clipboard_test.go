@@ -0,0 +1,28 @@
+package operations
+
+import (
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestYankableRemembersLatestPrimaryValue(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	yanked = nil
+	_, _, ok := Yanked()
+	wont.True(ok)
+	wont.Nil(YankToClipboard())
+
+	Yankable("catalog path", "")
+	_, _, ok = Yanked()
+	wont.True(ok)
+
+	Yankable("catalog path", "/tmp/hololib/catalog/abc")
+	Yankable("command", "rcc run --robot robot.yaml")
+	label, value, ok := Yanked()
+	must.True(ok)
+	must.Equal("command", label)
+	must.Equal("rcc run --robot robot.yaml", value)
+	yanked = nil
+}
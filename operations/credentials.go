@@ -23,6 +23,7 @@ const (
 	secretSuffix     = `.secret`
 	verifiedSuffix   = `.verified`
 	detailsSuffix    = `.details`
+	storeSuffix      = `.store`
 )
 
 var (
@@ -38,6 +39,7 @@ type account struct {
 	Verified   int64                  `json:"verified"`
 	Default    bool                   `json:"default"`
 	Details    map[string]interface{} `json:"details,omitempty"`
+	Store      string                 `json:"store,omitempty"`
 }
 
 func DefaultAccountName() string {
@@ -55,7 +57,12 @@ func UpdateCredentials(account, endpoint, identifier, secret string) {
 	prefix := accountsPrefix + account
 	xviper.Set(prefix+labelSuffix, account)
 	xviper.Set(prefix+identifierSuffix, identifier)
+	store := storeSecret(account, secret)
+	if len(store) > 0 {
+		secret = ""
+	}
 	xviper.Set(prefix+secretSuffix, secret)
+	xviper.Set(prefix+storeSuffix, store)
 	xviper.Set(prefix+verifiedSuffix, 0)
 	xviper.Set(prefix+detailsSuffix, new(map[string]interface{}))
 	if len(endpoint) > 0 {
@@ -63,6 +70,29 @@ func UpdateCredentials(account, endpoint, identifier, secret string) {
 	}
 }
 
+// MigrateCredentials moves plain text secrets from rcc.yaml into OS keychain
+// or encrypted vault file, and returns number of accounts migrated.
+func MigrateCredentials() (int, error) {
+	if len(credentialStores()) == 0 {
+		return 0, fmt.Errorf("Credential store is %q, so there is nowhere to migrate secrets.", common.RccCredentialsStore())
+	}
+	migrated := 0
+	for _, entry := range findAccounts() {
+		if len(entry.Store) > 0 || len(entry.Secret) == 0 {
+			continue
+		}
+		store := storeSecret(entry.Account, entry.Secret)
+		if len(store) == 0 {
+			return migrated, fmt.Errorf("Could not store secret of account %q into any credential store.", entry.Account)
+		}
+		prefix := accountsPrefix + entry.Account
+		xviper.Set(prefix+secretSuffix, "")
+		xviper.Set(prefix+storeSuffix, store)
+		migrated += 1
+	}
+	return migrated, nil
+}
+
 func VerifyAccounts(force bool) {
 	marker := time.Now().Add(-2 * time.Hour)
 	if marker.Before(common.Startup) {
@@ -89,7 +119,7 @@ func (it *account) IsValid() bool {
 }
 
 func (it *account) CacheKey() string {
-	return fmt.Sprintf("%s.%s", it.Identifier, it.Secret[:6])
+	return fmt.Sprintf("%s.%s", it.Identifier, it.Secret[:min(6, len(it.Secret))])
 }
 
 func (it *account) CacheToken(name, url, token string, deadline int64) {
@@ -135,6 +165,9 @@ func (it *account) Cached(period *TokenPeriod, name, url string) (string, bool)
 func (it *account) Delete(timeout time.Duration) error {
 	prefix := accountsPrefix + it.Account
 	defer xviper.Set(prefix, "deleted")
+	if len(it.Store) > 0 {
+		defer removeSecret(it.Account, it.Store)
+	}
 
 	client, err := cloud.NewClient(it.Endpoint)
 	if err != nil {
@@ -205,13 +238,23 @@ func loadAccount(label string) *account {
 	if ok {
 		details = some
 	}
+	secret := xviper.GetString(prefix + secretSuffix)
+	store := xviper.GetString(prefix + storeSuffix)
+	if len(store) > 0 {
+		stored, err := loadSecret(label, store)
+		if err != nil {
+			pretty.Warning("Could not load secret of account %q from %s, reason: %v", label, store, err)
+		}
+		secret = stored
+	}
 	return &account{
 		Account:    xviper.GetString(prefix + labelSuffix),
 		Identifier: xviper.GetString(prefix + identifierSuffix),
 		Endpoint:   xviper.GetString(prefix + endpointSuffix),
-		Secret:     xviper.GetString(prefix + secretSuffix),
+		Secret:     secret,
 		Verified:   xviper.GetInt64(prefix + verifiedSuffix),
 		Details:    details,
+		Store:      store,
 	}
 }
 
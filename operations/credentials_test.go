@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/joshyorko/rcc/common"
 	"github.com/joshyorko/rcc/hamlet"
 	"github.com/joshyorko/rcc/operations"
 	"github.com/joshyorko/rcc/settings"
@@ -46,6 +47,7 @@ func TestCanCallPublicFunctions(t *testing.T) {
 	wont_be.Panic(func() {
 		xviper.SetConfigFile(filepath.Join(os.TempDir(), "rcctest.yaml"))
 	})
+	t.Setenv(common.RCC_CREDENTIALS_STORE, operations.StorePlain)
 	operations.DefaultAccountName()
 	operations.UpdateCredentials("silly", "https://end", "42", "long_answer")
 }
@@ -62,6 +64,7 @@ func TestCanGetAccountByName(t *testing.T) {
 func TestCanCreateAndDeleteAccount(t *testing.T) {
 	must_be, wont_be := hamlet.Specifications(t)
 
+	t.Setenv(common.RCC_CREDENTIALS_STORE, operations.StorePlain)
 	operations.UpdateCredentials("dele", "https://end", "42", "long_answer")
 	sut := operations.AccountByName("dele")
 	wont_be.Nil(sut)
@@ -0,0 +1,156 @@
+package operations
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pathlib"
+)
+
+// vault is encrypted file store for account secrets, for machines without
+// usable OS keychain. Key comes from RCC_CREDENTIALS_KEY passphrase, or from
+// generated key file, readable only by owner, in user configuration directory
+// (not next to vault, so that copy of product home does not carry its key).
+type vault struct {
+	location string
+	keyfile  string
+}
+
+func fileVault() secretStore {
+	return &vault{
+		location: common.CredentialsVaultLocation(),
+		keyfile:  common.CredentialsKeyLocation(),
+	}
+}
+
+func (it *vault) Name() string {
+	return StoreFile
+}
+
+func (it *vault) key() ([]byte, error) {
+	passphrase, ok := common.RccCredentialsKey()
+	if ok {
+		digest := sha256.Sum256([]byte(passphrase))
+		return digest[:], nil
+	}
+	if len(it.keyfile) == 0 {
+		return nil, fmt.Errorf("No user configuration directory for credentials key, use %s instead.", common.RCC_CREDENTIALS_KEY)
+	}
+	key, err := os.ReadFile(it.keyfile)
+	if err == nil && len(key) == 32 {
+		return key, nil
+	}
+	if err == nil || !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("Credentials key %q is not usable.", it.keyfile)
+	}
+	key = make([]byte, 32)
+	_, err = rand.Read(key)
+	if err != nil {
+		return nil, err
+	}
+	err = pathlib.WriteFile(it.keyfile, key, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+func (it *vault) cipher() (cipher.AEAD, error) {
+	key, err := it.key()
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (it *vault) entries() (map[string]string, error) {
+	result := make(map[string]string)
+	content, err := os.ReadFile(it.location)
+	if errors.Is(err, os.ErrNotExist) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(content, &result)
+	if err != nil {
+		return nil, fmt.Errorf("Credentials vault %q is broken, reason: %v", it.location, err)
+	}
+	return result, nil
+}
+
+func (it *vault) save(entries map[string]string) error {
+	content, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	return pathlib.WriteFile(it.location, content, 0o600)
+}
+
+func (it *vault) Save(account, secret string) error {
+	aead, err := it.cipher()
+	if err != nil {
+		return err
+	}
+	entries, err := it.entries()
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(secret), []byte(account))
+	entries[account] = base64.StdEncoding.EncodeToString(sealed)
+	return it.save(entries)
+}
+
+func (it *vault) Load(account string) (string, error) {
+	entries, err := it.entries()
+	if err != nil {
+		return "", err
+	}
+	encoded, ok := entries[account]
+	if !ok {
+		return "", fmt.Errorf("No secret for account %q in %q.", account, it.location)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+	aead, err := it.cipher()
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("Secret of account %q is truncated.", account)
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	secret, err := aead.Open(nil, nonce, ciphertext, []byte(account))
+	if err != nil {
+		return "", fmt.Errorf("Could not decrypt secret of account %q, reason: %v", account, err)
+	}
+	return string(secret), nil
+}
+
+func (it *vault) Remove(account string) error {
+	entries, err := it.entries()
+	if err != nil {
+		return err
+	}
+	delete(entries, account)
+	return it.save(entries)
+}
@@ -0,0 +1,60 @@
+package operations
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/hamlet"
+	"github.com/joshyorko/rcc/pathlib"
+)
+
+func TestCanStoreSecretsIntoEncryptedVault(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	t.Setenv("ROBOCORP_HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(common.RCC_CREDENTIALS_STORE, StoreFile)
+
+	must.Equal(StoreFile, storeSecret("first", "top-secret-value"))
+	must.True(pathlib.IsFile(common.CredentialsKeyLocation()))
+	wont.Equal(filepath.Dir(common.CredentialsVaultLocation()), filepath.Dir(common.CredentialsKeyLocation()))
+	must.Equal(StoreFile, storeSecret("second", "other-value"))
+
+	content, err := os.ReadFile(common.CredentialsVaultLocation())
+	must.Nil(err)
+	wont.True(strings.Contains(string(content), "top-secret-value"))
+
+	secret, err := loadSecret("first", StoreFile)
+	must.Nil(err)
+	must.Equal("top-secret-value", secret)
+
+	removeSecret("first", StoreFile)
+	_, err = loadSecret("first", StoreFile)
+	wont.Nil(err)
+	secret, err = loadSecret("second", StoreFile)
+	must.Nil(err)
+	must.Equal("other-value", secret)
+}
+
+func TestVaultNeedsSameKeyToOpenSecrets(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	t.Setenv("ROBOCORP_HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(common.RCC_CREDENTIALS_STORE, StoreFile)
+	t.Setenv(common.RCC_CREDENTIALS_KEY, "correct horse")
+
+	must.Equal(StoreFile, storeSecret("account", "value"))
+	_, err := os.Stat(common.CredentialsKeyLocation())
+	must.True(os.IsNotExist(err))
+
+	t.Setenv(common.RCC_CREDENTIALS_KEY, "battery staple")
+	_, err = loadSecret("account", StoreFile)
+	wont.Nil(err)
+
+	t.Setenv(common.RCC_CREDENTIALS_STORE, StorePlain)
+	must.Equal("", storeSecret("account", "value"))
+}
@@ -34,6 +34,8 @@ const (
 	statusWarning  = `warning`
 	statusFail     = `fail`
 	statusFatal    = `fatal`
+	staleTempDays  = 7
+	staleUserDays  = 90
 )
 
 var (
@@ -109,6 +111,7 @@ func runDiagnostics(quick bool) *common.DiagnosticStatus {
 	result.Details["when"] = time.Now().Format(time.RFC3339 + " (MST)")
 	result.Details["timezone"] = time.Now().Format("MST")
 	result.Details["no-build"] = fmt.Sprintf("%v", settings.Global.NoBuild())
+	result.Details["offline"] = fmt.Sprintf("%v", settings.Global.Offline())
 	result.Details["ENV:ComSpec"] = os.Getenv("ComSpec")
 	result.Details["ENV:SHELL"] = os.Getenv("SHELL")
 	result.Details["ENV:LANG"] = os.Getenv("LANG")
@@ -166,12 +169,23 @@ func runDiagnostics(quick bool) *common.DiagnosticStatus {
 	}
 	result.Checks = append(result.Checks, lockpidsCheck()...)
 	result.Checks = append(result.Checks, lockfilesCheck()...)
+	result.Checks = append(result.Checks, micromambaCheck())
+	result.Checks = append(result.Checks, staleTempCheck())
+	result.Checks = append(result.Checks, restoreValidationCheck())
+	result.Checks = append(result.Checks, antivirusCheck())
+	result.Checks = append(result.Checks, spaceUsageCheck())
+	result.Checks = append(result.Checks, gpuCheck(result.Details))
 	if quick {
 		return result
 	}
 
 	// Move slow checks below this position
 
+	if settings.Global.Offline() {
+		result.Checks = append(result.Checks, offlineCheck())
+		return result
+	}
+
 	hostnames := settings.Global.Hostnames()
 	dnsStopwatch := common.Stopwatch("DNS lookup time for %d hostnames was about", len(hostnames))
 	for _, host := range hostnames {
@@ -283,11 +297,15 @@ func lockpidsCheck() []*common.DiagnosticCheck {
 		if entry.ProcessID == pid {
 			level = statusOk
 		}
+		message := entry.Message()
+		if isRunLock(entry.Basename) {
+			message = fmt.Sprintf("Robot run lock %q is held by user %q in space %q (parent/pid: %d/%d). Other runs of same robot in that space will wait, fail, or use suffixed space.", entry.Basename, entry.Username, entry.Space, entry.ParentID, entry.ProcessID)
+		}
 		result = append(result, &common.DiagnosticCheck{
 			Type:     "OS",
 			Category: common.CategoryLockPid,
 			Status:   level,
-			Message:  entry.Message(),
+			Message:  message,
 			Link:     support,
 		})
 	}
@@ -303,6 +321,219 @@ func lockpidsCheck() []*common.DiagnosticCheck {
 	return result
 }
 
+func micromambaCheck() *common.DiagnosticCheck {
+	supportGeneralUrl := settings.Global.DocsLink("troubleshooting")
+	if !conda.HasMicroMamba() {
+		return &common.DiagnosticCheck{
+			Type:     "RPA",
+			Category: common.CategoryMicromamba,
+			Status:   statusFail,
+			Message:  fmt.Sprintf("Micromamba %q is missing or too old.", conda.BinMicromamba()),
+			Link:     supportGeneralUrl,
+		}
+	}
+	return &common.DiagnosticCheck{
+		Type:     "RPA",
+		Category: common.CategoryMicromamba,
+		Status:   statusOk,
+		Message:  fmt.Sprintf("Micromamba %q is available.", conda.BinMicromamba()),
+		Link:     supportGeneralUrl,
+	}
+}
+
+func staleTempEntries(days int) []string {
+	deadline := time.Now().Add(-24 * time.Duration(days) * time.Hour)
+	entries, err := os.ReadDir(common.ProductTempRoot())
+	if err != nil {
+		return nil
+	}
+	result := []string{}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err == nil && info.ModTime().Before(deadline) {
+			result = append(result, filepath.Join(common.ProductTempRoot(), entry.Name()))
+		}
+	}
+	return result
+}
+
+func staleTempCheck() *common.DiagnosticCheck {
+	supportGeneralUrl := settings.Global.DocsLink("troubleshooting")
+	stale := len(staleTempEntries(staleTempDays))
+	if stale > 0 {
+		return &common.DiagnosticCheck{
+			Type:     "OS",
+			Category: common.CategoryStaleTemp,
+			Status:   statusWarning,
+			Message:  fmt.Sprintf("There are %d temp entries older than %d days in %q.", stale, staleTempDays, common.ProductTempRoot()),
+			Link:     supportGeneralUrl,
+		}
+	}
+	return &common.DiagnosticCheck{
+		Type:     "OS",
+		Category: common.CategoryStaleTemp,
+		Status:   statusOk,
+		Message:  fmt.Sprintf("No temp entries older than %d days.", staleTempDays),
+		Link:     supportGeneralUrl,
+	}
+}
+
+func restoreValidationCheck() *common.DiagnosticCheck {
+	supportGeneralUrl := settings.Global.DocsLink("troubleshooting")
+	findings, err := htfs.LoadRestoreFindings()
+	if err != nil {
+		return &common.DiagnosticCheck{
+			Type:     "OS",
+			Category: common.CategoryRestoreValidation,
+			Status:   statusWarning,
+			Message:  fmt.Sprintf("Could not read restore validation findings, reason: %v", err),
+			Link:     supportGeneralUrl,
+		}
+	}
+	if findings == nil {
+		return &common.DiagnosticCheck{
+			Type:     "OS",
+			Category: common.CategoryRestoreValidation,
+			Status:   statusOk,
+			Message:  fmt.Sprintf("No restore validation findings. Set %s to enable sampling.", common.RCC_RESTORE_VERIFY),
+			Link:     supportGeneralUrl,
+		}
+	}
+	if findings.Broken > 0 {
+		example := "?"
+		if len(findings.Corrupted) > 0 {
+			example = findings.Corrupted[0]
+		}
+		return &common.DiagnosticCheck{
+			Type:     "OS",
+			Category: common.CategoryRestoreValidation,
+			Status:   statusWarning,
+			Message:  fmt.Sprintf("Latest restore validation (%s) found %d corrupted files of %d sampled in %q, like %q. Check disk health.", findings.When, findings.Broken, findings.Sampled, findings.Space, example),
+			Link:     supportGeneralUrl,
+		}
+	}
+	return &common.DiagnosticCheck{
+		Type:     "OS",
+		Category: common.CategoryRestoreValidation,
+		Status:   statusOk,
+		Message:  fmt.Sprintf("Latest restore validation (%s) verified %d of %d files in %q, no corruption.", findings.When, findings.Sampled, findings.Total, findings.Space),
+		Link:     supportGeneralUrl,
+	}
+}
+
+// antivirusAdvice tells how to exclude holotree and hololib from scanning.
+func antivirusAdvice(scanner string) string {
+	if runtime.GOOS == "windows" && (len(scanner) == 0 || scanner == "Windows Defender") {
+		return fmt.Sprintf("As administrator, exclude them from Windows Defender with: Add-MpPreference -ExclusionPath %q,%q", common.HolotreeLocation(), common.HololibLocation())
+	}
+	return fmt.Sprintf("Consider excluding %q and %q from on-access scanning of your antivirus.", common.HolotreeLocation(), common.HololibLocation())
+}
+
+func antivirusCheck() *common.DiagnosticCheck {
+	supportGeneralUrl := settings.Global.DocsLink("troubleshooting")
+	findings, err := htfs.LoadAntivirusFindings()
+	if err != nil {
+		return &common.DiagnosticCheck{
+			Type:     "OS",
+			Category: common.CategoryAntivirus,
+			Status:   statusWarning,
+			Message:  fmt.Sprintf("Could not read antivirus findings, reason: %v", err),
+			Link:     supportGeneralUrl,
+		}
+	}
+	if findings == nil {
+		return &common.DiagnosticCheck{
+			Type:     "OS",
+			Category: common.CategoryAntivirus,
+			Status:   statusOk,
+			Message:  "No restore timing findings yet, so antivirus impact is not known.",
+			Link:     supportGeneralUrl,
+		}
+	}
+	if findings.Suspected && findings.Excluded != htfs.ExclusionYes {
+		return &common.DiagnosticCheck{
+			Type:     "OS",
+			Category: common.CategoryAntivirus,
+			Status:   statusWarning,
+			Message:  fmt.Sprintf("Latest restore (%s) of %d files into %q took %.1fms per file, which suggests antivirus scanning (estimated slowdown %.1fs, excluded: %s). %s", findings.When, findings.Files, findings.Space, findings.Latency, findings.Slowdown, findings.Excluded, antivirusAdvice(findings.Scanner)),
+			Link:     supportGeneralUrl,
+		}
+	}
+	return &common.DiagnosticCheck{
+		Type:     "OS",
+		Category: common.CategoryAntivirus,
+		Status:   statusOk,
+		Message:  fmt.Sprintf("Latest restore (%s) of %d files took %.1fms per file, no sign of antivirus slowdown.", findings.When, findings.Files, findings.Latency),
+		Link:     supportGeneralUrl,
+	}
+}
+
+func gpuCheck(details map[string]string) *common.DiagnosticCheck {
+	supportGeneralUrl := settings.Global.DocsLink("troubleshooting")
+	host := conda.DetectGpu()
+	details["gpu-driver"] = host.Driver
+	details["gpu-cuda"] = host.Cuda
+	details["gpu-devices"] = strings.Join(host.Devices, ", ")
+	if !host.Available() {
+		return &common.DiagnosticCheck{
+			Type:     "OS",
+			Category: common.CategoryGpu,
+			Status:   statusOk,
+			Message:  "No NVIDIA GPU driver detected, so environments requiring CUDA cannot use GPU here.",
+			Link:     supportGeneralUrl,
+		}
+	}
+	if len(host.Devices) == 0 {
+		return &common.DiagnosticCheck{
+			Type:     "OS",
+			Category: common.CategoryGpu,
+			Status:   statusWarning,
+			Message:  fmt.Sprintf("NVIDIA driver %s is installed, but no GPU devices were listed by nvidia-smi.", host.Driver),
+			Link:     supportGeneralUrl,
+		}
+	}
+	return &common.DiagnosticCheck{
+		Type:     "OS",
+		Category: common.CategoryGpu,
+		Status:   statusOk,
+		Message:  fmt.Sprintf("Detected %s; environments may use CUDA up to %s.", host, host.Cuda),
+		Link:     supportGeneralUrl,
+	}
+}
+
+func spaceUsageCheck() *common.DiagnosticCheck {
+	supportGeneralUrl := settings.Global.DocsLink("troubleshooting")
+	spaces := htfs.LoadCatalogInfos().Spaces()
+	paths := make([]string, 0, len(spaces))
+	for _, space := range spaces {
+		paths = append(paths, space.Path)
+	}
+	usages := htfs.UsageByUser(paths, nil)
+	deadline := time.Now().Add(-staleUserDays * 24 * time.Hour).Unix()
+	stale := []string{}
+	for _, usage := range usages {
+		if usage.Last < deadline {
+			stale = append(stale, fmt.Sprintf("%s (%d spaces)", usage.User, usage.Spaces))
+		}
+	}
+	if len(stale) > 0 {
+		return &common.DiagnosticCheck{
+			Type:     "OS",
+			Category: common.CategorySpaceUsage,
+			Status:   statusWarning,
+			Message:  fmt.Sprintf("%d of %d holotree users have not used their spaces in %d days: %s. See `rcc holotree usage --by-user` for details.", len(stale), len(usages), staleUserDays, strings.Join(stale, ", ")),
+			Link:     supportGeneralUrl,
+		}
+	}
+	return &common.DiagnosticCheck{
+		Type:     "OS",
+		Category: common.CategorySpaceUsage,
+		Status:   statusOk,
+		Message:  fmt.Sprintf("%d holotree spaces used by %d known users, none stale.", len(spaces), len(usages)),
+		Link:     supportGeneralUrl,
+	}
+}
+
 func anyEnvVarCheck(key string) *common.DiagnosticCheck {
 	supportGeneralUrl := settings.Global.DocsLink("troubleshooting")
 	anyVar := os.Getenv(key)
@@ -437,6 +668,16 @@ func productHomeCheck() *common.DiagnosticCheck {
 	}
 }
 
+func offlineCheck() *common.DiagnosticCheck {
+	return &common.DiagnosticCheck{
+		Type:     "network",
+		Category: common.CategoryNetworkLink,
+		Status:   statusWarning,
+		Message:  "Offline mode is active (--offline or RCC_OFFLINE). Network checks were skipped.",
+		Link:     settings.Global.DocsLink("troubleshooting/firewall-and-proxies"),
+	}
+}
+
 func dnsLookupCheck(site string) *common.DiagnosticCheck {
 	supportNetworkUrl := settings.Global.DocsLink("troubleshooting/firewall-and-proxies")
 	found, err := net.LookupHost(site)
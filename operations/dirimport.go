@@ -0,0 +1,67 @@
+package operations
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/fail"
+	"github.com/joshyorko/rcc/htfs"
+)
+
+// acceptDirectory imports un-zipped hololib directory (with catalog and
+// library subdirectories, like on network share). Only blobs missing from
+// local hololib are read, and each of them is digest verified.
+func acceptDirectory(directory string, sink *hololibSink) (err error) {
+	defer fail.Around(&err)
+
+	return filepath.WalkDir(directory, func(fullpath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		relative, err := filepath.Rel(directory, fullpath)
+		if err != nil {
+			return err
+		}
+		name := slashed(relative)
+		if !hololibEntry(name) {
+			common.Trace("Ignoring non-hololib file %q in %q.", relative, directory)
+			return nil
+		}
+		missing, err := sink.Missing(name)
+		if err != nil || !missing {
+			return err
+		}
+		source, err := os.Open(fullpath)
+		if err != nil {
+			return err
+		}
+		defer source.Close()
+		return sink.Accept(name, source)
+	})
+}
+
+func foreignDirectoryCatalogs(directory, platform string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(directory, "catalog"))
+	if err != nil {
+		return nil, err
+	}
+	result := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() || !catalogPattern.MatchString(path.Join("catalog", entry.Name())) {
+			continue
+		}
+		if htfs.CatalogPlatform(entry.Name()) != strings.ToLower(platform) {
+			result = append(result, entry.Name())
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
@@ -0,0 +1,96 @@
+package operations
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/hamlet"
+	"github.com/joshyorko/rcc/htfs"
+	"github.com/joshyorko/rcc/pathlib"
+)
+
+func writeEntry(t *testing.T, directory, name string, content []byte) {
+	t.Helper()
+	fullpath := filepath.Join(directory, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(fullpath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fullpath, content, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCanImportHololibDirectory(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	fresh := []byte("fresh blob")
+	known := []byte("known blob")
+	freshDigest := fmt.Sprintf("%02x", sha256.Sum256(fresh))
+	knownDigest := fmt.Sprintf("%02x", sha256.Sum256(known))
+	catalog := "catalog/0123456789abcdefv12.linux_amd64"
+
+	source := t.TempDir()
+	writeEntry(t, source, libraryEntry(freshDigest), gzipped(t, fresh))
+	writeEntry(t, source, libraryEntry(knownDigest), []byte("would not verify, so must not be read"))
+	writeEntry(t, source, catalog, []byte("catalog"))
+	writeEntry(t, source, "mutations/journal.log", []byte("ignored"))
+
+	target := t.TempDir()
+	writeEntry(t, target, libraryEntry(knownDigest), gzipped(t, known))
+
+	foreign, err := ForeignCatalogs(source, "windows_amd64")
+	must.Nil(err)
+	must.Equal([]string{"0123456789abcdefv12.linux_amd64"}, foreign)
+
+	sink := newHololibSink(target, source)
+	must.Nil(acceptDirectory(source, sink))
+	must.Nil(sink.Commit())
+	must.True(pathlib.IsFile(filepath.Join(target, catalog)))
+	must.True(pathlib.IsFile(filepath.Join(target, libraryEntry(freshDigest))))
+	wont.True(pathlib.IsFile(filepath.Join(target, "mutations", "journal.log")))
+	must.Equal(1, sink.blobs)
+	must.Equal(1, sink.summary.Skipped)
+	must.Equal(1, sink.summary.Verified)
+}
+
+func TestDirectoryImportRejectsCorruptedBlobs(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	digest := fmt.Sprintf("%02x", sha256.Sum256([]byte("expected")))
+	source := t.TempDir()
+	writeEntry(t, source, libraryEntry(digest), gzipped(t, []byte("tampered")))
+
+	target := t.TempDir()
+	sink := newHololibSink(target, source)
+	wont.Nil(acceptDirectory(source, sink))
+	wont.True(pathlib.IsFile(filepath.Join(target, libraryEntry(digest))))
+	must.Equal(0, sink.blobs)
+}
+
+func TestTrustedImportSkipsVerificationAndRecordsIt(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	t.Setenv(common.RCC_PULL_VERIFY, htfs.PullVerifyTrust)
+	digest := fmt.Sprintf("%02x", sha256.Sum256([]byte("expected")))
+	catalog := "catalog/0123456789abcdefv12.linux_amd64"
+	source := t.TempDir()
+	writeEntry(t, source, libraryEntry(digest), gzipped(t, []byte("unverified")))
+	writeEntry(t, source, catalog, []byte("catalog"))
+
+	target := t.TempDir()
+	sink := newHololibSink(target, source)
+	must.Nil(acceptDirectory(source, sink))
+	must.Nil(sink.Commit())
+	must.True(pathlib.IsFile(filepath.Join(target, libraryEntry(digest))))
+	must.Equal(0, sink.summary.Verified)
+
+	record, ok := htfs.LoadCatalogVerifications(target)["0123456789abcdefv12.linux_amd64"]
+	must.True(ok)
+	must.Equal(htfs.PullVerifyTrust, record.Mode)
+	must.Equal(1, record.Unverified)
+	must.Equal(source, record.Source)
+}
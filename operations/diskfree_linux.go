@@ -0,0 +1,14 @@
+package operations
+
+import (
+	"syscall"
+)
+
+func diskFree(location string) (uint64, error) {
+	var stat syscall.Statfs_t
+	err := syscall.Statfs(location, &stat)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize), nil
+}
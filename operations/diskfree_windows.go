@@ -0,0 +1,18 @@
+package operations
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+func diskFree(location string) (uint64, error) {
+	path, err := windows.UTF16PtrFromString(location)
+	if err != nil {
+		return 0, err
+	}
+	var available, total, free uint64
+	err = windows.GetDiskFreeSpaceEx(path, &available, &total, &free)
+	if err != nil {
+		return 0, err
+	}
+	return available, nil
+}
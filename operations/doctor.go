@@ -0,0 +1,231 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/conda"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/pretty"
+)
+
+type remedy struct {
+	Name    string
+	Command string
+	Fix     func() error
+}
+
+type DoctorFinding struct {
+	Category uint64 `json:"category"`
+	Remedy   string `json:"remedy"`
+	Before   string `json:"before"`
+	After    string `json:"after"`
+	Applied  bool   `json:"applied"`
+	Error    string `json:"error,omitempty"`
+}
+
+var (
+	statusRanking = map[string]int{
+		statusOk:      0,
+		statusWarning: 1,
+		statusFail:    2,
+		statusFatal:   3,
+	}
+)
+
+func remedies() map[uint64]*remedy {
+	return map[uint64]*remedy{
+		common.CategoryLockFile: &remedy{
+			Name:    "reset lockfile permissions",
+			Command: "rcc doctor --fix",
+			Fix:     fixLockfiles,
+		},
+		common.CategoryLongPath: &remedy{
+			Name:    "enable long path support",
+			Command: "rcc configuration longpaths --enable",
+			Fix:     conda.EnforceLongpathSupport,
+		},
+		common.CategoryHolotreeShared: &remedy{
+			Name:    "make holotree directories shared",
+			Command: "rcc holotree shared --enable",
+			Fix:     fixSharedDirectories,
+		},
+		common.CategoryMicromamba: &remedy{
+			Name:    "extract micromamba from rcc",
+			Command: "rcc doctor --fix",
+			Fix:     fixMicromamba,
+		},
+		common.CategoryStaleTemp: &remedy{
+			Name:    fmt.Sprintf("remove temp entries older than %d days", staleTempDays),
+			Command: fmt.Sprintf("rcc configuration cleanup --days %d", staleTempDays),
+			Fix:     fixStaleTemp,
+		},
+	}
+}
+
+// lockHeld probes lock without waiting for it. When lockfile cannot even be
+// opened (which is usually why it needs fixing), lock pid markers tell if
+// some running rcc holds it.
+func lockHeld(filename string) bool {
+	locker, free, err := pathlib.TryLocker(filename, false)
+	if err == nil {
+		if free {
+			locker.Release()
+		}
+		return !free
+	}
+	holders, err := pathlib.LockHoldersBy(filename)
+	return err != nil || len(holders) > 0
+}
+
+func fixLockfiles() error {
+	held := []string{}
+	for _, filename := range lockfiles() {
+		if !pathlib.Exists(filename) {
+			continue
+		}
+		if lockHeld(filename) {
+			held = append(held, filename)
+			continue
+		}
+		if os.Chmod(filename, 0o666) == nil {
+			continue
+		}
+		err := os.Remove(filename)
+		if err != nil {
+			return fmt.Errorf("Could not fix lockfile %q, reason: %v", filename, err)
+		}
+	}
+	if len(held) > 0 {
+		sort.Strings(held)
+		return fmt.Errorf("Lockfiles %q are held by running rcc processes, and were left as they are.", held)
+	}
+	return nil
+}
+
+func fixSharedDirectories() error {
+	locations := []string{
+		common.Product.HoloLocation(),
+		common.HololibLocation(),
+		common.HololibCatalogLocation(),
+		common.HololibLibraryLocation(),
+	}
+	for _, location := range locations {
+		if pathlib.IsSharedDir(location) {
+			continue
+		}
+		_, err := pathlib.ForceSharedDir(location)
+		if err != nil {
+			return fmt.Errorf("Could not make %q shared, reason: %v", location, err)
+		}
+	}
+	return nil
+}
+
+func fixMicromamba() error {
+	if conda.DoExtract(0) {
+		return nil
+	}
+	return fmt.Errorf("Could not extract micromamba into %q.", conda.BinMicromamba())
+}
+
+func fixStaleTemp() error {
+	for _, fullpath := range staleTempEntries(staleTempDays) {
+		err := os.RemoveAll(fullpath)
+		if err != nil {
+			return fmt.Errorf("Could not remove %q, reason: %v", fullpath, err)
+		}
+		common.Debug("Removed stale temp %q.", fullpath)
+	}
+	return nil
+}
+
+func worstStatuses(status *common.DiagnosticStatus) map[uint64]string {
+	result := make(map[uint64]string)
+	for _, check := range status.Checks {
+		known, ok := result[check.Category]
+		if !ok || statusRanking[check.Status] > statusRanking[known] {
+			result[check.Category] = check.Status
+		}
+	}
+	return result
+}
+
+func doctorFindings(before map[uint64]string, known map[uint64]*remedy) []*DoctorFinding {
+	result := []*DoctorFinding{}
+	for category, status := range before {
+		remedy, ok := known[category]
+		if !ok || status == statusOk {
+			continue
+		}
+		result = append(result, &DoctorFinding{
+			Category: category,
+			Remedy:   remedy.Name,
+			Before:   status,
+			After:    status,
+		})
+	}
+	sort.Slice(result, func(left, right int) bool {
+		return result[left].Category < result[right].Category
+	})
+	return result
+}
+
+func RunDoctor(fix bool) ([]*DoctorFinding, error) {
+	known := remedies()
+	findings := doctorFindings(worstStatuses(runDiagnostics(true)), known)
+	if len(findings) == 0 {
+		common.Log("Doctor found nothing to fix.")
+		return findings, nil
+	}
+	for _, finding := range findings {
+		remedy := known[finding.Category]
+		if !fix {
+			common.Log("Category %d is %q. Suggested fix: %s [%s]", finding.Category, finding.Before, remedy.Name, remedy.Command)
+			continue
+		}
+		common.Log("Category %d is %q. Applying fix: %s ...", finding.Category, finding.Before, remedy.Name)
+		err := remedy.Fix()
+		finding.Applied = err == nil
+		if err != nil {
+			finding.Error = err.Error()
+			pretty.Warning("Fix %q failed, reason: %v", remedy.Name, err)
+		}
+	}
+	if !fix {
+		return findings, nil
+	}
+	after := worstStatuses(runDiagnostics(true))
+	for _, finding := range findings {
+		status, ok := after[finding.Category]
+		if ok {
+			finding.After = status
+		} else {
+			finding.After = statusOk
+		}
+	}
+	return findings, nil
+}
+
+func DoctorReport(findings []*DoctorFinding, json bool) {
+	if json {
+		body, err := NiceJsonOutput(findings)
+		if err != nil {
+			common.Error("doctor", err)
+		} else {
+			common.Stdout("%s\n", body)
+		}
+		return
+	}
+	if len(findings) == 0 {
+		return
+	}
+	common.Log("")
+	common.Log("Doctor summary:")
+	common.Log("  %-8s  %-8s  %-8s  %-7s  %s", "Category", "Before", "After", "Applied", "Remedy")
+	for _, finding := range findings {
+		common.Log("  %-8d  %-8s  %-8s  %-7v  %s", finding.Category, finding.Before, finding.After, finding.Applied, finding.Remedy)
+	}
+}
@@ -0,0 +1,45 @@
+package operations
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/hamlet"
+	"github.com/joshyorko/rcc/pathlib"
+)
+
+func TestDoctorFindsOnlyRemediableProblems(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	status := &common.DiagnosticStatus{
+		Checks: []*common.DiagnosticCheck{
+			{Category: common.CategoryLockFile, Status: statusOk},
+			{Category: common.CategoryLockFile, Status: statusFail},
+			{Category: common.CategoryStaleTemp, Status: statusWarning},
+			{Category: common.CategoryMicromamba, Status: statusOk},
+			{Category: common.CategoryNetworkDNS, Status: statusFail},
+		},
+	}
+	worst := worstStatuses(status)
+	must.Equal(statusFail, worst[common.CategoryLockFile])
+	must.Equal(statusOk, worst[common.CategoryMicromamba])
+
+	findings := doctorFindings(worst, remedies())
+	must.Equal(2, len(findings))
+	must.Equal(uint64(common.CategoryLockFile), findings[0].Category)
+	must.Equal(statusFail, findings[0].Before)
+	must.Equal(uint64(common.CategoryStaleTemp), findings[1].Category)
+}
+
+func TestDoctorDetectsHeldLockfiles(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	t.Setenv("ROBOCORP_HOME", t.TempDir())
+	filename := filepath.Join(t.TempDir(), "doctor.lck")
+	locker, err := pathlib.Locker(filename, 100, false)
+	must.Nil(err)
+	must.True(lockHeld(filename))
+	must.Nil(locker.Release())
+	wont.True(lockHeld(filename))
+}
@@ -0,0 +1,87 @@
+package operations
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	FormatShell      = "shell"
+	FormatDotenv     = "dotenv"
+	FormatJson       = "json"
+	FormatPowershell = "powershell"
+	FormatFish       = "fish"
+)
+
+var (
+	VariableFormats = []string{FormatShell, FormatDotenv, FormatJson, FormatPowershell, FormatFish}
+)
+
+type variable struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+func splitVariables(items []string) []*variable {
+	result := make([]*variable, 0, len(items))
+	for _, line := range items {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok || len(key) == 0 {
+			continue
+		}
+		result = append(result, &variable{Key: key, Value: value})
+	}
+	return result
+}
+
+func dotenvValue(value string) string {
+	if !strings.ContainsAny(value, " \t\r\n\"'\\#$`") {
+		return value
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, `$`, `\$`, "`", "\\`")
+	return fmt.Sprintf(`"%s"`, replacer.Replace(value))
+}
+
+func powershellValue(value string) string {
+	return fmt.Sprintf("'%s'", strings.ReplaceAll(value, "'", "''"))
+}
+
+func fishValue(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	return fmt.Sprintf("'%s'", replacer.Replace(value))
+}
+
+// FormatVariables renders environment variables (KEY=value lines) in given
+// format, so that integrations can consume them without parsing. Shell
+// format is "export KEY=value" lines (or "SET KEY=value" on Windows).
+func FormatVariables(format string, items []string, windows bool) (string, error) {
+	variables := splitVariables(items)
+	lines := make([]string, 0, len(variables))
+	switch format {
+	case FormatJson:
+		return NiceJsonOutput(variables)
+	case FormatShell, "":
+		prefix := "export"
+		if windows {
+			prefix = "SET"
+		}
+		for _, line := range items {
+			lines = append(lines, fmt.Sprintf("%s %s", prefix, line))
+		}
+	case FormatDotenv:
+		for _, entry := range variables {
+			lines = append(lines, fmt.Sprintf("%s=%s", entry.Key, dotenvValue(entry.Value)))
+		}
+	case FormatPowershell:
+		for _, entry := range variables {
+			lines = append(lines, fmt.Sprintf("$env:%s = %s", entry.Key, powershellValue(entry.Value)))
+		}
+	case FormatFish:
+		for _, entry := range variables {
+			lines = append(lines, fmt.Sprintf("set -gx %s %s", entry.Key, fishValue(entry.Value)))
+		}
+	default:
+		return "", fmt.Errorf("Unknown variables format %q, expected one of: %s.", format, strings.Join(VariableFormats, ", "))
+	}
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,44 @@
+package operations
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestCanFormatVariablesForIntegrations(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	items := []string{"PLAIN=value", "SPACED=a b", "QUOTED=it's \"$HOME\"", "=broken", "EMPTY="}
+
+	content, err := FormatVariables(FormatShell, items[:1], false)
+	must.Nil(err)
+	must.Equal("export PLAIN=value", content)
+	content, err = FormatVariables("", items[:1], true)
+	must.Nil(err)
+	must.Equal("SET PLAIN=value", content)
+
+	content, err = FormatVariables(FormatDotenv, items, false)
+	must.Nil(err)
+	must.Equal("PLAIN=value\nSPACED=\"a b\"\nQUOTED=\"it's \\\"\\$HOME\\\"\"\nEMPTY=", content)
+
+	content, err = FormatVariables(FormatPowershell, items[2:3], false)
+	must.Nil(err)
+	must.Equal(`$env:QUOTED = 'it''s "$HOME"'`, content)
+
+	content, err = FormatVariables(FormatFish, items[2:3], false)
+	must.Nil(err)
+	must.Equal(`set -gx QUOTED 'it\'s "$HOME"'`, content)
+
+	content, err = FormatVariables(FormatJson, items, false)
+	must.Nil(err)
+	parsed := []map[string]string{}
+	must.Nil(json.Unmarshal([]byte(content), &parsed))
+	must.Equal(4, len(parsed))
+	must.Equal("SPACED", parsed[1]["key"])
+	must.Equal("a b", parsed[1]["value"])
+
+	_, err = FormatVariables("xml", items, false)
+	wont.Nil(err)
+}
@@ -0,0 +1,156 @@
+package operations
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/fail"
+	"github.com/joshyorko/rcc/htfs"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/settings"
+)
+
+type locator func(digest string) string
+
+type CatalogHealth struct {
+	Catalog string   `json:"catalog"`
+	Files   int      `json:"files"`
+	Missing []string `json:"missing,omitempty"`
+	Corrupt []string `json:"corrupt,omitempty"`
+	Error   string   `json:"error,omitempty"`
+}
+
+type CatalogProgress func(at, total int, health *CatalogHealth)
+
+func (it *CatalogHealth) Broken() bool {
+	return len(it.Missing) > 0 || len(it.Corrupt) > 0 || len(it.Error) > 0
+}
+
+func (it *CatalogHealth) Summary() string {
+	if len(it.Error) > 0 {
+		return it.Error
+	}
+	if !it.Broken() {
+		return fmt.Sprintf("ok, %d files", it.Files)
+	}
+	return fmt.Sprintf("%d files, %d missing, %d corrupted", it.Files, len(it.Missing), len(it.Corrupt))
+}
+
+func catalogHealth(fullpath string, locate locator, verified map[string]error) *CatalogHealth {
+	result := &CatalogHealth{
+		Catalog: filepath.Base(fullpath),
+		Missing: []string{},
+		Corrupt: []string{},
+	}
+	shadow, err := htfs.NewRoot(filepath.Join(common.ProductTemp(), "shadow"))
+	if err == nil {
+		err = shadow.LoadCatalog(fullpath)
+	}
+	if err != nil {
+		result.Error = fmt.Sprintf("Could not load catalog, reason: %v", err)
+		return result
+	}
+	digests := make(map[string]string)
+	err = shadow.Treetop(htfs.DigestMapper(digests))
+	if err != nil {
+		result.Error = fmt.Sprintf("Could not walk catalog, reason: %v", err)
+		return result
+	}
+	result.Files = len(digests)
+	for digest := range digests {
+		problem, ok := verified[digest]
+		if !ok {
+			location := locate(digest)
+			if pathlib.IsFile(location) {
+				problem = htfs.VerifyBlobFile(location, digest)
+			} else {
+				problem = fmt.Errorf("Content %s is missing.", digest)
+			}
+			verified[digest] = problem
+		}
+		switch {
+		case problem == nil:
+		case pathlib.IsFile(locate(digest)):
+			result.Corrupt = append(result.Corrupt, digest)
+		default:
+			result.Missing = append(result.Missing, digest)
+		}
+	}
+	sort.Strings(result.Missing)
+	sort.Strings(result.Corrupt)
+	return result
+}
+
+func CheckCatalogHealth(catalogs []string, progress CatalogProgress) []*CatalogHealth {
+	common.TimelineBegin("catalog health check start")
+	defer common.TimelineEnd()
+
+	verified := make(map[string]error)
+	result := make([]*CatalogHealth, 0, len(catalogs))
+	for at, catalog := range catalogs {
+		fullpath := filepath.Join(common.HololibCatalogLocation(), catalog)
+		health := catalogHealth(fullpath, htfs.ExactDefaultLocation, verified)
+		result = append(result, health)
+		if progress != nil {
+			progress(at+1, len(catalogs), health)
+		}
+	}
+	return result
+}
+
+// CatalogDiagnostics reports catalog health as diagnostics checks, one per
+// catalog.
+func CatalogDiagnostics(healths []*CatalogHealth) *common.DiagnosticStatus {
+	result := &common.DiagnosticStatus{
+		Details: make(map[string]string),
+		Checks:  []*common.DiagnosticCheck{},
+	}
+	result.Details["catalog-count"] = fmt.Sprintf("%d", len(healths))
+	diagnose := result.Diagnose("Catalog")
+	support := settings.Global.DocsLink("troubleshooting")
+	for _, health := range healths {
+		if health.Broken() {
+			diagnose.Fail(common.CategoryCatalogIntegrity, support, "Catalog %q is broken: %s.", health.Catalog, health.Summary())
+		} else {
+			diagnose.Ok(common.CategoryCatalogIntegrity, "Catalog %q is %s.", health.Catalog, health.Summary())
+		}
+	}
+	return result
+}
+
+func removeCorruptBlobs(health *CatalogHealth, locate locator) error {
+	for _, digest := range health.Corrupt {
+		err := pathlib.TryRemove("blob", locate(digest))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func RepairCatalog(health *CatalogHealth) (err error) {
+	defer fail.Around(&err)
+
+	origin := common.RccRemoteOrigin()
+	fail.On(len(origin) == 0, "Cannot repair %q without %s, delete it instead.", health.Catalog, common.RCC_REMOTE_ORIGIN)
+	err = removeCorruptBlobs(health, htfs.ExactDefaultLocation)
+	fail.On(err != nil, "%v", err)
+	err = PullCatalog(origin, health.Catalog, true)
+	fail.On(err != nil, "%v", err)
+	common.RunJournal("catalog repair", health.Catalog, "repaired from %q", origin)
+	return nil
+}
+
+func DeleteCatalog(health *CatalogHealth) (err error) {
+	defer fail.Around(&err)
+
+	err = removeCorruptBlobs(health, htfs.ExactDefaultLocation)
+	fail.On(err != nil, "%v", err)
+	fullpath := filepath.Join(common.HololibCatalogLocation(), health.Catalog)
+	err = pathlib.TryRemove("catalog", fullpath)
+	fail.On(err != nil, "%v", err)
+	common.RunJournal("catalog delete", health.Catalog, "deleted as broken")
+	return nil
+}
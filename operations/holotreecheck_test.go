@@ -0,0 +1,60 @@
+package operations
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+	"github.com/joshyorko/rcc/htfs"
+)
+
+func TestCatalogHealthFindsMissingAndCorruptContent(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	library := t.TempDir()
+	locate := func(digest string) string {
+		return filepath.Join(library, digest)
+	}
+	good := []byte("good content")
+	digest, err := htfs.BlobDigest(bytes.NewReader(good), "", nil)
+	must.Nil(err)
+	corrupt := strings.Repeat("0", len(digest))
+	missing := strings.Repeat("f", len(digest))
+	must.Nil(os.WriteFile(locate(digest), good, 0o644))
+	must.Nil(os.WriteFile(locate(corrupt), []byte("not matching"), 0o644))
+
+	root, err := htfs.NewRoot(".")
+	must.Nil(err)
+	root.Blueprint = "0123456789abcdef"
+	root.Tree.Files["good.txt"] = &htfs.File{Name: "good.txt", Digest: digest}
+	catalog := filepath.Join(t.TempDir(), htfs.CatalogName(root.Blueprint))
+	must.Nil(root.SaveAs(catalog))
+
+	verified := make(map[string]error)
+	health := catalogHealth(catalog, locate, verified)
+	wont.True(health.Broken())
+	must.Equal(1, health.Files)
+	must.Equal("ok, 1 files", health.Summary())
+
+	root.Tree.Files["corrupt.txt"] = &htfs.File{Name: "corrupt.txt", Digest: corrupt}
+	root.Tree.Files["missing.txt"] = &htfs.File{Name: "missing.txt", Digest: missing}
+	must.Nil(root.SaveAs(catalog))
+
+	health = catalogHealth(catalog, locate, verified)
+	must.True(health.Broken())
+	must.Equal(3, health.Files)
+	must.Equal([]string{missing}, health.Missing)
+	must.Equal([]string{corrupt}, health.Corrupt)
+	must.Equal(3, len(verified))
+
+	must.Nil(removeCorruptBlobs(health, locate))
+	_, err = os.Stat(locate(corrupt))
+	must.True(os.IsNotExist(err))
+
+	health = catalogHealth(filepath.Join(library, "nonexisting"), locate, verified)
+	must.True(health.Broken())
+	must.True(strings.Contains(health.Summary(), "Could not load catalog"))
+}
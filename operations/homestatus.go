@@ -0,0 +1,209 @@
+package operations
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/joshyorko/rcc/cloud"
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/htfs"
+	"github.com/joshyorko/rcc/journal"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/joshyorko/rcc/settings"
+)
+
+const (
+	widgetPending = "pending"
+	lowDiskLimit  = 5 * 1024 * 1024 * 1024
+)
+
+type StatusWidget struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Status string `json:"status"`
+	Hint   string `json:"hint"`
+}
+
+type statusProbe struct {
+	name  string
+	hint  string
+	probe func() (string, string)
+}
+
+func humaneBytes(size uint64) string {
+	value, suffix := pathlib.HumaneSizer(int64(size))
+	return fmt.Sprintf("%3.1f%s", value, suffix)
+}
+
+func profileWidget() (string, string) {
+	profiles, err := settings.ImportedProfiles()
+	if err != nil {
+		return fmt.Sprintf("%q active, reason: %v", settings.Global.Name(), err), statusWarning
+	}
+	return fmt.Sprintf("%q active (%d imported profiles)", settings.Global.Name(), len(profiles)), statusOk
+}
+
+func diskWidget() (string, string) {
+	free, err := diskFree(common.Product.Home())
+	if err != nil {
+		return fmt.Sprintf("unknown, reason: %v", err), statusWarning
+	}
+	status := statusOk
+	if free < lowDiskLimit {
+		status = statusWarning
+	}
+	return fmt.Sprintf("%s free under %s", humaneBytes(free), common.Product.Home()), status
+}
+
+func hololibWidget() (string, string) {
+	var total uint64
+	var count int
+	filepath.WalkDir(common.HololibLibraryLocation(), func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err == nil {
+			total += uint64(info.Size())
+			count += 1
+		}
+		return nil
+	})
+	return fmt.Sprintf("%s in %d blobs", humaneBytes(total), count), statusOk
+}
+
+func inventoryWidget() (string, string) {
+	catalogs := htfs.AllCatalogNames()
+	spaces, _ := filepath.Glob(filepath.Join(common.HolotreeLocation(), "*.meta"))
+	return fmt.Sprintf("%d catalogs, %d spaces", len(catalogs), len(spaces)), statusOk
+}
+
+func remoteWidget() (string, string) {
+	origin := common.RccRemoteOrigin()
+	if len(origin) == 0 {
+		return fmt.Sprintf("%s is not set", common.RCC_REMOTE_ORIGIN), statusOk
+	}
+	if settings.Global.Offline() {
+		return fmt.Sprintf("%s not checked, offline mode", origin), statusWarning
+	}
+	client, err := cloud.NewUnsafeClient(origin)
+	if err != nil {
+		return fmt.Sprintf("%s: %v", origin, err), statusFail
+	}
+	client = client.Uncritical().WithTimeout(5 * time.Second)
+	response := client.Head(client.NewRequest("/"))
+	if response.Err != nil {
+		return fmt.Sprintf("%s unreachable, reason: %v", origin, response.Err), statusFail
+	}
+	return fmt.Sprintf("%s reachable [%d in %s]", origin, response.Status, response.Elapsed), statusOk
+}
+
+func sharedWidget() (string, string) {
+	if pathlib.IsFile(common.SharedMarkerLocation()) {
+		return "shared holotree is enabled", statusOk
+	}
+	return "private holotree (shared not enabled)", statusOk
+}
+
+func lastRunWidget() (string, string) {
+	events, err := journal.Stats(4)
+	if err != nil {
+		return fmt.Sprintf("unknown, reason: %v", err), statusWarning
+	}
+	for at := len(events) - 1; at >= 0; at-- {
+		event := events[at]
+		if !event.Run {
+			continue
+		}
+		when := time.Unix(event.When, 0).Format(time.DateTime)
+		if event.Success {
+			return fmt.Sprintf("success at %s [%s]", when, event.What), statusOk
+		}
+		return fmt.Sprintf("failure at %s [%s]", when, event.What), statusFail
+	}
+	return "no runs during last four weeks", statusOk
+}
+
+func buildsWidget() (string, string) {
+	sources, err := journal.BuildSourceStatistics(4)
+	if err != nil {
+		return fmt.Sprintf("unknown, reason: %v", err), statusWarning
+	}
+	return sources.Summary(), statusOk
+}
+
+func statusProbes() []*statusProbe {
+	return []*statusProbe{
+		{"profile", "rcc interactive profiles", profileWidget},
+		{"disk", "rcc configuration cleanup", diskWidget},
+		{"hololib", "rcc holotree check", hololibWidget},
+		{"inventory", "rcc holotree list", inventoryWidget},
+		{"remote", "rcc holotree pull", remoteWidget},
+		{"shared", "rcc holotree shared --enable", sharedWidget},
+		{"last run", "rcc holotree stats", lastRunWidget},
+		{"builds", "rcc holotree stats --builds", buildsWidget},
+		{"activity", "rcc interactive activity", activityWidget},
+	}
+}
+
+func gatherWidgets(probes []*statusProbe, timeout time.Duration) []*StatusWidget {
+	type reply struct {
+		at     int
+		value  string
+		status string
+	}
+	result := make([]*StatusWidget, len(probes))
+	replies := make(chan *reply, len(probes))
+	for at, probe := range probes {
+		result[at] = &StatusWidget{
+			Name:   probe.name,
+			Value:  "still checking ...",
+			Status: widgetPending,
+			Hint:   probe.hint,
+		}
+		go func(at int, probe *statusProbe) {
+			value, status := probe.probe()
+			replies <- &reply{at, value, status}
+		}(at, probe)
+	}
+	deadline := time.After(timeout)
+	for waiting := len(probes); waiting > 0; waiting-- {
+		select {
+		case got := <-replies:
+			result[got.at].Value = got.value
+			result[got.at].Status = got.status
+		case <-deadline:
+			return result
+		}
+	}
+	return result
+}
+
+func HomeStatus(timeout time.Duration) []*StatusWidget {
+	return gatherWidgets(statusProbes(), timeout)
+}
+
+func ShowHomeStatus(widgets []*StatusWidget) {
+	compact := pretty.Compact()
+	for _, widget := range widgets {
+		color := pretty.Green
+		switch widget.Status {
+		case statusWarning, widgetPending:
+			color = pretty.Yellow
+		case statusFail, statusFatal:
+			color = pretty.Red
+		}
+		name := strings.ToUpper(widget.Name)
+		if compact {
+			common.Stdout("%s%-9s%s %s%s%s\n", pretty.White, name, pretty.Reset, color, pretty.Clip(widget.Value, pretty.TerminalColumns()-10), pretty.Reset)
+			continue
+		}
+		common.Stdout("%s%-10s%s %s%-8s%s %s\n", pretty.White, name, pretty.Reset, color, widget.Status, pretty.Reset, widget.Value)
+		common.Stdout("%-10s %s-> %s%s\n", "", pretty.Grey, widget.Hint, pretty.Reset)
+	}
+	common.Stdout("\n")
+}
@@ -0,0 +1,32 @@
+package operations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestGatherWidgetsLeavesSlowProbesPending(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	release := make(chan bool)
+	defer close(release)
+	probes := []*statusProbe{
+		{"fast", "rcc fast", func() (string, string) { return "done", statusOk }},
+		{"slow", "rcc slow", func() (string, string) { <-release; return "late", statusOk }},
+		{"broken", "rcc broken", func() (string, string) { return "bad", statusFail }},
+	}
+	widgets := gatherWidgets(probes, 100*time.Millisecond)
+	must.Equal(3, len(widgets))
+	must.Equal("fast", widgets[0].Name)
+	must.Equal("done", widgets[0].Value)
+	must.Equal(statusOk, widgets[0].Status)
+	must.Equal(widgetPending, widgets[1].Status)
+	must.Equal("rcc slow", widgets[1].Hint)
+	must.Equal(statusFail, widgets[2].Status)
+	wont.Equal(widgetPending, widgets[2].Status)
+
+	must.Equal(9, len(statusProbes()))
+	must.Equal("profile", statusProbes()[0].name)
+}
@@ -84,6 +84,10 @@ func TemplatesZip() string {
 func needNewTemplates() (ignore *MetaTemplates, err error) {
 	defer fail.Around(&err)
 
+	if settings.Global.Offline() {
+		common.Debug("Offline mode, using existing templates only.")
+		return nil, nil
+	}
 	metadata := settings.Global.TemplatesYamlURL()
 	if len(metadata) == 0 {
 		common.Debug("No URL for templates.yaml available.")
@@ -0,0 +1,92 @@
+package operations
+
+import (
+	"fmt"
+
+	"github.com/joshyorko/rcc/common"
+)
+
+const (
+	credentialService = `rcc-credentials`
+	StoreKeychain     = `keychain`
+	StoreFile         = `file`
+	StorePlain        = `plain`
+)
+
+// secretStore keeps account secrets outside of rcc.yaml, either in OS
+// keychain or in encrypted vault file.
+type secretStore interface {
+	Name() string
+	Save(account, secret string) error
+	Load(account string) (string, error)
+	Remove(account string) error
+}
+
+// credentialStores lists usable secret stores in preference order, based on
+// RCC_CREDENTIALS_STORE (keychain, file, or plain). Default is keychain, with
+// encrypted file as fallback for headless machines. Empty list means that
+// secrets are kept in plain text, as before.
+func credentialStores() []secretStore {
+	keychain := systemKeychain()
+	switch common.RccCredentialsStore() {
+	case StorePlain:
+		return []secretStore{}
+	case StoreFile:
+		return []secretStore{fileVault()}
+	case StoreKeychain:
+		if keychain != nil {
+			return []secretStore{keychain}
+		}
+		return []secretStore{}
+	}
+	if keychain != nil {
+		return []secretStore{keychain, fileVault()}
+	}
+	return []secretStore{fileVault()}
+}
+
+func findSecretStore(name string) (secretStore, error) {
+	switch name {
+	case StoreFile:
+		return fileVault(), nil
+	case StoreKeychain:
+		keychain := systemKeychain()
+		if keychain != nil {
+			return keychain, nil
+		}
+	}
+	return nil, fmt.Errorf("Credential store %q is not available on this machine.", name)
+}
+
+// storeSecret saves secret into first secret store that accepts it, and
+// returns name of that store. Empty name means that secret should be kept
+// in plain text.
+func storeSecret(account, secret string) string {
+	for _, store := range credentialStores() {
+		err := store.Save(account, secret)
+		if err == nil {
+			common.Debug("Secret of account %q stored into %s.", account, store.Name())
+			return store.Name()
+		}
+		common.Debug("Could not store secret of account %q into %s, reason: %v", account, store.Name(), err)
+	}
+	return ""
+}
+
+func loadSecret(account, name string) (string, error) {
+	store, err := findSecretStore(name)
+	if err != nil {
+		return "", err
+	}
+	return store.Load(account)
+}
+
+func removeSecret(account, name string) {
+	store, err := findSecretStore(name)
+	if err == nil {
+		err = store.Remove(account)
+	}
+	if err != nil {
+		common.Debug("Could not remove secret of account %q from %s, reason: %v", account, name, err)
+	}
+}
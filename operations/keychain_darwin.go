@@ -0,0 +1,71 @@
+package operations
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/joshyorko/rcc/shell"
+)
+
+// macKeychain uses login keychain through security command.
+type macKeychain struct {
+	tool string
+}
+
+func systemKeychain() secretStore {
+	tool, err := exec.LookPath("security")
+	if err != nil {
+		return nil
+	}
+	return &macKeychain{tool: tool}
+}
+
+func (it *macKeychain) Name() string {
+	return StoreKeychain
+}
+
+// securityQuoted quotes argument for interactive mode of security command.
+func securityQuoted(value string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+	return fmt.Sprintf(`"%s"`, escaped)
+}
+
+// Save feeds command into interactive mode of security command, so that
+// secret is never visible in process arguments (or in logged task arguments).
+func (it *macKeychain) Save(account, secret string) error {
+	command := fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n", securityQuoted(credentialService), securityQuoted(account), securityQuoted(secret))
+	task := shell.New(nil, ".", it.tool, "-i")
+	code, err := task.Feed([]byte(command))
+	if err != nil {
+		return err
+	}
+	if code != 0 {
+		return fmt.Errorf("security add-generic-password failed with code %d", code)
+	}
+	return nil
+}
+
+func (it *macKeychain) Load(account string) (string, error) {
+	task := shell.New(nil, ".", it.tool, "find-generic-password", "-s", credentialService, "-a", account, "-w")
+	output, code, err := task.CaptureOutput()
+	if err != nil {
+		return "", err
+	}
+	if code != 0 {
+		return "", fmt.Errorf("No secret for account %q in keychain.", account)
+	}
+	return strings.TrimRight(output, "\r\n"), nil
+}
+
+func (it *macKeychain) Remove(account string) error {
+	task := shell.New(nil, ".", it.tool, "delete-generic-password", "-s", credentialService, "-a", account)
+	code, err := task.Feed([]byte{})
+	if err != nil {
+		return err
+	}
+	if code != 0 {
+		return fmt.Errorf("security delete-generic-password failed with code %d", code)
+	}
+	return nil
+}
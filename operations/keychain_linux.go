@@ -0,0 +1,64 @@
+package operations
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/joshyorko/rcc/shell"
+)
+
+// secretService uses freedesktop Secret Service (GNOME Keyring, KWallet)
+// through secret-tool command.
+type secretService struct {
+	tool string
+}
+
+func systemKeychain() secretStore {
+	tool, err := exec.LookPath("secret-tool")
+	if err != nil {
+		return nil
+	}
+	return &secretService{tool: tool}
+}
+
+func (it *secretService) Name() string {
+	return StoreKeychain
+}
+
+func (it *secretService) Save(account, secret string) error {
+	label := fmt.Sprintf("rcc credentials for %s", account)
+	task := shell.New(nil, ".", it.tool, "store", "--label", label, "service", credentialService, "account", account)
+	code, err := task.Feed([]byte(secret))
+	if err != nil {
+		return err
+	}
+	if code != 0 {
+		return fmt.Errorf("secret-tool store failed with code %d", code)
+	}
+	return nil
+}
+
+func (it *secretService) Load(account string) (string, error) {
+	task := shell.New(nil, ".", it.tool, "lookup", "service", credentialService, "account", account)
+	output, code, err := task.CaptureOutput()
+	if err != nil {
+		return "", err
+	}
+	if code != 0 {
+		return "", fmt.Errorf("No secret for account %q in Secret Service.", account)
+	}
+	return strings.TrimRight(output, "\r\n"), nil
+}
+
+func (it *secretService) Remove(account string) error {
+	task := shell.New(nil, ".", it.tool, "clear", "service", credentialService, "account", account)
+	code, err := task.Feed([]byte{})
+	if err != nil {
+		return err
+	}
+	if code != 0 {
+		return fmt.Errorf("secret-tool clear failed with code %d", code)
+	}
+	return nil
+}
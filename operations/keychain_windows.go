@@ -0,0 +1,110 @@
+package operations
+
+import (
+	"fmt"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	credTypeGeneric         = 1
+	credPersistLocalMachine = 2
+)
+
+var (
+	advapi32       = windows.NewLazySystemDLL("advapi32.dll")
+	procCredWrite  = advapi32.NewProc("CredWriteW")
+	procCredRead   = advapi32.NewProc("CredReadW")
+	procCredDelete = advapi32.NewProc("CredDeleteW")
+	procCredFree   = advapi32.NewProc("CredFree")
+)
+
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        windows.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// credentialManager uses Windows Credential Manager generic credentials.
+type credentialManager struct{}
+
+func systemKeychain() secretStore {
+	if procCredWrite.Find() != nil {
+		return nil
+	}
+	return &credentialManager{}
+}
+
+func credentialTarget(account string) string {
+	return fmt.Sprintf("%s:%s", credentialService, account)
+}
+
+func (it *credentialManager) Name() string {
+	return StoreKeychain
+}
+
+func (it *credentialManager) Save(account, secret string) error {
+	target, err := windows.UTF16PtrFromString(credentialTarget(account))
+	if err != nil {
+		return err
+	}
+	username, err := windows.UTF16PtrFromString(account)
+	if err != nil {
+		return err
+	}
+	blob := []byte(secret)
+	entry := credential{
+		Type:               credTypeGeneric,
+		TargetName:         target,
+		CredentialBlobSize: uint32(len(blob)),
+		Persist:            credPersistLocalMachine,
+		UserName:           username,
+	}
+	if len(blob) > 0 {
+		entry.CredentialBlob = &blob[0]
+	}
+	ok, _, err := procCredWrite.Call(uintptr(unsafe.Pointer(&entry)), 0)
+	if ok == 0 {
+		return fmt.Errorf("CredWrite failed, reason: %v", err)
+	}
+	return nil
+}
+
+func (it *credentialManager) Load(account string) (string, error) {
+	target, err := windows.UTF16PtrFromString(credentialTarget(account))
+	if err != nil {
+		return "", err
+	}
+	var entry *credential
+	ok, _, err := procCredRead.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&entry)))
+	if ok == 0 {
+		return "", fmt.Errorf("No secret for account %q in Credential Manager, reason: %v", account, err)
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(entry)))
+	if entry.CredentialBlobSize == 0 {
+		return "", nil
+	}
+	return string(unsafe.Slice(entry.CredentialBlob, entry.CredentialBlobSize)), nil
+}
+
+func (it *credentialManager) Remove(account string) error {
+	target, err := windows.UTF16PtrFromString(credentialTarget(account))
+	if err != nil {
+		return err
+	}
+	ok, _, err := procCredDelete.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0)
+	if ok == 0 {
+		return fmt.Errorf("CredDelete failed, reason: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/robot"
+)
+
+const (
+	ExitKilledByLimit = 124
+	limitPollInterval = 1 * time.Second
+	limitGracePeriod  = 10 * time.Second
+	megabyte          = 1024 * 1024
+)
+
+type RunLimits struct {
+	Timeout  time.Duration
+	Memory   uint64
+	Niceness int
+}
+
+type limitSupervisor struct {
+	sync.Mutex
+	limits     *RunLimits
+	started    time.Time
+	terminated time.Time
+	reason     string
+	cancel     chan bool
+	done       chan bool
+}
+
+func (it *RunLimits) Active() bool {
+	return it != nil && (it.Timeout > 0 || it.Memory > 0 || it.Niceness != 0)
+}
+
+func EffectiveLimits(flags *RunLimits, config robot.Robot) (*RunLimits, error) {
+	result := &RunLimits{}
+	if config != nil && config.RunLimits() != nil {
+		defined := config.RunLimits()
+		if len(defined.Timeout) > 0 {
+			timeout, err := time.ParseDuration(defined.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("Invalid timeout %q in robot.yaml, reason: %v", defined.Timeout, err)
+			}
+			result.Timeout = timeout
+		}
+		result.Memory = defined.Memory
+		result.Niceness = defined.Niceness
+	}
+	if flags != nil {
+		if flags.Timeout > 0 {
+			result.Timeout = flags.Timeout
+		}
+		if flags.Memory > 0 {
+			result.Memory = flags.Memory
+		}
+		if flags.Niceness != 0 {
+			result.Niceness = flags.Niceness
+		}
+	}
+	return result, nil
+}
+
+func newLimitSupervisor(limits *RunLimits) *limitSupervisor {
+	return &limitSupervisor{
+		limits:  limits,
+		started: time.Now(),
+		cancel:  make(chan bool),
+		done:    make(chan bool),
+	}
+}
+
+func (it *limitSupervisor) Start() {
+	go it.background()
+}
+
+// Started applies niceness to started robot process (and so to processes it
+// starts), instead of rcc itself. Safe to use with nil supervisor.
+func (it *limitSupervisor) Started(pid int) {
+	if it == nil || it.limits.Niceness == 0 {
+		return
+	}
+	err := applyNiceness(pid, it.limits.Niceness)
+	if err != nil {
+		common.Log("Could not apply niceness %d, reason: %v", it.limits.Niceness, err)
+	} else {
+		common.Debug("Robot (pid %d) runs with niceness %d.", pid, it.limits.Niceness)
+	}
+}
+
+func (it *limitSupervisor) Stop() {
+	close(it.cancel)
+	<-it.done
+}
+
+func (it *limitSupervisor) Reason() string {
+	it.Lock()
+	defer it.Unlock()
+	return it.reason
+}
+
+func (it *limitSupervisor) exceeded(elapsed time.Duration, rss uint64) string {
+	if it.limits.Timeout > 0 && elapsed > it.limits.Timeout {
+		return fmt.Sprintf("timeout of %s exceeded", it.limits.Timeout)
+	}
+	if it.limits.Memory > 0 && rss > it.limits.Memory*megabyte {
+		return fmt.Sprintf("memory limit of %dM exceeded with %dM", it.limits.Memory, rss/megabyte)
+	}
+	return ""
+}
+
+func descendants(node *ProcessNode, result []int) []int {
+	for _, key := range node.Children.Keys() {
+		child := node.Children[key]
+		result = append(result, child.Pid)
+		result = descendants(child, result)
+	}
+	return result
+}
+
+func residentMemory(pids []int) uint64 {
+	total := uint64(0)
+	for _, pid := range pids {
+		details, ok := processDetails(pid)
+		if ok {
+			total += details.RSS
+		}
+	}
+	return total
+}
+
+func signalAll(pids []int, force bool) {
+	for _, pid := range pids {
+		process, err := os.FindProcess(pid)
+		if err != nil {
+			continue
+		}
+		if force {
+			err = process.Kill()
+		} else {
+			err = process.Signal(syscall.SIGTERM)
+			if err != nil {
+				err = process.Kill()
+			}
+		}
+		common.Trace("Limit signal to pid %d (force: %v), result: %v", pid, force, err)
+	}
+}
+
+func (it *limitSupervisor) check() {
+	processes, err := ProcessMapAll()
+	if err != nil {
+		return
+	}
+	self, ok := processes[os.Getpid()]
+	if !ok {
+		return
+	}
+	pids := descendants(self, []int{})
+	if len(pids) == 0 {
+		return
+	}
+	it.Lock()
+	defer it.Unlock()
+	if len(it.reason) == 0 {
+		it.reason = it.exceeded(time.Since(it.started), residentMemory(pids))
+		if len(it.reason) == 0 {
+			return
+		}
+		it.terminated = time.Now()
+		common.Log("Robot run killed by limit: %s. Terminating %d processes.", it.reason, len(pids))
+		common.RunJournal("limits", "terminate", "robot run %s; terminating processes %v", it.reason, pids)
+		signalAll(pids, false)
+		return
+	}
+	if time.Since(it.terminated) > limitGracePeriod {
+		common.RunJournal("limits", "kill", "processes %v did not stop in %s; killing them", pids, limitGracePeriod)
+		signalAll(pids, true)
+	}
+}
+
+func (it *limitSupervisor) background() {
+	defer close(it.done)
+	for {
+		select {
+		case <-it.cancel:
+			return
+		case <-time.After(limitPollInterval):
+			it.check()
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package operations
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joshyorko/rcc/conda"
+	"github.com/joshyorko/rcc/hamlet"
+	"github.com/joshyorko/rcc/robot"
+)
+
+func TestRunLimitsCombineRobotYamlAndFlags(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	filename := filepath.Join(t.TempDir(), "robot.yaml")
+	content := "tasks:\n  run:\n    shell: python -m robot\nartifactsDir: output\nlimits:\n  timeout: 30m\n  memory: 2048\n  niceness: 5\n"
+	must.Nil(os.WriteFile(filename, []byte(content), 0o644))
+	config, err := robot.LoadRobotYaml(filename, false)
+	must.Nil(err)
+
+	limits, err := EffectiveLimits(nil, config)
+	must.Nil(err)
+	must.True(limits.Active())
+	must.Equal(30*time.Minute, limits.Timeout)
+	must.Equal(uint64(2048), limits.Memory)
+	must.Equal(5, limits.Niceness)
+
+	limits, err = EffectiveLimits(&RunLimits{Timeout: time.Minute}, config)
+	must.Nil(err)
+	must.Equal(time.Minute, limits.Timeout)
+	must.Equal(uint64(2048), limits.Memory)
+
+	limits, err = EffectiveLimits(&RunLimits{}, nil)
+	must.Nil(err)
+	wont.True(limits.Active())
+
+	supervisor := newLimitSupervisor(&RunLimits{Timeout: time.Minute, Memory: 100})
+	must.Equal("", supervisor.exceeded(time.Second, 10*megabyte))
+	wont.Equal("", supervisor.exceeded(2*time.Minute, 10*megabyte))
+	wont.Equal("", supervisor.exceeded(time.Second, 200*megabyte))
+}
+
+func TestLimitSupervisorTerminatesRunawayProcess(t *testing.T) {
+	if conda.IsWindows() {
+		t.Skip("Not a windows test.")
+	}
+	must, wont := hamlet.Specifications(t)
+
+	command := exec.Command("sleep", "30")
+	must.Nil(command.Start())
+	supervisor := newLimitSupervisor(&RunLimits{Timeout: 500 * time.Millisecond})
+	supervisor.Start()
+	started := time.Now()
+	command.Wait()
+	supervisor.Stop()
+	must.True(time.Since(started) < 10*time.Second)
+	wont.Equal("", supervisor.Reason())
+}
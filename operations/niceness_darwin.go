@@ -0,0 +1,9 @@
+package operations
+
+import (
+	"syscall"
+)
+
+func applyNiceness(pid, niceness int) error {
+	return syscall.Setpriority(syscall.PRIO_PROCESS, pid, niceness)
+}
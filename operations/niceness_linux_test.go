@@ -0,0 +1,35 @@
+package operations
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+	"github.com/joshyorko/rcc/shell"
+)
+
+func TestNicenessIsAppliedToStartedRobot(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	before, err := syscall.Getpriority(syscall.PRIO_PROCESS, 0)
+	must.Nil(err)
+	supervisor := newLimitSupervisor(&RunLimits{Niceness: 5})
+	started := 0
+	code, err := shell.New(nil, ".", "sleep", "0.2").WithStarted(func(pid int) {
+		started = pid
+		supervisor.Started(pid)
+		// kernel reports priority as 20-niceness
+		priority, err := syscall.Getpriority(syscall.PRIO_PROCESS, pid)
+		must.Nil(err)
+		must.Equal(15, priority)
+	}).Transparent()
+	must.Nil(err)
+	must.Equal(0, code)
+	must.True(started > 0)
+	after, err := syscall.Getpriority(syscall.PRIO_PROCESS, 0)
+	must.Nil(err)
+	must.Equal(before, after)
+
+	var missing *limitSupervisor
+	missing.Started(started)
+}
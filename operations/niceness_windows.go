@@ -0,0 +1,9 @@
+package operations
+
+import (
+	"fmt"
+)
+
+func applyNiceness(pid, niceness int) error {
+	return fmt.Errorf("niceness %d is not supported on windows", niceness)
+}
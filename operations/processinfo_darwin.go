@@ -0,0 +1,22 @@
+package operations
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// processDetails asks ps for process details, since macOS does not have
+// procfs and libproc would require cgo. Plain exec is used (instead of shell
+// tasks), so that polling does not flood timeline and debug logs.
+func processDetails(pid int) (*ProcessDetails, bool) {
+	output, err := exec.Command("ps", "-o", "rss=,time=,etime=", "-p", fmt.Sprintf("%d", pid)).Output()
+	if err != nil {
+		return nil, false
+	}
+	details, err := parsePsDetails(strings.TrimSpace(string(output)))
+	if err != nil {
+		return nil, false
+	}
+	return details, true
+}
@@ -0,0 +1,70 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	clockTicks = 100
+)
+
+func parseProcStat(content string) (cpu time.Duration, started float64, err error) {
+	closing := strings.LastIndex(content, ")")
+	if closing < 0 {
+		return 0, 0, fmt.Errorf("Malformed process stat line.")
+	}
+	fields := strings.Fields(content[closing+1:])
+	// fields start from state (3rd field in proc(5) documentation)
+	if len(fields) < 20 {
+		return 0, 0, fmt.Errorf("Too few fields in process stat line.")
+	}
+	utime, err := strconv.ParseUint(fields[11], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	stime, err := strconv.ParseUint(fields[12], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	start, err := strconv.ParseUint(fields[19], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	cpu = time.Duration(utime+stime) * time.Second / clockTicks
+	return cpu, float64(start) / clockTicks, nil
+}
+
+func processDetails(pid int) (*ProcessDetails, bool) {
+	stat, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return nil, false
+	}
+	cpu, started, err := parseProcStat(string(stat))
+	if err != nil {
+		return nil, false
+	}
+	result := &ProcessDetails{CPU: cpu}
+	uptime, err := os.ReadFile("/proc/uptime")
+	if err == nil {
+		fields := strings.Fields(string(uptime))
+		seconds, err := strconv.ParseFloat(fields[0], 64)
+		if err == nil && seconds > started {
+			result.Runtime = time.Duration((seconds - started) * float64(time.Second))
+		}
+	}
+	statm, err := os.ReadFile(fmt.Sprintf("/proc/%d/statm", pid))
+	if err == nil {
+		fields := strings.Fields(string(statm))
+		if len(fields) > 1 {
+			pages, err := strconv.ParseUint(fields[1], 10, 64)
+			if err == nil {
+				result.RSS = pages * uint64(os.Getpagesize())
+			}
+		}
+	}
+	return result, true
+}
@@ -0,0 +1,56 @@
+package operations
+
+import (
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var (
+	kernel32                = windows.NewLazySystemDLL("kernel32.dll")
+	getProcessMemoryInfoApi = kernel32.NewProc("K32GetProcessMemoryInfo")
+)
+
+// processMemoryCounters is PROCESS_MEMORY_COUNTERS structure of psapi.
+type processMemoryCounters struct {
+	Cb                         uint32
+	PageFaultCount             uint32
+	PeakWorkingSetSize         uintptr
+	WorkingSetSize             uintptr
+	QuotaPeakPagedPoolUsage    uintptr
+	QuotaPagedPoolUsage        uintptr
+	QuotaPeakNonPagedPoolUsage uintptr
+	QuotaNonPagedPoolUsage     uintptr
+	PagefileUsage              uintptr
+	PeakPagefileUsage          uintptr
+}
+
+func filetimeDuration(filetime windows.Filetime) time.Duration {
+	ticks := uint64(filetime.HighDateTime)<<32 | uint64(filetime.LowDateTime)
+	return time.Duration(ticks * 100)
+}
+
+func processDetails(pid int) (*ProcessDetails, bool) {
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return nil, false
+	}
+	defer windows.CloseHandle(handle)
+	var created, exited, kernel, user windows.Filetime
+	err = windows.GetProcessTimes(handle, &created, &exited, &kernel, &user)
+	if err != nil {
+		return nil, false
+	}
+	result := &ProcessDetails{
+		CPU:     filetimeDuration(kernel) + filetimeDuration(user),
+		Runtime: time.Since(time.Unix(0, created.Nanoseconds())),
+	}
+	counters := processMemoryCounters{}
+	counters.Cb = uint32(unsafe.Sizeof(counters))
+	ok, _, _ := getProcessMemoryInfoApi.Call(uintptr(handle), uintptr(unsafe.Pointer(&counters)), uintptr(counters.Cb))
+	if ok != 0 {
+		result.RSS = uint64(counters.WorkingSetSize)
+	}
+	return result, true
+}
@@ -0,0 +1,184 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/conda"
+)
+
+type ProcessDetails struct {
+	CPU     time.Duration
+	RSS     uint64
+	Runtime time.Duration
+}
+
+type ProcessRow struct {
+	Depth   int
+	Node    *ProcessNode
+	Unknown bool
+	*ProcessDetails
+}
+
+// parsePsDuration parses "[[dd-]hh:]mm:ss[.ss]" durations used by ps.
+func parsePsDuration(text string) (time.Duration, error) {
+	days := 0
+	if at := strings.Index(text, "-"); at > 0 {
+		parsed, err := strconv.Atoi(text[:at])
+		if err != nil {
+			return 0, err
+		}
+		days, text = parsed, text[at+1:]
+	}
+	seconds := 0.0
+	for _, part := range strings.Split(text, ":") {
+		value, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return 0, err
+		}
+		seconds = seconds*60 + value
+	}
+	seconds += float64(days) * 24 * 60 * 60
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// parsePsDetails parses output line of "ps -o rss=,time=,etime=" where
+// resident memory is in kilobytes.
+func parsePsDetails(line string) (*ProcessDetails, error) {
+	fields := strings.Fields(line)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("Expected 3 fields from ps, got %q.", line)
+	}
+	rss, err := strconv.ParseUint(fields[0], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	cpu, err := parsePsDuration(fields[1])
+	if err != nil {
+		return nil, err
+	}
+	runtime, err := parsePsDuration(fields[2])
+	if err != nil {
+		return nil, err
+	}
+	return &ProcessDetails{CPU: cpu, RSS: rss * 1024, Runtime: runtime}, nil
+}
+
+func RobotProcessRoots(processes ProcessMap) []int {
+	self := os.Getpid()
+	result := []int{}
+	for _, pid := range processes.Keys() {
+		node := processes[pid]
+		if pid == self || len(node.Children) == 0 {
+			continue
+		}
+		name := strings.ToLower(filepath.Base(node.Executable))
+		if name == "rcc" || name == "rcc.exe" {
+			result = append(result, pid)
+		}
+	}
+	return result
+}
+
+func (it *ProcessNode) Contains(pid int) bool {
+	if it.Pid == pid {
+		return true
+	}
+	for _, child := range it.Children {
+		if child.Contains(pid) {
+			return true
+		}
+	}
+	return false
+}
+
+func (it *ProcessNode) flatten(depth, limit int, rows []*ProcessRow) []*ProcessRow {
+	details, ok := processDetails(it.Pid)
+	if !ok {
+		details = &ProcessDetails{}
+	}
+	rows = append(rows, &ProcessRow{Depth: depth, Node: it, Unknown: !ok, ProcessDetails: details})
+	if depth >= limit {
+		return rows
+	}
+	for _, key := range it.Children.Keys() {
+		rows = it.Children[key].flatten(depth+1, limit, rows)
+	}
+	return rows
+}
+
+func ProcessTreeRows(processes ProcessMap, roots []int) []*ProcessRow {
+	rows := []*ProcessRow{}
+	for _, pid := range roots {
+		root, ok := processes[pid]
+		if ok {
+			rows = root.flatten(0, 20, rows)
+		}
+	}
+	return rows
+}
+
+func humaneRss(size uint64) string {
+	if size == 0 {
+		return "N/A"
+	}
+	return fmt.Sprintf("%.1fM", float64(size)/(1024.0*1024.0))
+}
+
+func ShowProcessRows(rows []*ProcessRow) {
+	common.Stdout("%-8s  %-8s  %10s  %10s  %10s  %s\n", "PID", "PPID", "CPU", "RSS", "Runtime", "Executable")
+	unknown := 0
+	for _, row := range rows {
+		indent := strings.Repeat("  ", row.Depth)
+		cpu, runtime := "N/A", "N/A"
+		if row.Unknown {
+			unknown += 1
+		} else {
+			cpu = row.CPU.Round(10 * time.Millisecond).String()
+			runtime = row.Runtime.Round(time.Second).String()
+		}
+		common.Stdout("%-8d  %-8d  %10s  %10s  %10s  %s%s\n", row.Node.Pid, row.Node.Parent, cpu, humaneRss(row.RSS), runtime, indent, row.Node.Executable)
+	}
+	if unknown > 0 {
+		common.Stdout("Details of %d processes were not available (process exited, or access was denied).\n", unknown)
+	}
+}
+
+func JournalProcessRows(rows []*ProcessRow) {
+	for _, row := range rows {
+		common.RunJournal("process tree", fmt.Sprintf("depth=%d parent=%d pid=%d name=%s cpu=%s rss=%d runtime=%s", row.Depth, row.Node.Parent, row.Node.Pid, row.Node.Executable, row.CPU, row.RSS, row.Runtime), "process monitor dump")
+	}
+}
+
+func SignalRobotProcess(processes ProcessMap, roots []int, pid int, force bool) error {
+	member := false
+	for _, root := range roots {
+		node, ok := processes[root]
+		if ok && root != pid && node.Contains(pid) {
+			member = true
+		}
+	}
+	if !member {
+		return fmt.Errorf("Process %d is not a subprocess of monitored robot processes %v.", pid, roots)
+	}
+	if !force && conda.IsWindows() {
+		return fmt.Errorf("SIGTERM is not available on Windows, use --kill instead.")
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return err
+	}
+	if force {
+		err = process.Kill()
+	} else {
+		err = process.Signal(syscall.SIGTERM)
+	}
+	common.RunJournal("process signal", fmt.Sprintf("pid=%d name=%s force=%v error=%v", pid, processes[pid].Executable, force, err), "process monitor action")
+	return err
+}
@@ -0,0 +1,56 @@
+package operations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestCanOnlySignalRobotSubprocesses(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	processes := make(ProcessMap)
+	for _, node := range []*ProcessNode{
+		{Pid: 10, Parent: 1, Executable: "rcc"},
+		{Pid: 11, Parent: 10, Executable: "python"},
+		{Pid: 12, Parent: 11, Executable: "chromedriver"},
+		{Pid: 20, Parent: 1, Executable: "bash"},
+	} {
+		node.Children = make(ProcessMap)
+		processes[node.Pid] = node
+	}
+	for pid, node := range processes {
+		parent, ok := processes[node.Parent]
+		if ok {
+			parent.Children[pid] = node
+		}
+	}
+
+	must.True(processes[10].Contains(12))
+	wont.True(processes[10].Contains(20))
+	must.Equal([]int{10}, RobotProcessRoots(processes))
+
+	wont.Nil(SignalRobotProcess(processes, []int{10}, 20, false))
+	wont.Nil(SignalRobotProcess(processes, []int{10}, 10, false))
+	must.Equal(3, len(ProcessTreeRows(processes, []int{10})))
+}
+
+func TestCanParsePsProcessDetails(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	details, err := parsePsDetails("  20480   1:02.50 1-02:03:04\n")
+	must.Nil(err)
+	must.Equal(uint64(20480*1024), details.RSS)
+	must.Equal(62500*time.Millisecond, details.CPU)
+	must.Equal(26*time.Hour+3*time.Minute+4*time.Second, details.Runtime)
+
+	details, err = parsePsDetails("512 0:00.01 00:05")
+	must.Nil(err)
+	must.Equal(5*time.Second, details.Runtime)
+
+	_, err = parsePsDetails("")
+	wont.Nil(err)
+	_, err = parsePsDetails("many 0:00.01 00:05")
+	wont.Nil(err)
+}
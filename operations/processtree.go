@@ -51,6 +51,14 @@ func NewProcessNode(core ps.Process) *ProcessNode {
 }
 
 func ProcessMapNow() (ProcessMap, error) {
+	return processMap(true)
+}
+
+func ProcessMapAll() (ProcessMap, error) {
+	return processMap(false)
+}
+
+func processMap(filtered bool) (ProcessMap, error) {
 	processes, err := ps.Processes()
 	if err != nil {
 		return nil, err
@@ -59,7 +67,7 @@ func ProcessMapNow() (ProcessMap, error) {
 	for _, process := range processes {
 		node := NewProcessNode(process)
 		old, ok := processBlacklist[node.Pid]
-		if ok && old == node.Parent {
+		if filtered && ok && old == node.Parent {
 			continue
 		}
 		node.White = !ok
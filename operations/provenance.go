@@ -0,0 +1,202 @@
+package operations
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/htfs"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/robot"
+)
+
+const (
+	provenanceFile = "provenance.json"
+)
+
+// Provenance ties robot run results into exact rcc, environment, and code
+// versions. It is written as provenance.json into artifact directory.
+type Provenance struct {
+	Rcc         string             `json:"rcc"`
+	Task        string             `json:"task"`
+	Robot       string             `json:"robot"`
+	Environment string             `json:"environment,omitempty"`
+	Space       string             `json:"space,omitempty"`
+	Blueprint   string             `json:"blueprint,omitempty"`
+	Catalog     string             `json:"catalog,omitempty"`
+	Git         *GitProvenance     `json:"git,omitempty"`
+	Machine     *MachineProvenance `json:"machine"`
+	Timings     *TimingProvenance  `json:"timings"`
+	Exitcode    int                `json:"exitcode"`
+	Success     bool               `json:"success"`
+}
+
+type GitProvenance struct {
+	Commit string `json:"commit"`
+	Branch string `json:"branch,omitempty"`
+}
+
+// MachineProvenance uses only identities already present in rcc (symbolic
+// user identity is same as used in journal filenames), no new identifiers.
+type MachineProvenance struct {
+	Platform string `json:"platform"`
+	Cpus     int    `json:"cpus"`
+	Identity string `json:"identity"`
+}
+
+type TimingProvenance struct {
+	Invoked  string  `json:"invoked"`
+	Started  string  `json:"started"`
+	Finished string  `json:"finished"`
+	Setup    float64 `json:"setup_seconds"`
+	Elapsed  float64 `json:"elapsed_seconds"`
+}
+
+func gitDirectory(directory string) (string, bool) {
+	for {
+		candidate := filepath.Join(directory, ".git")
+		if pathlib.IsDir(candidate) {
+			return candidate, true
+		}
+		if pathlib.IsFile(candidate) {
+			content, err := os.ReadFile(candidate)
+			if err != nil {
+				return "", false
+			}
+			gitdir, ok := strings.CutPrefix(strings.TrimSpace(string(content)), "gitdir:")
+			if !ok {
+				return "", false
+			}
+			gitdir = strings.TrimSpace(gitdir)
+			if !filepath.IsAbs(gitdir) {
+				gitdir = filepath.Join(directory, gitdir)
+			}
+			return gitdir, pathlib.IsDir(gitdir)
+		}
+		parent := filepath.Dir(directory)
+		if parent == directory {
+			return "", false
+		}
+		directory = parent
+	}
+}
+
+func packedReference(gitdir, reference string) (string, bool) {
+	handle, err := os.Open(filepath.Join(gitdir, "packed-refs"))
+	if err != nil {
+		return "", false
+	}
+	defer handle.Close()
+	scanner := bufio.NewScanner(handle)
+	for scanner.Scan() {
+		commit, name, ok := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+		if ok && name == reference {
+			return commit, true
+		}
+	}
+	return "", false
+}
+
+// robotGitCommit detects git commit of directory, by reading git metadata
+// directly (no git executable needed).
+func robotGitCommit(directory string) *GitProvenance {
+	gitdir, ok := gitDirectory(directory)
+	if !ok {
+		return nil
+	}
+	content, err := os.ReadFile(filepath.Join(gitdir, "HEAD"))
+	if err != nil {
+		return nil
+	}
+	head := strings.TrimSpace(string(content))
+	reference, ok := strings.CutPrefix(head, "ref:")
+	if !ok {
+		return &GitProvenance{Commit: head}
+	}
+	reference = strings.TrimSpace(reference)
+	result := &GitProvenance{Branch: strings.TrimPrefix(reference, "refs/heads/")}
+	commondir := gitdir
+	if content, err := os.ReadFile(filepath.Join(gitdir, "commondir")); err == nil {
+		commondir = filepath.Join(gitdir, strings.TrimSpace(string(content)))
+	}
+	for _, base := range []string{gitdir, commondir} {
+		content, err = os.ReadFile(filepath.Join(base, filepath.FromSlash(reference)))
+		if err == nil {
+			result.Commit = strings.TrimSpace(string(content))
+			return result
+		}
+		if commit, ok := packedReference(base, reference); ok {
+			result.Commit = commit
+			return result
+		}
+	}
+	return nil
+}
+
+func spaceBlueprint(label string) (string, string) {
+	if len(label) == 0 {
+		return "", ""
+	}
+	shadow, err := htfs.NewRoot(label)
+	if err == nil {
+		err = shadow.LoadFrom(label + ".meta")
+	}
+	if err != nil || len(shadow.Blueprint) == 0 {
+		common.Debug("Could not resolve blueprint of %q, reason: %v", label, err)
+		return "", ""
+	}
+	return shadow.Blueprint, htfs.CatalogName(shadow.Blueprint)
+}
+
+func asAbsolute(filename string) string {
+	if len(filename) == 0 {
+		return filename
+	}
+	fullpath, err := filepath.Abs(filename)
+	if err != nil {
+		return filename
+	}
+	return fullpath
+}
+
+func writeProvenance(flags *RunFlags, config robot.Robot, label string, started time.Time, exitcode int, err error) {
+	finished := time.Now()
+	invoked := time.Unix(common.When, 0)
+	blueprint, catalog := spaceBlueprint(label)
+	provenance := &Provenance{
+		Rcc:         common.Version,
+		Task:        flags.Task,
+		Robot:       asAbsolute(flags.RobotYaml),
+		Environment: asAbsolute(flags.EnvironmentFile),
+		Space:       label,
+		Blueprint:   blueprint,
+		Catalog:     catalog,
+		Git:         robotGitCommit(config.WorkingDirectory()),
+		Machine: &MachineProvenance{
+			Platform: common.Platform(),
+			Cpus:     runtime.NumCPU(),
+			Identity: common.UserHomeIdentity(),
+		},
+		Timings: &TimingProvenance{
+			Invoked:  invoked.Format(time.RFC3339),
+			Started:  started.Format(time.RFC3339),
+			Finished: finished.Format(time.RFC3339),
+			Setup:    started.Sub(invoked).Round(time.Millisecond).Seconds(),
+			Elapsed:  finished.Sub(started).Round(time.Millisecond).Seconds(),
+		},
+		Exitcode: exitcode,
+		Success:  err == nil && exitcode == 0,
+	}
+	blob, problem := json.MarshalIndent(provenance, "", "  ")
+	if problem == nil {
+		problem = os.WriteFile(filepath.Join(config.ArtifactDirectory(), provenanceFile), blob, 0o644)
+	}
+	if problem != nil {
+		common.Debug("Could not write provenance file, reason: %v", problem)
+	}
+}
@@ -0,0 +1,58 @@
+package operations
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func writeGitFixture(t *testing.T, folder, relative, content string) {
+	t.Helper()
+	fullpath := filepath.Join(folder, relative)
+	if err := os.MkdirAll(filepath.Dir(fullpath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fullpath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRobotGitCommitIsDetectedFromMetadata(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	repository := t.TempDir()
+	robotdir := filepath.Join(repository, "robots", "demo")
+	must.Nil(os.MkdirAll(robotdir, 0o755))
+	must.Nil(robotGitCommit(robotdir))
+
+	writeGitFixture(t, repository, ".git/HEAD", "ref: refs/heads/main\n")
+	writeGitFixture(t, repository, ".git/refs/heads/main", "1111111111111111111111111111111111111111\n")
+	found := robotGitCommit(robotdir)
+	must.Equal("1111111111111111111111111111111111111111", found.Commit)
+	must.Equal("main", found.Branch)
+
+	writeGitFixture(t, repository, ".git/HEAD", "ref: refs/heads/packed\n")
+	writeGitFixture(t, repository, ".git/packed-refs", "# pack-refs with: peeled fully-peeled sorted\n2222222222222222222222222222222222222222 refs/heads/packed\n")
+	found = robotGitCommit(robotdir)
+	must.Equal("2222222222222222222222222222222222222222", found.Commit)
+	must.Equal("packed", found.Branch)
+
+	writeGitFixture(t, repository, ".git/HEAD", "3333333333333333333333333333333333333333\n")
+	found = robotGitCommit(robotdir)
+	must.Equal("3333333333333333333333333333333333333333", found.Commit)
+	must.Equal("", found.Branch)
+
+	worktree := t.TempDir()
+	writeGitFixture(t, repository, ".git/worktrees/side/HEAD", "ref: refs/heads/side\n")
+	writeGitFixture(t, repository, ".git/worktrees/side/commondir", "../..\n")
+	writeGitFixture(t, repository, ".git/refs/heads/side", "4444444444444444444444444444444444444444\n")
+	writeGitFixture(t, worktree, ".git", "gitdir: "+filepath.Join(repository, ".git", "worktrees", "side")+"\n")
+	found = robotGitCommit(worktree)
+	must.Equal("4444444444444444444444444444444444444444", found.Commit)
+	must.Equal("side", found.Branch)
+
+	writeGitFixture(t, repository, ".git/HEAD", "ref: refs/heads/missing\n")
+	must.Nil(robotGitCommit(robotdir))
+}
@@ -22,6 +22,8 @@ import (
 
 const (
 	X_RCC_RANDOM_IDENTITY = `X-Rcc-Random-Identity`
+	X_RCC_DOMAIN          = `X-Rcc-Domain`
+	X_RCC_ROBOT_VERSION   = `X-Rcc-Robot-Version`
 	AUTHORIZATION         = "Authorization"
 )
 
@@ -41,6 +43,10 @@ func pullOriginFingerprints(origin, catalogName string) (fingerprints string, co
 	if ok {
 		request.Headers[AUTHORIZATION] = authorization
 	}
+	domain, ok := common.RccRemoteDomain()
+	if ok {
+		request.Headers[X_RCC_DOMAIN] = domain
+	}
 	response := client.Get(request)
 	common.Timeline("status %d from GET %q", response.Status, url)
 
@@ -89,6 +95,10 @@ func downloadMissingEnvironmentParts(count int, origin, catalogName, selection s
 	if ok {
 		request.Header.Add(AUTHORIZATION, authorization)
 	}
+	domain, ok := common.RccRemoteDomain()
+	if ok {
+		request.Header.Add(X_RCC_DOMAIN, domain)
+	}
 
 	response, err := client.Do(request)
 	fail.On(err != nil, "Web request to %q failed, reason: %v", url, err)
@@ -126,6 +136,7 @@ func downloadMissingEnvironmentParts(count int, origin, catalogName, selection s
 
 func ProtectedImport(filename string) (err error) {
 	defer fail.Around(&err)
+	defer pathlib.TrackActivity("import")()
 
 	lockfile := common.HolotreeLock()
 	completed := pathlib.LockWaitMessage(lockfile, "Serialized environment import [holotree lock]")
@@ -134,17 +145,33 @@ func ProtectedImport(filename string) (err error) {
 	fail.On(err != nil, "Could not get lock for holotree. Quiting.")
 	defer locker.Release()
 
+	htfs.RecoverMutations()
+
 	common.Timeline("Import %v", filename)
-	return Unzip(common.HololibLocation(), filename, true, false, false)
+	return journaledImport(filename)
+}
+
+func journaledImport(filename string) (err error) {
+	defer fail.Around(&err)
+
+	sink := newHololibSink(common.HololibLocation(), filename)
+	if pathlib.IsDir(filename) {
+		fail.Fast(acceptDirectory(filename, sink))
+	} else {
+		fail.Fast(acceptZip(filename, sink))
+	}
+	return sink.Commit()
 }
 
 func PullCatalog(origin, catalogName string, useLock bool) (err error) {
 	defer fail.Around(&err)
+	defer pathlib.TrackActivity("pull")()
 
 	common.TimelineBegin("hololib+catalog pull start")
 	defer common.TimelineEnd()
 
 	common.Timeline("pulling %q parts from %q", catalogName, origin)
+	fail.On(settings.Global.Offline(), "%v", settings.OfflineError(fmt.Sprintf("pull %q from %q", catalogName, origin)))
 
 	unknownSelected, count, err := pullOriginFingerprints(origin, catalogName)
 	fail.On(err != nil, "%v", err)
@@ -158,7 +185,7 @@ func PullCatalog(origin, catalogName string, useLock bool) (err error) {
 	if useLock {
 		err = ProtectedImport(filename)
 	} else {
-		err = Unzip(common.HololibLocation(), filename, true, false, false)
+		err = journaledImport(filename)
 	}
 	fail.On(err != nil, "Failed to unzip %v to hololib, reason: %v", filename, err)
 
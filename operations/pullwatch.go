@@ -0,0 +1,92 @@
+package operations
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joshyorko/rcc/cloud"
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/pretty"
+)
+
+const (
+	watchRetryDelay = 30 * time.Second
+)
+
+// CatalogUpdates long-polls rccremote origin for catalogs that are new or
+// updated after cursor, waiting at most given time. Returns new cursor and
+// changed catalog names (which are empty, when wait time passed). When full
+// is true, catalogs is listing of all catalogs in origin, not just changes.
+func CatalogUpdates(origin string, cursor uint64, wait time.Duration) (next uint64, catalogs []string, full bool, err error) {
+	client, err := cloud.NewUnsafeClient(origin)
+	if err != nil {
+		return cursor, nil, false, fmt.Errorf("Could not create web client for %q, reason: %v", origin, err)
+	}
+	client = client.WithTimeout(wait + time.Minute)
+	request := client.NewRequest(fmt.Sprintf("/watch/?since=%d&wait=%d", cursor, int(wait.Seconds())))
+	remoteHeaders(request)
+	response := client.Get(request)
+	if response.Err != nil {
+		return cursor, nil, false, response.Err
+	}
+	if response.Status != 200 {
+		return cursor, nil, false, fmt.Errorf("Problem with watch request, status=%d, body=%s", response.Status, response.Body)
+	}
+	lines := strings.Split(strings.TrimSpace(string(response.Body)), "\n")
+	header := strings.Fields(lines[0])
+	if len(header) > 0 {
+		next, err = strconv.ParseUint(header[0], 10, 64)
+	}
+	if len(header) == 0 || err != nil {
+		return cursor, nil, false, fmt.Errorf("Invalid watch reply from %q, reason: %v", origin, err)
+	}
+	full = len(header) > 1 && header[1] == "full"
+	catalogs = []string{}
+	for _, line := range lines[1:] {
+		catalog := strings.TrimSpace(line)
+		if len(catalog) > 0 {
+			catalogs = append(catalogs, catalog)
+		}
+	}
+	return next, catalogs, full, nil
+}
+
+func isPlatformCatalog(catalog string) bool {
+	return strings.HasSuffix(catalog, "."+common.Platform())
+}
+
+// WatchCatalogs keeps this machine in sync with rccremote origin, by pulling
+// every new or updated catalog (of this platform) that origin announces.
+// From full listings (first round, or after origin restart), only catalogs
+// missing from local hololib are pulled. Watching continues until process
+// is stopped.
+func WatchCatalogs(origin string, wait time.Duration) {
+	cursor := uint64(0)
+	for {
+		next, catalogs, full, err := CatalogUpdates(origin, cursor, wait)
+		if err != nil {
+			pretty.Warning("Watching %q failed, retrying in %s, reason: %v", origin, watchRetryDelay, err)
+			time.Sleep(watchRetryDelay)
+			continue
+		}
+		for _, catalog := range catalogs {
+			if !isPlatformCatalog(catalog) {
+				continue
+			}
+			present := pathlib.IsFile(filepath.Join(common.HololibCatalogLocation(), catalog))
+			if present && full {
+				continue
+			}
+			common.Log("Pulling new or updated catalog %q from %q.", catalog, origin)
+			err = PullCatalog(origin, catalog, true)
+			if err != nil {
+				pretty.Warning("Failed to pull %q from %q, reason: %v", catalog, origin, err)
+			}
+		}
+		cursor = next
+	}
+}
@@ -0,0 +1,116 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/joshyorko/rcc/shell"
+
+	"gopkg.in/yaml.v2"
+)
+
+// QuickAction is user defined shortcut for frequent rcc operation, like
+// "rebuild env for robot X" or "clean idle spaces".
+type QuickAction struct {
+	Key     string `yaml:"key"`
+	Name    string `yaml:"name"`
+	Command string `yaml:"command"`
+}
+
+type quickActions struct {
+	Actions []*QuickAction `yaml:"actions"`
+}
+
+func (it *QuickAction) Arguments() ([]string, error) {
+	arguments, err := shell.Split(it.Command)
+	if err != nil {
+		return nil, err
+	}
+	if len(arguments) > 0 && (arguments[0] == "rcc" || arguments[0] == common.Product.Name()) {
+		arguments = arguments[1:]
+	}
+	if len(arguments) == 0 {
+		return nil, fmt.Errorf("Quick action %q has no command.", it.Name)
+	}
+	return arguments, nil
+}
+
+func quickActionsFrom(content []byte) ([]*QuickAction, error) {
+	result := new(quickActions)
+	err := yaml.Unmarshal(content, result)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for at, action := range result.Actions {
+		action.Key = strings.TrimSpace(action.Key)
+		if len([]rune(action.Key)) != 1 {
+			return nil, fmt.Errorf("Quick action #%d (%s) must have single character key, not %q.", at+1, action.Name, action.Key)
+		}
+		if seen[action.Key] {
+			return nil, fmt.Errorf("Quick action key %q is used more than once.", action.Key)
+		}
+		seen[action.Key] = true
+		if len(action.Name) == 0 {
+			action.Name = action.Command
+		}
+		_, err := action.Arguments()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result.Actions, nil
+}
+
+// LoadQuickActions reads user defined quick actions from quickactions.yaml
+// in ROBOCORP_HOME. Missing file means no quick actions.
+func LoadQuickActions() ([]*QuickAction, error) {
+	content, err := os.ReadFile(common.QuickActionsLocation())
+	if os.IsNotExist(err) {
+		return []*QuickAction{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return quickActionsFrom(content)
+}
+
+func FindQuickAction(actions []*QuickAction, key string) (*QuickAction, bool) {
+	for _, action := range actions {
+		if action.Key == key {
+			return action, true
+		}
+	}
+	return nil, false
+}
+
+func ShowQuickActions(actions []*QuickAction) {
+	if len(actions) == 0 {
+		common.Stdout("%s%-10s%s %sno quick actions defined, see %s%s\n\n", pretty.White, "ACTIONS", pretty.Reset, pretty.Grey, common.QuickActionsLocation(), pretty.Reset)
+		return
+	}
+	entries := make([]string, 0, len(actions))
+	for _, action := range actions {
+		entries = append(entries, fmt.Sprintf("%s[%s]%s %s", pretty.Cyan, action.Key, pretty.Reset, action.Name))
+	}
+	common.Stdout("%s%-10s%s %s\n", pretty.White, "ACTIONS", pretty.Reset, strings.Join(entries, "  "))
+	common.Stdout("%-10s %s-> rcc interactive actions [key]%s\n\n", "", pretty.Grey, pretty.Reset)
+}
+
+// RunQuickAction runs mapped rcc command as subprocess of current executable.
+func RunQuickAction(action *QuickAction) (int, error) {
+	arguments, err := action.Arguments()
+	if err != nil {
+		return -1, err
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return -1, err
+	}
+	common.RunJournal("quick action", action.Key, "%s: %s", action.Name, action.Command)
+	task := append([]string{executable}, arguments...)
+	return shell.New(nil, ".", task...).Execute(true)
+}
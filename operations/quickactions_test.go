@@ -0,0 +1,43 @@
+package operations
+
+import (
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestQuickActionsAreParsedAndValidated(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	t.Setenv("ROBOCORP_HOME", t.TempDir())
+	actions, err := LoadQuickActions()
+	must.Nil(err)
+	must.Equal(0, len(actions))
+
+	actions, err = quickActionsFrom([]byte(`actions:
+  - key: r
+    name: rebuild env for robot X
+    command: rcc holotree vars --robot "/path with space/robot.yaml" --force
+  - key: c
+    command: holotree delete --unused 30
+`))
+	must.Nil(err)
+	must.Equal(2, len(actions))
+	arguments, err := actions[0].Arguments()
+	must.Nil(err)
+	must.Equal([]string{"holotree", "vars", "--robot", "/path with space/robot.yaml", "--force"}, arguments)
+	must.Equal("holotree delete --unused 30", actions[1].Name)
+
+	found, ok := FindQuickAction(actions, "c")
+	must.True(ok)
+	must.Equal("holotree delete --unused 30", found.Command)
+	_, ok = FindQuickAction(actions, "x")
+	wont.True(ok)
+
+	_, err = quickActionsFrom([]byte("actions:\n  - key: rr\n    command: holotree list\n"))
+	wont.Nil(err)
+	_, err = quickActionsFrom([]byte("actions:\n  - key: r\n    command: holotree list\n  - key: r\n    command: holotree catalogs\n"))
+	wont.Nil(err)
+	_, err = quickActionsFrom([]byte("actions:\n  - key: r\n    command: rcc\n"))
+	wont.Nil(err)
+}
@@ -38,7 +38,7 @@ func rccVersionsJson() string {
 func updateRccVersionInfo() (err error) {
 	defer fail.Around(&err)
 
-	if !needNewRccInfo() {
+	if !needNewRccInfo() || settings.Global.Offline() {
 		return nil
 	}
 	return downloadVersionsJson()
@@ -0,0 +1,168 @@
+package operations
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joshyorko/rcc/cloud"
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/htfs"
+	"github.com/joshyorko/rcc/settings"
+)
+
+const (
+	registryTimeout = 15 * time.Second
+)
+
+// RegistryEntry is one catalog of blueprint known by registry source. Source
+// is rccremote where catalog can be pulled from, and latency is measured to
+// that source (zero when it could not be reached).
+type RegistryEntry struct {
+	Catalog  string        `json:"catalog"`
+	Platform string        `json:"platform"`
+	Source   string        `json:"source"`
+	Latency  time.Duration `json:"latency"`
+}
+
+func remoteHeaders(request *cloud.Request) {
+	request.Headers[X_RCC_RANDOM_IDENTITY] = common.RandomIdentifier()
+	authorization, ok := common.RccRemoteAuthorization()
+	if ok {
+		request.Headers[AUTHORIZATION] = authorization
+	}
+	domain, ok := common.RccRemoteDomain()
+	if ok {
+		request.Headers[X_RCC_DOMAIN] = domain
+	}
+}
+
+// RegistrySources lists where blueprint catalogs are queried from: given
+// rccremote origin, and registry endpoint from settings (or from
+// RCC_ENDPOINT_REGISTRY).
+func RegistrySources(origin string) []string {
+	result := []string{}
+	for _, candidate := range []string{origin, settings.Global.Endpoint("registry")} {
+		source := strings.TrimRight(strings.TrimSpace(candidate), "/")
+		if len(source) > 0 && !slices.Contains(result, source) {
+			result = append(result, source)
+		}
+	}
+	return result
+}
+
+// queryRegistry asks catalogs of blueprint from one source. Reply has one
+// catalog per line, optionally followed by source having it (central
+// registry); without source, catalog is in queried source itself.
+func queryRegistry(source, blueprint string) ([]*RegistryEntry, error) {
+	client, err := cloud.NewUnsafeClient(source)
+	if err != nil {
+		return nil, fmt.Errorf("Could not create web client for %q, reason: %v", source, err)
+	}
+	client = client.WithTimeout(registryTimeout)
+	request := client.NewRequest(fmt.Sprintf("/registry/%s", blueprint))
+	remoteHeaders(request)
+	response := client.Get(request)
+	if response.Err != nil {
+		return nil, response.Err
+	}
+	if response.Status != 200 {
+		return nil, fmt.Errorf("Registry query to %q failed, status=%d, body=%s", source, response.Status, response.Body)
+	}
+	result := []*RegistryEntry{}
+	prefix := htfs.CatalogPrefix(blueprint)
+	for _, line := range strings.Split(string(response.Body), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || !strings.HasPrefix(fields[0], prefix) {
+			continue
+		}
+		entry := &RegistryEntry{
+			Catalog:  fields[0],
+			Platform: strings.TrimPrefix(fields[0], prefix),
+			Source:   source,
+		}
+		if len(fields) > 1 {
+			entry.Source = strings.TrimRight(fields[1], "/")
+		}
+		result = append(result, entry)
+	}
+	return result, nil
+}
+
+// probeLatency measures round trip to health endpoint of rccremote.
+func probeLatency(source string) (time.Duration, bool) {
+	client, err := cloud.NewUnsafeClient(source)
+	if err != nil {
+		return 0, false
+	}
+	started := time.Now()
+	response := client.WithTimeout(registryTimeout).Get(client.NewRequest("/healthz"))
+	if response.Err != nil || response.Status != 200 {
+		return 0, false
+	}
+	return max(time.Since(started), time.Microsecond), true
+}
+
+// QueryRegistry asks all sources which catalogs exist for blueprint across
+// the fleet. Sources having catalog for this platform are probed for latency,
+// and result is sorted so that nearest usable source comes first. Problems
+// with individual sources are returned as warnings.
+func QueryRegistry(sources []string, blueprint string) ([]*RegistryEntry, []error) {
+	result := []*RegistryEntry{}
+	warnings := []error{}
+	seen := make(map[string]bool)
+	for _, source := range sources {
+		entries, err := queryRegistry(source, blueprint)
+		if err != nil {
+			warnings = append(warnings, err)
+			continue
+		}
+		for _, entry := range entries {
+			key := entry.Catalog + " " + entry.Source
+			if !seen[key] {
+				seen[key] = true
+				result = append(result, entry)
+			}
+		}
+	}
+	latencies := make(map[string]time.Duration)
+	for _, entry := range result {
+		if entry.Platform != common.Platform() {
+			continue
+		}
+		latency, ok := latencies[entry.Source]
+		if !ok {
+			latency, _ = probeLatency(entry.Source)
+			latencies[entry.Source] = latency
+		}
+		entry.Latency = latency
+	}
+	sort.SliceStable(result, func(left, right int) bool {
+		first, second := result[left], result[right]
+		if first.usable() != second.usable() {
+			return first.usable()
+		}
+		if first.usable() {
+			return first.Latency < second.Latency
+		}
+		return first.Platform < second.Platform
+	})
+	return result, warnings
+}
+
+func (it *RegistryEntry) usable() bool {
+	return it.Platform == common.Platform() && it.Latency > 0
+}
+
+// NearestSource gives reachable registry entry for this platform with lowest
+// latency, from result of QueryRegistry.
+func NearestSource(entries []*RegistryEntry) (*RegistryEntry, bool) {
+	for _, entry := range entries {
+		if entry.usable() {
+			return entry, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,187 @@
+package operations
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joshyorko/rcc/cloud"
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/htfs"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/pretty"
+)
+
+const (
+	listingWait = time.Second
+)
+
+// RemoteCatalog is catalog of this platform in rccremote listing, with
+// estimate of how much would be downloaded to pull it (blobs missing from
+// local hololib). Estimated is false, when origin could not tell sizes.
+type RemoteCatalog struct {
+	Name      string
+	Present   bool
+	Blobs     int
+	Missing   int
+	Download  uint64
+	Estimated bool
+}
+
+// RemoteCatalogs lists catalogs of this platform in rccremote origin (limited
+// to RCC_REMOTE_DOMAIN, when set), and tells which ones are already present
+// in local hololib.
+func RemoteCatalogs(origin string) ([]*RemoteCatalog, error) {
+	_, catalogs, _, err := CatalogUpdates(origin, 0, listingWait)
+	if err != nil {
+		return nil, err
+	}
+	result := []*RemoteCatalog{}
+	for _, catalog := range catalogs {
+		if !isPlatformCatalog(catalog) {
+			continue
+		}
+		result = append(result, &RemoteCatalog{
+			Name:    catalog,
+			Present: pathlib.IsFile(filepath.Join(common.HololibCatalogLocation(), catalog)),
+		})
+	}
+	return result, nil
+}
+
+// Estimate asks blob sizes of catalog from origin, and sums sizes of those
+// blobs that are missing from local hololib.
+func (it *RemoteCatalog) Estimate(origin string) error {
+	client, err := cloud.NewUnsafeClient(origin)
+	if err != nil {
+		return fmt.Errorf("Could not create web client for %q, reason: %v", origin, err)
+	}
+	request := client.NewRequest(fmt.Sprintf("/sizes/%s", it.Name))
+	remoteHeaders(request)
+	response := client.Get(request)
+	if response.Err != nil {
+		return response.Err
+	}
+	if response.Status != 200 {
+		return fmt.Errorf("Problem with sizes request, status=%d, body=%s", response.Status, response.Body)
+	}
+	it.Blobs, it.Missing, it.Download = 0, 0, 0
+	for _, line := range strings.Split(string(response.Body), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		it.Blobs++
+		if pathlib.IsFile(htfs.ExactDefaultLocation(fields[0])) {
+			continue
+		}
+		size, err := strconv.ParseUint(fields[1], 10, 64)
+		if err == nil {
+			it.Missing++
+			it.Download += size
+		}
+	}
+	it.Estimated = true
+	return nil
+}
+
+// EstimateCatalogs estimates download sizes of catalogs. Failures (like
+// older rccremote without sizes support) leave catalog without estimate.
+func EstimateCatalogs(origin string, catalogs []*RemoteCatalog) {
+	for _, catalog := range catalogs {
+		err := catalog.Estimate(origin)
+		if err != nil {
+			common.Debug("Could not estimate size of %q, reason: %v", catalog.Name, err)
+		}
+	}
+}
+
+// Size gives estimated download size in human readable form.
+func (it *RemoteCatalog) Size() string {
+	if !it.Estimated {
+		return "?"
+	}
+	return humaneBytes(it.Download)
+}
+
+// RemoteCatalogTable gives remote catalog listing as table.
+func RemoteCatalogTable(catalogs []*RemoteCatalog) *pretty.Table {
+	table := pretty.NewTable(
+		&pretty.TableColumn{Name: "catalog", Title: "Catalog"},
+		&pretty.TableColumn{Name: "status", Title: "Status"},
+		&pretty.TableColumn{Name: "missing", Title: "Missing blobs"},
+		&pretty.TableColumn{Name: "download", Title: "Download"},
+	)
+	for _, catalog := range catalogs {
+		table.Add(pretty.TableRow{
+			"catalog":  catalog.Name,
+			"status":   catalog.Status(),
+			"missing":  fmt.Sprintf("%d/%d", catalog.Missing, catalog.Blobs),
+			"download": catalog.Size(),
+		})
+	}
+	return table
+}
+
+// Status tells if catalog is already in local hololib.
+func (it *RemoteCatalog) Status() string {
+	if it.Present {
+		return "present"
+	}
+	return "missing"
+}
+
+// ShowRemoteCatalogs shows numbered listing of remote catalogs, with marks
+// on selected ones.
+func ShowRemoteCatalogs(origin string, catalogs []*RemoteCatalog, selected map[string]bool) {
+	common.Stdout("%s%-10s%s %s\n\n", pretty.White, "REMOTE", pretty.Reset, origin)
+	if len(catalogs) == 0 {
+		common.Stdout("  %sno catalogs for %s%s\n\n", pretty.Grey, common.Platform(), pretty.Reset)
+		return
+	}
+	total := uint64(0)
+	for at, catalog := range catalogs {
+		mark := " "
+		if selected[catalog.Name] {
+			mark = "*"
+			total += catalog.Download
+		}
+		status := pretty.Yellow + catalog.Status() + pretty.Reset
+		if catalog.Present {
+			status = pretty.Green + catalog.Status() + pretty.Reset
+		}
+		common.Stdout("  %s%3d%s %s %-40s %s %8s %s(%d/%d blobs missing)%s\n", pretty.Cyan, at+1, pretty.Reset, mark, catalog.Name, status, catalog.Size(), pretty.Grey, catalog.Missing, catalog.Blobs, pretty.Reset)
+	}
+	common.Stdout("\n  %d selected, estimated download %s\n\n", len(selected), humaneBytes(total))
+}
+
+// PullSelectedCatalogs pulls given catalogs from origin one by one, and
+// reports aggregate progress against estimated total download size.
+// Failures are reported and pulling continues with next catalog.
+func PullSelectedCatalogs(origin string, catalogs []*RemoteCatalog) (int, error) {
+	total := uint64(0)
+	for _, catalog := range catalogs {
+		total += catalog.Download
+	}
+	done, pulled := uint64(0), 0
+	var failure error
+	for at, catalog := range catalogs {
+		common.Log("Pulling %d/%d %q (estimated %s, %s of %s done) ...", at+1, len(catalogs), catalog.Name, catalog.Size(), humaneBytes(done), humaneBytes(total))
+		err := PullCatalog(origin, catalog.Name, true)
+		if err != nil {
+			pretty.Warning("Failed to pull %q from %q, reason: %v", catalog.Name, origin, err)
+			failure = err
+			continue
+		}
+		done += catalog.Download
+		pulled++
+		catalog.Present = true
+	}
+	common.Log("Pulled %d/%d catalogs (%s) from %q.", pulled, len(catalogs), humaneBytes(done), origin)
+	if failure != nil {
+		return pulled, fmt.Errorf("%d of %d catalogs failed to pull, last reason: %v", len(catalogs)-pulled, len(catalogs), failure)
+	}
+	return pulled, nil
+}
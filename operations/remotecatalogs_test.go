@@ -0,0 +1,53 @@
+package operations
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestRemoteCatalogsAreListedWithDownloadEstimates(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	local := fmt.Sprintf("0123456789abcdefv12.%s", common.Platform())
+	foreign := "fedcba9876543210v12.windows_amd64"
+	server := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		switch {
+		case request.URL.Path == "/watch/":
+			fmt.Fprintf(response, "7 full\n%s\n%s\n", local, foreign)
+		case strings.HasPrefix(request.URL.Path, "/sizes/"+local):
+			fmt.Fprintf(response, "%032x 1024\n%032x 2048\n", 1, 2)
+		default:
+			http.NotFound(response, request)
+		}
+	}))
+	defer server.Close()
+
+	catalogs, err := RemoteCatalogs(server.URL)
+	must.Nil(err)
+	must.Equal(1, len(catalogs))
+	must.Equal(local, catalogs[0].Name)
+	wont.True(catalogs[0].Present)
+	must.Equal("missing", catalogs[0].Status())
+	must.Equal("?", catalogs[0].Size())
+
+	EstimateCatalogs(server.URL, catalogs)
+	must.True(catalogs[0].Estimated)
+	must.Equal(2, catalogs[0].Blobs)
+	must.Equal(2, catalogs[0].Missing)
+	must.Equal(uint64(3072), catalogs[0].Download)
+	must.Equal("3.0K", catalogs[0].Size())
+
+	unknown := &RemoteCatalog{Name: foreign}
+	wont.Nil(unknown.Estimate(server.URL))
+	wont.True(unknown.Estimated)
+
+	table := RemoteCatalogTable(catalogs)
+	must.Equal(1, len(table.Rows))
+	must.Equal("2/2", table.Rows[0]["missing"])
+}
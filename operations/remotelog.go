@@ -0,0 +1,136 @@
+package operations
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pretty"
+)
+
+var (
+	remoteLogErrors   = []string{"error", "fail", "denied", "fatal", "panic"}
+	remoteLogWarnings = []string{"warning", "stale", "timeout", "draining"}
+)
+
+// TailLog gives last count lines of log file (all lines, if count is less
+// than one), and offset where following should continue from.
+func TailLog(filename string, count int) ([]string, int64, error) {
+	source, err := os.Open(filename)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer source.Close()
+	lines := []string{}
+	scanner := bufio.NewScanner(source)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+		if count > 0 && len(lines) > count {
+			lines = lines[1:]
+		}
+	}
+	if scanner.Err() != nil {
+		return nil, 0, scanner.Err()
+	}
+	offset, err := source.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, 0, err
+	}
+	return lines, offset, nil
+}
+
+func containsAny(line string, fragments []string) bool {
+	lower := strings.ToLower(line)
+	for _, fragment := range fragments {
+		if strings.Contains(lower, fragment) {
+			return true
+		}
+	}
+	return false
+}
+
+func logLineColor(line string) string {
+	switch {
+	case containsAny(line, remoteLogErrors):
+		return pretty.Red
+	case containsAny(line, remoteLogWarnings):
+		return pretty.Yellow
+	}
+	return pretty.White
+}
+
+// ShowLogLines shows log lines, with errors and warnings highlighted.
+func ShowLogLines(lines []string) {
+	for _, line := range lines {
+		common.Stdout("%s%s%s\n", logLineColor(line), line, pretty.Reset)
+	}
+}
+
+// ShowRemoteLog shows header and last lines of rccremote log.
+func ShowRemoteLog(filename string, lines []string) {
+	updated := "never"
+	stat, err := os.Stat(filename)
+	if err == nil {
+		updated = stat.ModTime().Format(time.DateTime)
+	}
+	common.Stdout("%s%-10s%s %s  %supdated: %s%s\n\n", pretty.White, "RCCREMOTE", pretty.Reset, filename, pretty.Grey, updated, pretty.Reset)
+	if len(lines) == 0 {
+		common.Stdout("  %sno log lines yet%s\n", pretty.Grey, pretty.Reset)
+	}
+	ShowLogLines(lines)
+	common.Stdout("\n")
+}
+
+// completeLines splits content into lines, leaving out last line, if it is
+// not yet complete, and tells how many bytes were consumed.
+func completeLines(content []byte) ([]string, int) {
+	consumed := strings.LastIndexByte(string(content), '\n') + 1
+	if consumed == 0 {
+		return []string{}, 0
+	}
+	return strings.Split(string(content[:consumed-1]), "\n"), consumed
+}
+
+// FollowLog shows new lines appended into log file after offset, checking
+// it every interval, until interrupted. If log file is truncated or rotated
+// (server restarted with new log, or log grew over its size limit),
+// following continues from beginning of new file.
+func FollowLog(filename string, offset int64, interval time.Duration) error {
+	for {
+		time.Sleep(interval)
+		stat, err := os.Stat(filename)
+		if os.IsNotExist(err) {
+			offset = 0
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if stat.Size() < offset {
+			offset = 0
+		}
+		if stat.Size() == offset {
+			continue
+		}
+		source, err := os.Open(filename)
+		if err != nil {
+			return err
+		}
+		_, err = source.Seek(offset, io.SeekStart)
+		if err == nil {
+			var content []byte
+			content, err = io.ReadAll(source)
+			lines, consumed := completeLines(content)
+			ShowLogLines(lines)
+			offset += int64(consumed)
+		}
+		source.Close()
+		if err != nil {
+			return err
+		}
+	}
+}
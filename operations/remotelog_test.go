@@ -0,0 +1,50 @@
+package operations
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+	"github.com/joshyorko/rcc/pretty"
+)
+
+func TestCanTailRemoteLog(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	filename := filepath.Join(t.TempDir(), "rccremote.log")
+	_, _, err := TailLog(filename, 5)
+	wont.Nil(err)
+
+	content := "first\nsecond\nthird\nfourth\n"
+	must.Nil(os.WriteFile(filename, []byte(content), 0o644))
+	lines, offset, err := TailLog(filename, 2)
+	must.Nil(err)
+	must.Equal([]string{"third", "fourth"}, lines)
+	must.Equal(int64(len(content)), offset)
+
+	lines, _, err = TailLog(filename, 0)
+	must.Nil(err)
+	must.Equal(4, len(lines))
+}
+
+func TestFollowedLogKeepsIncompleteLineForLater(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	lines, consumed := completeLines([]byte("partial"))
+	must.Equal(0, len(lines))
+	must.Equal(0, consumed)
+
+	lines, consumed = completeLines([]byte("one\ntwo\npart"))
+	must.Equal([]string{"one", "two"}, lines)
+	must.Equal(8, consumed)
+}
+
+func TestRemoteLogErrorsAreHighlighted(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	must.Equal(pretty.Red, logLineColor("Access denied: HTTP/1.1 GET \"/parts/\""))
+	must.Equal(pretty.Red, logLineColor("Remote for rcc failed, reason: bind"))
+	must.Equal(pretty.Yellow, logLineColor("Draining 2 in-flight request(s)"))
+	must.Equal(pretty.White, logLineColor("Remote for rcc starting (v18) ..."))
+}
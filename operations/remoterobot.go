@@ -0,0 +1,111 @@
+package operations
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/settings"
+)
+
+const (
+	RemoteRobotScheme = `rcc-remote://`
+)
+
+// RemoteRobot is robot package reference in form of
+// "rcc-remote://<domain>/<name>[/<version>]", served by rccremote at
+// RCC_REMOTE_ORIGIN. Empty domain means default domain of server, and empty
+// version means newest version.
+type RemoteRobot struct {
+	Domain  string
+	Name    string
+	Version string
+}
+
+// IsRemoteRobot tells if link refers to robot package in rccremote.
+func IsRemoteRobot(link string) bool {
+	return strings.HasPrefix(link, RemoteRobotScheme)
+}
+
+// ParseRemoteRobot parses "rcc-remote://<domain>/<name>[/<version>]" link.
+func ParseRemoteRobot(link string) (*RemoteRobot, error) {
+	rest, ok := strings.CutPrefix(link, RemoteRobotScheme)
+	if !ok {
+		return nil, fmt.Errorf("Robot reference %q does not start with %q.", link, RemoteRobotScheme)
+	}
+	parts := strings.Split(strings.TrimRight(rest, "/"), "/")
+	if len(parts) < 2 || len(parts) > 3 || len(parts[1]) == 0 {
+		return nil, fmt.Errorf("Robot reference %q should be in form %s<domain>/<name>[/<version>].", link, RemoteRobotScheme)
+	}
+	result := &RemoteRobot{Domain: parts[0], Name: parts[1]}
+	if len(parts) == 3 {
+		result.Version = parts[2]
+	}
+	return result, nil
+}
+
+func (it *RemoteRobot) String() string {
+	if len(it.Version) == 0 {
+		return fmt.Sprintf("%s%s/%s", RemoteRobotScheme, it.Domain, it.Name)
+	}
+	return fmt.Sprintf("%s%s/%s/%s", RemoteRobotScheme, it.Domain, it.Name, it.Version)
+}
+
+// DownloadRemoteRobot downloads robot package from rccremote at origin into
+// filename, and gives version that server sent.
+func DownloadRemoteRobot(origin string, robot *RemoteRobot, filename string) (string, error) {
+	origin = strings.TrimRight(strings.TrimSpace(origin), "/")
+	if len(origin) == 0 {
+		return "", fmt.Errorf("Pulling %s needs rccremote address in %s environment variable.", robot, common.RCC_REMOTE_ORIGIN)
+	}
+	if settings.Global.Offline() {
+		return "", settings.OfflineError(fmt.Sprintf("pull %s from %q", robot, origin))
+	}
+	link := fmt.Sprintf("%s/robots/%s", origin, url.PathEscape(robot.Name))
+	if len(robot.Version) > 0 {
+		link = fmt.Sprintf("%s/%s", link, url.PathEscape(robot.Version))
+	}
+	request, err := http.NewRequest(http.MethodGet, link, nil)
+	if err != nil {
+		return "", err
+	}
+	request.Header.Add("User-Agent", common.UserAgent())
+	request.Header.Add(X_RCC_RANDOM_IDENTITY, common.RandomIdentifier())
+	authorization, ok := common.RccRemoteAuthorization()
+	if ok {
+		request.Header.Add(AUTHORIZATION, authorization)
+	}
+	domain, ok := common.RccRemoteDomain()
+	if len(robot.Domain) > 0 {
+		domain, ok = robot.Domain, true
+	}
+	if ok {
+		request.Header.Add(X_RCC_DOMAIN, domain)
+	}
+	client := &http.Client{Transport: settings.Global.ConfiguredHttpTransport()}
+	response, err := client.Do(request)
+	if err != nil {
+		return "", fmt.Errorf("Web request to %q failed, reason: %v", link, err)
+	}
+	defer response.Body.Close()
+	common.Timeline("status %d from GET %q", response.StatusCode, link)
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Could not pull %s, %s (%s)", robot, response.Status, link)
+	}
+	sink, err := pathlib.Create(filename)
+	if err != nil {
+		return "", err
+	}
+	defer sink.Close()
+	_, err = io.Copy(sink, response.Body)
+	if err != nil {
+		os.Remove(filename)
+		return "", fmt.Errorf("Download of %s failed, reason: %v", robot, err)
+	}
+	return response.Header.Get(X_RCC_ROBOT_VERSION), nil
+}
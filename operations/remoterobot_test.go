@@ -0,0 +1,68 @@
+package operations
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestCanParseRemoteRobotReferences(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	must.True(IsRemoteRobot("rcc-remote://team-a/invoice-bot"))
+	wont.True(IsRemoteRobot("github.com/robocorp/template"))
+
+	robot, err := ParseRemoteRobot("rcc-remote://team-a/invoice-bot")
+	must.Nil(err)
+	must.Equal(&RemoteRobot{Domain: "team-a", Name: "invoice-bot"}, robot)
+	must.Equal("rcc-remote://team-a/invoice-bot", robot.String())
+
+	robot, err = ParseRemoteRobot("rcc-remote:///invoice-bot/1.2.0/")
+	must.Nil(err)
+	must.Equal(&RemoteRobot{Name: "invoice-bot", Version: "1.2.0"}, robot)
+
+	for _, broken := range []string{"rcc-remote://team-a", "rcc-remote://team-a/", "rcc-remote://a/b/c/d", "https://team-a/bot"} {
+		_, err = ParseRemoteRobot(broken)
+		wont.Nil(err)
+	}
+}
+
+func TestRemoteRobotIsDownloadedFromDomain(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	t.Setenv("RCC_REMOTE_AUTHORIZATION", "secret")
+	seen := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		seen["path"] = request.URL.Path
+		seen["domain"] = request.Header.Get(X_RCC_DOMAIN)
+		seen["token"] = request.Header.Get(AUTHORIZATION)
+		if request.URL.Path != "/robots/invoice-bot" {
+			http.NotFound(response, request)
+			return
+		}
+		response.Header().Set(X_RCC_ROBOT_VERSION, "1.1")
+		response.Write([]byte("zip content"))
+	}))
+	defer server.Close()
+
+	filename := filepath.Join(t.TempDir(), "robot.zip")
+	version, err := DownloadRemoteRobot(server.URL+"/", &RemoteRobot{Domain: "team-a", Name: "invoice-bot"}, filename)
+	must.Nil(err)
+	must.Equal("1.1", version)
+	must.Equal("team-a", seen["domain"])
+	must.Equal("secret", seen["token"])
+	content, err := os.ReadFile(filename)
+	must.Nil(err)
+	must.Equal("zip content", string(content))
+
+	_, err = DownloadRemoteRobot(server.URL, &RemoteRobot{Name: "invoice-bot", Version: "9.9"}, filename)
+	wont.Nil(err)
+	must.Equal("/robots/invoice-bot/9.9", seen["path"])
+
+	_, err = DownloadRemoteRobot("", &RemoteRobot{Name: "invoice-bot"}, filename)
+	wont.Nil(err)
+}
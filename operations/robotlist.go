@@ -0,0 +1,397 @@
+package operations
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/htfs"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/joshyorko/rcc/robot"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	RobotReady  = "ready"
+	RobotStale  = "stale"
+	RobotBroken = "broken"
+
+	recentRobotDirectories = 10
+)
+
+var (
+	RobotSortOrders = []string{"name", "path", "modified", "env"}
+
+	skippedRobotDirs = map[string]bool{
+		"node_modules": true,
+		"output":       true,
+		"__pycache__":  true,
+	}
+)
+
+// RobotEntry is one robot found under monorepo root, with status of its
+// environment in hololib.
+type RobotEntry struct {
+	Name      string
+	Path      string
+	Modified  time.Time
+	Status    string
+	Blueprint string
+}
+
+// EnvironmentDrift explains why robot environment is stale, by comparing
+// current environment definition against identity used in last build.
+type EnvironmentDrift struct {
+	Space   string
+	Added   []string
+	Removed []string
+}
+
+type interactiveSession struct {
+	RobotsSort    string                    `yaml:"robots-sort"`
+	ScanRoots     []string                  `yaml:"scan-roots,omitempty"`
+	RecentRoots   []string                  `yaml:"recent-roots,omitempty"`
+	RunParameters map[string]*RunParameters `yaml:"run-parameters,omitempty"`
+	LastView      *SessionView              `yaml:"last-view,omitempty"`
+}
+
+func newestModification(paths ...string) time.Time {
+	result := time.Time{}
+	for _, path := range paths {
+		stat, err := os.Stat(path)
+		if err == nil && stat.ModTime().After(result) {
+			result = stat.ModTime()
+		}
+	}
+	return result
+}
+
+func robotEntryFor(tree htfs.MutableLibrary, robotfile string) *RobotEntry {
+	entry := &RobotEntry{
+		Name:     filepath.Base(filepath.Dir(robotfile)),
+		Path:     robotfile,
+		Modified: newestModification(robotfile),
+		Status:   RobotBroken,
+	}
+	config, err := robot.LoadRobotYaml(robotfile, false)
+	if err != nil {
+		return entry
+	}
+	entry.Modified = newestModification(robotfile, config.CondaConfigFile())
+	_, blueprint, err := htfs.ComposeFinalBlueprint(nil, robotfile, false)
+	if err != nil {
+		return entry
+	}
+	entry.Blueprint = common.BlueprintHash(blueprint)
+	entry.Status = RobotStale
+	if tree != nil && tree.HasBlueprint(blueprint) {
+		entry.Status = RobotReady
+	}
+	return entry
+}
+
+// RobotEnvironmentDrift compares current environment definition of robot to
+// one that was used when robot last got its environment in some space.
+func RobotEnvironmentDrift(robotfile string) (*EnvironmentDrift, error) {
+	fullpath, err := filepath.Abs(robotfile)
+	if err != nil {
+		fullpath = robotfile
+	}
+	space, recorded, ok := htfs.LastBuiltIdentity(fullpath)
+	if !ok {
+		return nil, fmt.Errorf("There is no recorded build for robot %q, so there is nothing to compare to.", fullpath)
+	}
+	_, current, err := htfs.ComposeFinalBlueprint(nil, fullpath, false)
+	if err != nil {
+		return nil, err
+	}
+	added, removed := htfs.RequestedChanges(recorded, current)
+	return &EnvironmentDrift{
+		Space:   space,
+		Added:   added,
+		Removed: removed,
+	}, nil
+}
+
+func findRobotFiles(root string, found []string) ([]string, error) {
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			name := entry.Name()
+			if path != root && (strings.HasPrefix(name, ".") || skippedRobotDirs[name]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.Name() == "robot.yaml" {
+			found = append(found, path)
+		}
+		return nil
+	})
+	return found, err
+}
+
+// DiscoverRobots finds all robots (robot.yaml files) under given roots, and
+// checks if their environments are already available in hololib. Robots
+// under overlapping roots are listed only once.
+func DiscoverRobots(roots ...string) ([]*RobotEntry, error) {
+	found := []string{}
+	for _, root := range roots {
+		var err error
+		found, err = findRobotFiles(root, found)
+		if err != nil {
+			return nil, err
+		}
+	}
+	found = uniqueRobotFiles(found)
+	tree, err := htfs.New()
+	if err != nil {
+		common.Debug("Could not open hololib for robot statuses, reason: %v", err)
+		tree = nil
+	}
+	counter := pretty.NewCounter("Checking robots", len(found))
+	defer counter.Done()
+	result := make([]*RobotEntry, 0, len(found))
+	for _, robotfile := range found {
+		counter.Tick()
+		result = append(result, robotEntryFor(tree, robotfile))
+	}
+	return result, nil
+}
+
+func uniqueRobotFiles(found []string) []string {
+	seen := make(map[string]bool)
+	result := make([]string, 0, len(found))
+	for _, robotfile := range found {
+		fullpath, err := filepath.Abs(robotfile)
+		if err != nil {
+			fullpath = robotfile
+		}
+		if seen[fullpath] {
+			continue
+		}
+		seen[fullpath] = true
+		result = append(result, robotfile)
+	}
+	return result
+}
+
+// FilterRobots keeps robots whose name or path contains search text (case
+// insensitive), and optionally only those with stale environment.
+func FilterRobots(entries []*RobotEntry, search string, staleOnly bool) []*RobotEntry {
+	needle := strings.ToLower(search)
+	result := make([]*RobotEntry, 0, len(entries))
+	for _, entry := range entries {
+		if staleOnly && entry.Status == RobotReady {
+			continue
+		}
+		if len(needle) > 0 && !strings.Contains(strings.ToLower(entry.Path), needle) {
+			continue
+		}
+		result = append(result, entry)
+	}
+	return result
+}
+
+func robotStatusRank(status string) int {
+	switch status {
+	case RobotBroken:
+		return 0
+	case RobotStale:
+		return 1
+	}
+	return 2
+}
+
+// SortRobots sorts robots in place by given order, which is one of
+// RobotSortOrders. Most recently modified robots come first.
+func SortRobots(entries []*RobotEntry, order string) error {
+	var less func(left, right *RobotEntry) bool
+	switch order {
+	case "name":
+		less = func(left, right *RobotEntry) bool {
+			return left.Name < right.Name
+		}
+	case "path":
+		less = func(left, right *RobotEntry) bool {
+			return left.Path < right.Path
+		}
+	case "modified":
+		less = func(left, right *RobotEntry) bool {
+			return left.Modified.After(right.Modified)
+		}
+	case "env":
+		less = func(left, right *RobotEntry) bool {
+			return robotStatusRank(left.Status) < robotStatusRank(right.Status)
+		}
+	default:
+		return fmt.Errorf("Unknown sort order %q, use one of: %s.", order, strings.Join(RobotSortOrders, ", "))
+	}
+	sort.SliceStable(entries, func(left, right int) bool {
+		if less(entries[left], entries[right]) {
+			return true
+		}
+		if less(entries[right], entries[left]) {
+			return false
+		}
+		return entries[left].Path < entries[right].Path
+	})
+	return nil
+}
+
+// NextRobotSort gives sort order after given one, cycling back to first.
+func NextRobotSort(order string) string {
+	for at, candidate := range RobotSortOrders {
+		if candidate == order {
+			return RobotSortOrders[(at+1)%len(RobotSortOrders)]
+		}
+	}
+	return RobotSortOrders[0]
+}
+
+func loadInteractiveSession() *interactiveSession {
+	result := new(interactiveSession)
+	content, err := os.ReadFile(common.InteractiveSessionLocation())
+	if err != nil {
+		return result
+	}
+	err = yaml.Unmarshal(content, result)
+	if err != nil {
+		common.Debug("Ignoring interactive session %q, reason: %v", common.InteractiveSessionLocation(), err)
+		return new(interactiveSession)
+	}
+	return result
+}
+
+// RememberedRobotSort gives sort order chosen in previous interactive session,
+// or "name" if there was none.
+func RememberedRobotSort() string {
+	order := loadInteractiveSession().RobotsSort
+	for _, candidate := range RobotSortOrders {
+		if candidate == order {
+			return order
+		}
+	}
+	return RobotSortOrders[0]
+}
+
+func (it *interactiveSession) save() error {
+	content, err := yaml.Marshal(it)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(common.InteractiveSessionLocation(), content, 0o644)
+}
+
+// RememberRobotSort stores chosen sort order for next interactive session.
+func RememberRobotSort(order string) error {
+	session := loadInteractiveSession()
+	session.RobotsSort = order
+	return session.save()
+}
+
+func absoluteDirectory(directory string) string {
+	fullpath, err := filepath.Abs(directory)
+	if err != nil {
+		return filepath.Clean(directory)
+	}
+	return fullpath
+}
+
+func withoutDirectory(directories []string, directory string) []string {
+	result := make([]string, 0, len(directories))
+	for _, candidate := range directories {
+		if candidate != directory {
+			result = append(result, candidate)
+		}
+	}
+	return result
+}
+
+// RobotScanRoots gives directories where robots are discovered from: given
+// starting directory first, and then additional scan roots configured in
+// interactive.yaml. Missing directories are left out.
+func RobotScanRoots(start string) []string {
+	roots := []string{absoluteDirectory(start)}
+	for _, root := range loadInteractiveSession().ScanRoots {
+		if !pathlib.IsDir(root) {
+			common.Debug("Skipping missing robot scan root %q.", root)
+			continue
+		}
+		roots = append(withoutDirectory(roots, root), root)
+	}
+	return roots
+}
+
+// ConfiguredScanRoots gives additional robot scan roots from interactive.yaml.
+func ConfiguredScanRoots() []string {
+	return loadInteractiveSession().ScanRoots
+}
+
+// AddRobotScanRoot adds directory as additional robot scan root for all
+// following interactive sessions.
+func AddRobotScanRoot(directory string) error {
+	fullpath := absoluteDirectory(directory)
+	if !pathlib.IsDir(fullpath) {
+		return fmt.Errorf("Scan root %q is not a directory.", fullpath)
+	}
+	session := loadInteractiveSession()
+	session.ScanRoots = append(withoutDirectory(session.ScanRoots, fullpath), fullpath)
+	return session.save()
+}
+
+// RemoveRobotScanRoot removes directory from additional robot scan roots.
+func RemoveRobotScanRoot(directory string) error {
+	session := loadInteractiveSession()
+	session.ScanRoots = withoutDirectory(session.ScanRoots, absoluteDirectory(directory))
+	return session.save()
+}
+
+// RecentRobotDirectories gives previously visited robot directories, most
+// recent first.
+func RecentRobotDirectories() []string {
+	return loadInteractiveSession().RecentRoots
+}
+
+// RememberRobotDirectory puts directory first in list of recently visited
+// robot directories, which is limited to ten latest ones.
+func RememberRobotDirectory(directory string) error {
+	fullpath := absoluteDirectory(directory)
+	session := loadInteractiveSession()
+	recent := append([]string{fullpath}, withoutDirectory(session.RecentRoots, fullpath)...)
+	session.RecentRoots = recent[:min(len(recent), recentRobotDirectories)]
+	return session.save()
+}
+
+func ShowRobots(entries []*RobotEntry, order, search string, staleOnly bool) {
+	common.Stdout("%s%-10s%s sort: %s%s%s  search: %s%q%s  stale only: %s%v%s\n\n", pretty.White, "ROBOTS", pretty.Reset, pretty.Cyan, order, pretty.Reset, pretty.Cyan, search, pretty.Reset, pretty.Cyan, staleOnly, pretty.Reset)
+	if len(entries) == 0 {
+		common.Stdout("  %sno matching robots%s\n\n", pretty.Grey, pretty.Reset)
+		return
+	}
+	compact := pretty.Compact()
+	for at, entry := range entries {
+		color := pretty.Green
+		switch entry.Status {
+		case RobotStale:
+			color = pretty.Yellow
+		case RobotBroken:
+			color = pretty.Red
+		}
+		if compact {
+			common.Stdout("  %s%3d%s %s%-6s%s %s\n", pretty.Cyan, at+1, pretty.Reset, color, entry.Status, pretty.Reset, pretty.Clip(entry.Name, pretty.TerminalColumns()-14))
+			continue
+		}
+		common.Stdout("  %s%3d%s %-30s %s%-6s%s %s%s %s%s\n", pretty.Cyan, at+1, pretty.Reset, entry.Name, color, entry.Status, pretty.Reset, pretty.Grey, entry.Modified.Format(time.DateTime), entry.Path, pretty.Reset)
+	}
+	common.Stdout("\n")
+}
@@ -0,0 +1,98 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func robotPaths(entries []*RobotEntry) []string {
+	result := []string{}
+	for _, entry := range entries {
+		result = append(result, entry.Path)
+	}
+	return result
+}
+
+func TestRobotsCanBeFilteredAndSorted(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	now := time.Now()
+	entries := []*RobotEntry{
+		{Name: "beta", Path: "/repo/beta/robot.yaml", Modified: now.Add(-time.Hour), Status: RobotReady},
+		{Name: "alpha", Path: "/repo/zeta/alpha/robot.yaml", Modified: now, Status: RobotStale},
+		{Name: "gamma", Path: "/repo/gamma/robot.yaml", Modified: now.Add(-2 * time.Hour), Status: RobotBroken},
+	}
+
+	must.Nil(SortRobots(entries, "name"))
+	must.Equal([]string{"/repo/zeta/alpha/robot.yaml", "/repo/beta/robot.yaml", "/repo/gamma/robot.yaml"}, robotPaths(entries))
+	must.Nil(SortRobots(entries, "path"))
+	must.Equal([]string{"/repo/beta/robot.yaml", "/repo/gamma/robot.yaml", "/repo/zeta/alpha/robot.yaml"}, robotPaths(entries))
+	must.Nil(SortRobots(entries, "modified"))
+	must.Equal([]string{"/repo/zeta/alpha/robot.yaml", "/repo/beta/robot.yaml", "/repo/gamma/robot.yaml"}, robotPaths(entries))
+	must.Nil(SortRobots(entries, "env"))
+	must.Equal([]string{"/repo/gamma/robot.yaml", "/repo/zeta/alpha/robot.yaml", "/repo/beta/robot.yaml"}, robotPaths(entries))
+	wont.Nil(SortRobots(entries, "size"))
+
+	must.Equal(2, len(FilterRobots(entries, "", true)))
+	must.Equal([]string{"/repo/zeta/alpha/robot.yaml"}, robotPaths(FilterRobots(entries, "ZETA", false)))
+	must.Equal(0, len(FilterRobots(entries, "beta", true)))
+
+	must.Equal("path", NextRobotSort("name"))
+	must.Equal("name", NextRobotSort("env"))
+	must.Equal("name", NextRobotSort("unknown"))
+}
+
+func TestRobotSortIsRememberedBetweenSessions(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	t.Setenv("ROBOCORP_HOME", t.TempDir())
+	must.Equal("name", RememberedRobotSort())
+	must.Nil(RememberRobotSort("modified"))
+	must.Equal("modified", RememberedRobotSort())
+}
+
+func TestRobotScanRootsAndRecentDirectoriesAreRemembered(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	t.Setenv("ROBOCORP_HOME", t.TempDir())
+	start, extra := t.TempDir(), t.TempDir()
+	must.Equal([]string{start}, RobotScanRoots(start))
+
+	wont.Nil(AddRobotScanRoot(filepath.Join(extra, "missing")))
+	must.Nil(AddRobotScanRoot(extra))
+	must.Nil(AddRobotScanRoot(extra))
+	must.Equal([]string{extra}, ConfiguredScanRoots())
+	must.Equal([]string{start, extra}, RobotScanRoots(start))
+	must.Equal([]string{extra}, RobotScanRoots(extra))
+	must.Nil(RemoveRobotScanRoot(extra))
+	must.Equal([]string{start}, RobotScanRoots(start))
+
+	for at := 0; at < 12; at++ {
+		must.Nil(RememberRobotDirectory(filepath.Join(start, fmt.Sprintf("dir%d", at))))
+	}
+	must.Nil(RememberRobotDirectory(filepath.Join(start, "dir5")))
+	recent := RecentRobotDirectories()
+	must.Equal(10, len(recent))
+	must.Equal(filepath.Join(start, "dir5"), recent[0])
+	must.Equal(filepath.Join(start, "dir11"), recent[1])
+	must.Equal("name", RememberedRobotSort())
+}
+
+func TestRobotsAreDiscoveredOnceFromOverlappingRoots(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	t.Setenv("ROBOCORP_HOME", t.TempDir())
+	root := t.TempDir()
+	for _, name := range []string{"alpha", "beta"} {
+		must.Nil(os.MkdirAll(filepath.Join(root, name), 0o755))
+		must.Nil(os.WriteFile(filepath.Join(root, name, "robot.yaml"), []byte("tasks: {}\n"), 0o644))
+	}
+	entries, err := DiscoverRobots(root, filepath.Join(root, "beta"))
+	must.Nil(err)
+	must.Equal(2, len(entries))
+}
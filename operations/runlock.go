@@ -0,0 +1,120 @@
+package operations
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/pretty"
+)
+
+var (
+	runLockPattern = regexp.MustCompile(`[^\w.-]+`)
+)
+
+const (
+	RunLockWait   = "wait"
+	RunLockFail   = "fail"
+	RunLockSuffix = "suffix"
+
+	runLockPrefix   = "run_"
+	runLockSuffixes = 9
+	runLockPolling  = 250 * time.Millisecond
+)
+
+// RunLockFilename returns lock file for runs of given robot in given space.
+func RunLockFilename(robotfile, space string) string {
+	fullpath, err := filepath.Abs(robotfile)
+	if err != nil {
+		fullpath = robotfile
+	}
+	key := common.Textual(common.Sipit([]byte(fullpath)), 12)
+	name := fmt.Sprintf("%s%s_%s.lck", runLockPrefix, key, runLockPattern.ReplaceAllString(space, "_"))
+	return filepath.Join(common.RunLockLocation(), name)
+}
+
+func isRunLock(basename string) bool {
+	return strings.HasPrefix(basename, runLockPrefix) && strings.HasSuffix(basename, ".lck")
+}
+
+func waitRunLock(lockfile string, timeout time.Duration) (pathlib.Releaser, bool, error) {
+	deadline := time.Now().Add(timeout)
+	completed := pathlib.LockWaitMessage(lockfile, "Serialized robot run [run lock]")
+	defer completed()
+	for {
+		locker, ok, err := pathlib.TryLocker(lockfile, false)
+		if err != nil || ok {
+			return locker, ok, err
+		}
+		if timeout > 0 && time.Now().After(deadline) {
+			return nil, false, nil
+		}
+		time.Sleep(runLockPolling)
+	}
+}
+
+// AcquireRunLock prevents concurrent runs of same robot in same space. When
+// lock is held by other run, mode decides what happens: "wait" waits until
+// lock is free (or timeout, when positive, expires), "fail" fails fast, and
+// "suffix" switches into first free space with numeric suffix (like
+// "user-2"). Returns function releasing the lock.
+func AcquireRunLock(robotfile, mode string, timeout time.Duration) func() {
+	if len(mode) == 0 {
+		mode = common.RccRunLock()
+	}
+	if len(mode) == 0 {
+		mode = RunLockWait
+	}
+	pretty.Guard(mode == RunLockWait || mode == RunLockFail || mode == RunLockSuffix, 1, "Unknown run lock mode %q, expected %q, %q, or %q.", mode, RunLockWait, RunLockFail, RunLockSuffix)
+	space := common.HolotreeSpace
+	lockfile := RunLockFilename(robotfile, space)
+	locker, ok, err := pathlib.TryLocker(lockfile, false)
+	pretty.Guard(err == nil, 1, "Could not get run lock %q, reason: %v", lockfile, err)
+	if !ok {
+		holders := runLockHolders(lockfile)
+		switch mode {
+		case RunLockFail:
+			pretty.ExitCoded(2, common.ErrSpaceBusy, "Robot %q is already running in space %q%s.", robotfile, space, holders)
+		case RunLockSuffix:
+			for suffix := 2; suffix <= runLockSuffixes+1 && !ok; suffix++ {
+				candidate := fmt.Sprintf("%s-%d", space, suffix)
+				lockfile = RunLockFilename(robotfile, candidate)
+				common.HolotreeSpace = candidate
+				locker, ok, err = pathlib.TryLocker(lockfile, false)
+				pretty.Guard(err == nil, 1, "Could not get run lock %q, reason: %v", lockfile, err)
+			}
+			if ok {
+				pretty.Warning("Space %q is in use by other run%s, so using space %q instead.", space, holders, common.HolotreeSpace)
+			} else {
+				pretty.ExitCoded(2, common.ErrSpaceBusy, "Robot %q is already running in space %q and all its %d suffix spaces.", robotfile, space, runLockSuffixes)
+			}
+		default:
+			common.Log("Robot %q is already running in space %q%s, waiting ...", robotfile, space, holders)
+			locker, ok, err = waitRunLock(lockfile, timeout)
+			pretty.Guard(err == nil, 1, "Could not get run lock %q, reason: %v", lockfile, err)
+			if !ok {
+				pretty.ExitCoded(2, common.ErrSpaceBusy, "Robot %q is still running in space %q after waiting %s.", robotfile, space, timeout)
+			}
+		}
+	}
+	common.Debug("Got run lock %q for space %q.", lockfile, common.HolotreeSpace)
+	return func() {
+		locker.Release()
+	}
+}
+
+func runLockHolders(lockfile string) string {
+	holders, err := pathlib.LockHoldersBy(lockfile)
+	if err != nil || len(holders) == 0 {
+		return ""
+	}
+	pids := make([]string, 0, len(holders))
+	for _, holder := range holders {
+		pids = append(pids, fmt.Sprintf("%s/%d", holder.Username, holder.ProcessID))
+	}
+	return fmt.Sprintf(" (held by %s)", strings.Join(pids, ", "))
+}
@@ -0,0 +1,45 @@
+package operations
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestRunLockSerializesSameRobotAndSpace(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	t.Setenv("ROBOCORP_HOME", t.TempDir())
+	original := common.HolotreeSpace
+	defer func() {
+		common.HolotreeSpace = original
+	}()
+	common.HolotreeSpace = "user"
+	robotfile := filepath.Join(t.TempDir(), "robot.yaml")
+
+	lockfile := RunLockFilename(robotfile, "user")
+	must.True(isRunLock(filepath.Base(lockfile)))
+	wont.Equal(lockfile, RunLockFilename(robotfile, "other"))
+	wont.Equal(lockfile, RunLockFilename("elsewhere/robot.yaml", "user"))
+	must.Equal("run_", filepath.Base(RunLockFilename(robotfile, "a/b c"))[:4])
+
+	release := AcquireRunLock(robotfile, RunLockFail, 0)
+
+	must.Panic(func() { AcquireRunLock(robotfile, RunLockFail, 0) })
+	must.Panic(func() { AcquireRunLock(robotfile, RunLockWait, 300*time.Millisecond) })
+	must.Panic(func() { AcquireRunLock(robotfile, "bogus", 0) })
+	must.Equal("user", common.HolotreeSpace)
+
+	suffixed := AcquireRunLock(robotfile, RunLockSuffix, 0)
+	must.Equal("user-2", common.HolotreeSpace)
+	suffixed()
+
+	common.HolotreeSpace = "user"
+	release()
+	again := AcquireRunLock(robotfile, RunLockFail, 0)
+	must.Equal("user", common.HolotreeSpace)
+	again()
+}
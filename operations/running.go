@@ -1,7 +1,9 @@
 package operations
 
 import (
+	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -20,9 +22,11 @@ import (
 )
 
 const (
-	actualRun      = `actual main robot run`
-	preRun         = `pre-run script execution`
-	newEnvironment = `environment creation`
+	actualRun         = `actual main robot run`
+	preRun            = `pre-run script execution`
+	newEnvironment    = `environment creation`
+	argumentsFilename = `task-arguments.json`
+	argumentsVariable = `RCC_ARGUMENTS_FILE`
 )
 
 var (
@@ -43,6 +47,13 @@ type RunFlags struct {
 	RobotYaml       string
 	Assistant       bool
 	NoPipFreeze     bool
+	RefreshTokens   bool
+	Transcript      bool
+	History         bool
+	Inputs          string
+	Task            string
+	Limits          *RunLimits
+	Sandbox         bool
 }
 
 func (it *TokenPeriod) EnforceGracePeriod() *TokenPeriod {
@@ -145,11 +156,11 @@ func LoadAnyTaskEnvironment(packfile string, force bool) (bool, robot.Robot, rob
 	FixRobot(packfile)
 	config, err := robot.LoadRobotYaml(packfile, false)
 	if err != nil {
-		pretty.Exit(1, "Error: %v", err)
+		pretty.ExitCoded(1, common.ErrRobotLoad, "Error: %v", err)
 	}
 	anytasks := config.AvailableTasks()
 	if len(anytasks) == 0 {
-		pretty.Exit(1, "Could not find tasks from %q.", packfile)
+		pretty.ExitCoded(1, common.ErrTaskNotFound, "Could not find tasks from %q.", packfile)
 	}
 	return LoadTaskWithEnvironment(packfile, anytasks[0], force)
 }
@@ -159,17 +170,17 @@ func LoadTaskWithEnvironment(packfile, theTask string, force bool) (bool, robot.
 	FixRobot(packfile)
 	config, err := robot.LoadRobotYaml(packfile, true)
 	if err != nil {
-		pretty.Exit(1, "Error: %v", err)
+		pretty.ExitCoded(1, common.ErrRobotLoad, "Error: %v", err)
 	}
 
 	ok, err := config.Validate()
 	if !ok {
-		pretty.Exit(2, "Error: %v", err)
+		pretty.ExitCoded(2, common.ErrRobotValidation, "Error: %v", err)
 	}
 
 	todo := config.TaskByName(theTask)
 	if todo == nil {
-		pretty.Exit(3, "Error: Could not resolve what task to run. Select one using --task option.\nAvailable task names are: %v.", strings.Join(config.AvailableTasks(), ", "))
+		pretty.ExitCoded(3, common.ErrTaskNotFound, "Error: Could not resolve what task to run. Select one using --task option.\nAvailable task names are: %v.", strings.Join(config.AvailableTasks(), ", "))
 	}
 
 	if config.HasHolozip() && !common.UsesHolotree() {
@@ -178,6 +189,7 @@ func LoadTaskWithEnvironment(packfile, theTask string, force bool) (bool, robot.
 
 	pathlib.EnsureDirectoryExists(config.ArtifactDirectory())
 	journal.ForRun(filepath.Join(config.ArtifactDirectory(), "journal.run"))
+	pretty.FailureReportAt(config.ArtifactDirectory())
 	cache, err := SummonCache()
 	if err == nil && len(cache.Userset()) > 1 {
 		pretty.Note("There seems to be multiple users sharing %s, which might cause problems.", common.Product.HomeVariable())
@@ -192,16 +204,26 @@ func LoadTaskWithEnvironment(packfile, theTask string, force bool) (bool, robot.
 		return true, config, todo, ""
 	}
 
-	label, _, err := htfs.NewEnvironment(config.CondaConfigFile(), config.Holozip(), true, force, PullCatalog)
+	shared, err := UseSharedEnvironment(config)
+	if err != nil {
+		pretty.ExitCoded(2, common.ErrRobotValidation, "Error: %v", err)
+	}
+	if shared != nil {
+		pretty.Note("Using shared environment %q in space %q.", shared.Name, shared.Space)
+	}
+
+	label, _, err := htfs.NewEnvironment(config.CondaConfigFile(), config.Holozip(), config.LocalPackages(), true, force, PullCatalog)
 	if err != nil {
 		pretty.RccPointOfView(newEnvironment, err)
-		pretty.Exit(4, "Error: %v", err)
+		pretty.ExitCoded(4, common.ErrEnvironmentCreation, "Error: %v", err)
 	}
+	RecordRobotUsage(label, packfile)
 	return false, config, todo, label
 }
 
 func SelectExecutionModel(runFlags *RunFlags, simple bool, template []string, config robot.Robot, todo robot.Task, label string, interactive bool, extraEnv map[string]string) {
 	common.TimelineBegin("robot execution (simple=%v).", simple)
+	defer pretty.SummaryDuration("run", time.Now())
 	common.RunJournal("start", "robot", "started")
 	defer common.RunJournal("stop", "robot", "done")
 	defer common.TimelineEnd()
@@ -224,23 +246,35 @@ func ExecuteSimpleTask(flags *RunFlags, template []string, config robot.Robot, t
 	searchPath = searchPath.Prepend(config.Paths()...)
 	found, ok := searchPath.Which(task[0], conda.FileExtensions)
 	if !ok {
-		pretty.Exit(6, "Error: Cannot find command: %v", task[0])
+		pretty.ExitCoded(6, common.ErrTaskCommand, "Error: Cannot find command: %v", task[0])
 	}
 	fullpath, err := filepath.EvalSymlinks(found)
 	if err != nil {
 		pretty.Exit(7, "Error: %v", err)
 	}
 	var data Token
-	if len(flags.WorkspaceId) > 0 {
+	var refresher *tokenRefresher
+	if flags.RefreshTokens && len(flags.WorkspaceId) > 0 {
+		refresher, err = NewTokenRefresher(flags)
+		if err == nil {
+			data = refresher.Token()
+			refresher.Start()
+			defer refresher.Stop()
+		}
+	} else if len(flags.WorkspaceId) > 0 {
 		claims := RunRobotClaims(flags.TokenPeriod.RequestSeconds(), flags.WorkspaceId)
 		data, err = AuthorizeClaims(flags.AccountName, claims, flags.TokenPeriod.EnforceGracePeriod())
 	}
 	if err != nil {
-		pretty.Exit(8, "Error: %v", err)
+		pretty.ExitCoded(8, common.ErrCloudRequest, "Error: %v", err)
 	}
 	task[0] = fullpath
 	directory := config.WorkingDirectory()
 	environment := robot.PlainEnvironment([]string{searchPath.AsEnvironmental("PATH")}, true)
+	environment = append(environment, todo.Environment()...)
+	if refresher != nil {
+		environment = append(environment, refresher.Environment()...)
+	}
 	if len(data) > 0 {
 		endpoint := data["endpoint"]
 		for _, key := range rcHosts {
@@ -257,26 +291,172 @@ func ExecuteSimpleTask(flags *RunFlags, template []string, config robot.Robot, t
 			environment = append(environment, fmt.Sprintf("%s=%s", key, value))
 		}
 	}
+	requireSecrets(todo, environment)
+	requireInputs(todo, directory, environment)
 	outputDir, err := pathlib.EnsureDirectory(config.ArtifactDirectory())
 	if err != nil {
 		pretty.Exit(9, "Error: %v", err)
 	}
+	limits, err := EffectiveLimits(flags.Limits, config)
+	if err != nil {
+		pretty.Exit(5, "Error: %v", err)
+	}
+	var supervisor *limitSupervisor
+	if limits.Active() {
+		supervisor = newLimitSupervisor(limits)
+	}
+	sandbox, task, environment := setupSandbox(flags, config, task, environment)
+	defer sandbox.Cleanup()
 	common.Debug("about to run command - %v", task)
+	if supervisor != nil {
+		supervisor.Start()
+	}
+	started := time.Now()
+	exitcode := 0
 	if common.NoOutputCapture {
-		_, err = shell.New(environment, directory, task...).Execute(interactive)
+		exitcode, err = shell.New(environment, directory, task...).WithAttributes(sandbox.Attributes()).WithStarted(supervisor.Started).Execute(interactive)
 	} else {
-		_, err = shell.New(environment, directory, task...).Tee(outputDir, interactive)
+		exitcode, err = shell.New(environment, directory, task...).WithAttributes(sandbox.Attributes()).WithStarted(supervisor.Started).Tee(outputDir, interactive)
+	}
+	if supervisor != nil {
+		supervisor.Stop()
+	}
+	sandbox.hint(exitcode, err)
+	recordRunHistory(flags, config, started, exitcode, err)
+	writeProvenance(flags, config, "", started, exitcode, err)
+	contract := verifyOutputs(todo, directory)
+	if supervisor != nil {
+		reason := supervisor.Reason()
+		if len(reason) > 0 {
+			pretty.Exit(ExitKilledByLimit, "Error: robot run was killed by limit: %s", reason)
+		}
 	}
 	if err != nil {
 		pretty.Exit(10, "Error: %v", err)
 	}
+	if contract != nil {
+		pretty.ExitCoded(15, common.ErrTaskOutputs, "Error: %v", contract)
+	}
 	pretty.Ok()
 }
 
+func recordRunHistory(flags *RunFlags, config robot.Robot, started time.Time, exitcode int, err error) {
+	if !flags.History {
+		return
+	}
+	robotfile, problem := filepath.Abs(flags.RobotYaml)
+	if problem != nil {
+		robotfile = flags.RobotYaml
+	}
+	environment := flags.EnvironmentFile
+	if len(environment) > 0 {
+		environment, _ = filepath.Abs(environment)
+	}
+	problem = journal.RecordRun(&journal.RunRecord{
+		When:        started.Unix(),
+		Robot:       robotfile,
+		Task:        flags.Task,
+		Space:       common.HolotreeSpace,
+		Environment: environment,
+		Developer:   common.DeveloperFlag,
+		Elapsed:     time.Since(started).Round(time.Millisecond).Seconds(),
+		Exitcode:    exitcode,
+		Success:     err == nil,
+		Artifacts:   config.ArtifactDirectory(),
+		Inputs:      flags.Inputs,
+	})
+	if problem != nil {
+		common.Debug("Could not record run history, reason: %v", problem)
+	}
+}
+
+func requireSecrets(todo robot.Task, environment []string) {
+	missing := robot.MissingSecrets(todo, environment)
+	if len(missing) > 0 {
+		common.RunJournal("secrets", "missing", "task requires %s", strings.Join(missing, ", "))
+		pretty.ExitCoded(13, common.ErrMissingSecrets, "Error: Task requires secrets %s, but they are not available in environment.", strings.Join(missing, ", "))
+	}
+}
+
+// TaskArguments validates arguments declared by task, and gives remaining
+// command line arguments, and environment with argument variables added.
+// Argument values are also written into arguments file in artifact directory,
+// which is given to robot in RCC_ARGUMENTS_FILE variable.
+func TaskArguments(config robot.Robot, todo robot.Task, args []string, extraEnv map[string]string) ([]string, map[string]string) {
+	values, rest, err := robot.ParseArguments(todo, args)
+	if err != nil {
+		common.RunJournal("arguments", "invalid", "%v", err)
+		pretty.ExitCoded(17, common.ErrTaskArguments, "Error: %v", err)
+	}
+	if values == nil {
+		return rest, extraEnv
+	}
+	environment := robot.ArgumentsEnvironment(values)
+	maps.Copy(environment, extraEnv)
+	filename := filepath.Join(config.ArtifactDirectory(), argumentsFilename)
+	content, err := json.MarshalIndent(values, "", "  ")
+	if err == nil {
+		err = pathlib.WriteFile(filename, content, 0o644)
+	}
+	if err != nil {
+		pretty.Warning("Could not write task arguments into %q, reason: %v", filename, err)
+	} else {
+		environment[argumentsVariable] = filename
+	}
+	common.Debug("Task arguments are: %v", values)
+	return rest, environment
+}
+
+func reportContract(result *robot.ContractResult) bool {
+	if result == nil {
+		return true
+	}
+	pretty.SummaryContract(result.Kind, result.Policy, result.Checked, result.Missing)
+	if result.Ok() {
+		common.Debug("Task %s contract ok, %d declarations checked.", result.Kind, result.Checked)
+		return true
+	}
+	missing := strings.Join(result.Missing, ", ")
+	common.RunJournal("contract", result.Kind, "task %s missing (policy %s): %s", result.Kind, result.Policy, missing)
+	if !result.Failed() {
+		pretty.Warning("Task %s contract is broken, missing: %s", result.Kind, missing)
+		return true
+	}
+	return false
+}
+
+func requireInputs(todo robot.Task, root string, environment []string) {
+	result := robot.CheckInputs(todo, root, environment)
+	if !reportContract(result) {
+		pretty.ExitCoded(14, common.ErrTaskInputs, "Error: Task requires inputs %s, but they are not available.", strings.Join(result.Missing, ", "))
+	}
+}
+
+func verifyOutputs(todo robot.Task, root string) error {
+	result := robot.CheckOutputs(todo, root)
+	if !reportContract(result) {
+		return fmt.Errorf("Task did not produce declared outputs %s", strings.Join(result.Missing, ", "))
+	}
+	return nil
+}
+
+func recordedExecute(task *shell.Task, outputDir string, interactive bool) (int, error) {
+	filename := filepath.Join(outputDir, fmt.Sprintf("transcript_%s.jsonl", time.Now().Format("20060102_150405")))
+	sink, err := pathlib.Create(filename)
+	if err != nil {
+		return -604, err
+	}
+	defer sink.Close()
+	common.Log("Recording session transcript into %q.", filename)
+	common.RunJournal("shell", "transcript", "session transcript recorded into %q", filename)
+	defer common.Log("Session transcript is at %q. Use `rcc task shell --replay` to view it.", filename)
+	return task.Recorded(shell.NewTranscript(sink), interactive)
+}
+
 func findExecutableOrDie(searchPath pathlib.PathParts, executable string) string {
 	found, ok := searchPath.Which(executable, conda.FileExtensions)
 	if !ok {
-		pretty.Exit(6, "Error: Cannot find command: %v", executable)
+		pretty.ExitCoded(6, common.ErrTaskCommand, "Error: Cannot find command: %v", executable)
 	}
 	fullpath, err := filepath.EvalSymlinks(found)
 	if err != nil {
@@ -296,15 +476,27 @@ func ExecuteTask(flags *RunFlags, template []string, config robot.Robot, todo ro
 	searchPath := config.SearchPath(label)
 	task[0] = findExecutableOrDie(searchPath, task[0])
 	var data Token
-	if !flags.Assistant && len(flags.WorkspaceId) > 0 {
+	var refresher *tokenRefresher
+	if flags.RefreshTokens && len(flags.WorkspaceId) > 0 {
+		refresher, err = NewTokenRefresher(flags)
+		if err == nil {
+			data = refresher.Token()
+			refresher.Start()
+			defer refresher.Stop()
+		}
+	} else if !flags.Assistant && len(flags.WorkspaceId) > 0 {
 		claims := RunRobotClaims(flags.TokenPeriod.RequestSeconds(), flags.WorkspaceId)
 		data, err = AuthorizeClaims(flags.AccountName, claims, nil)
 	}
 	if err != nil {
-		pretty.Exit(8, "Error: %v", err)
+		pretty.ExitCoded(8, common.ErrCloudRequest, "Error: %v", err)
 	}
 	directory := config.WorkingDirectory()
 	environment := config.RobotExecutionEnvironment(label, developmentEnvironment.AsEnvironment(), true)
+	environment = append(environment, todo.Environment()...)
+	if refresher != nil {
+		environment = append(environment, refresher.Environment()...)
+	}
 	if len(data) > 0 {
 		endpoint := data["endpoint"]
 		for _, key := range rcHosts {
@@ -321,6 +513,8 @@ func ExecuteTask(flags *RunFlags, template []string, config robot.Robot, todo ro
 			environment = append(environment, fmt.Sprintf("%s=%s", key, value))
 		}
 	}
+	requireSecrets(todo, environment)
+	requireInputs(todo, directory, environment)
 	before := make(map[string]string)
 	beforeHash, beforeErr := conda.DigestFor(label, before)
 	outputDir, err := pathlib.EnsureDirectory(config.ArtifactDirectory())
@@ -346,35 +540,59 @@ func ExecuteTask(flags *RunFlags, template []string, config robot.Robot, todo ro
 			scriptCommand, err := shell.Split(script)
 			if err != nil {
 				pretty.RccPointOfView(preRun, err)
-				pretty.Exit(11, "%sScript '%s' parsing failure: %v%s", pretty.Red, script, err, pretty.Reset)
+				pretty.ExitCoded(11, common.ErrPreRunScript, "%sScript '%s' parsing failure: %v%s", pretty.Red, script, err, pretty.Reset)
 			}
 			scriptCommand[0] = findExecutableOrDie(searchPath, scriptCommand[0])
 			common.Debug("Running pre run script '%s' ...", script)
 			_, err = shell.New(environment, directory, scriptCommand...).Execute(interactive)
 			if err != nil {
 				pretty.RccPointOfView(preRun, err)
-				pretty.Exit(12, "%sScript '%s' failure: %v%s", pretty.Red, script, err, pretty.Reset)
+				pretty.ExitCoded(12, common.ErrPreRunScript, "%sScript '%s' failure: %v%s", pretty.Red, script, err, pretty.Reset)
 			}
 		}
 		journal.CurrentBuildEvent().PreRunComplete()
 		common.Timeline("pre run scripts completed")
 	}
 
+	limits, err := EffectiveLimits(flags.Limits, config)
+	if err != nil {
+		pretty.Exit(5, "Error: %v", err)
+	}
+	var supervisor *limitSupervisor
+	if limits.Active() {
+		supervisor = newLimitSupervisor(limits)
+	}
+
+	sandbox, task, environment := setupSandbox(flags, config, task, environment)
+	defer sandbox.Cleanup()
+
 	common.Debug("about to run command - %v", task)
 	journal.CurrentBuildEvent().RobotStarts()
 	pipe := WatchChildren(os.Getpid(), 550*time.Millisecond)
+	if supervisor != nil {
+		supervisor.Start()
+	}
+	started := time.Now()
+	exitcode := 0
 	shell.WithInterrupt(func() {
-		exitcode := 0
-		if common.NoOutputCapture {
-			exitcode, err = shell.New(environment, directory, task...).Execute(interactive)
+		if flags.Transcript {
+			exitcode, err = recordedExecute(shell.New(environment, directory, task...).WithAttributes(sandbox.Attributes()).WithStarted(supervisor.Started), outputDir, interactive)
+		} else if common.NoOutputCapture {
+			exitcode, err = shell.New(environment, directory, task...).WithAttributes(sandbox.Attributes()).WithStarted(supervisor.Started).Execute(interactive)
 		} else {
-			exitcode, err = shell.New(environment, directory, task...).Tee(outputDir, interactive)
+			exitcode, err = shell.New(environment, directory, task...).WithAttributes(sandbox.Attributes()).WithStarted(supervisor.Started).Tee(outputDir, interactive)
 		}
 		if exitcode != 0 {
 			details := fmt.Sprintf("%s_%d_%08x", common.Platform(), exitcode, uint32(exitcode))
 			cloud.InternalBackgroundMetric(common.ControllerIdentity(), "rcc.cli.run.failure", details)
 		}
 	})
+	if supervisor != nil {
+		supervisor.Stop()
+	}
+	sandbox.hint(exitcode, err)
+	recordRunHistory(flags, config, started, exitcode, err)
+	writeProvenance(flags, config, label, started, exitcode, err)
 	pretty.RccPointOfView(actualRun, err)
 	seen, ok := <-pipe
 	suberr := SubprocessWarning(seen, ok)
@@ -382,11 +600,21 @@ func ExecuteTask(flags *RunFlags, template []string, config robot.Robot, todo ro
 		pretty.Warning("Problem with subprocess warnings, reason: %v", suberr)
 	}
 	journal.CurrentBuildEvent().RobotEnds()
+	contract := verifyOutputs(todo, directory)
 	after := make(map[string]string)
 	afterHash, afterErr := conda.DigestFor(label, after)
 	conda.DiagnoseDirty(label, label, beforeHash, afterHash, beforeErr, afterErr, before, after, true)
+	if supervisor != nil {
+		reason := supervisor.Reason()
+		if len(reason) > 0 {
+			pretty.Exit(ExitKilledByLimit, "Error: robot run was killed by limit: %s (robot run exit)", reason)
+		}
+	}
 	if err != nil {
 		pretty.Exit(10, "Error: %v (robot run exit)", err)
 	}
+	if contract != nil {
+		pretty.ExitCoded(15, common.ErrTaskOutputs, "Error: %v (robot run exit)", contract)
+	}
 	pretty.Ok()
 }
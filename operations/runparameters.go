@@ -0,0 +1,78 @@
+package operations
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RunParameters are choices made before interactive run of robot task. They
+// are remembered per robot in interactive.yaml, and used as defaults next
+// time.
+type RunParameters struct {
+	Task        string            `yaml:"task,omitempty"`
+	Space       string            `yaml:"space,omitempty"`
+	Timeout     string            `yaml:"timeout,omitempty"`
+	Developer   bool              `yaml:"dev,omitempty"`
+	NoPipFreeze bool              `yaml:"no-pip-freeze,omitempty"`
+	WorkItems   bool              `yaml:"work-items,omitempty"`
+	Environment map[string]string `yaml:"environment,omitempty"`
+}
+
+// Duration gives timeout of run, zero meaning no timeout.
+func (it *RunParameters) Duration() time.Duration {
+	timeout, err := time.ParseDuration(it.Timeout)
+	if err != nil {
+		return 0
+	}
+	return timeout
+}
+
+// EnvironmentLines gives extra environment variables as sorted KEY=value
+// lines.
+func (it *RunParameters) EnvironmentLines() []string {
+	result := make([]string, 0, len(it.Environment))
+	for key, value := range it.Environment {
+		result = append(result, fmt.Sprintf("%s=%s", key, value))
+	}
+	sort.Strings(result)
+	return result
+}
+
+// ParseEnvironmentLine splits KEY=value into its parts.
+func ParseEnvironmentLine(line string) (string, string, error) {
+	key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+	key = strings.TrimSpace(key)
+	if !ok || len(key) == 0 || strings.ContainsAny(key, " \t") {
+		return "", "", fmt.Errorf("Environment variable must be given as KEY=value, not %q.", line)
+	}
+	return key, value, nil
+}
+
+// RememberedRunParameters gives run parameters chosen in previous interactive
+// run of robot, or defaults if robot was not run interactively before.
+func RememberedRunParameters(robotfile string) *RunParameters {
+	parameters, ok := loadInteractiveSession().RunParameters[absoluteDirectory(robotfile)]
+	if !ok || parameters == nil {
+		return &RunParameters{
+			Space:       "user",
+			Environment: map[string]string{},
+		}
+	}
+	if parameters.Environment == nil {
+		parameters.Environment = map[string]string{}
+	}
+	return parameters
+}
+
+// RememberRunParameters stores run parameters of robot for its next
+// interactive run.
+func RememberRunParameters(robotfile string, parameters *RunParameters) error {
+	session := loadInteractiveSession()
+	if session.RunParameters == nil {
+		session.RunParameters = make(map[string]*RunParameters)
+	}
+	session.RunParameters[absoluteDirectory(robotfile)] = parameters
+	return session.save()
+}
@@ -0,0 +1,60 @@
+package operations
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestRunParametersAreRememberedPerRobot(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	common.Product.ForceHome(t.TempDir())
+	defer common.Product.ForceHome("")
+
+	first := filepath.Join(t.TempDir(), "robot.yaml")
+	second := filepath.Join(t.TempDir(), "robot.yaml")
+
+	initial := RememberedRunParameters(first)
+	must.Equal("user", initial.Space)
+	must.Equal(time.Duration(0), initial.Duration())
+	must.Equal(0, len(initial.EnvironmentLines()))
+
+	chosen := &RunParameters{
+		Task:        "Main task",
+		Space:       "nightly",
+		Timeout:     "90m",
+		NoPipFreeze: true,
+		Environment: map[string]string{"ZETA": "last", "ALPHA": "a=b"},
+	}
+	must.Nil(RememberRunParameters(first, chosen))
+	must.Nil(AddRobotScanRoot(t.TempDir()))
+
+	again := RememberedRunParameters(first)
+	must.Equal("Main task", again.Task)
+	must.Equal("nightly", again.Space)
+	must.Equal(90*time.Minute, again.Duration())
+	must.True(again.NoPipFreeze)
+	wont.True(again.Developer)
+	must.Equal([]string{"ALPHA=a=b", "ZETA=last"}, again.EnvironmentLines())
+	must.Equal("user", RememberedRunParameters(second).Space)
+}
+
+func TestEnvironmentLinesAreParsed(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	key, value, err := ParseEnvironmentLine(" NAME=some value=here ")
+	must.Nil(err)
+	must.Equal("NAME", key)
+	must.Equal("some value=here", value)
+
+	_, _, err = ParseEnvironmentLine("NAME")
+	wont.Nil(err)
+	_, _, err = ParseEnvironmentLine("=value")
+	wont.Nil(err)
+	_, _, err = ParseEnvironmentLine("TWO WORDS=value")
+	wont.Nil(err)
+}
@@ -0,0 +1,160 @@
+package operations
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/joshyorko/rcc/robot"
+)
+
+// RunSandbox is effective sandbox of robot run. Sandboxed robot gets its own
+// temporary directory, no network, and read-only robot directory (artifacts
+// directory stays writable), unless robot declares capabilities for those.
+type RunSandbox struct {
+	Enabled    bool
+	Network    bool
+	WriteRobot bool
+	SharedTemp bool
+
+	private    string
+	attributes *syscall.SysProcAttr
+}
+
+func (it *RunSandbox) Active() bool {
+	return it != nil && it.Enabled
+}
+
+// Restrictions lists what sandbox takes away from robot.
+func (it *RunSandbox) Restrictions() []string {
+	result := []string{}
+	if !it.SharedTemp {
+		result = append(result, "private tmp")
+	}
+	if !it.Network {
+		result = append(result, "no network")
+	}
+	if !it.WriteRobot {
+		result = append(result, "read-only robot directory")
+	}
+	return result
+}
+
+// EffectiveSandbox combines --sandbox flag with 'sandbox:' from robot.yaml.
+func EffectiveSandbox(enabled bool, config robot.Robot) *RunSandbox {
+	var defined *robot.Sandbox
+	if config != nil {
+		defined = config.RunSandbox()
+	}
+	return &RunSandbox{
+		Enabled:    enabled || (defined != nil && defined.Enabled),
+		Network:    defined.Allows(robot.CapabilityNetwork),
+		WriteRobot: defined.Allows(robot.CapabilityWriteRobot),
+		SharedTemp: defined.Allows(robot.CapabilitySharedTemp),
+	}
+}
+
+// Attributes gives process attributes (namespaces) of sandboxed task.
+func (it *RunSandbox) Attributes() *syscall.SysProcAttr {
+	if it == nil {
+		return nil
+	}
+	return it.attributes
+}
+
+// Cleanup removes private temporary directory of sandbox.
+func (it *RunSandbox) Cleanup() {
+	if it != nil && len(it.private) > 0 {
+		os.RemoveAll(it.private)
+	}
+}
+
+// Environment gives robot its own temporary directory, unless robot shares
+// temporary directory. Same directory is also mounted over /tmp inside
+// sandbox (see Command).
+func (it *RunSandbox) Environment(environment []string) ([]string, error) {
+	if it.SharedTemp {
+		return environment, nil
+	}
+	err := os.MkdirAll(common.ProductTempRoot(), 0o755)
+	if err != nil {
+		return nil, err
+	}
+	it.private, err = os.MkdirTemp(common.ProductTempRoot(), "sandbox_")
+	if err != nil {
+		return nil, err
+	}
+	return append(environment, "TMPDIR="+it.private, "TEMP="+it.private, "TMP="+it.private), nil
+}
+
+// Command wraps task command so that it runs inside sandbox, and sets up
+// process attributes needed for that.
+func (it *RunSandbox) Command(task []string, robotdir, artifacts string) ([]string, error) {
+	privateTemp := !it.SharedTemp && len(it.private) > 0
+	if it.Network && it.WriteRobot && !privateTemp {
+		return task, nil
+	}
+	err := sandboxSupported()
+	if err != nil {
+		return nil, err
+	}
+	if it.WriteRobot && !privateTemp {
+		it.attributes = sandboxAttributes(true, false)
+		return task, nil
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	wrapped := []string{self, "internal", "sandbox"}
+	if !it.WriteRobot {
+		wrapped = append(wrapped, "--readonly", robotdir)
+		if isInsideDirectory(robotdir, artifacts) {
+			wrapped = append(wrapped, "--writable", artifacts)
+		}
+	}
+	if privateTemp {
+		wrapped = append(wrapped, "--tmp", it.private)
+	}
+	wrapped = append(wrapped, "--")
+	it.attributes = sandboxAttributes(!it.Network, true)
+	return append(wrapped, task...), nil
+}
+
+// setupSandbox applies effective sandbox of robot run into task command and
+// its environment, and exits if sandbox cannot be set up. Result is nil when
+// robot does not run in sandbox.
+func setupSandbox(flags *RunFlags, config robot.Robot, task, environment []string) (*RunSandbox, []string, []string) {
+	sandbox := EffectiveSandbox(flags.Sandbox, config)
+	if !sandbox.Active() {
+		return nil, task, environment
+	}
+	environment, err := sandbox.Environment(environment)
+	if err == nil {
+		task, err = sandbox.Command(task, config.WorkingDirectory(), config.ArtifactDirectory())
+	}
+	if err != nil {
+		sandbox.Cleanup()
+		pretty.ExitCoded(16, common.ErrRobotSandbox, "Error: %v", err)
+	}
+	common.RunJournal("sandbox", strings.Join(sandbox.Restrictions(), ", "), "robot runs in sandbox")
+	pretty.Note("Robot runs in sandbox: %s.", strings.Join(sandbox.Restrictions(), ", "))
+	return sandbox, task, environment
+}
+
+func isInsideDirectory(parent, child string) bool {
+	relative, err := filepath.Rel(parent, child)
+	return err == nil && relative != ".." && !strings.HasPrefix(relative, ".."+string(filepath.Separator))
+}
+
+// hint explains how to give robot more access, when sandboxed run
+// fails.
+func (it *RunSandbox) hint(exitcode int, err error) {
+	if it == nil || (exitcode == 0 && err == nil) {
+		return
+	}
+	pretty.Note("Robot ran in sandbox (%s). If it needs more access, declare it in robot.yaml, like 'sandbox: capabilities: [%s]'.", strings.Join(it.Restrictions(), ", "), strings.Join(robot.SandboxCapabilities, ", "))
+}
@@ -0,0 +1,18 @@
+package operations
+
+import (
+	"fmt"
+	"syscall"
+)
+
+func sandboxSupported() error {
+	return fmt.Errorf("Robot sandbox is only supported on Linux.")
+}
+
+func sandboxAttributes(network, mounts bool) *syscall.SysProcAttr {
+	return nil
+}
+
+func EnterSandbox(readonly string, writable []string, temporary string, command []string) error {
+	return sandboxSupported()
+}
@@ -0,0 +1,139 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/joshyorko/rcc/common"
+	"golang.org/x/sys/unix"
+)
+
+const (
+	systemTemp = "/tmp"
+)
+
+var (
+	// kernel switches that disable unprivileged user namespaces, with value
+	// that means disabled
+	userNamespaceSwitches = map[string]string{
+		"/proc/sys/user/max_user_namespaces":                     "0",
+		"/proc/sys/kernel/unprivileged_userns_clone":             "0",
+		"/proc/sys/kernel/apparmor_restrict_unprivileged_userns": "1",
+	}
+
+	// mount flags which are locked in user namespace, and so must be kept
+	// when remounting
+	lockedMountFlags = map[int64]uintptr{
+		unix.ST_NOSUID:     unix.MS_NOSUID,
+		unix.ST_NODEV:      unix.MS_NODEV,
+		unix.ST_NOEXEC:     unix.MS_NOEXEC,
+		unix.ST_NOATIME:    unix.MS_NOATIME,
+		unix.ST_NODIRATIME: unix.MS_NODIRATIME,
+		unix.ST_RELATIME:   unix.MS_RELATIME,
+	}
+)
+
+func sandboxSupported() error {
+	for filename, disabled := range userNamespaceSwitches {
+		content, err := os.ReadFile(filename)
+		if err == nil && strings.TrimSpace(string(content)) == disabled {
+			return fmt.Errorf("Robot sandbox needs unprivileged user namespaces, but they are disabled by %s (value %s).", filename, disabled)
+		}
+	}
+	return nil
+}
+
+func sandboxAttributes(network, mounts bool) *syscall.SysProcAttr {
+	flags := syscall.CLONE_NEWUSER
+	if network {
+		flags |= syscall.CLONE_NEWNET
+	}
+	if mounts {
+		flags |= syscall.CLONE_NEWNS
+	}
+	return &syscall.SysProcAttr{
+		Cloneflags:  uintptr(flags),
+		UidMappings: []syscall.SysProcIDMap{{ContainerID: os.Getuid(), HostID: os.Getuid(), Size: 1}},
+		GidMappings: []syscall.SysProcIDMap{{ContainerID: os.Getgid(), HostID: os.Getgid(), Size: 1}},
+	}
+}
+
+func keptMountFlags(path string) uintptr {
+	var stat unix.Statfs_t
+	if unix.Statfs(path, &stat) != nil {
+		return 0
+	}
+	result := uintptr(0)
+	for flag, mount := range lockedMountFlags {
+		if stat.Flags&flag != 0 {
+			result |= mount
+		}
+	}
+	return result
+}
+
+// privateTemp mounts given directory over /tmp, unless something that robot
+// run needs (like robot or holotree) is inside /tmp, since mount would hide
+// it.
+func privateTemp(temporary string, needed ...string) error {
+	for _, path := range needed {
+		fullpath, err := filepath.Abs(path)
+		if err == nil && isInsideDirectory(systemTemp, fullpath) {
+			return fmt.Errorf("Could not give sandbox private %s, since %q is inside it. Move it outside of %s, or declare 'shared-tmp' capability in robot.yaml.", systemTemp, path, systemTemp)
+		}
+	}
+	err := unix.Mount(temporary, systemTemp, "", unix.MS_BIND|unix.MS_REC, "")
+	if err != nil {
+		return fmt.Errorf("Could not mount private %s in sandbox, reason: %v", systemTemp, err)
+	}
+	return nil
+}
+
+// EnterSandbox is run inside new user and mount namespace. It makes robot
+// directory read-only (keeping writable directories inside it writable),
+// mounts private temporary directory over /tmp, and then replaces itself
+// with actual robot command. Empty readonly or temporary skips that part.
+func EnterSandbox(readonly string, writable []string, temporary string, command []string) error {
+	workdir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	err = unix.Mount("", "/", "", unix.MS_REC|unix.MS_PRIVATE, "")
+	if err != nil {
+		return fmt.Errorf("Could not make sandbox mounts private, reason: %v", err)
+	}
+	if len(temporary) > 0 {
+		needed := append([]string{workdir, temporary, command[0], common.Product.Home()}, writable...)
+		if len(readonly) > 0 {
+			needed = append(needed, readonly)
+		}
+		err = privateTemp(temporary, needed...)
+		if err != nil {
+			return err
+		}
+	}
+	if len(readonly) > 0 {
+		for _, directory := range writable {
+			err = unix.Mount(directory, directory, "", unix.MS_BIND|unix.MS_REC, "")
+			if err != nil {
+				return fmt.Errorf("Could not keep %q writable in sandbox, reason: %v", directory, err)
+			}
+		}
+		err = unix.Mount(readonly, readonly, "", unix.MS_BIND|unix.MS_REC, "")
+		if err == nil {
+			flags := unix.MS_BIND | unix.MS_REMOUNT | unix.MS_RDONLY | keptMountFlags(readonly)
+			err = unix.Mount("", readonly, "", flags, "")
+		}
+		if err != nil {
+			return fmt.Errorf("Could not make %q read-only in sandbox, reason: %v", readonly, err)
+		}
+	}
+	err = os.Chdir(workdir)
+	if err != nil {
+		return err
+	}
+	return syscall.Exec(command[0], command, os.Environ())
+}
@@ -0,0 +1,57 @@
+package operations
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+	"github.com/joshyorko/rcc/robot"
+)
+
+func TestSandboxCombinesFlagAndCapabilities(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	wont.True(EffectiveSandbox(false, nil).Active())
+	must.True(EffectiveSandbox(true, nil).Active())
+	must.Equal([]string{"private tmp", "no network", "read-only robot directory"}, EffectiveSandbox(true, nil).Restrictions())
+
+	directory := t.TempDir()
+	filename := filepath.Join(directory, "robot.yaml")
+	content := "tasks:\n  run:\n    shell: python -m robot\nartifactsDir: output\nsandbox:\n  enabled: true\n  capabilities: [network, write-robot]\n"
+	must.Nil(os.WriteFile(filename, []byte(content), 0o644))
+	config, err := robot.LoadRobotYaml(filename, false)
+	must.Nil(err)
+	valid, err := config.Validate()
+	must.True(valid)
+	must.Nil(err)
+
+	sandbox := EffectiveSandbox(false, config)
+	must.True(sandbox.Active())
+	must.Equal([]string{"private tmp"}, sandbox.Restrictions())
+
+	t.Setenv("ROBOCORP_HOME", t.TempDir())
+	environment, err := sandbox.Environment([]string{"PATH=/bin"})
+	must.Nil(err)
+	must.Equal(4, len(environment))
+	must.Equal("TMPDIR="+sandbox.private, environment[1])
+	task, err := sandbox.Command([]string{"python", "-m", "robot"}, directory, filepath.Join(directory, "output"))
+	if sandboxSupported() == nil {
+		must.Nil(err)
+		must.Equal([]string{"internal", "sandbox", "--tmp", sandbox.private, "--", "python", "-m", "robot"}, task[1:])
+		wont.Nil(sandbox.Attributes())
+	} else {
+		wont.Nil(err)
+	}
+	sandbox.Cleanup()
+	_, err = os.Stat(sandbox.private)
+	must.True(os.IsNotExist(err))
+
+	content = "tasks:\n  run:\n    shell: python -m robot\nartifactsDir: output\nsandbox:\n  capabilities: [internet]\n"
+	must.Nil(os.WriteFile(filename, []byte(content), 0o644))
+	config, err = robot.LoadRobotYaml(filename, false)
+	must.Nil(err)
+	valid, err = config.Validate()
+	wont.True(valid)
+	wont.Nil(err)
+}
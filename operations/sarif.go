@@ -0,0 +1,172 @@
+package operations
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/joshyorko/rcc/common"
+)
+
+const (
+	sarifVersion = `2.1.0`
+	sarifSchema  = `https://json.schemastore.org/sarif-2.1.0.json`
+)
+
+var (
+	sarifRuleCleaner = regexp.MustCompile(`[^A-Za-z0-9]+`)
+)
+
+type (
+	SarifLog struct {
+		Version string      `json:"version"`
+		Schema  string      `json:"$schema"`
+		Runs    []*SarifRun `json:"runs"`
+	}
+	SarifRun struct {
+		Tool    SarifTool      `json:"tool"`
+		Results []*SarifResult `json:"results"`
+	}
+	SarifTool struct {
+		Driver SarifDriver `json:"driver"`
+	}
+	SarifDriver struct {
+		Name    string       `json:"name"`
+		Version string       `json:"version"`
+		Rules   []*SarifRule `json:"rules"`
+	}
+	SarifRule struct {
+		Id               string       `json:"id"`
+		Name             string       `json:"name"`
+		ShortDescription SarifMessage `json:"shortDescription"`
+		HelpUri          string       `json:"helpUri,omitempty"`
+	}
+	SarifMessage struct {
+		Text string `json:"text"`
+	}
+	SarifResult struct {
+		RuleId    string           `json:"ruleId"`
+		RuleIndex int              `json:"ruleIndex"`
+		Level     string           `json:"level"`
+		Message   SarifMessage     `json:"message"`
+		Locations []*SarifLocation `json:"locations,omitempty"`
+	}
+	SarifLocation struct {
+		PhysicalLocation SarifPhysicalLocation `json:"physicalLocation"`
+	}
+	SarifPhysicalLocation struct {
+		ArtifactLocation SarifArtifactLocation `json:"artifactLocation"`
+	}
+	SarifArtifactLocation struct {
+		Uri string `json:"uri"`
+	}
+)
+
+// SarifRuleId maps diagnostics check into SARIF rule, so that rule IDs
+// match diagnostics categories (like RCC1010), and uncategorized checks get
+// rule by their type (like RCC-Robot).
+func SarifRuleId(check *common.DiagnosticCheck) string {
+	if check.Category != common.CategoryUndefined {
+		return fmt.Sprintf("RCC%04d", check.Category)
+	}
+	return fmt.Sprintf("RCC-%s", strings.Trim(sarifRuleCleaner.ReplaceAllString(check.Type, "-"), "-"))
+}
+
+func sarifDescription(check *common.DiagnosticCheck) string {
+	if check.Category != common.CategoryUndefined {
+		return fmt.Sprintf("%s diagnostics, category %d", check.Type, check.Category)
+	}
+	return fmt.Sprintf("%s diagnostics", check.Type)
+}
+
+func sarifLevel(status string) string {
+	switch status {
+	case common.StatusFatal, common.StatusFail:
+		return "error"
+	case common.StatusWarning:
+		return "warning"
+	}
+	return "note"
+}
+
+func sarifArtifact(artifact string) string {
+	if len(artifact) == 0 {
+		return ""
+	}
+	relative, err := filepath.Rel(".", artifact)
+	if err == nil && !strings.HasPrefix(relative, "..") && !filepath.IsAbs(relative) {
+		return filepath.ToSlash(relative)
+	}
+	absolute, err := filepath.Abs(artifact)
+	if err != nil {
+		return filepath.ToSlash(artifact)
+	}
+	slashed := filepath.ToSlash(absolute)
+	if !strings.HasPrefix(slashed, "/") {
+		slashed = "/" + slashed
+	}
+	return "file://" + slashed
+}
+
+// DiagnosticsAsSarif converts problems (checks that are not ok) of
+// diagnostics into SARIF log, for CI systems to show as annotations. When
+// artifact is given, results are located into that file.
+func DiagnosticsAsSarif(status *common.DiagnosticStatus, artifact string) *SarifLog {
+	driver := SarifDriver{
+		Name:    "rcc",
+		Version: common.Version,
+		Rules:   []*SarifRule{},
+	}
+	run := &SarifRun{
+		Tool:    SarifTool{Driver: driver},
+		Results: []*SarifResult{},
+	}
+	location := sarifArtifact(artifact)
+	rules := make(map[string]int)
+	for _, check := range status.Checks {
+		if check.Status == common.StatusOk {
+			continue
+		}
+		id := SarifRuleId(check)
+		index, ok := rules[id]
+		if !ok {
+			index = len(run.Tool.Driver.Rules)
+			rules[id] = index
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, &SarifRule{
+				Id:               id,
+				Name:             check.Type,
+				ShortDescription: SarifMessage{Text: sarifDescription(check)},
+				HelpUri:          check.Link,
+			})
+		}
+		result := &SarifResult{
+			RuleId:    id,
+			RuleIndex: index,
+			Level:     sarifLevel(check.Status),
+			Message:   SarifMessage{Text: check.Message},
+		}
+		if len(location) > 0 {
+			result.Locations = []*SarifLocation{
+				{PhysicalLocation: SarifPhysicalLocation{ArtifactLocation: SarifArtifactLocation{Uri: location}}},
+			}
+		}
+		run.Results = append(run.Results, result)
+	}
+	return &SarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs:    []*SarifRun{run},
+	}
+}
+
+func WriteSarif(sink io.Writer, log *SarifLog) error {
+	body, err := json.MarshalIndent(log, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(sink, string(body))
+	return err
+}
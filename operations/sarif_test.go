@@ -0,0 +1,41 @@
+package operations
+
+import (
+	"testing"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestDiagnosticsConvertIntoSarif(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	status := &common.DiagnosticStatus{
+		Details: map[string]string{},
+		Checks:  []*common.DiagnosticCheck{},
+	}
+	status.Diagnose("OS").Ok(common.CategoryLongPath, "all good")
+	status.Diagnose("Catalog").Fail(common.CategoryCatalogIntegrity, "https://docs.example/troubleshooting", "catalog is broken")
+	status.Diagnose("Catalog").Warning(common.CategoryCatalogIntegrity, "", "catalog is suspicious")
+	status.Diagnose("settings.yaml").Fatal(common.CategoryUndefined, "", "settings are broken")
+
+	sut := DiagnosticsAsSarif(status, "robot.yaml")
+	must.Equal("2.1.0", sut.Version)
+	must.Equal(1, len(sut.Runs))
+	run := sut.Runs[0]
+	must.Equal(2, len(run.Tool.Driver.Rules))
+	must.Equal("RCC2040", run.Tool.Driver.Rules[0].Id)
+	must.Equal("https://docs.example/troubleshooting", run.Tool.Driver.Rules[0].HelpUri)
+	must.Equal("RCC-settings-yaml", run.Tool.Driver.Rules[1].Id)
+
+	must.Equal(3, len(run.Results))
+	must.Equal("error", run.Results[0].Level)
+	must.Equal("warning", run.Results[1].Level)
+	must.Equal(0, run.Results[1].RuleIndex)
+	must.Equal("error", run.Results[2].Level)
+	must.Equal(1, run.Results[2].RuleIndex)
+	must.Equal("robot.yaml", run.Results[0].Locations[0].PhysicalLocation.ArtifactLocation.Uri)
+
+	wont.Nil(DiagnosticsAsSarif(status, "").Runs[0].Results)
+	must.Nil(DiagnosticsAsSarif(status, "").Runs[0].Results[0].Locations)
+}
@@ -0,0 +1,219 @@
+package operations
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/joshyorko/rcc/cloud"
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/htfs"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/joshyorko/rcc/settings"
+)
+
+const (
+	webhookTimeout = 30 * time.Second
+)
+
+// scrubState is position of scrubber in hololib, kept between cycles.
+type scrubState struct {
+	Cursor  string    `json:"cursor"`
+	Rounds  int       `json:"rounds"`
+	Checked int       `json:"checked"`
+	Cycles  int       `json:"cycles"`
+	When    time.Time `json:"when"`
+}
+
+// ScrubSummary is health summary of one scrub cycle. Round tells how many
+// blobs of current full round over hololib are verified so far.
+type ScrubSummary struct {
+	Started    time.Time `json:"started"`
+	Elapsed    string    `json:"elapsed"`
+	Cycle      int       `json:"cycle"`
+	Checked    int       `json:"checked"`
+	Total      int       `json:"total"`
+	Round      int       `json:"round"`
+	Rounds     int       `json:"rounds"`
+	Corrupt    []string  `json:"corrupt"`
+	Missing    []string  `json:"missing"`
+	Repaired   []string  `json:"repaired"`
+	Unrepaired []string  `json:"unrepaired"`
+}
+
+// Healthy tells that cycle found no problems, or that all were repaired.
+func (it *ScrubSummary) Healthy() bool {
+	return len(it.Unrepaired) == 0
+}
+
+func (it *ScrubSummary) String() string {
+	return fmt.Sprintf("checked %d blobs (round %d/%d, %d rounds done), %d corrupt, %d missing, %d catalogs repaired, %d unrepaired", it.Checked, it.Round, it.Total, it.Rounds, len(it.Corrupt), len(it.Missing), len(it.Repaired), len(it.Unrepaired))
+}
+
+func loadScrubState() *scrubState {
+	result := &scrubState{}
+	content, err := os.ReadFile(common.HololibScrubLocation())
+	if err == nil {
+		err = json.Unmarshal(content, result)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		common.Debug("Ignoring scrub state %q, reason: %v", common.HololibScrubLocation(), err)
+		return &scrubState{}
+	}
+	return result
+}
+
+func (it *scrubState) save() error {
+	content, err := json.MarshalIndent(it, "", "  ")
+	if err != nil {
+		return err
+	}
+	return pathlib.WriteFile(common.HololibScrubLocation(), content, 0o644)
+}
+
+// brokenCatalogs groups problem blobs by catalogs that reference them.
+func brokenCatalogs(known map[string]map[string]bool, corrupt, missing []string) map[string]*CatalogHealth {
+	result := make(map[string]*CatalogHealth)
+	mark := func(digest string, corrupted bool) {
+		for catalog := range known[digest] {
+			name := filepath.Base(catalog)
+			health, ok := result[name]
+			if !ok {
+				health = &CatalogHealth{Catalog: name, Missing: []string{}, Corrupt: []string{}}
+				result[name] = health
+			}
+			if corrupted {
+				health.Corrupt = append(health.Corrupt, digest)
+			} else {
+				health.Missing = append(health.Missing, digest)
+			}
+		}
+	}
+	for _, digest := range corrupt {
+		mark(digest, true)
+	}
+	for _, digest := range missing {
+		mark(digest, false)
+	}
+	return result
+}
+
+// ScrubCycle verifies next batch of hololib blobs, and repairs catalogs with
+// corrupted or missing blobs from RCC_REMOTE_ORIGIN, when it is configured.
+// Summary is recorded into journal, and posted to webhook, if one is given.
+func ScrubCycle(batch int, webhook string) (*ScrubSummary, error) {
+	common.TimelineBegin("holotree scrub cycle start")
+	defer common.TimelineEnd()
+
+	summary := &ScrubSummary{
+		Started:    time.Now().Truncate(time.Second),
+		Repaired:   []string{},
+		Unrepaired: []string{},
+	}
+	state := loadScrubState()
+
+	// verification is done under holotree lock, so that catalogs being
+	// recorded (and their blobs lifted) are not seen as half done; repairs
+	// take that lock themselves, so it is released before them
+	lockfile := common.HolotreeLock()
+	completed := pathlib.LockWaitMessage(lockfile, "Serialized holotree scrub [holotree lock]")
+	locker, err := pathlib.Locker(lockfile, 30000, common.SharedHolotree)
+	completed()
+	if err != nil {
+		return nil, fmt.Errorf("Could not get lock for holotree, reason: %v", err)
+	}
+	htfs.RecoverMutations()
+	known, _ := htfs.LoadHololibHashes()
+	selected, cursor, wrapped := htfs.ScrubSelection(known, state.Cursor, batch)
+	summary.Corrupt, summary.Missing = htfs.ScrubBlobs(selected)
+	summary.Checked, summary.Total = len(selected), len(known)
+	broken := brokenCatalogs(known, summary.Corrupt, summary.Missing)
+	locker.Release()
+
+	origin := common.RccRemoteOrigin()
+	canRepair := len(origin) > 0 && !settings.Global.Offline()
+	for name, health := range broken {
+		if !canRepair {
+			summary.Unrepaired = append(summary.Unrepaired, name)
+			continue
+		}
+		err := RepairCatalog(health)
+		if err != nil {
+			pretty.Warning("Could not repair catalog %q, reason: %v", name, err)
+			summary.Unrepaired = append(summary.Unrepaired, name)
+		} else {
+			summary.Repaired = append(summary.Repaired, name)
+		}
+	}
+	sort.Strings(summary.Repaired)
+	sort.Strings(summary.Unrepaired)
+	if len(broken) > 0 && !canRepair {
+		pretty.Warning("%d catalogs have broken blobs, and without %s (or in offline mode) they cannot be repaired.", len(broken), common.RCC_REMOTE_ORIGIN)
+	}
+
+	state.Cycles++
+	state.Checked += len(selected)
+	if wrapped {
+		state.Rounds++
+		state.Checked = 0
+	}
+	state.Cursor, state.When = cursor, summary.Started
+	summary.Cycle, summary.Rounds = state.Cycles, state.Rounds
+	summary.Round = min(state.Checked, summary.Total)
+	summary.Elapsed = time.Since(summary.Started).Round(time.Millisecond).String()
+	err = state.save()
+	if err != nil {
+		return summary, fmt.Errorf("Could not save scrub state, reason: %v", err)
+	}
+	common.RunJournal("holotree scrub", fmt.Sprintf("cycle %d", summary.Cycle), "%s", summary)
+	if len(webhook) > 0 {
+		err = postScrubSummary(webhook, summary)
+		if err != nil {
+			pretty.Warning("Could not post scrub summary to webhook, reason: %v", err)
+		}
+	}
+	return summary, nil
+}
+
+func postScrubSummary(webhook string, summary *ScrubSummary) error {
+	client, err := cloud.NewClient(webhook)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+	request := client.NewRequest("")
+	request.Headers[contentType] = applicationJson
+	request.Body = bytes.NewReader(body)
+	response := client.WithTimeout(webhookTimeout).Uncritical().Post(request)
+	if response.Err != nil {
+		return response.Err
+	}
+	if response.Status < 200 || response.Status > 299 {
+		return fmt.Errorf("Webhook answered with status %d.", response.Status)
+	}
+	return nil
+}
+
+// ScrubDaemon runs scrub cycles with given interval between them, until
+// process is stopped. Failed cycles are only warned about.
+func ScrubDaemon(batch int, interval time.Duration, webhook string, report func(*ScrubSummary)) {
+	for {
+		summary, err := ScrubCycle(batch, webhook)
+		if err != nil {
+			pretty.Warning("Scrub cycle failed, reason: %v", err)
+		}
+		if summary != nil {
+			report(summary)
+		}
+		common.Log("Next scrub cycle in %s, at %s.", interval, time.Now().Add(interval).Format(time.DateTime))
+		time.Sleep(interval)
+	}
+}
@@ -0,0 +1,48 @@
+package operations
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestBrokenCatalogsGroupsBlobsByCatalog(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	known := map[string]map[string]bool{
+		"aaaa": {"/hololib/catalog/first.linux_amd64": true, "/hololib/catalog/second.linux_amd64": true},
+		"bbbb": {"/hololib/catalog/second.linux_amd64": true},
+		"cccc": {"/hololib/catalog/third.linux_amd64": true},
+	}
+	broken := brokenCatalogs(known, []string{"aaaa"}, []string{"bbbb"})
+	must.Equal(2, len(broken))
+	must.Equal([]string{"aaaa"}, broken["first.linux_amd64"].Corrupt)
+	must.Equal([]string{"aaaa"}, broken["second.linux_amd64"].Corrupt)
+	must.Equal([]string{"bbbb"}, broken["second.linux_amd64"].Missing)
+	must.True(broken["second.linux_amd64"].Broken())
+}
+
+func TestScrubSummaryIsPostedToWebhook(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	received := &ScrubSummary{}
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost || request.URL.Path != "/hook" {
+			writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewDecoder(request.Body).Decode(received)
+		writer.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	summary := &ScrubSummary{Cycle: 3, Checked: 10, Unrepaired: []string{"first.linux_amd64"}}
+	must.Nil(postScrubSummary(server.URL+"/hook", summary))
+	must.Equal(3, received.Cycle)
+	must.Equal([]string{"first.linux_amd64"}, received.Unrepaired)
+	wont.True(received.Healthy())
+	wont.Nil(postScrubSummary(server.URL+"/other", summary))
+}
@@ -0,0 +1,347 @@
+package operations
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/pretty"
+	"gopkg.in/yaml.v2"
+)
+
+// ServerProfile is named rccremote server, so that its origin (and domain and
+// authorization) do not have to be typed again and again.
+type ServerProfile struct {
+	Name          string `yaml:"name" json:"name"`
+	Description   string `yaml:"description,omitempty" json:"description,omitempty"`
+	Origin        string `yaml:"origin" json:"origin"`
+	Domain        string `yaml:"domain,omitempty" json:"domain,omitempty"`
+	Authorization string `yaml:"authorization,omitempty" json:"-"`
+	Sealed        string `yaml:"sealed-authorization,omitempty" json:"-"`
+}
+
+// serverProfiles is format of both saved profiles in ROBOCORP_HOME, and
+// exported (shared) profile files.
+type serverProfiles struct {
+	Servers []*ServerProfile `yaml:"servers"`
+}
+
+// ServerMerge tells what happened when profiles were imported.
+type ServerMerge struct {
+	Added     []string `json:"added"`
+	Replaced  []string `json:"replaced"`
+	Kept      []string `json:"kept"`
+	Unchanged []string `json:"unchanged"`
+}
+
+func (it *ServerProfile) String() string {
+	if len(it.Domain) > 0 {
+		return fmt.Sprintf("%s (%s, domain %s)", it.Name, it.Origin, it.Domain)
+	}
+	return fmt.Sprintf("%s (%s)", it.Name, it.Origin)
+}
+
+// Same tells if profiles have same content.
+func (it *ServerProfile) Same(other *ServerProfile) bool {
+	return it.Name == other.Name && it.Description == other.Description && it.Origin == other.Origin && it.Domain == other.Domain && it.Authorization == other.Authorization
+}
+
+// Validate checks that profile can be saved.
+func (it *ServerProfile) Validate() error {
+	if len(strings.TrimSpace(it.Name)) == 0 || strings.ContainsAny(it.Name, " \t/\\") {
+		return fmt.Errorf("Server profile name %q must be nonempty and without spaces or slashes.", it.Name)
+	}
+	if !strings.HasPrefix(it.Origin, "http://") && !strings.HasPrefix(it.Origin, "https://") {
+		return fmt.Errorf("Origin %q of server profile %q must be http(s) URL.", it.Origin, it.Name)
+	}
+	return nil
+}
+
+// Apply makes profile active for rest of this rcc run, same as if its
+// values were given in RCC_REMOTE_* environment variables.
+func (it *ServerProfile) Apply() {
+	os.Setenv(common.RCC_REMOTE_ORIGIN, it.Origin)
+	if len(it.Domain) > 0 {
+		os.Setenv(common.RCC_REMOTE_DOMAIN, it.Domain)
+	}
+	if len(it.Authorization) > 0 {
+		os.Setenv(common.RCC_REMOTE_AUTHORIZATION, it.Authorization)
+	}
+}
+
+func passphraseCipher(passphrase string) (cipher.AEAD, error) {
+	digest := sha256.Sum256([]byte(passphrase))
+	block, err := aes.NewCipher(digest[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func (it *ServerProfile) seal(passphrase string) error {
+	if len(it.Authorization) == 0 {
+		return nil
+	}
+	aead, err := passphraseCipher(passphrase)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(it.Authorization), []byte(it.Name))
+	it.Sealed = base64.StdEncoding.EncodeToString(sealed)
+	it.Authorization = ""
+	return nil
+}
+
+func (it *ServerProfile) open(passphrase string) error {
+	if len(it.Sealed) == 0 {
+		return nil
+	}
+	sealed, err := base64.StdEncoding.DecodeString(it.Sealed)
+	if err != nil {
+		return err
+	}
+	aead, err := passphraseCipher(passphrase)
+	if err != nil {
+		return err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return fmt.Errorf("Authorization of server profile %q is truncated.", it.Name)
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	secret, err := aead.Open(nil, nonce, ciphertext, []byte(it.Name))
+	if err != nil {
+		return fmt.Errorf("Could not decrypt authorization of server profile %q (wrong passphrase?), reason: %v", it.Name, err)
+	}
+	it.Authorization, it.Sealed = string(secret), ""
+	return nil
+}
+
+func loadServerProfiles(filename string) (*serverProfiles, error) {
+	result := &serverProfiles{Servers: []*ServerProfile{}}
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	err = yaml.Unmarshal(content, result)
+	if err != nil {
+		return nil, fmt.Errorf("Server profiles %q are not valid, reason: %v", filename, err)
+	}
+	for _, profile := range result.Servers {
+		err = profile.Validate()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (it *serverProfiles) find(name string) (*ServerProfile, int) {
+	for at, profile := range it.Servers {
+		if profile.Name == name {
+			return profile, at
+		}
+	}
+	return nil, -1
+}
+
+// saveAs writes profiles sorted by name, and readable only by owner, since
+// they may contain authorizations.
+func (it *serverProfiles) saveAs(filename string) error {
+	sort.Slice(it.Servers, func(left, right int) bool {
+		return it.Servers[left].Name < it.Servers[right].Name
+	})
+	content, err := yaml.Marshal(it)
+	if err != nil {
+		return err
+	}
+	return pathlib.WriteFile(filename, content, 0o600)
+}
+
+func savedServerProfiles() (*serverProfiles, error) {
+	result, err := loadServerProfiles(common.ServerProfilesLocation())
+	if errors.Is(err, os.ErrNotExist) {
+		return &serverProfiles{Servers: []*ServerProfile{}}, nil
+	}
+	return result, err
+}
+
+// ServerProfiles gives saved server profiles, sorted by name.
+func ServerProfiles() ([]*ServerProfile, error) {
+	saved, err := savedServerProfiles()
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(saved.Servers, func(left, right int) bool {
+		return saved.Servers[left].Name < saved.Servers[right].Name
+	})
+	return saved.Servers, nil
+}
+
+// ServerProfileByName gives saved server profile with given name.
+func ServerProfileByName(name string) (*ServerProfile, error) {
+	saved, err := savedServerProfiles()
+	if err != nil {
+		return nil, err
+	}
+	profile, _ := saved.find(name)
+	if profile == nil {
+		return nil, fmt.Errorf("No server profile named %q. See: rcc interactive servers", name)
+	}
+	return profile, nil
+}
+
+// SaveServerProfile adds new, or replaces existing server profile.
+func SaveServerProfile(profile *ServerProfile) error {
+	err := profile.Validate()
+	if err != nil {
+		return err
+	}
+	saved, err := savedServerProfiles()
+	if err != nil {
+		return err
+	}
+	_, at := saved.find(profile.Name)
+	if at < 0 {
+		saved.Servers = append(saved.Servers, profile)
+	} else {
+		saved.Servers[at] = profile
+	}
+	return saved.saveAs(common.ServerProfilesLocation())
+}
+
+// RemoveServerProfile removes saved server profile with given name.
+func RemoveServerProfile(name string) error {
+	saved, err := savedServerProfiles()
+	if err != nil {
+		return err
+	}
+	_, at := saved.find(name)
+	if at < 0 {
+		return fmt.Errorf("No server profile named %q.", name)
+	}
+	saved.Servers = slices.Delete(saved.Servers, at, at+1)
+	return saved.saveAs(common.ServerProfilesLocation())
+}
+
+// ExportServerProfiles writes named (or all, when no names are given) saved
+// profiles into sharable file. Authorizations are stripped, unless
+// passphrase is given, in which case they are encrypted with it.
+func ExportServerProfiles(filename string, names []string, passphrase string) (int, error) {
+	saved, err := savedServerProfiles()
+	if err != nil {
+		return 0, err
+	}
+	exported := &serverProfiles{Servers: []*ServerProfile{}}
+	for _, profile := range saved.Servers {
+		if len(names) > 0 && !slices.Contains(names, profile.Name) {
+			continue
+		}
+		copied := *profile
+		if len(passphrase) == 0 {
+			copied.Authorization = ""
+		} else if err := copied.seal(passphrase); err != nil {
+			return 0, err
+		}
+		exported.Servers = append(exported.Servers, &copied)
+	}
+	for _, name := range names {
+		if found, _ := exported.find(name); found == nil {
+			return 0, fmt.Errorf("No server profile named %q.", name)
+		}
+	}
+	if len(exported.Servers) == 0 {
+		return 0, fmt.Errorf("No server profiles to export.")
+	}
+	return len(exported.Servers), exported.saveAs(filename)
+}
+
+// ReadServerProfiles reads shared profile file. Encrypted authorizations are
+// opened with passphrase, or dropped (with warning) when there is none.
+func ReadServerProfiles(filename, passphrase string) ([]*ServerProfile, error) {
+	shared, err := loadServerProfiles(filename)
+	if err != nil {
+		return nil, err
+	}
+	for _, profile := range shared.Servers {
+		if len(profile.Sealed) > 0 && len(passphrase) == 0 {
+			pretty.Warning("Server profile %q has encrypted authorization, but no passphrase was given, so it is not imported.", profile.Name)
+			profile.Sealed = ""
+			continue
+		}
+		err = profile.open(passphrase)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return shared.Servers, nil
+}
+
+// MergeServerProfiles merges incoming profiles into saved ones. New profiles
+// are added, and identical ones left as they are. When saved profile with
+// same name differs, replace decides if it is replaced or kept.
+func MergeServerProfiles(incoming []*ServerProfile, replace func(saved, incoming *ServerProfile) bool) (*ServerMerge, error) {
+	saved, err := savedServerProfiles()
+	if err != nil {
+		return nil, err
+	}
+	result := &ServerMerge{Added: []string{}, Replaced: []string{}, Kept: []string{}, Unchanged: []string{}}
+	for _, profile := range incoming {
+		existing, at := saved.find(profile.Name)
+		switch {
+		case existing == nil:
+			saved.Servers = append(saved.Servers, profile)
+			result.Added = append(result.Added, profile.Name)
+		case existing.Same(profile):
+			result.Unchanged = append(result.Unchanged, profile.Name)
+		case replace(existing, profile):
+			saved.Servers[at] = profile
+			result.Replaced = append(result.Replaced, profile.Name)
+		default:
+			result.Kept = append(result.Kept, profile.Name)
+		}
+	}
+	if len(result.Added)+len(result.Replaced) == 0 {
+		return result, nil
+	}
+	return result, saved.saveAs(common.ServerProfilesLocation())
+}
+
+// ServerProfileTable gives saved server profiles as table.
+func ServerProfileTable(profiles []*ServerProfile) *pretty.Table {
+	table := pretty.NewTable(
+		&pretty.TableColumn{Name: "name", Title: "Name"},
+		&pretty.TableColumn{Name: "origin", Title: "Origin"},
+		&pretty.TableColumn{Name: "domain", Title: "Domain"},
+		&pretty.TableColumn{Name: "authorization", Title: "Authorization"},
+		&pretty.TableColumn{Name: "description", Title: "Description"},
+	)
+	for _, profile := range profiles {
+		authorization := "no"
+		if len(profile.Authorization) > 0 {
+			authorization = "yes"
+		}
+		table.Add(pretty.TableRow{
+			"name":          profile.Name,
+			"origin":        profile.Origin,
+			"domain":        profile.Domain,
+			"authorization": authorization,
+			"description":   profile.Description,
+		})
+	}
+	return table
+}
@@ -0,0 +1,75 @@
+package operations
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestServerProfilesCanBeSharedAndMerged(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	t.Setenv("ROBOCORP_HOME", t.TempDir())
+	wont.Nil(SaveServerProfile(&ServerProfile{Name: "bad name", Origin: "https://example.com"}))
+	wont.Nil(SaveServerProfile(&ServerProfile{Name: "team", Origin: "example.com"}))
+	must.Nil(SaveServerProfile(&ServerProfile{Name: "team", Origin: "https://team.example.com", Authorization: "secret"}))
+	must.Nil(SaveServerProfile(&ServerProfile{Name: "local", Origin: "http://127.0.0.1:4653", Domain: "robots"}))
+
+	shared := filepath.Join(t.TempDir(), "servers.yaml")
+	count, err := ExportServerProfiles(shared, nil, "")
+	must.Nil(err)
+	must.Equal(2, count)
+	content, err := os.ReadFile(shared)
+	must.Nil(err)
+	wont.True(strings.Contains(string(content), "secret"))
+
+	count, err = ExportServerProfiles(shared, []string{"team"}, "passphrase")
+	must.Nil(err)
+	must.Equal(1, count)
+	content, err = os.ReadFile(shared)
+	must.Nil(err)
+	wont.True(strings.Contains(string(content), "secret"))
+	must.True(strings.Contains(string(content), "sealed-authorization"))
+	_, err = ExportServerProfiles(shared, []string{"missing"}, "")
+	wont.Nil(err)
+
+	_, err = ReadServerProfiles(shared, "wrong")
+	wont.Nil(err)
+	stripped, err := ReadServerProfiles(shared, "")
+	must.Nil(err)
+	must.Equal("", stripped[0].Authorization)
+	incoming, err := ReadServerProfiles(shared, "passphrase")
+	must.Nil(err)
+	must.Equal(1, len(incoming))
+	must.Equal("secret", incoming[0].Authorization)
+
+	must.Nil(RemoveServerProfile("team"))
+	wont.Nil(RemoveServerProfile("team"))
+	merge, err := MergeServerProfiles(incoming, func(saved, incoming *ServerProfile) bool { return true })
+	must.Nil(err)
+	must.Equal([]string{"team"}, merge.Added)
+	merge, err = MergeServerProfiles(incoming, func(saved, incoming *ServerProfile) bool { return true })
+	must.Nil(err)
+	must.Equal([]string{"team"}, merge.Unchanged)
+
+	changed := *incoming[0]
+	changed.Origin = "https://other.example.com"
+	merge, err = MergeServerProfiles([]*ServerProfile{&changed}, func(saved, incoming *ServerProfile) bool { return false })
+	must.Nil(err)
+	must.Equal([]string{"team"}, merge.Kept)
+	profile, err := ServerProfileByName("team")
+	must.Nil(err)
+	must.Equal("https://team.example.com", profile.Origin)
+
+	merge, err = MergeServerProfiles([]*ServerProfile{&changed}, func(saved, incoming *ServerProfile) bool { return true })
+	must.Nil(err)
+	must.Equal([]string{"team"}, merge.Replaced)
+	profiles, err := ServerProfiles()
+	must.Nil(err)
+	must.Equal(2, len(profiles))
+	must.Equal("local", profiles[0].Name)
+	must.Equal("https://other.example.com", profiles[1].Origin)
+}
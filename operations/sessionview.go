@@ -0,0 +1,74 @@
+package operations
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// SessionView is last opened interactive view, with its arguments and flags
+// (including search, toggles, and selection changed inside view), so that
+// interrupted workflow can be resumed where it was left off.
+type SessionView struct {
+	View      string            `yaml:"view"`
+	Arguments []string          `yaml:"arguments,omitempty"`
+	Flags     map[string]string `yaml:"flags,omitempty"`
+	When      time.Time         `yaml:"when"`
+}
+
+func (it *SessionView) String() string {
+	parts := []string{it.View}
+	for _, name := range slices.Sorted(maps.Keys(it.Flags)) {
+		parts = append(parts, fmt.Sprintf("--%s %q", name, it.Flags[name]))
+	}
+	for _, argument := range it.Arguments {
+		parts = append(parts, fmt.Sprintf("%q", argument))
+	}
+	return strings.Join(parts, " ")
+}
+
+// RememberView stores opened interactive view as last view of session.
+func RememberView(view string, arguments []string, flags map[string]string) error {
+	session := loadInteractiveSession()
+	session.LastView = &SessionView{
+		View:      view,
+		Arguments: arguments,
+		Flags:     flags,
+		When:      time.Now().Truncate(time.Second),
+	}
+	return session.save()
+}
+
+// RememberViewFlag updates flag of last view, when state (like search text)
+// changes inside view. Empty value removes flag.
+func RememberViewFlag(name, value string) error {
+	session := loadInteractiveSession()
+	if session.LastView == nil {
+		return nil
+	}
+	if session.LastView.Flags == nil {
+		session.LastView.Flags = make(map[string]string)
+	}
+	if len(value) == 0 {
+		delete(session.LastView.Flags, name)
+	} else {
+		session.LastView.Flags[name] = value
+	}
+	session.LastView.When = time.Now().Truncate(time.Second)
+	return session.save()
+}
+
+// LastView gives last interactive view of previous session, if there is one.
+func LastView() (*SessionView, bool) {
+	view := loadInteractiveSession().LastView
+	return view, view != nil && len(view.View) > 0
+}
+
+// ForgetView removes last view, so that next session starts fresh.
+func ForgetView() error {
+	session := loadInteractiveSession()
+	session.LastView = nil
+	return session.save()
+}
@@ -0,0 +1,40 @@
+package operations
+
+import (
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestLastViewCanBeResumedAndForgotten(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	t.Setenv("ROBOCORP_HOME", t.TempDir())
+	_, ok := LastView()
+	wont.True(ok)
+	must.Nil(RememberViewFlag("filter", "ignored"))
+	_, ok = LastView()
+	wont.True(ok)
+
+	must.Nil(RememberRobotSort("modified"))
+	must.Nil(RememberView("robots", nil, map[string]string{"directory": "monorepo"}))
+	must.Nil(RememberViewFlag("filter", "invoice"))
+	must.Nil(RememberViewFlag("stale", "true"))
+	must.Nil(RememberViewFlag("stale", ""))
+	view, ok := LastView()
+	must.True(ok)
+	must.Equal("robots", view.View)
+	must.Equal(map[string]string{"directory": "monorepo", "filter": "invoice"}, view.Flags)
+	must.Equal(`robots --directory "monorepo" --filter "invoice"`, view.String())
+	must.Equal("modified", RememberedRobotSort())
+
+	must.Nil(RememberView("compare", []string{"left", "right"}, nil))
+	view, ok = LastView()
+	must.True(ok)
+	must.Equal(`compare "left" "right"`, view.String())
+
+	must.Nil(ForgetView())
+	_, ok = LastView()
+	wont.True(ok)
+	must.Equal("modified", RememberedRobotSort())
+}
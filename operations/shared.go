@@ -0,0 +1,42 @@
+package operations
+
+import (
+	"path/filepath"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/htfs"
+	"github.com/joshyorko/rcc/robot"
+)
+
+// UseSharedEnvironment switches holotree space to space of shared environment
+// of robot (if it has one), so that all robots referencing same definition
+// resolve to same blueprint and space. Holotree lock makes sure that shared
+// environment is built only once, and other robots just reuse it.
+func UseSharedEnvironment(config robot.Robot) (*robot.SharedEnvironment, error) {
+	shared, err := config.SharedEnvironment()
+	if err != nil || shared == nil {
+		return shared, err
+	}
+	if common.HolotreeSpace != shared.Space {
+		common.Debug("Space %q replaced by shared environment %q space %q.", common.HolotreeSpace, shared.Name, shared.Space)
+	}
+	common.HolotreeSpace = shared.Space
+	return shared, nil
+}
+
+// SharedSpaceLocation is location of holotree space of shared environment
+// for current controller.
+func SharedSpaceLocation(shared *robot.SharedEnvironment) string {
+	name := htfs.ControllerSpaceName([]byte(common.ControllerIdentity()), []byte(shared.Space))
+	return filepath.Join(common.HolotreeLocation(), name)
+}
+
+// RecordRobotUsage remembers which robot used which space, so that consumers
+// of shared environments can be listed.
+func RecordRobotUsage(space, robotfile string) {
+	fullpath, err := filepath.Abs(robotfile)
+	if err != nil {
+		fullpath = robotfile
+	}
+	htfs.RecordSpaceRobot(space, fullpath)
+}
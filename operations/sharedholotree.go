@@ -0,0 +1,65 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/shell"
+)
+
+// SharedHolotreeState tells if shared holotree is enabled on this machine,
+// and if current user has initialized its use.
+func SharedHolotreeState() (enabled bool, initialized bool) {
+	return pathlib.IsFile(common.SharedMarkerLocation()), pathlib.IsFile(common.HoloInitUserFile())
+}
+
+// SharedHolotreeChanges describes what enabling shared holotree changes on
+// this machine, for user to review before elevation.
+func SharedHolotreeChanges(enabled bool) []string {
+	result := []string{}
+	if !enabled {
+		result = append(result,
+			fmt.Sprintf("with elevated rights: create %q, writable by all users", common.Product.HoloLocation()),
+			fmt.Sprintf("with elevated rights: write marker file %q", common.SharedMarkerLocation()))
+	}
+	return append(result,
+		fmt.Sprintf("as current user: initialize shared holotree use in %q", common.HoloInitLocation()),
+		fmt.Sprintf("environments are then built into %q instead of %s", common.Product.HoloLocation(), common.Product.HomeVariable()))
+}
+
+// EnableSharedHolotree enables shared holotree with elevated rights (unless
+// it is already enabled), and then initializes it for current user, by
+// running rcc itself with "holotree shared" and "holotree init" commands.
+func EnableSharedHolotree(enabled bool) error {
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		command, err := elevatedCommand(self, "holotree", "shared", "--enable", "--once")
+		if err != nil {
+			return err
+		}
+		common.Debug("Enabling shared holotree with %q.", command)
+		code, err := shell.New(nil, ".", command...).Transparent()
+		if err != nil || code != 0 {
+			return fmt.Errorf("Enabling shared holotree failed with exit code %d, reason: %v", code, err)
+		}
+	}
+	code, err := shell.New(nil, ".", self, "holotree", "init").Transparent()
+	if err != nil || code != 0 {
+		return fmt.Errorf("Initializing shared holotree failed with exit code %d, reason: %v", code, err)
+	}
+	return nil
+}
+
+// SharedHolotreeChecks verifies shared holotree setup with same checks as
+// diagnostics uses.
+func SharedHolotreeChecks() []*common.DiagnosticCheck {
+	return []*common.DiagnosticCheck{
+		verifySharedDirectory(common.Product.HoloLocation()),
+		verifySharedDirectory(common.HoloInitLocation()),
+	}
+}
@@ -0,0 +1,18 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+func elevatedCommand(command ...string) ([]string, error) {
+	if os.Geteuid() == 0 {
+		return command, nil
+	}
+	sudo, err := exec.LookPath("sudo")
+	if err != nil {
+		return nil, fmt.Errorf("Elevation needs 'sudo', which is not available. Run %q as root instead.", command)
+	}
+	return append([]string{sudo}, command...), nil
+}
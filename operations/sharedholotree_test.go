@@ -0,0 +1,21 @@
+package operations
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestSharedHolotreeChangesDependOnState(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	disabled := SharedHolotreeChanges(false)
+	enabled := SharedHolotreeChanges(true)
+	must.Equal(4, len(disabled))
+	must.Equal(2, len(enabled))
+	must.True(strings.HasPrefix(disabled[0], "with elevated rights:"))
+	for _, change := range enabled {
+		wont.True(strings.Contains(change, "elevated"))
+	}
+}
@@ -0,0 +1,16 @@
+package operations
+
+import (
+	"fmt"
+	"strings"
+)
+
+func powershellQuoted(text string) string {
+	return "'" + strings.ReplaceAll(text, "'", "''") + "'"
+}
+
+func elevatedCommand(command ...string) ([]string, error) {
+	arguments := strings.Join(command[1:], " ")
+	script := fmt.Sprintf("$process = Start-Process -FilePath %s -ArgumentList %s -Verb RunAs -Wait -PassThru; exit $process.ExitCode", powershellQuoted(command[0]), powershellQuoted(arguments))
+	return []string{"powershell", "-NoProfile", "-NonInteractive", "-Command", script}, nil
+}
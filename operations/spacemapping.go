@@ -0,0 +1,148 @@
+package operations
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/htfs"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/pretty"
+)
+
+// SpaceRobotMatch is robot that produced holotree space. Current is true,
+// when robot environment definition still gives blueprint of that space, and
+// false, when robot has used space, but its definition has changed since.
+type SpaceRobotMatch struct {
+	Robot   *RobotEntry
+	Current bool
+}
+
+// SpaceMapping tells which robots (and so, which conda.yaml files) are
+// behind one holotree space.
+type SpaceMapping struct {
+	Space      string
+	Controller string
+	Path       string
+	Blueprint  string
+	Matches    []*SpaceRobotMatch
+}
+
+func (it *SpaceMapping) add(entry *RobotEntry, current bool) {
+	for _, match := range it.Matches {
+		if match.Robot.Path == entry.Path {
+			match.Current = match.Current || current
+			return
+		}
+	}
+	it.Matches = append(it.Matches, &SpaceRobotMatch{Robot: entry, Current: current})
+}
+
+func fullRobotPath(robotfile string) string {
+	fullpath, err := filepath.Abs(robotfile)
+	if err != nil {
+		return robotfile
+	}
+	return fullpath
+}
+
+// MapSpacesToRobots correlates holotree spaces with known robots. Robot
+// matches space, when blueprint of its environment is same as blueprint of
+// space, or when it is recorded as user of that space. Recorded robots outside
+// of known robots are included, if their robot.yaml still exists.
+func MapSpacesToRobots(spaces htfs.Roots, entries []*RobotEntry) []*SpaceMapping {
+	known := make(map[string]*RobotEntry)
+	byBlueprint := make(map[string][]*RobotEntry)
+	for _, entry := range entries {
+		known[fullRobotPath(entry.Path)] = entry
+		if len(entry.Blueprint) > 0 {
+			byBlueprint[entry.Blueprint] = append(byBlueprint[entry.Blueprint], entry)
+		}
+	}
+	tree, err := htfs.New()
+	if err != nil {
+		tree = nil
+	}
+	result := make([]*SpaceMapping, 0, len(spaces))
+	for _, space := range spaces {
+		mapping := &SpaceMapping{
+			Space:      space.Space,
+			Controller: space.Controller,
+			Path:       space.Path,
+			Blueprint:  space.Blueprint,
+			Matches:    []*SpaceRobotMatch{},
+		}
+		for _, entry := range byBlueprint[space.Blueprint] {
+			mapping.add(entry, true)
+		}
+		for _, recorded := range htfs.LoadSpaceRobots(space.Path).Sorted() {
+			entry, ok := known[recorded.Robot]
+			if !ok {
+				if !pathlib.IsFile(recorded.Robot) {
+					continue
+				}
+				entry = robotEntryFor(tree, recorded.Robot)
+				known[recorded.Robot] = entry
+			}
+			mapping.add(entry, entry.Blueprint == space.Blueprint)
+		}
+		result = append(result, mapping)
+	}
+	sort.SliceStable(result, func(left, right int) bool {
+		return result[left].Path < result[right].Path
+	})
+	return result
+}
+
+// FilterSpaceMappings keeps spaces whose name, path, blueprint, or matching
+// robot path contains search text (case insensitive).
+func FilterSpaceMappings(mappings []*SpaceMapping, search string) []*SpaceMapping {
+	needle := strings.ToLower(search)
+	if len(needle) == 0 {
+		return mappings
+	}
+	result := make([]*SpaceMapping, 0, len(mappings))
+	for _, mapping := range mappings {
+		haystack := []string{mapping.Space, mapping.Path, mapping.Blueprint}
+		for _, match := range mapping.Matches {
+			haystack = append(haystack, match.Robot.Path)
+		}
+		if strings.Contains(strings.ToLower(strings.Join(haystack, "\n")), needle) {
+			result = append(result, mapping)
+		}
+	}
+	return result
+}
+
+func spaceRobotNames(mapping *SpaceMapping) string {
+	if len(mapping.Matches) == 0 {
+		return pretty.Grey + "no known robot" + pretty.Reset
+	}
+	names := make([]string, 0, len(mapping.Matches))
+	for _, match := range mapping.Matches {
+		if match.Current {
+			names = append(names, pretty.Green+match.Robot.Name+pretty.Reset)
+		} else {
+			names = append(names, pretty.Yellow+match.Robot.Name+" (changed)"+pretty.Reset)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+func ShowSpaceMappings(mappings []*SpaceMapping, search string) {
+	common.Stdout("%s%-10s%s search: %s%q%s\n\n", pretty.White, "SPACES", pretty.Reset, pretty.Cyan, search, pretty.Reset)
+	if len(mappings) == 0 {
+		common.Stdout("  %sno matching spaces%s\n\n", pretty.Grey, pretty.Reset)
+		return
+	}
+	compact := pretty.Compact()
+	for at, mapping := range mappings {
+		if compact {
+			common.Stdout("  %s%3d%s %s -> %s\n", pretty.Cyan, at+1, pretty.Reset, mapping.Space, spaceRobotNames(mapping))
+			continue
+		}
+		common.Stdout("  %s%3d%s %-20s %s%s%s %s\n", pretty.Cyan, at+1, pretty.Reset, mapping.Space, pretty.Grey, mapping.Blueprint, pretty.Reset, spaceRobotNames(mapping))
+	}
+	common.Stdout("\n")
+}
@@ -0,0 +1,51 @@
+package operations
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+	"github.com/joshyorko/rcc/htfs"
+)
+
+func spaceRoot(space, path, blueprint string) *htfs.Root {
+	return &htfs.Root{Info: &htfs.Info{Space: space, Controller: "user", Path: path, Blueprint: blueprint}}
+}
+
+func TestSpacesAreMappedToRobotsByBlueprintAndUse(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	t.Setenv("ROBOCORP_HOME", t.TempDir())
+	holotree := t.TempDir()
+	invoice := spaceRoot("invoice", filepath.Join(holotree, "invoice"), "aaaa")
+	orphan := spaceRoot("orphan", filepath.Join(holotree, "orphan"), "cccc")
+	legacy := spaceRoot("legacy", filepath.Join(holotree, "legacy"), "bbbb")
+
+	entries := []*RobotEntry{
+		{Name: "invoice", Path: "/repo/invoice/robot.yaml", Blueprint: "aaaa"},
+		{Name: "twin", Path: "/repo/twin/robot.yaml", Blueprint: "aaaa"},
+		{Name: "mover", Path: "/repo/mover/robot.yaml", Blueprint: "dddd"},
+	}
+	htfs.RecordSpaceRobot(legacy.Path, "/repo/mover/robot.yaml")
+	htfs.RecordSpaceRobot(legacy.Path, "/gone/robot.yaml")
+	htfs.RecordSpaceRobot(invoice.Path, "/repo/invoice/robot.yaml")
+
+	mappings := MapSpacesToRobots(htfs.Roots{orphan, legacy, invoice}, entries)
+	must.Equal(3, len(mappings))
+	must.Equal("invoice", mappings[0].Space)
+	must.Equal(2, len(mappings[0].Matches))
+	must.True(mappings[0].Matches[0].Current)
+	must.Equal("/repo/twin/robot.yaml", mappings[0].Matches[1].Robot.Path)
+
+	must.Equal("legacy", mappings[1].Space)
+	must.Equal(1, len(mappings[1].Matches))
+	must.Equal("mover", mappings[1].Matches[0].Robot.Name)
+	wont.True(mappings[1].Matches[0].Current)
+
+	must.Equal("orphan", mappings[2].Space)
+	must.Equal(0, len(mappings[2].Matches))
+
+	must.Equal(1, len(FilterSpaceMappings(mappings, "MOVER")))
+	must.Equal(1, len(FilterSpaceMappings(mappings, "cccc")))
+	must.Equal(3, len(FilterSpaceMappings(mappings, "")))
+}
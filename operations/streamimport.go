@@ -0,0 +1,328 @@
+package operations
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/conda"
+	"github.com/joshyorko/rcc/fail"
+	"github.com/joshyorko/rcc/htfs"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/joshyorko/rcc/settings"
+	"github.com/klauspost/compress/zstd"
+)
+
+type hololibSink struct {
+	directory  string
+	archive    string
+	mode       string
+	catalogs   map[string][]byte
+	blobs      int
+	skipped    int
+	unverified int
+	summary    *htfs.TransferSummary
+}
+
+func newHololibSink(directory, archive string) *hololibSink {
+	mode := htfs.PullVerifyMode()
+	if mode == htfs.PullVerifyTrust {
+		pretty.Warning("Blobs from %q are trusted without digest verification (%s=%s).", archive, common.RCC_PULL_VERIFY, mode)
+	}
+	return &hololibSink{
+		directory: directory,
+		archive:   archive,
+		mode:      mode,
+		catalogs:  make(map[string][]byte),
+		summary:   htfs.NewTransferSummary("import", archive),
+	}
+}
+
+func (it *hololibSink) Accept(name string, source io.Reader) error {
+	return it.AcceptChecked(name, source, nil)
+}
+
+// AcceptChecked is like Accept, but calls check (when given) after whole
+// source was read, and before anything is written into hololib, so that
+// archive level checksums (like zip CRC-32) can still reject the entry.
+func (it *hololibSink) AcceptChecked(name string, source io.Reader, check func() error) (err error) {
+	defer fail.Around(&err)
+
+	checked := func() {
+		if check != nil {
+			fail.Fast(check())
+		}
+	}
+	name = slashed(name)
+	if local := localPattern.FindStringSubmatch(name); local != nil {
+		return it.acceptLocalPackage(local[2], strings.ToLower(local[1]), source, checked)
+	}
+	library := libraryPattern.MatchString(name)
+	catalog := catalogPattern.MatchString(name)
+	fail.On(!library && !catalog, "Entry %q does not match Holotree catalog or library entry pattern.", name)
+
+	if catalog {
+		blob, err := io.ReadAll(source)
+		fail.On(err != nil, "Failed to read catalog %q, reason: %v", name, err)
+		checked()
+		it.catalogs[name] = blob
+		return nil
+	}
+
+	target, err := zipEntryTarget(it.directory, name)
+	fail.Fast(err)
+	if pathlib.IsFile(target) {
+		_, err = io.Copy(io.Discard, source)
+		fail.On(err != nil, "Failed to skip %q, reason: %v", name, err)
+		checked()
+		it.skip()
+		return nil
+	}
+
+	partname := fmt.Sprintf("%s.part%s", target, <-common.Identities)
+	defer os.Remove(partname)
+	sink, err := pathlib.Create(partname)
+	fail.On(err != nil, "Failed to create %q, reason: %v", partname, err)
+	size, err := io.Copy(sink, source)
+	sink.Close()
+	fail.On(err != nil, "Failed to write %q, reason: %v", partname, err)
+	checked()
+
+	verified := htfs.VerifiedOnArrival(it.mode)
+	if verified {
+		err = htfs.VerifyBlobFile(partname, path.Base(name))
+		fail.Fast(err)
+	} else {
+		it.unverified++
+	}
+
+	err = pathlib.TryRename("import", partname, target)
+	fail.Fast(err)
+	pathlib.MakeSharedFile(target)
+	it.blobs++
+	it.summary.File(size, verified)
+	return nil
+}
+
+// acceptLocalPackage stores local package carried in archive into local
+// package storage, after its sha256 digest is verified.
+func (it *hololibSink) acceptLocalPackage(basename, digest string, source io.Reader, checked func()) (err error) {
+	defer fail.Around(&err)
+
+	target, ok := conda.LocalPackageFile(fmt.Sprintf("%s#sha256=%s", basename, digest))
+	fail.On(!ok, "Entry %q is not valid local package.", basename)
+	if pathlib.IsFile(target) {
+		_, err = io.Copy(io.Discard, source)
+		fail.On(err != nil, "Failed to skip %q, reason: %v", basename, err)
+		checked()
+		return nil
+	}
+
+	partname := fmt.Sprintf("%s.part%s", target, <-common.Identities)
+	defer os.Remove(partname)
+	sink, err := pathlib.Create(partname)
+	fail.On(err != nil, "Failed to create %q, reason: %v", partname, err)
+	hasher := sha256.New()
+	_, err = io.Copy(io.MultiWriter(sink, hasher), source)
+	sink.Close()
+	fail.On(err != nil, "Failed to write %q, reason: %v", partname, err)
+	checked()
+
+	actual := fmt.Sprintf("%02x", hasher.Sum(nil))
+	fail.On(actual != digest, "Local package %q has sha256 %s, but expected %s.", basename, actual, digest)
+	err = pathlib.TryRename("import", partname, target)
+	fail.Fast(err)
+	common.Debug("Local package %q imported as %q.", basename, target)
+	return nil
+}
+
+// Missing tells if library entry is not yet in hololib. Present entries are
+// counted as skipped, so that their content does not need to be read.
+func (it *hololibSink) Missing(name string) (bool, error) {
+	name = slashed(name)
+	if !libraryPattern.MatchString(name) {
+		return true, nil
+	}
+	target, err := zipEntryTarget(it.directory, name)
+	if err != nil {
+		return false, err
+	}
+	if pathlib.IsFile(target) {
+		it.skip()
+		return false, nil
+	}
+	return true, nil
+}
+
+func (it *hololibSink) skip() {
+	it.skipped++
+	it.summary.Skip()
+}
+
+func (it *hololibSink) Commit() (err error) {
+	defer fail.Around(&err)
+
+	fail.On(len(it.catalogs) == 0, "No catalogs found from imported content!")
+	targets := make(map[string][]byte)
+	fresh := []string{}
+	for name, blob := range it.catalogs {
+		target, err := zipEntryTarget(it.directory, name)
+		fail.Fast(err)
+		targets[target] = blob
+		if !pathlib.Exists(target) {
+			fresh = append(fresh, target)
+		}
+	}
+	mutation, err := htfs.BeginMutation(htfs.MutationImport, fresh, nil)
+	fail.Fast(err)
+	for target, blob := range targets {
+		err = pathlib.WriteFile(target, blob, 0o644)
+		fail.On(err != nil, "Failed to write catalog %q, reason: %v", target, err)
+		pathlib.MakeSharedFile(target)
+		it.summary.Catalog()
+	}
+	fail.Fast(mutation.Commit())
+	it.summary.Done()
+	it.record()
+	return nil
+}
+
+// record remembers how blobs of committed catalogs were verified.
+func (it *hololibSink) record() {
+	names := []string{}
+	for name := range it.catalogs {
+		names = append(names, path.Base(slashed(name)))
+	}
+	err := htfs.RecordCatalogVerification(it.directory, names, &htfs.CatalogVerification{
+		Mode:       it.mode,
+		Source:     it.archive,
+		Verified:   it.blobs - it.unverified,
+		Unverified: it.unverified,
+	})
+	if err != nil {
+		common.Debug("Could not record catalog verification, reason: %v", err)
+	}
+}
+
+func archiveKind(link string) string {
+	name := strings.ToLower(link)
+	parsed, err := url.Parse(link)
+	if err == nil {
+		name = strings.ToLower(parsed.Path)
+	}
+	switch {
+	case strings.HasSuffix(name, ".tar.zst"), strings.HasSuffix(name, ".tzst"):
+		return "zst"
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return "tgz"
+	case strings.HasSuffix(name, ".tar"):
+		return "tar"
+	default:
+		return "zip"
+	}
+}
+
+func streamTar(source io.Reader, sink *hololibSink) (err error) {
+	defer fail.Around(&err)
+
+	reader := tar.NewReader(source)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		fail.On(err != nil, "Failed to read tar stream, reason: %v", err)
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		fail.Fast(sink.Accept(header.Name, reader))
+	}
+}
+
+func acceptZip(zipfile string, sink *hololibSink) (err error) {
+	defer fail.Around(&err)
+
+	archive, err := zip.OpenReader(zipfile)
+	fail.On(err != nil, "Failed to open %q, reason: %v", zipfile, err)
+	defer archive.Close()
+	for _, entry := range archive.File {
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+		name := slashed(entry.Name)
+		if !hololibEntry(name) {
+			common.Debug("Ignoring non-hololib entry %q in %q.", entry.Name, zipfile)
+			continue
+		}
+		reader, err := entry.Open()
+		fail.On(err != nil, "Failed to open %q, reason: %v", entry.Name, err)
+		err = sink.Accept(entry.Name, reader)
+		reader.Close()
+		fail.Fast(err)
+	}
+	return nil
+}
+
+func ImportFromUrl(link string) (err error) {
+	defer fail.Around(&err)
+	defer pathlib.TrackActivity("import")()
+
+	kind := archiveKind(link)
+
+	lockfile := common.HolotreeLock()
+	completed := pathlib.LockWaitMessage(lockfile, "Serialized environment import [holotree lock]")
+	locker, err := pathlib.Locker(lockfile, 30000, common.SharedHolotree)
+	completed()
+	fail.On(err != nil, "Could not get lock for holotree. Quiting.")
+	defer locker.Release()
+
+	htfs.RecoverMutations()
+
+	common.TimelineBegin("import %q from url", link)
+	defer common.TimelineEnd()
+
+	client := &http.Client{Transport: settings.Global.ConfiguredHttpTransport()}
+	request, err := http.NewRequest("GET", link, nil)
+	fail.On(err != nil, "Failed create request to %q failed, reason: %v", link, err)
+	request.Header.Add("Accept", "application/octet-stream")
+	request.Header.Add("User-Agent", common.UserAgent())
+	response, err := client.Do(request)
+	fail.On(err != nil, "Web request to %q failed, reason: %v", link, err)
+	defer response.Body.Close()
+	fail.On(response.StatusCode < 200 || 299 < response.StatusCode, "Downloading %q failed, reason: %q!", link, response.Status)
+
+	meter := pretty.NewMeter("Import download", response.ContentLength)
+	source := io.TeeReader(response.Body, meter)
+	sink := newHololibSink(common.HololibLocation(), link)
+
+	switch kind {
+	case "tgz":
+		wrapper, err := gzip.NewReader(source)
+		fail.On(err != nil, "Failed to open gzip stream from %q, reason: %v", link, err)
+		defer wrapper.Close()
+		err = streamTar(wrapper, sink)
+		fail.Fast(err)
+	case "zst":
+		wrapper, err := zstd.NewReader(source)
+		fail.On(err != nil, "Failed to open zstd stream from %q, reason: %v", link, err)
+		defer wrapper.Close()
+		err = streamTar(wrapper, sink)
+		fail.Fast(err)
+	case "tar":
+		fail.Fast(streamTar(source, sink))
+	default:
+		fail.Fast(streamZip(source, sink))
+	}
+	meter.Done()
+	return sink.Commit()
+}
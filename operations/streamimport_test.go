@@ -0,0 +1,210 @@
+package operations
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/hamlet"
+	"github.com/joshyorko/rcc/htfs"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/klauspost/compress/zstd"
+)
+
+func gzipped(t *testing.T, content []byte) []byte {
+	t.Helper()
+	buffer := bytes.NewBuffer(nil)
+	writer := gzip.NewWriter(buffer)
+	writer.Write(content)
+	writer.Close()
+	return buffer.Bytes()
+}
+
+func tarStream(t *testing.T, entries map[string][]byte) *bytes.Buffer {
+	t.Helper()
+	buffer := bytes.NewBuffer(nil)
+	writer := tar.NewWriter(buffer)
+	for name, content := range entries {
+		header := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := writer.WriteHeader(header); err != nil {
+			t.Fatalf("unable to write tar header: %v", err)
+		}
+		if _, err := writer.Write(content); err != nil {
+			t.Fatalf("unable to write tar entry: %v", err)
+		}
+	}
+	writer.Close()
+	return buffer
+}
+
+func zipStream(t *testing.T, entries map[string][]byte) *bytes.Buffer {
+	t.Helper()
+	buffer := bytes.NewBuffer(nil)
+	writer := zip.NewWriter(buffer)
+	for name, content := range entries {
+		target, err := writer.Create(name)
+		if err != nil {
+			t.Fatalf("unable to create zip entry: %v", err)
+		}
+		if _, err := target.Write(content); err != nil {
+			t.Fatalf("unable to write zip entry: %v", err)
+		}
+	}
+	writer.Close()
+	return buffer
+}
+
+func libraryEntry(digest string) string {
+	return fmt.Sprintf("library/%s/%s/%s/%s", digest[:2], digest[2:4], digest[4:6], digest)
+}
+
+func TestCanStreamTarIntoHololib(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	content := []byte("hello, hololib")
+	digest := fmt.Sprintf("%02x", sha256.Sum256(content))
+	catalog := "catalog/0123456789abcdefv12.linux_amd64"
+	stream := tarStream(t, map[string][]byte{
+		libraryEntry(digest): gzipped(t, content),
+		catalog:              []byte("catalog"),
+	})
+
+	target := t.TempDir()
+	sink := newHololibSink(target, "test.tar")
+	must.Nil(streamTar(stream, sink))
+	wont.True(pathlib.IsFile(filepath.Join(target, catalog)))
+	must.Nil(sink.Commit())
+	must.True(pathlib.IsFile(filepath.Join(target, catalog)))
+	must.True(pathlib.IsFile(filepath.Join(target, libraryEntry(digest))))
+	must.Equal(1, sink.blobs)
+	must.Equal(1, sink.summary.Catalogs)
+	must.Equal(1, sink.summary.Files)
+	must.Equal(1, sink.summary.Verified)
+	must.Equal(0, sink.summary.Skipped)
+	summaries := htfs.TransferSummaries()
+	must.True(len(summaries) > 0)
+	must.Equal("import", summaries[len(summaries)-1].Operation)
+}
+
+func TestStreamImportRejectsCorruptedBlobs(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	digest := fmt.Sprintf("%02x", sha256.Sum256([]byte("expected")))
+	stream := tarStream(t, map[string][]byte{
+		libraryEntry(digest): gzipped(t, []byte("tampered")),
+	})
+
+	target := t.TempDir()
+	sink := newHololibSink(target, "test.tar")
+	wont.Nil(streamTar(stream, sink))
+	wont.True(pathlib.IsFile(filepath.Join(target, libraryEntry(digest))))
+	must.Equal(0, sink.blobs)
+}
+
+func TestStreamImportStoresVerifiedLocalPackages(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	common.Product.ForceHome(t.TempDir())
+	defer common.Product.ForceHome("")
+
+	content := []byte("not really a wheel")
+	digest := fmt.Sprintf("%02x", sha256.Sum256(content))
+	stream := tarStream(t, map[string][]byte{
+		"localpackages/" + digest + "/private-1.0-py3-none-any.whl":  content,
+		"localpackages/" + digest + "/tampered-1.0-py3-none-any.whl": []byte("tampered"),
+	})
+
+	sink := newHololibSink(t.TempDir(), "test.tar")
+	wont.Nil(streamTar(stream, sink))
+
+	stream = tarStream(t, map[string][]byte{
+		"localpackages/" + digest + "/private-1.0-py3-none-any.whl": content,
+	})
+	must.Nil(streamTar(stream, sink))
+	stored := filepath.Join(common.LocalPackagesLocation(), digest, "private-1.0-py3-none-any.whl")
+	must.True(pathlib.IsFile(stored))
+	wont.True(pathlib.IsFile(filepath.Join(common.LocalPackagesLocation(), digest, "tampered-1.0-py3-none-any.whl")))
+	must.True(hololibEntry("localpackages/" + digest + "/private-1.0-py3-none-any.whl"))
+	wont.True(hololibEntry("localpackages/" + digest + "/.."))
+}
+
+func TestCanDetectImportArchiveKind(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	must.Equal("zip", archiveKind("https://example.com/hololib.zip"))
+	must.Equal("tgz", archiveKind("https://example.com/hololib.tar.gz?token=abc"))
+	must.Equal("tar", archiveKind("https://example.com/hololib.tar"))
+	must.Equal("zst", archiveKind("https://example.com/hololib.tar.zst"))
+}
+
+func TestCanStreamZipIntoHololib(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	content := []byte("hello, zipped hololib")
+	digest := fmt.Sprintf("%02x", sha256.Sum256(content))
+	catalog := "catalog/0123456789abcdefv12.linux_amd64"
+	stream := zipStream(t, map[string][]byte{
+		libraryEntry(digest): gzipped(t, content),
+		catalog:              []byte("catalog"),
+	})
+
+	target := t.TempDir()
+	sink := newHololibSink(target, "test.zip")
+	must.Nil(streamZip(stream, sink))
+	wont.True(pathlib.IsFile(filepath.Join(target, catalog)))
+	must.Nil(sink.Commit())
+	must.True(pathlib.IsFile(filepath.Join(target, catalog)))
+	must.True(pathlib.IsFile(filepath.Join(target, libraryEntry(digest))))
+	must.Equal(1, sink.blobs)
+}
+
+func TestStreamZipRejectsBrokenChecksum(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	content := []byte("hello, zipped hololib")
+	digest := fmt.Sprintf("%02x", sha256.Sum256(content))
+	stream := zipStream(t, map[string][]byte{
+		libraryEntry(digest): gzipped(t, content),
+	}).Bytes()
+	// flip CRC-32 in data descriptor, which follows compressed content
+	at := bytes.Index(stream, []byte{0x50, 0x4b, 0x07, 0x08})
+	must.True(at > 0)
+	stream[at+4] ^= 0xff
+
+	t.Setenv(common.RCC_PULL_VERIFY, htfs.PullVerifyTrust)
+	target := t.TempDir()
+	sink := newHololibSink(target, "test.zip")
+	wont.Nil(streamZip(bytes.NewReader(stream), sink))
+	wont.True(pathlib.IsFile(filepath.Join(target, libraryEntry(digest))))
+	must.Equal(0, len(pathlib.Glob(filepath.Dir(filepath.Join(target, libraryEntry(digest))), "*")))
+}
+
+func TestCanStreamTarZstIntoHololib(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	content := []byte("hello, zstd hololib")
+	digest := fmt.Sprintf("%02x", sha256.Sum256(content))
+	stream := tarStream(t, map[string][]byte{
+		libraryEntry(digest): gzipped(t, content),
+	})
+	compressed := bytes.NewBuffer(nil)
+	encoder, err := zstd.NewWriter(compressed)
+	must.Nil(err)
+	_, err = encoder.Write(stream.Bytes())
+	must.Nil(err)
+	must.Nil(encoder.Close())
+
+	decoder, err := zstd.NewReader(compressed)
+	must.Nil(err)
+	defer decoder.Close()
+	target := t.TempDir()
+	sink := newHololibSink(target, "test.tar.zst")
+	must.Nil(streamTar(decoder, sink))
+	must.True(pathlib.IsFile(filepath.Join(target, libraryEntry(digest))))
+}
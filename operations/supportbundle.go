@@ -0,0 +1,286 @@
+package operations
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/conda"
+	"github.com/joshyorko/rcc/journal"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/joshyorko/rcc/settings"
+)
+
+const (
+	redacted = "<redacted>"
+)
+
+var (
+	redactUserinfo = regexp.MustCompile(`(://)[^/@\s:"']+:[^/@\s"']+@`)
+	redactQuery    = regexp.MustCompile(`(?i)([?&](?:token|key|sig|signature|secret|password|code)=)[^&\s"']+`)
+	redactKeyValue = regexp.MustCompile(`(?i)((?:token|secret|password|passwd|authorization|api[_-]?key|credentials?)["']?\s*[:=]\s*["']?)(?:(?:bearer|basic)\s+)?[^\s"',}&<]+`)
+	redactBearer   = regexp.MustCompile(`(?i)\b(bearer|basic)\s+[a-z0-9._~+/=-]{8,}`)
+)
+
+// SupportEntry is one file in support bundle.
+type SupportEntry struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Size        int    `json:"size"`
+	Included    bool   `json:"included"`
+	content     []byte
+}
+
+// SupportBundle is collected support material, before it is written as zip.
+type SupportBundle struct {
+	Created time.Time       `json:"created"`
+	Limit   int             `json:"limit"`
+	Entries []*SupportEntry `json:"entries"`
+}
+
+// RedactText replaces secrets (passwords and tokens in URLs, key/value pairs,
+// and authorization headers) from text with placeholder.
+func RedactText(text string) string {
+	text = redactUserinfo.ReplaceAllString(text, "${1}"+redacted+"@")
+	text = redactQuery.ReplaceAllString(text, "${1}"+redacted)
+	text = redactKeyValue.ReplaceAllString(text, "${1}"+redacted)
+	return redactBearer.ReplaceAllString(text, "${1} "+redacted)
+}
+
+func (it *SupportBundle) add(name, description string, content []byte) {
+	it.Entries = append(it.Entries, &SupportEntry{
+		Name:        name,
+		Description: description,
+		Size:        len(content),
+		content:     content,
+	})
+}
+
+func (it *SupportBundle) addJson(name, description string, content interface{}) {
+	blob, err := json.MarshalIndent(content, "", "  ")
+	if err != nil {
+		pretty.Warning("Could not serialize %s, reason: %v", name, err)
+		return
+	}
+	it.add(name, description, []byte(RedactText(string(blob))))
+}
+
+func (it *SupportBundle) manifest() ([]byte, error) {
+	return json.MarshalIndent(it, "", "  ")
+}
+
+// limit marks entries included in collection order, until size limit would
+// be exceeded. Manifest counts against the limit, and first entry that does
+// not fit leaves out it and all less important entries after it.
+func (it *SupportBundle) limit() {
+	for _, entry := range it.Entries {
+		entry.Included = false
+	}
+	total := 0
+	if it.Limit > 0 {
+		// manifest with nothing included is the largest one ("false" > "true")
+		manifest, _ := it.manifest()
+		total = len(manifest)
+	}
+	fits := true
+	for _, entry := range it.Entries {
+		fits = fits && (it.Limit <= 0 || total+entry.Size <= it.Limit)
+		entry.Included = fits
+		if fits {
+			total += entry.Size
+		}
+	}
+}
+
+// Size gives total size of manifest and included entries.
+func (it *SupportBundle) Size() int {
+	manifest, _ := it.manifest()
+	total := len(manifest)
+	for _, entry := range it.Entries {
+		if entry.Included {
+			total += entry.Size
+		}
+	}
+	return total
+}
+
+// Table lists what bundle has, so that user can consent to sharing it.
+func (it *SupportBundle) Table() *pretty.Table {
+	table := pretty.NewTable(
+		&pretty.TableColumn{Name: "name", Title: "File"},
+		&pretty.TableColumn{Name: "size", Title: "Size"},
+		&pretty.TableColumn{Name: "included", Title: "Included"},
+		&pretty.TableColumn{Name: "description", Title: "Description"},
+	)
+	for _, entry := range it.Entries {
+		included := "yes"
+		if !entry.Included {
+			included = "no, over size limit"
+		}
+		table.Add(pretty.TableRow{
+			"name":        entry.Name,
+			"size":        humaneBytes(uint64(entry.Size)),
+			"included":    included,
+			"description": entry.Description,
+		})
+	}
+	return table
+}
+
+// WriteZip writes included entries, and manifest of all entries, into zip.
+func (it *SupportBundle) WriteZip(filename string) error {
+	handle, err := pathlib.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer handle.Close()
+	writer := zip.NewWriter(handle)
+	manifest, err := it.manifest()
+	if err != nil {
+		return err
+	}
+	entries := append([]*SupportEntry{{Name: "manifest.json", content: manifest, Included: true}}, it.Entries...)
+	for _, entry := range entries {
+		if !entry.Included {
+			continue
+		}
+		target, err := writer.CreateHeader(&zip.FileHeader{Name: entry.Name, Method: zip.Deflate, Modified: it.Created})
+		if err != nil {
+			return err
+		}
+		_, err = target.Write(entry.content)
+		if err != nil {
+			return err
+		}
+	}
+	err = writer.Close()
+	if err != nil {
+		return err
+	}
+	return handle.Close()
+}
+
+func supportVersions() map[string]string {
+	result := map[string]string{
+		"rcc":      common.Version,
+		"platform": common.Platform(),
+		"go":       runtime.Version(),
+	}
+	if pathlib.IsFile(conda.BinMicromamba()) {
+		result["micromamba"] = conda.MicromambaVersion()
+	} else {
+		result["micromamba"] = "not installed"
+	}
+	return result
+}
+
+// recentJournals gives journal files changed in last days, newest first.
+// Rotated (compressed) journals are left out.
+func recentJournals(days int) []string {
+	type journalFile struct {
+		path    string
+		changed time.Time
+	}
+	found := []*journalFile{}
+	limit := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+	filepath.WalkDir(common.JournalLocation(), func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || filepath.Ext(path) == ".gz" {
+			return nil
+		}
+		info, err := entry.Info()
+		if err == nil && info.ModTime().After(limit) {
+			found = append(found, &journalFile{path: path, changed: info.ModTime()})
+		}
+		return nil
+	})
+	sort.Slice(found, func(left, right int) bool {
+		return found[left].changed.After(found[right].changed)
+	})
+	result := make([]string, 0, len(found))
+	for _, entry := range found {
+		result = append(result, entry.path)
+	}
+	return result
+}
+
+func (it *SupportBundle) addLastRun() {
+	runs, err := journal.RunHistory("", 1)
+	if err != nil || len(runs) == 0 {
+		return
+	}
+	last := runs[0]
+	artifacts := []map[string]interface{}{}
+	filepath.WalkDir(last.Artifacts, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		relative, _ := filepath.Rel(last.Artifacts, path)
+		artifacts = append(artifacts, map[string]interface{}{
+			"name":     filepath.ToSlash(relative),
+			"size":     info.Size(),
+			"modified": info.ModTime().Format(time.RFC3339),
+		})
+		return nil
+	})
+	it.addJson("last-run.json", "last robot run and its artifact listing (names and sizes only)", map[string]interface{}{
+		"run":       last,
+		"artifacts": artifacts,
+	})
+	report, err := os.ReadFile(pretty.FailureReportIn(last.Artifacts))
+	if err == nil {
+		it.add("failure-report.json", "failure report of last run (if it failed)", []byte(RedactText(string(report))))
+	}
+}
+
+// CollectSupportBundle collects diagnostics, versions, redacted settings,
+// holotree statistics, last run details, and recent journals, in that order
+// of importance. Size limit (in bytes, zero meaning no limit) leaves out
+// least important entries that do not fit.
+func CollectSupportBundle(limit, days int, quick bool) *SupportBundle {
+	common.TimelineBegin("support bundle collect start")
+	defer common.TimelineEnd()
+
+	bundle := &SupportBundle{
+		Created: time.Now().Truncate(time.Second),
+		Limit:   limit,
+		Entries: []*SupportEntry{},
+	}
+	bundle.addJson("versions.json", "versions of rcc, micromamba, and platform", supportVersions())
+	status := runDiagnostics(quick)
+	settings.Global.Diagnostics(status)
+	bundle.addJson("diagnostics.json", "rcc configure diagnostics output", status)
+	effective, err := settings.SummonSettings()
+	if err == nil {
+		content, err := effective.AsYaml()
+		if err == nil {
+			bundle.add("settings.yaml", "effective settings (secrets redacted)", []byte(RedactText(string(content))))
+		}
+	}
+	_, statistics := journal.MakeStatistics(2, false, false, false, false)
+	bundle.add("statistics.txt", "holotree build statistics of last two weeks", statistics)
+	bundle.addLastRun()
+	for _, filename := range recentJournals(days) {
+		content, err := os.ReadFile(filename)
+		if err != nil {
+			continue
+		}
+		relative, _ := filepath.Rel(common.JournalLocation(), filename)
+		name := fmt.Sprintf("journals/%s", filepath.ToSlash(relative))
+		bundle.add(name, "journal (secrets redacted)", []byte(RedactText(string(content))))
+	}
+	bundle.limit()
+	return bundle
+}
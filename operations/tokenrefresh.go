@@ -0,0 +1,135 @@
+package operations
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pathlib"
+)
+
+const (
+	RC_API_TOKEN_FILE = `RC_API_TOKEN_FILE`
+)
+
+type tokenFile struct {
+	Endpoint  string `json:"endpoint"`
+	Token     string `json:"token"`
+	Workspace string `json:"workspace"`
+	Refreshed int64  `json:"refreshed"`
+	Expires   int64  `json:"expires"`
+}
+
+type tokenRefresher struct {
+	flags    *RunFlags
+	filename string
+	current  Token
+	cancel   chan bool
+}
+
+// tokenFilename is unique for each run, so that concurrent runs do not
+// overwrite (or remove) each others tokens.
+func tokenFilename() string {
+	return filepath.Join(common.ProductTemp(), fmt.Sprintf("rctoken_%d_%s.json", os.Getpid(), strings.TrimPrefix(<-common.Identities, "#")))
+}
+
+func NewTokenRefresher(flags *RunFlags) (*tokenRefresher, error) {
+	flags.TokenPeriod = flags.TokenPeriod.EnforceGracePeriod()
+	if flags.TokenPeriod == nil {
+		flags.TokenPeriod = DefaultTokenPeriod()
+	}
+	result := &tokenRefresher{
+		flags:    flags,
+		filename: tokenFilename(),
+		cancel:   make(chan bool),
+	}
+	err := result.refresh()
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (it *tokenRefresher) Token() Token {
+	return it.current
+}
+
+func (it *tokenRefresher) Environment() []string {
+	return []string{fmt.Sprintf("%s=%s", RC_API_TOKEN_FILE, it.filename)}
+}
+
+func (it *tokenRefresher) Interval() time.Duration {
+	valid, _, _ := it.flags.TokenPeriod.AsSeconds()
+	return time.Duration(valid) * time.Second
+}
+
+func (it *tokenRefresher) refresh() error {
+	claims := RunRobotClaims(it.flags.TokenPeriod.RequestSeconds(), it.flags.WorkspaceId)
+	data, err := AuthorizeClaims(it.flags.AccountName, claims, it.flags.TokenPeriod)
+	if err != nil {
+		return err
+	}
+	err = it.write(data)
+	if err != nil {
+		return err
+	}
+	it.current = data
+	return nil
+}
+
+func (it *tokenRefresher) write(data Token) error {
+	now := time.Now().Unix()
+	content := tokenFile{
+		Endpoint:  fmt.Sprintf("%v", data["endpoint"]),
+		Token:     fmt.Sprintf("%v", data["token"]),
+		Workspace: it.flags.WorkspaceId,
+		Refreshed: now,
+		Expires:   now + int64(it.flags.TokenPeriod.RequestSeconds()),
+	}
+	blob, err := json.MarshalIndent(content, "", "  ")
+	if err != nil {
+		return err
+	}
+	partial := fmt.Sprintf("%s.part", it.filename)
+	err = pathlib.WriteFile(partial, blob, 0o600)
+	if err != nil {
+		return err
+	}
+	pathlib.RestrictOwnerOnly(partial)
+	return os.Rename(partial, it.filename)
+}
+
+func (it *tokenRefresher) Start() {
+	go it.background()
+}
+
+func (it *tokenRefresher) Stop() {
+	close(it.cancel)
+	pathlib.TryRemove("token", it.filename)
+}
+
+func (it *tokenRefresher) background() {
+	var counter = 0
+	for {
+		select {
+		case _ = <-it.cancel:
+			common.Trace("Stopping token refresher.")
+			return
+		case <-time.After(it.Interval()):
+			counter += 1
+			common.Timeline("token refresh #%d", counter)
+			err := it.refresh()
+			if err != nil {
+				common.Log("Problem refreshing workspace tokens: %v", err)
+				common.RunJournal("token", "refresh", "failed refresh #%d, reason: %v", counter, err)
+				continue
+			}
+			common.Debug("Workspace tokens refreshed into %q (#%d).", it.filename, counter)
+			common.RunJournal("token", "refresh", "refresh #%d into %q", counter, it.filename)
+		}
+	}
+}
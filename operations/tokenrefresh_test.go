@@ -0,0 +1,42 @@
+package operations
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestCanWriteRefreshedTokenFile(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	refresher := &tokenRefresher{
+		flags: &RunFlags{
+			TokenPeriod: &TokenPeriod{ValidityTime: 30, GracePeriod: 10},
+			WorkspaceId: "1234",
+		},
+		filename: filepath.Join(t.TempDir(), "rctoken.json"),
+	}
+	must.Equal(30*time.Minute, refresher.Interval())
+
+	must.Nil(refresher.write(Token{"endpoint": "https://api.example.com/", "token": "secret"}))
+	blob, err := os.ReadFile(refresher.filename)
+	must.Nil(err)
+	content := tokenFile{}
+	must.Nil(json.Unmarshal(blob, &content))
+	must.Equal("https://api.example.com/", content.Endpoint)
+	must.Equal("secret", content.Token)
+	must.Equal("1234", content.Workspace)
+	must.Equal(int64(40*60), content.Expires-content.Refreshed)
+	_, err = os.Stat(refresher.filename + ".part")
+	wont.Nil(err)
+}
+
+func TestTokenFilesAreUniquePerRun(t *testing.T) {
+	_, wont := hamlet.Specifications(t)
+
+	wont.Equal(tokenFilename(), tokenFilename())
+}
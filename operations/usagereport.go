@@ -0,0 +1,141 @@
+package operations
+
+import (
+	"sort"
+
+	"github.com/joshyorko/rcc/journal"
+)
+
+// usageCommands maps build event labels into commands that produced them.
+var usageCommands = map[string]string{
+	"assistant": "rcc assistant run",
+	"envexec":   "rcc env exec",
+	"prepare":   "rcc cloud prepare",
+	"robot":     "rcc run",
+	"variables": "rcc holotree variables",
+	"venv":      "rcc holotree venv",
+}
+
+// CommandUsage is usage summary of one command, from local build journals.
+type CommandUsage struct {
+	Command   string  `json:"command"`
+	Runs      int     `json:"runs"`
+	Builds    int     `json:"builds"`
+	Reuses    int     `json:"reuses"`
+	Failed    int     `json:"failed"`
+	SetupTime float64 `json:"setup_seconds"`
+}
+
+// UsageReport is private summary of local usage. It is computed only from
+// journals under product home, and it never leaves this machine.
+type UsageReport struct {
+	Weeks         uint            `json:"weeks"`
+	Commands      []*CommandUsage `json:"commands"`
+	Runs          int             `json:"runs"`
+	Builds        int             `json:"builds"`
+	Reuses        int             `json:"reuses"`
+	FailedBuilds  int             `json:"failed_builds"`
+	HitRate       float64         `json:"cache_hit_rate"`
+	BuildTime     float64         `json:"average_build_seconds"`
+	ReuseTime     float64         `json:"average_reuse_seconds"`
+	TimeSaved     float64         `json:"time_saved_seconds"`
+	Robots        int             `json:"robots"`
+	RobotRuns     int             `json:"robot_runs"`
+	RobotFailures int             `json:"robot_failures"`
+}
+
+func usageCommand(label string) string {
+	command, ok := usageCommands[label]
+	if ok {
+		return command
+	}
+	return label
+}
+
+// setupTime is time from start of command until robot started, or until
+// command finished, when there was no robot.
+func setupTime(event *journal.BuildEvent) float64 {
+	if event.RobotStart > 0 {
+		return event.RobotStart - event.Started
+	}
+	return max(event.Finished-event.Started, 0)
+}
+
+func average(total float64, count int) float64 {
+	if count == 0 {
+		return 0
+	}
+	return total / float64(count)
+}
+
+// SummarizeUsage aggregates build events and run history into usage report.
+// Time saved by holotree is estimated as difference between average setup
+// time with environment build and without it, for each reused environment.
+func SummarizeUsage(weeks uint, events journal.BuildEvents, runs []*journal.RunRecord) *UsageReport {
+	report := &UsageReport{
+		Weeks:    weeks,
+		Commands: []*CommandUsage{},
+	}
+	commands := make(map[string]*CommandUsage)
+	buildTime, reuseTime := 0.0, 0.0
+	for _, event := range events {
+		name := usageCommand(event.What)
+		command, ok := commands[name]
+		if !ok {
+			command = &CommandUsage{Command: name}
+			commands[name] = command
+			report.Commands = append(report.Commands, command)
+		}
+		spent := setupTime(event)
+		command.Runs++
+		command.SetupTime += spent
+		report.Runs++
+		switch {
+		case event.Build && !event.Success:
+			command.Builds++
+			command.Failed++
+			report.Builds++
+			report.FailedBuilds++
+		case event.Build:
+			command.Builds++
+			report.Builds++
+			buildTime += spent
+		default:
+			command.Reuses++
+			report.Reuses++
+			reuseTime += spent
+		}
+	}
+	sort.SliceStable(report.Commands, func(left, right int) bool {
+		return report.Commands[left].Runs > report.Commands[right].Runs
+	})
+	report.HitRate = 100.0 * average(float64(report.Reuses), report.Runs)
+	report.BuildTime = average(buildTime, report.Builds-report.FailedBuilds)
+	report.ReuseTime = average(reuseTime, report.Reuses)
+	if report.BuildTime > report.ReuseTime {
+		report.TimeSaved = float64(report.Reuses) * (report.BuildTime - report.ReuseTime)
+	}
+	robots := make(map[string]bool)
+	for _, run := range runs {
+		robots[run.Robot] = true
+		report.RobotRuns++
+		if !run.Success {
+			report.RobotFailures++
+		}
+	}
+	report.Robots = len(robots)
+	return report
+}
+
+// LocalUsageReport loads local journals of last weeks into usage report.
+func LocalUsageReport(weeks uint) (*UsageReport, error) {
+	events, err := journal.Stats(weeks)
+	if err != nil {
+		return nil, err
+	}
+	runs, err := journal.RunHistory("", weeks)
+	if err != nil {
+		return nil, err
+	}
+	return SummarizeUsage(weeks, events, runs), nil
+}
@@ -0,0 +1,55 @@
+package operations
+
+import (
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+	"github.com/joshyorko/rcc/journal"
+)
+
+func TestSummarizeUsageCountsBuildsAndReuses(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	events := journal.BuildEvents{
+		{What: "robot", Build: true, Success: true, Started: 1, RobotStart: 101, Finished: 130},
+		{What: "robot", Success: true, Started: 1, RobotStart: 11, Finished: 40},
+		{What: "robot", Success: true, Started: 1, RobotStart: 11, Finished: 40},
+		{What: "variables", Success: true, Started: 1, Finished: 11},
+		{What: "variables", Build: true, Started: 1, Finished: 61},
+	}
+	runs := []*journal.RunRecord{
+		{Robot: "a/robot.yaml", Success: true},
+		{Robot: "a/robot.yaml", Success: false},
+		{Robot: "b/robot.yaml", Success: true},
+	}
+	report := SummarizeUsage(4, events, runs)
+
+	must.Equal(uint(4), report.Weeks)
+	must.Equal(5, report.Runs)
+	must.Equal(2, report.Builds)
+	must.Equal(1, report.FailedBuilds)
+	must.Equal(3, report.Reuses)
+	must.Equal(60.0, report.HitRate)
+	must.Equal(100.0, report.BuildTime)
+	must.Equal(10.0, report.ReuseTime)
+	must.Equal(270.0, report.TimeSaved)
+	must.Equal(2, len(report.Commands))
+	must.Equal("rcc run", report.Commands[0].Command)
+	must.Equal(3, report.Commands[0].Runs)
+	must.Equal("rcc holotree variables", report.Commands[1].Command)
+	must.Equal(1, report.Commands[1].Failed)
+	must.Equal(2, report.Robots)
+	must.Equal(3, report.RobotRuns)
+	must.Equal(1, report.RobotFailures)
+	wont.Nil(report.Commands)
+}
+
+func TestSummarizeUsageWithoutJournals(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	report := SummarizeUsage(12, nil, nil)
+	must.Equal(0, report.Runs)
+	must.Equal(0.0, report.HitRate)
+	must.Equal(0.0, report.TimeSaved)
+	must.Equal(0, len(report.Commands))
+}
@@ -0,0 +1,99 @@
+package operations
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pretty"
+)
+
+type exportable struct {
+	title string
+	table *pretty.Table
+}
+
+var (
+	exported *exportable
+)
+
+// Exportable remembers data of current interactive view, so that it can be
+// exported into markdown or CSV file for sharing in tickets and chat.
+func Exportable(title string, table *pretty.Table) {
+	exported = &exportable{
+		title: title,
+		table: table,
+	}
+}
+
+// ExportView writes data of current interactive view into file, as CSV if
+// filename ends with ".csv" and otherwise as markdown.
+func ExportView(filename string) error {
+	if exported == nil {
+		return fmt.Errorf("Nothing to export in this view.")
+	}
+	err := exported.table.Export(filename, exported.title)
+	if err != nil {
+		return fmt.Errorf("Could not export %s view into %q, reason: %v", exported.title, filename, err)
+	}
+	common.Log("%sExported %s view (%d rows) into %q.%s", pretty.Green, exported.title, len(exported.table.Rows), filename, pretty.Reset)
+	return nil
+}
+
+// RobotTable gives robot list view as table.
+func RobotTable(entries []*RobotEntry) *pretty.Table {
+	table := pretty.NewTable(
+		&pretty.TableColumn{Name: "name", Title: "Robot"},
+		&pretty.TableColumn{Name: "status", Title: "Status"},
+		&pretty.TableColumn{Name: "modified", Title: "Modified"},
+		&pretty.TableColumn{Name: "path", Title: "Path"},
+	)
+	for _, entry := range entries {
+		table.Add(pretty.TableRow{
+			"name":     entry.Name,
+			"status":   entry.Status,
+			"modified": entry.Modified.Format(time.DateTime),
+			"path":     entry.Path,
+		})
+	}
+	return table
+}
+
+// SpaceTable gives spaces view as table.
+func SpaceTable(mappings []*SpaceMapping) *pretty.Table {
+	table := pretty.NewTable(
+		&pretty.TableColumn{Name: "space", Title: "Space"},
+		&pretty.TableColumn{Name: "blueprint", Title: "Blueprint"},
+		&pretty.TableColumn{Name: "robots", Title: "Robots"},
+	)
+	for _, mapping := range mappings {
+		table.Add(pretty.TableRow{
+			"space":     mapping.Space,
+			"blueprint": mapping.Blueprint,
+			"robots":    spaceRobotNames(mapping),
+		})
+	}
+	return table
+}
+
+// DiagnosticsTable gives checks of diagnostics run as table.
+func DiagnosticsTable(status *common.DiagnosticStatus) *pretty.Table {
+	table := pretty.NewTable(
+		&pretty.TableColumn{Name: "type", Title: "Type"},
+		&pretty.TableColumn{Name: "category", Title: "Category"},
+		&pretty.TableColumn{Name: "status", Title: "Status"},
+		&pretty.TableColumn{Name: "message", Title: "Message"},
+		&pretty.TableColumn{Name: "link", Title: "Link"},
+	)
+	for _, check := range status.Checks {
+		table.Add(pretty.TableRow{
+			"type":     check.Type,
+			"category": strconv.FormatUint(check.Category, 10),
+			"status":   check.Status,
+			"message":  check.Message,
+			"link":     check.Link,
+		})
+	}
+	return table
+}
@@ -0,0 +1,37 @@
+package operations
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestExportViewWritesMarkdownOrCsv(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	exported = nil
+	directory := t.TempDir()
+	wont.Nil(ExportView(filepath.Join(directory, "nothing.md")))
+
+	status := &common.DiagnosticStatus{Checks: []*common.DiagnosticCheck{
+		{Type: "OS", Category: common.CategoryAntivirus, Status: "warning", Message: "Antivirus is scanning holotree."},
+	}}
+	Exportable("Diagnostics", DiagnosticsTable(status))
+
+	markdown := filepath.Join(directory, "diagnostics.md")
+	must.Nil(ExportView(markdown))
+	content, err := os.ReadFile(markdown)
+	must.Nil(err)
+	must.True(strings.HasPrefix(string(content), "## Diagnostics\n"))
+	must.True(strings.Contains(string(content), "| OS | 2050 | warning | Antivirus is scanning holotree. |  |\n"))
+
+	csv := filepath.Join(directory, "diagnostics.csv")
+	must.Nil(ExportView(csv))
+	content, err = os.ReadFile(csv)
+	must.Nil(err)
+	must.Equal("type,category,status,message,link\nOS,2050,warning,Antivirus is scanning holotree.,\n", string(content))
+}
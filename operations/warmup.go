@@ -0,0 +1,177 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/conda"
+	"github.com/joshyorko/rcc/fail"
+	"github.com/joshyorko/rcc/htfs"
+	"github.com/joshyorko/rcc/robot"
+	"github.com/joshyorko/rcc/shell"
+)
+
+// importValidationScript checks that every top level module of installed
+// distributions can be found, without actually importing (running) them.
+const importValidationScript = `
+import importlib.metadata, importlib.util
+seen, missing = set(), []
+for dist in importlib.metadata.distributions():
+    names = (dist.read_text("top_level.txt") or "").split()
+    if not names:
+        for entry in dist.files or []:
+            parts = entry.parts
+            if len(parts) == 2 and parts[1] == "__init__.py":
+                names.append(parts[0])
+            elif len(parts) == 1 and parts[0].endswith(".py"):
+                names.append(parts[0][:-3])
+    for name in names:
+        if name in seen or name.startswith("_") or not name.isidentifier():
+            continue
+        seen.add(name)
+        try:
+            if importlib.util.find_spec(name) is None:
+                missing.append(name)
+        except Exception:
+            missing.append(name)
+print("checked", len(seen))
+for name in missing:
+    print("missing", name)
+`
+
+type (
+	WarmupTarget struct {
+		Robot string
+		Space string
+	}
+
+	WarmupTargets []*WarmupTarget
+
+	WarmupResult struct {
+		Robot   string
+		Space   string
+		Label   string
+		Simple  bool
+		Checked int
+		Missing []string
+		Elapsed time.Duration
+	}
+)
+
+// ReadWarmupTargets reads list of robots to warm up. Each non-comment line
+// has robot.yaml path (relative to list file) and optional space name.
+func ReadWarmupTargets(filename, space string) (WarmupTargets, error) {
+	fullpath, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := os.ReadFile(fullpath)
+	if err != nil {
+		return nil, err
+	}
+	basedir := filepath.Dir(fullpath)
+	result := WarmupTargets{}
+	for _, line := range strings.Split(string(raw), "\n") {
+		flat := strings.TrimSpace(line)
+		if strings.HasPrefix(flat, "#") || len(flat) == 0 {
+			continue
+		}
+		fields := strings.Fields(flat)
+		target := &WarmupTarget{
+			Robot: fields[0],
+			Space: space,
+		}
+		if !filepath.IsAbs(target.Robot) {
+			target.Robot = filepath.Join(basedir, target.Robot)
+		}
+		if len(fields) > 1 {
+			target.Space = fields[1]
+		}
+		result = append(result, target)
+	}
+	return result, nil
+}
+
+func parseImportValidation(output string) (int, []string) {
+	checked, missing := 0, []string{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		switch fields[0] {
+		case "checked":
+			checked, _ = strconv.Atoi(fields[1])
+		case "missing":
+			missing = append(missing, fields[1])
+		}
+	}
+	return checked, missing
+}
+
+func validateImports(config robot.Robot, label string) (int, []string, error) {
+	python, ok := config.SearchPath(label).Which("python", conda.FileExtensions)
+	if !ok {
+		return 0, nil, fmt.Errorf("Could not find python from environment %q.", label)
+	}
+	environment := config.RobotExecutionEnvironment(label, nil, false)
+	output, code, err := shell.New(environment, config.WorkingDirectory(), python, "-c", importValidationScript).CaptureOutput()
+	if err != nil {
+		return 0, nil, err
+	}
+	if code != 0 {
+		return 0, nil, fmt.Errorf("Import validation exited with code %d: %s", code, strings.TrimSpace(output))
+	}
+	checked, missing := parseImportValidation(output)
+	return checked, missing, nil
+}
+
+// WarmupRobot builds or refreshes environment of robot, restores it into
+// space, and validates that installed packages are importable, so that
+// following scheduled run can start without delays.
+func WarmupRobot(target *WarmupTarget, force bool) (result *WarmupResult, err error) {
+	defer fail.Around(&err)
+
+	started := time.Now()
+	common.TimelineBegin("robot warmup %q [space: %s]", target.Robot, target.Space)
+	defer common.TimelineEnd()
+
+	result = &WarmupResult{
+		Robot: target.Robot,
+		Space: target.Space,
+	}
+	defer func() {
+		result.Elapsed = time.Since(started)
+	}()
+
+	FixRobot(target.Robot)
+	config, err := robot.LoadRobotYaml(target.Robot, false)
+	fail.On(err != nil, "Could not load %q, reason: %v", target.Robot, err)
+	ok, err := config.Validate()
+	fail.On(!ok, "Robot %q is not valid, reason: %v", target.Robot, err)
+	if !config.UsesConda() {
+		result.Simple = true
+		return result, nil
+	}
+
+	common.HolotreeSpace = target.Space
+	shared, err := UseSharedEnvironment(config)
+	fail.On(err != nil, "Could not resolve shared environment for %q, reason: %v", target.Robot, err)
+	if shared != nil {
+		result.Space = shared.Space
+	}
+	label, _, err := htfs.NewEnvironment(config.CondaConfigFile(), config.Holozip(), config.LocalPackages(), true, force, PullCatalog)
+	fail.On(err != nil, "Could not build environment for %q, reason: %v", target.Robot, err)
+	result.Label = label
+	RecordRobotUsage(label, target.Robot)
+
+	result.Checked, result.Missing, err = validateImports(config, label)
+	fail.On(err != nil, "%v", err)
+	common.RunJournal("robot warmup", fmt.Sprintf("robot=%s space=%s checked=%d missing=%d", target.Robot, target.Space, result.Checked, len(result.Missing)), "environment warmed up")
+	return result, nil
+}
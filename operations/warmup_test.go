@@ -0,0 +1,43 @@
+package operations
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestCanReadWarmupTargets(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	folder := t.TempDir()
+	listing := filepath.Join(folder, "robots.txt")
+	absolute := filepath.Join(t.TempDir(), "robot.yaml")
+	content := "# scheduled robots\n\nfirst/robot.yaml\n  second/robot.yaml  nightly \n" + absolute + "\n"
+	must.Nil(os.WriteFile(listing, []byte(content), 0o644))
+
+	targets, err := ReadWarmupTargets(listing, "user")
+	must.Nil(err)
+	must.Equal(3, len(targets))
+	must.Equal(filepath.Join(folder, "first", "robot.yaml"), targets[0].Robot)
+	must.Equal("user", targets[0].Space)
+	must.Equal(filepath.Join(folder, "second", "robot.yaml"), targets[1].Robot)
+	must.Equal("nightly", targets[1].Space)
+	must.Equal(absolute, targets[2].Robot)
+
+	_, err = ReadWarmupTargets(filepath.Join(folder, "missing.txt"), "user")
+	wont.Nil(err)
+}
+
+func TestCanParseImportValidationOutput(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	checked, missing := parseImportValidation("checked 42\nmissing yaml\nnoise line here\nmissing robot\n")
+	must.Equal(42, checked)
+	must.Equal([]string{"yaml", "robot"}, missing)
+
+	checked, missing = parseImportValidation("")
+	must.Equal(0, checked)
+	must.Equal(0, len(missing))
+}
@@ -0,0 +1,462 @@
+package operations
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pathlib"
+)
+
+const (
+	workItemsWorkspace = "local-workspace"
+	workItemsProcess   = "local-process"
+	workItemsRun       = "local-run"
+	workItemsStep      = "local-step"
+)
+
+// WorkItem is one work item, in same format as devdata/work-items.json files
+// of file adapter: payload and files, keyed by file name.
+type WorkItem struct {
+	Payload any               `json:"payload"`
+	Files   map[string]string `json:"files,omitempty"`
+
+	id    string
+	state string
+}
+
+// WorkItemQueue is local simulator of work item queue. It serves same
+// endpoints as Control Room does for robots (RC_API_WORKITEM_HOST and
+// RC_API_PROCESS_HOST), using input items from work-items.json file, and
+// writing created output items into output file, which can be used as input
+// of next step.
+type WorkItemQueue struct {
+	sync.Mutex
+	inputs   []*WorkItem
+	outputs  []*WorkItem
+	items    map[string]*WorkItem
+	reserved int
+	output   string
+	files    string
+	token    string
+}
+
+type workItemFile struct {
+	Name string `json:"fileName"`
+	Id   string `json:"fileId"`
+	Size int64  `json:"fileSize"`
+}
+
+type workItemRelease struct {
+	Id        string `json:"workItemId"`
+	State     string `json:"state"`
+	Exception any    `json:"exception,omitempty"`
+}
+
+func workItemsToken() string {
+	secret := make([]byte, 16)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "local"
+	}
+	return hex.EncodeToString(secret)
+}
+
+// WorkItemsFile gives devdata/work-items.json of robot, if there is one.
+func WorkItemsFile(robotfile string) (string, bool) {
+	filename := filepath.Join(filepath.Dir(robotfile), "devdata", "work-items.json")
+	return filename, pathlib.IsFile(filename)
+}
+
+// LoadWorkItemQueue loads input work items from filename. Relative file paths
+// of items are relative to that file. Output items are written into output
+// file, and their files next to it.
+func LoadWorkItemQueue(filename, output string) (*WorkItemQueue, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	inputs := []*WorkItem{}
+	err = json.Unmarshal(content, &inputs)
+	if err != nil {
+		return nil, fmt.Errorf("Work items file %q is not valid, reason: %v", filename, err)
+	}
+	base := filepath.Dir(filename)
+	queue := &WorkItemQueue{
+		inputs:  inputs,
+		outputs: []*WorkItem{},
+		items:   make(map[string]*WorkItem),
+		output:  output,
+		files:   strings.TrimSuffix(output, filepath.Ext(output)),
+		token:   workItemsToken(),
+	}
+	for at, item := range inputs {
+		item.id = fmt.Sprintf("input-%d", at+1)
+		if item.Files == nil {
+			item.Files = map[string]string{}
+		}
+		for name, path := range item.Files {
+			if !filepath.IsAbs(path) {
+				item.Files[name] = filepath.Join(base, path)
+			}
+		}
+		queue.items[item.id] = item
+	}
+	return queue, nil
+}
+
+// Environment gives variables which make robot use this queue, when queue is
+// served at given base URL.
+func (it *WorkItemQueue) Environment(base string) map[string]string {
+	result := map[string]string{
+		"RC_API_WORKITEM_HOST":  base,
+		"RC_API_WORKITEM_TOKEN": it.token,
+		"RC_API_PROCESS_HOST":   base,
+		"RC_API_PROCESS_TOKEN":  it.token,
+		"RC_WORKSPACE_ID":       workItemsWorkspace,
+		"RC_PROCESS_ID":         workItemsProcess,
+		"RC_PROCESS_RUN_ID":     workItemsRun,
+		"RC_ACTIVITY_RUN_ID":    workItemsStep,
+		"RPA_WORKITEMS_ADAPTER": "RobocorpAdapter",
+		"RC_WORKITEM_ADAPTER":   "RobocorpAdapter",
+	}
+	if len(it.inputs) > 0 {
+		result["RC_WORKITEM_ID"] = it.inputs[0].id
+	}
+	return result
+}
+
+// Serve starts serving queue on given address, and gives its base URL.
+func (it *WorkItemQueue) Serve(address string) (string, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return "", err
+	}
+	base := fmt.Sprintf("http://%s", listener.Addr())
+	go http.Serve(listener, it.Handler(base))
+	common.Log("Work item simulator with %d input items is available at %s.", len(it.inputs), base)
+	return base, nil
+}
+
+// Outputs gives output work items created so far.
+func (it *WorkItemQueue) Outputs() []*WorkItem {
+	it.Lock()
+	defer it.Unlock()
+
+	return append([]*WorkItem{}, it.outputs...)
+}
+
+// save writes output items into output file. Caller must hold the lock.
+func (it *WorkItemQueue) save() error {
+	content, err := json.MarshalIndent(it.outputs, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(it.output), 0o755)
+	if err != nil {
+		return err
+	}
+	return pathlib.WriteFile(it.output, content, 0o644)
+}
+
+func workItemReply(response http.ResponseWriter, status int, body any) {
+	response.Header().Set("Content-Type", "application/json")
+	response.WriteHeader(status)
+	if body != nil {
+		json.NewEncoder(response).Encode(body)
+	}
+}
+
+func workItemError(response http.ResponseWriter, status int, form string, details ...any) {
+	workItemReply(response, status, map[string]string{"error": fmt.Sprintf(form, details...)})
+}
+
+func (it *WorkItemQueue) authorized(handler http.HandlerFunc) http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		if request.Header.Get("Authorization") != "Bearer "+it.token {
+			workItemError(response, http.StatusUnauthorized, "Missing or wrong token.")
+			return
+		}
+		handler(response, request)
+	}
+}
+
+// signed accepts file download and upload URLs given by queue, like signed
+// URLs of Control Room file storage.
+func (it *WorkItemQueue) signed(handler http.HandlerFunc) http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		if request.URL.Query().Get("token") != it.token {
+			workItemError(response, http.StatusForbidden, "Missing or wrong file token.")
+			return
+		}
+		handler(response, request)
+	}
+}
+
+func (it *WorkItemQueue) fileURL(base, item, file string) string {
+	return fmt.Sprintf("%s/files/%s/%s?token=%s", base, url.PathEscape(item), url.PathEscape(file), it.token)
+}
+
+// item finds work item of request (under lock), or replies not found.
+func (it *WorkItemQueue) item(response http.ResponseWriter, request *http.Request) (*WorkItem, bool) {
+	item, ok := it.items[request.PathValue("item")]
+	if !ok {
+		workItemError(response, http.StatusNotFound, "No work item %q.", request.PathValue("item"))
+	}
+	return item, ok
+}
+
+// Handler gives HTTP handler of queue, where base is URL which robot uses to
+// reach it (for file download and upload URLs).
+func (it *WorkItemQueue) Handler(base string) http.Handler {
+	items := "/json-v1/workspaces/{workspace}/workitems/{item}"
+	robotRun := "/process-v1/workspaces/{workspace}/processes/{process}/runs/{run}/robotRuns/{step}"
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+items+"/data", it.authorized(it.getData))
+	mux.HandleFunc("PUT "+items+"/data", it.authorized(it.putData))
+	mux.HandleFunc("GET "+items+"/files", it.authorized(it.listFiles))
+	mux.HandleFunc("GET "+items+"/files/{file}", it.authorized(func(response http.ResponseWriter, request *http.Request) {
+		url := it.fileURL(base, request.PathValue("item"), request.PathValue("file"))
+		workItemReply(response, http.StatusOK, map[string]string{"url": url})
+	}))
+	mux.HandleFunc("DELETE "+items+"/files/{file}", it.authorized(it.deleteFile))
+	mux.HandleFunc("POST "+items+"/files/upload/", it.authorized(func(response http.ResponseWriter, request *http.Request) {
+		upload := workItemFile{}
+		err := json.NewDecoder(request.Body).Decode(&upload)
+		if err != nil || len(upload.Name) == 0 || strings.ContainsAny(upload.Name, `/\`) {
+			workItemError(response, http.StatusBadRequest, "Upload needs valid fileName.")
+			return
+		}
+		url := it.fileURL(base, request.PathValue("item"), upload.Name)
+		workItemReply(response, http.StatusOK, map[string]any{"url": url, "fields": map[string]string{}})
+	}))
+	mux.HandleFunc("POST "+items+"/files/upload/{file}/complete", it.authorized(func(response http.ResponseWriter, request *http.Request) {
+		workItemReply(response, http.StatusOK, map[string]string{})
+	}))
+	mux.HandleFunc("GET /files/{item}/{file}", it.signed(it.downloadFile))
+	mux.HandleFunc("POST /files/{item}/{file}", it.signed(it.uploadFile))
+	mux.HandleFunc("PUT /files/{item}/{file}", it.signed(it.uploadFile))
+	mux.HandleFunc("POST "+robotRun+"/reserve-next-work-item", it.authorized(it.reserve))
+	mux.HandleFunc("POST "+robotRun+"/release-work-item", it.authorized(it.release))
+	mux.HandleFunc("POST /process-v1/workspaces/{workspace}/processes/{process}/work-items/{item}/output", it.authorized(it.createOutput))
+	return mux
+}
+
+func (it *WorkItemQueue) getData(response http.ResponseWriter, request *http.Request) {
+	it.Lock()
+	defer it.Unlock()
+
+	item, ok := it.item(response, request)
+	if ok {
+		workItemReply(response, http.StatusOK, item.Payload)
+	}
+}
+
+func (it *WorkItemQueue) putData(response http.ResponseWriter, request *http.Request) {
+	var payload any
+	err := json.NewDecoder(request.Body).Decode(&payload)
+	if err != nil {
+		workItemError(response, http.StatusBadRequest, "Payload is not valid JSON, reason: %v", err)
+		return
+	}
+	it.Lock()
+	defer it.Unlock()
+
+	item, ok := it.item(response, request)
+	if !ok {
+		return
+	}
+	item.Payload = payload
+	err = it.save()
+	if err != nil {
+		workItemError(response, http.StatusInternalServerError, "%v", err)
+		return
+	}
+	workItemReply(response, http.StatusOK, payload)
+}
+
+func (it *WorkItemQueue) listFiles(response http.ResponseWriter, request *http.Request) {
+	it.Lock()
+	defer it.Unlock()
+
+	item, ok := it.item(response, request)
+	if !ok {
+		return
+	}
+	result := []workItemFile{}
+	for _, name := range slices.Sorted(maps.Keys(item.Files)) {
+		size := int64(0)
+		stat, err := os.Stat(item.Files[name])
+		if err == nil {
+			size = stat.Size()
+		}
+		result = append(result, workItemFile{Name: name, Id: name, Size: size})
+	}
+	workItemReply(response, http.StatusOK, result)
+}
+
+func (it *WorkItemQueue) deleteFile(response http.ResponseWriter, request *http.Request) {
+	it.Lock()
+	defer it.Unlock()
+
+	item, ok := it.item(response, request)
+	if !ok {
+		return
+	}
+	delete(item.Files, request.PathValue("file"))
+	err := it.save()
+	if err != nil {
+		workItemError(response, http.StatusInternalServerError, "%v", err)
+		return
+	}
+	workItemReply(response, http.StatusOK, map[string]string{})
+}
+
+func (it *WorkItemQueue) downloadFile(response http.ResponseWriter, request *http.Request) {
+	it.Lock()
+	item, ok := it.item(response, request)
+	path := ""
+	if ok {
+		path, ok = item.Files[request.PathValue("file")]
+	}
+	it.Unlock()
+	if !ok {
+		http.NotFound(response, request)
+		return
+	}
+	http.ServeFile(response, request, path)
+}
+
+func uploadedContent(request *http.Request) (io.ReadCloser, error) {
+	if !strings.HasPrefix(request.Header.Get("Content-Type"), "multipart/form-data") {
+		return request.Body, nil
+	}
+	file, _, err := request.FormFile("file")
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+func (it *WorkItemQueue) uploadFile(response http.ResponseWriter, request *http.Request) {
+	name := request.PathValue("file")
+	if strings.ContainsAny(name, `/\`) || name == ".." {
+		workItemError(response, http.StatusBadRequest, "Invalid file name %q.", name)
+		return
+	}
+	it.Lock()
+	defer it.Unlock()
+
+	item, ok := it.item(response, request)
+	if !ok {
+		return
+	}
+	source, err := uploadedContent(request)
+	if err != nil {
+		workItemError(response, http.StatusBadRequest, "%v", err)
+		return
+	}
+	defer source.Close()
+	target := filepath.Join(it.files, item.id, name)
+	err = os.MkdirAll(filepath.Dir(target), 0o755)
+	if err == nil {
+		var sink *os.File
+		sink, err = pathlib.Create(target)
+		if err == nil {
+			_, err = io.Copy(sink, source)
+			sink.Close()
+		}
+	}
+	if err == nil {
+		item.Files[name] = target
+		err = it.save()
+	}
+	if err != nil {
+		workItemError(response, http.StatusInternalServerError, "%v", err)
+		return
+	}
+	workItemReply(response, http.StatusNoContent, nil)
+}
+
+func (it *WorkItemQueue) reserve(response http.ResponseWriter, request *http.Request) {
+	it.Lock()
+	defer it.Unlock()
+
+	it.reserved++
+	if it.reserved >= len(it.inputs) {
+		common.Log("Work item simulator: no more input items.")
+		workItemReply(response, http.StatusNoContent, nil)
+		return
+	}
+	item := it.inputs[it.reserved]
+	common.Log("Work item simulator: reserved %s.", item.id)
+	workItemReply(response, http.StatusOK, map[string]string{"workItemId": item.id})
+}
+
+func (it *WorkItemQueue) release(response http.ResponseWriter, request *http.Request) {
+	release := workItemRelease{}
+	err := json.NewDecoder(request.Body).Decode(&release)
+	if err != nil {
+		workItemError(response, http.StatusBadRequest, "Release is not valid JSON, reason: %v", err)
+		return
+	}
+	it.Lock()
+	defer it.Unlock()
+
+	item, ok := it.items[release.Id]
+	if !ok {
+		workItemError(response, http.StatusNotFound, "No work item %q.", release.Id)
+		return
+	}
+	item.state = release.State
+	if release.Exception != nil {
+		common.Log("Work item simulator: released %s as %s with exception %v.", item.id, item.state, release.Exception)
+	} else {
+		common.Log("Work item simulator: released %s as %s.", item.id, item.state)
+	}
+	workItemReply(response, http.StatusOK, map[string]string{})
+}
+
+func (it *WorkItemQueue) createOutput(response http.ResponseWriter, request *http.Request) {
+	body := struct {
+		Payload any `json:"payload"`
+	}{}
+	err := json.NewDecoder(request.Body).Decode(&body)
+	if err != nil {
+		workItemError(response, http.StatusBadRequest, "Output is not valid JSON, reason: %v", err)
+		return
+	}
+	it.Lock()
+	defer it.Unlock()
+
+	_, ok := it.item(response, request)
+	if !ok {
+		return
+	}
+	output := &WorkItem{
+		Payload: body.Payload,
+		Files:   map[string]string{},
+		id:      fmt.Sprintf("output-%d", len(it.outputs)+1),
+	}
+	it.outputs = append(it.outputs, output)
+	it.items[output.id] = output
+	err = it.save()
+	if err != nil {
+		workItemError(response, http.StatusInternalServerError, "%v", err)
+		return
+	}
+	common.Log("Work item simulator: created %s from %s.", output.id, request.PathValue("item"))
+	workItemReply(response, http.StatusOK, map[string]string{"id": output.id})
+}
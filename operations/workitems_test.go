@@ -0,0 +1,106 @@
+package operations
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func workItemCall(t *testing.T, method, url, token, body string) (int, map[string]any) {
+	request, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(token) > 0 {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	reply := map[string]any{}
+	json.NewDecoder(response.Body).Decode(&reply)
+	return response.StatusCode, reply
+}
+
+func TestWorkItemQueueServesInputsAndCollectsOutputs(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	devdata := filepath.Join(t.TempDir(), "devdata")
+	must.Nil(os.MkdirAll(devdata, 0o755))
+	must.Nil(os.WriteFile(filepath.Join(devdata, "invoice.txt"), []byte("invoice"), 0o644))
+	must.Nil(os.WriteFile(filepath.Join(devdata, "work-items.json"), []byte(`[
+	{"payload": {"order": 1}, "files": {"invoice.txt": "invoice.txt"}},
+	{"payload": {"order": 2}}
+]`), 0o644))
+	output := filepath.Join(t.TempDir(), "output", "work-items.json")
+
+	queue, err := LoadWorkItemQueue(filepath.Join(devdata, "work-items.json"), output)
+	must.Nil(err)
+	server := httptest.NewServer(nil)
+	defer server.Close()
+	server.Config.Handler = queue.Handler(server.URL)
+
+	environment := queue.Environment(server.URL)
+	must.Equal("input-1", environment["RC_WORKITEM_ID"])
+	must.Equal(server.URL, environment["RC_API_WORKITEM_HOST"])
+	token := environment["RC_API_WORKITEM_TOKEN"]
+	wont.Equal("", token)
+
+	items := server.URL + "/json-v1/workspaces/local-workspace/workitems/"
+	robotRun := server.URL + "/process-v1/workspaces/local-workspace/processes/local-process/runs/local-run/robotRuns/local-step/"
+
+	status, _ := workItemCall(t, "GET", items+"input-1/data", "wrong", "")
+	must.Equal(http.StatusUnauthorized, status)
+	status, payload := workItemCall(t, "GET", items+"input-1/data", token, "")
+	must.Equal(http.StatusOK, status)
+	must.Equal(1.0, payload["order"])
+
+	status, reply := workItemCall(t, "GET", items+"input-1/files/invoice.txt", token, "")
+	must.Equal(http.StatusOK, status)
+	response, err := http.Get(reply["url"].(string))
+	must.Nil(err)
+	content, _ := io.ReadAll(response.Body)
+	response.Body.Close()
+	must.Equal("invoice", string(content))
+	response, err = http.Get(server.URL + "/files/input-1/invoice.txt")
+	must.Nil(err)
+	response.Body.Close()
+	must.Equal(http.StatusForbidden, response.StatusCode)
+
+	status, reply = workItemCall(t, "POST", robotRun+"reserve-next-work-item", token, "")
+	must.Equal(http.StatusOK, status)
+	must.Equal("input-2", reply["workItemId"])
+	status, _ = workItemCall(t, "POST", robotRun+"reserve-next-work-item", token, "")
+	must.Equal(http.StatusNoContent, status)
+
+	status, reply = workItemCall(t, "POST", server.URL+"/process-v1/workspaces/local-workspace/processes/local-process/work-items/input-2/output", token, `{"payload": {"total": 42}}`)
+	must.Equal(http.StatusOK, status)
+	must.Equal("output-1", reply["id"])
+	status, reply = workItemCall(t, "POST", items+"output-1/files/upload/", token, `{"fileName": "report.txt", "fileSize": 6}`)
+	must.Equal(http.StatusOK, status)
+	status, _ = workItemCall(t, "POST", reply["url"].(string), "", "report")
+	must.Equal(http.StatusNoContent, status)
+
+	status, _ = workItemCall(t, "POST", robotRun+"release-work-item", token, `{"workItemId": "input-2", "state": "COMPLETED"}`)
+	must.Equal(http.StatusOK, status)
+	must.Equal("COMPLETED", queue.items["input-2"].state)
+
+	outputs := []*WorkItem{}
+	content, err = os.ReadFile(output)
+	must.Nil(err)
+	must.Nil(json.Unmarshal(content, &outputs))
+	must.Equal(1, len(outputs))
+	must.Equal(map[string]any{"total": 42.0}, outputs[0].Payload)
+	uploaded, err := os.ReadFile(outputs[0].Files["report.txt"])
+	must.Nil(err)
+	must.Equal("report", string(uploaded))
+}
@@ -3,12 +3,15 @@ package operations
 import (
 	"archive/zip"
 	"bytes"
+	"crypto/sha256"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/joshyorko/rcc/common"
 	"github.com/joshyorko/rcc/pathlib"
@@ -18,18 +21,26 @@ import (
 )
 
 const (
-	backslash = `\`
-	slash     = `/`
+	backslash     = `\`
+	slash         = `/`
+	rccIgnoreFile = `.rccignore`
 )
 
 var (
-	libraryPattern = regexp.MustCompile("(?i)^library[/\\\\]{1,2}[0-9a-f]{2}[/\\\\]{1,2}[0-9a-f]{2}[/\\\\]{1,2}[0-9a-f]{2}[/\\\\]{1,2}[0-9a-f]{64}$")
-	catalogPattern = regexp.MustCompile("(?i)^catalog[/\\\\]{1,2}[0-9a-f]{16}v[0-9a-f]{2}\\.(?:windows|darwin|linux)_(?:amd64|arm64)")
+	libraryPattern  = regexp.MustCompile("(?i)^library[/\\\\]{1,2}[0-9a-f]{2}[/\\\\]{1,2}[0-9a-f]{2}[/\\\\]{1,2}[0-9a-f]{2}[/\\\\]{1,2}[0-9a-f]{64}$")
+	catalogPattern  = regexp.MustCompile("(?i)^catalog[/\\\\]{1,2}[0-9a-f]{16}v[0-9a-f]{2}\\.(?:windows|darwin|linux)_(?:amd64|arm64)")
+	localPattern    = regexp.MustCompile("(?i)^localpackages[/\\\\]{1,2}([0-9a-f]{64})[/\\\\]{1,2}([^/\\\\]*[^./\\\\][^/\\\\]*)$")
+	stableTimestamp = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
 )
 
 type (
 	Verifier func(file *zip.File) error
 
+	wrapEntry struct {
+		fullpath string
+		relative string
+	}
+
 	WriteTarget struct {
 		Source *zip.File
 		Target string
@@ -70,11 +81,15 @@ func zipEntryTarget(directory, entry string) (string, error) {
 	return target, nil
 }
 
+// hololibEntry tells if archive entry is something that import accepts:
+// library blob, catalog, or local package.
+func hololibEntry(name string) bool {
+	return libraryPattern.MatchString(name) || catalogPattern.MatchString(name) || localPattern.MatchString(name)
+}
+
 func HololibZipShape(file *zip.File) error {
-	library := libraryPattern.MatchString(file.Name)
-	catalog := catalogPattern.MatchString(file.Name)
-	if !library && !catalog {
-		return fmt.Errorf("filename %q does not match Holotree catalog or library entry pattern.", file.Name)
+	if !hololibEntry(file.Name) {
+		return fmt.Errorf("filename %q does not match Holotree catalog, library or local package entry pattern.", file.Name)
 	}
 	return nil
 }
@@ -340,6 +355,30 @@ func (it *zipper) Add(fullpath, relativepath string, details os.FileInfo) {
 	}
 }
 
+func (it *zipper) AddStable(fullpath, relativepath string) {
+	common.Debug("- %v", relativepath)
+	source, err := os.Open(fullpath)
+	if err != nil {
+		it.Note(err)
+		return
+	}
+	defer source.Close()
+	header := &zip.FileHeader{
+		Name:     slashed(relativepath),
+		Method:   zip.Deflate,
+		Modified: stableTimestamp,
+	}
+	target, err := it.writer.CreateHeader(header)
+	if err != nil {
+		it.Note(err)
+		return
+	}
+	_, err = io.Copy(target, source)
+	if err != nil {
+		it.Note(err)
+	}
+}
+
 func (it *zipper) AddBlob(relativepath string, blob []byte) {
 	target, err := it.writer.Create(slashed(relativepath))
 	if err != nil {
@@ -449,25 +488,121 @@ func Unzip(directory, zipfile string, force, temporary, flatten bool) error {
 	return FixDirectory(fullpath)
 }
 
+func wrapEntries(directory, zipfile string, ignores, excludes []string) ([]*wrapEntry, error) {
+	config, err := robot.LoadRobotYaml(robot.DetectConfigurationName(directory), false)
+	if err != nil {
+		return nil, err
+	}
+	ignores = append(ignores, config.IgnoreFiles()...)
+	local := filepath.Join(directory, rccIgnoreFile)
+	if pathlib.IsFile(local) {
+		ignores = append(ignores, local)
+	}
+	ignored, err := pathlib.LoadIgnoreFiles(ignores)
+	if err != nil {
+		return nil, err
+	}
+	rules := []pathlib.Ignore{defaultIgnores(zipfile), ignored}
+	for _, pattern := range excludes {
+		rules = append(rules, pathlib.IgnorePattern(pattern))
+	}
+	result := make([]*wrapEntry, 0, 100)
+	collect := func(fullpath, relativepath string, details os.FileInfo) {
+		result = append(result, &wrapEntry{fullpath, slashed(relativepath)})
+	}
+	err = pathlib.ForceWalk(directory, pathlib.ForceFilename("hololib.zip"), pathlib.CompositeIgnore(rules...), collect)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(result, func(left, right int) bool {
+		return result[left].relative < result[right].relative
+	})
+	return result, nil
+}
+
 func Zip(directory, zipfile string, ignores []string) error {
+	return ZipExcluding(directory, zipfile, ignores, nil)
+}
+
+// ZipExcluding wraps robot into reproducible zip, where entries are sorted and
+// have fixed timestamps, so that identical sources produce identical bytes.
+func ZipExcluding(directory, zipfile string, ignores, excludes []string) error {
 	common.Timeline("zip %q to %q", directory, zipfile)
 	defer common.Timeline("zip done")
 	common.Debug("Wrapping %v into %v ...", directory, zipfile)
-	config, err := robot.LoadRobotYaml(robot.DetectConfigurationName(directory), false)
+	entries, err := wrapEntries(directory, zipfile, ignores, excludes)
 	if err != nil {
 		return err
 	}
-	ignores = append(ignores, config.IgnoreFiles()...)
 	zipper, err := newZipper(zipfile)
 	if err != nil {
 		return err
 	}
 	defer zipper.Close()
-	ignored, err := pathlib.LoadIgnoreFiles(ignores)
-	if err != nil {
-		return err
+	for _, entry := range entries {
+		zipper.AddStable(entry.fullpath, entry.relative)
 	}
-	defaults := defaultIgnores(zipfile)
-	pathlib.ForceWalk(directory, pathlib.ForceFilename("hololib.zip"), pathlib.CompositeIgnore(defaults, ignored), zipper.Add)
 	return nil
 }
+
+func zipDigest(entry *zip.File) (string, error) {
+	source, err := entry.Open()
+	if err != nil {
+		return "", err
+	}
+	defer source.Close()
+	digest := sha256.New()
+	_, err = io.Copy(digest, source)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%02x", digest.Sum(nil)), nil
+}
+
+// VerifyWrap compares existing robot zip against source tree, and returns
+// list of differences (missing, extra, and changed entries).
+func VerifyWrap(directory, zipfile string, ignores, excludes []string) ([]string, error) {
+	common.TimelineBegin("wrap verify %q against %q", zipfile, directory)
+	defer common.TimelineEnd()
+
+	entries, err := wrapEntries(directory, zipfile, ignores, excludes)
+	if err != nil {
+		return nil, err
+	}
+	unzip, err := newUnzipper(zipfile, false)
+	if err != nil {
+		return nil, err
+	}
+	defer unzip.Close()
+	wrapped := make(map[string]*zip.File)
+	for _, entry := range unzip.reader.File {
+		if !entry.FileInfo().IsDir() {
+			wrapped[slashed(entry.Name)] = entry
+		}
+	}
+	differences := []string{}
+	for _, entry := range entries {
+		member, ok := wrapped[entry.relative]
+		if !ok {
+			differences = append(differences, fmt.Sprintf("missing: %s", entry.relative))
+			continue
+		}
+		delete(wrapped, entry.relative)
+		expected, err := pathlib.Sha256(entry.fullpath)
+		if err != nil {
+			return nil, err
+		}
+		actual, err := zipDigest(member)
+		if err != nil {
+			return nil, err
+		}
+		if expected != actual {
+			differences = append(differences, fmt.Sprintf("changed: %s", entry.relative))
+		}
+	}
+	for name := range wrapped {
+		differences = append(differences, fmt.Sprintf("extra: %s", name))
+	}
+	sort.Strings(differences)
+	return differences, nil
+}
@@ -93,3 +93,60 @@ func TestZipEntryTargetRejectsTraversalPath(t *testing.T) {
 
 	wont.Equal(nil, err)
 }
+
+func writeWrapFixture(t *testing.T, folder, relative, content string) {
+	t.Helper()
+	fullpath := filepath.Join(folder, relative)
+	if err := os.MkdirAll(filepath.Dir(fullpath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fullpath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWrapIsReproducibleAndVerifiable(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	source := t.TempDir()
+	writeWrapFixture(t, source, "robot.yaml", "tasks:\n  Demo:\n    shell: python task.py\n")
+	writeWrapFixture(t, source, "task.py", "print('hello')\n")
+	writeWrapFixture(t, source, "lib/helper.py", "VALUE = 1\n")
+	writeWrapFixture(t, source, "debug.log", "noise\n")
+	writeWrapFixture(t, source, "devdata/secret.json", "{}\n")
+	writeWrapFixture(t, source, ".rccignore", "# local excludes\n*.log\n")
+
+	targets := t.TempDir()
+	first := filepath.Join(targets, "first.zip")
+	second := filepath.Join(targets, "second.zip")
+	must.Nil(ZipExcluding(source, first, nil, []string{"devdata"}))
+	writeWrapFixture(t, source, "task.py", "print('hello')\n")
+	must.Nil(ZipExcluding(source, second, nil, []string{"devdata"}))
+
+	left, err := os.ReadFile(first)
+	must.Nil(err)
+	right, err := os.ReadFile(second)
+	must.Nil(err)
+	must.Equal(left, right)
+
+	reader, err := zip.OpenReader(first)
+	must.Nil(err)
+	names := []string{}
+	for _, entry := range reader.File {
+		names = append(names, entry.Name)
+		must.Equal(stableTimestamp.Unix(), entry.Modified.Unix())
+	}
+	reader.Close()
+	must.Equal([]string{".rccignore", "lib/helper.py", "robot.yaml", "task.py"}, names)
+
+	differences, err := VerifyWrap(source, first, nil, []string{"devdata"})
+	must.Nil(err)
+	must.Equal(0, len(differences))
+
+	writeWrapFixture(t, source, "task.py", "print('changed')\n")
+	writeWrapFixture(t, source, "lib/added.py", "ADDED = True\n")
+	differences, err = VerifyWrap(source, first, nil, nil)
+	must.Nil(err)
+	must.Equal([]string{"changed: task.py", "missing: devdata/secret.json", "missing: lib/added.py"}, differences)
+	wont.Nil(ZipExcluding(filepath.Join(source, "nonexisting"), second, nil, nil))
+}
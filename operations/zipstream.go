@@ -0,0 +1,200 @@
+package operations
+
+import (
+	"archive/zip"
+	"bufio"
+	"compress/flate"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"strings"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/fail"
+)
+
+// Zip keeps its directory at the end of archive, but every entry also has
+// local header in front of its content. That is enough for reading archives
+// written by rcc export (and other streaming zip writers) while downloading.
+
+const (
+	zipLocalHeader      = 0x04034b50
+	zipDataDescriptor   = 0x08074b50
+	zipCentralDirectory = 0x02014b50
+	zipEndOfDirectory   = 0x06054b50
+	zipDescriptorFlag   = 0x8
+	zip64ExtraId        = 0x0001
+	zip32Limit          = 0xffffffff
+)
+
+type zipLocal struct {
+	Version     uint16
+	Flags       uint16
+	Method      uint16
+	Time        uint16
+	Date        uint16
+	Checksum    uint32
+	Compressed  uint32
+	Size        uint32
+	NameLength  uint16
+	ExtraLength uint16
+}
+
+type zipEntry struct {
+	zipLocal
+	name       string
+	zip64      bool
+	compressed uint64
+}
+
+func readZipEntry(reader io.Reader) (entry *zipEntry, err error) {
+	defer fail.Around(&err)
+
+	entry = &zipEntry{}
+	err = binary.Read(reader, binary.LittleEndian, &entry.zipLocal)
+	fail.On(err != nil, "Failed to read zip entry header, reason: %v", err)
+	name := make([]byte, entry.NameLength)
+	_, err = io.ReadFull(reader, name)
+	fail.On(err != nil, "Failed to read zip entry name, reason: %v", err)
+	extra := make([]byte, entry.ExtraLength)
+	_, err = io.ReadFull(reader, extra)
+	fail.On(err != nil, "Failed to read zip entry %q extra fields, reason: %v", name, err)
+	entry.name = string(name)
+	entry.compressed = uint64(entry.Compressed)
+	for len(extra) >= 4 {
+		id := binary.LittleEndian.Uint16(extra[0:2])
+		size := int(binary.LittleEndian.Uint16(extra[2:4]))
+		fail.On(len(extra) < 4+size, "Zip entry %q has broken extra fields.", entry.name)
+		field := extra[4 : 4+size]
+		if id == zip64ExtraId {
+			entry.zip64 = true
+			if entry.Size == zip32Limit && len(field) >= 8 {
+				field = field[8:]
+			}
+			if entry.Compressed == zip32Limit && len(field) >= 8 {
+				entry.compressed = binary.LittleEndian.Uint64(field[:8])
+			}
+		}
+		extra = extra[4+size:]
+	}
+	return entry, nil
+}
+
+func (it *zipEntry) descriptor() bool {
+	return it.Flags&zipDescriptorFlag != 0
+}
+
+// content gives reader for uncompressed content of entry. Deflated content
+// with unknown size is read thru byte reader, so that inflating does not
+// consume anything after end of compressed stream.
+func (it *zipEntry) content(reader *bufio.Reader) (io.ReadCloser, error) {
+	switch {
+	case it.Method == zip.Deflate && it.descriptor():
+		return flate.NewReader(reader), nil
+	case it.Method == zip.Deflate:
+		return flate.NewReader(bufio.NewReader(io.LimitReader(reader, int64(it.compressed)))), nil
+	case it.Method == zip.Store && !it.descriptor():
+		return io.NopCloser(io.LimitReader(reader, int64(it.compressed))), nil
+	case it.Method == zip.Store:
+		return nil, fmt.Errorf("Stored zip entry %q without known size cannot be streamed.", it.name)
+	default:
+		return nil, fmt.Errorf("Zip entry %q uses unsupported compression method %d.", it.name, it.Method)
+	}
+}
+
+// checksum reads data descriptor (when entry has one) after content, and
+// gives expected CRC-32 of entry.
+func (it *zipEntry) checksum(reader io.Reader, size uint64) (uint32, error) {
+	if !it.descriptor() {
+		return it.Checksum, nil
+	}
+	var first, checksum uint32
+	err := binary.Read(reader, binary.LittleEndian, &first)
+	if err != nil {
+		return 0, err
+	}
+	checksum = first
+	if first == zipDataDescriptor {
+		err = binary.Read(reader, binary.LittleEndian, &checksum)
+		if err != nil {
+			return 0, err
+		}
+	}
+	sizes := make([]byte, 8)
+	if it.zip64 || size >= zip32Limit {
+		sizes = make([]byte, 16)
+	}
+	_, err = io.ReadFull(reader, sizes)
+	return checksum, err
+}
+
+func streamZip(source io.Reader, sink *hololibSink) (err error) {
+	defer fail.Around(&err)
+
+	reader := bufio.NewReader(source)
+	for {
+		var signature uint32
+		err = binary.Read(reader, binary.LittleEndian, &signature)
+		fail.On(err != nil, "Failed to read zip stream, reason: %v", err)
+		if signature == zipCentralDirectory || signature == zipEndOfDirectory {
+			_, err = io.Copy(io.Discard, reader)
+			fail.On(err != nil, "Failed to read zip stream, reason: %v", err)
+			return nil
+		}
+		fail.On(signature != zipLocalHeader, "Unexpected record 0x%08x in zip stream.", signature)
+		entry, err := readZipEntry(reader)
+		fail.Fast(err)
+		fail.Fast(streamZipEntry(reader, entry, sink))
+	}
+}
+
+func streamZipEntry(reader *bufio.Reader, entry *zipEntry, sink *hololibSink) (err error) {
+	defer fail.Around(&err)
+
+	content, err := entry.content(reader)
+	fail.Fast(err)
+	defer content.Close()
+	digest := crc32.NewIEEE()
+	counter := &countingWriter{}
+	tee := io.TeeReader(content, io.MultiWriter(digest, counter))
+	// content must be checked before sink moves it into hololib
+	checked := false
+	check := func() error {
+		checked = true
+		_, err := io.Copy(io.Discard, tee)
+		if err != nil {
+			return fmt.Errorf("Failed to read zip entry %q, reason: %v", entry.name, err)
+		}
+		expected, err := entry.checksum(reader, counter.size)
+		if err != nil {
+			return fmt.Errorf("Failed to read zip entry %q descriptor, reason: %v", entry.name, err)
+		}
+		if digest.Sum32() != expected {
+			return fmt.Errorf("Zip entry %q has wrong checksum.", entry.name)
+		}
+		return nil
+	}
+	name := slashed(entry.name)
+	switch {
+	case strings.HasSuffix(name, "/"):
+	case !hololibEntry(name):
+		fail.On(common.StrictFlag, "Entry %q does not match Holotree catalog, library or local package entry pattern.", entry.name)
+		common.Debug("Ignoring non-hololib entry %q in zip stream.", entry.name)
+	default:
+		fail.Fast(sink.AcceptChecked(entry.name, tee, check))
+	}
+	if !checked {
+		fail.Fast(check())
+	}
+	return nil
+}
+
+type countingWriter struct {
+	size uint64
+}
+
+func (it *countingWriter) Write(content []byte) (int, error) {
+	it.size += uint64(len(content))
+	return len(content), nil
+}
@@ -0,0 +1,58 @@
+package pathlib
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/joshyorko/rcc/common"
+)
+
+const (
+	activitySuffix = ".activity"
+)
+
+// TrackActivity marks long running operation (like pull, environment build,
+// or export) as in-flight, so that other rcc processes can see it, until
+// returned function is called. Marker is lockpid, so it is kept fresh, and
+// left-overs of crashed processes go stale on their own.
+func TrackActivity(kind string) func() {
+	_, err := MakeSharedDir(common.HololibPids())
+	if err != nil {
+		common.Debug("Could not track %q activity, reason: %v", kind, err)
+		return func() {}
+	}
+	latch := LockpidFor(kind + activitySuffix).Keepalive()
+	return func() {
+		close(latch)
+	}
+}
+
+func (it *Lockpid) Activity() (string, bool) {
+	return strings.CutSuffix(it.Basename, activitySuffix)
+}
+
+// Activities lists in-flight activities of all rcc processes, ordered by kind
+// and process id.
+func Activities() (Lockpids, error) {
+	if !IsDir(common.HololibPids()) {
+		return Lockpids{}, nil
+	}
+	holders, err := LoadLockpids()
+	if err != nil {
+		return nil, err
+	}
+	result := Lockpids{}
+	for _, holder := range holders {
+		_, ok := holder.Activity()
+		if ok {
+			result = append(result, holder)
+		}
+	}
+	sort.SliceStable(result, func(left, right int) bool {
+		if result[left].Basename != result[right].Basename {
+			return result[left].Basename < result[right].Basename
+		}
+		return result[left].ProcessID < result[right].ProcessID
+	})
+	return result, nil
+}
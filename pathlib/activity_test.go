@@ -0,0 +1,54 @@
+package pathlib_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/joshyorko/rcc/hamlet"
+	"github.com/joshyorko/rcc/pathlib"
+)
+
+func activityKinds(t *testing.T) []string {
+	activities, err := pathlib.Activities()
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := []string{}
+	for _, activity := range activities {
+		kind, _ := activity.Activity()
+		result = append(result, kind)
+	}
+	return result
+}
+
+func waitActivities(t *testing.T, count int) []string {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		kinds := activityKinds(t)
+		if len(kinds) == count || time.Now().After(deadline) {
+			return kinds
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestActivitiesAreVisibleWhileInFlight(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	t.Setenv("ROBOCORP_HOME", t.TempDir())
+	must.Equal(0, len(activityKinds(t)))
+
+	pull := pathlib.TrackActivity("pull")
+	export := pathlib.TrackActivity("export")
+	must.Equal([]string{"export", "pull"}, waitActivities(t, 2))
+
+	lock := pathlib.LockpidFor("global.lck")
+	must.Nil(os.WriteFile(lock.Location(), []byte{}, 0o644))
+	must.Equal([]string{"export", "pull"}, waitActivities(t, 2))
+
+	pull()
+	must.Equal([]string{"export"}, waitActivities(t, 1))
+	export()
+	must.Equal(0, len(waitActivities(t, 0)))
+}
@@ -28,6 +28,13 @@ func Fake() Releaser {
 	return fake(true)
 }
 
+func lockedWithMarker(file *os.File, filename string) *Locked {
+	lockpid := LockpidFor(filename)
+	latch := lockpid.Keepalive()
+	common.Trace("LOCKER: make marker %v", lockpid.Location())
+	return &Locked{file, latch}
+}
+
 func waitingLockNotification(lockfile, message string, latch chan bool) {
 	delay := 5 * time.Second
 	counter := 0
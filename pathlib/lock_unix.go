@@ -18,6 +18,42 @@ func Locker(filename string, trycount int, sharedLocation bool) (Releaser, error
 		defer common.Stopwatch("LOCKER: Got lock on %v in", filename).Report()
 	}
 	common.Trace("LOCKER: Want lock on: %v", filename)
+	file, err := openLockfile(filename, sharedLocation)
+	if err != nil {
+		return nil, err
+	}
+	err = syscall.Flock(int(file.Fd()), int(syscall.LOCK_EX))
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return lockedWithMarker(file, filename), nil
+}
+
+// TryLocker is non-blocking variant of Locker. When lock is held by someone
+// else, it returns false without error.
+func TryLocker(filename string, sharedLocation bool) (Releaser, bool, error) {
+	if common.WarrantyVoided() || Lockless {
+		return Fake(), true, nil
+	}
+	common.Trace("LOCKER: Try lock on: %v", filename)
+	file, err := openLockfile(filename, sharedLocation)
+	if err != nil {
+		return nil, false, err
+	}
+	err = syscall.Flock(int(file.Fd()), int(syscall.LOCK_EX|syscall.LOCK_NB))
+	if err == syscall.EWOULDBLOCK {
+		file.Close()
+		return nil, false, nil
+	}
+	if err != nil {
+		file.Close()
+		return nil, false, err
+	}
+	return lockedWithMarker(file, filename), true, nil
+}
+
+func openLockfile(filename string, sharedLocation bool) (*os.File, error) {
 	if sharedLocation {
 		_, err := EnsureSharedParentDirectory(filename)
 		if err != nil {
@@ -35,16 +71,10 @@ func Locker(filename string, trycount int, sharedLocation bool) (Releaser, error
 	}
 	_, err = shared.MakeSharedFile(filename)
 	if err != nil {
+		file.Close()
 		return nil, err
 	}
-	err = syscall.Flock(int(file.Fd()), int(syscall.LOCK_EX))
-	if err != nil {
-		return nil, err
-	}
-	lockpid := LockpidFor(filename)
-	latch := lockpid.Keepalive()
-	common.Trace("LOCKER: make marker %v", lockpid.Location())
-	return &Locked{file, latch}, nil
+	return file, nil
 }
 
 func (it Locked) Release() error {
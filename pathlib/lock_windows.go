@@ -13,6 +13,9 @@ import (
 
 const (
 	LOCKFILE_EXCLUSIVE_LOCK = 2
+
+	// ERROR_LOCK_VIOLATION, lock is held by some other handle
+	errLockViolation = syscall.Errno(33)
 )
 
 // https://docs.microsoft.com/en-us/windows/win32/api/fileapi/nf-fileapi-lockfile
@@ -74,15 +77,48 @@ func Locker(filename string, trycount int, sharedLocation bool) (Releaser, error
 			return nil, err
 		}
 		if success {
-			lockpid := LockpidFor(filename)
-			latch := lockpid.Keepalive()
-			common.Trace("LOCKER: make marker %v", lockpid.Location())
-			return &Locked{file, latch}, nil
+			return lockedWithMarker(file, filename), nil
 		}
 		time.Sleep(40 * time.Millisecond)
 	}
 }
 
+// TryLocker is non-blocking variant of Locker. When lock is held by someone
+// else, it returns false without error.
+func TryLocker(filename string, sharedLocation bool) (Releaser, bool, error) {
+	if common.WarrantyVoided() || Lockless {
+		return Fake(), true, nil
+	}
+	common.Trace("LOCKER: Try lock on: %v", filename)
+	var err error
+	if sharedLocation {
+		_, err = EnsureSharedParentDirectory(filename)
+	} else {
+		_, err = EnsureParentDirectory(filename)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE, 0o666)
+	if err != nil {
+		return nil, false, err
+	}
+	_, err = shared.MakeSharedFile(filename)
+	if err != nil {
+		file.Close()
+		return nil, false, err
+	}
+	success, err := trylock(lockFile, file)
+	if !success {
+		file.Close()
+		if err == errLockViolation {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return lockedWithMarker(file, filename), true, nil
+}
+
 func (it Locked) Release() error {
 	success, err := trylock(unlockFile, it)
 	common.Trace("LOCKER: release %v success: %v with err: %v", it.Name(), success, err)
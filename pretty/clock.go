@@ -0,0 +1,49 @@
+package pretty
+
+import (
+	"os"
+	"time"
+
+	"golang.org/x/term"
+)
+
+var (
+	clock        = time.Now
+	terminalSize = func() (int, int, error) {
+		return term.GetSize(int(os.Stdout.Fd()))
+	}
+)
+
+// Now gives current time as views see it. Views (progress, meters, counters,
+// snapshots, and interactive watches) should use this instead of time.Now,
+// so that their output can be rendered deterministically in tests.
+func Now() time.Time {
+	return clock()
+}
+
+// since is time.Since by views clock.
+func since(moment time.Time) time.Duration {
+	return Now().Sub(moment)
+}
+
+// UseClock replaces clock of views, and gives function that restores
+// previous clock.
+func UseClock(replacement func() time.Time) func() {
+	previous := clock
+	clock = replacement
+	return func() {
+		clock = previous
+	}
+}
+
+// UseTerminalSize fixes terminal size that views see, and gives function that
+// restores previous behaviour.
+func UseTerminalSize(columns, rows int) func() {
+	previous := terminalSize
+	terminalSize = func() (int, int, error) {
+		return columns, rows, nil
+	}
+	return func() {
+		terminalSize = previous
+	}
+}
@@ -0,0 +1,44 @@
+package pretty
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var (
+	confirmSource io.Reader = os.Stdin
+	confirmSink   io.Writer = os.Stderr
+)
+
+func confirmReply(form string, details ...interface{}) string {
+	fmt.Fprintf(confirmSink, "%s? %s%s%s ", Yellow, White, fmt.Sprintf(form, details...), Reset)
+	reply, err := bufio.NewReader(confirmSource).ReadString('\n')
+	fmt.Fprintln(confirmSink)
+	if err != nil && len(reply) == 0 {
+		return ""
+	}
+	return strings.TrimSpace(reply)
+}
+
+// Confirm asks yes/no question from interactive user before destructive
+// action. Non-interactive use (and disabled confirmations) always confirms.
+func Confirm(disabled bool, form string, details ...interface{}) bool {
+	if disabled || !Interactive {
+		return true
+	}
+	reply := confirmReply("%s %s[y/N]:%s", fmt.Sprintf(form, details...), Grey, Reset)
+	return strings.EqualFold(reply, "y") || strings.EqualFold(reply, "yes")
+}
+
+// ConfirmTyped is for irreversible actions, and requires user to type
+// expected text exactly, before action is confirmed.
+func ConfirmTyped(disabled bool, expected, form string, details ...interface{}) bool {
+	if disabled || !Interactive {
+		return true
+	}
+	reply := confirmReply("%s Type %s%q%s to confirm:", fmt.Sprintf(form, details...), Cyan, expected, White)
+	return reply == expected
+}
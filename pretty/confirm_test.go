@@ -0,0 +1,39 @@
+package pretty
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestConfirmationsNeedInteractiveAnswers(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	defer func(interactive bool, source io.Reader, sink io.Writer) {
+		Interactive, confirmSource, confirmSink = interactive, source, sink
+	}(Interactive, confirmSource, confirmSink)
+	confirmSink = bytes.NewBuffer(nil)
+
+	Interactive = false
+	must.True(Confirm(false, "delete?"))
+	must.True(ConfirmTyped(false, "name", "delete?"))
+
+	Interactive = true
+	must.True(Confirm(true, "delete?"))
+	must.True(ConfirmTyped(true, "name", "delete?"))
+
+	confirmSource = strings.NewReader("y\n")
+	must.True(Confirm(false, "delete?"))
+	confirmSource = strings.NewReader("\n")
+	wont.True(Confirm(false, "delete?"))
+	confirmSource = strings.NewReader("")
+	wont.True(Confirm(false, "delete?"))
+
+	confirmSource = strings.NewReader("name\n")
+	must.True(ConfirmTyped(false, "name", "delete?"))
+	confirmSource = strings.NewReader("y\n")
+	wont.True(ConfirmTyped(false, "name", "delete?"))
+}
@@ -0,0 +1,86 @@
+package pretty
+
+import (
+	"sync"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+)
+
+// Counter is like Meter, but for counting items (like catalogs) instead of
+// bytes. It stays silent on fast operations, and only starts reporting after
+// first meter interval has passed.
+type Counter struct {
+	sync.Mutex
+	label    string
+	total    int
+	seen     int
+	reported time.Time
+	passed   int64
+	spoken   bool
+}
+
+func NewCounter(label string, total int) *Counter {
+	return &Counter{
+		label:    label,
+		total:    total,
+		reported: Now(),
+	}
+}
+
+func (it *Counter) Tick() {
+	it.Lock()
+	defer it.Unlock()
+
+	it.seen += 1
+	if PlainProgress {
+		it.checkpoint()
+		return
+	}
+	if since(it.reported) > meterInterval {
+		it.reported = Now()
+		it.report("")
+	}
+}
+
+func (it *Counter) checkpoint() {
+	passed := checkpoint(int64(it.seen), int64(it.total))
+	if passed > it.passed {
+		it.passed = passed
+		it.report("")
+	}
+}
+
+func (it *Counter) report(suffix string) {
+	it.spoken = true
+	if PlainProgress {
+		it.plain(suffix)
+		return
+	}
+	spinner := `|/-\`[it.seen%4]
+	if it.total > 0 {
+		common.Log("%s%c %s: %d of %d (%d%%)%s%s", Grey, spinner, it.label, it.seen, it.total, (100*it.seen)/it.total, suffix, Reset)
+	} else {
+		common.Log("%s%c %s: %d%s%s", Grey, spinner, it.label, it.seen, suffix, Reset)
+	}
+	mirrorMeter(it.label, int64(it.seen), int64(it.total))
+}
+
+func (it *Counter) plain(suffix string) {
+	if it.total > 0 {
+		plainLine("%s: %d of %d (%d%%)%s", it.label, it.seen, it.total, (100*it.seen)/it.total, suffix)
+	} else {
+		plainLine("%s: %d%s", it.label, it.seen, suffix)
+	}
+	mirrorMeter(it.label, int64(it.seen), int64(it.total))
+}
+
+func (it *Counter) Done() {
+	it.Lock()
+	defer it.Unlock()
+
+	common.Timeline("%s: %d items", it.label, it.seen)
+	if it.spoken || (PlainProgress && it.total > 0) {
+		it.report(" [done]")
+	}
+}
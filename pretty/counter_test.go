@@ -0,0 +1,57 @@
+package pretty
+
+import (
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestCounterStaysSilentOnFastOperations(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	counter := NewCounter("Loading things", 3)
+	for range 3 {
+		counter.Tick()
+	}
+	counter.Done()
+	must.Equal(3, counter.seen)
+	wont.True(counter.spoken)
+
+	counter.report("")
+	must.True(counter.spoken)
+}
+
+func TestCheckpointsAreFixedPercentages(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	must.Equal(int64(0), checkpoint(0, 100))
+	must.Equal(int64(0), checkpoint(9, 100))
+	must.Equal(int64(10), checkpoint(10, 100))
+	must.Equal(int64(90), checkpoint(99, 100))
+	must.Equal(int64(0), checkpoint(100, 100))
+	must.Equal(int64(0), checkpoint(5, 0))
+	must.Equal(int64(30), checkpoint(1, 3))
+}
+
+func TestPlainCounterSpeaksOnCheckpointsOnly(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	defer func(original bool) { PlainProgress = original }(PlainProgress)
+	PlainProgress = true
+
+	counter := NewCounter("Loading things", 40)
+	for range 3 {
+		counter.Tick()
+	}
+	wont.True(counter.spoken)
+	must.Equal(int64(0), counter.passed)
+
+	counter.Tick()
+	must.True(counter.spoken)
+	must.Equal(int64(10), counter.passed)
+
+	for range 30 {
+		counter.Tick()
+	}
+	must.Equal(int64(80), counter.passed)
+}
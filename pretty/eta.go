@@ -0,0 +1,232 @@
+package pretty
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+)
+
+const (
+	etaRunsKept       = 5
+	etaBlueprintsKept = 50
+	confidenceHigh    = "high"
+	confidenceMedium  = "medium"
+	confidenceLow     = "low"
+)
+
+var (
+	volatilePattern = regexp.MustCompile(`\([^)]*\)|\[[^\]]*\]|"[^"]*"|[0-9]+`)
+	estimator       = newEtaEstimator()
+)
+
+// StepDuration is time from previous progress step to this step.
+type StepDuration struct {
+	Name    string  `json:"name"`
+	Seconds float64 `json:"seconds"`
+}
+
+type etaBlueprint struct {
+	Used int64             `json:"used"`
+	Runs [][]*StepDuration `json:"runs"`
+}
+
+// etaHistory keeps step durations of latest successful environment builds,
+// keyed by blueprint hash, so that remaining build time can be estimated
+// from how same blueprint actually behaved before.
+type etaHistory struct {
+	Blueprints map[string]*etaBlueprint `json:"blueprints"`
+}
+
+// Estimate is expected remaining time of environment build.
+type Estimate struct {
+	Remaining  float64 `json:"remaining"`
+	Samples    int     `json:"samples"`
+	Confidence string  `json:"confidence"`
+}
+
+type etaEstimator struct {
+	sync.Mutex
+	blueprint string
+	history   *etaHistory
+	current   []*StepDuration
+}
+
+func newEtaEstimator() *etaEstimator {
+	return &etaEstimator{
+		current: []*StepDuration{},
+	}
+}
+
+// stepName removes volatile parts (versions, counts, identities, and details
+// in parenthesis or brackets) from progress message, leaving stable name.
+func stepName(step int, message string) string {
+	stable := strings.Join(strings.Fields(volatilePattern.ReplaceAllString(message, "")), " ")
+	return fmt.Sprintf("%02d %s", step, stable)
+}
+
+func loadEtaHistory(filename string) *etaHistory {
+	history := &etaHistory{}
+	content, err := os.ReadFile(filename)
+	if err == nil {
+		err = json.Unmarshal(content, history)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		common.Debug("Ignoring step duration history %q, reason: %v", filename, err)
+	}
+	if history.Blueprints == nil {
+		history.Blueprints = make(map[string]*etaBlueprint)
+	}
+	return history
+}
+
+func (it *etaHistory) remember(blueprint string, run []*StepDuration, when time.Time) {
+	entry, ok := it.Blueprints[blueprint]
+	if !ok {
+		entry = &etaBlueprint{Runs: [][]*StepDuration{}}
+		it.Blueprints[blueprint] = entry
+	}
+	entry.Used = when.Unix()
+	entry.Runs = append(entry.Runs, run)
+	if len(entry.Runs) > etaRunsKept {
+		entry.Runs = entry.Runs[len(entry.Runs)-etaRunsKept:]
+	}
+	if len(it.Blueprints) <= etaBlueprintsKept {
+		return
+	}
+	keys := make([]string, 0, len(it.Blueprints))
+	for key := range it.Blueprints {
+		keys = append(keys, key)
+	}
+	sort.SliceStable(keys, func(left, right int) bool {
+		return it.Blueprints[keys[left]].Used > it.Blueprints[keys[right]].Used
+	})
+	for _, key := range keys[etaBlueprintsKept:] {
+		delete(it.Blueprints, key)
+	}
+}
+
+func (it *etaHistory) save(filename string) error {
+	blob, err := json.Marshal(it)
+	if err != nil {
+		return err
+	}
+	partname := fmt.Sprintf("%s.part%d", filename, os.Getpid())
+	defer os.Remove(partname)
+	err = os.WriteFile(partname, blob, 0o644)
+	if err != nil {
+		return err
+	}
+	return os.Rename(partname, filename)
+}
+
+// remaining is sum of durations of steps after named step in one run.
+func remaining(run []*StepDuration, name string) (float64, bool) {
+	for at, step := range run {
+		if step.Name != name {
+			continue
+		}
+		total := 0.0
+		for _, later := range run[at+1:] {
+			total += later.Seconds
+		}
+		return total, true
+	}
+	return 0, false
+}
+
+func confidence(samples []float64, median float64) string {
+	spread := (samples[len(samples)-1] - samples[0]) / max(median, 1.0)
+	switch {
+	case len(samples) >= 3 && spread <= 0.25:
+		return confidenceHigh
+	case len(samples) >= 2 && spread <= 0.5:
+		return confidenceMedium
+	default:
+		return confidenceLow
+	}
+}
+
+// estimate uses median of remaining times of previous runs, which have seen
+// same step; confidence depends on number of such runs and their spread.
+func (it *etaHistory) estimate(blueprint, name string) (*Estimate, bool) {
+	entry, ok := it.Blueprints[blueprint]
+	if !ok {
+		return nil, false
+	}
+	samples := []float64{}
+	for _, run := range entry.Runs {
+		if seconds, ok := remaining(run, name); ok {
+			samples = append(samples, seconds)
+		}
+	}
+	if len(samples) == 0 {
+		return nil, false
+	}
+	sort.Float64s(samples)
+	median := samples[len(samples)/2]
+	if len(samples)%2 == 0 {
+		median = (samples[len(samples)/2-1] + median) / 2
+	}
+	return &Estimate{
+		Remaining:  median,
+		Samples:    len(samples),
+		Confidence: confidence(samples, median),
+	}, true
+}
+
+func (it *etaEstimator) begin(blueprint string) {
+	it.Lock()
+	defer it.Unlock()
+
+	it.blueprint = blueprint
+	it.history = loadEtaHistory(common.StepDurationsLocation())
+}
+
+func (it *etaEstimator) step(failed bool, step int, message string, elapsed float64) *Estimate {
+	it.Lock()
+	defer it.Unlock()
+
+	name := stepName(step, message)
+	it.current = append(it.current, &StepDuration{Name: name, Seconds: elapsed})
+	run, blueprint := it.current, it.blueprint
+	if step == maxSteps {
+		it.blueprint, it.current = "", []*StepDuration{}
+	}
+	if failed || it.history == nil || len(blueprint) == 0 {
+		return nil
+	}
+	if step == maxSteps {
+		it.history.remember(blueprint, run, Now())
+		err := it.history.save(common.StepDurationsLocation())
+		if err != nil {
+			common.Debug("Could not save step duration history, reason: %v", err)
+		}
+		return nil
+	}
+	estimate, ok := it.history.estimate(blueprint, name)
+	if !ok {
+		return nil
+	}
+	return estimate
+}
+
+// EstimateFor starts remaining time estimation of environment build, based
+// on step durations of previous successful builds of same blueprint.
+func EstimateFor(blueprint string) {
+	estimator.begin(blueprint)
+}
+
+func (it *Estimate) String() string {
+	if it == nil {
+		return ""
+	}
+	remains := time.Duration(it.Remaining * float64(time.Second)).Round(time.Second)
+	return fmt.Sprintf("  [ETA %s, %s confidence]", remains, it.Confidence)
+}
@@ -0,0 +1,74 @@
+package pretty
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func durations(seconds ...float64) []*StepDuration {
+	result := make([]*StepDuration, 0, len(seconds))
+	for at, value := range seconds {
+		result = append(result, &StepDuration{Name: stepName(at, "Step phase."), Seconds: value})
+	}
+	return result
+}
+
+func TestStepNamesAreStableAcrossRuns(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	first := stepName(8, `Running pip install phase. (pip v23.2.1) [layer: 4e67cd8d4b7ba1f3]`)
+	second := stepName(8, `Running pip install phase. (pip v24.0) [layer: 0123456789abcdef]`)
+	must.Equal("08 Running pip install phase.", first)
+	must.Equal(first, second)
+	wont.Equal(first, stepName(8, "Skipping pip install phase -- no pip dependencies."))
+	must.Equal(stepName(2, `Holotree blueprint is "abc" [linux_amd64 with 8 workers on 8 CPUs from "conda.yaml"].`), "02 Holotree blueprint is .")
+}
+
+func TestEstimatesUseHistoricalStepDurations(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	history := loadEtaHistory(filepath.Join(t.TempDir(), "missing.json"))
+	_, ok := history.estimate("feedface", stepName(1, "Step phase."))
+	wont.True(ok)
+
+	now := time.Now()
+	history.remember("feedface", durations(1, 2, 10, 20), now)
+	estimate, ok := history.estimate("feedface", stepName(1, "Step phase."))
+	must.True(ok)
+	must.Equal(30.0, estimate.Remaining)
+	must.Equal(confidenceLow, estimate.Confidence)
+
+	history.remember("feedface", durations(1, 2, 11, 21), now)
+	history.remember("feedface", durations(1, 2, 12, 22), now)
+	estimate, ok = history.estimate("feedface", stepName(1, "Step phase."))
+	must.True(ok)
+	must.Equal(32.0, estimate.Remaining)
+	must.Equal(3, estimate.Samples)
+	must.Equal(confidenceHigh, estimate.Confidence)
+
+	history.remember("feedface", durations(1, 2, 80, 30), now)
+	estimate, ok = history.estimate("feedface", stepName(2, "Step phase."))
+	must.True(ok)
+	must.Equal(21.5, estimate.Remaining)
+	must.Equal(confidenceMedium, estimate.Confidence)
+	must.Equal("  [ETA 22s, medium confidence]", estimate.String())
+
+	for range etaRunsKept + 3 {
+		history.remember("feedface", durations(1, 2, 3), now)
+	}
+	must.Equal(etaRunsKept, len(history.Blueprints["feedface"].Runs))
+
+	for at := range etaBlueprintsKept + 5 {
+		history.remember(stepName(at, "blueprint"), durations(1), now.Add(time.Duration(at)*time.Second))
+	}
+	must.Equal(etaBlueprintsKept, len(history.Blueprints))
+	_, ok = history.Blueprints["feedface"]
+	wont.True(ok)
+
+	filename := filepath.Join(t.TempDir(), "stepdurations.json")
+	must.Nil(history.save(filename))
+	must.Equal(etaBlueprintsKept, len(loadEtaHistory(filename).Blueprints))
+}
@@ -0,0 +1,220 @@
+package pretty
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+var (
+	updateFrames = flag.Bool("update-frames", false, "rewrite golden frames in testdata/frames")
+	frameEpoch   = time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+	bannerLine   = regexp.MustCompile(`@{10,}`)
+)
+
+// frameClock is manually advanced clock, so that durations in frames only
+// depend on scenario, not on speed of test machine.
+type frameClock struct {
+	sync.Mutex
+	now time.Time
+}
+
+func (it *frameClock) Now() time.Time {
+	it.Lock()
+	defer it.Unlock()
+
+	return it.now
+}
+
+func (it *frameClock) advance(duration time.Duration) {
+	it.Lock()
+	defer it.Unlock()
+
+	it.now = it.now.Add(duration)
+}
+
+// frame is what views wrote while scenario was played, with volatile parts
+// (version, temporary paths, and banner widths that follow version length)
+// replaced by stable markers.
+type frame struct {
+	sync.Mutex
+	clock *frameClock
+	lines []string
+	marks map[string]string
+}
+
+func (it *frame) observe(message string) {
+	it.Lock()
+	defer it.Unlock()
+
+	it.lines = append(it.lines, message)
+}
+
+func (it *frame) mark(volatile, marker string) {
+	it.marks[volatile] = marker
+}
+
+func (it *frame) add(form string, details ...interface{}) {
+	it.observe(fmt.Sprintf(form, details...))
+}
+
+func (it *frame) text() string {
+	it.Lock()
+	defer it.Unlock()
+
+	text := strings.Join(it.lines, "\n") + "\n"
+	text = strings.ReplaceAll(text, common.Version, "<version>")
+	for volatile, marker := range it.marks {
+		text = strings.ReplaceAll(text, volatile, marker)
+	}
+	return bannerLine.ReplaceAllString(text, "<banner>")
+}
+
+// paintFrames replaces colors with readable tags, so that color changes are
+// visible in golden frames.
+func paintFrames() func() {
+	colors := map[*string]string{
+		&White: "<white>", &Grey: "<grey>", &Red: "<red>", &Green: "<green>",
+		&Yellow: "<yellow>", &Cyan: "<cyan>", &Magenta: "<magenta>", &Bold: "<bold>",
+		&Reset: "<reset>",
+	}
+	previous := make(map[*string]string, len(colors))
+	for color, tag := range colors {
+		previous[color] = *color
+		*color = tag
+	}
+	return func() {
+		for color, value := range previous {
+			*color = value
+		}
+	}
+}
+
+// renderFrame plays scenario with fixed clock and terminal size, and gives
+// resulting frame.
+func renderFrame(t *testing.T, columns, rows int, scenario func(*frame)) string {
+	clock := &frameClock{now: frameEpoch}
+	defer UseClock(clock.Now)()
+	defer UseTerminalSize(columns, rows)()
+	defer paintFrames()()
+	defer func(interactive, plain bool, mark time.Time, original *snapshotRecorder, eta *etaEstimator) {
+		Interactive, PlainProgress, ProgressMark, recorder, estimator = interactive, plain, mark, original, eta
+	}(Interactive, PlainProgress, ProgressMark, recorder, estimator)
+	Interactive, PlainProgress, ProgressMark = true, false, clock.Now()
+	recorder, estimator = newSnapshotRecorder(), newEtaEstimator()
+
+	result := &frame{clock: clock, lines: []string{}, marks: make(map[string]string)}
+	common.WaitLogs()
+	defer common.UnregisterLogObserver(common.RegisterLogObserver(result.observe))
+	scenario(result)
+	common.WaitLogs()
+	return result.text()
+}
+
+// matchFrame compares frame with golden frame in testdata/frames, or rewrites
+// golden frame when tests are run with -update-frames option.
+func matchFrame(t *testing.T, name, actual string) {
+	must, _ := hamlet.Specifications(t)
+
+	filename := filepath.Join("testdata", "frames", name+".golden")
+	if *updateFrames {
+		must.Nil(os.MkdirAll(filepath.Dir(filename), 0o755))
+		must.Nil(os.WriteFile(filename, []byte(actual), 0o644))
+	}
+	expected, err := os.ReadFile(filename)
+	must.Nil(err)
+	if string(expected) != actual {
+		t.Errorf("frame %q differs from %q (rerun with -update-frames if change is intended):\n%s", name, filename, actual)
+	}
+}
+
+func environmentBuilding(it *frame) {
+	Progress(1, "Fresh [private mode] holotree environment %v.", "abc123")
+	it.clock.advance(120 * time.Millisecond)
+	Progress(2, "Holotree blueprint is %q [linux_amd64 with 8 workers on 8 CPUs from %q].", "0123456789abcdef", "conda.yaml")
+	it.clock.advance(2 * time.Second)
+	Progress(3, "Micromamba install on environment.")
+	meter := NewMeter("Downloading", 4*1024*1024)
+	it.clock.advance(3 * time.Second)
+	meter.Write(make([]byte, 1024*1024))
+	it.clock.advance(3 * time.Second)
+	meter.Write(make([]byte, 3*1024*1024))
+	meter.Done()
+	Warning("Floating dependency %q should be pinned.", "requests")
+	it.clock.advance(1500 * time.Millisecond)
+	Progress(7, "Pip install phase with %d dependencies.", 12)
+}
+
+func TestEnvironmentBuildingFrames(t *testing.T) {
+	matchFrame(t, "environment-building", renderFrame(t, 120, 40, environmentBuilding))
+	matchFrame(t, "environment-building-compact", renderFrame(t, 48, 20, environmentBuilding))
+}
+
+func TestRobotRunningFrame(t *testing.T) {
+	matchFrame(t, "robot-running", renderFrame(t, 120, 40, func(it *frame) {
+		Progress(15, "Fresh holotree done [with 8 workers on 8 CPUs].")
+		it.clock.advance(250 * time.Millisecond)
+		Note("Running against old environment, and not using newer one.")
+		counter := NewCounter("Robot tasks", 3)
+		for range 3 {
+			it.clock.advance(2500 * time.Millisecond)
+			counter.Tick()
+		}
+		counter.Done()
+		Lowlight("Robot output is in %q.", "output")
+	}))
+}
+
+func TestRunCompleteFrame(t *testing.T) {
+	matchFrame(t, "run-complete", renderFrame(t, 120, 40, func(it *frame) {
+		Progress(15, "Fresh holotree done [with 8 workers on 8 CPUs].")
+		it.clock.advance(42 * time.Second)
+		RccPointOfView("Robot run", nil)
+		Ok()
+	}))
+}
+
+func TestFailureFrame(t *testing.T) {
+	matchFrame(t, "failure", renderFrame(t, 120, 40, func(it *frame) {
+		artifacts := t.TempDir()
+		it.mark(artifacts, "<artifacts>")
+		FailureReportAt(artifacts)
+		Progress(1, "Fresh [private mode] holotree environment %v.", "abc123")
+		it.clock.advance(3 * time.Second)
+		Regression(3, "Micromamba install failed with exit code %d.", 1)
+		Warning("Retrying with %s.", "--repodata-ttl 0")
+		it.clock.advance(time.Second)
+		RccPointOfView("Environment creation", errors.New("micromamba failed"))
+		common.WaitLogs()
+
+		blob, err := os.ReadFile(filepath.Join(artifacts, failureReportName))
+		if err != nil {
+			t.Fatal(err)
+		}
+		report := &FailureReport{}
+		if err := json.Unmarshal(blob, report); err != nil {
+			t.Fatal(err)
+		}
+		report.Command = []string{"<command>"}
+		content := &strings.Builder{}
+		encoder := json.NewEncoder(content)
+		encoder.SetEscapeHTML(false)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			t.Fatal(err)
+		}
+		it.add("--- %s ---", failureReportName)
+		it.add("%s", strings.TrimSpace(content.String()))
+	}))
+}
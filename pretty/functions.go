@@ -19,7 +19,8 @@ var (
 )
 
 func init() {
-	ProgressMark = time.Now()
+	ProgressMark = Now()
+	common.RegisterLogObserver(observeLog)
 }
 
 func Ok() error {
@@ -47,6 +48,8 @@ func Note(format string, rest ...interface{}) {
 
 func Warning(format string, rest ...interface{}) {
 	niceform := fmt.Sprintf("%sWarning: %s%s", Yellow, format, Reset)
+	summaryWarnings.Add(1)
+	recorder.warning(fmt.Sprintf(format, rest...))
 	common.Log(niceform, rest...)
 }
 
@@ -66,6 +69,7 @@ func Exit(code int, format string, rest ...interface{}) {
 		niceform = fmt.Sprintf("%s%s%s", Green, format, Reset)
 	} else {
 		niceform = fmt.Sprintf("%s%s%s", Red, format, Reset)
+		failureSnapshot(fmt.Sprintf("exit code %d", code), snapshotReason(format, rest))
 	}
 	common.Exit(code, niceform, rest...)
 }
@@ -77,6 +81,22 @@ func Guard(truth bool, code int, format string, rest ...interface{}) {
 	}
 }
 
+// ExitCoded is like Exit, but failure also shows stable error code, its
+// remediation hint, and how to get full description of it.
+func ExitCoded(code int, failure string, format string, rest ...interface{}) {
+	summaryLock.Lock()
+	summaryFailure = failure
+	summaryLock.Unlock()
+	Exit(code, "%s", common.ExplainFailure(failure, fmt.Sprintf(format, rest...)))
+}
+
+// GuardCoded is like Guard, but with stable error code (see ExitCoded).
+func GuardCoded(truth bool, code int, failure string, format string, rest ...interface{}) {
+	if !truth {
+		ExitCoded(code, failure, format, rest...)
+	}
+}
+
 func RccPointOfView(context string, err error) {
 	explain := fmt.Sprintf(rccpov, common.Version, common.ControllerType, context)
 	printer := Lowlight
@@ -86,6 +106,7 @@ func RccPointOfView(context string, err error) {
 		printer = Highlight
 		message = fmt.Sprintf("@@@  %s FAILURE, reason: %q. See details above.  @@@", explain, err)
 		journal = fmt.Sprintf("%s FAILURE, reason: %s", explain, err)
+		defer failureSnapshot(context, err.Error())
 	}
 	banner := strings.Repeat("@", len(message))
 	printer(banner)
@@ -95,7 +116,7 @@ func RccPointOfView(context string, err error) {
 }
 
 func Regression(step int, form string, details ...interface{}) {
-	progress(Red, step, form, details...)
+	progress(Red, true, step, form, details...)
 }
 
 func Progress(step int, form string, details ...interface{}) {
@@ -103,15 +124,24 @@ func Progress(step int, form string, details ...interface{}) {
 	if step == maxSteps {
 		color = Green
 	}
-	progress(color, step, form, details...)
+	progress(color, false, step, form, details...)
 }
 
-func progress(color string, step int, form string, details ...interface{}) {
+func progress(color string, failed bool, step int, form string, details ...interface{}) {
 	previous := ProgressMark
-	ProgressMark = time.Now()
+	ProgressMark = Now()
 	delta := ProgressMark.Sub(previous).Round(1 * time.Millisecond).Seconds()
 	message := fmt.Sprintf(form, details...)
-	common.Log("%s####  Progress: %02d/%d  %s  %8.3fs  %s%s", color, step, maxSteps, common.Version, delta, message, Reset)
+	estimate := estimator.step(failed, step, message, delta)
+	if PlainProgress {
+		plainLine("####  Progress: %02d/%d  %s  %8.3fs  %s%s", step, maxSteps, common.Version, delta, message, estimate)
+	} else if Compact() {
+		common.Log("%s#### %02d/%d %s%s", color, step, maxSteps, Clip(message, TerminalColumns()-12), Reset)
+	} else {
+		common.Log("%s####  Progress: %02d/%d  %s  %8.3fs  %s%s%s", color, step, maxSteps, common.Version, delta, message, estimate, Reset)
+	}
 	common.Timeline("%d/%d %s", step, maxSteps, message)
 	common.RunJournal("environment", "build", "Progress: %02d/%d  %s  %8.3fs  %s", step, maxSteps, common.Version, delta, message)
+	mirrorStep(step, maxSteps, message, delta, estimate)
+	snapshotStep(failed, step, maxSteps, message, delta)
 }
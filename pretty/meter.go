@@ -0,0 +1,111 @@
+package pretty
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+)
+
+const (
+	meterInterval = 2 * time.Second
+)
+
+type Meter struct {
+	sync.Mutex
+	label    string
+	total    int64
+	seen     int64
+	started  time.Time
+	reported time.Time
+	passed   int64
+}
+
+func NewMeter(label string, total int64) *Meter {
+	now := Now()
+	return &Meter{
+		label:    label,
+		total:    total,
+		started:  now,
+		reported: now,
+	}
+}
+
+func humaneBytes(size int64) string {
+	value := float64(size)
+	for _, suffix := range []string{"b", "K", "M", "G"} {
+		if value < 1024.0 || suffix == "G" {
+			return fmt.Sprintf("%3.1f%s", value, suffix)
+		}
+		value = value / 1024.0
+	}
+	return fmt.Sprintf("%db", size)
+}
+
+func (it *Meter) Write(blob []byte) (int, error) {
+	it.Lock()
+	defer it.Unlock()
+
+	it.seen += int64(len(blob))
+	if PlainProgress {
+		passed := checkpoint(it.seen, it.total)
+		if passed > it.passed {
+			it.passed = passed
+			it.report("")
+		}
+		return len(blob), nil
+	}
+	if since(it.reported) > meterInterval {
+		it.reported = Now()
+		it.report("")
+	}
+	return len(blob), nil
+}
+
+func (it *Meter) Seen() int64 {
+	it.Lock()
+	defer it.Unlock()
+
+	return it.seen
+}
+
+func (it *Meter) rate() string {
+	elapsed := since(it.started).Seconds()
+	if elapsed <= 0.0 {
+		return "N/A"
+	}
+	return humaneBytes(int64(float64(it.seen)/elapsed)) + "/s"
+}
+
+func (it *Meter) report(suffix string) {
+	if PlainProgress {
+		it.plain(suffix)
+		return
+	}
+	if it.total > 0 {
+		share := (100 * it.seen) / it.total
+		common.Log("%s%s: %s of %s (%d%%) at %s%s%s", Grey, it.label, humaneBytes(it.seen), humaneBytes(it.total), share, it.rate(), suffix, Reset)
+	} else {
+		common.Log("%s%s: %s at %s%s%s", Grey, it.label, humaneBytes(it.seen), it.rate(), suffix, Reset)
+	}
+	common.Timeline("%s: %d bytes", it.label, it.seen)
+	mirrorMeter(it.label, it.seen, it.total)
+}
+
+func (it *Meter) plain(suffix string) {
+	if it.total > 0 {
+		plainLine("%s: %s of %s (%d%%)%s", it.label, humaneBytes(it.seen), humaneBytes(it.total), (100*it.seen)/it.total, suffix)
+	} else {
+		plainLine("%s: %s%s", it.label, humaneBytes(it.seen), suffix)
+	}
+	common.Timeline("%s: %d bytes", it.label, it.seen)
+	mirrorMeter(it.label, it.seen, it.total)
+}
+
+func (it *Meter) Done() {
+	it.Lock()
+	defer it.Unlock()
+
+	it.report(" [done]")
+}
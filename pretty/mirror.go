@@ -0,0 +1,223 @@
+package pretty
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"sync"
+
+	"github.com/joshyorko/rcc/common"
+)
+
+const (
+	mirrorBacklog = 200
+	mirrorBuffer  = 64
+	mirrorPage    = `<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>rcc dashboard mirror</title></head>
+<body style="background:#111;color:#ddd;font-family:monospace">
+<h3 id="step">waiting for progress ...</h3>
+<pre id="logs"></pre>
+<script>
+const logs = document.getElementById("logs");
+const step = document.getElementById("step");
+const source = new EventSource("/events");
+function eta(it) { return it ? " [ETA " + Math.round(it.remaining) + "s, " + it.confidence + " confidence]" : ""; }
+function show(it) { step.textContent = it.step + "/" + it.steps + " " + it.message + eta(it.estimate); }
+source.addEventListener("state", function(event) {
+  const state = JSON.parse(event.data);
+  logs.textContent = state.logs.join("\n") + "\n";
+  if (state.steps.length > 0) { show(state.steps[state.steps.length-1]); }
+});
+source.addEventListener("log", function(event) { logs.textContent += JSON.parse(event.data) + "\n"; });
+source.addEventListener("step", function(event) { show(JSON.parse(event.data)); });
+</script>
+</body></html>
+`
+)
+
+var (
+	ansiPattern = regexp.MustCompile("\x1b\\[[0-9;]*[a-zA-Z]")
+	mirror      *dashboardMirror
+)
+
+type MirrorStep struct {
+	Step     int       `json:"step"`
+	Steps    int       `json:"steps"`
+	Message  string    `json:"message"`
+	Elapsed  float64   `json:"elapsed"`
+	Estimate *Estimate `json:"estimate,omitempty"`
+}
+
+type MirrorMeter struct {
+	Label string `json:"label"`
+	Seen  int64  `json:"seen"`
+	Total int64  `json:"total"`
+}
+
+type MirrorState struct {
+	Version string                  `json:"version"`
+	Started int64                   `json:"started"`
+	Steps   []*MirrorStep           `json:"steps"`
+	Meters  map[string]*MirrorMeter `json:"meters"`
+	Logs    []string                `json:"logs"`
+}
+
+type dashboardMirror struct {
+	sync.Mutex
+	state   *MirrorState
+	clients map[chan []byte]bool
+}
+
+func newDashboardMirror() *dashboardMirror {
+	return &dashboardMirror{
+		state: &MirrorState{
+			Version: common.Version,
+			Started: Now().Unix(),
+			Steps:   []*MirrorStep{},
+			Meters:  make(map[string]*MirrorMeter),
+			Logs:    []string{},
+		},
+		clients: make(map[chan []byte]bool),
+	}
+}
+
+func sseEvent(kind string, payload interface{}) []byte {
+	blob, err := json.Marshal(payload)
+	if err != nil {
+		return nil
+	}
+	return []byte(fmt.Sprintf("event: %s\ndata: %s\n\n", kind, blob))
+}
+
+func (it *dashboardMirror) broadcast(kind string, payload interface{}) {
+	event := sseEvent(kind, payload)
+	if event == nil {
+		return
+	}
+	for client := range it.clients {
+		select {
+		case client <- event:
+		default:
+		}
+	}
+}
+
+func (it *dashboardMirror) log(message string) {
+	it.Lock()
+	defer it.Unlock()
+
+	clean := ansiPattern.ReplaceAllString(message, "")
+	it.state.Logs = append(it.state.Logs, clean)
+	if len(it.state.Logs) > mirrorBacklog {
+		it.state.Logs = it.state.Logs[len(it.state.Logs)-mirrorBacklog:]
+	}
+	it.broadcast("log", clean)
+}
+
+func (it *dashboardMirror) step(step *MirrorStep) {
+	it.Lock()
+	defer it.Unlock()
+
+	it.state.Steps = append(it.state.Steps, step)
+	it.broadcast("step", step)
+}
+
+func (it *dashboardMirror) meter(meter *MirrorMeter) {
+	it.Lock()
+	defer it.Unlock()
+
+	it.state.Meters[meter.Label] = meter
+	it.broadcast("meter", meter)
+}
+
+func (it *dashboardMirror) snapshot() []byte {
+	it.Lock()
+	defer it.Unlock()
+
+	blob, _ := json.Marshal(it.state)
+	return blob
+}
+
+func (it *dashboardMirror) subscribe() (chan []byte, []byte) {
+	it.Lock()
+	defer it.Unlock()
+
+	client := make(chan []byte, mirrorBuffer)
+	it.clients[client] = true
+	return client, sseEvent("state", it.state)
+}
+
+func (it *dashboardMirror) unsubscribe(client chan []byte) {
+	it.Lock()
+	defer it.Unlock()
+
+	delete(it.clients, client)
+}
+
+func (it *dashboardMirror) serveEvents(response http.ResponseWriter, request *http.Request) {
+	flusher, ok := response.(http.Flusher)
+	if !ok {
+		response.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	client, initial := it.subscribe()
+	defer it.unsubscribe(client)
+	headers := response.Header()
+	headers.Set("Content-Type", "text/event-stream")
+	headers.Set("Cache-Control", "no-cache")
+	response.Write(initial)
+	flusher.Flush()
+	for {
+		select {
+		case <-request.Context().Done():
+			return
+		case event := <-client:
+			response.Write(event)
+			flusher.Flush()
+		}
+	}
+}
+
+func (it *dashboardMirror) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/events", it.serveEvents)
+	mux.HandleFunc("/state", func(response http.ResponseWriter, request *http.Request) {
+		response.Header().Set("Content-Type", "application/json")
+		response.Write(it.snapshot())
+	})
+	mux.HandleFunc("/", func(response http.ResponseWriter, request *http.Request) {
+		if request.URL.Path != "/" {
+			http.NotFound(response, request)
+			return
+		}
+		response.Header().Set("Content-Type", "text/html; charset=utf-8")
+		response.Write([]byte(mirrorPage))
+	})
+	return mux
+}
+
+func StartMirror(address string) (string, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return "", err
+	}
+	mirror = newDashboardMirror()
+	go http.Serve(listener, mirror.handler())
+	location := fmt.Sprintf("http://%s/", listener.Addr())
+	common.Log("Dashboard mirror is available at %s (events at %sevents).", location, location)
+	return location, nil
+}
+
+func mirrorStep(step, steps int, message string, elapsed float64, estimate *Estimate) {
+	if mirror != nil {
+		mirror.step(&MirrorStep{Step: step, Steps: steps, Message: message, Elapsed: elapsed, Estimate: estimate})
+	}
+}
+
+func mirrorMeter(label string, seen, total int64) {
+	if mirror != nil {
+		mirror.meter(&MirrorMeter{Label: label, Seen: seen, Total: total})
+	}
+}
@@ -0,0 +1,53 @@
+package pretty_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/hamlet"
+	"github.com/joshyorko/rcc/pretty"
+)
+
+func TestDashboardMirrorPublishesProgressAndLogs(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	location, err := pretty.StartMirror("127.0.0.1:0")
+	must.Nil(err)
+	wont.Equal("", location)
+
+	events, err := http.Get(location + "events")
+	must.Nil(err)
+	defer events.Body.Close()
+	must.Equal("text/event-stream", events.Header.Get("Content-Type"))
+	reader := bufio.NewReader(events.Body)
+	first, err := reader.ReadString('\n')
+	must.Nil(err)
+	must.Equal("event: state\n", first)
+
+	pretty.Progress(1, "Mirror test step %d.", 1)
+	common.WaitLogs()
+
+	seen := map[string]bool{}
+	for !(seen["event: step"] && seen["event: log"]) {
+		line, err := reader.ReadString('\n')
+		must.Nil(err)
+		seen[strings.TrimSpace(line)] = true
+	}
+
+	response, err := http.Get(location + "state")
+	must.Nil(err)
+	defer response.Body.Close()
+	blob, err := io.ReadAll(response.Body)
+	must.Nil(err)
+	state := &pretty.MirrorState{}
+	must.Nil(json.Unmarshal(blob, state))
+	must.Equal(1, len(state.Steps))
+	must.Equal("Mirror test step 1.", state.Steps[0].Message)
+	must.True(len(state.Logs) > 0)
+	wont.True(strings.Contains(strings.Join(state.Logs, "\n"), "\x1b["))
+}
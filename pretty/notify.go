@@ -0,0 +1,68 @@
+package pretty
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+)
+
+const (
+	notifyTitle = "rcc"
+)
+
+var (
+	notifyAfter   time.Duration
+	notifyDesktop bool
+	notifyBell    bool
+)
+
+// ConfigureNotifications sets up completion notifications of long
+// environment builds and robot runs. Notifications are only given, when
+// run lasted longer than after.
+func ConfigureNotifications(after time.Duration, desktop, bell bool) {
+	notifyAfter, notifyDesktop, notifyBell = after, desktop, bell
+}
+
+// notifiable tells if run had environment build or robot run phase, and
+// lasted long enough, to deserve completion notification.
+func notifiable(elapsed time.Duration) bool {
+	if !notifyDesktop && !notifyBell {
+		return false
+	}
+	summaryLock.Lock()
+	_, building := summaryDurations["environment"]
+	_, running := summaryDurations["run"]
+	summaryLock.Unlock()
+	return (building || running) && elapsed >= notifyAfter
+}
+
+func notificationMessage(command string, code int, elapsed time.Duration) string {
+	elapsed = elapsed.Round(time.Second)
+	if code == 0 {
+		return fmt.Sprintf("%q completed successfully in %s.", command, elapsed)
+	}
+	if len(summaryFailure) > 0 {
+		return fmt.Sprintf("%q failed [%s] with exit code %d after %s.", command, summaryFailure, code, elapsed)
+	}
+	return fmt.Sprintf("%q failed with exit code %d after %s.", command, code, elapsed)
+}
+
+// NotifyCompletion rings terminal bell and/or shows desktop notification,
+// when long environment build or robot run completes, if configured so.
+func NotifyCompletion(command string, code int) {
+	elapsed := time.Duration(common.Clock.Elapsed())
+	if !notifiable(elapsed) {
+		return
+	}
+	if notifyBell && Interactive {
+		fmt.Fprint(os.Stderr, "\a")
+	}
+	if notifyDesktop {
+		err := desktopNotification(notifyTitle, notificationMessage(command, code, elapsed))
+		if err != nil {
+			common.Debug("Could not show desktop notification, reason: %v", err)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package pretty
+
+import (
+	"os/exec"
+)
+
+func desktopNotification(title, message string) error {
+	script := []string{
+		"-e", "on run argv",
+		"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+		"-e", "end run",
+	}
+	return exec.Command("osascript", append(script, title, message)...).Run()
+}
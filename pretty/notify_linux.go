@@ -0,0 +1,9 @@
+package pretty
+
+import (
+	"os/exec"
+)
+
+func desktopNotification(title, message string) error {
+	return exec.Command("notify-send", "--app-name", title, title, message).Run()
+}
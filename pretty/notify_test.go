@@ -0,0 +1,40 @@
+package pretty
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestNotificationsNeedLongBuildOrRun(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	defer ConfigureNotifications(0, false, false)
+	summaryLock.Lock()
+	saved := summaryDurations
+	summaryDurations = make(map[string]float64)
+	summaryLock.Unlock()
+	defer func() {
+		summaryLock.Lock()
+		summaryDurations = saved
+		summaryLock.Unlock()
+	}()
+
+	ConfigureNotifications(time.Minute, false, false)
+	wont.True(notifiable(time.Hour))
+
+	ConfigureNotifications(time.Minute, true, false)
+	wont.True(notifiable(time.Hour))
+
+	SummaryDuration("run", time.Now())
+	must.True(notifiable(time.Hour))
+	wont.True(notifiable(time.Second))
+
+	ConfigureNotifications(0, false, true)
+	must.True(notifiable(time.Second))
+
+	must.True(strings.Contains(notificationMessage("rcc run", 0, 90*time.Second), "successfully in 1m30s"))
+	must.True(strings.Contains(notificationMessage("rcc run", 3, 90*time.Second), "exit code 3 after 1m30s"))
+}
@@ -0,0 +1,22 @@
+package pretty
+
+import (
+	"os"
+	"os/exec"
+)
+
+const toastScript = `
+[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] > $null
+$template = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$texts = $template.GetElementsByTagName('text')
+$texts.Item(0).AppendChild($template.CreateTextNode($env:RCC_NOTIFY_TITLE)) > $null
+$texts.Item(1).AppendChild($template.CreateTextNode($env:RCC_NOTIFY_MESSAGE)) > $null
+$toast = [Windows.UI.Notifications.ToastNotification]::new($template)
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier($env:RCC_NOTIFY_TITLE).Show($toast)
+`
+
+func desktopNotification(title, message string) error {
+	command := exec.Command("powershell.exe", "-NoProfile", "-NonInteractive", "-Command", toastScript)
+	command.Env = append(os.Environ(), "RCC_NOTIFY_TITLE="+title, "RCC_NOTIFY_MESSAGE="+message)
+	return command.Run()
+}
@@ -8,7 +8,6 @@ import (
 	"strings"
 
 	"github.com/joshyorko/rcc/common"
-	"golang.org/x/term"
 )
 
 var (
@@ -18,7 +17,7 @@ var (
 )
 
 func Page(content []byte) {
-	width, height, err := term.GetSize(int(os.Stdout.Fd()))
+	width, height, err := terminalSize()
 	if err != nil || !Interactive {
 		common.Stdout("\n%s\n", content)
 		return
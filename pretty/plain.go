@@ -0,0 +1,36 @@
+package pretty
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+)
+
+const (
+	checkpointPercent = 10
+)
+
+// PlainProgress is output mode for log collectors. Progress is reported as
+// timestamped lines at fixed percentage checkpoints, without spinners or
+// colors, so that same work produces same lines regardless of its speed.
+var PlainProgress bool
+
+func plainProgressWanted(stderr bool) bool {
+	return PlainProgress || len(os.Getenv("RCC_PLAIN_PROGRESS")) > 0 || !stderr
+}
+
+// checkpoint gives latest passed percentage checkpoint of seen from total.
+// Last checkpoint (100%) is left for final [done] line.
+func checkpoint(seen, total int64) int64 {
+	if total <= 0 || seen >= total {
+		return 0
+	}
+	return ((100 * seen / total) / checkpointPercent) * checkpointPercent
+}
+
+func plainLine(form string, details ...interface{}) {
+	stamp := Now().UTC().Format(time.RFC3339)
+	common.Log("%s %s", stamp, fmt.Sprintf(form, details...))
+}
@@ -0,0 +1,174 @@
+package pretty
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+)
+
+const (
+	snapshotLogLines  = 50
+	snapshotWarnings  = 20
+	failureReportName = "failure-report.json"
+)
+
+var (
+	recorder = newSnapshotRecorder()
+)
+
+type SnapshotStep struct {
+	Step    int     `json:"step"`
+	Steps   int     `json:"steps"`
+	Message string  `json:"message"`
+	Elapsed float64 `json:"elapsed"`
+	Status  string  `json:"status"`
+}
+
+type FailureReport struct {
+	Version  string          `json:"version"`
+	Command  []string        `json:"command"`
+	Context  string          `json:"context"`
+	Reason   string          `json:"reason"`
+	Started  string          `json:"started"`
+	Failed   string          `json:"failed"`
+	Elapsed  float64         `json:"elapsed"`
+	Steps    []*SnapshotStep `json:"steps"`
+	Warnings []string        `json:"warnings"`
+	Logs     []string        `json:"logs"`
+}
+
+type snapshotRecorder struct {
+	sync.Mutex
+	started  time.Time
+	target   string
+	reported bool
+	steps    []*SnapshotStep
+	warnings []string
+	logs     []string
+}
+
+func newSnapshotRecorder() *snapshotRecorder {
+	return &snapshotRecorder{
+		started:  Now(),
+		steps:    []*SnapshotStep{},
+		warnings: []string{},
+		logs:     []string{},
+	}
+}
+
+func tail(lines []string, limit int) []string {
+	if len(lines) > limit {
+		return lines[len(lines)-limit:]
+	}
+	return lines
+}
+
+func (it *snapshotRecorder) log(message string) {
+	it.Lock()
+	defer it.Unlock()
+
+	it.logs = tail(append(it.logs, ansiPattern.ReplaceAllString(message, "")), snapshotLogLines)
+}
+
+func (it *snapshotRecorder) warning(message string) {
+	it.Lock()
+	defer it.Unlock()
+
+	it.warnings = tail(append(it.warnings, ansiPattern.ReplaceAllString(message, "")), snapshotWarnings)
+}
+
+func (it *snapshotRecorder) step(step *SnapshotStep) {
+	it.Lock()
+	defer it.Unlock()
+
+	it.steps = append(it.steps, step)
+}
+
+func (it *snapshotRecorder) report(context, reason string) *FailureReport {
+	it.Lock()
+	defer it.Unlock()
+
+	now := Now()
+	return &FailureReport{
+		Version:  common.Version,
+		Command:  os.Args,
+		Context:  context,
+		Reason:   reason,
+		Started:  it.started.Format(time.RFC3339),
+		Failed:   now.Format(time.RFC3339),
+		Elapsed:  now.Sub(it.started).Round(time.Millisecond).Seconds(),
+		Steps:    append([]*SnapshotStep{}, it.steps...),
+		Warnings: append([]string{}, it.warnings...),
+		Logs:     append([]string{}, it.logs...),
+	}
+}
+
+func (it *snapshotRecorder) save(context, reason string) (string, error) {
+	it.Lock()
+	target, reported := it.target, it.reported
+	it.reported = it.reported || len(target) > 0
+	it.Unlock()
+
+	if len(target) == 0 || reported {
+		return "", nil
+	}
+	common.WaitLogs()
+	blob, err := json.MarshalIndent(it.report(context, reason), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	filename := filepath.Join(target, failureReportName)
+	err = os.WriteFile(filename, blob, 0o644)
+	if err != nil {
+		return "", err
+	}
+	common.RunJournal("failure report", filename, "dashboard snapshot of failed run [%s]", context)
+	return filename, nil
+}
+
+func observeLog(message string) {
+	recorder.log(message)
+	if mirror != nil {
+		mirror.log(message)
+	}
+}
+
+// FailureReportIn gives location of failure report in artifact directory.
+func FailureReportIn(directory string) string {
+	return filepath.Join(directory, failureReportName)
+}
+
+// FailureReportAt sets artifact directory where failure report is written, if run fails.
+func FailureReportAt(directory string) {
+	recorder.Lock()
+	defer recorder.Unlock()
+
+	recorder.target = directory
+}
+
+func failureSnapshot(context, reason string) {
+	filename, err := recorder.save(context, reason)
+	if err != nil {
+		common.Debug("Could not write failure report, reason: %v", err)
+	}
+	if len(filename) > 0 {
+		common.Log("%sFailure report with dashboard snapshot is at %q.%s", Grey, filename, Reset)
+	}
+}
+
+func snapshotReason(format string, details []interface{}) string {
+	return fmt.Sprintf(format, details...)
+}
+
+func snapshotStep(failed bool, step, steps int, message string, elapsed float64) {
+	status := "ok"
+	if failed {
+		status = "failed"
+	}
+	recorder.step(&SnapshotStep{Step: step, Steps: steps, Message: message, Elapsed: elapsed, Status: status})
+}
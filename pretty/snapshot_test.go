@@ -0,0 +1,52 @@
+package pretty
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestFailureReportCapturesDashboardSnapshotOnce(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	defer func(original *snapshotRecorder) {
+		recorder = original
+	}(recorder)
+	recorder = newSnapshotRecorder()
+
+	RccPointOfView("no target", errors.New("ignored"))
+	wont.True(recorder.reported)
+
+	directory := t.TempDir()
+	FailureReportAt(directory)
+	Progress(1, "Snapshot step %d.", 1)
+	Regression(2, "Snapshot step %d failed.", 2)
+	Warning("Snapshot %s.", "warning")
+	for at := 0; at < snapshotLogLines+10; at++ {
+		common.Log("snapshot log line %d", at)
+	}
+	RccPointOfView("Snapshot test", errors.New("first failure"))
+	RccPointOfView("Snapshot test", errors.New("second failure"))
+
+	blob, err := os.ReadFile(filepath.Join(directory, failureReportName))
+	must.Nil(err)
+	report := &FailureReport{}
+	must.Nil(json.Unmarshal(blob, report))
+	must.Equal("Snapshot test", report.Context)
+	must.Equal("first failure", report.Reason)
+	must.Equal(2, len(report.Steps))
+	must.Equal("ok", report.Steps[0].Status)
+	must.Equal("failed", report.Steps[1].Status)
+	must.Equal("Snapshot step 2 failed.", report.Steps[1].Message)
+	must.Equal([]string{"Snapshot warning."}, report.Warnings)
+	must.Equal(snapshotLogLines, len(report.Logs))
+	logs := strings.Join(report.Logs, "\n")
+	must.True(strings.Contains(logs, "snapshot log line 59"))
+	wont.True(strings.Contains(logs, "snapshot log line 0\n"))
+}
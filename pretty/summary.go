@@ -0,0 +1,142 @@
+package pretty
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+)
+
+var (
+	summaryWarnings  atomic.Int64
+	summaryLock      sync.Mutex
+	summaryDurations = make(map[string]float64)
+	summaryFailure   string
+	summaryContracts []*ContractSummary
+)
+
+// ContractSummary is outcome of task inputs or outputs contract check.
+type ContractSummary struct {
+	Kind    string   `json:"kind"`
+	Policy  string   `json:"policy"`
+	Checked int      `json:"checked"`
+	Missing []string `json:"missing,omitempty"`
+}
+
+// RunSummary is single line machine readable outcome of rcc run, for
+// orchestration systems, that run rcc in --silent mode.
+type RunSummary struct {
+	Version   string             `json:"version"`
+	Command   string             `json:"command"`
+	Exit      int                `json:"exit"`
+	Error     string             `json:"error,omitempty"`
+	Blueprint string             `json:"blueprint,omitempty"`
+	Space     string             `json:"space,omitempty"`
+	Warnings  int64              `json:"warnings"`
+	Durations map[string]float64 `json:"durations"`
+	Contracts []*ContractSummary `json:"contracts,omitempty"`
+	When      string             `json:"when"`
+}
+
+// SummaryDuration records duration (in seconds) of named phase of run, to be
+// included in end-of-run summary.
+func SummaryDuration(name string, started time.Time) {
+	summaryLock.Lock()
+	defer summaryLock.Unlock()
+
+	summaryDurations[name] += time.Since(started).Seconds()
+}
+
+// SummaryContract records outcome of task contract check, to be included in
+// end-of-run summary.
+func SummaryContract(kind, policy string, checked int, missing []string) {
+	summaryLock.Lock()
+	defer summaryLock.Unlock()
+
+	summaryContracts = append(summaryContracts, &ContractSummary{
+		Kind:    kind,
+		Policy:  policy,
+		Checked: checked,
+		Missing: missing,
+	})
+}
+
+func newRunSummary(command string, code int) *RunSummary {
+	summaryLock.Lock()
+	defer summaryLock.Unlock()
+
+	durations := make(map[string]float64, len(summaryDurations)+1)
+	for name, seconds := range summaryDurations {
+		durations[name] = seconds
+	}
+	durations["total"] = common.Clock.Elapsed().Seconds()
+	return &RunSummary{
+		Version:   common.Version,
+		Command:   command,
+		Exit:      code,
+		Error:     summaryFailure,
+		Blueprint: common.EnvironmentHash,
+		Space:     common.HolotreeSpace,
+		Warnings:  summaryWarnings.Load(),
+		Durations: durations,
+		Contracts: summaryContracts,
+		When:      Now().Format(time.RFC3339),
+	}
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (it nopCloser) Close() error {
+	return nil
+}
+
+func summarySink(target string) (io.WriteCloser, error) {
+	descriptor, ok := strings.CutPrefix(target, "fd:")
+	if !ok {
+		return os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	}
+	fd, err := strconv.Atoi(descriptor)
+	if err != nil || fd < 1 {
+		return nil, fmt.Errorf("Invalid summary target %q, expected form is like 'fd:3'.", target)
+	}
+	switch fd {
+	case 1:
+		return nopCloser{os.Stdout}, nil
+	case 2:
+		return nopCloser{os.Stderr}, nil
+	}
+	return os.NewFile(uintptr(fd), target), nil
+}
+
+// EmitSummary writes end-of-run summary into configured target, if there is
+// one. This is done even in --silent mode.
+func EmitSummary(command string, code int) {
+	target := common.RccSummaryTarget()
+	if len(target) == 0 {
+		return
+	}
+	blob, err := json.Marshal(newRunSummary(command, code))
+	if err != nil {
+		common.Debug("Could not create run summary, reason: %v", err)
+		return
+	}
+	sink, err := summarySink(target)
+	if err != nil {
+		common.Debug("Could not open run summary target %q, reason: %v", target, err)
+		return
+	}
+	defer sink.Close()
+	_, err = fmt.Fprintf(sink, "%s\n", blob)
+	if err != nil {
+		common.Debug("Could not write run summary to %q, reason: %v", target, err)
+	}
+}
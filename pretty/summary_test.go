@@ -0,0 +1,47 @@
+package pretty
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestSummaryIsWrittenOnlyWhenTargetIsGiven(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	_, err := summarySink("fd:x")
+	wont.Nil(err)
+	_, err = summarySink("fd:0")
+	wont.Nil(err)
+
+	target := filepath.Join(t.TempDir(), "summary.jsonl")
+	t.Setenv(common.RCC_SUMMARY_TO, "")
+	EmitSummary("test", 0)
+	_, err = os.Stat(target)
+	wont.Nil(err)
+
+	t.Setenv(common.RCC_SUMMARY_TO, target)
+	before := summaryWarnings.Load()
+	Warning("summary test warning")
+	SummaryDuration("environment", time.Now().Add(-2*time.Second))
+	EmitSummary("test:run", 3)
+	EmitSummary("test:run", 0)
+
+	content, err := os.ReadFile(target)
+	must.Nil(err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	must.Equal(2, len(lines))
+	summary := &RunSummary{}
+	must.Nil(json.Unmarshal([]byte(lines[0]), summary))
+	must.Equal("test:run", summary.Command)
+	must.Equal(3, summary.Exit)
+	must.Equal(before+1, summary.Warnings)
+	must.True(summary.Durations["environment"] >= 2.0)
+	must.True(summary.Durations["total"] > 0.0)
+}
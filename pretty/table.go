@@ -0,0 +1,312 @@
+package pretty
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+var (
+	filterPattern   = regexp.MustCompile(`^\s*([a-zA-Z][a-zA-Z0-9_.-]*)\s*(>=|<=|!=|=|>|<|~)\s*(.*?)\s*$`)
+	quantityPattern = regexp.MustCompile(`^(?i)(-?[0-9]+(?:\.[0-9]+)?)\s*([dwkmgt]?)b?$`)
+)
+
+var quantityUnits = map[string]float64{
+	"":  1,
+	"d": 1,
+	"w": 7,
+	"k": 1 << 10,
+	"m": 1 << 20,
+	"g": 1 << 30,
+	"t": 1 << 40,
+}
+
+type TableRow map[string]any
+
+type TableColumn struct {
+	Name   string
+	Title  string
+	Format func(any) string
+}
+
+// Table is listing with server style controls: filtering, sorting, column
+// selection, and output as aligned text or as CSV.
+type Table struct {
+	Columns  []*TableColumn
+	Rows     []TableRow
+	selected []*TableColumn
+}
+
+type tableFilter struct {
+	column   string
+	operator string
+	expected string
+}
+
+func NewTable(columns ...*TableColumn) *Table {
+	return &Table{
+		Columns:  columns,
+		Rows:     []TableRow{},
+		selected: columns,
+	}
+}
+
+func (it *Table) Add(row TableRow) {
+	it.Rows = append(it.Rows, row)
+}
+
+func (it *Table) column(name string) (*TableColumn, bool) {
+	for _, column := range it.Columns {
+		if column.Name == name {
+			return column, true
+		}
+	}
+	return nil, false
+}
+
+func (it *Table) ColumnNames() []string {
+	result := make([]string, 0, len(it.Columns))
+	for _, column := range it.Columns {
+		result = append(result, column.Name)
+	}
+	return result
+}
+
+func (it *Table) unknown(name string) error {
+	return fmt.Errorf("Unknown column %q, known columns are: %s", name, strings.Join(it.ColumnNames(), ", "))
+}
+
+func parseQuantity(text string) (float64, bool) {
+	found := quantityPattern.FindStringSubmatch(strings.TrimSpace(text))
+	if found == nil {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(found[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	return value * quantityUnits[strings.ToLower(found[2])], true
+}
+
+func numeric(value any) (float64, bool) {
+	switch actual := value.(type) {
+	case int:
+		return float64(actual), true
+	case int64:
+		return float64(actual), true
+	case uint64:
+		return float64(actual), true
+	case float64:
+		return actual, true
+	}
+	return 0, false
+}
+
+func compare(left, right any) int {
+	first, leftok := numeric(left)
+	second, rightok := numeric(right)
+	if leftok && rightok {
+		switch {
+		case first < second:
+			return -1
+		case first > second:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(fmt.Sprintf("%v", left), fmt.Sprintf("%v", right))
+}
+
+func (it *tableFilter) accepts(value any) (bool, error) {
+	if it.operator == "~" {
+		pattern, err := regexp.Compile(it.expected)
+		if err != nil {
+			return false, fmt.Errorf("Invalid pattern in filter %q, reason: %v", it.expected, err)
+		}
+		return pattern.MatchString(fmt.Sprintf("%v", value)), nil
+	}
+	var expected any = it.expected
+	if _, ok := numeric(value); ok {
+		quantity, ok := parseQuantity(it.expected)
+		if !ok {
+			return false, fmt.Errorf("Column %q is numeric, but %q is not a number.", it.column, it.expected)
+		}
+		expected = quantity
+	}
+	delta := compare(value, expected)
+	switch it.operator {
+	case "=":
+		return delta == 0, nil
+	case "!=":
+		return delta != 0, nil
+	case ">":
+		return delta > 0, nil
+	case ">=":
+		return delta >= 0, nil
+	case "<":
+		return delta < 0, nil
+	case "<=":
+		return delta <= 0, nil
+	}
+	return false, fmt.Errorf("Unknown filter operator %q.", it.operator)
+}
+
+// Filter keeps only rows which match all given expressions, like "idle>30d",
+// "size>=1G", "controller=rcc.user", or "blueprint~^c8b".
+func (it *Table) Filter(expressions []string) error {
+	filters := make([]*tableFilter, 0, len(expressions))
+	for _, expression := range expressions {
+		found := filterPattern.FindStringSubmatch(expression)
+		if found == nil {
+			return fmt.Errorf("Invalid filter expression %q, expected form like idle>30d or controller=rcc.user", expression)
+		}
+		if _, ok := it.column(found[1]); !ok {
+			return it.unknown(found[1])
+		}
+		filters = append(filters, &tableFilter{found[1], found[2], found[3]})
+	}
+	kept := make([]TableRow, 0, len(it.Rows))
+search:
+	for _, row := range it.Rows {
+		for _, filter := range filters {
+			ok, err := filter.accepts(row[filter.column])
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue search
+			}
+		}
+		kept = append(kept, row)
+	}
+	it.Rows = kept
+	return nil
+}
+
+// Sort orders rows by named column, and leading "-" reverses the order.
+func (it *Table) Sort(order string) error {
+	name := strings.TrimPrefix(order, "-")
+	reverse := len(name) < len(order)
+	if _, ok := it.column(name); !ok {
+		return it.unknown(name)
+	}
+	sort.SliceStable(it.Rows, func(left, right int) bool {
+		delta := compare(it.Rows[left][name], it.Rows[right][name])
+		if reverse {
+			return delta > 0
+		}
+		return delta < 0
+	})
+	return nil
+}
+
+// Select limits output to given columns, in given order.
+func (it *Table) Select(names []string) error {
+	selected := make([]*TableColumn, 0, len(names))
+	for _, name := range names {
+		column, ok := it.column(strings.TrimSpace(name))
+		if !ok {
+			return it.unknown(name)
+		}
+		selected = append(selected, column)
+	}
+	it.selected = selected
+	return nil
+}
+
+func (it *TableColumn) text(value any) string {
+	if it.Format != nil {
+		return it.Format(value)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func (it *Table) WriteText(sink io.Writer) {
+	tabbed := tabwriter.NewWriter(sink, 2, 4, 2, ' ', 0)
+	titles := make([]string, 0, len(it.selected))
+	lines := make([]string, 0, len(it.selected))
+	for _, column := range it.selected {
+		titles = append(titles, column.Title)
+		lines = append(lines, strings.Repeat("-", len(column.Title)))
+	}
+	fmt.Fprintf(tabbed, "%s\n", strings.Join(titles, "\t"))
+	fmt.Fprintf(tabbed, "%s\n", strings.Join(lines, "\t"))
+	for _, row := range it.Rows {
+		cells := make([]string, 0, len(it.selected))
+		for _, column := range it.selected {
+			cells = append(cells, column.text(row[column.Name]))
+		}
+		fmt.Fprintf(tabbed, "%s\n", strings.Join(cells, "\t"))
+	}
+	tabbed.Flush()
+}
+
+func markdownCell(text string) string {
+	clean := strings.TrimSpace(ansiPattern.ReplaceAllString(text, ""))
+	clean = strings.ReplaceAll(clean, "|", "\\|")
+	return strings.ReplaceAll(clean, "\n", " ")
+}
+
+// WriteMarkdown writes selected columns with formatted values as markdown
+// table, with given title as heading. ANSI styling is stripped.
+func (it *Table) WriteMarkdown(sink io.Writer, title string) {
+	if len(title) > 0 {
+		fmt.Fprintf(sink, "## %s\n\n", markdownCell(title))
+	}
+	titles := make([]string, 0, len(it.selected))
+	lines := make([]string, 0, len(it.selected))
+	for _, column := range it.selected {
+		titles = append(titles, markdownCell(column.Title))
+		lines = append(lines, "---")
+	}
+	fmt.Fprintf(sink, "| %s |\n", strings.Join(titles, " | "))
+	fmt.Fprintf(sink, "| %s |\n", strings.Join(lines, " | "))
+	for _, row := range it.Rows {
+		cells := make([]string, 0, len(it.selected))
+		for _, column := range it.selected {
+			cells = append(cells, markdownCell(column.text(row[column.Name])))
+		}
+		fmt.Fprintf(sink, "| %s |\n", strings.Join(cells, " | "))
+	}
+}
+
+// Export writes table into file, as CSV if filename ends with ".csv" and
+// otherwise as markdown.
+func (it *Table) Export(filename, title string) error {
+	sink, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	defer sink.Close()
+	if strings.EqualFold(filepath.Ext(filename), ".csv") {
+		return it.WriteCSV(sink)
+	}
+	it.WriteMarkdown(sink, title)
+	return nil
+}
+
+// WriteCSV writes selected columns with raw (unformatted) values.
+func (it *Table) WriteCSV(sink io.Writer) error {
+	writer := csv.NewWriter(sink)
+	header := make([]string, 0, len(it.selected))
+	for _, column := range it.selected {
+		header = append(header, column.Name)
+	}
+	writer.Write(header)
+	for _, row := range it.Rows {
+		cells := make([]string, 0, len(it.selected))
+		for _, column := range it.selected {
+			cells = append(cells, ansiPattern.ReplaceAllString(fmt.Sprintf("%v", row[column.Name]), ""))
+		}
+		writer.Write(cells)
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,100 @@
+package pretty_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+	"github.com/joshyorko/rcc/pretty"
+)
+
+func spaceTable() *pretty.Table {
+	table := pretty.NewTable(
+		&pretty.TableColumn{Name: "space", Title: "Space"},
+		&pretty.TableColumn{Name: "controller", Title: "Controller"},
+		&pretty.TableColumn{Name: "idle", Title: "Idle"},
+		&pretty.TableColumn{Name: "size", Title: "Size"},
+	)
+	table.Add(pretty.TableRow{"space": "alpha", "controller": "rcc.user", "idle": 45, "size": uint64(3 << 30)})
+	table.Add(pretty.TableRow{"space": "beta", "controller": "citests", "idle": 2, "size": uint64(5 << 20)})
+	table.Add(pretty.TableRow{"space": "gamma", "controller": "rcc.user", "idle": 31, "size": uint64(700 << 20)})
+	table.Add(pretty.TableRow{"space": "delta", "controller": "rcc.user", "idle": 5, "size": uint64(2 << 30)})
+	return table
+}
+
+func spaceNames(table *pretty.Table) string {
+	names := []string{}
+	for _, row := range table.Rows {
+		names = append(names, row["space"].(string))
+	}
+	return strings.Join(names, ",")
+}
+
+func TestTableFiltersWithQuantitiesAndStrings(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	table := spaceTable()
+	must.Nil(table.Filter([]string{"idle>30d", "controller=rcc.user"}))
+	must.Equal("alpha,gamma", spaceNames(table))
+
+	table = spaceTable()
+	must.Nil(table.Filter([]string{"size>=1G"}))
+	must.Equal("alpha,delta", spaceNames(table))
+
+	table = spaceTable()
+	must.Nil(table.Filter([]string{"controller!=rcc.user"}))
+	must.Equal("beta", spaceNames(table))
+
+	table = spaceTable()
+	must.Nil(table.Filter([]string{"space~^(a|d)"}))
+	must.Equal("alpha,delta", spaceNames(table))
+
+	wont.Nil(spaceTable().Filter([]string{"bogus>1"}))
+	wont.Nil(spaceTable().Filter([]string{"idle>soon"}))
+	wont.Nil(spaceTable().Filter([]string{"idle"}))
+}
+
+func TestTableSortsAndSelectsColumns(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	table := spaceTable()
+	must.Nil(table.Sort("-size"))
+	must.Equal("alpha,delta,gamma,beta", spaceNames(table))
+	must.Nil(table.Sort("idle"))
+	must.Equal("beta,delta,gamma,alpha", spaceNames(table))
+	must.Nil(table.Sort("space"))
+	must.Equal("alpha,beta,delta,gamma", spaceNames(table))
+	wont.Nil(table.Sort("bogus"))
+
+	wont.Nil(table.Select([]string{"space", "bogus"}))
+	must.Nil(table.Select([]string{"size", "space"}))
+	sink := &strings.Builder{}
+	must.Nil(table.WriteCSV(sink))
+	lines := strings.Split(strings.TrimSpace(sink.String()), "\n")
+	must.Equal(5, len(lines))
+	must.Equal("size,space", lines[0])
+	must.Equal("3221225472,alpha", lines[1])
+
+	sink = &strings.Builder{}
+	table.WriteText(sink)
+	must.True(strings.HasPrefix(sink.String(), "Size"))
+	wont.True(strings.Contains(sink.String(), "rcc.user"))
+}
+
+func TestTableWritesMarkdownWithoutStyling(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	table := pretty.NewTable(
+		&pretty.TableColumn{Name: "space", Title: "Space"},
+		&pretty.TableColumn{Name: "robots", Title: "Robots"},
+	)
+	table.Add(pretty.TableRow{"space": "alpha", "robots": "\x1b[32mfirst | second\x1b[0m"})
+	sink := &strings.Builder{}
+	table.WriteMarkdown(sink, "Spaces")
+	must.Equal("## Spaces\n\n| Space | Robots |\n| --- | --- |\n| alpha | first \\| second |\n", sink.String())
+
+	sink.Reset()
+	must.Nil(table.WriteCSV(sink))
+	must.Equal("space,robots\nalpha,first | second\n", sink.String())
+	wont.True(strings.Contains(sink.String(), "\x1b"))
+}
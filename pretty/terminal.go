@@ -0,0 +1,36 @@
+package pretty
+
+const (
+	MinimumColumns = 60
+)
+
+// TerminalColumns returns current width of terminal, or zero when output is
+// not interactive terminal. Size is checked on every call, so that views
+// follow terminal resizes on their next refresh.
+func TerminalColumns() int {
+	if !Interactive {
+		return 0
+	}
+	width, _, err := terminalSize()
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// Compact tells if terminal is currently too narrow for full layout, and views
+// should use compact layout instead. When terminal grows again, full layout
+// is used automatically.
+func Compact() bool {
+	columns := TerminalColumns()
+	return columns > 0 && columns < MinimumColumns
+}
+
+// Clip shortens plain text to fit into given width, when width is known.
+func Clip(text string, width int) string {
+	runes := []rune(text)
+	if width < 4 || len(runes) <= width {
+		return text
+	}
+	return string(runes[:width-3]) + "..."
+}
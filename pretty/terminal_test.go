@@ -0,0 +1,16 @@
+package pretty
+
+import (
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestCanClipTextToTerminalWidth(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	must.Equal("short", Clip("short", 20))
+	must.Equal("longer...", Clip("longer text here", 9))
+	must.Equal("unknown width", Clip("unknown width", 0))
+	must.Equal("äöå...", Clip("äöåäöåäöå", 6))
+}
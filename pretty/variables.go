@@ -39,8 +39,9 @@ func Setup() {
 	Interactive = stdin && stdout && stderr
 
 	localSetup(Interactive)
+	PlainProgress = plainProgressWanted(stderr)
 
-	common.Trace("Interactive mode enabled: %v; colors enabled: %v; icons enabled: %v", Interactive, !Disabled, Iconic)
+	common.Trace("Interactive mode enabled: %v; colors enabled: %v; icons enabled: %v; plain progress: %v", Interactive, !Disabled, Iconic, PlainProgress)
 	if Interactive && !Disabled && !Colorless {
 		White = csi("97m")
 		Grey = csi("90m")
@@ -0,0 +1,220 @@
+package remotree
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/joshyorko/rcc/common"
+)
+
+// Access is network level access control for rccremote. Empty allowlist
+// allows all peers, and client CA requires TLS (certificate and key).
+type Access struct {
+	AllowCidrs  []string
+	Certificate string
+	Key         string
+	ClientCA    string
+}
+
+type accessGuard struct {
+	networks []*net.IPNet
+	mutual   bool
+}
+
+func parseNetworks(cidrs []string) ([]*net.IPNet, error) {
+	result := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if len(cidr) == 0 {
+			continue
+		}
+		if !strings.Contains(cidr, "/") {
+			address := net.ParseIP(cidr)
+			if address == nil {
+				return nil, fmt.Errorf("Invalid address %q in allowlist.", cidr)
+			}
+			if address.To4() != nil {
+				cidr = fmt.Sprintf("%s/32", cidr)
+			} else {
+				cidr = fmt.Sprintf("%s/128", cidr)
+			}
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid CIDR %q in allowlist, reason: %v", cidr, err)
+		}
+		result = append(result, network)
+	}
+	return result, nil
+}
+
+func (it *Access) TLS() bool {
+	return len(it.Certificate) > 0 || len(it.Key) > 0
+}
+
+func (it *Access) guard() (*accessGuard, error) {
+	networks, err := parseNetworks(it.AllowCidrs)
+	if err != nil {
+		return nil, err
+	}
+	if len(it.ClientCA) > 0 && !it.TLS() {
+		return nil, fmt.Errorf("Client CA requires TLS, so both server certificate and key must be given.")
+	}
+	return &accessGuard{
+		networks: networks,
+		mutual:   len(it.ClientCA) > 0,
+	}, nil
+}
+
+func (it *Access) tlsConfig() (*tls.Config, error) {
+	if !it.TLS() {
+		return nil, nil
+	}
+	certificate, err := tls.LoadX509KeyPair(it.Certificate, it.Key)
+	if err != nil {
+		return nil, fmt.Errorf("Could not load server certificate %q and key %q, reason: %v", it.Certificate, it.Key, err)
+	}
+	config := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{certificate},
+	}
+	if len(it.ClientCA) == 0 {
+		return config, nil
+	}
+	content, err := os.ReadFile(it.ClientCA)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read client CA %q, reason: %v", it.ClientCA, err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(content) {
+		return nil, fmt.Errorf("No PEM certificates found from client CA %q.", it.ClientCA)
+	}
+	config.ClientCAs = pool
+	// handshake accepts missing certificates, so that rejected peers get
+	// clear 403 response, and audit log entry, instead of TLS alert
+	config.ClientAuth = tls.VerifyClientCertIfGiven
+	return config, nil
+}
+
+func (it *accessGuard) allowed(remote string) bool {
+	if len(it.networks) == 0 {
+		return true
+	}
+	host, _, err := net.SplitHostPort(remote)
+	if err != nil {
+		host = remote
+	}
+	address := net.ParseIP(host)
+	if address == nil {
+		return false
+	}
+	for _, network := range it.networks {
+		if network.Contains(address) {
+			return true
+		}
+	}
+	return false
+}
+
+func (it *accessGuard) verified(request *http.Request) (string, bool) {
+	if !it.mutual {
+		return "", true
+	}
+	if request.TLS == nil || len(request.TLS.VerifiedChains) == 0 || len(request.TLS.VerifiedChains[0]) == 0 {
+		return "", false
+	}
+	return request.TLS.VerifiedChains[0][0].Subject.CommonName, true
+}
+
+func (it *accessGuard) reject(response http.ResponseWriter, request *http.Request, reason string) {
+	common.Log("Access denied: %s %s %q from %q [%s]", request.Proto, request.Method, request.URL.Path, request.RemoteAddr, reason)
+	common.RunJournal("rccremote", "denied", "%s %q from %q: %s", request.Method, request.URL.Path, request.RemoteAddr, reason)
+	http.Error(response, fmt.Sprintf("403 Forbidden: %s", reason), http.StatusForbidden)
+}
+
+func (it *accessGuard) serve(handler http.Handler, response http.ResponseWriter, request *http.Request) {
+	if !it.allowed(request.RemoteAddr) {
+		it.reject(response, request, "peer address not in allowlist")
+		return
+	}
+	subject, ok := it.verified(request)
+	if !ok {
+		it.reject(response, request, "no verified client certificate")
+		return
+	}
+	if len(subject) > 0 {
+		common.Trace("Client certificate %q accepted from %q.", subject, request.RemoteAddr)
+	}
+	handler.ServeHTTP(response, request)
+}
+
+func (it *accessGuard) wrap(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		it.serve(handler, response, request)
+	})
+}
+
+// gatekeeper holds current access guard and TLS configuration, so that they
+// can be replaced on configuration reload without restarting server.
+type gatekeeper struct {
+	secure bool
+	guard  atomic.Pointer[accessGuard]
+	tls    atomic.Pointer[tls.Config]
+}
+
+func newGatekeeper(access *Access) (*gatekeeper, error) {
+	keeper := &gatekeeper{secure: access.TLS()}
+	err := keeper.update(access)
+	if err != nil {
+		return nil, err
+	}
+	return keeper, nil
+}
+
+// update replaces access guard and TLS configuration. Turning TLS on or off
+// needs restart, since it changes how server listens.
+func (it *gatekeeper) update(access *Access) error {
+	if access.TLS() != it.secure {
+		return fmt.Errorf("Turning TLS on or off needs restart of rccremote.")
+	}
+	guard, err := access.guard()
+	if err != nil {
+		return err
+	}
+	config, err := access.tlsConfig()
+	if err != nil {
+		return err
+	}
+	it.guard.Store(guard)
+	it.tls.Store(config)
+	return nil
+}
+
+// tlsConfig gives listener configuration, which uses current TLS
+// configuration for each new connection.
+func (it *gatekeeper) tlsConfig() *tls.Config {
+	if !it.secure {
+		return nil
+	}
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return &it.tls.Load().Certificates[0], nil
+		},
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return it.tls.Load(), nil
+		},
+	}
+}
+
+func (it *gatekeeper) wrap(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		it.guard.Load().serve(handler, response, request)
+	})
+}
@@ -0,0 +1,123 @@
+package remotree
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func okHandler() http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		response.Write([]byte("ok"))
+	})
+}
+
+func issueCertificate(t *testing.T, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey, tls.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate, err := x509.ParseCertificate(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return certificate, key, tls.Certificate{Certificate: [][]byte{raw}, PrivateKey: key}
+}
+
+func TestAllowlistAcceptsOnlyListedNetworks(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	_, err := parseNetworks([]string{"10.0.0.0/33"})
+	wont.Nil(err)
+	_, err = parseNetworks([]string{"not-an-address"})
+	wont.Nil(err)
+
+	_, err = (&Access{ClientCA: "ca.pem"}).guard()
+	wont.Nil(err)
+
+	guard, err := (&Access{AllowCidrs: []string{"10.0.0.0/8", "192.168.1.7", "fd00::/8"}}).guard()
+	must.Nil(err)
+	must.True(guard.allowed("10.1.2.3:4653"))
+	must.True(guard.allowed("192.168.1.7:1234"))
+	must.True(guard.allowed("[fd00::1]:4653"))
+	wont.True(guard.allowed("192.168.1.8:1234"))
+	wont.True(guard.allowed("garbage"))
+
+	open, err := (&Access{}).guard()
+	must.Nil(err)
+	must.True(open.allowed("203.0.113.1:80"))
+
+	server := httptest.NewServer(guard.wrap(okHandler()))
+	defer server.Close()
+	response, err := http.Get(server.URL + "/parts/demo")
+	must.Nil(err)
+	response.Body.Close()
+	must.Equal(http.StatusForbidden, response.StatusCode)
+
+	local, err := (&Access{AllowCidrs: []string{"127.0.0.0/8", "::1"}}).guard()
+	must.Nil(err)
+	allowed := httptest.NewServer(local.wrap(okHandler()))
+	defer allowed.Close()
+	response, err = http.Get(allowed.URL + "/parts/demo")
+	must.Nil(err)
+	response.Body.Close()
+	must.Equal(http.StatusOK, response.StatusCode)
+}
+
+func TestMutualTlsRequiresClientCertificateFromCA(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	authority, authorityKey, _ := issueCertificate(t, "test-ca", nil, nil)
+	_, _, trusted := issueCertificate(t, "trusted-client", authority, authorityKey)
+	_, _, stranger := issueCertificate(t, "stranger", nil, nil)
+
+	guard := &accessGuard{mutual: true}
+	server := httptest.NewUnstartedServer(guard.wrap(okHandler()))
+	pool := x509.NewCertPool()
+	pool.AddCert(authority)
+	server.TLS = &tls.Config{ClientCAs: pool, ClientAuth: tls.VerifyClientCertIfGiven}
+	server.StartTLS()
+	defer server.Close()
+
+	status := func(certificates ...tls.Certificate) int {
+		transport := server.Client().Transport.(*http.Transport).Clone()
+		transport.TLSClientConfig.Certificates = certificates
+		response, err := (&http.Client{Transport: transport}).Get(server.URL + "/catalog/demo")
+		if err != nil {
+			return 0
+		}
+		response.Body.Close()
+		return response.StatusCode
+	}
+
+	must.Equal(http.StatusForbidden, status())
+	must.Equal(http.StatusOK, status(trusted))
+	must.True(status(stranger) != http.StatusOK)
+}
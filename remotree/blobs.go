@@ -0,0 +1,121 @@
+package remotree
+
+import (
+	"bytes"
+	"net/http"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/htfs"
+	"github.com/joshyorko/rcc/pathlib"
+)
+
+const (
+	catalogNamesRefresh = 5 * time.Second
+)
+
+var (
+	blobPattern = regexp.MustCompile(`^[0-9a-f]{32,64}$`)
+)
+
+// catalogNames caches names of known catalogs (including compacted ones), so
+// that catalog requests do not rescan snapshot manifests every time. Unknown
+// names reload the cache, but at most once in catalogNamesRefresh.
+type catalogNames struct {
+	sync.Mutex
+	loaded time.Time
+	names  map[string]bool
+}
+
+func (it *catalogNames) Member(catalog string) bool {
+	it.Lock()
+	defer it.Unlock()
+	if it.names[catalog] {
+		return true
+	}
+	if time.Since(it.loaded) < catalogNamesRefresh {
+		return false
+	}
+	names := make(map[string]bool)
+	for _, name := range htfs.AllCatalogNames() {
+		names[name] = true
+	}
+	it.names, it.loaded = names, time.Now()
+	return it.names[catalog]
+}
+
+func acceptHololibRequest(response http.ResponseWriter, request *http.Request, kind, name string) bool {
+	if request.Method != http.MethodGet && request.Method != http.MethodHead {
+		response.WriteHeader(http.StatusMethodNotAllowed)
+		common.Trace("%s: rejecting request %q for %q.", kind, request.Method, name)
+		return false
+	}
+	if isSelfRequest(request) {
+		response.WriteHeader(http.StatusConflict)
+		common.Trace("%s: rejecting /SELF/ request for %q.", kind, name)
+		return false
+	}
+	return true
+}
+
+func notFound(response http.ResponseWriter) {
+	response.WriteHeader(http.StatusNotFound)
+	response.Write([]byte("404 not found, sorry"))
+}
+
+func serveHololibFile(response http.ResponseWriter, request *http.Request, kind, name, fullpath string) {
+	if !acceptHololibRequest(response, request, kind, name) {
+		return
+	}
+	if len(fullpath) == 0 || !pathlib.IsFile(fullpath) {
+		notFound(response)
+		return
+	}
+	headers := response.Header()
+	headers.Add("Content-Type", "application/octet-stream")
+	http.ServeFile(response, request, fullpath)
+}
+
+func makeBlobHandler() http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		digest := path.Base(request.URL.Path)
+		defer common.Stopwatch("Blob %q took", digest).Debug()
+		fullpath := ""
+		if blobPattern.MatchString(digest) {
+			fullpath = htfs.ExactDefaultLocation(digest)
+		}
+		serveHololibFile(response, request, "Blob", digest, fullpath)
+	}
+}
+
+func makeCatalogHandler() http.HandlerFunc {
+	known := &catalogNames{}
+	return func(response http.ResponseWriter, request *http.Request) {
+		catalog := path.Base(request.URL.Path)
+		defer common.Stopwatch("Catalog %q took", catalog).Debug()
+		if !known.Member(catalog) {
+			serveHololibFile(response, request, "Catalog", catalog, "")
+			return
+		}
+		fullpath := filepath.Join(common.HololibCatalogLocation(), catalog)
+		if pathlib.IsFile(fullpath) {
+			serveHololibFile(response, request, "Catalog", catalog, fullpath)
+			return
+		}
+		if !acceptHololibRequest(response, request, "Catalog", catalog) {
+			return
+		}
+		content, err := htfs.CompactedCatalog(catalog)
+		if err != nil {
+			common.Debug("Catalog %q could not be served, reason: %v", catalog, err)
+			notFound(response)
+			return
+		}
+		response.Header().Add("Content-Type", "application/octet-stream")
+		http.ServeContent(response, request, catalog, time.Time{}, bytes.NewReader(content))
+	}
+}
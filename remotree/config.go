@@ -0,0 +1,63 @@
+package remotree
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Config is content of rccremote configuration file. Keys have same names as
+// command line flags, and flags given on command line override values from
+// file. Access control (allow-cidr, tls-cert, tls-key, client-ca), verbosity,
+// and drain-timeout are reloaded on SIGHUP, other settings need restart.
+type Config struct {
+	Hostname     string   `yaml:"hostname,omitempty"`
+	Port         int      `yaml:"port,omitempty"`
+	Domain       string   `yaml:"domain,omitempty"`
+	Hold         string   `yaml:"hold,omitempty"`
+	Robots       string   `yaml:"robots,omitempty"`
+	Log          string   `yaml:"log,omitempty"`
+	Proxy        bool     `yaml:"proxy,omitempty"`
+	Debug        bool     `yaml:"debug,omitempty"`
+	Trace        bool     `yaml:"trace,omitempty"`
+	DrainTimeout string   `yaml:"drain-timeout,omitempty"`
+	AllowCidrs   []string `yaml:"allow-cidr,omitempty"`
+	Certificate  string   `yaml:"tls-cert,omitempty"`
+	Key          string   `yaml:"tls-key,omitempty"`
+	ClientCA     string   `yaml:"client-ca,omitempty"`
+}
+
+// Reload gives access control and drain timeout from reloaded configuration.
+type Reload func() (*Access, time.Duration, error)
+
+// LoadConfig reads rccremote configuration file. Unknown keys are errors, so
+// that typos do not silently fall back to defaults.
+func LoadConfig(filename string) (*Config, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	config := &Config{}
+	err = yaml.UnmarshalStrict(content, config)
+	if err != nil {
+		return nil, fmt.Errorf("Configuration %q is not valid, reason: %v", filename, err)
+	}
+	_, err = config.Drain()
+	if err != nil {
+		return nil, fmt.Errorf("Configuration %q has invalid drain-timeout, reason: %v", filename, err)
+	}
+	if config.Port < 0 || config.Port > 65535 {
+		return nil, fmt.Errorf("Configuration %q has invalid port %d.", filename, config.Port)
+	}
+	return config, nil
+}
+
+// Drain gives drain-timeout as duration, zero when not set.
+func (it *Config) Drain() (time.Duration, error) {
+	if len(it.DrainTimeout) == 0 {
+		return 0, nil
+	}
+	return time.ParseDuration(it.DrainTimeout)
+}
@@ -0,0 +1,70 @@
+package remotree
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	filename := filepath.Join(t.TempDir(), "rccremote.yaml")
+	if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+func TestLoadConfigIsStrict(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	config, err := LoadConfig(writeConfig(t, "hostname: 0.0.0.0\nport: 4700\ndrain-timeout: 45s\nallow-cidr:\n- 10.0.0.0/8\n- 192.168.1.7\n"))
+	must.Nil(err)
+	must.Equal("0.0.0.0", config.Hostname)
+	must.Equal(4700, config.Port)
+	must.Equal([]string{"10.0.0.0/8", "192.168.1.7"}, config.AllowCidrs)
+	drain, err := config.Drain()
+	must.Nil(err)
+	must.Equal(45*time.Second, drain)
+
+	_, err = LoadConfig(writeConfig(t, "hostnme: 0.0.0.0\n"))
+	wont.Nil(err)
+	_, err = LoadConfig(writeConfig(t, "drain-timeout: soon\n"))
+	wont.Nil(err)
+	_, err = LoadConfig(writeConfig(t, "port: 70000\n"))
+	wont.Nil(err)
+	_, err = LoadConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	wont.Nil(err)
+}
+
+func TestGatekeeperReloadsAllowlist(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	keeper, err := newGatekeeper(&Access{AllowCidrs: []string{"10.0.0.0/8"}})
+	must.Nil(err)
+	must.Nil(keeper.tlsConfig())
+	server := httptest.NewServer(keeper.wrap(okHandler()))
+	defer server.Close()
+
+	response, err := http.Get(server.URL)
+	must.Nil(err)
+	response.Body.Close()
+	must.Equal(http.StatusForbidden, response.StatusCode)
+
+	must.Nil(keeper.update(&Access{AllowCidrs: []string{"127.0.0.0/8", "::1"}}))
+	response, err = http.Get(server.URL)
+	must.Nil(err)
+	response.Body.Close()
+	must.Equal(http.StatusOK, response.StatusCode)
+
+	wont.Nil(keeper.update(&Access{AllowCidrs: []string{"bad"}}))
+	wont.Nil(keeper.update(&Access{Certificate: "server.pem", Key: "server.key"}))
+	response, err = http.Get(server.URL)
+	must.Nil(err)
+	response.Body.Close()
+	must.Equal(http.StatusOK, response.StatusCode)
+}
@@ -0,0 +1,380 @@
+package remotree
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/htfs"
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pathlib"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	domainsFile = "domains.yaml"
+)
+
+var (
+	domainScoped = []string{"/parts/", "/sizes/", "/delta/", "/force/", "/catalog/"}
+)
+
+// Domain is symbolic part of hololib served by rccremote. Catalogs are glob
+// patterns of catalog names (empty means all catalogs), robots are glob
+// patterns of served robot package names (empty means all robots), and when
+// tokens are given, client must present one of them as its authorization.
+type Domain struct {
+	Name     string   `yaml:"name"`
+	Catalogs []string `yaml:"catalogs,omitempty"`
+	Robots   []string `yaml:"robots,omitempty"`
+	Tokens   []string `yaml:"token-digests,omitempty"`
+}
+
+// Domains is content of domains.yaml in rccremote hold storage.
+type Domains struct {
+	Domains []*Domain `yaml:"domains"`
+}
+
+func DomainsFilename(storage string) string {
+	return filepath.Join(storage, domainsFile)
+}
+
+func tokenDigest(token string) string {
+	return fmt.Sprintf("%02x", sha256.Sum256([]byte(token)))
+}
+
+// LoadDomains loads domain definitions from storage. Missing file means that
+// there are no domains defined.
+func LoadDomains(storage string) (*Domains, error) {
+	result := &Domains{Domains: []*Domain{}}
+	content, err := os.ReadFile(DomainsFilename(storage))
+	if os.IsNotExist(err) {
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = yaml.Unmarshal(content, result)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid domains file %q, reason: %v", DomainsFilename(storage), err)
+	}
+	return result, nil
+}
+
+func (it *Domains) Save(storage string) error {
+	_, err := pathlib.EnsureDirectory(storage)
+	if err != nil {
+		return err
+	}
+	content, err := yaml.Marshal(it)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(DomainsFilename(storage), content, 0o600)
+}
+
+func (it *Domains) Find(name string) (*Domain, bool) {
+	for _, domain := range it.Domains {
+		if domain.Name == name {
+			return domain, true
+		}
+	}
+	return nil, false
+}
+
+// Add adds (or extends existing) domain with catalog patterns and tokens.
+// Only digests of tokens are stored.
+func (it *Domains) Add(name string, catalogs, tokens []string) error {
+	if len(strings.TrimSpace(name)) == 0 {
+		return fmt.Errorf("Domain name cannot be empty.")
+	}
+	for _, pattern := range catalogs {
+		_, err := path.Match(pattern, "")
+		if err != nil {
+			return fmt.Errorf("Invalid catalog pattern %q, reason: %v", pattern, err)
+		}
+	}
+	domain, ok := it.Find(name)
+	if !ok {
+		domain = &Domain{Name: name}
+		it.Domains = append(it.Domains, domain)
+		sort.Slice(it.Domains, func(left, right int) bool {
+			return it.Domains[left].Name < it.Domains[right].Name
+		})
+	}
+	domain.Catalogs = append(domain.Catalogs, catalogs...)
+	for _, token := range tokens {
+		domain.Tokens = append(domain.Tokens, tokenDigest(token))
+	}
+	return nil
+}
+
+// AddRobots adds robot name patterns into existing domain.
+func (it *Domains) AddRobots(name string, robots []string) error {
+	domain, ok := it.Find(name)
+	if !ok {
+		return fmt.Errorf("Unknown domain %q.", name)
+	}
+	for _, pattern := range robots {
+		_, err := path.Match(pattern, "")
+		if err != nil {
+			return fmt.Errorf("Invalid robot pattern %q, reason: %v", pattern, err)
+		}
+	}
+	domain.Robots = append(domain.Robots, robots...)
+	return nil
+}
+
+func matchesAny(patterns []string, name string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		matched, _ := path.Match(pattern, name)
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (it *Domain) Serves(catalog string) bool {
+	return matchesAny(it.Catalogs, catalog)
+}
+
+func (it *Domain) ServesRobot(robot string) bool {
+	return matchesAny(it.Robots, robot)
+}
+
+func (it *Domain) Authorized(authorization string) bool {
+	if len(it.Tokens) == 0 {
+		return true
+	}
+	candidates := []string{authorization}
+	token, ok := strings.CutPrefix(authorization, "Bearer ")
+	if ok {
+		candidates = append(candidates, token)
+	}
+	for _, candidate := range candidates {
+		digest := []byte(tokenDigest(candidate))
+		for _, known := range it.Tokens {
+			if subtle.ConstantTimeCompare(digest, []byte(known)) == 1 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// catalogBlobs caches blob digests of catalogs, so that blob requests can be
+// limited to blobs of catalogs visible in requested domain. New catalogs are
+// loaded when digest is not found, but at most once in catalogNamesRefresh.
+type catalogBlobs struct {
+	sync.Mutex
+	loaded  time.Time
+	digests map[string]map[string]bool
+	names   func() []string
+	parts   func(string) (string, bool)
+}
+
+func newCatalogBlobs() *catalogBlobs {
+	return &catalogBlobs{
+		digests: make(map[string]map[string]bool),
+		names:   htfs.AllCatalogNames,
+		parts:   loadCatalogParts,
+	}
+}
+
+func (it *catalogBlobs) contains(serves func(string) bool, digest string) bool {
+	for catalog, digests := range it.digests {
+		if digests[digest] && serves(catalog) {
+			return true
+		}
+	}
+	return false
+}
+
+func (it *catalogBlobs) refresh() {
+	current := make(map[string]map[string]bool)
+	for _, catalog := range it.names() {
+		known, ok := it.digests[catalog]
+		if !ok {
+			parts, found := it.parts(catalog)
+			if !found {
+				continue
+			}
+			known = make(map[string]bool)
+			for _, digest := range strings.Split(parts, "\n") {
+				known[digest] = true
+			}
+		}
+		current[catalog] = known
+	}
+	it.digests, it.loaded = current, time.Now()
+}
+
+// Served tells if blob belongs to any catalog that serves function accepts.
+func (it *catalogBlobs) Served(serves func(string) bool, digest string) bool {
+	it.Lock()
+	defer it.Unlock()
+
+	if it.contains(serves, digest) {
+		return true
+	}
+	if time.Since(it.loaded) < catalogNamesRefresh {
+		return false
+	}
+	it.refresh()
+	return it.contains(serves, digest)
+}
+
+// domainGuard maps requests to domains. Domains file is reloaded when it
+// changes, so that domains can be managed without restarting server.
+type domainGuard struct {
+	sync.Mutex
+	storage  string
+	fallback string
+	modified time.Time
+	size     int64
+	domains  *Domains
+	blobs    *catalogBlobs
+}
+
+func newDomainGuard(storage, fallback string) *domainGuard {
+	return &domainGuard{
+		storage:  storage,
+		fallback: fallback,
+		domains:  &Domains{},
+		blobs:    newCatalogBlobs(),
+	}
+}
+
+func (it *domainGuard) current() *Domains {
+	it.Lock()
+	defer it.Unlock()
+
+	stat, err := os.Stat(DomainsFilename(it.storage))
+	if err != nil {
+		it.modified, it.size, it.domains = time.Time{}, 0, &Domains{}
+		return it.domains
+	}
+	if stat.ModTime().Equal(it.modified) && stat.Size() == it.size {
+		return it.domains
+	}
+	domains, err := LoadDomains(it.storage)
+	if err != nil {
+		common.Log("Keeping previous domains, reason: %v", err)
+		return it.domains
+	}
+	common.Log("Loaded %d domain(s) from %q.", len(domains.Domains), DomainsFilename(it.storage))
+	it.modified, it.size, it.domains = stat.ModTime(), stat.Size(), domains
+	return it.domains
+}
+
+func requestedDomain(request *http.Request) string {
+	name := request.URL.Query().Get("domain")
+	if len(name) == 0 {
+		name = request.Header.Get(operations.X_RCC_DOMAIN)
+	}
+	return name
+}
+
+func scopedCatalog(request *http.Request) (string, bool) {
+	for _, prefix := range domainScoped {
+		if strings.HasPrefix(request.URL.Path, prefix) {
+			return path.Base(request.URL.Path), true
+		}
+	}
+	return "", false
+}
+
+// serves tells which catalogs are visible to (already authorized) request.
+func (it *domainGuard) serves(request *http.Request) func(string) bool {
+	domains := it.current()
+	if len(domains.Domains) == 0 {
+		return func(string) bool { return true }
+	}
+	name := requestedDomain(request)
+	if len(name) == 0 {
+		name = it.fallback
+	}
+	domain, ok := domains.Find(name)
+	if !ok {
+		return func(string) bool { return false }
+	}
+	return domain.Serves
+}
+
+// servesRobot tells which robot packages are visible to (already authorized)
+// request.
+func (it *domainGuard) servesRobot(request *http.Request) func(string) bool {
+	domains := it.current()
+	if len(domains.Domains) == 0 {
+		return func(string) bool { return true }
+	}
+	name := requestedDomain(request)
+	if len(name) == 0 {
+		name = it.fallback
+	}
+	domain, ok := domains.Find(name)
+	if !ok {
+		return func(string) bool { return false }
+	}
+	return domain.ServesRobot
+}
+
+func (it *domainGuard) reject(response http.ResponseWriter, request *http.Request, status int, reason string) {
+	common.Log("Domain denied: %s %q from %q [%s]", request.Method, request.URL.Path, request.RemoteAddr, reason)
+	common.RunJournal("rccremote", "domain", "%s %q from %q: %s", request.Method, request.URL.Path, request.RemoteAddr, reason)
+	http.Error(response, fmt.Sprintf("%d %s: %s", status, http.StatusText(status), reason), status)
+}
+
+// wrap limits hololib requests to catalogs of requested domain, and blob
+// requests to blobs of those catalogs. Without any domains defined,
+// everything is served as before.
+func (it *domainGuard) wrap(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		catalog, scoped := scopedCatalog(request)
+		hololib := scoped || strings.HasPrefix(request.URL.Path, "/blob/") || strings.HasPrefix(request.URL.Path, "/watch/") || strings.HasPrefix(request.URL.Path, "/registry/") || strings.HasPrefix(request.URL.Path, robotsPrefix)
+		domains := it.current()
+		if !hololib || len(domains.Domains) == 0 {
+			handler.ServeHTTP(response, request)
+			return
+		}
+		name := requestedDomain(request)
+		if len(name) == 0 {
+			name = it.fallback
+		}
+		domain, ok := domains.Find(name)
+		if !ok {
+			it.reject(response, request, http.StatusNotFound, fmt.Sprintf("unknown domain %q", name))
+			return
+		}
+		if !domain.Authorized(request.Header.Get(operations.AUTHORIZATION)) {
+			it.reject(response, request, http.StatusForbidden, fmt.Sprintf("not authorized for domain %q", name))
+			return
+		}
+		if scoped && !domain.Serves(catalog) {
+			it.reject(response, request, http.StatusNotFound, fmt.Sprintf("catalog %q is not in domain %q", catalog, name))
+			return
+		}
+		if strings.HasPrefix(request.URL.Path, "/blob/") && len(domain.Catalogs) > 0 {
+			digest := path.Base(request.URL.Path)
+			if !it.blobs.Served(domain.Serves, digest) {
+				it.reject(response, request, http.StatusNotFound, fmt.Sprintf("blob %q is not in catalogs of domain %q", digest, name))
+				return
+			}
+		}
+		handler.ServeHTTP(response, request)
+	})
+}
@@ -0,0 +1,96 @@
+package remotree
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+	"github.com/joshyorko/rcc/operations"
+)
+
+func TestDomainsAreStoredWithTokenDigestsOnly(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	storage := t.TempDir()
+	domains, err := LoadDomains(storage)
+	must.Nil(err)
+	must.Equal(0, len(domains.Domains))
+
+	wont.Nil(domains.Add("", nil, nil))
+	wont.Nil(domains.Add("broken", []string{"[a-"}, nil))
+	must.Nil(domains.Add("team-b", []string{"bbbb*"}, []string{"secret-b"}))
+	must.Nil(domains.Add("team-a", []string{"aaaa*"}, nil))
+	must.Nil(domains.Add("team-b", []string{"cccc*"}, nil))
+	must.Nil(domains.Save(storage))
+
+	loaded, err := LoadDomains(storage)
+	must.Nil(err)
+	must.Equal(2, len(loaded.Domains))
+	must.Equal("team-a", loaded.Domains[0].Name)
+	team, ok := loaded.Find("team-b")
+	must.True(ok)
+	must.Equal([]string{"bbbb*", "cccc*"}, team.Catalogs)
+	must.Equal(1, len(team.Tokens))
+	wont.Equal("secret-b", team.Tokens[0])
+
+	must.True(team.Serves("cccc1234v12.linux_amd64"))
+	wont.True(team.Serves("aaaa1234v12.linux_amd64"))
+	must.True(team.Authorized("secret-b"))
+	must.True(team.Authorized("Bearer secret-b"))
+	wont.True(team.Authorized("secret-a"))
+	wont.True(team.Authorized(""))
+	must.True((&Domain{Name: "open"}).Serves("anything"))
+	must.True((&Domain{Name: "open"}).Authorized(""))
+}
+
+func TestDomainGuardLimitsCatalogsPerDomain(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	storage := t.TempDir()
+	guard := newDomainGuard(storage, "team-a")
+	server := httptest.NewServer(guard.wrap(okHandler()))
+	defer server.Close()
+
+	status := func(url, domain, token string) int {
+		request, err := http.NewRequest(http.MethodGet, server.URL+url, nil)
+		must.Nil(err)
+		if len(domain) > 0 {
+			request.Header.Set(operations.X_RCC_DOMAIN, domain)
+		}
+		if len(token) > 0 {
+			request.Header.Set(operations.AUTHORIZATION, token)
+		}
+		response, err := http.DefaultClient.Do(request)
+		must.Nil(err)
+		response.Body.Close()
+		return response.StatusCode
+	}
+
+	must.Equal(http.StatusOK, status("/parts/bbbb1234", "", ""))
+
+	domains, err := LoadDomains(storage)
+	must.Nil(err)
+	must.Nil(domains.Add("team-a", []string{"aaaa*"}, nil))
+	must.Nil(domains.Add("team-b", []string{"bbbb*"}, []string{"secret-b"}))
+	must.Nil(domains.Save(storage))
+
+	must.Equal(http.StatusOK, status("/parts/aaaa1234", "", ""))
+	must.Equal(http.StatusNotFound, status("/parts/bbbb1234", "", ""))
+	must.Equal(http.StatusForbidden, status("/parts/bbbb1234", "team-b", ""))
+	must.Equal(http.StatusOK, status("/parts/bbbb1234", "team-b", "secret-b"))
+	must.Equal(http.StatusOK, status("/catalog/bbbb1234?domain=team-b", "", "secret-b"))
+	must.Equal(http.StatusNotFound, status("/delta/aaaa1234", "team-b", "secret-b"))
+	must.Equal(http.StatusForbidden, status("/blob/0123456789abcdef0123456789abcdef", "team-b", "wrong"))
+
+	guard.blobs.names = func() []string { return []string{"aaaa1234", "bbbb1234"} }
+	guard.blobs.parts = func(catalog string) (string, bool) {
+		return map[string]string{"aaaa1234": "aaaa0000aaaa0000aaaa0000aaaa0000\naaaa1111aaaa1111aaaa1111aaaa1111", "bbbb1234": "bbbb0000bbbb0000bbbb0000bbbb0000"}[catalog], true
+	}
+	must.Equal(http.StatusOK, status("/blob/aaaa1111aaaa1111aaaa1111aaaa1111", "team-a", ""))
+	must.Equal(http.StatusNotFound, status("/blob/bbbb0000bbbb0000bbbb0000bbbb0000", "team-a", ""))
+	must.Equal(http.StatusOK, status("/blob/bbbb0000bbbb0000bbbb0000bbbb0000", "team-b", "secret-b"))
+	must.Equal(http.StatusNotFound, status("/blob/aaaa0000aaaa0000aaaa0000aaaa0000", "team-b", "secret-b"))
+	must.Equal(http.StatusNotFound, status("/parts/aaaa1234", "team-c", ""))
+	must.Equal(http.StatusOK, status("/healthz", "team-c", ""))
+}
@@ -2,6 +2,7 @@ package remotree
 
 import (
 	"bufio"
+	"fmt"
 	"net/http"
 	"path/filepath"
 	"strings"
@@ -9,6 +10,7 @@ import (
 	"github.com/joshyorko/rcc/common"
 	"github.com/joshyorko/rcc/fail"
 	"github.com/joshyorko/rcc/htfs"
+	"github.com/joshyorko/rcc/pathlib"
 	"github.com/joshyorko/rcc/set"
 )
 
@@ -52,20 +54,55 @@ func makeQueryHandler(queries Partqueries, triggers chan string) http.HandlerFun
 	}
 }
 
+// makeSizesHandler answers with "digest size" line for each blob of catalog,
+// so that clients can estimate download size of catalogs before pulling.
+func makeSizesHandler(queries Partqueries) http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		catalog := filepath.Base(request.URL.Path)
+		defer common.Stopwatch("Sizes of catalog %q took", catalog).Debug()
+		if request.Method != http.MethodGet {
+			response.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		reply := make(chan string)
+		queries <- &Partquery{
+			Catalog: catalog,
+			Reply:   reply,
+		}
+		content, ok := <-reply
+		if !ok {
+			response.WriteHeader(http.StatusNotFound)
+			response.Write([]byte("404 not found, sorry"))
+			return
+		}
+		response.Header().Add("Content-Type", "text/plain")
+		response.WriteHeader(http.StatusOK)
+		writer := bufio.NewWriter(response)
+		defer writer.Flush()
+		for _, digest := range strings.Split(content, "\n") {
+			if len(digest) == 0 {
+				continue
+			}
+			size, _ := pathlib.Size(htfs.ExactDefaultLocation(digest))
+			fmt.Fprintf(writer, "%s %d\n", digest, size)
+		}
+	}
+}
+
 func loadSingleCatalog(catalog string) (root *htfs.Root, err error) {
 	defer fail.Around(&err)
 	tempdir := filepath.Join(common.ProductTemp(), "rccremote")
 	shadow, err := htfs.NewRoot(tempdir)
 	fail.On(err != nil, "Could not create root, reason: %v", err)
 	filename := filepath.Join(common.HololibCatalogLocation(), catalog)
-	err = shadow.LoadFrom(filename)
+	err = shadow.LoadCatalog(filename)
 	fail.On(err != nil, "Could not load root, reason: %v", err)
 	common.Trace("Catalog %q loaded.", catalog)
 	return shadow, nil
 }
 
 func loadCatalogParts(catalog string) (string, bool) {
-	catalogs := htfs.CatalogNames()
+	catalogs := htfs.AllCatalogNames()
 	if !set.Member(catalogs, catalog) {
 		return "", false
 	}
@@ -0,0 +1,65 @@
+package remotree
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pathlib"
+)
+
+var (
+	colorPattern = regexp.MustCompile("\x1b\\[[0-9;]*m")
+)
+
+// LogInto appends all log lines of server (without colors, but with
+// timestamps) into given file, in addition to normal output, so that they
+// can be followed from other processes (like "rcc interactive remotelog").
+// File is rotated when it grows over RCC_LOG_MAX_SIZE. Returned function
+// stops logging into file.
+func LogInto(filename string) (func(), error) {
+	_, err := pathlib.EnsureParentDirectory(filename)
+	if err != nil {
+		return nil, err
+	}
+	sink, err := openLog(filename)
+	if err != nil {
+		return nil, err
+	}
+	limit := common.RccLogMaxSize()
+	lock := sync.Mutex{}
+	observer := common.RegisterLogObserver(func(message string) {
+		lock.Lock()
+		defer lock.Unlock()
+		if sink == nil {
+			return
+		}
+		fmt.Fprintf(sink, "%s %s\n", time.Now().Format(time.DateTime), colorPattern.ReplaceAllString(message, ""))
+		if limit < 1 {
+			return
+		}
+		stat, err := sink.Stat()
+		if err != nil || stat.Size() < limit {
+			return
+		}
+		sink.Close()
+		common.RotateLogAt(filename, limit, common.RccLogMaxFiles())
+		sink, _ = openLog(filename)
+	})
+	return func() {
+		common.UnregisterLogObserver(observer)
+		lock.Lock()
+		defer lock.Unlock()
+		if sink != nil {
+			sink.Close()
+			sink = nil
+		}
+	}, nil
+}
+
+func openLog(filename string) (*os.File, error) {
+	return os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+}
@@ -0,0 +1,55 @@
+package remotree
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestServerLogIsWrittenIntoFileWithoutColors(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	filename := filepath.Join(t.TempDir(), "logs", "rccremote.log")
+	stop, err := LogInto(filename)
+	must.Nil(err)
+	common.Log("\x1b[31mAccess denied\x1b[0m for test")
+	common.WaitLogs()
+	stop()
+
+	content, err := os.ReadFile(filename)
+	must.Nil(err)
+	must.True(strings.Contains(string(content), "Access denied for test"))
+	wont.True(strings.Contains(string(content), "\x1b["))
+}
+
+func TestServerLogIsRotatedWhenItGrowsTooBig(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	t.Setenv(common.RCC_LOG_MAX_SIZE, "1k")
+	t.Setenv(common.RCC_LOG_MAX_FILES, "1")
+	filename := filepath.Join(t.TempDir(), "rccremote.log")
+	stop, err := LogInto(filename)
+	must.Nil(err)
+	for range 60 {
+		common.Log("Serving catalog %s to client", strings.Repeat("x", 40))
+	}
+	common.WaitLogs()
+	common.Log("Last line after rotation")
+	common.WaitLogs()
+	stop()
+
+	stat, err := os.Stat(filename)
+	must.Nil(err)
+	must.True(stat.Size() < 1024)
+	content, err := os.ReadFile(filename)
+	must.Nil(err)
+	must.True(strings.Contains(string(content), "Last line after rotation"))
+	_, err = os.Stat(common.RotatedLog(filename, 1))
+	must.Nil(err)
+	_, err = os.Stat(common.RotatedLog(filename, 2))
+	wont.Nil(err)
+}
@@ -0,0 +1,337 @@
+package remotree
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/fail"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/settings"
+)
+
+const (
+	indexFreshness = 10 * time.Minute
+)
+
+var (
+	anchorPattern   = regexp.MustCompile(`(?is)<a\s[^>]*href="[^"]+"[^>]*>.*?</a>`)
+	hrefPattern     = regexp.MustCompile(`href="([^"]+)"`)
+	sha256Fragment  = regexp.MustCompile(`^sha256=([0-9a-f]{64})$`)
+	sha256Pattern   = regexp.MustCompile(`^[0-9a-f]{64}$`)
+	condaPackageExt = []string{".conda", ".tar.bz2"}
+)
+
+type transformer func(link string, content []byte) []byte
+
+type packageProxy struct {
+	sync.Mutex
+	name     string
+	upstream string
+	cache    string
+	allowed  map[string]bool
+	client   *http.Client
+	rewrite  transformer
+	repodata map[string]*repodataDigests
+}
+
+// repodataDigests are package digests parsed from one cached repodata.json,
+// valid as long as that file is not modified.
+type repodataDigests struct {
+	modified time.Time
+	digests  map[string]string
+}
+
+func newPackageProxy(name, upstream, cache string, hostnames []string) *packageProxy {
+	allowed := make(map[string]bool)
+	for _, host := range hostnames {
+		allowed[host] = true
+	}
+	parsed, err := url.Parse(upstream)
+	if err == nil {
+		allowed[parsed.Hostname()] = true
+	}
+	return &packageProxy{
+		name:     name,
+		upstream: upstream,
+		cache:    filepath.Join(cache, name),
+		allowed:  allowed,
+		client:   &http.Client{Transport: settings.Global.ConfiguredHttpTransport()},
+		rewrite:  simpleIndexRewriter(upstream),
+		repodata: make(map[string]*repodataDigests),
+	}
+}
+
+func safeRelative(relative string) (string, bool) {
+	cleaned := path.Clean("/" + relative)
+	if cleaned == "/" || strings.Contains(relative, "..") {
+		return "", false
+	}
+	return strings.TrimPrefix(cleaned, "/"), true
+}
+
+func isFresh(fullpath string, volatile bool) bool {
+	stat, err := os.Stat(fullpath)
+	if err != nil || stat.IsDir() {
+		return false
+	}
+	return !volatile || time.Since(stat.ModTime()) < indexFreshness
+}
+
+func (it *packageProxy) download(link, target, expected string, transform transformer) (err error) {
+	defer fail.Around(&err)
+
+	request, err := http.NewRequest("GET", link, nil)
+	fail.On(err != nil, "Failed create request to %q, reason: %v", link, err)
+	request.Header.Add("User-Agent", common.UserAgent())
+	if transform != nil {
+		request.Header.Add("Accept", "text/html")
+	}
+	response, err := it.client.Do(request)
+	fail.On(err != nil, "Web request to %q failed, reason: %v", link, err)
+	defer response.Body.Close()
+	fail.On(response.StatusCode != http.StatusOK, "Upstream %q responded %q.", link, response.Status)
+
+	partname := fmt.Sprintf("%s.part%s", target, <-common.Identities)
+	defer os.Remove(partname)
+	sink, err := pathlib.Create(partname)
+	fail.On(err != nil, "Failed to create %q, reason: %v", partname, err)
+	digest := sha256.New()
+	if transform != nil {
+		content, err := io.ReadAll(io.TeeReader(response.Body, digest))
+		if err == nil {
+			_, err = sink.Write(transform(link, content))
+		}
+		sink.Close()
+		fail.On(err != nil, "Failed to write %q, reason: %v", partname, err)
+	} else {
+		_, err = io.Copy(io.MultiWriter(sink, digest), response.Body)
+		sink.Close()
+		fail.On(err != nil, "Failed to write %q, reason: %v", partname, err)
+	}
+	actual := fmt.Sprintf("%02x", digest.Sum(nil))
+	fail.On(len(expected) > 0 && actual != expected, "Integrity check of %q failed, expected sha256 %s, actual %s.", link, expected, actual)
+
+	return pathlib.TryRename(it.name, partname, target)
+}
+
+func (it *packageProxy) serve(response http.ResponseWriter, request *http.Request, link, relative string, volatile bool, expected string, transform transformer) {
+	fullpath := filepath.Join(it.cache, filepath.FromSlash(relative))
+	if !isFresh(fullpath, volatile) {
+		err := it.download(link, fullpath, expected, transform)
+		if err != nil && !pathlib.IsFile(fullpath) {
+			common.Log("%s proxy: %v", it.name, err)
+			response.WriteHeader(http.StatusBadGateway)
+			response.Write([]byte("502 upstream failure, sorry"))
+			return
+		}
+		if err != nil {
+			common.Log("%s proxy: serving stale %q, reason: %v", it.name, relative, err)
+		}
+	}
+	common.Trace("%s proxy: serving %q from cache.", it.name, relative)
+	http.ServeFile(response, request, fullpath)
+}
+
+// simpleIndexRewriter makes transformer, which keeps all links of simple
+// index pages going through proxy. Links to other index pages (under upstream
+// simple root) are rewritten to proxied index pages, and links with sha256
+// fragment to proxied (and verified) files. Other links cannot be verified,
+// so their anchors are dropped from index page.
+func simpleIndexRewriter(upstream string) transformer {
+	root := strings.TrimSuffix(upstream, "/") + "/"
+	return func(link string, content []byte) []byte {
+		base, err := url.Parse(link)
+		if err != nil {
+			return content
+		}
+		return anchorPattern.ReplaceAllFunc(content, func(anchor []byte) []byte {
+			match := hrefPattern.Find(anchor)
+			href := string(hrefPattern.FindSubmatch(anchor)[1])
+			reference, err := url.Parse(strings.ReplaceAll(href, "&amp;", "&"))
+			if err != nil {
+				return nil
+			}
+			target := base.ResolveReference(reference)
+			found := sha256Fragment.FindStringSubmatch(target.Fragment)
+			replacement := ""
+			switch {
+			case found != nil:
+				digest := found[1]
+				replacement = fmt.Sprintf(`href="/pypi/files/%s/%s/%s%s#sha256=%s"`, digest, target.Scheme, target.Host, target.EscapedPath(), digest)
+			case len(target.Fragment) == 0 && strings.HasPrefix(target.String(), root):
+				replacement = fmt.Sprintf(`href="/pypi/simple/%s"`, strings.TrimPrefix(target.String(), root))
+			default:
+				common.Trace("PyPI proxy: dropping unverifiable link %q from %q.", target, link)
+				return nil
+			}
+			return bytes.Replace(anchor, match, []byte(replacement), 1)
+		})
+	}
+}
+
+func makePypiHandler(proxy *packageProxy) http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		defer common.Stopwatch("PyPI proxy of %q took", request.URL.Path).Debug()
+		if request.Method != http.MethodGet && request.Method != http.MethodHead {
+			response.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		relative, ok := safeRelative(strings.TrimPrefix(request.URL.Path, "/pypi/"))
+		if !ok {
+			response.WriteHeader(http.StatusNotFound)
+			return
+		}
+		parts := strings.SplitN(relative, "/", 5)
+		switch {
+		case parts[0] == "simple":
+			page := strings.TrimPrefix(relative, "simple")
+			page = strings.TrimPrefix(page, "/")
+			if len(page) > 0 {
+				page += "/"
+			}
+			link := strings.TrimSuffix(proxy.upstream, "/") + "/" + page
+			proxy.serve(response, request, link, path.Join(relative, "index.html"), true, "", proxy.rewrite)
+		case parts[0] == "files" && len(parts) == 5 && sha256Pattern.MatchString(parts[1]):
+			digest, scheme := parts[1], parts[2]
+			link := fmt.Sprintf("%s://%s/%s", scheme, parts[3], parts[4])
+			target, err := url.Parse(link)
+			if err != nil || (scheme != "https" && scheme != "http") || !proxy.allowed[target.Hostname()] {
+				common.Trace("PyPI proxy: rejecting %q.", link)
+				response.WriteHeader(http.StatusForbidden)
+				return
+			}
+			cached := path.Join("files", digest[:2], digest, path.Base(parts[4]))
+			proxy.serve(response, request, link, cached, false, digest, nil)
+		default:
+			response.WriteHeader(http.StatusNotFound)
+		}
+	}
+}
+
+func isCondaPackage(name string) bool {
+	for _, suffix := range condaPackageExt {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func condaPackageDigests(repodata string) map[string]string {
+	result := make(map[string]string)
+	blob, err := os.ReadFile(repodata)
+	if err != nil {
+		return result
+	}
+	var index struct {
+		Packages      map[string]struct{ Sha256 string } `json:"packages"`
+		CondaPackages map[string]struct{ Sha256 string } `json:"packages.conda"`
+	}
+	if json.Unmarshal(blob, &index) != nil {
+		return result
+	}
+	for name, found := range index.Packages {
+		result[name] = found.Sha256
+	}
+	for name, found := range index.CondaPackages {
+		result[name] = found.Sha256
+	}
+	return result
+}
+
+// condaPackageDigest gives digest of package from cached repodata.json, which
+// is parsed only once per channel subdirectory (and again after it changes).
+func (it *packageProxy) condaPackageDigest(repodata, name string) string {
+	stat, err := os.Stat(repodata)
+	if err != nil {
+		return ""
+	}
+	it.Lock()
+	cached, ok := it.repodata[repodata]
+	it.Unlock()
+	if !ok || !cached.modified.Equal(stat.ModTime()) {
+		cached = &repodataDigests{modified: stat.ModTime(), digests: condaPackageDigests(repodata)}
+		it.Lock()
+		it.repodata[repodata] = cached
+		it.Unlock()
+	}
+	return cached.digests[name]
+}
+
+// condaDigest finds expected sha256 of conda package from channel repodata.
+// When cached repodata is missing or does not know the package, fresh one is
+// fetched from upstream, and without known digest package is not cached at all.
+func (it *packageProxy) condaDigest(relative, name string) (string, error) {
+	repodata := path.Join(path.Dir(relative), "repodata.json")
+	fullpath := filepath.Join(it.cache, filepath.FromSlash(repodata))
+	digest := it.condaPackageDigest(fullpath, name)
+	if len(digest) > 0 {
+		return digest, nil
+	}
+	link := strings.TrimSuffix(it.upstream, "/") + "/" + repodata
+	err := it.download(link, fullpath, "", nil)
+	if err != nil {
+		return "", fmt.Errorf("Could not fetch %q for %q, reason: %v", repodata, relative, err)
+	}
+	digest = it.condaPackageDigest(fullpath, name)
+	if len(digest) == 0 {
+		return "", fmt.Errorf("No sha256 for %q in %q, refusing to cache it.", relative, repodata)
+	}
+	return digest, nil
+}
+
+func makeCondaHandler(proxy *packageProxy) http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		defer common.Stopwatch("Conda proxy of %q took", request.URL.Path).Debug()
+		if request.Method != http.MethodGet && request.Method != http.MethodHead {
+			response.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		relative, ok := safeRelative(strings.TrimPrefix(request.URL.Path, "/conda/"))
+		if !ok {
+			response.WriteHeader(http.StatusNotFound)
+			return
+		}
+		link := strings.TrimSuffix(proxy.upstream, "/") + "/" + relative
+		name := path.Base(relative)
+		if !isCondaPackage(name) {
+			proxy.serve(response, request, link, relative, true, "", nil)
+			return
+		}
+		expected := ""
+		if !isFresh(filepath.Join(proxy.cache, filepath.FromSlash(relative)), false) {
+			digest, err := proxy.condaDigest(relative, name)
+			if err != nil {
+				common.Log("%s proxy: %v", proxy.name, err)
+				response.WriteHeader(http.StatusBadGateway)
+				response.Write([]byte("502 upstream failure, sorry"))
+				return
+			}
+			expected = digest
+		}
+		proxy.serve(response, request, link, relative, false, expected, nil)
+	}
+}
+
+func registerProxies(mux *http.ServeMux, storage string) {
+	cache := filepath.Join(storage, "proxy")
+	hostnames := settings.Global.Hostnames()
+	pypi := newPackageProxy("pypi", settings.Global.PypiLink(""), cache, hostnames)
+	conda := newPackageProxy("conda", settings.Global.CondaLink(""), cache, hostnames)
+	mux.HandleFunc("/pypi/", makePypiHandler(pypi))
+	mux.HandleFunc("/conda/", makeCondaHandler(conda))
+	common.Log("Proxying PyPI from %q and conda from %q, cache at %q.", pypi.upstream, conda.upstream, cache)
+}
@@ -0,0 +1,121 @@
+package remotree
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestCanRewriteSimpleIndexLinks(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	digest := fmt.Sprintf("%02x", sha256.Sum256([]byte("wheel")))
+	page := fmt.Sprintf(`<a href="../../packages/ab/cd/demo-1.0.whl#sha256=%s" data-requires-python="&gt;=3.8">demo</a>
+<a href="other/">other</a>
+<a href="https://files.example.com/demo-0.9.whl">unverifiable</a>`, digest)
+	rewrite := simpleIndexRewriter("https://mirror.example.com/simple/")
+	rewritten := string(rewrite("https://mirror.example.com/simple/demo/", []byte(page)))
+	must.Equal(fmt.Sprintf(`<a href="/pypi/files/%s/https/mirror.example.com/packages/ab/cd/demo-1.0.whl#sha256=%s" data-requires-python="&gt;=3.8">demo</a>
+<a href="/pypi/simple/demo/other/">other</a>
+`, digest, digest), rewritten)
+}
+
+func TestProxyRejectsUnsafePaths(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	_, ok := safeRelative("simple/../../etc/passwd")
+	wont.True(ok)
+	_, ok = safeRelative("")
+	wont.True(ok)
+	relative, ok := safeRelative("simple/demo/")
+	must.True(ok)
+	must.Equal("simple/demo", relative)
+}
+
+func TestProxyVerifiesAndCachesPackages(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	content := []byte("package content")
+	digest := fmt.Sprintf("%02x", sha256.Sum256(content))
+	hits := 0
+	upstream := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		hits++
+		response.Write(content)
+	}))
+	defer upstream.Close()
+	location, err := url.Parse(upstream.URL)
+	must.Nil(err)
+
+	proxy := newPackageProxy("pypi", upstream.URL+"/simple/", t.TempDir(), nil)
+	server := httptest.NewServer(makePypiHandler(proxy))
+	defer server.Close()
+
+	fetch := func(digest string) (int, string) {
+		response, err := http.Get(fmt.Sprintf("%s/pypi/files/%s/http/%s/demo.whl", server.URL, digest, location.Host))
+		must.Nil(err)
+		defer response.Body.Close()
+		body, _ := io.ReadAll(response.Body)
+		return response.StatusCode, string(body)
+	}
+
+	status, body := fetch(digest)
+	must.Equal(http.StatusOK, status)
+	must.Equal(string(content), body)
+	status, _ = fetch(digest)
+	must.Equal(http.StatusOK, status)
+	must.Equal(1, hits)
+
+	status, _ = fetch(fmt.Sprintf("%02x", sha256.Sum256([]byte("other"))))
+	must.Equal(http.StatusBadGateway, status)
+}
+
+func TestCondaProxyFetchesRepodataBeforeCachingPackages(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	content := []byte("conda package")
+	digest := fmt.Sprintf("%02x", sha256.Sum256(content))
+	repodata := fmt.Sprintf(`{"packages.conda": {"demo-1.0-0.conda": {"sha256": "%s"}}}`, digest)
+	hits := make(map[string]int)
+	upstream := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		hits[request.URL.Path]++
+		switch request.URL.Path {
+		case "/noarch/repodata.json":
+			response.Write([]byte(repodata))
+		case "/noarch/demo-1.0-0.conda", "/noarch/other-1.0-0.conda":
+			response.Write(content)
+		default:
+			response.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer upstream.Close()
+
+	cache := t.TempDir()
+	proxy := newPackageProxy("conda", upstream.URL, cache, nil)
+	server := httptest.NewServer(makeCondaHandler(proxy))
+	defer server.Close()
+
+	fetch := func(name string) int {
+		response, err := http.Get(fmt.Sprintf("%s/conda/noarch/%s", server.URL, name))
+		must.Nil(err)
+		defer response.Body.Close()
+		io.ReadAll(response.Body)
+		return response.StatusCode
+	}
+
+	must.Equal(http.StatusOK, fetch("demo-1.0-0.conda"))
+	must.Equal(1, hits["/noarch/repodata.json"])
+	must.Equal(http.StatusOK, fetch("demo-1.0-0.conda"))
+	must.Equal(1, hits["/noarch/demo-1.0-0.conda"])
+
+	must.Equal(1, len(proxy.repodata))
+
+	must.Equal(http.StatusBadGateway, fetch("other-1.0-0.conda"))
+	must.Equal(2, hits["/noarch/repodata.json"])
+	must.Equal(0, hits["/noarch/other-1.0-0.conda"])
+}
@@ -0,0 +1,46 @@
+package remotree
+
+import (
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/htfs"
+)
+
+var (
+	blueprintPattern = regexp.MustCompile(`^[0-9a-f]{8,64}$`)
+)
+
+// makeRegistryHandler answers registry queries: which catalogs (of any
+// platform) this server has for given blueprint hash, limited to client
+// domain. Reply has one catalog name per line.
+func makeRegistryHandler(domains *domainGuard) http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		blueprint := path.Base(request.URL.Path)
+		defer common.Stopwatch("Registry query of blueprint %q took", blueprint).Debug()
+		if request.Method != http.MethodGet {
+			response.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if !blueprintPattern.MatchString(blueprint) {
+			http.Error(response, "400 bad request: blueprint hash expected", http.StatusBadRequest)
+			return
+		}
+		serves := domains.serves(request)
+		prefix := htfs.CatalogPrefix(blueprint)
+		found := []string{}
+		for _, catalog := range htfs.AllCatalogNames() {
+			if strings.HasPrefix(catalog, prefix) && serves(catalog) {
+				found = append(found, catalog)
+			}
+		}
+		response.Header().Set("Content-Type", "text/plain")
+		response.WriteHeader(http.StatusOK)
+		for _, catalog := range found {
+			response.Write([]byte(catalog + "\n"))
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package remotree
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/hamlet"
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pathlib"
+)
+
+func TestRegistryFindsNearestCatalogSource(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	common.Product.ForceHome(t.TempDir())
+	defer common.Product.ForceHome("")
+
+	blueprint := "0123456789abcdef"
+	for _, catalog := range []string{blueprint + "v12." + common.Platform(), blueprint + "v12.other_platform", "fedcba9876543210v12." + common.Platform()} {
+		must.Nil(pathlib.WriteFile(filepath.Join(common.HololibCatalogLocation(), catalog), []byte("{}"), 0o644))
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", makeHealthHandler())
+	mux.HandleFunc("/registry/", makeRegistryHandler(newDomainGuard(t.TempDir(), "")))
+	remote := httptest.NewServer(mux)
+	defer remote.Close()
+
+	central := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		fmt.Fprintf(response, "%sv12.%s %s\n", blueprint, common.Platform(), "http://127.0.0.1:1")
+	}))
+	defer central.Close()
+
+	entries, problems := operations.QueryRegistry([]string{central.URL, remote.URL}, blueprint)
+	must.Equal(0, len(problems))
+	must.Equal(3, len(entries))
+	must.Equal(remote.URL, entries[0].Source)
+	must.True(entries[0].Latency > 0)
+	must.Equal("other_platform", entries[2].Platform)
+
+	nearest, ok := operations.NearestSource(entries)
+	must.True(ok)
+	must.Equal(remote.URL, nearest.Source)
+
+	_, problems = operations.QueryRegistry([]string{remote.URL + "/missing"}, "not-a-hash")
+	wont.Equal(0, len(problems))
+}
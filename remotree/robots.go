@@ -0,0 +1,184 @@
+package remotree
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/operations"
+)
+
+const (
+	robotsPrefix = "/robots/"
+	latestRobot  = "latest"
+)
+
+var (
+	robotNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+	versionSplitter  = regexp.MustCompile(`[.+_-]`)
+)
+
+// RobotPackage is one robot.zip served by rccremote. Packages are stored in
+// robots directory as "<name>/<version>.zip".
+type RobotPackage struct {
+	Name    string
+	Version string
+	Size    int64
+	Path    string
+}
+
+func validRobotName(name string) bool {
+	return robotNamePattern.MatchString(name) && !strings.Contains(name, "..")
+}
+
+// versionLess compares versions numerically part by part ("1.10" is after
+// "1.9"), and falls back to text comparison on non-numeric parts.
+func versionLess(left, right string) bool {
+	first, second := versionSplitter.Split(left, -1), versionSplitter.Split(right, -1)
+	for at := 0; at < min(len(first), len(second)); at++ {
+		if first[at] == second[at] {
+			continue
+		}
+		one, err1 := strconv.Atoi(first[at])
+		two, err2 := strconv.Atoi(second[at])
+		if err1 == nil && err2 == nil {
+			return one < two
+		}
+		return first[at] < second[at]
+	}
+	return len(first) < len(second)
+}
+
+// ListRobots lists robot packages in robots directory, sorted by name and
+// version (oldest first).
+func ListRobots(directory string) ([]*RobotPackage, error) {
+	result := []*RobotPackage{}
+	names, err := os.ReadDir(directory)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if !name.IsDir() || !validRobotName(name.Name()) {
+			continue
+		}
+		versions, err := os.ReadDir(filepath.Join(directory, name.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, version := range versions {
+			label, ok := strings.CutSuffix(version.Name(), ".zip")
+			if version.IsDir() || !ok || !validRobotName(label) {
+				continue
+			}
+			info, err := version.Info()
+			if err != nil {
+				continue
+			}
+			result = append(result, &RobotPackage{
+				Name:    name.Name(),
+				Version: label,
+				Size:    info.Size(),
+				Path:    filepath.Join(directory, name.Name(), version.Name()),
+			})
+		}
+	}
+	sort.SliceStable(result, func(left, right int) bool {
+		if result[left].Name != result[right].Name {
+			return result[left].Name < result[right].Name
+		}
+		return versionLess(result[left].Version, result[right].Version)
+	})
+	return result, nil
+}
+
+// FindRobot finds robot package by name and version, where empty version
+// (or "latest") means newest version.
+func FindRobot(directory, name, version string) (*RobotPackage, bool) {
+	if !validRobotName(name) {
+		return nil, false
+	}
+	packages, err := ListRobots(directory)
+	if err != nil {
+		return nil, false
+	}
+	var found *RobotPackage
+	for _, candidate := range packages {
+		if candidate.Name != name {
+			continue
+		}
+		if candidate.Version == version {
+			return candidate, true
+		}
+		if len(version) == 0 || version == latestRobot {
+			found = candidate
+		}
+	}
+	return found, found != nil
+}
+
+// makeRobotsHandler serves robot packages: "/robots/" lists them (one
+// "name version size" line per package), "/robots/<name>" gives newest
+// version, and "/robots/<name>/<version>" that exact version. Only robots of
+// client domain are visible.
+func makeRobotsHandler(directory string, domains *domainGuard) http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		defer common.Stopwatch("Robots request %q took", request.URL.Path).Debug()
+		if request.Method != http.MethodGet && request.Method != http.MethodHead {
+			response.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		serves := domains.servesRobot(request)
+		parts := strings.Split(strings.Trim(strings.TrimPrefix(request.URL.Path, robotsPrefix), "/"), "/")
+		if len(parts) == 1 && len(parts[0]) == 0 {
+			packages, err := ListRobots(directory)
+			if err != nil {
+				http.Error(response, fmt.Sprintf("500 internal server error: %v", err), http.StatusInternalServerError)
+				return
+			}
+			response.Header().Set("Content-Type", "text/plain")
+			response.WriteHeader(http.StatusOK)
+			for _, robot := range packages {
+				if serves(robot.Name) {
+					fmt.Fprintf(response, "%s %s %d\n", robot.Name, robot.Version, robot.Size)
+				}
+			}
+			return
+		}
+		if len(parts) > 2 {
+			http.NotFound(response, request)
+			return
+		}
+		version := ""
+		if len(parts) == 2 {
+			version = parts[1]
+		}
+		robot, ok := FindRobot(directory, parts[0], version)
+		if !ok || !serves(robot.Name) {
+			http.Error(response, "404 not found, sorry", http.StatusNotFound)
+			return
+		}
+		source, err := os.Open(robot.Path)
+		if err != nil {
+			http.Error(response, "404 not found, sorry", http.StatusNotFound)
+			return
+		}
+		defer source.Close()
+		stat, err := source.Stat()
+		if err != nil {
+			http.Error(response, fmt.Sprintf("500 internal server error: %v", err), http.StatusInternalServerError)
+			return
+		}
+		common.Log("Serving robot %s version %s to %q.", robot.Name, robot.Version, request.RemoteAddr)
+		headers := response.Header()
+		headers.Set("Content-Type", "application/zip")
+		headers.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", robot.Name+"-"+robot.Version+".zip"))
+		headers.Set(operations.X_RCC_ROBOT_VERSION, robot.Version)
+		http.ServeContent(response, request, robot.Name+".zip", stat.ModTime(), source)
+	}
+}
@@ -0,0 +1,112 @@
+package remotree
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+	"github.com/joshyorko/rcc/operations"
+)
+
+func writeRobotPackage(t *testing.T, directory, name, version, content string) {
+	folder := filepath.Join(directory, name)
+	if err := os.MkdirAll(folder, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(folder, version+".zip"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRobotVersionsAreOrderedNumerically(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	must.True(versionLess("1.9", "1.10"))
+	wont.True(versionLess("1.10", "1.9"))
+	must.True(versionLess("1.2", "1.2.1"))
+	must.True(versionLess("1.0-beta", "1.0-rc"))
+	wont.True(versionLess("2.0", "2.0"))
+}
+
+func TestRobotPackagesAreListedAndFound(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	directory := t.TempDir()
+	writeRobotPackage(t, directory, "invoice-bot", "1.9", "old")
+	writeRobotPackage(t, directory, "invoice-bot", "1.10", "new")
+	writeRobotPackage(t, directory, "report-bot", "0.1", "report")
+	must.Nil(os.WriteFile(filepath.Join(directory, "invoice-bot", "notes.txt"), []byte("skip"), 0o644))
+
+	packages, err := ListRobots(directory)
+	must.Nil(err)
+	must.Equal(3, len(packages))
+	must.Equal("1.9", packages[0].Version)
+	must.Equal("1.10", packages[1].Version)
+	must.Equal("report-bot", packages[2].Name)
+
+	latest, ok := FindRobot(directory, "invoice-bot", "")
+	must.True(ok)
+	must.Equal("1.10", latest.Version)
+	exact, ok := FindRobot(directory, "invoice-bot", "1.9")
+	must.True(ok)
+	must.Equal("1.9", exact.Version)
+	_, ok = FindRobot(directory, "invoice-bot", "2.0")
+	wont.True(ok)
+	_, ok = FindRobot(directory, "..", "")
+	wont.True(ok)
+}
+
+func TestRobotsAreServedPerDomain(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	directory := t.TempDir()
+	writeRobotPackage(t, directory, "invoice-bot", "1.0", "invoice v1")
+	writeRobotPackage(t, directory, "invoice-bot", "1.1", "invoice v1.1")
+	writeRobotPackage(t, directory, "payroll-bot", "3.0", "payroll")
+
+	storage := t.TempDir()
+	domains, err := LoadDomains(storage)
+	must.Nil(err)
+	must.Nil(domains.Add("team-a", nil, []string{"secret-a"}))
+	must.Nil(domains.AddRobots("team-a", []string{"invoice-*"}))
+	wont.Nil(domains.AddRobots("missing", nil))
+	must.Nil(domains.Save(storage))
+
+	guard := newDomainGuard(storage, "team-a")
+	mux := http.NewServeMux()
+	mux.HandleFunc(robotsPrefix, makeRobotsHandler(directory, guard))
+	server := httptest.NewServer(guard.wrap(mux))
+	defer server.Close()
+
+	fetch := func(url, token string) (int, string, string) {
+		request, err := http.NewRequest(http.MethodGet, server.URL+url, nil)
+		must.Nil(err)
+		if len(token) > 0 {
+			request.Header.Set(operations.AUTHORIZATION, token)
+		}
+		response, err := http.DefaultClient.Do(request)
+		must.Nil(err)
+		defer response.Body.Close()
+		body, _ := io.ReadAll(response.Body)
+		return response.StatusCode, string(body), response.Header.Get(operations.X_RCC_ROBOT_VERSION)
+	}
+
+	status, _, _ := fetch("/robots/invoice-bot", "")
+	must.Equal(http.StatusForbidden, status)
+	status, body, version := fetch("/robots/invoice-bot", "secret-a")
+	must.Equal(http.StatusOK, status)
+	must.Equal("invoice v1.1", body)
+	must.Equal("1.1", version)
+	status, body, _ = fetch("/robots/invoice-bot/1.0", "secret-a")
+	must.Equal(http.StatusOK, status)
+	must.Equal("invoice v1", body)
+	status, _, _ = fetch("/robots/payroll-bot", "secret-a")
+	must.Equal(http.StatusNotFound, status)
+	status, body, _ = fetch("/robots/", "secret-a")
+	must.Equal(http.StatusOK, status)
+	must.Equal("invoice-bot 1.0 10\ninvoice-bot 1.1 12\n", body)
+}
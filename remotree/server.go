@@ -1,7 +1,6 @@
 package remotree
 
 import (
-	"context"
 	"fmt"
 	"net/http"
 	"os"
@@ -10,16 +9,29 @@ import (
 	"syscall"
 	"time"
 
-	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/common"
 )
 
-func Serve(address string, port int, domain, storage string) error {
+const (
+	exportsKept = 24 * time.Hour
+)
+
+// Serve runs rccremote until it is signalled to stop. When reload is given,
+// SIGHUP reloads access control and drain timeout instead of stopping. When
+// robots directory is given, robot packages in it are served too.
+func Serve(address string, port int, domain, storage, robots string, proxy bool, access *Access, drainTimeout time.Duration, reload Reload) error {
 	// we need
 	// - query handler (for just catalog hashes)
 	// - partial content sender (for sending delta catalog)
 	// - webserver
+	keeper, err := newGatekeeper(access)
+	if err != nil {
+		return err
+	}
+	tlsConfig := keeper.tlsConfig()
+
 	holding := filepath.Join(storage, "hold")
-	err := cleanupHoldStorage(holding)
+	err = cleanupHoldStorage(holding)
 	if err != nil {
 		return err
 	}
@@ -27,12 +39,17 @@ func Serve(address string, port int, domain, storage string) error {
 
 	tempdir, ok := tempDir()
 	if ok {
-		pathlib.TryRemoveAll("remotree.Serve[start]", tempdir)
-		defer pathlib.TryRemoveAll("remotree.Serve[defer]", tempdir)
+		cleanupExports(tempdir, exportsKept)
+		defer cleanupExports(tempdir, exportsKept)
 	}
 
 	triggers := make(chan string, 20)
 	defer close(triggers)
+	for _, catalog := range loadPendingPulls(storage) {
+		if len(triggers) < cap(triggers) {
+			triggers <- catalog
+		}
+	}
 
 	partqueries := make(Partqueries)
 	defer close(partqueries)
@@ -40,28 +57,79 @@ func Serve(address string, port int, domain, storage string) error {
 	go listProvider(partqueries)
 	go pullProcess(triggers)
 
+	watcher := newCatalogWatcher()
+	stopWatcher := make(chan bool)
+	defer close(stopWatcher)
+	go watcher.watch(stopWatcher)
+
+	state := &serverState{}
+	domains := newDomainGuard(storage, domain)
 	listen := fmt.Sprintf("%s:%d", address, port)
 	mux := http.NewServeMux()
 	server := &http.Server{
 		Addr:           listen,
-		Handler:        mux,
+		Handler:        keeper.wrap(state.track(domains.wrap(mux))),
+		TLSConfig:      tlsConfig,
 		ReadTimeout:    2 * time.Minute,
 		WriteTimeout:   30 * time.Minute,
 		MaxHeaderBytes: 1 << 14,
 	}
 
+	mux.HandleFunc("/healthz", makeHealthHandler())
+	mux.HandleFunc("/readyz", makeReadyHandler(state))
 	mux.HandleFunc("/parts/", makeQueryHandler(partqueries, triggers))
+	mux.HandleFunc("/sizes/", makeSizesHandler(partqueries))
 	mux.HandleFunc("/delta/", makeDeltaHandler(partqueries))
 	mux.HandleFunc("/force/", makeTriggerHandler(triggers))
+	mux.HandleFunc("/catalog/", makeCatalogHandler())
+	mux.HandleFunc("/blob/", makeBlobHandler())
+	mux.HandleFunc("/watch/", makeWatchHandler(watcher, domains, state))
+	mux.HandleFunc("/registry/", makeRegistryHandler(domains))
+	if len(robots) > 0 {
+		mux.HandleFunc(robotsPrefix, makeRobotsHandler(robots, domains))
+	}
+	if proxy {
+		registerProxies(mux, storage)
+	}
 
-	go server.ListenAndServe()
+	if tlsConfig != nil {
+		go server.ListenAndServeTLS("", "")
+	} else {
+		go server.ListenAndServe()
+	}
 
-	return runTillSignal(server)
+	return runTillSignal(server, state, drainTimeout, keeper, reload, func() error {
+		return savePendingPulls(storage, triggers)
+	})
 }
 
-func runTillSignal(server *http.Server) error {
+// reloaded applies reloaded configuration, and gives drain timeout to use.
+// On failure, previous configuration stays in use.
+func reloaded(keeper *gatekeeper, reload Reload, drainTimeout time.Duration) time.Duration {
+	access, timeout, err := reload()
+	if err == nil {
+		err = keeper.update(access)
+	}
+	if err != nil {
+		common.Log("Configuration reload failed, keeping previous configuration, reason: %v", err)
+		return drainTimeout
+	}
+	common.Log("Configuration reloaded (allowed peers: %d, TLS: %v, client CA: %v, drain timeout: %s).", len(access.AllowCidrs), access.TLS(), len(access.ClientCA) > 0, timeout)
+	return timeout
+}
+
+func runTillSignal(server *http.Server, state *serverState, drainTimeout time.Duration, keeper *gatekeeper, reload Reload, persist func() error) error {
 	signals := make(chan os.Signal, 1)
 	signal.Notify(signals, syscall.SIGINT, syscall.SIGHUP, syscall.SIGTERM)
-	<-signals
-	return server.Shutdown(context.TODO())
+	received := <-signals
+	for received == syscall.SIGHUP && reload != nil {
+		drainTimeout = reloaded(keeper, reload, drainTimeout)
+		received = <-signals
+	}
+	common.Log("Received %v, shutting down gracefully ...", received)
+	err := drain(server, state, drainTimeout)
+	if err != nil {
+		common.Log("Draining failed, reason: %v", err)
+	}
+	return persist()
 }
@@ -0,0 +1,129 @@
+package remotree
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pathlib"
+)
+
+const (
+	pendingPullsFile = "pending.pulls"
+)
+
+// serverState tracks whether server is still accepting work, and how many
+// requests are currently in flight, so that it can be drained on shutdown.
+type serverState struct {
+	draining atomic.Bool
+	inflight atomic.Int64
+}
+
+func (it *serverState) track(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		it.inflight.Add(1)
+		defer it.inflight.Add(-1)
+		handler.ServeHTTP(response, request)
+	})
+}
+
+func makeHealthHandler() http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		response.Header().Set("Content-Type", "text/plain")
+		response.Write([]byte("ok\n"))
+	}
+}
+
+func makeReadyHandler(state *serverState) http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		response.Header().Set("Content-Type", "text/plain")
+		if state.draining.Load() {
+			response.WriteHeader(http.StatusServiceUnavailable)
+			response.Write([]byte("draining\n"))
+			return
+		}
+		response.Write([]byte("ready\n"))
+	}
+}
+
+// drain stops accepting new connections and waits in-flight requests to
+// complete, at most given timeout, after which remaining connections are
+// forcibly closed.
+func drain(server *http.Server, state *serverState, timeout time.Duration) error {
+	state.draining.Store(true)
+	common.Log("Draining %d in-flight request(s), waiting at most %s ...", state.inflight.Load(), timeout)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err := server.Shutdown(ctx)
+	if err == context.DeadlineExceeded {
+		common.Log("Drain timeout reached, closing %d remaining request(s).", state.inflight.Load())
+		return server.Close()
+	}
+	return err
+}
+
+// savePendingPulls persists catalogs that were triggered for pulling, but
+// were not yet pulled, so that those are continued on next startup.
+func savePendingPulls(storage string, triggers chan string) error {
+	pending := []string{}
+collecting:
+	for {
+		select {
+		case catalog := <-triggers:
+			pending = append(pending, catalog)
+		default:
+			break collecting
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	_, err := pathlib.EnsureDirectory(storage)
+	if err != nil {
+		return err
+	}
+	common.Log("Saving %d pending pull(s) for next startup.", len(pending))
+	return os.WriteFile(filepath.Join(storage, pendingPullsFile), []byte(strings.Join(pending, "\n")+"\n"), 0o644)
+}
+
+// loadPendingPulls returns (and forgets) pulls that were pending when server
+// was previously shut down.
+func loadPendingPulls(storage string) []string {
+	filename := filepath.Join(storage, pendingPullsFile)
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil
+	}
+	pathlib.TryRemove("pending", filename)
+	result := []string{}
+	for _, line := range strings.Split(string(content), "\n") {
+		catalog := strings.TrimSpace(line)
+		if len(catalog) > 0 {
+			result = append(result, catalog)
+		}
+	}
+	return result
+}
+
+// cleanupExports removes unfinished delta exports, and completed ones older
+// than given age, but keeps recent completed ones, so that they can be served
+// again after restart.
+func cleanupExports(tempdir string, age time.Duration) {
+	partials, _ := filepath.Glob(filepath.Join(tempdir, "*_build.zip"))
+	for _, partial := range partials {
+		pathlib.TryRemove("export", partial)
+	}
+	exports, _ := filepath.Glob(filepath.Join(tempdir, "*_parts.zip"))
+	deadline := time.Now().Add(-age)
+	for _, export := range exports {
+		stat, err := os.Stat(export)
+		if err == nil && stat.ModTime().Before(deadline) {
+			pathlib.TryRemove("export", export)
+		}
+	}
+}
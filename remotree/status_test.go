@@ -0,0 +1,70 @@
+package remotree
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestReadinessFollowsDraining(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	state := &serverState{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", makeHealthHandler())
+	mux.HandleFunc("/readyz", makeReadyHandler(state))
+
+	probe := func(path string) int {
+		response := httptest.NewRecorder()
+		state.track(mux).ServeHTTP(response, httptest.NewRequest(http.MethodGet, path, nil))
+		return response.Code
+	}
+
+	must.Equal(http.StatusOK, probe("/healthz"))
+	must.Equal(http.StatusOK, probe("/readyz"))
+	state.draining.Store(true)
+	must.Equal(http.StatusOK, probe("/healthz"))
+	must.Equal(http.StatusServiceUnavailable, probe("/readyz"))
+	must.Equal(int64(0), state.inflight.Load())
+}
+
+func TestDrainWaitsInflightRequests(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	state := &serverState{}
+	started, finished := make(chan bool), make(chan bool, 1)
+	server := httptest.NewUnstartedServer(state.track(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		response.Write([]byte("done"))
+		finished <- true
+	})))
+	server.Start()
+	defer server.Close()
+
+	go http.Get(server.URL)
+	<-started
+	must.Nil(drain(server.Config, state, 5*time.Second))
+	must.True(state.draining.Load())
+	must.Equal(1, len(finished))
+}
+
+func TestPendingPullsSurviveRestart(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	storage := t.TempDir()
+	must.Equal(0, len(loadPendingPulls(storage)))
+
+	triggers := make(chan string, 5)
+	triggers <- "first"
+	triggers <- "second"
+	must.Nil(savePendingPulls(storage, triggers))
+	must.Equal(0, len(triggers))
+
+	pending := loadPendingPulls(storage)
+	must.Equal([]string{"first", "second"}, pending)
+	must.Equal(0, len(loadPendingPulls(storage)))
+}
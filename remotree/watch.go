@@ -0,0 +1,193 @@
+package remotree
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/htfs"
+	"github.com/joshyorko/rcc/set"
+)
+
+const (
+	watchInterval    = 5 * time.Second
+	watchDefaultWait = 60 * time.Second
+	watchMaximumWait = 5 * time.Minute
+	watchEventsKept  = 1000
+)
+
+type catalogEvent struct {
+	sequence uint64
+	catalog  string
+}
+
+// catalogWatcher tracks new and updated catalogs in hololib as sequence of
+// events, so that subscribed clients can long-poll changes after their
+// cursor, instead of polling everything on schedule.
+type catalogWatcher struct {
+	sync.Mutex
+	sequence uint64
+	known    map[string]time.Time
+	events   []catalogEvent
+	changed  chan bool
+}
+
+func newCatalogWatcher() *catalogWatcher {
+	return &catalogWatcher{
+		known:   make(map[string]time.Time),
+		events:  []catalogEvent{},
+		changed: make(chan bool),
+	}
+}
+
+func currentCatalogs() map[string]time.Time {
+	result := htfs.CompactedCatalogTimes()
+	for _, catalog := range htfs.CatalogNames() {
+		stat, err := os.Stat(filepath.Join(common.HololibCatalogLocation(), catalog))
+		if err == nil {
+			result[catalog] = stat.ModTime()
+		}
+	}
+	return result
+}
+
+// record adds events for catalogs that are new or modified since previous
+// scan, and wakes up waiting clients.
+func (it *catalogWatcher) record(catalogs map[string]time.Time) {
+	it.Lock()
+	defer it.Unlock()
+
+	added := 0
+	for _, catalog := range set.Keys(catalogs) {
+		modified := catalogs[catalog]
+		previous, ok := it.known[catalog]
+		if ok && previous.Equal(modified) {
+			continue
+		}
+		it.known[catalog] = modified
+		it.sequence += 1
+		it.events = append(it.events, catalogEvent{sequence: it.sequence, catalog: catalog})
+		added += 1
+	}
+	if len(it.events) > watchEventsKept {
+		it.events = it.events[len(it.events)-watchEventsKept:]
+	}
+	if added > 0 {
+		common.Debug("Catalog watcher: %d new or updated catalog(s), cursor is now %d.", added, it.sequence)
+		close(it.changed)
+		it.changed = make(chan bool)
+	}
+}
+
+// since returns new cursor, catalogs changed after cursor, and channel that
+// is closed on next change. Zero or unknown cursor (too old, or from before
+// server restart) gets full listing of all known catalogs instead.
+func (it *catalogWatcher) since(cursor uint64) (uint64, []string, bool, <-chan bool) {
+	it.Lock()
+	defer it.Unlock()
+
+	outdated := len(it.events) > 0 && cursor+1 < it.events[0].sequence
+	if cursor > it.sequence || outdated || (cursor == 0 && it.sequence > 0) {
+		return it.sequence, set.Keys(it.known), true, it.changed
+	}
+	result := []string{}
+	for _, event := range it.events {
+		if event.sequence > cursor {
+			result = append(result, event.catalog)
+		}
+	}
+	return it.sequence, set.Set(result), false, it.changed
+}
+
+func (it *catalogWatcher) watch(stop chan bool) {
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+	for {
+		it.record(currentCatalogs())
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func watchWait(request *http.Request) time.Duration {
+	seconds, err := strconv.Atoi(request.URL.Query().Get("wait"))
+	if err != nil || seconds < 0 {
+		return watchDefaultWait
+	}
+	return min(time.Duration(seconds)*time.Second, watchMaximumWait)
+}
+
+// makeWatchHandler serves long-poll subscriptions. Client gives its cursor
+// as "since" parameter, and reply is held until there are new or updated
+// catalogs in client domain, or "wait" seconds have passed. First line of
+// reply is new cursor (followed by "full" when reply lists all catalogs
+// instead of changes), and rest of lines are catalog names.
+func makeWatchHandler(watcher *catalogWatcher, domains *domainGuard, state *serverState) http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodGet {
+			response.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		cursor, err := strconv.ParseUint(request.URL.Query().Get("since"), 10, 64)
+		if err != nil {
+			cursor = 0
+		}
+		serves := domains.serves(request)
+		deadline := time.NewTimer(watchWait(request))
+		defer deadline.Stop()
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+	waiting:
+		for {
+			next, changes, full, changed := watcher.since(cursor)
+			selected := []string{}
+			for _, catalog := range changes {
+				if serves(catalog) {
+					selected = append(selected, catalog)
+				}
+			}
+			cursor = next
+			if len(selected) > 0 || full {
+				writeWatchReply(response, cursor, full, selected)
+				return
+			}
+			for {
+				select {
+				case <-changed:
+					continue waiting
+				case <-deadline.C:
+					break waiting
+				case <-request.Context().Done():
+					return
+				case <-ticker.C:
+					if state.draining.Load() {
+						break waiting
+					}
+				}
+			}
+		}
+		writeWatchReply(response, cursor, false, []string{})
+	}
+}
+
+func writeWatchReply(response http.ResponseWriter, cursor uint64, full bool, catalogs []string) {
+	response.Header().Set("Content-Type", "text/plain")
+	response.WriteHeader(http.StatusOK)
+	if full {
+		fmt.Fprintf(response, "%d full\n", cursor)
+	} else {
+		fmt.Fprintf(response, "%d\n", cursor)
+	}
+	if len(catalogs) > 0 {
+		fmt.Fprintf(response, "%s\n", strings.Join(catalogs, "\n"))
+	}
+}
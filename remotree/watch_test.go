@@ -0,0 +1,74 @@
+package remotree
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/joshyorko/rcc/hamlet"
+	"github.com/joshyorko/rcc/operations"
+)
+
+func TestCatalogWatcherTracksChanges(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	sut := newCatalogWatcher()
+	first := time.Now()
+	sut.record(map[string]time.Time{"bbb": first, "aaa": first})
+
+	cursor, catalogs, full, _ := sut.since(0)
+	must.Equal(uint64(2), cursor)
+	must.Equal([]string{"aaa", "bbb"}, catalogs)
+	must.True(full)
+
+	_, catalogs, full, changed := sut.since(cursor)
+	must.Equal(0, len(catalogs))
+	wont.True(full)
+
+	sut.record(map[string]time.Time{"bbb": first.Add(time.Second), "aaa": first, "ccc": first})
+	select {
+	case <-changed:
+	default:
+		t.Fatal("waiting clients were not woken up")
+	}
+	next, catalogs, full, _ := sut.since(cursor)
+	must.Equal(uint64(4), next)
+	must.Equal([]string{"bbb", "ccc"}, catalogs)
+	wont.True(full)
+
+	_, catalogs, full, _ = sut.since(99)
+	must.Equal([]string{"aaa", "bbb", "ccc"}, catalogs)
+	must.True(full)
+}
+
+func TestWatchHandlerNotifiesSubscribers(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	watcher := newCatalogWatcher()
+	watcher.record(map[string]time.Time{"aaa": time.Now()})
+	server := httptest.NewServer(makeWatchHandler(watcher, newDomainGuard(t.TempDir(), ""), &serverState{}))
+	defer server.Close()
+
+	cursor, catalogs, full, err := operations.CatalogUpdates(server.URL, 0, time.Second)
+	must.Nil(err)
+	must.True(full)
+	must.Equal([]string{"aaa"}, catalogs)
+
+	next, catalogs, full, err := operations.CatalogUpdates(server.URL, cursor, time.Second)
+	must.Nil(err)
+	wont.True(full)
+	must.Equal(cursor, next)
+	must.Equal(0, len(catalogs))
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		watcher.record(map[string]time.Time{"aaa": time.Now(), "bbb": time.Now()})
+	}()
+	started := time.Now()
+	next, catalogs, full, err = operations.CatalogUpdates(server.URL, cursor, 10*time.Second)
+	must.Nil(err)
+	wont.True(full)
+	must.Equal([]string{"aaa", "bbb"}, catalogs)
+	must.Equal(cursor+2, next)
+	must.True(time.Since(started) < 5*time.Second)
+}
@@ -0,0 +1,148 @@
+package robot
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	ArgumentString = "string"
+	ArgumentInt    = "int"
+	ArgumentFloat  = "float"
+	ArgumentBool   = "bool"
+
+	argumentPrefix = "RCC_ARG_"
+)
+
+var (
+	argumentPattern = regexp.MustCompile("^[a-zA-Z][a-zA-Z0-9_-]*$")
+	argumentTypes   = []string{ArgumentString, ArgumentInt, ArgumentFloat, ArgumentBool}
+)
+
+// Argument is named task argument declared in robot.yaml. Arguments are
+// given after "--" on command line (like "--count 5"), validated by their
+// type, and passed to robot as RCC_ARG_<NAME> environment variables and as
+// arguments file.
+type Argument struct {
+	Type     string `yaml:"type,omitempty"`
+	Default  string `yaml:"default,omitempty"`
+	Required bool   `yaml:"required,omitempty"`
+	Help     string `yaml:"help,omitempty"`
+}
+
+func (it *Argument) kind() string {
+	if len(it.Type) == 0 {
+		return ArgumentString
+	}
+	return it.Type
+}
+
+func (it *Argument) convert(value string) (any, error) {
+	switch it.kind() {
+	case ArgumentInt:
+		return strconv.ParseInt(value, 10, 64)
+	case ArgumentFloat:
+		return strconv.ParseFloat(value, 64)
+	case ArgumentBool:
+		return strconv.ParseBool(value)
+	}
+	return value, nil
+}
+
+func validateArguments(arguments map[string]*Argument) error {
+	for name, argument := range arguments {
+		if !argumentPattern.MatchString(name) {
+			return fmt.Errorf("has invalid 'arguments:' name %q!", name)
+		}
+		if argument == nil {
+			return fmt.Errorf("has empty 'arguments:' declaration for %q!", name)
+		}
+		if !slices.Contains(argumentTypes, argument.kind()) {
+			return fmt.Errorf("has invalid type %q for argument %q (expected one of %s)!", argument.Type, name, strings.Join(argumentTypes, ", "))
+		}
+		if argument.Required && len(argument.Default) > 0 {
+			return fmt.Errorf("has both 'required:' and 'default:' for argument %q!", name)
+		}
+		if len(argument.Default) > 0 {
+			_, err := argument.convert(argument.Default)
+			if err != nil {
+				return fmt.Errorf("has default %q for argument %q which is not %s!", argument.Default, name, argument.kind())
+			}
+		}
+	}
+	return nil
+}
+
+// ArgumentVariable gives environment variable name of task argument.
+func ArgumentVariable(name string) string {
+	return argumentPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// ParseArguments picks declared task arguments from command line arguments
+// (given after "--"), and gives their typed values (with defaults applied)
+// and remaining arguments, which are passed to task command as before.
+// Missing required arguments and values of wrong type are errors.
+func ParseArguments(todo Task, args []string) (map[string]any, []string, error) {
+	declared := todo.Arguments()
+	if len(declared) == 0 {
+		return nil, args, nil
+	}
+	given := make(map[string]string)
+	rest := make([]string, 0, len(args))
+	for at := 0; at < len(args); at++ {
+		name, value, inline := strings.Cut(strings.TrimPrefix(args[at], "--"), "=")
+		argument, ok := declared[name]
+		if !ok || !strings.HasPrefix(args[at], "--") {
+			rest = append(rest, args[at])
+			continue
+		}
+		switch {
+		case inline:
+		case argument.kind() == ArgumentBool && (at+1 == len(args) || strings.HasPrefix(args[at+1], "--")):
+			value = "true"
+		case at+1 < len(args):
+			at++
+			value = args[at]
+		default:
+			return nil, nil, fmt.Errorf("Task argument --%s needs a value.", name)
+		}
+		given[name] = value
+	}
+	values := make(map[string]any)
+	missing := []string{}
+	for _, name := range slices.Sorted(maps.Keys(declared)) {
+		argument := declared[name]
+		value, ok := given[name]
+		if !ok {
+			value = argument.Default
+		}
+		if len(value) == 0 {
+			if argument.Required {
+				missing = append(missing, "--"+name)
+			}
+			continue
+		}
+		typed, err := argument.convert(value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("Task argument --%s value %q is not %s.", name, value, argument.kind())
+		}
+		values[name] = typed
+	}
+	if len(missing) > 0 {
+		return nil, nil, fmt.Errorf("Task requires arguments %s, but they were not given.", strings.Join(missing, ", "))
+	}
+	return values, rest, nil
+}
+
+// ArgumentsEnvironment gives task argument values as environment variables.
+func ArgumentsEnvironment(values map[string]any) map[string]string {
+	result := make(map[string]string)
+	for name, value := range values {
+		result[ArgumentVariable(name)] = fmt.Sprintf("%v", value)
+	}
+	return result
+}
@@ -0,0 +1,119 @@
+package robot
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	ContractFail = "fail"
+	ContractWarn = "warn"
+)
+
+// Contract declares files (glob patterns, relative to robot root) and
+// environment variables, that task expects to exist before run (inputs:),
+// or files that task produces during run (outputs:). Policy tells if
+// broken contract fails the run, or just warns about it.
+type Contract struct {
+	Files     []string `yaml:"files,omitempty"`
+	Variables []string `yaml:"variables,omitempty"`
+	Policy    string   `yaml:"policy,omitempty"`
+}
+
+// ContractResult is outcome of checking one contract of a task.
+type ContractResult struct {
+	Kind    string
+	Policy  string
+	Checked int
+	Missing []string
+}
+
+func (it *ContractResult) Ok() bool {
+	return len(it.Missing) == 0
+}
+
+func (it *ContractResult) Failed() bool {
+	return !it.Ok() && it.Policy == ContractFail
+}
+
+func (it *Contract) validate(kind string, variables bool) error {
+	if it == nil {
+		return nil
+	}
+	if it.Policy != "" && it.Policy != ContractFail && it.Policy != ContractWarn {
+		return fmt.Errorf("has invalid '%s:' policy %q (expected %q or %q)!", kind, it.Policy, ContractFail, ContractWarn)
+	}
+	if !variables && len(it.Variables) > 0 {
+		return fmt.Errorf("cannot declare variables in '%s:'!", kind)
+	}
+	for _, name := range it.Variables {
+		if !EnvKeyPattern.MatchString(name) {
+			return fmt.Errorf("has invalid '%s:' variable name %q!", kind, name)
+		}
+	}
+	for _, pattern := range it.Files {
+		_, err := filepath.Match(pattern, "")
+		if err != nil {
+			return fmt.Errorf("has invalid '%s:' file pattern %q!", kind, pattern)
+		}
+	}
+	return nil
+}
+
+func (it *Contract) check(kind, defaults, root string, environment []string) *ContractResult {
+	if it == nil {
+		return nil
+	}
+	result := &ContractResult{
+		Kind:    kind,
+		Policy:  it.Policy,
+		Checked: len(it.Files) + len(it.Variables),
+		Missing: []string{},
+	}
+	if len(result.Policy) == 0 {
+		result.Policy = defaults
+	}
+	for _, pattern := range it.Files {
+		fullpath := pattern
+		if !filepath.IsAbs(fullpath) {
+			fullpath = filepath.Join(root, pattern)
+		}
+		found, _ := filepath.Glob(fullpath)
+		if len(found) == 0 {
+			result.Missing = append(result.Missing, pattern)
+		}
+	}
+	present := presentVariables(environment)
+	for _, name := range it.Variables {
+		if !present[name] {
+			result.Missing = append(result.Missing, name)
+		}
+	}
+	return result
+}
+
+func presentVariables(environment []string) map[string]bool {
+	present := make(map[string]bool)
+	for _, entry := range environment {
+		key, value, ok := strings.Cut(entry, "=")
+		if ok {
+			present[key] = len(value) > 0
+		}
+	}
+	return present
+}
+
+// CheckInputs verifies that declared input files exist under robot root and
+// declared input variables are available in environment. Default policy for
+// inputs is to fail. Result is nil, if task has no inputs declared.
+func CheckInputs(todo Task, root string, environment []string) *ContractResult {
+	return todo.Inputs().check("inputs", ContractFail, root, environment)
+}
+
+// CheckOutputs verifies that declared output files exist under robot root
+// after run. Default policy for outputs is to warn. Result is nil, if task
+// has no outputs declared.
+func CheckOutputs(todo Task, root string) *ContractResult {
+	return todo.Outputs().check("outputs", ContractWarn, root, nil)
+}
@@ -7,8 +7,10 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"slices"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/joshyorko/rcc/common"
 	"github.com/joshyorko/rcc/conda"
@@ -19,9 +21,18 @@ import (
 	"gopkg.in/yaml.v2"
 )
 
+const (
+	CapabilityNetwork    = "network"
+	CapabilityWriteRobot = "write-robot"
+	CapabilitySharedTemp = "shared-tmp"
+)
+
 var (
+	SandboxCapabilities = []string{CapabilityNetwork, CapabilityWriteRobot, CapabilitySharedTemp}
+
 	GoosPattern   = regexp.MustCompile("(?i:(windows|darwin|linux))")
 	GoarchPattern = regexp.MustCompile("(?i:(amd64|arm64))")
+	EnvKeyPattern = regexp.MustCompile("^[A-Za-z_][A-Za-z0-9_]*$")
 )
 
 type Robot interface {
@@ -31,6 +42,7 @@ type Robot interface {
 	TaskByName(string) Task
 	UsesConda() bool
 	CondaConfigFile() string
+	SharedEnvironment() (*SharedEnvironment, error)
 	PreRunScripts() []string
 	RootDirectory() string
 	HasHolozip() bool
@@ -46,29 +58,64 @@ type Robot interface {
 	PythonPaths() pathlib.PathParts
 	SearchPath(location string) pathlib.PathParts
 	RobotExecutionEnvironment(location string, inject []string, full bool) []string
+	RunLimits() *Limits
+	RunSandbox() *Sandbox
+	LocalPackages() []string
 }
 
 type Task interface {
 	Commandline() []string
+	Environment() []string
+	Secrets() []string
+	Inputs() *Contract
+	Outputs() *Contract
+	Arguments() map[string]*Argument
 }
 
 type robot struct {
 	Tasks        map[string]*task `yaml:"tasks"`
 	Devtasks     map[string]*task `yaml:"devTasks"`
 	Conda        string           `yaml:"condaConfigFile,omitempty"`
+	Shared       string           `yaml:"sharedEnvironment,omitempty"`
 	PreRun       []string         `yaml:"preRunScripts,omitempty"`
 	Environments []string         `yaml:"environmentConfigs,omitempty"`
 	Ignored      []string         `yaml:"ignoreFiles"`
 	Artifacts    string           `yaml:"artifactsDir"`
 	Path         []string         `yaml:"PATH"`
 	Pythonpath   []string         `yaml:"PYTHONPATH"`
+	Limits       *Limits          `yaml:"limits,omitempty"`
+	Sandbox      *Sandbox         `yaml:"sandbox,omitempty"`
+	Local        []string         `yaml:"localPackages,omitempty"`
 	Root         string
 }
 
+type Limits struct {
+	Timeout  string `yaml:"timeout,omitempty"`
+	Memory   uint64 `yaml:"memory,omitempty"`
+	Niceness int    `yaml:"niceness,omitempty"`
+}
+
+// Sandbox is opt-in isolation of robot processes, and capabilities that
+// robot declares it needs when running inside sandbox.
+type Sandbox struct {
+	Enabled      bool     `yaml:"enabled,omitempty"`
+	Capabilities []string `yaml:"capabilities,omitempty"`
+}
+
+// Allows tells if sandbox capability is declared.
+func (it *Sandbox) Allows(capability string) bool {
+	return it != nil && slices.Contains(it.Capabilities, capability)
+}
+
 type task struct {
-	Task    string   `yaml:"robotTaskName,omitempty"`
-	Shell   string   `yaml:"shell,omitempty"`
-	Command []string `yaml:"command,omitempty"`
+	Task    string               `yaml:"robotTaskName,omitempty"`
+	Shell   string               `yaml:"shell,omitempty"`
+	Command []string             `yaml:"command,omitempty"`
+	Env     map[string]string    `yaml:"env,omitempty"`
+	Require []string             `yaml:"secrets,omitempty"`
+	Input   *Contract            `yaml:"inputs,omitempty"`
+	Output  *Contract            `yaml:"outputs,omitempty"`
+	Args    map[string]*Argument `yaml:"arguments,omitempty"`
 	robot   *robot
 }
 
@@ -259,6 +306,37 @@ func (it *robot) Validate() (bool, error) {
 		if count != 1 {
 			return false, fmt.Errorf("In robot.yaml, task '%s' needs exactly one of robotTaskName/shell/command definition!", name)
 		}
+		err := task.validateEnvironment()
+		if err != nil {
+			return false, fmt.Errorf("In robot.yaml, task '%s' %v", name, err)
+		}
+	}
+	if len(it.Shared) > 0 {
+		if len(it.Conda) > 0 || len(it.Environments) > 0 {
+			return false, errors.New("In robot.yaml, 'sharedEnvironment:' cannot be used together with 'condaConfigFile:' or 'environmentConfigs:'!")
+		}
+		_, err := it.SharedEnvironment()
+		if err != nil {
+			return false, fmt.Errorf("In robot.yaml, 'sharedEnvironment:' %v", err)
+		}
+	}
+	if it.Limits != nil && len(it.Limits.Timeout) > 0 {
+		_, err := time.ParseDuration(it.Limits.Timeout)
+		if err != nil {
+			return false, fmt.Errorf("In robot.yaml, 'limits:' has invalid timeout %q, reason: %v", it.Limits.Timeout, err)
+		}
+	}
+	if it.Sandbox != nil {
+		for _, capability := range it.Sandbox.Capabilities {
+			if !slices.Contains(SandboxCapabilities, capability) {
+				return false, fmt.Errorf("In robot.yaml, 'sandbox:' has unknown capability %q, known ones are: %s", capability, strings.Join(SandboxCapabilities, ", "))
+			}
+		}
+	}
+	for _, filename := range it.LocalPackages() {
+		if !pathlib.IsFile(filename) {
+			return false, fmt.Errorf("In robot.yaml, 'localPackages:' has %q, which does not exist!", filename)
+		}
 	}
 	return true, nil
 }
@@ -369,7 +447,16 @@ func (it *robot) TaskByName(name string) Task {
 }
 
 func (it *robot) UsesConda() bool {
-	return len(it.Conda) > 0 || len(it.availableEnvironmentConfigurations(common.Platform())) > 0
+	return len(it.Shared) > 0 || len(it.Conda) > 0 || len(it.availableEnvironmentConfigurations(common.Platform())) > 0
+}
+
+// SharedEnvironment returns resolved shared environment, or nil if robot
+// does not use one.
+func (it *robot) SharedEnvironment() (*SharedEnvironment, error) {
+	if len(it.Shared) == 0 {
+		return nil, nil
+	}
+	return ResolveSharedEnvironment(it.Root, it.Shared)
 }
 
 func (it *robot) CondaConfigFile() string {
@@ -379,6 +466,10 @@ func (it *robot) CondaConfigFile() string {
 }
 
 func (it *robot) resolveCondaConfigFile() string {
+	shared, err := it.SharedEnvironment()
+	if err == nil && shared != nil {
+		return shared.Conda
+	}
 	available := it.availableEnvironmentConfigurations(common.Platform())
 	if len(available) > 0 {
 		return available[0]
@@ -386,6 +477,28 @@ func (it *robot) resolveCondaConfigFile() string {
 	return filepath.Join(it.Root, it.Conda)
 }
 
+func (it *robot) RunLimits() *Limits {
+	return it.Limits
+}
+
+func (it *robot) RunSandbox() *Sandbox {
+	return it.Sandbox
+}
+
+// LocalPackages gives local package files declared in robot.yaml, relative
+// paths resolved from robot directory.
+func (it *robot) LocalPackages() []string {
+	result := make([]string, 0, len(it.Local))
+	for _, entry := range it.Local {
+		if filepath.IsAbs(entry) {
+			result = append(result, entry)
+		} else {
+			result = append(result, filepath.Join(it.Root, entry))
+		}
+	}
+	return result
+}
+
 func (it *robot) PreRunScripts() []string {
 	return it.PreRun
 }
@@ -520,6 +633,64 @@ func (it *task) Commandline() []string {
 	return it.Command
 }
 
+func (it *task) validateEnvironment() error {
+	for key := range it.Env {
+		if !EnvKeyPattern.MatchString(key) {
+			return fmt.Errorf("has invalid 'env:' variable name %q!", key)
+		}
+	}
+	for _, name := range it.Require {
+		if !EnvKeyPattern.MatchString(name) {
+			return fmt.Errorf("has invalid 'secrets:' variable name %q!", name)
+		}
+	}
+	err := it.Input.validate("inputs", true)
+	if err != nil {
+		return err
+	}
+	err = validateArguments(it.Args)
+	if err != nil {
+		return err
+	}
+	return it.Output.validate("outputs", false)
+}
+
+func (it *task) Environment() []string {
+	result := make([]string, 0, len(it.Env))
+	for key, value := range it.Env {
+		result = append(result, fmt.Sprintf("%s=%s", key, value))
+	}
+	sort.Strings(result)
+	return result
+}
+
+func (it *task) Secrets() []string {
+	return it.Require
+}
+
+func (it *task) Inputs() *Contract {
+	return it.Input
+}
+
+func (it *task) Outputs() *Contract {
+	return it.Output
+}
+
+func (it *task) Arguments() map[string]*Argument {
+	return it.Args
+}
+
+func MissingSecrets(todo Task, environment []string) []string {
+	present := presentVariables(environment)
+	missing := []string{}
+	for _, name := range todo.Secrets() {
+		if !present[name] {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
 func robotFrom(content []byte) (*robot, error) {
 	config := robot{}
 	err := yaml.Unmarshal(content, &config)
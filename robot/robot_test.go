@@ -1,6 +1,9 @@
 package robot_test
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -115,3 +118,147 @@ func TestCanGetTaskFormCommand(t *testing.T) {
 	wont.Nil(command)
 	must.Equal(12, len(command))
 }
+
+func TestCanReadTaskEnvironmentAndSecrets(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	sut, err := robot.LoadRobotYaml("testdata/taskenv.yaml", false)
+	must.Nil(err)
+	wont.Nil(sut)
+	valid, err := sut.Validate()
+	must.True(valid)
+	must.Nil(err)
+
+	plain := sut.TaskByName("plain task")
+	wont.Nil(plain)
+	must.Equal(0, len(plain.Environment()))
+	must.Equal(0, len(robot.MissingSecrets(plain, nil)))
+
+	report := sut.TaskByName("report task")
+	wont.Nil(report)
+	must.Equal([]string{"REPORT_FORMAT=pdf", "REPORT_LEVEL=3"}, report.Environment())
+	must.Equal([]string{"REPORT_API_KEY", "REPORT_USER"}, robot.MissingSecrets(report, []string{"PATH=/bin"}))
+	must.Equal([]string{"REPORT_USER"}, robot.MissingSecrets(report, []string{"REPORT_API_KEY=secret", "REPORT_USER="}))
+	must.Equal(0, len(robot.MissingSecrets(report, []string{"REPORT_API_KEY=secret", "REPORT_USER=robot"})))
+}
+
+func TestCanCheckTaskContracts(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	sut, err := robot.LoadRobotYaml("testdata/contract.yaml", false)
+	must.Nil(err)
+	valid, err := sut.Validate()
+	must.True(valid)
+	must.Nil(err)
+
+	plain := sut.TaskByName("plain task")
+	wont.Nil(plain)
+	must.Nil(robot.CheckInputs(plain, "testdata", nil))
+	must.Nil(robot.CheckOutputs(plain, "testdata"))
+
+	task := sut.TaskByName("contract task")
+	wont.Nil(task)
+	inputs := robot.CheckInputs(task, "testdata", []string{"WORKITEM_INPUT=items.json"})
+	wont.Nil(inputs)
+	must.Equal(robot.ContractFail, inputs.Policy)
+	must.Equal(3, inputs.Checked)
+	must.Equal([]string{"devdata/missing.csv"}, inputs.Missing)
+	must.True(inputs.Failed())
+
+	outputs := robot.CheckOutputs(task, "testdata")
+	must.Equal([]string{"output/report.xlsx"}, outputs.Missing)
+	must.True(outputs.Failed())
+	outputs = robot.CheckOutputs(task, t.TempDir())
+	must.True(outputs.Failed())
+}
+
+func TestBrokenTaskContractsAreInvalid(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	for _, contract := range []string{
+		"inputs: {policy: maybe}",
+		"inputs: {variables: [\"bad name\"]}",
+		"outputs: {variables: [REPORT]}",
+		"outputs: {files: [\"[broken\"]}",
+	} {
+		content := fmt.Sprintf("tasks:\n  task:\n    shell: python -m task\n    %s\n", contract)
+		filename := filepath.Join(t.TempDir(), "robot.yaml")
+		must.Nil(os.WriteFile(filename, []byte(content), 0o644))
+		sut, err := robot.LoadRobotYaml(filename, false)
+		must.Nil(err)
+		valid, err := sut.Validate()
+		wont.True(valid)
+		wont.Nil(err)
+	}
+}
+
+func TestCanParseTypedTaskArguments(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	content := `artifactsDir: output
+tasks:
+  process:
+    shell: python -m process
+    arguments:
+      count: {type: int, default: "10"}
+      ratio: {type: float}
+      dry-run: {type: bool}
+      mode: {required: true, help: "Processing mode."}
+`
+	filename := filepath.Join(t.TempDir(), "robot.yaml")
+	must.Nil(os.WriteFile(filename, []byte(content), 0o644))
+	sut, err := robot.LoadRobotYaml(filename, false)
+	must.Nil(err)
+	valid, err := sut.Validate()
+	must.True(valid)
+	must.Nil(err)
+	task := sut.TaskByName("process")
+	wont.Nil(task)
+
+	values, rest, err := robot.ParseArguments(task, []string{"--mode", "fast", "--dry-run", "--loglevel", "TRACE", "--ratio=0.5"})
+	must.Nil(err)
+	must.Equal(map[string]any{"count": int64(10), "ratio": 0.5, "dry-run": true, "mode": "fast"}, values)
+	must.Equal([]string{"--loglevel", "TRACE"}, rest)
+	must.Equal(map[string]string{
+		"RCC_ARG_COUNT":   "10",
+		"RCC_ARG_RATIO":   "0.5",
+		"RCC_ARG_DRY_RUN": "true",
+		"RCC_ARG_MODE":    "fast",
+	}, robot.ArgumentsEnvironment(values))
+
+	_, _, err = robot.ParseArguments(task, []string{"--count", "5"})
+	wont.Nil(err)
+	_, _, err = robot.ParseArguments(task, []string{"--mode", "fast", "--count", "many"})
+	wont.Nil(err)
+	_, _, err = robot.ParseArguments(task, []string{"--mode"})
+	wont.Nil(err)
+
+	values, rest, err = robot.ParseArguments(sut.TaskByName("process"), []string{"--mode=slow", "--dry-run", "false"})
+	must.Nil(err)
+	must.Equal(false, values["dry-run"])
+	must.Equal(0, len(rest))
+}
+
+func TestBrokenTaskArgumentsAreInvalid(t *testing.T) {
+	_, wont := hamlet.Specifications(t)
+
+	for _, arguments := range []string{
+		"arguments: {\"bad name\": {}}",
+		"arguments: {count: {type: integer}}",
+		"arguments: {count: {type: int, default: many}}",
+		"arguments: {mode: {required: true, default: fast}}",
+	} {
+		content := fmt.Sprintf("artifactsDir: output\ntasks:\n  task:\n    shell: python -m task\n    %s\n", arguments)
+		filename := filepath.Join(t.TempDir(), "robot.yaml")
+		if err := os.WriteFile(filename, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		sut, err := robot.LoadRobotYaml(filename, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		valid, err := sut.Validate()
+		wont.True(valid)
+		wont.Nil(err)
+	}
+}
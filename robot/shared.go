@@ -0,0 +1,123 @@
+package robot
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joshyorko/rcc/pathlib"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	SharedEnvironmentsFile = "environments.yaml"
+	sharedSpacePrefix      = "shared-"
+)
+
+// SharedEnvironment is resolved named environment definition, that multiple
+// robots can reference from their robot.yaml (sharedEnvironment: key), so
+// that they all resolve to same blueprint and same holotree space.
+type SharedEnvironment struct {
+	Name      string
+	Conda     string
+	Space     string
+	Workspace string
+}
+
+type sharedEntry struct {
+	Conda string `yaml:"conda"`
+	Space string `yaml:"space,omitempty"`
+}
+
+type sharedEnvironments struct {
+	Environments map[string]*sharedEntry `yaml:"environments"`
+}
+
+func isSharedPath(reference string) bool {
+	extension := strings.ToLower(filepath.Ext(reference))
+	return extension == ".yaml" || extension == ".yml" || strings.ContainsAny(reference, `/\`)
+}
+
+func sharedFromPath(root, reference string) (*SharedEnvironment, error) {
+	fullpath := reference
+	if !filepath.IsAbs(fullpath) {
+		fullpath = filepath.Join(root, reference)
+	}
+	if pathlib.IsDir(fullpath) {
+		fullpath = filepath.Join(fullpath, "conda.yaml")
+	}
+	if !pathlib.IsFile(fullpath) {
+		return nil, fmt.Errorf("shared environment %q does not exist at %q", reference, fullpath)
+	}
+	name := filepath.Base(filepath.Dir(fullpath))
+	return &SharedEnvironment{
+		Name:  name,
+		Conda: fullpath,
+		Space: sharedSpacePrefix + name,
+	}, nil
+}
+
+// FindSharedEnvironments finds nearest workspace environments.yaml file,
+// starting from given directory and going up towards filesystem root.
+func FindSharedEnvironments(directory string) (string, bool) {
+	current := directory
+	for {
+		candidate := filepath.Join(current, SharedEnvironmentsFile)
+		if pathlib.IsFile(candidate) {
+			return candidate, true
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return "", false
+		}
+		current = parent
+	}
+}
+
+func sharedFromAlias(root, alias string) (*SharedEnvironment, error) {
+	workspace, ok := FindSharedEnvironments(root)
+	if !ok {
+		return nil, fmt.Errorf("shared environment %q needs %s file in robot directory or above it", alias, SharedEnvironmentsFile)
+	}
+	content, err := os.ReadFile(workspace)
+	if err != nil {
+		return nil, err
+	}
+	definitions := &sharedEnvironments{}
+	err = yaml.Unmarshal(content, definitions)
+	if err != nil {
+		return nil, fmt.Errorf("%q: %w", workspace, err)
+	}
+	entry, ok := definitions.Environments[alias]
+	if !ok || entry == nil || len(entry.Conda) == 0 {
+		return nil, fmt.Errorf("shared environment %q is not defined in %q", alias, workspace)
+	}
+	conda := entry.Conda
+	if !filepath.IsAbs(conda) {
+		conda = filepath.Join(filepath.Dir(workspace), conda)
+	}
+	if !pathlib.IsFile(conda) {
+		return nil, fmt.Errorf("shared environment %q in %q points to missing file %q", alias, workspace, conda)
+	}
+	space := entry.Space
+	if len(space) == 0 {
+		space = sharedSpacePrefix + alias
+	}
+	return &SharedEnvironment{
+		Name:      alias,
+		Conda:     conda,
+		Space:     space,
+		Workspace: workspace,
+	}, nil
+}
+
+// ResolveSharedEnvironment resolves reference, which is either path to
+// conda.yaml (or directory having one), or alias defined in workspace
+// environments.yaml file.
+func ResolveSharedEnvironment(root, reference string) (*SharedEnvironment, error) {
+	if isSharedPath(reference) {
+		return sharedFromPath(root, reference)
+	}
+	return sharedFromAlias(root, reference)
+}
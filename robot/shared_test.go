@@ -0,0 +1,73 @@
+package robot_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+	"github.com/joshyorko/rcc/robot"
+)
+
+func writeSharedFixture(t *testing.T, root, relative, content string) string {
+	fullpath := filepath.Join(root, relative)
+	err := os.MkdirAll(filepath.Dir(fullpath), 0o755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(fullpath, []byte(content), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fullpath
+}
+
+func TestRobotsCanShareEnvironmentByAliasAndPath(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	workspace := t.TempDir()
+	conda := writeSharedFixture(t, workspace, "shared/reporting/conda.yaml", "dependencies:\n- python=3.10\n")
+	writeSharedFixture(t, workspace, "environments.yaml", "environments:\n  reporting:\n    conda: shared/reporting/conda.yaml\n  nightly:\n    conda: shared/reporting/conda.yaml\n    space: night\n  broken:\n    conda: shared/missing/conda.yaml\n")
+	robotTasks := "tasks:\n  Demo:\n    shell: python task.py\nartifactsDir: output\n"
+	first := writeSharedFixture(t, workspace, "robots/first/robot.yaml", robotTasks+"sharedEnvironment: reporting\n")
+	second := writeSharedFixture(t, workspace, "robots/second/robot.yaml", robotTasks+"sharedEnvironment: ../../shared/reporting\n")
+	mixed := writeSharedFixture(t, workspace, "robots/mixed/robot.yaml", robotTasks+"sharedEnvironment: reporting\ncondaConfigFile: conda.yaml\n")
+
+	config, err := robot.LoadRobotYaml(first, false)
+	must.Nil(err)
+	ok, err := config.Validate()
+	must.True(ok)
+	must.Nil(err)
+	must.True(config.UsesConda())
+	must.Equal(conda, config.CondaConfigFile())
+	shared, err := config.SharedEnvironment()
+	must.Nil(err)
+	must.Equal("reporting", shared.Name)
+	must.Equal("shared-reporting", shared.Space)
+	must.Equal(filepath.Join(workspace, "environments.yaml"), shared.Workspace)
+
+	config, err = robot.LoadRobotYaml(second, false)
+	must.Nil(err)
+	must.Equal(conda, config.CondaConfigFile())
+	shared, err = config.SharedEnvironment()
+	must.Nil(err)
+	must.Equal("shared-reporting", shared.Space)
+	must.Equal("", shared.Workspace)
+
+	shared, err = robot.ResolveSharedEnvironment(filepath.Dir(first), "nightly")
+	must.Nil(err)
+	must.Equal("night", shared.Space)
+
+	_, err = robot.ResolveSharedEnvironment(filepath.Dir(first), "broken")
+	wont.Nil(err)
+	_, err = robot.ResolveSharedEnvironment(filepath.Dir(first), "unknown")
+	wont.Nil(err)
+	_, err = robot.ResolveSharedEnvironment(t.TempDir(), "reporting")
+	wont.Nil(err)
+
+	config, err = robot.LoadRobotYaml(mixed, false)
+	must.Nil(err)
+	ok, err = config.Validate()
+	wont.True(ok)
+	wont.Nil(err)
+}
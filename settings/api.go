@@ -26,6 +26,7 @@ type Api interface {
 	PypiLink(page string) string
 	CondaLink(page string) string
 	Hostnames() []string
+	DeniedLicenses() []string
 	ConfiguredHttpTransport() *http.Transport
 	NoProxy() string
 	HttpsProxy() string
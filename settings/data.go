@@ -2,6 +2,7 @@ package settings
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/url"
 	"sort"
 	"strings"
@@ -17,6 +18,7 @@ const (
 
 type StringMap map[string]string
 type BoolMap map[string]bool
+type StringLists map[string][]string
 
 func (it StringMap) Lookup(key string) string {
 	return it[key]
@@ -33,9 +35,12 @@ func (it SettingsLayers) Effective() *Settings {
 		Branding:     make(StringMap),
 		Certificates: &Certificates{},
 		Network:      &Network{},
+		Notify:       &Notify{},
 		Endpoints:    make(StringMap),
+		Mirrors:      make(StringLists),
 		Options:      make(BoolMap),
 		Hosts:        make([]string, 0, 100),
+		Licenses:     make([]string, 0, 10),
 		Meta: &Meta{
 			Name:        "generated",
 			Description: "generated",
@@ -56,9 +61,12 @@ type Settings struct {
 	Branding     StringMap     `yaml:"branding,omitempty" json:"branding,omitempty"`
 	Certificates *Certificates `yaml:"certificates,omitempty" json:"certificates,omitempty"`
 	Network      *Network      `yaml:"network,omitempty" json:"network,omitempty"`
+	Notify       *Notify       `yaml:"notifications,omitempty" json:"notifications,omitempty"`
 	Endpoints    StringMap     `yaml:"endpoints,omitempty" json:"endpoints,omitempty"`
+	Mirrors      StringLists   `yaml:"mirrors,omitempty" json:"mirrors,omitempty"`
 	Hosts        []string      `yaml:"diagnostics-hosts,omitempty" json:"diagnostics-hosts,omitempty"`
 	Options      BoolMap       `yaml:"options,omitempty" json:"options,omitempty"`
+	Licenses     []string      `yaml:"deny-licenses,omitempty" json:"deny-licenses,omitempty"`
 	Meta         *Meta         `yaml:"meta,omitempty" json:"meta,omitempty"`
 }
 
@@ -98,18 +106,30 @@ func (it *Settings) onTopOf(target *Settings) {
 			target.Endpoints[key] = value
 		}
 	}
+	for key, value := range it.Mirrors {
+		if len(value) > 0 {
+			target.Mirrors[key] = value
+		}
+	}
 	for key, value := range it.Options {
 		target.Options[key] = value
 	}
 	for _, host := range it.Hosts {
 		target.Hosts = append(target.Hosts, host)
 	}
+	for _, license := range it.Licenses {
+		target.Licenses = append(target.Licenses, license)
+	}
 	if it.Certificates != nil {
 		it.Certificates.onTopOf(target)
 	}
 	if it.Network != nil {
 		it.Network.onTopOf(target)
 	}
+	if it.Notify != nil {
+		notify := *it.Notify
+		target.Notify = &notify
+	}
 	if it.Meta != nil {
 		it.Meta.onTopOf(target)
 	}
@@ -137,6 +157,11 @@ func (it *Settings) Hostnames() []string {
 			hostFromUrl(name, collector)
 		}
 	}
+	for _, mirrors := range it.Mirrors {
+		for _, name := range mirrors {
+			hostFromUrl(name, collector)
+		}
+	}
 	if it.Hosts != nil {
 		for _, name := range it.Hosts {
 			collector[name] = true
@@ -220,6 +245,11 @@ func (it *Settings) Diagnostics(target *common.DiagnosticStatus) {
 		correct = diagnoseOptionalUrl(it.Endpoints["pypi"], "endpoints/pypi", diagnose, correct)
 		correct = diagnoseOptionalUrl(it.Endpoints["pypi-trusted"], "endpoints/pypi-trusted", diagnose, correct)
 	}
+	for key, mirrors := range it.Mirrors {
+		for at, mirror := range mirrors {
+			correct = diagnoseUrl(mirror, fmt.Sprintf("mirrors/%s[%d]", key, at), diagnose, correct)
+		}
+	}
 	if it.Meta == nil {
 		diagnose.Warning(0, "", "settings.yaml: meta section is totally missing")
 		correct = false
@@ -292,6 +322,14 @@ func (it *Meta) onTopOf(target *Settings) {
 	}
 }
 
+// Notify configures completion notifications of environment builds and
+// robot runs, which last longer than After seconds.
+type Notify struct {
+	Desktop bool `yaml:"desktop" json:"desktop"`
+	Bell    bool `yaml:"bell" json:"bell"`
+	After   int  `yaml:"after-seconds" json:"after-seconds"`
+}
+
 type Network struct {
 	NoProxy    string `yaml:"no-proxy" json:"no-proxy"`
 	HttpsProxy string `yaml:"https-proxy" json:"https-proxy"`
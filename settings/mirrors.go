@@ -0,0 +1,155 @@
+package settings
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pathlib"
+)
+
+const (
+	mirrorQuarantine = 10 * time.Minute
+	mirrorTimeout    = 5 * time.Second
+)
+
+var (
+	mirrorLock   sync.Mutex
+	mirrorChosen = make(map[string]string)
+	mirrorProbe  = probeMirror
+)
+
+// mirrorHealth remembers when each mirror URL last failed, so that dead
+// primary endpoint is tried last, instead of adding timeouts to every rcc
+// operation during quarantine period.
+type mirrorHealth map[string]int64
+
+func loadMirrorHealth() mirrorHealth {
+	result := make(mirrorHealth)
+	content, err := os.ReadFile(common.MirrorHealthLocation())
+	if err != nil {
+		return result
+	}
+	err = json.Unmarshal(content, &result)
+	if err != nil {
+		common.Debug("Ignoring broken mirror health file, reason: %v", err)
+		return make(mirrorHealth)
+	}
+	return result
+}
+
+func (it mirrorHealth) save() {
+	content, err := json.MarshalIndent(it, "", "  ")
+	if err == nil {
+		err = pathlib.WriteFile(common.MirrorHealthLocation(), content, 0o644)
+	}
+	if err != nil {
+		common.Debug("Could not save mirror health, reason: %v", err)
+	}
+}
+
+func (it mirrorHealth) quarantined(link string) bool {
+	failed, ok := it[link]
+	if !ok {
+		return false
+	}
+	return time.Since(time.Unix(failed, 0)) < mirrorQuarantine
+}
+
+func probeMirror(link string) error {
+	client := &http.Client{
+		Transport: Global.ConfiguredHttpTransport(),
+		Timeout:   mirrorTimeout,
+	}
+	response, err := client.Head(link)
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	if response.StatusCode >= 500 {
+		return fmt.Errorf("Mirror responded with status %q.", response.Status)
+	}
+	return nil
+}
+
+func forgetChosenMirrors() {
+	mirrorLock.Lock()
+	defer mirrorLock.Unlock()
+	mirrorChosen = make(map[string]string)
+}
+
+// MarkMirror records result of using mirror URL, so that next rcc runs
+// can order mirror chains based on it.
+func MarkMirror(link string, healthy bool) {
+	mirrorLock.Lock()
+	defer mirrorLock.Unlock()
+	health := loadMirrorHealth()
+	_, known := health[link]
+	if healthy && !known {
+		return
+	}
+	if healthy {
+		delete(health, link)
+	} else {
+		health[link] = time.Now().Unix()
+	}
+	health.save()
+}
+
+// MirrorChain returns endpoint and its configured mirrors (mirrors: section
+// of settings.yaml) in order they should be tried. Order is configuration
+// order, except that recently failed URLs are moved to end of chain.
+func (it gateway) MirrorChain(key string) []string {
+	candidates := make([]string, 0, len(it.settings().Mirrors[key])+1)
+	seen := make(map[string]bool)
+	primary := it.Endpoint(key)
+	for _, link := range append([]string{primary}, it.settings().Mirrors[key]...) {
+		if len(link) == 0 || seen[link] {
+			continue
+		}
+		seen[link] = true
+		candidates = append(candidates, link)
+	}
+	health := loadMirrorHealth()
+	sort.SliceStable(candidates, func(left, right int) bool {
+		return !health.quarantined(candidates[left]) && health.quarantined(candidates[right])
+	})
+	return candidates
+}
+
+// healthyEndpoint selects first working URL from mirror chain of endpoint.
+// Without mirrors, or when offline, configured endpoint is used as is. Choice
+// is remembered for rest of the process.
+func (it gateway) healthyEndpoint(key string) string {
+	chain := it.MirrorChain(key)
+	if len(chain) < 2 || it.Offline() {
+		return it.Endpoint(key)
+	}
+	mirrorLock.Lock()
+	chosen, ok := mirrorChosen[key]
+	mirrorLock.Unlock()
+	if ok {
+		return chosen
+	}
+	chosen = chain[0]
+	for _, link := range chain {
+		err := mirrorProbe(link)
+		if err == nil {
+			chosen = link
+			MarkMirror(link, true)
+			break
+		}
+		common.Debug("Mirror %q of %q endpoint is not healthy, reason: %v", link, key, err)
+		MarkMirror(link, false)
+	}
+	common.Trace("Using %q as %q endpoint.", chosen, key)
+	mirrorLock.Lock()
+	mirrorChosen[key] = chosen
+	mirrorLock.Unlock()
+	return chosen
+}
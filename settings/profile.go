@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/joshyorko/rcc/common"
@@ -12,6 +13,33 @@ import (
 	"gopkg.in/yaml.v2"
 )
 
+// ProfileFilename is location of imported profile with given name.
+func ProfileFilename(name string) string {
+	basename := fmt.Sprintf("profile_%s.yaml", strings.ToLower(name))
+	return common.ExpandPath(filepath.Join(common.Product.Home(), basename))
+}
+
+// ImportedProfiles loads all imported profiles, sorted by name. Profiles that
+// cannot be loaded are ignored.
+func ImportedProfiles() ([]*Profile, error) {
+	pattern := common.ExpandPath(filepath.Join(common.Product.Home(), "profile_*.yaml"))
+	found, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*Profile, 0, len(found))
+	for _, filename := range found {
+		profile := &Profile{}
+		if profile.LoadFrom(filename) == nil {
+			result = append(result, profile)
+		}
+	}
+	sort.Slice(result, func(left, right int) bool {
+		return result[left].Name < result[right].Name
+	})
+	return result, nil
+}
+
 type Profile struct {
 	Name         string    `yaml:"name" json:"name"`
 	Description  string    `yaml:"description" json:"description"`
@@ -46,9 +74,7 @@ func (it *Profile) LoadFrom(filename string) error {
 }
 
 func (it *Profile) Import() (err error) {
-	basename := fmt.Sprintf("profile_%s.yaml", strings.ToLower(it.Name))
-	filename := common.ExpandPath(filepath.Join(common.Product.Home(), basename))
-	return it.SaveAs(filename)
+	return it.SaveAs(ProfileFilename(it.Name))
 }
 
 func (it *Profile) Activate() (err error) {
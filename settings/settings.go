@@ -1,14 +1,17 @@
 package settings
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/joshyorko/rcc/blobs"
 	"github.com/joshyorko/rcc/common"
@@ -46,6 +49,7 @@ func loadEnvOverrides() *Settings {
 		"RCC_ENDPOINT_PYPI_TRUSTED":  "pypi-trusted",
 		"RCC_ENDPOINT_CONDA":         "conda",
 		"RCC_ENDPOINT_UV_RELEASES":   "uv-releases",
+		"RCC_ENDPOINT_REGISTRY":      "registry",
 	}
 
 	// Mapping of env var -> autoupdates key in settings
@@ -226,7 +230,7 @@ func (it gateway) TelemetryURL() string {
 }
 
 func (it gateway) PypiURL() string {
-	return it.Endpoint("pypi")
+	return it.healthyEndpoint("pypi")
 }
 
 func (it gateway) PypiTrustedHost() string {
@@ -234,7 +238,7 @@ func (it gateway) PypiTrustedHost() string {
 }
 
 func (it gateway) CondaURL() string {
-	return it.Endpoint("conda")
+	return it.healthyEndpoint("conda")
 }
 
 func (it gateway) UvReleasesURL() string {
@@ -269,7 +273,7 @@ func (it gateway) HasCaBundle() bool {
 }
 
 func (it gateway) DownloadsLink(resource string) string {
-	return resolveLink(it.Endpoint("downloads"), resource)
+	return resolveLink(it.healthyEndpoint("downloads"), resource)
 }
 
 func (it gateway) DocsLink(page string) string {
@@ -277,7 +281,7 @@ func (it gateway) DocsLink(page string) string {
 }
 
 func (it gateway) PypiLink(page string) string {
-	endpoint := it.Endpoint("pypi")
+	endpoint := it.healthyEndpoint("pypi")
 	if len(endpoint) == 0 {
 		endpoint = pypiDefault
 	}
@@ -285,7 +289,7 @@ func (it gateway) PypiLink(page string) string {
 }
 
 func (it gateway) CondaLink(page string) string {
-	endpoint := it.Endpoint("conda")
+	endpoint := it.healthyEndpoint("conda")
 	if len(endpoint) == 0 {
 		endpoint = condaDefault
 	}
@@ -295,6 +299,11 @@ func (it gateway) CondaLink(page string) string {
 func (it gateway) Hostnames() []string {
 	return it.settings().Hostnames()
 }
+
+func (it gateway) DeniedLicenses() []string {
+	return it.settings().Licenses
+}
+
 func (it gateway) VerifySsl() bool {
 	return it.settings().Certificates.VerifySsl
 }
@@ -312,8 +321,46 @@ func (it gateway) NoBuild() bool {
 	return nobuild || common.NoBuild || it.Option("no-build")
 }
 
+func (it gateway) Offline() bool {
+	offline := len(os.Getenv("RCC_OFFLINE")) > 0
+	return offline || common.Offline
+}
+
+func OfflineError(action string) error {
+	return fmt.Errorf("Cannot %s, offline mode is active (--offline or RCC_OFFLINE).", action)
+}
+
+func offlineDialer(ctx context.Context, network, address string) (net.Conn, error) {
+	return nil, OfflineError(fmt.Sprintf("connect to %q", address))
+}
+
+func (it gateway) Blake3Digests() bool {
+	return it.Option("blake3-digests")
+}
+
+// Notifications returns completion notification policy: minimum duration
+// of run, and are desktop notifications and/or terminal bell enabled.
+func (it gateway) Notifications() (time.Duration, bool, bool) {
+	notify := it.settings().Notify
+	if notify == nil {
+		return 0, false, false
+	}
+	return time.Duration(notify.After) * time.Second, notify.Desktop, notify.Bell
+}
+
+func (it gateway) NoConfirm() bool {
+	noconfirm := len(os.Getenv("RCC_NO_CONFIRM")) > 0
+	return noconfirm || it.Option("no-confirm")
+}
+
 func (it gateway) ConfiguredHttpTransport() *http.Transport {
-	return httpTransport.Clone()
+	transport := httpTransport.Clone()
+	if it.Offline() {
+		transport.Proxy = nil
+		transport.DialContext = offlineDialer
+		transport.DialTLSContext = offlineDialer
+	}
+	return transport
 }
 
 func (it gateway) loadRootCAs() *x509.CertPool {
@@ -349,16 +396,29 @@ func initProtection() {
 	}
 }
 
+// Reload rereads all settings layers and reconfigures http transport, so
+// that profile switch takes effect without restarting rcc.
+func Reload() {
+	cachedSettings = nil
+	forgetChosenMirrors()
+	configure()
+}
+
 func init() {
 	defer initProtection()
 
+	Global = gateway(true)
+	common.ErrorDocsLink = Global.DocsLink
+	configure()
+}
+
+func configure() {
 	chain = SettingsLayers{
 		DefaultSettingsLayer(),
 		CustomSettingsLayer(),
 		loadEnvOverrides(),
 	}
 	verifySsl := true
-	Global = gateway(true)
 	httpTransport = http.DefaultTransport.(*http.Transport).Clone()
 	settings, err := SummonSettings()
 	if err == nil && settings.Certificates != nil {
@@ -383,4 +443,13 @@ func init() {
 		InsecureSkipVerify: !verifySsl,
 		RootCAs:            Global.loadRootCAs(),
 	}
+	certificate, key, ok := common.RccRemoteClientCertificate()
+	if ok {
+		pair, err := tls.LoadX509KeyPair(certificate, key)
+		if err != nil {
+			common.Log("Warning! Problem loading client certificate %q, reason: %v.", certificate, err)
+		} else {
+			httpTransport.TLSClientConfig.Certificates = []tls.Certificate{pair}
+		}
+	}
 }
@@ -1,6 +1,11 @@
 package settings_test
 
 import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/joshyorko/rcc/hamlet"
@@ -40,3 +45,65 @@ func TestThatSomeDefaultValuesAreVisible(t *testing.T) {
 	must_be.Equal("", settings.Global.NoProxy())
 	must_be.Equal(4, len(settings.Global.Hostnames()))
 }
+
+func TestImportedProfilesAreListedAndActivated(t *testing.T) {
+	must_be, wont_be := hamlet.Specifications(t)
+
+	t.Setenv("ROBOCORP_HOME", t.TempDir())
+	profiles, err := settings.ImportedProfiles()
+	must_be.Nil(err)
+	must_be.Equal(0, len(profiles))
+
+	for _, name := range []string{"Zulu", "Alpha"} {
+		profile := &settings.Profile{Name: name, Description: name + " profile"}
+		must_be.Nil(profile.Import())
+	}
+	profiles, err = settings.ImportedProfiles()
+	must_be.Nil(err)
+	must_be.Equal(2, len(profiles))
+	must_be.Equal("Alpha", profiles[0].Name)
+	must_be.Equal("Zulu", profiles[1].Name)
+	wont_be.Equal(settings.ProfileFilename("Alpha"), settings.ProfileFilename("Zulu"))
+
+	settings.Reload()
+	sut, err := settings.SummonSettings()
+	must_be.Nil(err)
+	wont_be.Nil(sut)
+}
+
+func TestMirrorChainSkipsDeadPrimary(t *testing.T) {
+	must_be, wont_be := hamlet.Specifications(t)
+
+	live := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		response.WriteHeader(http.StatusOK)
+	}))
+	defer live.Close()
+	dead := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		response.WriteHeader(http.StatusBadGateway)
+	}))
+	defer dead.Close()
+
+	home := t.TempDir()
+	t.Setenv("ROBOCORP_HOME", home)
+	t.Setenv("RCC_ENDPOINT_PYPI", "")
+	custom := fmt.Sprintf("endpoints:\n  pypi: %s/\nmirrors:\n  pypi:\n  - %s/\n  - %s/\n", dead.URL, dead.URL, live.URL)
+	must_be.Nil(os.WriteFile(filepath.Join(home, "settings.yaml"), []byte(custom), 0o644))
+	settings.Reload()
+	defer settings.Reload()
+
+	chain := settings.Global.MirrorChain("pypi")
+	must_be.Equal(2, len(chain))
+	must_be.Equal(dead.URL+"/", chain[0])
+
+	must_be.Equal(live.URL+"/", settings.Global.PypiURL())
+	must_be.Equal(live.URL+"/simple/", settings.Global.PypiLink("simple/"))
+
+	chain = settings.Global.MirrorChain("pypi")
+	must_be.Equal(live.URL+"/", chain[0])
+	must_be.Equal(dead.URL+"/", chain[1])
+
+	settings.MarkMirror(dead.URL+"/", true)
+	chain = settings.Global.MirrorChain("pypi")
+	must_be.Equal(dead.URL+"/", chain[0])
+	wont_be.Equal("", settings.Global.CondaLink(""))
+}
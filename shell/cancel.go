@@ -0,0 +1,57 @@
+package shell
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+
+	"github.com/joshyorko/rcc/pretty"
+)
+
+var (
+	ErrCancelled = errors.New("operation was cancelled by interrupt")
+
+	cancelContext, cancelAll = context.WithCancel(context.Background())
+)
+
+// Cancel stops all running and future child processes started by tasks,
+// first with interrupt signal, and after grace period by killing them.
+func Cancel() {
+	cancelAll()
+}
+
+// Cancelled tells if Cancel has been called (for example by interrupt).
+func Cancelled() bool {
+	return cancelContext.Err() != nil
+}
+
+func interruptProcess(process *os.Process) error {
+	err := process.Signal(os.Interrupt)
+	if err != nil {
+		return process.Kill()
+	}
+	return nil
+}
+
+// CancelOnInterrupt makes first interrupt signal (Ctrl-C) cancel child
+// processes and mark operation cancelled, so that caller can clean up after
+// itself. Second interrupt is not caught. Returned function stops watching.
+func CancelOnInterrupt(operation string) func() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	stopped := make(chan bool)
+	go func() {
+		select {
+		case got := <-signals:
+			signal.Stop(signals)
+			pretty.Warning("Detected %q signal, cancelling %s. Second one will not be caught. [rcc]", got, operation)
+			Cancel()
+		case <-stopped:
+		}
+	}()
+	return func() {
+		signal.Stop(signals)
+		close(stopped)
+	}
+}
@@ -0,0 +1,46 @@
+package shell_test
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/joshyorko/rcc/conda"
+	"github.com/joshyorko/rcc/hamlet"
+	"github.com/joshyorko/rcc/shell"
+)
+
+func TestCancelStopsRunningAndFutureTasks(t *testing.T) {
+	if conda.IsWindows() {
+		t.Skip("Not a windows test.")
+	}
+
+	must_be, wont_be := hamlet.Specifications(t)
+
+	// cancellation is process wide, so it is tested in separate process
+	if len(os.Getenv("RCC_CANCEL_TEST")) == 0 {
+		command := exec.Command(os.Args[0], "-test.run", "^TestCancelStopsRunningAndFutureTasks$")
+		command.Env = append(os.Environ(), "RCC_CANCEL_TEST=yes")
+		output, err := command.CombinedOutput()
+		must_be.Nil(err)
+		must_be.True(len(output) > 0)
+		return
+	}
+
+	wont_be.True(shell.Cancelled())
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		shell.Cancel()
+	}()
+	started := time.Now()
+	code, err := shell.New(nil, ".", "sleep", "30").Transparent()
+	wont_be.Nil(err)
+	wont_be.Equal(0, code)
+	must_be.True(time.Since(started) < 10*time.Second)
+	must_be.True(shell.Cancelled())
+
+	code, err = shell.New(nil, ".", "echo", "hello").Transparent()
+	must_be.Equal(shell.ErrCancelled, err)
+	must_be.Equal(-500, code)
+}
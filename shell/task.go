@@ -7,6 +7,7 @@ import (
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"syscall"
 	"time"
 
 	"github.com/google/shlex"
@@ -29,6 +30,8 @@ type (
 		args        []string
 		stderronly  bool
 		nostderr    bool
+		attributes  *syscall.SysProcAttr
+		started     func(pid int)
 	}
 
 	Wrapper func()
@@ -60,6 +63,20 @@ func (it *Task) NoStderr() *Task {
 	return it
 }
 
+// WithAttributes sets operating system specific attributes (like namespaces)
+// for started process.
+func (it *Task) WithAttributes(attributes *syscall.SysProcAttr) *Task {
+	it.attributes = attributes
+	return it
+}
+
+// WithStarted sets callback, which gets process id of started process,
+// before it is waited for.
+func (it *Task) WithStarted(started func(pid int)) *Task {
+	it.started = started
+	return it
+}
+
 func (it *Task) stdout() io.Writer {
 	if it.stderronly {
 		return os.Stderr
@@ -69,9 +86,16 @@ func (it *Task) stdout() io.Writer {
 
 func (it *Task) execute(stdin io.Reader, stdout, stderr io.Writer) (int, error) {
 	common.Trace("Execute %q with arguments %q", it.executable, it.args)
-	command := exec.Command(it.executable, it.args...)
+	if Cancelled() {
+		return -500, ErrCancelled
+	}
+	command := exec.CommandContext(cancelContext, it.executable, it.args...)
+	command.Cancel = func() error {
+		return interruptProcess(command.Process)
+	}
 	command.Env = it.environment
 	command.Dir = it.directory
+	command.SysProcAttr = it.attributes
 	command.Stdin = stdin
 	command.Stdout = stdout
 	if it.nostderr {
@@ -86,6 +110,9 @@ func (it *Task) execute(stdin io.Reader, stdout, stderr io.Writer) (int, error)
 	}
 	common.Timeline("exec %q started", it.executable)
 	common.Debug("PID #%d is %q.", command.Process.Pid, command)
+	if it.started != nil {
+		it.started(command.Process.Pid)
+	}
 	defer func() {
 		if command.ProcessState.ExitCode() != 0 {
 			common.Log("Process %d: %v, command: %s %s [%s/%d]", command.Process.Pid, command.ProcessState, it.executable, it.args, common.Version, os.Getpid())
@@ -158,6 +185,10 @@ func (it *Task) Tracked(sink io.Writer, interactive bool) (int, error) {
 	return it.execute(stdin, sink, sink)
 }
 
+func (it *Task) Feed(input []byte) (int, error) {
+	return it.execute(bytes.NewReader(input), io.Discard, io.Discard)
+}
+
 func (it *Task) CaptureOutput() (string, int, error) {
 	stdin := bytes.NewReader([]byte{})
 	stdout := bytes.NewBuffer(nil)
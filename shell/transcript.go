@@ -0,0 +1,104 @@
+package shell
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	TranscriptStdin  = "stdin"
+	TranscriptStdout = "stdout"
+	TranscriptStderr = "stderr"
+)
+
+type (
+	TranscriptEntry struct {
+		At     float64 `json:"at"`
+		Stream string  `json:"stream"`
+		Data   string  `json:"data"`
+	}
+
+	Transcript struct {
+		sync.Mutex
+		encoder *json.Encoder
+		started time.Time
+	}
+
+	transcriptStream struct {
+		transcript *Transcript
+		stream     string
+	}
+)
+
+func NewTranscript(sink io.Writer) *Transcript {
+	return &Transcript{
+		encoder: json.NewEncoder(sink),
+		started: time.Now(),
+	}
+}
+
+func (it *Transcript) Stream(stream string) io.Writer {
+	return &transcriptStream{
+		transcript: it,
+		stream:     stream,
+	}
+}
+
+func (it *Transcript) record(stream string, blob []byte) error {
+	it.Lock()
+	defer it.Unlock()
+
+	return it.encoder.Encode(&TranscriptEntry{
+		At:     time.Since(it.started).Seconds(),
+		Stream: stream,
+		Data:   string(blob),
+	})
+}
+
+func (it *transcriptStream) Write(blob []byte) (int, error) {
+	err := it.transcript.record(it.stream, blob)
+	if err != nil {
+		return 0, err
+	}
+	return len(blob), nil
+}
+
+// Recorded runs task and records its streams into transcript. Interactive
+// tasks get real terminal where platform allows it, and then terminal output
+// (both stdout and stderr) is recorded as stdout stream.
+func (it *Task) Recorded(transcript *Transcript, interactive bool) (int, error) {
+	stdout := io.MultiWriter(it.stdout(), transcript.Stream(TranscriptStdout))
+	stderr := io.MultiWriter(os.Stderr, transcript.Stream(TranscriptStderr))
+	if !interactive {
+		return it.execute(bytes.NewReader([]byte{}), stdout, stderr)
+	}
+	return it.recordedInteractive(transcript, stdout, stderr)
+}
+
+func Replay(source io.Reader, stdout, stderr io.Writer, speed float64) error {
+	scanner := bufio.NewScanner(source)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	previous := 0.0
+	for scanner.Scan() {
+		entry := &TranscriptEntry{}
+		err := json.Unmarshal(scanner.Bytes(), entry)
+		if err != nil {
+			return err
+		}
+		if speed > 0 && entry.At > previous {
+			time.Sleep(time.Duration((entry.At - previous) / speed * float64(time.Second)))
+		}
+		previous = entry.At
+		if entry.Stream == TranscriptStderr {
+			io.WriteString(stderr, entry.Data)
+		} else {
+			io.WriteString(stdout, entry.Data)
+		}
+	}
+	return scanner.Err()
+}
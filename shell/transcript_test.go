@@ -0,0 +1,34 @@
+package shell_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/joshyorko/rcc/conda"
+	"github.com/joshyorko/rcc/hamlet"
+	"github.com/joshyorko/rcc/shell"
+)
+
+func TestCanRecordAndReplayTranscript(t *testing.T) {
+	if conda.IsWindows() {
+		t.Skip("Not a windows test.")
+	}
+
+	must_be, wont_be := hamlet.Specifications(t)
+
+	recording := bytes.NewBuffer(nil)
+	transcript := shell.NewTranscript(recording)
+	code, err := shell.New(nil, ".", "sh", "-c", "echo out; echo err 1>&2").Recorded(transcript, false)
+	must_be.Nil(err)
+	must_be.Equal(0, code)
+	must_be.Equal(2, strings.Count(recording.String(), "\n"))
+	wont_be.True(strings.Contains(recording.String(), `"stream":"stdin"`))
+
+	stdout, stderr := bytes.NewBuffer(nil), bytes.NewBuffer(nil)
+	must_be.Nil(shell.Replay(recording, stdout, stderr, 0))
+	must_be.Equal("out\n", stdout.String())
+	must_be.Equal("err\n", stderr.String())
+
+	wont_be.Nil(shell.Replay(strings.NewReader("not json\n"), stdout, stderr, 0))
+}
@@ -0,0 +1,132 @@
+//go:build darwin || linux || !windows
+// +build darwin linux !windows
+
+package shell
+
+import (
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/creack/pty"
+	"golang.org/x/term"
+)
+
+func (it *Task) recordedInteractive(transcript *Transcript, stdout, stderr io.Writer) (int, error) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		return it.recordedTerminal(transcript)
+	}
+	// own pipe, so that process exit does not wait for pending stdin reads
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return -603, err
+	}
+	stop, err := copyStdin(writer, transcript, func() { writer.Close() })
+	if err != nil {
+		reader.Close()
+		writer.Close()
+		return -604, err
+	}
+	code, err := it.execute(reader, stdout, stderr)
+	reader.Close()
+	stop()
+	return code, err
+}
+
+// recordedTerminal runs task attached to pseudo terminal, so that process
+// sees a terminal as its stdin, stdout, and stderr, and everything passing
+// thru that terminal gets recorded.
+func (it *Task) recordedTerminal(transcript *Transcript) (int, error) {
+	master, slave, err := pty.Open()
+	if err != nil {
+		return -603, err
+	}
+	defer master.Close()
+	pty.InheritSize(os.Stdin, master)
+	resized := make(chan os.Signal, 1)
+	defer close(resized)
+	defer signal.Stop(resized)
+	signal.Notify(resized, syscall.SIGWINCH)
+	go func() {
+		for range resized {
+			pty.InheritSize(os.Stdin, master)
+		}
+	}()
+
+	state, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		slave.Close()
+		return -604, err
+	}
+	defer term.Restore(int(os.Stdin.Fd()), state)
+
+	drained := make(chan bool)
+	go func() {
+		defer close(drained)
+		io.Copy(io.MultiWriter(it.stdout(), transcript.Stream(TranscriptStdout)), master)
+	}()
+	stop, err := copyStdin(master, transcript, func() {})
+	if err != nil {
+		slave.Close()
+		return -604, err
+	}
+
+	attributes := &syscall.SysProcAttr{}
+	if it.attributes != nil {
+		*attributes = *it.attributes
+	}
+	attributes.Setsid = true
+	attributes.Setctty = true
+	attributes.Ctty = 0
+	terminal := *it
+	terminal.attributes = attributes
+	terminal.started = func(pid int) {
+		// only child keeps terminal open, so output ends when it exits
+		slave.Close()
+		if it.started != nil {
+			it.started(pid)
+		}
+	}
+	code, err := terminal.execute(slave, slave, slave)
+	slave.Close()
+
+	// background processes may still hold terminal, so do not wait forever
+	select {
+	case <-drained:
+	case <-time.After(3 * time.Second):
+	}
+	master.Close()
+	<-drained
+	stop()
+	return code, err
+}
+
+// copyStdin copies stdin to target (and transcript) until stdin ends or
+// returned stop function is called, and then calls finished. Stdin is read thru nonblocking duplicate, so that
+// pending read can be interrupted when process has exited.
+func copyStdin(target io.Writer, transcript *Transcript, finished func()) (func(), error) {
+	original := int(os.Stdin.Fd())
+	duplicate, err := syscall.Dup(original)
+	if err != nil {
+		return nil, err
+	}
+	err = syscall.SetNonblock(duplicate, true)
+	if err != nil {
+		syscall.Close(duplicate)
+		return nil, err
+	}
+	input := os.NewFile(uintptr(duplicate), "stdin")
+	copied := make(chan bool)
+	go func() {
+		defer close(copied)
+		defer finished()
+		io.Copy(io.MultiWriter(target, transcript.Stream(TranscriptStdin)), input)
+	}()
+	return func() {
+		input.Close()
+		<-copied
+		syscall.SetNonblock(original, false)
+	}, nil
+}
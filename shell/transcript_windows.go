@@ -0,0 +1,16 @@
+//go:build windows
+// +build windows
+
+package shell
+
+import (
+	"io"
+	"os"
+)
+
+// On Windows, console is passed to process as is, since pending console
+// reads cannot be interrupted when process exits. Therefore stdin is not
+// recorded there.
+func (it *Task) recordedInteractive(transcript *Transcript, stdout, stderr io.Writer) (int, error) {
+	return it.execute(os.Stdin, stdout, stderr)
+}
@@ -0,0 +1,64 @@
+package wizard
+
+import (
+	"fmt"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/operations"
+)
+
+const (
+	quickActionsExample = `actions:
+  - key: r
+    name: rebuild env for robot X
+    command: holotree vars --robot /path/to/robotX/robot.yaml --force
+  - key: p
+    name: pull from remote
+    command: holotree pull --robot /path/to/robotX/robot.yaml
+  - key: c
+    name: clean idle spaces
+    command: holotree delete --unused 30`
+)
+
+// QuickActions shows user defined quick actions bar, and runs one selected
+// with a key (or one given as argument).
+func QuickActions(arguments []string) error {
+	common.Stdout("\n")
+
+	actions, err := operations.LoadQuickActions()
+	if err != nil {
+		return fmt.Errorf("Could not load quick actions from %q, reason: %v", common.QuickActionsLocation(), err)
+	}
+	if len(actions) == 0 {
+		note("No quick actions defined. Add them into %s, like:", common.QuickActionsLocation())
+		common.Stdout("\n%s\n\n", quickActionsExample)
+		return nil
+	}
+	key := firstOf(arguments, "")
+	if len(key) == 0 {
+		operations.ShowQuickActions(actions)
+		keys := []string{""}
+		for _, action := range actions {
+			keys = append(keys, action.Key)
+		}
+		key, err = ask("Key of quick action to run (empty to quit)", "", memberValidation(keys, "Unknown quick action key."))
+		if err != nil {
+			return err
+		}
+		if len(key) == 0 {
+			return nil
+		}
+	}
+	action, ok := operations.FindQuickAction(actions, key)
+	if !ok {
+		return fmt.Errorf("There is no quick action with key %q.", key)
+	}
+	operations.Yankable("command", fmt.Sprintf("rcc %s", action.Command))
+	note("Running quick action %q: rcc %s", action.Name, action.Command)
+	common.Stdout("\n")
+	code, err := operations.RunQuickAction(action)
+	if err != nil {
+		return fmt.Errorf("Quick action %q failed with exit code %d, reason: %v", action.Name, code, err)
+	}
+	return nil
+}
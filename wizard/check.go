@@ -0,0 +1,88 @@
+package wizard
+
+import (
+	"fmt"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/htfs"
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pretty"
+)
+
+const (
+	repairAction = "repair from remote origin"
+	deleteAction = "delete catalog (rebuilt on next use)"
+	skipAction   = "skip, leave as is"
+)
+
+func checkProgress(at, total int, health *operations.CatalogHealth) {
+	color := pretty.Green
+	if health.Broken() {
+		color = pretty.Red
+	}
+	common.Stdout("%s[%d/%d]%s %s %s%s%s\n", pretty.Grey, at, total, pretty.White, health.Catalog, color, health.Summary(), pretty.Reset)
+}
+
+func Check(arguments []string) error {
+	common.Stdout("\n")
+
+	catalogs := htfs.AllCatalogNames()
+	if len(catalogs) == 0 {
+		return fmt.Errorf("There are no catalogs in hololib to check.")
+	}
+	if len(arguments) > 0 {
+		selected, err := chooseMany("Choose catalogs to check", "Catalogs", catalogs, arguments)
+		if err != nil {
+			return err
+		}
+		catalogs = selected
+	}
+
+	note("Checking %d catalogs, this may take a while.", len(catalogs))
+	results := operations.CheckCatalogHealth(catalogs, checkProgress)
+
+	broken := []*operations.CatalogHealth{}
+	for _, health := range results {
+		if health.Broken() {
+			broken = append(broken, health)
+		}
+	}
+	common.Stdout("\n%sResults:%s %d catalogs checked, %d broken.\n\n", pretty.Grey, pretty.Reset, len(results), len(broken))
+	if len(broken) == 0 {
+		return nil
+	}
+
+	actions := []string{deleteAction, skipAction}
+	if len(common.RccRemoteOrigin()) > 0 {
+		actions = append([]string{repairAction}, actions...)
+	}
+	for _, health := range broken {
+		common.Stdout("%s%s%s: %s%s%s\n", pretty.White, health.Catalog, pretty.Reset, pretty.Red, health.Summary(), pretty.Reset)
+		action, err := choose("Choose action for this catalog", "Actions", actions)
+		if err != nil {
+			return err
+		}
+		switch action {
+		case repairAction:
+			err = operations.RepairCatalog(health)
+		case deleteAction:
+			var ok bool
+			ok, err = confirm(fmt.Sprintf("Really delete catalog %s?", health.Catalog))
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+			err = operations.DeleteCatalog(health)
+		default:
+			continue
+		}
+		if err != nil {
+			pretty.Warning("Action %q on %s failed, reason: %v", action, health.Catalog, err)
+			continue
+		}
+		note("Done: %s on %s.", action, health.Catalog)
+	}
+	return nil
+}
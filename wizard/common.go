@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/operations"
 	"github.com/joshyorko/rcc/pretty"
 )
 
@@ -17,7 +18,8 @@ const (
 )
 
 var (
-	namePattern = regexp.MustCompile("^[\\w-]*$")
+	namePattern   = regexp.MustCompile("^[\\w-]*$")
+	exportPattern = regexp.MustCompile("(?i)^.+\\.(?:md|txt|csv)$")
 )
 
 type Validator func(string) bool
@@ -64,11 +66,22 @@ func note(form string, details ...interface{}) {
 	common.Stdout("%s! %s%s%s\n", pretty.Red, pretty.White, message, pretty.Reset)
 }
 
+// answer reads one line of reply, using line editor when both input and
+// output are interactive terminal.
+func answer(prompt string) (string, error) {
+	if pretty.Interactive && canEditLines() {
+		reply, err := readLine(prompt)
+		return reply + UNIX_NEWLINE, err
+	}
+	common.Stdout("%s", prompt)
+	source := bufio.NewReader(os.Stdin)
+	return source.ReadString(newline)
+}
+
 func ask(question, defaults string, validator Validator) (string, error) {
 	for {
-		common.Stdout("%s? %s%s %s[%s]:%s ", pretty.Green, pretty.White, question, pretty.Grey, defaults, pretty.Reset)
-		source := bufio.NewReader(os.Stdin)
-		reply, err := source.ReadString(newline)
+		prompt := fmt.Sprintf("%s? %s%s %s[%s]:%s ", pretty.Green, pretty.White, question, pretty.Grey, defaults, pretty.Reset)
+		reply, err := answer(prompt)
 		common.Stdout("\n")
 		if err != nil {
 			return "", err
@@ -83,3 +96,26 @@ func ask(question, defaults string, validator Validator) (string, error) {
 		return reply, nil
 	}
 }
+
+// exportView asks file name, and exports current view there as markdown, or
+// as CSV when file name ends with ".csv".
+func exportView(defaults string) {
+	filename, err := ask("Export view into file (.md or .csv)", defaults, regexpValidation(exportPattern, "File name must end with '.md', '.txt', or '.csv'."))
+	if err != nil {
+		note("%v", err)
+		return
+	}
+	err = operations.ExportView(filename)
+	if err != nil {
+		note("%v", err)
+	}
+}
+
+// rememberState stores state of current view, so that it can be resumed in
+// next interactive session.
+func rememberState(flag, value string) {
+	err := operations.RememberViewFlag(flag, value)
+	if err != nil {
+		common.Debug("Could not remember %s of view, reason: %v", flag, err)
+	}
+}
@@ -0,0 +1,150 @@
+package wizard
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/htfs"
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/joshyorko/rcc/set"
+)
+
+const (
+	missingPackage = "-"
+)
+
+func loadSummary(catalog string) (*htfs.EnvironmentSummary, error) {
+	shadow, err := htfs.NewRoot(filepath.Join(common.ProductTemp(), "shadow"))
+	if err != nil {
+		return nil, err
+	}
+	err = shadow.LoadCatalog(filepath.Join(common.HololibCatalogLocation(), catalog))
+	if err != nil {
+		return nil, fmt.Errorf("Could not load catalog %q, reason: %v", catalog, err)
+	}
+	return shadow.Summary()
+}
+
+func orMissing(version string) string {
+	if len(version) == 0 {
+		return missingPackage
+	}
+	return version
+}
+
+func humaneBytes(bytes uint64) string {
+	value, suffix := pathlib.HumaneSizer(int64(bytes))
+	return fmt.Sprintf("%.1f%s", value, suffix)
+}
+
+func sizeDelta(left, right uint64) string {
+	if right >= left {
+		return fmt.Sprintf("+%s", humaneBytes(right-left))
+	}
+	return fmt.Sprintf("-%s", humaneBytes(left-right))
+}
+
+func comparePackages(tabbed *tabwriter.Writer, title string, left, right map[string]string) {
+	deltas := htfs.DiffPackages(left, right)
+	same := len(left)
+	for _, delta := range deltas {
+		if len(delta.Left) > 0 {
+			same -= 1
+		}
+	}
+	fmt.Fprintf(tabbed, "%s%s%s\t%d packages\t%d packages\t%d same, %d different\n", pretty.White, title, pretty.Reset, len(left), len(right), same, len(deltas))
+	for _, delta := range deltas {
+		fmt.Fprintf(tabbed, "  %s\t%s\t%s\t\n", delta.Name, orMissing(delta.Left), orMissing(delta.Right))
+	}
+}
+
+func compareRequested(tabbed *tabwriter.Writer, left, right []string) {
+	leftOnly, rightOnly := htfs.DiffRequested(left, right)
+	fmt.Fprintf(tabbed, "%sidentity.yaml%s\t%d requested\t%d requested\t%d only left, %d only right\n", pretty.White, pretty.Reset, len(left), len(right), len(leftOnly), len(rightOnly))
+	for _, entry := range leftOnly {
+		fmt.Fprintf(tabbed, "  %s\t%s\t%s\t\n", entry, entry, missingPackage)
+	}
+	for _, entry := range rightOnly {
+		fmt.Fprintf(tabbed, "  %s\t%s\t%s\t\n", entry, missingPackage, entry)
+	}
+}
+
+func showComparison(left, right *htfs.EnvironmentSummary) {
+	common.WaitLogs()
+	tabbed := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+	fmt.Fprintf(tabbed, "\t%s\t%s\t\n", left.Blueprint, right.Blueprint)
+	fmt.Fprintf(tabbed, "\t%s\t%s\t\n", "----------------", "----------------")
+	fmt.Fprintf(tabbed, "platform\t%s\t%s\t\n", left.Platform, right.Platform)
+	fmt.Fprintf(tabbed, "python\t%s\t%s\t\n", orMissing(left.Python), orMissing(right.Python))
+	fmt.Fprintf(tabbed, "size\t%s\t%s\t%s\n", humaneBytes(left.Bytes), humaneBytes(right.Bytes), sizeDelta(left.Bytes, right.Bytes))
+	fmt.Fprintf(tabbed, "files\t%d\t%d\t%+d\n", left.Files, right.Files, int64(right.Files)-int64(left.Files))
+	fmt.Fprintf(tabbed, "\t\t\t\n")
+	compareRequested(tabbed, left.Requested, right.Requested)
+	fmt.Fprintf(tabbed, "\t\t\t\n")
+	comparePackages(tabbed, "conda packages", left.Conda, right.Conda)
+	fmt.Fprintf(tabbed, "\t\t\t\n")
+	comparePackages(tabbed, "pip packages", left.Pip, right.Pip)
+	tabbed.Flush()
+}
+
+func remove(candidates []string, unwanted string) []string {
+	result := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		if candidate != unwanted {
+			result = append(result, candidate)
+		}
+	}
+	return result
+}
+
+// Compare lets user select two catalogs (or uses ones given as arguments), and
+// shows their differences side by side.
+func Compare(arguments []string) (err error) {
+	common.Stdout("\n")
+
+	catalogs := htfs.AllCatalogNames()
+	if len(catalogs) < 2 {
+		return fmt.Errorf("There must be at least two catalogs in hololib to compare, now there are %d.", len(catalogs))
+	}
+	left, right := firstOf(arguments, ""), ""
+	if len(arguments) > 1 {
+		right = arguments[1]
+	}
+	if len(left) == 0 {
+		left, err = choose("Choose first catalog to compare", "Catalogs", catalogs)
+		if err != nil {
+			return err
+		}
+	}
+	if len(right) == 0 {
+		right, err = choose("Choose second catalog to compare", "Catalogs", remove(catalogs, left))
+		if err != nil {
+			return err
+		}
+	}
+	for _, catalog := range []string{left, right} {
+		if !set.Member(catalogs, catalog) {
+			return fmt.Errorf("There is no catalog %q in hololib.", catalog)
+		}
+	}
+	operations.Yankable("catalog path", filepath.Join(common.HololibCatalogLocation(), right))
+	note("Comparing %s and %s", left, right)
+	common.Stdout("\n")
+
+	leftSummary, err := loadSummary(left)
+	if err != nil {
+		return err
+	}
+	rightSummary, err := loadSummary(right)
+	if err != nil {
+		return err
+	}
+	showComparison(leftSummary, rightSummary)
+	common.Stdout("\n")
+	return nil
+}
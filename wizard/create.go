@@ -84,6 +84,11 @@ func Create(arguments []string) error {
 		return err
 	}
 
+	err = pickDependencies(fullpath)
+	if err != nil {
+		return err
+	}
+
 	common.Stdout("%s%s%sThe %s%s%s robot has been created to: %s%s%s\n", pretty.Yellow, pretty.Sparkles, pretty.Green, pretty.Cyan, selected, pretty.Green, pretty.Cyan, robotName, pretty.Reset)
 	common.Stdout("\n")
 
@@ -0,0 +1,297 @@
+package wizard
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joshyorko/rcc/cloud"
+	"github.com/joshyorko/rcc/conda"
+	"github.com/joshyorko/rcc/robot"
+	"github.com/joshyorko/rcc/settings"
+)
+
+const (
+	keepTemplatePython = "keep template default"
+	pypiSimpleJson     = "application/vnd.pypi.simple.v1+json"
+	versionsShown      = 10
+)
+
+var (
+	pythonVersions    = []string{"3.12.8", "3.11.11", "3.10.16", "3.9.13"}
+	dependencyLine    = regexp.MustCompile(`^(\s*)-\s+([^\s#:]+)(.*)$`)
+	pipBlockLine      = regexp.MustCompile(`^(\s*)-\s+pip:\s*(?:#.*)?$`)
+	topLevelLine      = regexp.MustCompile(`^[^\s#-]`)
+	prereleasePattern = regexp.MustCompile(`[a-zA-Z]`)
+	pipNamePattern    = regexp.MustCompile(`^(?:[A-Za-z0-9][A-Za-z0-9._-]*)?$`)
+	pipVersionPattern = regexp.MustCompile(`^[0-9][0-9A-Za-z.+!-]*$`)
+	condaSpecPattern  = regexp.MustCompile(`^(?:[A-Za-z0-9][A-Za-z0-9._-]*=[0-9][0-9A-Za-z.*_+-]*)?$`)
+)
+
+func canonicalName(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "-", ".", "-").Replace(name))
+}
+
+func dependencyName(spec string) string {
+	dependency := conda.AsDependency(spec)
+	if dependency == nil {
+		return ""
+	}
+	return canonicalName(dependency.Name)
+}
+
+func compareVersions(left, right string) int {
+	lefties, righties := strings.Split(left, "."), strings.Split(right, ".")
+	for at := range max(len(lefties), len(righties)) {
+		first, second := 0, 0
+		if at < len(lefties) {
+			first, _ = strconv.Atoi(lefties[at])
+		}
+		if at < len(righties) {
+			second, _ = strconv.Atoi(righties[at])
+		}
+		if first != second {
+			return first - second
+		}
+	}
+	return 0
+}
+
+// newestReleases drops pre-releases and returns at most limit newest
+// versions, newest first.
+func newestReleases(versions []string, limit int) []string {
+	result := make([]string, 0, len(versions))
+	for _, version := range versions {
+		if !prereleasePattern.MatchString(version) {
+			result = append(result, version)
+		}
+	}
+	sort.SliceStable(result, func(left, right int) bool {
+		return compareVersions(result[left], result[right]) > 0
+	})
+	return result[:min(len(result), limit)]
+}
+
+// pypiVersions returns at most versionsShown newest released versions of
+// package from configured PyPI endpoint.
+func pypiVersions(endpoint, name string) ([]string, error) {
+	versions, err := pypiReleases(endpoint, name)
+	if err != nil {
+		return nil, err
+	}
+	return versions[:min(len(versions), versionsShown)], nil
+}
+
+// pypiReleases uses JSON form of simple repository API (PEP 691) from
+// configured PyPI endpoint, to get all released versions of package, newest
+// first.
+func pypiReleases(endpoint, name string) ([]string, error) {
+	client, err := cloud.NewClient(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	client = client.WithTimeout(10 * time.Second).Uncritical()
+	request := client.NewRequest(fmt.Sprintf("/%s/", canonicalName(name)))
+	request.Headers["Accept"] = pypiSimpleJson
+	response := client.Get(request)
+	if response.Status != 200 {
+		return nil, fmt.Errorf("Could not get versions of %q from %q, status: %d, reason: %v", name, endpoint, response.Status, response.Err)
+	}
+	listing := struct {
+		Versions []string `json:"versions"`
+	}{}
+	err = json.Unmarshal(response.Body, &listing)
+	if err != nil {
+		return nil, err
+	}
+	return newestReleases(listing.Versions, len(listing.Versions)), nil
+}
+
+type insertion struct {
+	after int
+	lines []string
+}
+
+// editedCondaYaml changes python version and adds (or replaces same named)
+// conda and pip dependencies in conda.yaml content, keeping rest of file
+// (comments, selectors, and ordering) as it was.
+func editedCondaYaml(content, python string, condas, pips []string) string {
+	lines := strings.Split(content, "\n")
+	known := make(map[string]int)
+	section, pipIndent := false, -1
+	sectionAt, lastConda, lastPip, pipBlockAt := -1, -1, -1, -1
+	condaIndent, pipEntryIndent, pipBlockIndent := "  ", "", ""
+	for at, line := range lines {
+		if topLevelLine.MatchString(line) {
+			section, pipIndent = strings.HasPrefix(line, "dependencies:"), -1
+			if section {
+				sectionAt = at
+			}
+			continue
+		}
+		if !section {
+			continue
+		}
+		if found := pipBlockLine.FindStringSubmatch(line); found != nil {
+			pipIndent, pipBlockAt, pipBlockIndent = len(found[1]), at, found[1]
+			continue
+		}
+		found := dependencyLine.FindStringSubmatch(line)
+		if found == nil {
+			continue
+		}
+		if pipIndent >= 0 && len(found[1]) > pipIndent {
+			lastPip, pipEntryIndent = at, found[1]
+			known["pip:"+dependencyName(found[2])] = at
+			continue
+		}
+		pipIndent, lastConda, condaIndent = -1, at, found[1]
+		known["conda:"+dependencyName(found[2])] = at
+	}
+	if sectionAt < 0 {
+		sectionAt = len(lines)
+		if lines[sectionAt-1] == "" {
+			sectionAt -= 1
+		}
+		lines = append(lines[:sectionAt], append([]string{"dependencies:"}, lines[sectionAt:]...)...)
+	}
+	if lastConda < 0 {
+		lastConda = sectionAt
+	}
+	replace := func(at int, spec string) {
+		found := dependencyLine.FindStringSubmatch(lines[at])
+		lines[at] = fmt.Sprintf("%s- %s%s", found[1], spec, found[3])
+	}
+	condaAdded, pipAdded := []string{}, []string{}
+	if len(python) > 0 {
+		condas = append([]string{fmt.Sprintf("python=%s", python)}, condas...)
+	}
+	for _, spec := range condas {
+		if at, ok := known["conda:"+dependencyName(spec)]; ok {
+			replace(at, spec)
+		} else {
+			condaAdded = append(condaAdded, fmt.Sprintf("%s- %s", condaIndent, spec))
+		}
+	}
+	if pipBlockAt < 0 && len(pips) > 0 {
+		pipEntryIndent = condaIndent + "    "
+		condaAdded = append(condaAdded, fmt.Sprintf("%s- pip:", condaIndent))
+	} else if lastPip < 0 {
+		lastPip, pipEntryIndent = pipBlockAt, pipBlockIndent+"    "
+	}
+	for _, spec := range pips {
+		if at, ok := known["pip:"+dependencyName(spec)]; ok {
+			replace(at, spec)
+		} else {
+			pipAdded = append(pipAdded, fmt.Sprintf("%s- %s", pipEntryIndent, spec))
+		}
+	}
+	insertions := []*insertion{{lastConda, condaAdded}}
+	if pipBlockAt < 0 {
+		insertions[0].lines = append(condaAdded, pipAdded...)
+	} else {
+		insertions = append(insertions, &insertion{lastPip, pipAdded})
+	}
+	sort.SliceStable(insertions, func(left, right int) bool {
+		return insertions[left].after > insertions[right].after
+	})
+	for _, entry := range insertions {
+		tail := append(append([]string{}, entry.lines...), lines[entry.after+1:]...)
+		lines = append(lines[:entry.after+1], tail...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func askPipDependencies() ([]string, error) {
+	result := []string{}
+	for {
+		name, err := ask("Add pip package (empty to continue)", "", regexpValidation(pipNamePattern, "Give just package name, like 'requests'."))
+		if err != nil || len(name) == 0 {
+			return result, err
+		}
+		versions, err := pypiVersions(settings.Global.PypiLink(""), name)
+		if err == nil && len(versions) == 0 {
+			err = fmt.Errorf("No released versions of %q found.", name)
+		}
+		if err != nil {
+			note("No version completion, reason: %v", err)
+			version, err := ask(fmt.Sprintf("Give version of %q", name), "", regexpValidation(pipVersionPattern, "Give exact version, like '2.31.0'."))
+			if err != nil {
+				return result, err
+			}
+			result = append(result, fmt.Sprintf("%s==%s", name, version))
+			continue
+		}
+		version, err := choose(fmt.Sprintf("Choose version of %q", name), "Versions", versions)
+		if err != nil {
+			return result, err
+		}
+		result = append(result, fmt.Sprintf("%s==%s", name, version))
+	}
+}
+
+func askCondaDependencies() ([]string, error) {
+	result := []string{}
+	for {
+		spec, err := ask("Add conda package as name=version (empty to continue)", "", regexpValidation(condaSpecPattern, "Give package with exact version, like 'nodejs=22.11.0'."))
+		if err != nil || len(spec) == 0 {
+			return result, err
+		}
+		result = append(result, spec)
+	}
+}
+
+// pickDependencies lets user choose python version and initial dependencies
+// for freshly created robot, and writes them into its conda.yaml.
+func pickDependencies(directory string) error {
+	config, err := robot.LoadRobotYaml(filepath.Join(directory, "robot.yaml"), false)
+	if err != nil {
+		note("Skipping dependency picker, reason: %v", err)
+		return nil
+	}
+	condafile := config.CondaConfigFile()
+	if strings.ToLower(filepath.Base(condafile)) == "package.yaml" {
+		note("Skipping dependency picker, since template uses %q.", condafile)
+		return nil
+	}
+	content, err := os.ReadFile(condafile)
+	if err != nil {
+		return err
+	}
+	wanted, err := confirm("Do you want to choose Python version and add dependencies now?")
+	if err != nil || !wanted {
+		return err
+	}
+	python, err := choose("Choose Python version", "Python", append([]string{keepTemplatePython}, pythonVersions...))
+	if err != nil {
+		return err
+	}
+	if python == keepTemplatePython {
+		python = ""
+	}
+	pips, err := askPipDependencies()
+	if err != nil {
+		return err
+	}
+	condas, err := askCondaDependencies()
+	if err != nil {
+		return err
+	}
+	edited := editedCondaYaml(string(content), python, condas, pips)
+	_, err = conda.CondaYamlFrom([]byte(edited))
+	if err != nil {
+		return fmt.Errorf("Edited %q is not valid, reason: %v", condafile, err)
+	}
+	err = os.WriteFile(condafile, []byte(edited), 0o644)
+	if err != nil {
+		return err
+	}
+	note("Updated %q with %d conda and %d pip dependencies.", condafile, len(condas), len(pips))
+	return nil
+}
@@ -0,0 +1,83 @@
+package wizard
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+const templateCondaYaml = `channels:
+  # Define conda channels here.
+  - conda-forge
+
+dependencies:
+  # Define conda packages here.
+  - python=3.9.13
+
+  - pip=22.1.2
+  - pip:
+    # Define pip packages here.
+    - rpaframework==15.6.0 # https://rpaframework.org/releasenotes.html
+`
+
+func TestCanPickNewestReleases(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	versions := []string{"1.9.0", "2.0.0rc1", "1.10.0", "1.2", "0.9.9", "1.10.1", "2.0.0.dev3"}
+	must.Equal("1.10.1 1.10.0 1.9.0", strings.Join(newestReleases(versions, 3), " "))
+	must.Equal(5, len(newestReleases(versions, 10)))
+	must.True(compareVersions("1.2", "1.2.0") == 0)
+}
+
+func TestCanEditTemplateCondaYaml(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	must.Equal(templateCondaYaml, editedCondaYaml(templateCondaYaml, "", nil, nil))
+
+	edited := editedCondaYaml(templateCondaYaml, "3.12.8", []string{"nodejs=22.11.0"}, []string{"rpaframework==28.6.3", "requests==2.32.3"})
+	expected := `channels:
+  # Define conda channels here.
+  - conda-forge
+
+dependencies:
+  # Define conda packages here.
+  - python=3.12.8
+
+  - pip=22.1.2
+  - nodejs=22.11.0
+  - pip:
+    # Define pip packages here.
+    - rpaframework==28.6.3 # https://rpaframework.org/releasenotes.html
+    - requests==2.32.3
+`
+	must.Equal(expected, edited)
+
+	plain := "channels:\n- conda-forge\ndependencies:\n- python=3.10.16\n"
+	must.Equal("channels:\n- conda-forge\ndependencies:\n- python=3.10.16\n- pip:\n    - robocorp==2.1.0\n", editedCondaYaml(plain, "", nil, []string{"robocorp==2.1.0"}))
+
+	empty := "channels:\n  - conda-forge\n"
+	must.Equal("channels:\n  - conda-forge\ndependencies:\n  - python=3.11.11\n", editedCondaYaml(empty, "3.11.11", nil, nil))
+}
+
+func TestCanCompleteVersionsFromPypi(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		if request.URL.Path != "/robocorp-tasks/" || request.Header.Get("Accept") != pypiSimpleJson {
+			http.NotFound(response, request)
+			return
+		}
+		response.Write([]byte(`{"meta": {"api-version": "1.1"}, "name": "robocorp-tasks", "versions": ["3.0.0", "3.1.1", "4.0.0b1", "3.1.0"]}`))
+	}))
+	defer server.Close()
+
+	versions, err := pypiVersions(server.URL, "Robocorp_Tasks")
+	must.Nil(err)
+	must.Equal("3.1.1 3.1.0 3.0.0", strings.Join(versions, " "))
+
+	_, err = pypiVersions(server.URL, "missing")
+	wont.Nil(err)
+}
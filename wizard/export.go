@@ -0,0 +1,193 @@
+package wizard
+
+import (
+	"compress/flate"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/htfs"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/pretty"
+)
+
+var (
+	selectionPattern = regexp.MustCompile("^(?:all|\\d+(?:\\s*,\\s*\\d+)*)$")
+	zipfilePattern   = regexp.MustCompile("(?i)^.+\\.zip$")
+	yesNo            = []string{"y", "n"}
+	compressions     = map[string]int{
+		"default compression":              flate.DefaultCompression,
+		"fastest, larger archive":          flate.BestSpeed,
+		"best, slower but smaller archive": flate.BestCompression,
+		"no compression at all":            flate.NoCompression,
+	}
+)
+
+func humaneSize(size int64) string {
+	return fmt.Sprintf("%.1fM", float64(size)/(1024.0*1024.0))
+}
+
+func chooseMany(question, label string, candidates, defaults []string) ([]string, error) {
+	common.Stdout("%s%s:%s\n", pretty.Grey, label, pretty.Reset)
+	preselected := []string{}
+	for index, candidate := range candidates {
+		common.Stdout("  %s%2d: %s%s%s\n", pretty.Grey, index+1, pretty.White, candidate, pretty.Reset)
+		for _, filter := range defaults {
+			if strings.Contains(candidate, filter) {
+				preselected = append(preselected, fmt.Sprintf("%d", index+1))
+				break
+			}
+		}
+	}
+	common.Stdout("\n")
+	initial := "1"
+	if len(preselected) > 0 {
+		initial = strings.Join(preselected, ",")
+	}
+	validator := func(input string) bool {
+		if !selectionPattern.MatchString(input) {
+			common.Stdout("%sGive numbers from above list separated by commas, or 'all'.%s\n\n", pretty.Red, pretty.Reset)
+			return false
+		}
+		for _, part := range strings.Split(input, ",") {
+			selected, err := strconv.Atoi(strings.TrimSpace(part))
+			if input != "all" && (err != nil || selected < 1 || selected > len(candidates)) {
+				common.Stdout("%sSelection %q is not in above list.%s\n\n", pretty.Red, part, pretty.Reset)
+				return false
+			}
+		}
+		return true
+	}
+	reply, err := ask(question, initial, validator)
+	if err != nil {
+		return nil, err
+	}
+	if reply == "all" {
+		return candidates, nil
+	}
+	seen := make(map[string]bool)
+	result := []string{}
+	for _, part := range strings.Split(reply, ",") {
+		selected, _ := strconv.Atoi(strings.TrimSpace(part))
+		candidate := candidates[selected-1]
+		if !seen[candidate] {
+			seen[candidate] = true
+			result = append(result, candidate)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+func confirm(question string) (bool, error) {
+	reply, err := ask(question, "n", memberValidation(yesNo, "Answer with 'y' or 'n'."))
+	if err != nil {
+		return false, err
+	}
+	return reply == "y", nil
+}
+
+func Export(arguments []string) error {
+	common.Stdout("\n")
+
+	catalogs := htfs.AllCatalogNames()
+	if len(catalogs) == 0 {
+		return fmt.Errorf("There are no catalogs in hololib to export.")
+	}
+	selected, err := chooseMany("Choose catalogs to export", "Catalogs", catalogs, arguments)
+	if err != nil {
+		return err
+	}
+
+	zipfile, err := ask("Give export zip file", "hololib.zip", regexpValidation(zipfilePattern, "Export file name must end with '.zip'."))
+	if err != nil {
+		return err
+	}
+	fullpath, err := filepath.Abs(zipfile)
+	if err != nil {
+		return err
+	}
+	if pathlib.IsDir(fullpath) {
+		return fmt.Errorf("Destination %q is a directory.", fullpath)
+	}
+	if pathlib.Exists(fullpath) {
+		overwrite, err := confirm(fmt.Sprintf("File %q already exists. Overwrite it?", fullpath))
+		if err != nil {
+			return err
+		}
+		if !overwrite {
+			return fmt.Errorf("Export to %q cancelled.", fullpath)
+		}
+	}
+
+	chosen := make(map[string]bool)
+	for _, catalog := range selected {
+		chosen[catalog] = true
+	}
+	bases := []string{"none, make full export"}
+	for _, catalog := range catalogs {
+		if !chosen[catalog] {
+			bases = append(bases, catalog)
+		}
+	}
+	known := []string{}
+	base, err := choose("Choose delta base (already present on target)", "Delta bases", bases)
+	if err != nil {
+		return err
+	}
+	if base != bases[0] {
+		known = append(known, base)
+	}
+
+	levels := make([]string, 0, len(compressions))
+	for label := range compressions {
+		levels = append(levels, label)
+	}
+	sort.Strings(levels)
+	level, err := choose("Choose compression level", "Compression levels", levels)
+	if err != nil {
+		return err
+	}
+
+	count, size, err := htfs.ExportEstimate(selected, known)
+	if err != nil {
+		return err
+	}
+	note("Export will contain %d catalogs and %d files, about %s before compression.", len(selected), count, humaneSize(size))
+	note("Destination is %q.", fullpath)
+	proceed, err := confirm("Proceed with export?")
+	if err != nil {
+		return err
+	}
+	if !proceed {
+		return fmt.Errorf("Export to %q cancelled.", fullpath)
+	}
+
+	tree, err := htfs.New()
+	if err != nil {
+		return err
+	}
+	meter := pretty.NewMeter("Export", size)
+	htfs.ExportLevel = compressions[level]
+	htfs.ExportProgress = meter
+	defer func() {
+		htfs.ExportProgress = nil
+	}()
+	err = tree.Export(selected, known, fullpath)
+	if err != nil {
+		return err
+	}
+	meter.Done()
+
+	common.Stdout("%s%s%sExported %d catalogs into: %s%s%s\n", pretty.Yellow, pretty.Sparkles, pretty.Green, len(selected), pretty.Cyan, fullpath, pretty.Reset)
+	common.Stdout("\n")
+	common.Stdout("%s%sImport it on target machine with:%s\n", pretty.White, pretty.Rocket, pretty.Reset)
+	common.Stdout("%s$ %srcc holotree import %s%s\n", pretty.Grey, pretty.Cyan, filepath.Base(fullpath), pretty.Reset)
+	common.Stdout("\n")
+
+	return nil
+}
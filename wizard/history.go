@@ -0,0 +1,63 @@
+package wizard
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/journal"
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pretty"
+)
+
+const (
+	historyLimit = 15
+	noRerun      = "quit, do not rerun anything"
+)
+
+func historyLine(record *journal.RunRecord) string {
+	status := fmt.Sprintf("%sexit %d%s", pretty.Green, record.Exitcode, pretty.White)
+	if !record.Success {
+		status = fmt.Sprintf("%sexit %d%s", pretty.Red, record.Exitcode, pretty.White)
+	}
+	when := time.Unix(record.When, 0).Format(time.DateTime)
+	return fmt.Sprintf("%s  %-20s  %s  %7.1fs  space=%s  %s%s", when, record.TaskName(), status, record.Elapsed, record.Space, pretty.Grey, record.Artifacts)
+}
+
+// History shows recent runs of given robot and returns one selected for rerun,
+// or nil if user did not want to rerun anything.
+func History(robotfile string, weeks uint) (*journal.RunRecord, error) {
+	common.Stdout("\n")
+
+	records, err := journal.RunHistory(robotfile, weeks)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("No runs of %q recorded during last %d weeks.", robotfile, weeks)
+	}
+	if len(records) > historyLimit {
+		records = records[:historyLimit]
+	}
+	note("Recent runs of %s", robotfile)
+	lines := make([]string, 0, len(records)+1)
+	selection := make(map[string]*journal.RunRecord)
+	for _, record := range records {
+		line := historyLine(record)
+		if _, ok := selection[line]; ok {
+			continue
+		}
+		selection[line] = record
+		lines = append(lines, line)
+	}
+	lines = append(lines, noRerun)
+	common.Stdout("\n")
+	chosen, err := choose("Choose run to repeat with same task, space, and environment", "Run history (newest first)", lines)
+	if err != nil {
+		return nil, err
+	}
+	if selected, ok := selection[chosen]; ok {
+		operations.Yankable("artifacts path", selected.Artifacts)
+	}
+	return selection[chosen], nil
+}
@@ -0,0 +1,272 @@
+package wizard
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"unicode"
+
+	"golang.org/x/term"
+	"golang.org/x/text/width"
+)
+
+const (
+	keyText      = ""
+	keyEnter     = "enter"
+	keyCancel    = "cancel"
+	keyEOF       = "eof"
+	keyLeft      = "left"
+	keyRight     = "right"
+	keyWordLeft  = "word-left"
+	keyWordRight = "word-right"
+	keyHome      = "home"
+	keyEnd       = "end"
+	keyBackspace = "backspace"
+	keyDelete    = "delete"
+	keyWordBack  = "word-back"
+	keyKillHead  = "kill-head"
+	keyKillTail  = "kill-tail"
+	keyPasteOn   = "paste-on"
+	keyPasteOff  = "paste-off"
+	keyIgnore    = "ignore"
+)
+
+var (
+	errInputCancelled = errors.New("Input cancelled by user.")
+
+	escapeKeys = map[string]string{
+		"[D": keyLeft, "OD": keyLeft,
+		"[C": keyRight, "OC": keyRight,
+		"[H": keyHome, "OH": keyHome, "[1~": keyHome, "[7~": keyHome,
+		"[F": keyEnd, "OF": keyEnd, "[4~": keyEnd, "[8~": keyEnd,
+		"[3~":   keyDelete,
+		"[1;5D": keyWordLeft, "[1;3D": keyWordLeft,
+		"[1;5C": keyWordRight, "[1;3C": keyWordRight,
+		"[200~": keyPasteOn,
+		"[201~": keyPasteOff,
+		"b":     keyWordLeft,
+		"f":     keyWordRight,
+		"\x7f":  keyWordBack,
+	}
+
+	controlKeys = map[rune]string{
+		0x01: keyHome,
+		0x02: keyLeft,
+		0x03: keyCancel,
+		0x04: keyDelete,
+		0x05: keyEnd,
+		0x06: keyRight,
+		0x08: keyBackspace,
+		0x0b: keyKillTail,
+		0x15: keyKillHead,
+		0x17: keyWordBack,
+		0x7f: keyBackspace,
+		'\r': keyEnter,
+		'\n': keyEnter,
+	}
+)
+
+// lineEditor is minimal readline style editor for wizard questions, with
+// cursor movement, home/end, word deletion, bracketed paste, and full
+// unicode support.
+type lineEditor struct {
+	text   []rune
+	cursor int
+	paste  bool
+}
+
+func (it *lineEditor) String() string {
+	return string(it.text)
+}
+
+func (it *lineEditor) insert(letter rune) {
+	if it.paste && (letter == '\r' || letter == '\n') {
+		letter = ' '
+	}
+	if unicode.IsControl(letter) {
+		return
+	}
+	it.text = append(it.text[:it.cursor], append([]rune{letter}, it.text[it.cursor:]...)...)
+	it.cursor++
+}
+
+func (it *lineEditor) wordStart() int {
+	at := it.cursor
+	for at > 0 && unicode.IsSpace(it.text[at-1]) {
+		at--
+	}
+	for at > 0 && !unicode.IsSpace(it.text[at-1]) {
+		at--
+	}
+	return at
+}
+
+func (it *lineEditor) wordEnd() int {
+	at := it.cursor
+	for at < len(it.text) && unicode.IsSpace(it.text[at]) {
+		at++
+	}
+	for at < len(it.text) && !unicode.IsSpace(it.text[at]) {
+		at++
+	}
+	return at
+}
+
+func (it *lineEditor) remove(from, to int) {
+	it.text = append(it.text[:from], it.text[to:]...)
+	it.cursor = from
+}
+
+// apply changes editor state based on key, and returns true when editing
+// is done.
+func (it *lineEditor) apply(key string, letter rune) bool {
+	switch key {
+	case keyText:
+		it.insert(letter)
+	case keyEnter:
+		if it.paste {
+			it.insert(' ')
+			return false
+		}
+		return true
+	case keyLeft:
+		it.cursor = max(0, it.cursor-1)
+	case keyRight:
+		it.cursor = min(len(it.text), it.cursor+1)
+	case keyWordLeft:
+		it.cursor = it.wordStart()
+	case keyWordRight:
+		it.cursor = it.wordEnd()
+	case keyHome:
+		it.cursor = 0
+	case keyEnd:
+		it.cursor = len(it.text)
+	case keyBackspace:
+		if it.cursor > 0 {
+			it.remove(it.cursor-1, it.cursor)
+		}
+	case keyDelete:
+		if it.cursor < len(it.text) {
+			it.remove(it.cursor, it.cursor+1)
+		}
+	case keyWordBack:
+		it.remove(it.wordStart(), it.cursor)
+	case keyKillHead:
+		it.remove(0, it.cursor)
+	case keyKillTail:
+		it.text = it.text[:it.cursor]
+	case keyPasteOn:
+		it.paste = true
+	case keyPasteOff:
+		it.paste = false
+	}
+	return false
+}
+
+// cells returns number of terminal cells needed to show runes.
+func cells(runes []rune) int {
+	total := 0
+	for _, letter := range runes {
+		switch width.LookupRune(letter).Kind() {
+		case width.EastAsianWide, width.EastAsianFullwidth:
+			total += 2
+		default:
+			total++
+		}
+	}
+	return total
+}
+
+func readEscape(source *bufio.Reader) string {
+	first, _, err := source.ReadRune()
+	if err != nil {
+		return keyIgnore
+	}
+	sequence := string(first)
+	if first == '[' || first == 'O' {
+		for {
+			letter, _, err := source.ReadRune()
+			if err != nil {
+				break
+			}
+			sequence += string(letter)
+			if letter >= 0x40 && letter <= 0x7e {
+				break
+			}
+		}
+	}
+	key, ok := escapeKeys[sequence]
+	if !ok {
+		return keyIgnore
+	}
+	return key
+}
+
+func readKey(source *bufio.Reader) (string, rune, error) {
+	letter, _, err := source.ReadRune()
+	if errors.Is(err, io.EOF) {
+		return keyEOF, 0, nil
+	}
+	if err != nil {
+		return "", 0, err
+	}
+	if letter == 0x1b {
+		return readEscape(source), 0, nil
+	}
+	if key, ok := controlKeys[letter]; ok {
+		return key, letter, nil
+	}
+	return keyText, letter, nil
+}
+
+func editLine(source *bufio.Reader, render func(*lineEditor)) (string, error) {
+	editor := &lineEditor{}
+	for {
+		key, letter, err := readKey(source)
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case key == keyCancel:
+			return "", errInputCancelled
+		case key == keyEOF && len(editor.text) == 0:
+			return "", io.EOF
+		case key == keyEOF:
+			return editor.String(), nil
+		case key == keyDelete && letter == 0x04 && len(editor.text) == 0:
+			return "", io.EOF
+		}
+		if editor.apply(key, letter) {
+			return editor.String(), nil
+		}
+		render(editor)
+	}
+}
+
+func canEditLines() bool {
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// readLine reads one answer from terminal in raw mode, using lineEditor.
+func readLine(prompt string) (string, error) {
+	state, err := term.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		return "", err
+	}
+	defer term.Restore(int(os.Stdin.Fd()), state)
+	fmt.Print("\x1b[?2004h")
+	defer fmt.Print("\x1b[?2004l")
+	render := func(editor *lineEditor) {
+		tail := cells(editor.text[editor.cursor:])
+		fmt.Printf("\r\x1b[K%s%s", prompt, editor.String())
+		if tail > 0 {
+			fmt.Printf("\x1b[%dD", tail)
+		}
+	}
+	fmt.Print(prompt)
+	reply, err := editLine(bufio.NewReader(os.Stdin), render)
+	fmt.Print("\r\n")
+	return reply, err
+}
@@ -0,0 +1,66 @@
+package wizard
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func edited(keys string) (string, error) {
+	return editLine(bufio.NewReader(strings.NewReader(keys)), func(*lineEditor) {})
+}
+
+func TestLineEditorMovesCursorAndDeletes(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	reply, err := edited("https://exmple.com\x1b[D\x1b[D\x1b[D\x1b[D\x1b[D\x1b[D\x1b[D\x1b[Da\r")
+	must.Nil(err)
+	must.Equal("https://example.com", reply)
+
+	reply, err = edited("world\x01hello \x05!\r")
+	must.Nil(err)
+	must.Equal("hello world!", reply)
+
+	reply, err = edited("one two three\x17\x17four\r")
+	must.Nil(err)
+	must.Equal("one four", reply)
+
+	reply, err = edited("abc\x1b[H\x1b[3~\x1b[F\x7f\r")
+	must.Nil(err)
+	must.Equal("b", reply)
+
+	reply, err = edited("keep this\x1b[1;5Dgone\x0b\r")
+	must.Nil(err)
+	must.Equal("keep gone", reply)
+
+	reply, err = edited("drop this\x1bbkeep \x15\r")
+	must.Nil(err)
+	must.Equal("this", reply)
+}
+
+func TestLineEditorHandlesUnicodeAndPaste(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	reply, err := edited("päivää 世界\x1b[D\x7f\r")
+	must.Nil(err)
+	must.Equal("päivää 界", reply)
+	must.Equal(4, cells([]rune("世界")))
+	must.Equal(6, cells([]rune("päivää")))
+
+	reply, err = edited("\x1b[200~first\nsecond\x1b[201~\r")
+	must.Nil(err)
+	must.Equal("first second", reply)
+
+	_, err = edited("partial\x03")
+	must.Equal(errInputCancelled, err)
+
+	_, err = edited("")
+	must.Equal(io.EOF, err)
+
+	reply, err = edited("no newline")
+	must.Nil(err)
+	must.Equal("no newline", reply)
+}
@@ -0,0 +1,74 @@
+package wizard
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/joshyorko/rcc/settings"
+)
+
+func findProfile(profiles []*settings.Profile, reply string) (*settings.Profile, bool) {
+	selected, err := strconv.Atoi(reply)
+	if err == nil && selected > 0 && selected <= len(profiles) {
+		return profiles[selected-1], true
+	}
+	for _, profile := range profiles {
+		if profile.Name == reply {
+			return profile, true
+		}
+	}
+	return nil, false
+}
+
+// Profiles lists imported configuration profiles, and switches immediately
+// to one selected (or given as argument). Result tells if profile was
+// switched.
+func Profiles(arguments []string) (bool, error) {
+	common.Stdout("\n")
+
+	profiles, err := settings.ImportedProfiles()
+	if err != nil {
+		return false, err
+	}
+	if len(profiles) == 0 {
+		note("No profiles imported. Import one with: rcc configuration import --filename profile.yaml")
+		return false, nil
+	}
+	reply := firstOf(arguments, "")
+	if len(reply) == 0 {
+		current := settings.Global.Name()
+		common.Stdout("%s%-10s%s active: %s%s%s\n\n", pretty.White, "PROFILES", pretty.Reset, pretty.Cyan, current, pretty.Reset)
+		keys := []string{""}
+		for at, profile := range profiles {
+			marker := " "
+			if profile.Name == current {
+				marker = "*"
+			}
+			common.Stdout("  %s%3d%s %s %-20s %s%s%s\n", pretty.Cyan, at+1, pretty.Reset, marker, profile.Name, pretty.Grey, profile.Description, pretty.Reset)
+			keys = append(keys, fmt.Sprintf("%d", at+1), profile.Name)
+		}
+		common.Stdout("\n")
+		reply, err = ask("Number or name of profile to switch to (empty to quit)", "", memberValidation(keys, "Unknown profile."))
+		if err != nil {
+			return false, err
+		}
+		if len(reply) == 0 {
+			return false, nil
+		}
+	}
+	profile, ok := findProfile(profiles, reply)
+	if !ok {
+		return false, fmt.Errorf("There is no profile %q.", reply)
+	}
+	err = profile.Activate()
+	if err != nil {
+		return false, fmt.Errorf("Could not activate profile %q, reason: %v", profile.Name, err)
+	}
+	settings.Reload()
+	common.RunJournal("profile", "switch", "switched to profile %q", profile.Name)
+	note("Switched to profile %q.", profile.Name)
+	common.Stdout("\n")
+	return true, nil
+}
@@ -0,0 +1,110 @@
+package wizard
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/operations"
+)
+
+// parseSelection parses numbers and ranges like "1,3-5 7" into indexes
+// (zero based) of list with count items.
+func parseSelection(reply string, count int) ([]int, error) {
+	result := []int{}
+	for _, part := range strings.FieldsFunc(reply, func(r rune) bool { return r == ',' || r == ' ' }) {
+		first, last, ranged := strings.Cut(part, "-")
+		low, err := strconv.Atoi(first)
+		if err != nil {
+			return nil, fmt.Errorf("Unknown command %q.", reply)
+		}
+		high := low
+		if ranged {
+			high, err = strconv.Atoi(last)
+			if err != nil {
+				return nil, fmt.Errorf("Unknown command %q.", reply)
+			}
+		}
+		if low < 1 || high > count || low > high {
+			return nil, fmt.Errorf("Selection %q is outside of 1-%d.", part, count)
+		}
+		for number := low; number <= high; number++ {
+			result = append(result, number-1)
+		}
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("Unknown command %q.", reply)
+	}
+	return result, nil
+}
+
+// Pull lists catalogs of this platform in rccremote origin, with estimated
+// download sizes, and pulls only those that user marks.
+func Pull(origin string) error {
+	common.Stdout("\n")
+
+	if len(origin) == 0 {
+		return fmt.Errorf("No remote origin. Give one with --origin, or set %s.", common.RCC_REMOTE_ORIGIN)
+	}
+	catalogs, err := operations.RemoteCatalogs(origin)
+	if err != nil {
+		return fmt.Errorf("Could not list catalogs of %q, reason: %v", origin, err)
+	}
+	operations.EstimateCatalogs(origin, catalogs)
+	selected := make(map[string]bool)
+	for {
+		operations.ShowRemoteCatalogs(origin, catalogs, selected)
+		operations.Exportable("Remote catalogs", operations.RemoteCatalogTable(catalogs))
+		common.Stdout("  numbers/ranges = toggle mark (like 1,3-5), m = mark missing, c = clear marks,\n  p = pull marked, e = export view, empty = quit\n\n")
+		reply, err := ask("Command", "", func(string) bool { return true })
+		if err != nil {
+			return err
+		}
+		switch reply {
+		case "":
+			return nil
+		case "e":
+			exportView("remote-catalogs.md")
+		case "m":
+			for _, catalog := range catalogs {
+				if !catalog.Present {
+					selected[catalog.Name] = true
+				}
+			}
+		case "c":
+			clear(selected)
+		case "p":
+			chosen := []*operations.RemoteCatalog{}
+			for _, catalog := range catalogs {
+				if selected[catalog.Name] {
+					chosen = append(chosen, catalog)
+				}
+			}
+			if len(chosen) == 0 {
+				note("Nothing marked to pull.")
+				continue
+			}
+			_, err = operations.PullSelectedCatalogs(origin, chosen)
+			if err != nil {
+				note("%v", err)
+			}
+			clear(selected)
+			operations.EstimateCatalogs(origin, catalogs)
+		default:
+			indexes, err := parseSelection(reply, len(catalogs))
+			if err != nil {
+				note("%v", err)
+				continue
+			}
+			for _, index := range indexes {
+				name := catalogs[index].Name
+				if selected[name] {
+					delete(selected, name)
+				} else {
+					selected[name] = true
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package wizard
+
+import (
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestCanParseSelectionsWithRanges(t *testing.T) {
+	must, wont := hamlet.Specifications(t)
+
+	indexes, err := parseSelection("1,3-5 7", 8)
+	must.Nil(err)
+	must.Equal([]int{0, 2, 3, 4, 6}, indexes)
+
+	indexes, err = parseSelection("2", 2)
+	must.Nil(err)
+	must.Equal([]int{1}, indexes)
+
+	for _, broken := range []string{"0", "9", "3-2", "x", "1-x", ",", "2-9"} {
+		_, err = parseSelection(broken, 8)
+		wont.Nil(err)
+	}
+}
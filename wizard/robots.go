@@ -0,0 +1,165 @@
+package wizard
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pathlib"
+	"github.com/joshyorko/rcc/pretty"
+)
+
+// pickRobotDirectory lets user jump to one of configured scan roots or
+// recently visited directories, or to any other directory given by path.
+func pickRobotDirectory(current []string) (string, bool) {
+	choices := operations.ConfiguredScanRoots()
+	for _, recent := range operations.RecentRobotDirectories() {
+		if !slices.Contains(choices, recent) {
+			choices = append(choices, recent)
+		}
+	}
+	common.Stdout("%sDIRECTORIES%s scanning: %s%s%s\n\n", pretty.White, pretty.Reset, pretty.Cyan, strings.Join(current, ", "), pretty.Reset)
+	for at, choice := range choices {
+		common.Stdout("  %s%3d%s %s\n", pretty.Cyan, at+1, pretty.Reset, choice)
+	}
+	common.Stdout("\n  number = select directory, path = any other directory, empty = cancel\n\n")
+	reply, err := ask("Directory", "", func(string) bool { return true })
+	if err != nil || len(reply) == 0 {
+		return "", false
+	}
+	selected, err := strconv.Atoi(reply)
+	if err == nil && selected > 0 && selected <= len(choices) {
+		return choices[selected-1], true
+	}
+	if !pathlib.IsDir(reply) {
+		note("Directory %q does not exist.", reply)
+		return "", false
+	}
+	return reply, true
+}
+
+func showEnvironmentDrift(robotfile string) {
+	drift, err := operations.RobotEnvironmentDrift(robotfile)
+	if err != nil {
+		note("Cannot explain stale environment: %v", err)
+		return
+	}
+	common.Stdout("  Changes since last build in %s:\n", drift.Space)
+	for _, entry := range drift.Added {
+		common.Stdout("    %s+ %s%s\n", pretty.Green, entry, pretty.Reset)
+	}
+	for _, entry := range drift.Removed {
+		common.Stdout("    %s- %s%s\n", pretty.Red, entry, pretty.Reset)
+	}
+	if len(drift.Added)+len(drift.Removed) == 0 {
+		common.Stdout("    %srequested dependencies are same, so difference is in channels or other settings%s\n", pretty.Grey, pretty.Reset)
+	}
+	common.Stdout("\n")
+}
+
+// showRobot shows details of one robot, and commands to use it.
+func showRobot(robot *operations.RobotEntry) {
+	note("Robot %q (%s) is at %s", robot.Name, robot.Status, robot.Path)
+	if robot.Status == operations.RobotStale {
+		showEnvironmentDrift(robot.Path)
+	}
+	operations.Yankable("command", fmt.Sprintf("rcc run --robot %q", robot.Path))
+	common.Stdout("  -> rcc run --robot %q\n", robot.Path)
+	common.Stdout("  -> rcc interactive --view history --robot %q\n\n", robot.Path)
+}
+
+func discoverRobots(roots []string) ([]*operations.RobotEntry, error) {
+	entries, err := operations.DiscoverRobots(roots...)
+	if err != nil {
+		return nil, fmt.Errorf("Could not find robots under %q, reason: %v", roots, err)
+	}
+	err = operations.RememberRobotDirectory(roots[0])
+	if err != nil {
+		note("Could not remember directory, reason: %v", err)
+	}
+	return entries, nil
+}
+
+// Robots shows list of robots under scan root directories, with search, sort,
+// and stale environment toggle, and shows details of one selected robot.
+// Selected stale robot also shows requested dependencies added or removed
+// since its last build. Scanner can be moved to another directory without
+// restarting. Chosen sort
+// order and visited directories are remembered for next session, and search,
+// toggle, directory, and selected robot are remembered for resuming view.
+func Robots(roots []string, order, search, selected string, staleOnly bool) error {
+	common.Stdout("\n")
+
+	entries, err := discoverRobots(roots)
+	if err != nil {
+		return err
+	}
+	if len(order) == 0 {
+		order = operations.RememberedRobotSort()
+	}
+	for _, entry := range entries {
+		if len(selected) > 0 && entry.Path == selected {
+			showRobot(entry)
+		}
+	}
+	for {
+		err = operations.SortRobots(entries, order)
+		if err != nil {
+			return err
+		}
+		visible := operations.FilterRobots(entries, search, staleOnly)
+		operations.ShowRobots(visible, order, search, staleOnly)
+		operations.Exportable("Robots", operations.RobotTable(visible))
+		common.Stdout("  /text = search, s = next sort, t = toggle stale only, d = change directory, number = select robot, y = copy command, e = export view, empty = quit\n\n")
+		reply, err := ask("Command", "", func(string) bool { return true })
+		if err != nil {
+			return err
+		}
+		switch {
+		case len(reply) == 0:
+			return nil
+		case strings.HasPrefix(reply, "/"):
+			search = strings.TrimSpace(reply[1:])
+			rememberState("filter", search)
+		case reply == "s":
+			order = operations.NextRobotSort(order)
+			err = operations.RememberRobotSort(order)
+			if err != nil {
+				note("Could not remember sort order, reason: %v", err)
+			}
+		case reply == "t":
+			staleOnly = !staleOnly
+			rememberState("stale", strconv.FormatBool(staleOnly))
+		case reply == "d":
+			directory, ok := pickRobotDirectory(roots)
+			if !ok {
+				continue
+			}
+			moved, err := discoverRobots([]string{directory})
+			if err != nil {
+				note("%v", err)
+				continue
+			}
+			roots, entries = []string{directory}, moved
+			rememberState("directory", directory)
+		case reply == "y":
+			err = operations.YankToClipboard()
+			if err != nil {
+				note("%v", err)
+			}
+		case reply == "e":
+			exportView("robots.md")
+		default:
+			selected, err := strconv.Atoi(reply)
+			if err != nil || selected < 1 || selected > len(visible) {
+				note("Unknown command %q.", reply)
+				continue
+			}
+			showRobot(visible[selected-1])
+			rememberState("select", visible[selected-1].Path)
+		}
+	}
+}
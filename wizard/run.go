@@ -0,0 +1,149 @@
+package wizard
+
+import (
+	"slices"
+	"time"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pretty"
+)
+
+func toggle(question string, current bool) (bool, error) {
+	defaults := "n"
+	if current {
+		defaults = "y"
+	}
+	reply, err := ask(question, defaults, memberValidation(yesNo, "Answer with 'y' or 'n'."))
+	if err != nil {
+		return false, err
+	}
+	return reply == "y", nil
+}
+
+func timeoutValidation(input string) bool {
+	if len(input) == 0 {
+		return true
+	}
+	_, err := time.ParseDuration(input)
+	if err != nil {
+		common.Stdout("%sGive timeout like 90m or 2h, or leave empty for no timeout.%s\n\n", pretty.Red, pretty.Reset)
+	}
+	return err == nil
+}
+
+func environmentValidation(input string) bool {
+	if len(input) == 0 {
+		return true
+	}
+	_, _, err := operations.ParseEnvironmentLine(input)
+	if err != nil {
+		common.Stdout("%s%v%s\n\n", pretty.Red, err, pretty.Reset)
+	}
+	return err == nil
+}
+
+func askEnvironment(parameters *operations.RunParameters) error {
+	lines := parameters.EnvironmentLines()
+	if len(lines) > 0 {
+		common.Stdout("%sExtra environment variables:%s\n", pretty.Grey, pretty.Reset)
+		for _, line := range lines {
+			common.Stdout("  %s\n", line)
+		}
+		common.Stdout("\n")
+		keep, err := toggle("Keep these environment variables", true)
+		if err != nil {
+			return err
+		}
+		if !keep {
+			parameters.Environment = map[string]string{}
+		}
+	}
+	for {
+		reply, err := ask("Extra environment variable as KEY=value (empty to continue)", "", environmentValidation)
+		if err != nil {
+			return err
+		}
+		if len(reply) == 0 {
+			return nil
+		}
+		key, value, _ := operations.ParseEnvironmentLine(reply)
+		parameters.Environment[key] = value
+	}
+}
+
+func chooseTask(parameters *operations.RunParameters, tasks []string) error {
+	if len(tasks) == 1 {
+		parameters.Task = tasks[0]
+	}
+	if len(tasks) < 2 {
+		return nil
+	}
+	at := slices.Index(tasks, parameters.Task)
+	if at > 0 {
+		tasks = append([]string{parameters.Task}, slices.Delete(slices.Clone(tasks), at, at+1)...)
+	}
+	chosen, err := choose("Choose task to run", "Tasks", tasks)
+	if err != nil {
+		return err
+	}
+	parameters.Task = chosen
+	return nil
+}
+
+// RunParameters asks run parameters and task before interactive run of
+// robot, using previous choices for that robot as defaults, and remembers
+// new choices for next time. Available tasks depend on dev mode (tasks or
+// devTasks), so they come from given function.
+func RunParameters(robotfile string, tasks func(developer bool) []string) (*operations.RunParameters, error) {
+	common.Stdout("\n")
+
+	parameters := operations.RememberedRunParameters(robotfile)
+	note("Previous run parameters: space %q, timeout %q, dev %v, no pip freeze %v, work item simulator %v, and %d extra environment variables.", parameters.Space, parameters.Timeout, parameters.Developer, parameters.NoPipFreeze, parameters.WorkItems, len(parameters.Environment))
+	common.Stdout("\n")
+	customize, err := confirm("Change run parameters before launching")
+	if err != nil {
+		return nil, err
+	}
+	if customize {
+		parameters.Space, err = ask("Space name", parameters.Space, regexpValidation(namePattern, "Use only letters, numbers, underscores, and dashes."))
+		if err != nil {
+			return nil, err
+		}
+		parameters.Timeout, err = ask("Timeout for run (like 90m, empty for none)", parameters.Timeout, timeoutValidation)
+		if err != nil {
+			return nil, err
+		}
+		parameters.Developer, err = toggle("Use devTasks (--dev)", parameters.Developer)
+		if err != nil {
+			return nil, err
+		}
+		parameters.NoPipFreeze, err = toggle("Skip pip freeze report (--no-pip-freeze)", parameters.NoPipFreeze)
+		if err != nil {
+			return nil, err
+		}
+		_, ok := operations.WorkItemsFile(robotfile)
+		if ok {
+			parameters.WorkItems, err = toggle("Serve devdata/work-items.json from local work item simulator", parameters.WorkItems)
+			if err != nil {
+				return nil, err
+			}
+		}
+		err = askEnvironment(parameters)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(parameters.Space) == 0 {
+		parameters.Space = "user"
+	}
+	err = chooseTask(parameters, tasks(parameters.Developer))
+	if err != nil {
+		return nil, err
+	}
+	err = operations.RememberRunParameters(robotfile, parameters)
+	if err != nil {
+		pretty.Warning("Could not remember run parameters, reason: %v", err)
+	}
+	return parameters, nil
+}
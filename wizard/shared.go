@@ -0,0 +1,63 @@
+package wizard
+
+import (
+	"fmt"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pretty"
+)
+
+func showSharedChecks() error {
+	failed := 0
+	for _, check := range operations.SharedHolotreeChecks() {
+		color := pretty.Green
+		if check.Status != "ok" {
+			color = pretty.Red
+			failed += 1
+		}
+		common.Stdout("  %s%-7s%s %s\n", color, check.Status, pretty.Reset, check.Message)
+	}
+	common.Stdout("\n")
+	if failed > 0 {
+		return fmt.Errorf("Shared holotree setup has %d problems, see \"rcc configuration diagnostics\" for more.", failed)
+	}
+	return nil
+}
+
+// SharedHolotree guides user through enabling shared holotree: it explains
+// what will be changed, runs enablement with elevated rights (sudo or UAC),
+// initializes shared use for current user, and verifies the result.
+func SharedHolotree() error {
+	common.Stdout("\n")
+
+	enabled, initialized := operations.SharedHolotreeState()
+	if enabled && initialized {
+		note("Shared holotree is already enabled, and initialized for this user.")
+		common.Stdout("\n")
+		return showSharedChecks()
+	}
+
+	common.Stdout("%sShared holotree%s lets all users of this machine use same environments, so\n", pretty.White, pretty.Reset)
+	common.Stdout("each environment is built only once. Following changes will be made:\n\n")
+	for _, change := range operations.SharedHolotreeChanges(enabled) {
+		common.Stdout("  - %s\n", change)
+	}
+	common.Stdout("\n")
+	warning(!enabled, "Enabling needs elevated rights, so expect sudo password or UAC prompt.")
+
+	ok, err := confirm("Enable shared holotree now?")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		note("Nothing was changed.")
+		return nil
+	}
+	err = operations.EnableSharedHolotree(enabled)
+	if err != nil {
+		return err
+	}
+	common.Stdout("\n%sVerifying shared holotree:%s\n\n", pretty.Grey, pretty.Reset)
+	return showSharedChecks()
+}
@@ -0,0 +1,85 @@
+package wizard
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/htfs"
+	"github.com/joshyorko/rcc/operations"
+	"github.com/joshyorko/rcc/pretty"
+)
+
+// showSpace shows details of one space and robots behind it, and lets user
+// jump to one of those robots.
+func showSpace(mapping *operations.SpaceMapping) error {
+	note("Space %q (controller %s) is at %s", mapping.Space, mapping.Controller, mapping.Path)
+	common.Stdout("  Blueprint: %s\n\n", mapping.Blueprint)
+	if len(mapping.Matches) == 0 {
+		common.Stdout("  %sNo known robot produces this space. Add more scan roots with --directory or \"rcc interactive robots --add-root\".%s\n\n", pretty.Grey, pretty.Reset)
+		return nil
+	}
+	for at, match := range mapping.Matches {
+		state := pretty.Green + "current" + pretty.Reset
+		if !match.Current {
+			state = pretty.Yellow + "changed" + pretty.Reset
+		}
+		common.Stdout("  %s%3d%s %-30s %s %s%s%s\n", pretty.Cyan, at+1, pretty.Reset, match.Robot.Name, state, pretty.Grey, match.Robot.Path, pretty.Reset)
+	}
+	common.Stdout("\n  \"current\" robots give blueprint of this space now, \"changed\" ones used it before their environment changed.\n")
+	common.Stdout("  number = jump to robot, empty = back to spaces\n\n")
+	reply, err := ask("Robot", "", func(string) bool { return true })
+	if err != nil || len(reply) == 0 {
+		return err
+	}
+	selected, err := strconv.Atoi(reply)
+	if err != nil || selected < 1 || selected > len(mapping.Matches) {
+		note("Unknown robot %q.", reply)
+		return nil
+	}
+	showRobot(mapping.Matches[selected-1].Robot)
+	return nil
+}
+
+// Spaces shows holotree spaces with robots (and so conda.yaml files) that
+// produced them, found by blueprint correlation with robots under scan roots
+// and by recorded space users. Selected space can be used to jump to its
+// robot.
+func Spaces(roots []string, search string) error {
+	common.Stdout("\n")
+
+	entries, err := discoverRobots(roots)
+	if err != nil {
+		return err
+	}
+	mappings := operations.MapSpacesToRobots(htfs.LoadCatalogInfos().Spaces(), entries)
+	for {
+		visible := operations.FilterSpaceMappings(mappings, search)
+		operations.ShowSpaceMappings(visible, search)
+		operations.Exportable("Spaces", operations.SpaceTable(visible))
+		common.Stdout("  /text = search, number = select space, e = export view, empty = quit\n\n")
+		reply, err := ask("Command", "", func(string) bool { return true })
+		if err != nil {
+			return err
+		}
+		switch {
+		case len(reply) == 0:
+			return nil
+		case strings.HasPrefix(reply, "/"):
+			search = strings.TrimSpace(reply[1:])
+			rememberState("filter", search)
+		case reply == "e":
+			exportView("spaces.md")
+		default:
+			selected, err := strconv.Atoi(reply)
+			if err != nil || selected < 1 || selected > len(visible) {
+				note("Unknown command %q.", reply)
+				continue
+			}
+			err = showSpace(visible[selected-1])
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
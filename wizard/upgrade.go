@@ -0,0 +1,263 @@
+package wizard
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/joshyorko/rcc/common"
+	"github.com/joshyorko/rcc/conda"
+	"github.com/joshyorko/rcc/pretty"
+	"github.com/joshyorko/rcc/robot"
+	"github.com/joshyorko/rcc/settings"
+)
+
+var (
+	changelogPattern = regexp.MustCompile(`#\s*(https?://\S+)`)
+)
+
+// Bump is available newer version of one pinned pip dependency. Compatible
+// is newest version within same major version (or same minor, when major is
+// zero), and Latest is newest released version overall.
+type Bump struct {
+	Name       string
+	Current    string
+	Compatible string
+	Latest     string
+	Changelog  string
+}
+
+type pinned struct {
+	name      string
+	version   string
+	changelog string
+}
+
+func (it *Bump) Spec(version string) string {
+	return fmt.Sprintf("%s==%s", it.Name, version)
+}
+
+func (it *Bump) Major() bool {
+	return len(it.Latest) > 0 && it.Latest != it.Compatible
+}
+
+// pinnedPipDependencies finds exactly pinned (==) pip dependencies from
+// conda.yaml content, with changelog links from their trailing comments.
+func pinnedPipDependencies(content string) []*pinned {
+	result := []*pinned{}
+	section, pipIndent := false, -1
+	for _, line := range strings.Split(content, "\n") {
+		if topLevelLine.MatchString(line) {
+			section, pipIndent = strings.HasPrefix(line, "dependencies:"), -1
+			continue
+		}
+		if !section {
+			continue
+		}
+		if found := pipBlockLine.FindStringSubmatch(line); found != nil {
+			pipIndent = len(found[1])
+			continue
+		}
+		found := dependencyLine.FindStringSubmatch(line)
+		if found == nil {
+			continue
+		}
+		if pipIndent < 0 || len(found[1]) <= pipIndent {
+			pipIndent = -1
+			continue
+		}
+		name, version, ok := strings.Cut(found[2], "==")
+		if !ok || len(name) == 0 || len(version) == 0 {
+			continue
+		}
+		link := ""
+		if comment := changelogPattern.FindStringSubmatch(found[3]); comment != nil {
+			link = comment[1]
+		}
+		result = append(result, &pinned{name: name, version: version, changelog: link})
+	}
+	return result
+}
+
+// majorOf gives version prefix that must stay same for bump to be
+// compatible, which is major version, or major.minor for zero versions.
+func majorOf(version string) string {
+	parts := strings.Split(version, ".")
+	if parts[0] == "0" && len(parts) > 1 {
+		return strings.Join(parts[:2], ".")
+	}
+	return parts[0]
+}
+
+func compatibleWith(current, candidate string) bool {
+	major := majorOf(current)
+	return candidate == major || strings.HasPrefix(candidate, major+".")
+}
+
+// newerVersions picks newest compatible and newest overall version, from
+// versions sorted newest first, if those are newer than current.
+func newerVersions(current string, versions []string) (compatible, latest string) {
+	for _, version := range versions {
+		if compareVersions(version, current) <= 0 {
+			break
+		}
+		if len(latest) == 0 {
+			latest = version
+		}
+		if compatibleWith(current, version) {
+			compatible = version
+			break
+		}
+	}
+	return compatible, latest
+}
+
+// projectPage derives package history page from simple index endpoint, like
+// from "https://pypi.org/simple/" to "https://pypi.org/project/name/#history".
+func projectPage(endpoint, name string) string {
+	base, found := strings.CutSuffix(strings.TrimRight(endpoint, "/"), "/simple")
+	if !found {
+		return ""
+	}
+	return fmt.Sprintf("%s/project/%s/#history", base, canonicalName(name))
+}
+
+func availableBumps(endpoint, content string) ([]*Bump, []string) {
+	bumps, failures := []*Bump{}, []string{}
+	for _, pin := range pinnedPipDependencies(content) {
+		project, _, _ := strings.Cut(pin.name, "[")
+		versions, err := pypiReleases(endpoint, project)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", pin.name, err))
+			continue
+		}
+		compatible, latest := newerVersions(pin.version, versions)
+		if len(latest) == 0 {
+			continue
+		}
+		changelog := pin.changelog
+		if len(changelog) == 0 {
+			changelog = projectPage(endpoint, project)
+		}
+		bumps = append(bumps, &Bump{
+			Name:       pin.name,
+			Current:    pin.version,
+			Compatible: compatible,
+			Latest:     latest,
+			Changelog:  changelog,
+		})
+	}
+	return bumps, failures
+}
+
+func upgradeReport(condafile string, bumps []*Bump) {
+	common.Stdout("%sNewer versions of pinned pip dependencies in %q:%s\n\n", pretty.Grey, condafile, pretty.Reset)
+	if len(bumps) == 0 {
+		common.Stdout("  %sAll pinned pip dependencies are up to date.%s\n\n", pretty.Green, pretty.Reset)
+		return
+	}
+	for _, bump := range bumps {
+		compatible := bump.Compatible
+		if len(compatible) == 0 {
+			compatible = "-"
+		}
+		common.Stdout("  %s%-30s%s %-12s compatible: %s%-12s%s latest: %-12s %s%s%s\n", pretty.White, bump.Name, pretty.Reset, bump.Current, pretty.Green, compatible, pretty.Reset, bump.Latest, pretty.Grey, bump.Changelog, pretty.Reset)
+	}
+	common.Stdout("\n")
+}
+
+func bumpChoices(bumps []*Bump) ([]string, map[string]string) {
+	choices, specs := []string{}, make(map[string]string)
+	for _, bump := range bumps {
+		if len(bump.Compatible) > 0 {
+			label := fmt.Sprintf("%s %s -> %s (compatible)", bump.Name, bump.Current, bump.Compatible)
+			choices, specs[label] = append(choices, label), bump.Spec(bump.Compatible)
+		}
+		if len(bump.Compatible) == 0 || bump.Major() {
+			label := fmt.Sprintf("%s %s -> %s (latest, may break)", bump.Name, bump.Current, bump.Latest)
+			choices, specs[label] = append(choices, label), bump.Spec(bump.Latest)
+		}
+	}
+	return choices, specs
+}
+
+// selectedSpecs keeps only newest selected version for each package.
+func selectedSpecs(selected []string, specs map[string]string) []string {
+	newest := make(map[string]string)
+	order := []string{}
+	for _, label := range selected {
+		name, version, _ := strings.Cut(specs[label], "==")
+		previous, ok := newest[name]
+		if !ok {
+			order = append(order, name)
+		}
+		if !ok || compareVersions(version, previous) > 0 {
+			newest[name] = version
+		}
+	}
+	result := make([]string, 0, len(order))
+	for _, name := range order {
+		result = append(result, fmt.Sprintf("%s==%s", name, newest[name]))
+	}
+	return result
+}
+
+// Upgrade checks configured PyPI index for newer versions of pinned pip
+// dependencies in robot conda.yaml, and reports them. Unless only checking,
+// selected bumps (or all compatible ones, with compatibleOnly) are written
+// back into conda.yaml. Result tells if conda.yaml was changed.
+func Upgrade(robotfile string, check, compatibleOnly bool) (bool, error) {
+	config, err := robot.LoadRobotYaml(robotfile, false)
+	if err != nil {
+		return false, err
+	}
+	condafile := config.CondaConfigFile()
+	if strings.ToLower(filepath.Base(condafile)) == "package.yaml" {
+		return false, fmt.Errorf("Upgrading dependencies in %q is not supported, only conda.yaml files are.", condafile)
+	}
+	content, err := os.ReadFile(condafile)
+	if err != nil {
+		return false, err
+	}
+	bumps, failures := availableBumps(settings.Global.PypiLink(""), string(content))
+	for _, failure := range failures {
+		note("Could not check %s", failure)
+	}
+	upgradeReport(condafile, bumps)
+	if check || len(bumps) == 0 {
+		return false, nil
+	}
+	choices, specs := bumpChoices(bumps)
+	selected := []string{}
+	if compatibleOnly {
+		for _, choice := range choices {
+			if strings.HasSuffix(choice, "(compatible)") {
+				selected = append(selected, choice)
+			}
+		}
+	} else {
+		selected, err = chooseMany("Choose version bumps to apply", "Bumps", choices, []string{"(compatible)"})
+		if err != nil {
+			return false, err
+		}
+	}
+	pips := selectedSpecs(selected, specs)
+	if len(pips) == 0 {
+		note("Nothing selected, %q left as is.", condafile)
+		return false, nil
+	}
+	edited := editedCondaYaml(string(content), "", nil, pips)
+	_, err = conda.CondaYamlFrom([]byte(edited))
+	if err != nil {
+		return false, fmt.Errorf("Upgraded %q is not valid, reason: %v", condafile, err)
+	}
+	err = os.WriteFile(condafile, []byte(edited), 0o644)
+	if err != nil {
+		return false, err
+	}
+	common.RunJournal("robot", "upgrade", "upgraded %q: %s", condafile, strings.Join(pips, ", "))
+	note("Updated %q with %d version bumps: %s", condafile, len(pips), strings.Join(pips, ", "))
+	return true, nil
+}
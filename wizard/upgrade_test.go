@@ -0,0 +1,69 @@
+package wizard
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/joshyorko/rcc/hamlet"
+)
+
+func TestCanFindPinnedPipDependencies(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	pins := pinnedPipDependencies(templateCondaYaml + "    - requests>=2.0\n    - robocorp[all]==1.2.0\n  - nodejs=22.11.0\n")
+	must.Equal(2, len(pins))
+	must.Equal("rpaframework", pins[0].name)
+	must.Equal("15.6.0", pins[0].version)
+	must.Equal("https://rpaframework.org/releasenotes.html", pins[0].changelog)
+	must.Equal("robocorp[all]", pins[1].name)
+	must.Equal("", pins[1].changelog)
+}
+
+func TestCanPickNewerVersions(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	versions := []string{"3.0.1", "2.5.0", "2.4.1", "2.4.0", "1.9.0"}
+	compatible, latest := newerVersions("2.4.0", versions)
+	must.Equal("2.5.0", compatible)
+	must.Equal("3.0.1", latest)
+
+	compatible, latest = newerVersions("3.0.1", versions)
+	must.Equal("", compatible)
+	must.Equal("", latest)
+
+	compatible, latest = newerVersions("0.3.1", []string{"0.4.0", "0.3.9", "0.3.1"})
+	must.Equal("0.3.9", compatible)
+	must.Equal("0.4.0", latest)
+
+	must.Equal("https://pypi.org/project/robocorp-tasks/#history", projectPage("https://pypi.org/simple/", "Robocorp_Tasks"))
+	must.Equal("", projectPage("https://example.com/pypi/", "requests"))
+
+	specs := map[string]string{"a": "lib==2.5.0", "b": "lib==3.0.1", "c": "other==1.0"}
+	must.Equal([]string{"lib==3.0.1", "other==1.0"}, selectedSpecs([]string{"b", "a", "c"}, specs))
+}
+
+func TestCanListAvailableBumps(t *testing.T) {
+	must, _ := hamlet.Specifications(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		if request.URL.Path != "/simple/rpaframework/" {
+			http.NotFound(response, request)
+			return
+		}
+		response.Write([]byte(`{"versions": ["15.6.0", "15.9.1", "16.0.0rc1", "28.6.3"]}`))
+	}))
+	defer server.Close()
+
+	bumps, failures := availableBumps(server.URL+"/simple", templateCondaYaml+"    - missing==1.0\n")
+	must.Equal(1, len(failures))
+	must.Equal(1, len(bumps))
+	must.Equal("15.9.1", bumps[0].Compatible)
+	must.Equal("28.6.3", bumps[0].Latest)
+	must.True(bumps[0].Major())
+
+	choices, specs := bumpChoices(bumps)
+	must.Equal(2, len(choices))
+	must.Equal("rpaframework==15.9.1", specs[choices[0]])
+	must.Equal("rpaframework==28.6.3", specs[choices[1]])
+}